@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"s3manager/config"
+	"strings"
+)
+
+// Nested aliases are expanded up to this depth, deeper ones are assumed to be a loop
+const maxAliasDepth = 10
+
+// expandAlias replaces a user-defined command in args by the command line of its alias
+// in the [alias] section of the config file, followed by the remaining arguments. Built-in
+// commands cannot be shadowed. Without a config file args are returned unchanged.
+func expandAlias(args []string) ([]string, error) {
+	i := commandIndex(args)
+	if i < 0 || isBuiltinCommand(args[i]) {
+		return args, nil
+	}
+
+	path := configFlag(args)
+	if path == "" {
+		if path = config.ProfilesPath(); path == "" {
+			return args, nil
+		}
+		if _, err := os.Stat(path); err != nil {
+			return args, nil
+		}
+	}
+	profiles, err := config.LoadProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for depth := 0; i >= 0 && !isBuiltinCommand(args[i]); depth++ {
+		line, ok := profiles.Aliases[args[i]]
+		if !ok {
+			return args, nil
+		}
+		if seen[args[i]] || depth >= maxAliasDepth {
+			return nil, fmt.Errorf("alias %s expands to itself", args[i])
+		}
+		seen[args[i]] = true
+
+		words, err := splitCommandLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %s in %s: %w", args[i], path, err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("alias %s in %s is empty", args[i], path)
+		}
+		args = append(append(append([]string(nil), args[:i]...), words...), args[i+1:]...)
+		i = commandIndex(args)
+	}
+	return args, nil
+}
+
+// commandIndex returns the index of the first argument that is not a global flag or its
+// value, -1 when there is none.
+func commandIndex(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		flag := rootCmd.PersistentFlags().Lookup(name)
+		if flag == nil && !strings.HasPrefix(arg, "--") && len(name) == 1 {
+			flag = rootCmd.PersistentFlags().ShorthandLookup(name)
+		}
+		// Flags without a default for their bare form take the next argument as value
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return -1
+}
+
+// configFlag returns the value of --config in args, "" when it is not given.
+func configFlag(args []string) string {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			return value
+		}
+		if arg == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func isBuiltinCommand(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help" || name == "completion"
+}
+
+// splitCommandLine splits s into words like a POSIX shell, honouring single and double
+// quotes and backslash escapes. Variables and globs are not expanded.
+func splitCommandLine(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\' && quote == '"':
+			if i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+				i++
+				r = runes[i]
+			}
+			word.WriteRune(r)
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"upload /var/backups/db --no-archive", []string{"upload", "/var/backups/db", "--no-archive"}},
+		{`upload "My Documents" --exclude '*.log'`, []string{"upload", "My Documents", "--exclude", "*.log"}},
+		{`grep "say \"hi\"" a\ b ''`, []string{"grep", `say "hi"`, "a b", ""}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitCommandLine(tt.line)
+		if err != nil {
+			t.Errorf("splitCommandLine(%q) error = %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommandLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+	if _, err := splitCommandLine(`upload "unterminated`); err == nil {
+		t.Errorf("splitCommandLine() of an unterminated quote should return error")
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte(`[alias]
+backup-db = upload /var/backups/db --destination "backups/db" --no-archive
+nightly = backup-db --confirm
+upload = delete-old backups --days 1
+loop = loop-again
+loop-again = loop
+`), 0644)
+	t.Setenv("S3MANAGER_CONFIG", path)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"backup-db", "-v"}, []string{"upload", "/var/backups/db", "--destination", "backups/db", "--no-archive", "-v"}},
+		{[]string{"--bucket", "other", "nightly"}, []string{"--bucket", "other", "upload", "/var/backups/db", "--destination", "backups/db", "--no-archive", "--confirm"}},
+		// Built-in commands cannot be shadowed
+		{[]string{"upload", "file.txt"}, []string{"upload", "file.txt"}},
+		{[]string{"unknown"}, []string{"unknown"}},
+	}
+	for _, tt := range tests {
+		got, err := expandAlias(tt.args)
+		if err != nil {
+			t.Errorf("expandAlias(%q) error = %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandAlias(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := expandAlias([]string{"loop"}); err == nil {
+		t.Errorf("expandAlias() of a loop should return error")
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var appendCmd = &cobra.Command{
+	Use:   "append <key>",
+	Short: "Append data to an object",
+	Long: `Add the content of --from to the end of an object, creating the object when it does not
+exist, so that periodic log segments accumulate into one object. The key may contain
+tokens like {yyyy}/{MM}/{dd}, which starts a new object every day.
+
+S3 objects cannot be changed in place, so the object is written again as a multipart
+upload: its current content is copied on the server and the new data is added as the last
+parts. Only objects smaller than 5MB are downloaded for that. Content type, metadata and
+tags are kept. When another writer changes the object meanwhile, nothing is written and
+the command fails, so appends running at the same time never lose data.`,
+	Example: `  # Ship the last log segment into a daily object
+  s3manager append "logs/{yyyy}/{MM}/{dd}/app.log" --from /var/log/app/segment.log
+
+  # Append from a pipe
+  journalctl --since -1h | s3manager append logs/journal.log --from -`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runAppend(cmd, args[0])
+	},
+}
+
+func init() {
+	appendCmd.Flags().String("from", "", "File with the data to append, - for standard input")
+	appendCmd.MarkFlagRequired("from")
+}
+
+func runAppend(cmd *cobra.Command, key string) {
+	from, _ := cmd.Flags().GetString("from")
+
+	key, err := utils.ExpandTemplate(key, time.Now())
+	if err != nil {
+		utils.PrintError(fmt.Errorf("invalid key: %w", err), "append")
+		return
+	}
+
+	var body io.Reader = os.Stdin
+	if from != "-" {
+		file, err := os.Open(from)
+		if err != nil {
+			utils.PrintError(err, "append")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	jb, err := startJob(cmd, "append")
+	if err != nil {
+		utils.PrintError(err, "append")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "append")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	result, err := client.Append(ctx, key, body)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "append")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	jb.succeed(result)
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "append")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Appended %s to %s, now %s\n", result.AppendedHuman, key, result.SizeHuman)
+	}
+}
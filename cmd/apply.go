@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan.json>",
+	Short: "Execute a previously reviewed deletion plan",
+	Long: `Execute a deletion plan written by "delete-old --plan-out" exactly as it was reviewed.
+
+Only the objects listed in the plan are deleted. Before deleting anything the prefix is
+listed again and the command fails if any planned object was overwritten since the plan
+was made; create and review a new plan in that case. Planned objects that no longer
+exist are skipped and objects uploaded after planning are never touched.`,
+	Example: `  # Review the plan, then apply it
+  s3manager delete-old --days 90 --folder logs --plan-out prune.json
+  s3manager apply prune.json
+
+  # Apply without the confirmation prompt
+  s3manager apply prune.json --confirm`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runApply(cmd, args)
+	},
+}
+
+func runApply(cmd *cobra.Command, args []string) {
+	path := args[0]
+	confirm, _ := cmd.Flags().GetBool("confirm")
+
+	plan, err := s3client.ReadDeletionPlan(path)
+	if err != nil {
+		utils.PrintError(err, "apply")
+		return
+	}
+
+	applyDeletionFlags(cmd)
+
+	if !confirm {
+		warning := i18n.Tf("WARNING: This will %s the objects planned at %s from bucket '%s'",
+			deletionVerb(), plan.CreatedAt, plan.BucketName)
+		if plan.Folder != "" {
+			warning += i18n.Tf(" in folder '%s'", plan.Folder)
+		}
+
+		ok, err := confirmDeletion(newPrompter(cmd, os.Stdout), os.Stdout, warning, plan.BucketName, plan.TotalObjects, plan.TotalSizeBytes)
+		if err != nil {
+			utils.PrintError(err, "apply")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Operation cancelled."))
+			return
+		}
+	}
+
+	jb, err := startJob(cmd, "apply")
+	if err != nil {
+		utils.PrintError(err, "apply")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "apply")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	ctx, unlock, err := holdLock(ctx, cmd, client, "apply")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "apply")
+		return
+	}
+	defer unlock()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Applying plan %s: %d objects from bucket %s\n", path, plan.TotalObjects, plan.BucketName)
+	}
+
+	result, err := client.ApplyDeletionPlan(ctx, plan)
+	if err != nil {
+		if result == nil || !result.Interrupted {
+			jb.fail(err, nil)
+			utils.PrintError(err, "apply")
+			return
+		}
+		jb.fail(err, result)
+	} else {
+		jb.succeed(result)
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "apply")
+		return
+	}
+
+	if isVerbose(cmd) && !result.Interrupted {
+		cmd.Println("Plan applied successfully")
+	}
+}
+
+func init() {
+	applyCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+	addDeletionFlags(applyCmd)
+	applyCmd.Flags().Int("max-delete", 0, "Abort without deleting anything if the plan deletes more objects, 0 for no limit (default from MAX_DELETE)")
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Work with zip archives stored in the bucket",
+	Long: `Work with zip archives stored in the bucket, such as the ones created by upload --archive.
+
+The archive is read with ranged requests: its central directory and the members that are
+needed are fetched, not the whole object.`,
+}
+
+func init() {
+	archiveCmd.AddCommand(archiveExtractCmd)
+	archiveCmd.AddCommand(archiveListCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var archiveExtractCmd = &cobra.Command{
+	Use:   "extract <key>",
+	Short: "Restore single members of a zip archive in the bucket",
+	Long: `Restore single members of a zip archive in the bucket without downloading all of it.
+
+Members are given by their name in the archive or by a glob pattern, like db/*.sql, and
+written below --dest with their path in the archive. Members whose names would leave the
+destination are refused.`,
+	Example: `  # Restore the schema from last night's backup
+  s3manager archive extract backups/site-20240315.zip --member "db/schema.sql" --dest ./
+
+  # Restore every SQL file of the archive
+  s3manager archive extract backups/site-20240315.zip --member "db/*.sql" --dest /tmp/restore`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		members, _ := cmd.Flags().GetStringArray("member")
+		dest, _ := cmd.Flags().GetString("dest")
+		runArchiveExtract(cmd, args[0], members, dest)
+	},
+}
+
+func init() {
+	archiveExtractCmd.Flags().StringArray("member", nil, "Member to restore, a name in the archive or a glob pattern (repeatable)")
+	archiveExtractCmd.Flags().String("dest", ".", "Local directory to restore the members to")
+	archiveExtractCmd.MarkFlagRequired("member")
+}
+
+func runArchiveExtract(cmd *cobra.Command, key string, members []string, dest string) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "archive extract")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	result, err := client.ExtractArchive(ctx, key, members, dest)
+	if err != nil {
+		utils.PrintError(err, "archive extract")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "archive extract")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Restored %d files (%s), fetched %s of %s in %d requests\n", result.TotalFiles, result.TotalSizeHuman, result.FetchedHuman, result.ArchiveSizeHuman, result.Requests)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/export"
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var archiveListCmd = &cobra.Command{
+	Use:   "list <key>",
+	Short: "List the members of a zip archive in the bucket",
+	Long: `List the members of a zip archive in the bucket with their sizes.
+
+Only the central directory at the end of the archive is fetched, so checking what a
+backup contains takes a request or two however large it is.`,
+	Example: `  # What is in last night's backup
+  s3manager archive list backups/site-20240315.zip
+
+  # The members as CSV
+  s3manager archive list backups/site-20240315.zip --output csv`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runArchiveList(cmd, args[0])
+	},
+}
+
+func init() {
+	addReportOutputFlag(archiveListCmd)
+}
+
+func runArchiveList(cmd *cobra.Command, key string) {
+	output, err := outputFormat(cmd, outputJSON, outputCSV)
+	if err != nil {
+		utils.PrintError(err, "archive list")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "archive list")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 5*time.Minute)
+	defer cancel()
+
+	result, err := client.ListArchive(ctx, key)
+	if err != nil {
+		utils.PrintError(err, "archive list")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := printReport(output, result, archiveMemberTable(result.Members)); err != nil {
+		utils.PrintError(err, "archive list")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("%d files, %s (%s compressed), fetched %s of %s\n", result.TotalFiles, result.TotalSizeHuman, result.CompressedSizeHuman, result.FetchedHuman, result.ArchiveSizeHuman)
+	}
+}
+
+func archiveMemberTable(members []models.ArchiveMember) export.Table {
+	table := export.Table{Columns: []export.Column{
+		{Name: "name", Kind: export.String},
+		{Name: "size_bytes", Kind: export.Int64},
+		{Name: "compressed_size_bytes", Kind: export.Int64},
+		{Name: "modified", Kind: export.Time},
+	}}
+	for _, member := range members {
+		modified, _ := time.Parse(time.RFC3339, member.Modified)
+		table.Rows = append(table.Rows, []any{member.Name, member.Size, member.CompressedSize, modified})
+	}
+	return table
+}
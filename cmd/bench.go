@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure upload and download throughput against the bucket",
+	Long: `Upload an object of synthetic data, download it again and report the throughput of
+both transfers and the latency percentiles of their part requests.
+
+Run it with different --part-size and --parallel values to choose the settings for a
+connection, or with different providers to compare them. The object is written under
+.s3manager/bench/ (below --prefix) and deleted afterwards; nothing is written to disk.
+The upload keeps up to --parallel parts in memory.`,
+	Example: `  # Benchmark 1GB with 8 parallel 16MB parts
+  s3manager bench --size 1GB --parallel 8 --part-size 16MB
+
+  # Credentials limited to a prefix
+  s3manager bench --size 256MB --prefix backups/`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBench(cmd)
+	},
+}
+
+func runBench(cmd *cobra.Command) {
+	sizeFlag, _ := cmd.Flags().GetString("size")
+	partSizeFlag, _ := cmd.Flags().GetString("part-size")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	prefix, _ := cmd.Flags().GetString("prefix")
+
+	size, err := utils.ParseBytes(sizeFlag)
+	if err != nil {
+		utils.PrintError(err, "bench")
+		return
+	}
+	partSize, err := utils.ParseBytes(partSizeFlag)
+	if err != nil {
+		utils.PrintError(err, "bench")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "bench")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Benchmarking %s in %s parts, %d in parallel\n", utils.FormatBytes(size), utils.FormatBytes(partSize), parallel)
+	}
+
+	result, err := client.Bench(ctx, s3client.BenchOptions{
+		Size:     size,
+		PartSize: partSize,
+		Parallel: parallel,
+		Prefix:   prefix,
+	})
+	if err != nil {
+		utils.PrintError(err, "bench")
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "bench")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Upload %s, download %s\n", result.Upload.ThroughputHuman, result.Download.ThroughputHuman)
+	}
+}
+
+func init() {
+	benchCmd.Flags().String("size", "256MB", "Size of the synthetic object (e.g. 1GB)")
+	benchCmd.Flags().String("part-size", "64MB", "Size of the multipart upload and download parts, at least 5MB")
+	benchCmd.Flags().Int("parallel", 5, "Number of parts transferred in parallel")
+	benchCmd.Flags().String("prefix", "", "Prefix under which the benchmark object is written, for credentials limited to a prefix")
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var bucketCmd = &cobra.Command{
+	Use:   "bucket",
+	Short: "Manage bucket-level configuration",
+	Long: `Manage bucket-level configuration such as static website hosting,
+tags, access logging and request metrics.
+
+The bucket name is taken from the configuration file unless overridden with --bucket flag.`,
+}
+
+func init() {
+	bucketCmd.AddCommand(bucketWebsiteCmd)
+	bucketCmd.AddCommand(bucketTagsCmd)
+	bucketCmd.AddCommand(bucketLoggingCmd)
+	bucketCmd.AddCommand(bucketMetricsCmd)
+}
@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"context"
 	"github.com/spf13/cobra"
-	"s3manager/internal/s3client"
 	"s3manager/pkg/utils"
 	"time"
 )
@@ -27,13 +25,17 @@ The bucket name is taken from the configuration file unless overridden with --bu
 }
 
 func runBucketInfo(cmd *cobra.Command) {
-	client, err := s3client.New(cfg)
+	client, err := newClient(cfg)
 	if err != nil {
 		utils.PrintError(err, "bucket-info")
 		return
 	}
+	if err := useListingCache(cmd, client); err != nil {
+		utils.PrintError(err, "bucket-info")
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := operationContext(cmd, 5*time.Minute)
 	defer cancel()
 
 	if isVerbose(cmd) {
@@ -61,5 +63,5 @@ func runBucketInfo(cmd *cobra.Command) {
 }
 
 func init() {
-	bucketInfoCmd.Flags().Int("timeout", 300, "Timeout in seconds for the operation")
+	addCacheFlags(bucketInfoCmd)
 }
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/models"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var bucketLoggingCmd = &cobra.Command{
+	Use:   "logging",
+	Short: "Manage server access logging",
+	Long: `Get or set where the server access logs of the bucket are delivered.
+
+Access logs record every request made to the bucket, which audits need and which
+delete-old --unused-for reads to find objects nobody fetches anymore. The target bucket
+must be in the same region and allow the logging service to write to it.`,
+}
+
+var bucketLoggingGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show the access logging target",
+	Example: `  # Show where the access logs of the configured bucket go
+  s3manager bucket logging get`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBucketLogging(cmd, "bucket logging get", func(ctx context.Context, client *s3client.Client) (*models.BucketLogging, error) {
+			return client.GetBucketLogging(ctx)
+		})
+	},
+}
+
+var bucketLoggingSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Enable or disable access logging",
+	Long: `Deliver the access logs of the bucket to --target-bucket below --target-prefix, by
+default the ACCESS_LOG_BUCKET and ACCESS_LOG_PREFIX that delete-old reads them from.
+--disable turns logging off.`,
+	Example: `  # Log to the configured ACCESS_LOG_BUCKET and ACCESS_LOG_PREFIX
+  s3manager bucket logging set
+
+  # Log to another bucket
+  s3manager bucket logging set --target-bucket my-logs --target-prefix assets-bucket/
+
+  # Stop logging
+  s3manager bucket logging set --disable`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		disable, _ := cmd.Flags().GetBool("disable")
+		targetBucket, _ := cmd.Flags().GetString("target-bucket")
+		targetPrefix, _ := cmd.Flags().GetString("target-prefix")
+
+		switch {
+		case disable && (cmd.Flags().Changed("target-bucket") || cmd.Flags().Changed("target-prefix")):
+			utils.PrintError(fmt.Errorf("--disable cannot be combined with a target"), "bucket logging set")
+			return
+		case disable:
+			targetBucket, targetPrefix = "", ""
+		default:
+			if !cmd.Flags().Changed("target-bucket") {
+				targetBucket = cfg.AccessLogBucket
+			}
+			if !cmd.Flags().Changed("target-prefix") {
+				targetPrefix = cfg.AccessLogPrefix
+			}
+			if targetBucket == "" {
+				utils.PrintError(fmt.Errorf("no target bucket, set ACCESS_LOG_BUCKET or --target-bucket"), "bucket logging set")
+				return
+			}
+		}
+		runBucketLogging(cmd, "bucket logging set", func(ctx context.Context, client *s3client.Client) (*models.BucketLogging, error) {
+			return client.PutBucketLogging(ctx, targetBucket, targetPrefix)
+		})
+	},
+}
+
+func runBucketLogging(cmd *cobra.Command, command string, operation func(context.Context, *s3client.Client) (*models.BucketLogging, error)) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Bucket: %s\n", getBucketName(cmd))
+	}
+
+	logging, err := operation(ctx, client)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		logging.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(logging); err != nil {
+		utils.PrintError(err, command)
+	}
+}
+
+func init() {
+	bucketLoggingCmd.AddCommand(bucketLoggingGetCmd)
+	bucketLoggingCmd.AddCommand(bucketLoggingSetCmd)
+
+	bucketLoggingSetCmd.Flags().String("target-bucket", "", "Bucket receiving the access logs (default from ACCESS_LOG_BUCKET)")
+	bucketLoggingSetCmd.Flags().String("target-prefix", "", "Key prefix of the access logs (default from ACCESS_LOG_PREFIX)")
+	bucketLoggingSetCmd.Flags().Bool("disable", false, "Turn access logging off")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"s3manager/internal/models"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var bucketMetricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Manage request metrics configurations",
+	Long: `Get, set or delete the request metrics configurations of the bucket.
+
+Each configuration publishes CloudWatch request metrics, such as request counts, errors
+and latencies, for the whole bucket or the objects matching a prefix and tags. The
+CloudWatch console names the whole-bucket configuration EntireBucket.`,
+}
+
+var bucketMetricsGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "List the metrics configurations",
+	Example: `  # List the metrics configurations of the configured bucket
+  s3manager bucket metrics get`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBucketMetrics(cmd, "bucket metrics get", func(ctx context.Context, client *s3client.Client) (*models.BucketMetrics, error) {
+			return client.GetBucketMetrics(ctx)
+		})
+	},
+}
+
+var bucketMetricsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Create or replace a metrics configuration",
+	Long: `Create the metrics configuration --id, or replace it when it exists. Without --prefix
+and --tag it covers the whole bucket; otherwise only objects below the prefix carrying
+all of the tags.`,
+	Example: `  # Request metrics for the whole bucket
+  s3manager bucket metrics set --id EntireBucket
+
+  # Request metrics for uploads of one team
+  s3manager bucket metrics set --id backend-uploads --prefix uploads/ --tag team=backend`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		id, _ := cmd.Flags().GetString("id")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		tagFlag, _ := cmd.Flags().GetStringArray("tag")
+
+		tags, err := parseTags(tagFlag)
+		if err != nil {
+			utils.PrintError(err, "bucket metrics set")
+			return
+		}
+		runBucketMetrics(cmd, "bucket metrics set", func(ctx context.Context, client *s3client.Client) (*models.BucketMetrics, error) {
+			return client.PutBucketMetrics(ctx, id, prefix, tags)
+		})
+	},
+}
+
+var bucketMetricsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a metrics configuration",
+	Example: `  # Stop publishing the whole-bucket request metrics
+  s3manager bucket metrics delete --id EntireBucket`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		id, _ := cmd.Flags().GetString("id")
+		runBucketMetrics(cmd, "bucket metrics delete", func(ctx context.Context, client *s3client.Client) (*models.BucketMetrics, error) {
+			return client.DeleteBucketMetrics(ctx, id)
+		})
+	},
+}
+
+func runBucketMetrics(cmd *cobra.Command, command string, operation func(context.Context, *s3client.Client) (*models.BucketMetrics, error)) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Bucket: %s\n", getBucketName(cmd))
+	}
+
+	metrics, err := operation(ctx, client)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		metrics.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(metrics); err != nil {
+		utils.PrintError(err, command)
+	}
+}
+
+func init() {
+	bucketMetricsCmd.AddCommand(bucketMetricsGetCmd)
+	bucketMetricsCmd.AddCommand(bucketMetricsSetCmd)
+	bucketMetricsCmd.AddCommand(bucketMetricsDeleteCmd)
+
+	bucketMetricsSetCmd.Flags().String("id", "", "ID of the metrics configuration (required)")
+	bucketMetricsSetCmd.Flags().String("prefix", "", "Only count requests for keys below this prefix")
+	bucketMetricsSetCmd.Flags().StringArray("tag", []string{}, "Only count requests for objects with this tag, as key=value (repeatable)")
+	bucketMetricsSetCmd.MarkFlagRequired("id")
+
+	bucketMetricsDeleteCmd.Flags().String("id", "", "ID of the metrics configuration (required)")
+	bucketMetricsDeleteCmd.MarkFlagRequired("id")
+}
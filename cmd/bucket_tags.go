@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/models"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var bucketTagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Manage bucket tags",
+	Long: `Get or set the tags of the bucket.
+
+Bucket tags show up in the cost and usage reports once they are activated as cost
+allocation tags in the billing console, so storage costs can be split by team, project
+or environment.`,
+}
+
+var bucketTagsGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show the bucket tags",
+	Example: `  # Show the tags of the configured bucket
+  s3manager bucket tags get`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBucketTags(cmd, "bucket tags get", func(ctx context.Context, client *s3client.Client) (*models.BucketTags, error) {
+			return client.GetBucketTags(ctx)
+		})
+	},
+}
+
+var bucketTagsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Add, change or remove bucket tags",
+	Long: `Add or change the tags given with --tag and remove those given with --remove. The other
+tags of the bucket are kept unless --replace is set, which makes --tag the complete set.`,
+	Example: `  # Tag the bucket for cost allocation
+  s3manager bucket tags set --tag team=backend --tag cost-center=4711
+
+  # Remove a tag
+  s3manager bucket tags set --remove project
+
+  # Replace all tags
+  s3manager bucket tags set --replace --tag team=data`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		tagFlag, _ := cmd.Flags().GetStringArray("tag")
+		remove, _ := cmd.Flags().GetStringArray("remove")
+		replace, _ := cmd.Flags().GetBool("replace")
+
+		tags, err := parseTags(tagFlag)
+		if err != nil {
+			utils.PrintError(err, "bucket tags set")
+			return
+		}
+		if len(tags) == 0 && len(remove) == 0 && !replace {
+			utils.PrintError(fmt.Errorf("nothing to change, use --tag or --remove"), "bucket tags set")
+			return
+		}
+		runBucketTags(cmd, "bucket tags set", func(ctx context.Context, client *s3client.Client) (*models.BucketTags, error) {
+			return client.PutBucketTags(ctx, tags, remove, replace)
+		})
+	},
+}
+
+func runBucketTags(cmd *cobra.Command, command string, operation func(context.Context, *s3client.Client) (*models.BucketTags, error)) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Bucket: %s\n", getBucketName(cmd))
+	}
+
+	tags, err := operation(ctx, client)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		tags.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(tags); err != nil {
+		utils.PrintError(err, command)
+	}
+}
+
+func init() {
+	bucketTagsCmd.AddCommand(bucketTagsGetCmd)
+	bucketTagsCmd.AddCommand(bucketTagsSetCmd)
+
+	bucketTagsSetCmd.Flags().StringArray("tag", []string{}, "Tag to add or change, as key=value (repeatable)")
+	bucketTagsSetCmd.Flags().StringArray("remove", []string{}, "Key of a tag to remove (repeatable)")
+	bucketTagsSetCmd.Flags().Bool("replace", false, "Replace all tags of the bucket by the --tag ones")
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/models"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var bucketWebsiteCmd = &cobra.Command{
+	Use:   "website",
+	Short: "Manage static website hosting configuration",
+	Long: `Get, set or delete the static website hosting configuration of the bucket.
+
+The configuration consists of the index and error documents and optional redirect
+rules, or a single redirect of all requests to another host.`,
+}
+
+var bucketWebsiteGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show the website configuration",
+	Example: `  # Show website configuration of the configured bucket
+  s3manager bucket website get`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBucketWebsite(cmd, "bucket website get", func(ctx context.Context, client *s3client.Client) (*models.BucketWebsite, error) {
+			return client.GetBucketWebsite(ctx)
+		})
+	},
+}
+
+var bucketWebsiteSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Enable website hosting with the given configuration",
+	Example: `  # Serve index.html with a custom 404 page
+  s3manager bucket website set --index-document index.html --error-document 404.html
+
+  # Apply redirect rules from a JSON file
+  s3manager bucket website set --index-document index.html --routing-rules rules.json
+
+  # Redirect every request to another host
+  s3manager bucket website set --redirect-all-to www.example.com --redirect-protocol https`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		website, err := websiteFromFlags(cmd)
+		if err != nil {
+			utils.PrintError(err, "bucket website set")
+			return
+		}
+		runBucketWebsite(cmd, "bucket website set", func(ctx context.Context, client *s3client.Client) (*models.BucketWebsite, error) {
+			return client.PutBucketWebsite(ctx, website)
+		})
+	},
+}
+
+var bucketWebsiteDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Disable website hosting",
+	Example: `  # Remove the website configuration
+  s3manager bucket website delete --confirm`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		confirm, _ := cmd.Flags().GetBool("confirm")
+		if !confirm {
+			fmt.Println(i18n.Tf("This will disable static website hosting for bucket '%s'", getBucketName(cmd)))
+			ok, err := newPrompter(cmd, os.Stdout).Confirm(i18n.T("Continue?"))
+			if err != nil {
+				utils.PrintError(err, "bucket website delete")
+				return
+			}
+			if !ok {
+				fmt.Println(i18n.T("Operation cancelled."))
+				return
+			}
+		}
+		runBucketWebsite(cmd, "bucket website delete", func(ctx context.Context, client *s3client.Client) (*models.BucketWebsite, error) {
+			return client.DeleteBucketWebsite(ctx)
+		})
+	},
+}
+
+func runBucketWebsite(cmd *cobra.Command, command string, operation func(context.Context, *s3client.Client) (*models.BucketWebsite, error)) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Bucket: %s\n", getBucketName(cmd))
+	}
+
+	website, err := operation(ctx, client)
+	if err != nil {
+		utils.PrintError(err, command)
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		website.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(website); err != nil {
+		utils.PrintError(err, command)
+	}
+}
+
+func websiteFromFlags(cmd *cobra.Command) (*models.BucketWebsite, error) {
+	indexDocument, _ := cmd.Flags().GetString("index-document")
+	errorDocument, _ := cmd.Flags().GetString("error-document")
+	redirectAllTo, _ := cmd.Flags().GetString("redirect-all-to")
+	redirectProtocol, _ := cmd.Flags().GetString("redirect-protocol")
+	routingRulesFile, _ := cmd.Flags().GetString("routing-rules")
+
+	website := &models.BucketWebsite{
+		IndexDocument: indexDocument,
+		ErrorDocument: errorDocument,
+	}
+
+	if redirectAllTo != "" {
+		if cmd.Flags().Changed("index-document") || errorDocument != "" || routingRulesFile != "" {
+			return nil, fmt.Errorf("--redirect-all-to cannot be combined with document or routing rule flags")
+		}
+		website.IndexDocument = ""
+		website.RedirectAllRequestsTo = &models.WebsiteRedirect{
+			HostName: redirectAllTo,
+			Protocol: redirectProtocol,
+		}
+	}
+
+	if routingRulesFile != "" {
+		rules, err := loadRoutingRules(routingRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		website.RoutingRules = rules
+	}
+
+	return website, nil
+}
+
+func loadRoutingRules(path string) ([]models.WebsiteRoutingRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing rules: %w", err)
+	}
+
+	var rules []models.WebsiteRoutingRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse routing rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func init() {
+	bucketWebsiteCmd.AddCommand(bucketWebsiteGetCmd)
+	bucketWebsiteCmd.AddCommand(bucketWebsiteSetCmd)
+	bucketWebsiteCmd.AddCommand(bucketWebsiteDeleteCmd)
+
+	bucketWebsiteSetCmd.Flags().String("index-document", "index.html", "Suffix served for directory requests")
+	bucketWebsiteSetCmd.Flags().String("error-document", "", "Object returned for 4XX errors (optional)")
+	bucketWebsiteSetCmd.Flags().String("routing-rules", "", "JSON file with a list of redirect rules (optional)")
+	bucketWebsiteSetCmd.Flags().String("redirect-all-to", "", "Redirect every request to this host instead of serving content")
+	bucketWebsiteSetCmd.Flags().String("redirect-protocol", "", "Protocol used with --redirect-all-to (http or https)")
+
+	bucketWebsiteDeleteCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Integration tests for bucket website commands
+// These tests require a real S3 connection and are skipped by default
+// To run these tests, set the environment variable S3_INTEGRATION_TEST=true
+
+func TestBucketWebsiteGetCommand(t *testing.T) {
+	integrationEnv(t)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	bucketWebsiteGetCmd.SetArgs([]string{})
+	err := bucketWebsiteGetCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	if err != nil {
+		t.Fatalf("Bucket website get command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "enabled") {
+		t.Errorf("Output doesn't contain enabled: %s", output)
+	}
+}
+
+func TestLoadRoutingRules(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "routing-rules-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	rulesPath := filepath.Join(tempDir, "rules.json")
+	rules := `[{"condition": {"key_prefix_equals": "docs/"}, "redirect": {"replace_key_prefix_with": "documents/"}}]`
+	if err := os.WriteFile(rulesPath, []byte(rules), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+
+	loaded, err := loadRoutingRules(rulesPath)
+	if err != nil {
+		t.Fatalf("loadRoutingRules() error = %v", err)
+	}
+
+	if len(loaded) != 1 {
+		t.Fatalf("rules length = %d, want %d", len(loaded), 1)
+	}
+
+	if loaded[0].Condition.KeyPrefixEquals != "docs/" {
+		t.Errorf("KeyPrefixEquals = %s, want %s", loaded[0].Condition.KeyPrefixEquals, "docs/")
+	}
+
+	if loaded[0].Redirect.ReplaceKeyPrefixWith != "documents/" {
+		t.Errorf("ReplaceKeyPrefixWith = %s, want %s", loaded[0].Redirect.ReplaceKeyPrefixWith, "documents/")
+	}
+
+	if _, err := loadRoutingRules(filepath.Join(tempDir, "missing.json")); err == nil {
+		t.Errorf("loadRoutingRules() with missing file should return error")
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+)
+
+// addCacheFlags registers --cached and --cache-ttl for read-only commands that list objects.
+func addCacheFlags(c *cobra.Command) {
+	c.Flags().Bool("cached", false, "Reuse listings cached by an earlier run with --cached, and cache the listings of this one")
+	c.Flags().Duration("cache-ttl", 0, "How long a cached listing is used (default from LISTING_CACHE_TTL, or 15m)")
+}
+
+// useListingCache makes client read its listings from the cache with --cached.
+func useListingCache(cmd *cobra.Command, client *s3client.Client) error {
+	if cached, _ := cmd.Flags().GetBool("cached"); !cached {
+		return nil
+	}
+	ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+	if ttl < 0 {
+		return fmt.Errorf("--cache-ttl must not be negative")
+	}
+	return client.UseListingCache(ttl)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBucketInfoCached(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "a.txt", []byte("hello"), time.Now())
+	t.Setenv("LISTING_CACHE_DIR", t.TempDir())
+
+	runCommand(t, fake, "", "bucket-info", "--cached")
+	fake.PutObject("test-bucket", "b.txt", []byte("world!"), time.Now())
+
+	output := runCommand(t, fake, "", "bucket-info", "--cached")
+	if !strings.Contains(output, `"object_count": 1`) {
+		t.Errorf("bucket-info --cached did not use the cached listing:\n%s", output)
+	}
+	output = runCommand(t, fake, "", "bucket-info")
+	if !strings.Contains(output, `"object_count": 2`) {
+		t.Errorf("bucket-info without --cached did not list the bucket:\n%s", output)
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Exit statuses of check commands, following the Nagios plugin convention.
+const (
+	checkExitCritical = 2
+	checkExitUnknown  = 3
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Run monitoring checks against the bucket",
+	Long: `Run monitoring checks against the bucket.
+
+Checks print a JSON result and exit with status 0 when the check passes, 2 when it
+fails and 3 when it could not be performed, following the Nagios plugin convention,
+so they can be used directly from Nagios, cron wrappers or healthchecks.io.`,
+}
+
+func init() {
+	checkCmd.AddCommand(checkFreshnessCmd)
+}
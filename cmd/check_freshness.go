@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var checkFreshnessCmd = &cobra.Command{
+	Use:   "freshness",
+	Short: "Check that the newest object under a prefix is recent and large enough",
+	Long: `Check that the newest object under a prefix is recent and large enough.
+
+The check fails when no object matches, when the newest one is older than --max-age
+or when it is smaller than --min-size. The result is printed as JSON and the exit
+status is 0 when the check passes, 2 when it fails and 3 on errors.`,
+	Example: `  # Alert when the last database backup is older than 26 hours or under 100MB
+  s3manager check freshness --prefix backups/db --max-age 26h --min-size 100MB
+
+  # Only consider compressed dumps
+  s3manager check freshness --prefix backups/ --pattern "*.sql.gz" --max-age 24h`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCheckFreshness(cmd)
+	},
+}
+
+func runCheckFreshness(cmd *cobra.Command) error {
+	prefix, _ := cmd.Flags().GetString("prefix")
+	pattern, _ := cmd.Flags().GetString("pattern")
+	maxAge, _ := cmd.Flags().GetDuration("max-age")
+	minSizeFlag, _ := cmd.Flags().GetString("min-size")
+
+	fail := func(err error) error {
+		utils.PrintError(err, "check freshness")
+		return exitStatus(cmd, checkExitUnknown)
+	}
+
+	if maxAge < 0 {
+		return fail(fmt.Errorf("max-age must not be negative"))
+	}
+
+	var minSize int64
+	if minSizeFlag != "" {
+		size, err := utils.ParseBytes(minSizeFlag)
+		if err != nil {
+			return fail(err)
+		}
+		minSize = size
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx, cancel := operationContext(cmd, 5*time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Checking freshness of: %s\n", prefix)
+	}
+
+	result, err := client.CheckFreshness(ctx, s3client.FreshnessOptions{
+		Prefix:  prefix,
+		Pattern: pattern,
+		MaxAge:  maxAge,
+		MinSize: minSize,
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		return fail(err)
+	}
+
+	if !result.OK {
+		return exitStatus(cmd, checkExitCritical)
+	}
+	return nil
+}
+
+func init() {
+	checkFreshnessCmd.Flags().String("prefix", "", "Prefix the newest object is searched under (required)")
+	if err := checkFreshnessCmd.MarkFlagRequired("prefix"); err != nil {
+		utils.PrintError(err, "check freshness")
+	}
+	checkFreshnessCmd.Flags().String("pattern", "", "Glob matched against object names (e.g. '*.sql.gz')")
+	checkFreshnessCmd.Flags().Duration("max-age", 0, "Maximum age of the newest object (e.g. 26h), 0 to skip")
+	checkFreshnessCmd.Flags().String("min-size", "", "Minimum size of the newest object (e.g. 100MB)")
+}
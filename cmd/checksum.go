@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"slices"
+	"time"
+)
+
+var checksumCmd = &cobra.Command{
+	Use:   "checksum [key] [local-file]",
+	Short: "Verify that an object matches a local file",
+	Long: `Show the checksums of an object and optionally verify them against a local file.
+
+With only a key, the remote ETag, SHA-256 checksum, part count and part size are reported.
+With a local file, the same checksums are recomputed locally:
+- etag: MD5 for single-part uploads, or the multipart ETag computed with the upload's part size
+- sha256: full-object or composite SHA-256 checksum (requires the object to have one)
+- auto: sha256 when the object has a SHA-256 checksum, etag otherwise
+
+The part size is detected from the first part of the object unless --part-size is given.
+The command exits with status 1 when the local file does not match.`,
+	Example: `  # Show remote checksums
+  s3manager checksum backups/db.sql.gz
+
+  # Verify a local file after a migration
+  s3manager checksum backups/db.sql.gz ./db.sql.gz
+
+  # Force ETag comparison with a known part size
+  s3manager checksum backups/db.sql.gz ./db.sql.gz --algorithm etag --part-size 16MB`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChecksum(cmd, args)
+	},
+}
+
+func runChecksum(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	localPath := ""
+	if len(args) > 1 {
+		localPath = args[1]
+	}
+	algorithm, _ := cmd.Flags().GetString("algorithm")
+	partSizeFlag, _ := cmd.Flags().GetString("part-size")
+
+	if !slices.Contains([]string{s3client.ChecksumAuto, s3client.ChecksumETag, s3client.ChecksumSHA256}, algorithm) {
+		utils.PrintError(fmt.Errorf("unsupported checksum algorithm: %s", algorithm), "checksum")
+		return nil
+	}
+
+	var partSize int64
+	if partSizeFlag != "" {
+		size, err := utils.ParseBytes(partSizeFlag)
+		if err != nil {
+			utils.PrintError(err, "checksum")
+			return nil
+		}
+		partSize = size
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "checksum")
+		return nil
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Computing checksums for: %s\n", key)
+		if localPath != "" {
+			cmd.Printf("  Local file: %s\n", localPath)
+		}
+	}
+
+	result, err := client.VerifyChecksum(ctx, key, localPath, algorithm, partSize)
+	if err != nil {
+		utils.PrintError(err, "checksum")
+		return nil
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "checksum")
+		return nil
+	}
+
+	if result.Match != nil && !*result.Match {
+		return exitStatus(cmd, 1)
+	}
+	return nil
+}
+
+func init() {
+	checksumCmd.Flags().String("algorithm", s3client.ChecksumAuto, "Checksum to compare: auto, etag or sha256")
+	checksumCmd.Flags().String("part-size", "", "Multipart part size used for the upload (e.g. 16MB); detected when omitted")
+}
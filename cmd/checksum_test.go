@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Integration tests for checksum command
+// These tests require a real S3 connection and are skipped by default
+// To run these tests, set the environment variable S3_INTEGRATION_TEST=true
+
+func TestChecksumCommand(t *testing.T) {
+	integrationEnv(t)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	// Note: This test assumes that the "test-checksum/file.txt" object exists in the bucket
+	checksumCmd.SetArgs([]string{"test-checksum/file.txt"})
+	err := checksumCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	if err != nil {
+		t.Fatalf("Checksum command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "remote_etag") {
+		t.Errorf("Output doesn't contain remote_etag: %s", output)
+	}
+}
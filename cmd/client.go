@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"s3manager/config"
+	"s3manager/internal/s3client"
+)
+
+// ClientFactory creates the S3 client of a command for the configuration c, which is cfg
+// or a copy of it for another bucket.
+type ClientFactory func(c *config.Config) (*s3client.Client, error)
+
+// newClient is the factory the commands create their S3 clients with. Execute installs
+// s3client.New; tests run commands with one that connects to a fake server instead.
+var newClient ClientFactory = s3client.New
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"s3manager/config"
+	"s3manager/internal/s3client"
+	"s3manager/internal/s3fake"
+)
+
+// runCommand runs the command line args against fake with stdin as input, and returns
+// what the command wrote to stdout.
+func runCommand(t *testing.T, fake *s3fake.Server, stdin string, args ...string) string {
+	t.Helper()
+	output, err := executeCommand(t, fake, stdin, args...)
+	if err != nil {
+		t.Fatalf("execute(%v) error = %v", args, err)
+	}
+	return output
+}
+
+// executeCommand runs the command line args like runCommand, and also returns the error
+// of execute.
+func executeCommand(t *testing.T, fake *s3fake.Server, stdin string, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("S3MANAGER_CONFIG", os.DevNull)
+	t.Setenv("S3MANAGER_PROFILE", "")
+	resetCommands(rootCmd)
+
+	c := &config.Config{BucketName: "test-bucket", Settings: config.DefaultSettings(), ConfirmThresholdObjects: 1000, ConfirmThresholdBytes: 10 << 30,
+		ListingCacheDir: os.Getenv("LISTING_CACHE_DIR"), ListingCacheTTL: time.Minute}
+	factory := func(c *config.Config) (*s3client.Client, error) {
+		server := *c
+		server.ApiURL, server.AccessKey, server.SecretKey, server.Region = fake.URL(), "test", "test", "us-east-1"
+		return s3client.New(&server)
+	}
+
+	in, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(in, stdin)
+	in.Seek(0, io.SeekStart)
+	out, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedIn, savedOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = in, w
+	defer func() { os.Stdin, os.Stdout = savedIn, savedOut }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(out)
+		output <- string(data)
+	}()
+	err = execute(c, factory, args)
+	w.Close()
+	return <-output, err
+}
+
+// integrationEnv skips the test unless S3_INTEGRATION_TEST=true, and points the storage
+// settings at the bucket of the TEST_ variables for the duration of the test.
+func integrationEnv(t *testing.T) {
+	t.Helper()
+	if os.Getenv("S3_INTEGRATION_TEST") != "true" {
+		t.Skip("Skipping integration test; set S3_INTEGRATION_TEST=true to run")
+	}
+	for _, name := range []string{"BUCKET_NAME", "REGION", "API_URL", "ACCESS_KEY", "SECRET_KEY"} {
+		t.Setenv(name, os.Getenv("TEST_"+name))
+	}
+}
+
+// resetCommands restores the flags of cmd and its subcommands to their defaults and drops
+// their context, which cobra keeps from one execution to the next.
+func resetCommands(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.SetContext(nil)
+	for _, sub := range cmd.Commands() {
+		resetCommands(sub)
+	}
+}
+
+func TestBucketInfoOutput(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "a.txt", []byte("hello"), time.Now())
+	fake.PutObject("test-bucket", "b.txt", []byte("world!"), time.Now())
+
+	output := runCommand(t, fake, "", "bucket-info")
+	for _, want := range []string{`"bucket_name": "test-bucket"`, `"object_count": 2`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("bucket-info output lacks %s:\n%s", want, output)
+		}
+	}
+}
+
+func TestDeleteOldPrompt(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "logs/old.log", []byte("old"), time.Now().AddDate(0, 0, -60))
+	fake.PutObject("test-bucket", "logs/new.log", []byte("new"), time.Now())
+
+	output := runCommand(t, fake, "no\n", "delete-old", "--days", "30", "--folder", "logs")
+	if !strings.Contains(output, "Impact: 1 objects") || !strings.Contains(output, "Operation cancelled.") {
+		t.Errorf("delete-old answered no printed:\n%s", output)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 2 {
+		t.Errorf("objects after cancelling = %v, want both", keys)
+	}
+
+	output = runCommand(t, fake, "yes\n", "delete-old", "--days", "30", "--folder", "logs")
+	if !strings.Contains(output, `"deleted_count": 1`) {
+		t.Errorf("delete-old answered yes printed:\n%s", output)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 1 || keys[0] != "logs/new.log" {
+		t.Errorf("objects after deleting = %v, want logs/new.log", keys)
+	}
+
+	// --dry-run neither asks nor deletes
+	fake.PutObject("test-bucket", "logs/old.log", []byte("old"), time.Now().AddDate(0, 0, -60))
+	output = runCommand(t, fake, "", "delete-old", "--days", "30", "--folder", "logs", "--dry-run")
+	if strings.Contains(output, "Are you sure?") || !strings.Contains(output, "logs/old.log") {
+		t.Errorf("delete-old --dry-run printed:\n%s", output)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 2 {
+		t.Errorf("objects after a dry run = %v, want both", keys)
+	}
+}
+
+func TestDeleteOldSeveralFolders(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	old := time.Now().AddDate(0, 0, -60)
+	for _, key := range []string{"logs/app1/a.log", "logs/app2/a.log", "logs/app3/a.log", "logs/app4/a.log"} {
+		fake.PutObject("test-bucket", key, []byte("old"), old)
+	}
+
+	output := runCommand(t, fake, "no\n", "delete-old", "--days", "30", "--folder", "logs/app1, logs/app2", "--folder", "logs/app3")
+	if !strings.Contains(output, "in folders 'logs/app1', 'logs/app2', 'logs/app3'") || !strings.Contains(output, "Impact: 3 objects") {
+		t.Errorf("delete-old with several folders printed:\n%s", output)
+	}
+
+	output = runCommand(t, fake, "", "delete-old", "--days", "30", "--folder", "logs/app1,logs/app2,logs/app3", "--confirm")
+	if !strings.Contains(output, `"deleted_count": 3`) {
+		t.Errorf("delete-old with several folders printed:\n%s", output)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 1 || keys[0] != "logs/app4/a.log" {
+		t.Errorf("objects after deleting = %v, want logs/app4/a.log", keys)
+	}
+}
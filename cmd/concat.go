@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var concatCmd = &cobra.Command{
+	Use:   "concat <dest-key> [src-key...]",
+	Short: "Concatenate objects into one",
+	Long: `Store the source objects, one after the other, as <dest-key>, for example to merge the
+chunks of an export into a single file. With --prefix the objects under it are added in key
+order after the sources given as arguments; <dest-key> itself is skipped there.
+
+The object is assembled on the server as a multipart upload, so large sources are never
+downloaded. S3 requires every part but the last to be at least 5MB; sources smaller than
+that are downloaded and uploaded together with their neighbours. When a source changes
+while it is copied, nothing is written and the command fails.
+
+<dest-key> may be one of the sources. With --delete-sources the other sources are deleted
+(or moved to the trash with --trash) once the object is stored.`,
+	Example: `  # Merge the chunks of an export
+  s3manager concat exports/orders.csv --prefix exports/orders/ --delete-sources
+
+  # Add two objects to the end of a third
+  s3manager concat logs/all.log logs/all.log logs/monday.log logs/tuesday.log`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runConcat(cmd, args[0], args[1:])
+	},
+}
+
+func init() {
+	concatCmd.Flags().String("prefix", "", "Also concatenate the objects under this prefix, in key order")
+	concatCmd.Flags().Bool("delete-sources", false, "Delete the sources once the object is stored")
+	addDeletionFlags(concatCmd)
+}
+
+func runConcat(cmd *cobra.Command, destination string, sources []string) {
+	prefix, _ := cmd.Flags().GetString("prefix")
+	deleteSources, _ := cmd.Flags().GetBool("delete-sources")
+	applyDeletionFlags(cmd)
+
+	if len(sources) == 0 && prefix == "" {
+		utils.PrintError(fmt.Errorf("no sources: pass source keys or --prefix"), "concat")
+		return
+	}
+	destination, err := utils.ExpandTemplate(destination, time.Now())
+	if err != nil {
+		utils.PrintError(fmt.Errorf("invalid key: %w", err), "concat")
+		return
+	}
+
+	jb, err := startJob(cmd, "concat")
+	if err != nil {
+		utils.PrintError(err, "concat")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "concat")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	result, err := client.Concat(ctx, destination, sources, s3client.ConcatOptions{
+		Prefix:        prefix,
+		DeleteSources: deleteSources,
+	})
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "concat")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	jb.succeed(result)
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "concat")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Concatenated %d objects into %s (%s)\n", len(result.Sources), destination, result.SizeHuman)
+	}
+}
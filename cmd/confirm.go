@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"s3manager/internal/i18n"
+	"s3manager/pkg/utils"
+)
+
+// confirmDeletion shows the impact of a deletion described by warning and asks whether to
+// go ahead. Above CONFIRM_THRESHOLD_OBJECTS objects or CONFIRM_THRESHOLD_BYTES bytes a
+// plain "yes" is not enough and the bucket name has to be typed.
+func confirmDeletion(prompter Prompter, out io.Writer, warning, bucketName string, objects int, size int64) (bool, error) {
+	fmt.Fprintln(out, warning)
+	fmt.Fprintln(out, i18n.Tf("Impact: %d objects, %s", objects, utils.FormatBytes(size)))
+
+	if objects > cfg.ConfirmThresholdObjects || size > cfg.ConfirmThresholdBytes {
+		fmt.Fprintln(out, i18n.Tf("This is more than %d objects or %s.",
+			cfg.ConfirmThresholdObjects, utils.FormatBytes(cfg.ConfirmThresholdBytes)))
+		return prompter.ConfirmName(i18n.T("Type the bucket name to proceed"), bucketName)
+	}
+	return prompter.Confirm(i18n.T("Are you sure?"))
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"s3manager/config"
+	"strings"
+	"testing"
+)
+
+func TestConfirmDeletion(t *testing.T) {
+	original := cfg
+	cfg = &config.Config{ConfirmThresholdObjects: 100, ConfirmThresholdBytes: 1 << 20}
+	defer func() { cfg = original }()
+
+	tests := []struct {
+		name    string
+		objects int
+		size    int64
+		input   string
+		want    bool
+		prompt  string
+	}{
+		{"small deletion confirmed", 10, 1024, "yes\n", true, "Are you sure?"},
+		{"small deletion declined", 10, 1024, "no\n", false, "Are you sure?"},
+		{"many objects need the bucket name", 500, 1024, "yes\n", false, "Type the bucket name"},
+		{"large size needs the bucket name", 1, 2 << 20, "my-bucket\n", true, "Type the bucket name"},
+		{"bucket name without newline", 500, 0, "my-bucket", true, "Type the bucket name"},
+		{"wrong bucket name", 500, 0, "other-bucket\n", false, "Type the bucket name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			prompter := &linePrompter{in: bufio.NewReader(strings.NewReader(tt.input)), out: &out}
+			got, err := confirmDeletion(prompter, &out, "WARNING", "my-bucket", tt.objects, tt.size)
+			if err != nil {
+				t.Fatalf("confirmDeletion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("confirmDeletion() = %t, want %t", got, tt.want)
+			}
+			if !strings.Contains(out.String(), tt.prompt) || !strings.Contains(out.String(), "Impact:") {
+				t.Errorf("prompt = %q, want the impact and %q", out.String(), tt.prompt)
+			}
+		})
+	}
+}
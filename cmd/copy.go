@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy <source-key> <destination-key>",
+	Short: "Copy an object within the bucket",
+	Long: `Copy an object within the bucket on the server, without downloading it.
+
+--sse-c-source-key decrypts a source encrypted with a customer-provided key (SSE-C),
+--sse-c-key encrypts the copy. Copying an object onto itself with both keys rotates its
+key. Keys are files with the raw 32 bytes or their base64 encoding, or the base64 value.
+Objects larger than 5GB, which S3 cannot copy in a single request, are copied in parts
+on the server, keeping their headers and tags.`,
+	Example: `  # Copy a backup to another folder
+  s3manager copy backups/db.sql.gz archive/2024/db.sql.gz
+
+  # Rotate the SSE-C key of an object
+  s3manager copy vault/db.sql.gz vault/db.sql.gz --sse-c-source-key old.key --sse-c-key new.key`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCopy(cmd, args[0], args[1])
+	},
+}
+
+func init() {
+	addSSECustomerKeyFlag(copyCmd, "Encrypt the copy with this SSE-C key, a file or base64 of 32 bytes")
+	copyCmd.Flags().String("sse-c-source-key", "", "SSE-C key the source is encrypted with, a file or base64 of 32 bytes")
+}
+
+func runCopy(cmd *cobra.Command, source, destination string) {
+	sseKey, err := sseCustomerKey(cmd, "sse-c-key")
+	if err != nil {
+		utils.PrintError(err, "copy")
+		return
+	}
+	sourceKey, err := sseCustomerKey(cmd, "sse-c-source-key")
+	if err != nil {
+		utils.PrintError(err, "copy")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "copy")
+		return
+	}
+	client.SetSSECustomerKey(sseKey)
+
+	ctx, cancel := operationContext(cmd, 10*time.Minute)
+	defer cancel()
+
+	result, err := client.Copy(ctx, source, destination, sourceKey)
+	if err != nil {
+		utils.PrintError(err, "copy")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "copy")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Copied %s to %s\n", source, destination)
+	}
+}
@@ -1,11 +1,19 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/journal"
+	"s3manager/internal/models"
 	"s3manager/internal/s3client"
+	"s3manager/internal/storage"
 	"s3manager/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,9 +23,10 @@ var deleteOldCmd = &cobra.Command{
 	Long: `Delete files in the S3 bucket that are older than the specified number of days.
 
 The command will:
-- List all objects in the specified folder (or entire bucket if no folder specified)
-- Filter objects older than the cutoff date
-- Delete matching objects in batches
+- List all objects in the specified folders (or entire bucket if no folder specified)
+- Filter objects older than the cutoff date, and with --regex or --filter those matching
+- With --unused-for, keep objects that S3 server access logs show were read recently
+- Delete matching objects in batches of 1000, several batches in parallel
 - Return detailed information about the deletion operation
 
 WARNING: This operation is irreversible. Deleted files cannot be recovered.`,
@@ -27,11 +36,45 @@ WARNING: This operation is irreversible. Deleted files cannot be recovered.`,
   # Delete files older than 7 days from specific folder
   s3manager delete-old --days 7 --folder "logs/2025"
 
+  # Purge several folders at once, with one combined report
+  s3manager delete-old --days 30 --folder logs/app1,logs/app2 --folder logs/app3
+
   # Delete with confirmation and verbose output
   s3manager delete-old --days 30 --folder "temp" --confirm --verbose
 
   # Use different bucket
-  s3manager delete-old --days 30 --bucket my-other-bucket`,
+  s3manager delete-old --days 30 --bucket my-other-bucket
+
+  # Continue a purge that was interrupted by a crash or Ctrl-C
+  s3manager delete-old --days 90 --folder "logs" --confirm --resume
+
+  # Purge a huge prefix with 8 parallel batches, at most 20 batches per second
+  s3manager delete-old --days 90 --folder "logs" --concurrency 8 --batches-per-second 20
+
+  # Keep manifests and the LATEST marker next to the data however old they are
+  s3manager delete-old --days 30 --folder "exports" --exclude "*.json" --exclude LATEST
+
+  # Only delete dumps following the naming convention, which no glob can express
+  s3manager delete-old --days 30 --folder "backups" --regex '^backups/db-(prod|staging)-\d{8}\.sql\.gz$'
+
+  # Delete large old objects, except the JSON manifests
+  s3manager delete-old --days 30 --filter 'size>100MB and not key~"*.json"'
+
+  # Date backups by the day in their key, LastModified was reset by replication
+  s3manager delete-old --days 30 --folder "backups" --date-from-key '(\d{4}-\d{2}-\d{2})/'
+
+  # Clean up a historical range
+  s3manager delete-old --folder "logs" --newer-than 2023-01-01 --older-than 2023-07-01
+
+  # Apply a per-tag retention rule
+  s3manager delete-old --days 14 --tag-filter environment=staging
+
+  # Delete objects nobody has downloaded in half a year
+  s3manager delete-old --unused-for 180d --folder "assets" --access-log-bucket my-logs --access-log-prefix "assets-bucket/"
+
+  # Save the deletion for review, then run exactly that plan
+  s3manager delete-old --days 90 --folder "logs" --plan-out prune.json
+  s3manager apply prune.json`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runDeleteOld(cmd)
 	},
@@ -39,90 +82,369 @@ WARNING: This operation is irreversible. Deleted files cannot be recovered.`,
 
 func runDeleteOld(cmd *cobra.Command) {
 	days, _ := cmd.Flags().GetInt("days")
-	folder, _ := cmd.Flags().GetString("folder")
+	folderFlag, _ := cmd.Flags().GetStringArray("folder")
+	folders := splitPrefixes(folderFlag)
 	confirm, _ := cmd.Flags().GetBool("confirm")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	planOut, _ := cmd.Flags().GetString("plan-out")
+	tagFilter, _ := cmd.Flags().GetStringArray("tag-filter")
+	unusedForFlag, _ := cmd.Flags().GetString("unused-for")
+	excludeFlag, _ := cmd.Flags().GetStringArray("exclude")
+	dateFromKeyFlag, _ := cmd.Flags().GetString("date-from-key")
+	dateLayout, _ := cmd.Flags().GetString("date-layout")
+
+	var unusedFor time.Duration
+	if unusedForFlag != "" {
+		var err error
+		unusedFor, err = utils.ParseAge(unusedForFlag)
+		if err != nil || unusedFor <= 0 {
+			utils.PrintError(fmt.Errorf("invalid --unused-for %q, expected an age like 180d", unusedForFlag), "delete-old")
+			return
+		}
+	}
+
+	window, err := timeWindow(cmd)
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
 
-	if days <= 0 {
+	// --unused-for or --older-than alone is enough, they bound the age as well
+	if days < 0 || (days == 0 && unusedFor == 0 && window.Before.IsZero()) {
 		err := fmt.Errorf("days must be greater than 0")
 		utils.PrintError(err, "delete-old")
 		return
 	}
 
-	// Show confirmation prompt if not in confirm mode and not dry-run
+	if cmd.Flags().Changed("access-log-bucket") {
+		cfg.AccessLogBucket, _ = cmd.Flags().GetString("access-log-bucket")
+	}
+	if cmd.Flags().Changed("access-log-prefix") {
+		cfg.AccessLogPrefix, _ = cmd.Flags().GetString("access-log-prefix")
+	}
+
+	tags, err := parseTags(tagFilter)
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	exclude := append(slices.Clone(cfg.DeleteExclude), excludeFlag...)
+	if err := storage.ValidatePatterns(exclude); err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	regex, err := keyRegex(cmd)
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	objects, err := objectFilter(cmd)
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	var dateFromKey *storage.KeyDate
+	if dateFromKeyFlag != "" {
+		dateFromKey, err = storage.NewKeyDate(dateFromKeyFlag, dateLayout)
+		if err != nil {
+			utils.PrintError(err, "delete-old")
+			return
+		}
+	}
+	opts := s3client.DeleteOptions{Folders: folders, DaysOld: days, Tags: tags, UnusedFor: unusedFor, Exclude: exclude, Regex: regex, Filter: objects, DateFromKey: dateFromKey, Window: window, DryRun: dryRun}
+
+	output, err := outputFormat(cmd, outputJSON, outputJSONL)
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	if output == outputJSONL && (!dryRun || planOut != "" || !s3Backend()) {
+		utils.PrintError(fmt.Errorf("--output %s is only supported for dry runs against S3", outputJSONL), "delete-old")
+		return
+	}
+
+	applyDeletionFlags(cmd)
+
+	if !s3Backend() {
+		runDeleteOldStore(cmd, folders, days, exclude, regex, objects, dateFromKey, window)
+		return
+	}
+
+	if output == outputJSONL {
+		runDeleteOldStream(cmd, opts)
+		return
+	}
+
+	// Planning deletes nothing, the plan is reviewed and run later with "apply"
+	if planOut != "" {
+		runDeleteOldPlan(cmd, opts, planOut)
+		return
+	}
+
+	// Show confirmation prompt with the computed impact if not in confirm mode and not dry-run
 	if !confirm && !dryRun {
-		cutoffDate := time.Now().AddDate(0, 0, -days)
+		now := time.Now()
 		bucketName := getBucketName(cmd)
 
-		fmt.Printf("WARNING: This will permanently delete files older than %d days (%s) from bucket '%s'",
-			days, cutoffDate.Format("2006-01-02"), bucketName)
+		plan, err := previewDeleteOld(cmd, opts)
+		if err != nil {
+			utils.PrintError(err, "delete-old")
+			return
+		}
 
-		if folder != "" {
-			fmt.Printf(" in folder '%s'", folder)
+		warning := i18n.Tf("WARNING: This will %s files", deletionVerb())
+		if days > 0 {
+			warning += i18n.Tf(" older than %d days (%s)", days, now.AddDate(0, 0, -days).Format("2006-01-02"))
+		}
+		if unusedFor > 0 {
+			warning += i18n.Tf(" not read or modified since %s", now.Add(-unusedFor).Format("2006-01-02"))
+		}
+		warning += describeWindow(window)
+		warning += i18n.Tf(" from bucket '%s'", bucketName)
+		warning += describeFolders(folders)
+		if len(tags) > 0 {
+			warning += i18n.Tf(" tagged %s", strings.Join(tagFilter, ", "))
+		}
+		if len(exclude) > 0 {
+			warning += i18n.Tf(" except %s", strings.Join(exclude, ", "))
+		}
+		if regex != nil {
+			warning += i18n.Tf(" whose key matches %s", regex)
+		}
+		if objects != nil {
+			warning += i18n.Tf(" matching %s", objects)
+		}
+		if dateFromKey != nil {
+			warning += i18n.T(" dated by their key")
 		}
-		fmt.Println()
-		fmt.Print("Are you sure? (yes/no): ")
 
-		var response string
-		_, err := fmt.Scanln(&response)
+		ok, err := confirmDeletion(newPrompter(cmd, os.Stdout), os.Stdout, warning, bucketName, plan.TotalObjects, plan.TotalSizeBytes)
 		if err != nil {
 			utils.PrintError(err, "delete-old")
 			return
 		}
-		if response != "yes" && response != "y" && response != "YES" {
-			fmt.Println("Operation cancelled.")
+		if !ok {
+			fmt.Println(i18n.T("Operation cancelled."))
 			return
 		}
 	}
 
-	client, err := s3client.New(cfg)
+	if cmd.Flags().Changed("concurrency") {
+		cfg.DeleteConcurrency, _ = cmd.Flags().GetInt("concurrency")
+	}
+	if cmd.Flags().Changed("batches-per-second") {
+		cfg.DeleteBatchesPerSecond, _ = cmd.Flags().GetFloat64("batches-per-second")
+	}
+
+	jb, err := startJob(cmd, "delete-old")
 	if err != nil {
 		utils.PrintError(err, "delete-old")
 		return
 	}
 
-	timeout, _ := cmd.Flags().GetInt("timeout")
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "delete-old")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
 	defer cancel()
 
+	ctx, unlock, err := holdLock(ctx, cmd, client, "delete-old")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	defer unlock()
+
 	if isVerbose(cmd) {
-		cmd.Printf("Deleting files older than %d days from bucket: %s\n", days, getBucketName(cmd))
-		if folder != "" {
-			cmd.Printf("Folder: %s\n", folder)
+		if days > 0 {
+			cmd.Printf("Deleting files older than %d days from bucket: %s\n", days, getBucketName(cmd))
+		} else if !window.Before.IsZero() {
+			cmd.Printf("Deleting files modified before %s from bucket: %s\n", utils.FormatTime(window.Before), getBucketName(cmd))
+		} else {
+			cmd.Printf("Deleting unused files from bucket: %s\n", getBucketName(cmd))
+		}
+		if unusedFor > 0 {
+			cmd.Printf("Unused for: %s (access logs in s3://%s/%s)\n", unusedForFlag, cfg.AccessLogBucket, cfg.AccessLogPrefix)
+		}
+		if len(folders) > 0 {
+			cmd.Printf("Folder: %s\n", strings.Join(folders, ", "))
+		}
+		if len(tags) > 0 {
+			cmd.Printf("Tag filter: %s\n", strings.Join(tagFilter, ", "))
+		}
+		if len(exclude) > 0 {
+			cmd.Printf("Excluded: %s\n", strings.Join(exclude, ", "))
+		}
+		if regex != nil {
+			cmd.Printf("Regex: %s\n", regex)
+		}
+		if objects != nil {
+			cmd.Printf("Filter: %s\n", objects)
+		}
+		if dateFromKey != nil {
+			cmd.Printf("Date from key: %s\n", dateFromKey)
 		}
 		if dryRun {
 			cmd.Println("DRY RUN MODE: No files will actually be deleted")
 		}
 	}
 
-	result, err := client.DeleteOldFiles(ctx, folder, days, dryRun)
+	var jr *journal.Journal
+	if !dryRun {
+		params := append([]string{cfg.BucketName, storage.JoinFolders(slices.Sorted(slices.Values(folders))), strconv.Itoa(days), unusedFor.String()}, slices.Sorted(slices.Values(tagFilter))...)
+		if dateFromKey != nil {
+			params = append(params, "date-from-key", dateFromKey.String())
+		}
+		if regex != nil {
+			params = append(params, "regex", regex.String())
+		}
+		if objects != nil {
+			params = append(params, "filter", objects.String())
+		}
+		if window != (storage.TimeWindow{}) {
+			params = append(params, "window", utils.FormatTime(window.After), utils.FormatTime(window.Before))
+		}
+		jr, err = openJournal(cmd, "delete-old", params...)
+		if err != nil {
+			jb.fail(err, nil)
+			utils.PrintError(err, "delete-old")
+			return
+		}
+		if jr.Resumed() && isVerbose(cmd) {
+			cmd.Printf("Resuming from journal: %s (%d objects already deleted)\n", jr.Path(), jr.DoneCount())
+		}
+	}
+
+	opts.Journal = jr
+	result, err := client.DeleteOldFiles(ctx, opts)
+	if result != nil {
+		result.Throttling = client.Throttling()
+	}
 	if err != nil {
+		closeJournal(jr)
+		if result == nil || !result.Interrupted {
+			jb.fail(err, nil)
+			utils.PrintError(err, "delete-old")
+			return
+		}
+		jb.fail(err, result)
+	} else {
+		if err := jr.Remove(); err != nil {
+			slog.Warn("Failed to remove journal", "path", jr.Path(), "error", err)
+		}
+		jb.succeed(result)
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
 		utils.PrintError(err, "delete-old")
 		return
 	}
 
-	if err := utils.PrintJSON(result); err != nil {
+	if isVerbose(cmd) && !result.Interrupted {
+		cmd.Println("Delete operation completed successfully")
+	}
+}
+
+// runDeleteOldStream prints the objects a dry run would delete as JSON Lines while the
+// listing is still running.
+func runDeleteOldStream(cmd *cobra.Command, opts s3client.DeleteOptions) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	count, size, err := client.StreamDeleteOld(ctx, opts, func(item models.ListItem) error {
+		return utils.PrintJSONLine(item)
+	})
+	if err != nil {
 		utils.PrintError(err, "delete-old")
 		return
 	}
 
 	if isVerbose(cmd) {
-		cmd.Println("Delete operation completed successfully")
+		cmd.Printf("DRY RUN: %d objects (%s) would be deleted\n", count, utils.FormatBytes(size))
 	}
 }
 
-func init() {
-	deleteOldCmd.Flags().IntP("days", "d", 0, "Delete files older than this many days (required)")
-	err := deleteOldCmd.MarkFlagRequired("days")
+// previewDeleteOld lists what a run would delete so that the prompt can show its impact.
+func previewDeleteOld(cmd *cobra.Command, opts s3client.DeleteOptions) (*models.DeletionPlan, error) {
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	return client.PlanDeleteOld(ctx, opts)
+}
+
+func runDeleteOldPlan(cmd *cobra.Command, opts s3client.DeleteOptions, path string) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	plan, err := client.PlanDeleteOld(ctx, opts)
 	if err != nil {
 		utils.PrintError(err, "delete-old")
 		return
 	}
 
-	deleteOldCmd.Flags().StringP("folder", "f", "", "Folder/prefix to search in (optional, searches entire bucket if not specified)")
+	if err := s3client.WriteDeletionPlan(path, plan); err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+
+	if err := utils.PrintJSON(plan); err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Plan with %d objects (%s) written to %s, run it with: s3manager apply %s\n",
+			plan.TotalObjects, plan.TotalSizeHuman, path, path)
+	}
+}
+
+func init() {
+	deleteOldCmd.Flags().IntP("days", "d", 0, "Delete files older than this many days (required unless --unused-for or --older-than is given)")
+
+	deleteOldCmd.Flags().StringArrayP("folder", "f", []string{}, "Folder/prefix to search in, repeatable or comma-separated (optional, searches entire bucket if not specified)")
 	deleteOldCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
 	deleteOldCmd.Flags().Bool("dry-run", false, "Show what would be deleted without actually deleting")
-	deleteOldCmd.Flags().Int("timeout", 1800, "Timeout in seconds for the operation (default: 30 minutes)")
+	deleteOldCmd.Flags().StringArray("tag-filter", []string{}, "Only delete objects carrying this tag, as key=value (repeatable, all must match)")
+	deleteOldCmd.Flags().StringArray("exclude", []string{}, "Never delete objects matching this glob, e.g. '*.json', in addition to DELETE_EXCLUDE (repeatable)")
+	addFilterFlag(deleteOldCmd, "Only delete objects matching this expression, e.g. 'size>100MB and not key~\"*.json\"'")
+	addRegexFlag(deleteOldCmd, "Only delete objects whose full key matches this regular expression")
+	deleteOldCmd.Flags().String("date-from-key", "", "Regular expression whose first group is the date of an object, used instead of LastModified (e.g. '(\\d{4}-\\d{2}-\\d{2})/')")
+	deleteOldCmd.Flags().String("date-layout", storage.DefaultKeyDateLayout, "Go time layout of the date matched by --date-from-key")
+	addTimeWindowFlags(deleteOldCmd)
+	addOutputFlag(deleteOldCmd)
+	deleteOldCmd.MarkFlagsMutuallyExclusive("days", "older-than")
+	deleteOldCmd.Flags().String("unused-for", "", "Only delete objects nobody has read or written for this long, e.g. 180d (needs server access logs)")
+	deleteOldCmd.Flags().String("access-log-bucket", "", "Bucket holding the server access logs of this bucket (default from ACCESS_LOG_BUCKET)")
+	deleteOldCmd.Flags().String("access-log-prefix", "", "Key prefix of the server access logs (default from ACCESS_LOG_PREFIX)")
+	deleteOldCmd.Flags().String("plan-out", "", "Write the objects that would be deleted to this plan file instead of deleting them")
+	deleteOldCmd.Flags().Int("max-delete", 0, "Abort without deleting anything if more objects match, 0 for no limit (default from MAX_DELETE)")
+	addDeletionFlags(deleteOldCmd)
+	deleteOldCmd.Flags().Int("concurrency", 4, "Number of delete batches (1000 keys each) sent in parallel (default from DELETE_CONCURRENCY)")
+	deleteOldCmd.Flags().Bool("resume", false, "Continue an interrupted run from its journal instead of re-scanning")
+	deleteOldCmd.Flags().String("journal", "", "Journal file path (default: per-operation file in the user cache directory)")
+	deleteOldCmd.Flags().Float64("batches-per-second", 0, "Maximum delete batches per second, 0 for unlimited (default from DELETE_BATCHES_PER_SECOND)")
 
 	deleteOldCmd.SetUsageTemplate(`Usage:{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
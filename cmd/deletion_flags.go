@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/i18n"
+)
+
+// addDeletionFlags registers the safety flags shared by commands that delete objects.
+func addDeletionFlags(c *cobra.Command) {
+	c.Flags().StringArray("protect", []string{}, "Prefix that must never be deleted, in addition to PROTECTED_PREFIXES (repeatable)")
+	c.Flags().Bool("allow-protected", false, "Also delete objects under protected prefixes")
+	c.Flags().Bool("trash", false, "Move objects to the trash folder (TRASH_PREFIX/<date>/) instead of deleting them")
+}
+
+// applyDeletionFlags applies --max-delete, the --protect prefixes, the --allow-protected
+// override and --trash to the configuration.
+func applyDeletionFlags(cmd *cobra.Command) {
+	if cmd.Flags().Changed("max-delete") {
+		cfg.MaxDelete, _ = cmd.Flags().GetInt("max-delete")
+	}
+	protect, _ := cmd.Flags().GetStringArray("protect")
+	cfg.ProtectedPrefixes = append(cfg.ProtectedPrefixes, protect...)
+	cfg.AllowProtected, _ = cmd.Flags().GetBool("allow-protected")
+	cfg.UseTrash, _ = cmd.Flags().GetBool("trash")
+}
+
+// deletionVerb describes what happens to deleted objects in confirmation prompts.
+func deletionVerb() string {
+	if cfg.UseTrash {
+		return i18n.T("move to the trash")
+	}
+	return i18n.T("permanently delete")
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy [dir]",
+	Short: "Deploy a built static website to S3",
+	Long: `Deploy a built static website directory to the S3 bucket.
+
+The command will:
+- Upload new and changed files (unchanged files are detected by ETag and skipped)
+- Set the Content-Type and Cache-Control headers based on the file extension
+- Optionally pre-compress text assets with gzip or brotli
+- Upload assets before HTML pages so that pages never reference missing files
+- Optionally delete remote files that no longer exist locally
+- Optionally invalidate the changed paths in CloudFront or a generic CDN purge webhook
+
+HTML files are served with "public, max-age=0, must-revalidate" and all other files
+with "public, max-age=86400" unless overridden with --cache-control.`,
+	Example: `  # Deploy a site to the bucket root
+  s3manager deploy ./dist
+
+  # Deploy to a prefix and remove files that were deleted locally
+  s3manager deploy ./public --destination "site" --delete
+
+  # Pre-compress text assets with brotli
+  s3manager deploy ./dist --compress br
+
+  # Long-lived caching for fingerprinted assets
+  s3manager deploy ./dist --cache-control ".css=public, max-age=31536000, immutable" \
+    --cache-control ".js=public, max-age=31536000, immutable"
+
+  # Invalidate changed paths in the CloudFront distribution from config
+  s3manager deploy ./dist --invalidate
+
+  # See what would change without uploading
+  s3manager deploy ./dist --delete --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDeploy(cmd, args)
+	},
+}
+
+func runDeploy(cmd *cobra.Command, args []string) {
+	sourceDir := args[0]
+	destination, _ := cmd.Flags().GetString("destination")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	deleteRemoved, _ := cmd.Flags().GetBool("delete")
+	compression, _ := cmd.Flags().GetString("compress")
+	compressExt, _ := cmd.Flags().GetStringSlice("compress-ext")
+	cacheControlFlag, _ := cmd.Flags().GetStringArray("cache-control")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	invalidate, _ := cmd.Flags().GetBool("invalidate")
+	distributionID, _ := cmd.Flags().GetString("distribution-id")
+
+	if compression == "none" {
+		compression = s3client.CompressionNone
+	}
+
+	cacheControl, err := parseCacheControlOverrides(cacheControlFlag)
+	if err != nil {
+		utils.PrintError(err, "deploy")
+		return
+	}
+
+	if !isDirectory(sourceDir) {
+		utils.PrintError(fmt.Errorf("deploy source must be a directory: %s", sourceDir), "deploy")
+		return
+	}
+
+	// Show operation summary if not in confirm mode and not dry-run
+	if !confirm && !dryRun {
+		fmt.Println(i18n.T("Deploy operation summary:"))
+		fmt.Println(i18n.Tf("Bucket: %s", getBucketName(cmd)))
+		fmt.Println(i18n.Tf("Source: %s", sourceDir))
+		fmt.Println(i18n.Tf("Destination: %s", getDestinationDisplay(destination)))
+		if compression != s3client.CompressionNone {
+			fmt.Println(i18n.Tf("Compression: %s", compression))
+		}
+		if deleteRemoved {
+			fmt.Println(i18n.Tf("WARNING: remote files missing from '%s' will be deleted", sourceDir))
+		}
+
+		ok, err := newPrompter(cmd, os.Stdout).Confirm(i18n.T("Continue with deploy?"))
+		if err != nil {
+			utils.PrintError(err, "deploy")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Deploy cancelled."))
+			return
+		}
+	}
+
+	applyDeletionFlags(cmd)
+
+	jb, err := startJob(cmd, "deploy")
+	if err != nil {
+		utils.PrintError(err, "deploy")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "deploy")
+		return
+	}
+	defer showFileProgress(cmd, client)()
+
+	if invalidate && !client.CDNConfigured(distributionID) {
+		err := fmt.Errorf("--invalidate requires --distribution-id, CLOUDFRONT_DISTRIBUTION_ID or CDN_PURGE_URL")
+		jb.fail(err, nil)
+		utils.PrintError(err, "deploy")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	ctx, unlock, err := holdLock(ctx, cmd, client, "deploy")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "deploy")
+		return
+	}
+	defer unlock()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Starting deploy operation...\n")
+		cmd.Printf("  Source: %s\n", sourceDir)
+		cmd.Printf("  Destination: %s\n", getDestinationDisplay(destination))
+		if dryRun {
+			cmd.Println("  DRY RUN MODE: No files will actually be uploaded or deleted")
+		}
+	}
+
+	result, err := client.DeploySite(ctx, sourceDir, destination, s3client.DeployOptions{
+		CacheControl:       cacheControl,
+		Compression:        compression,
+		CompressExtensions: normalizeExtensions(compressExt),
+		DeleteRemoved:      deleteRemoved,
+		DryRun:             dryRun,
+		Concurrency:        concurrency,
+	})
+	if err != nil && (result == nil || !result.Interrupted) {
+		jb.fail(err, nil)
+		utils.PrintError(err, "deploy")
+		return
+	}
+
+	// An interrupted deploy is reported as is; its invalidation is left to the next run
+	if invalidate && !dryRun && !result.Interrupted {
+		changed := make([]string, 0, len(result.Uploaded)+len(result.DeletedFiles))
+		for _, item := range result.Uploaded {
+			changed = append(changed, item.RemotePath)
+		}
+		changed = append(changed, result.DeletedFiles...)
+
+		if len(changed) > 0 {
+			paths := s3client.InvalidationPaths(changed, destination)
+			if isVerbose(cmd) {
+				cmd.Printf("Invalidating %d CDN paths\n", len(paths))
+			}
+			invalidation, err := client.InvalidateCDN(ctx, distributionID, paths)
+			if err != nil {
+				// The deploy itself succeeded, so the failure is reported in the result instead
+				slog.Warn("CDN invalidation failed", "error", err)
+			}
+			result.CDNInvalidation = invalidation
+		}
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	result.Throttling = client.Throttling()
+
+	if err != nil {
+		jb.fail(err, result)
+	} else {
+		jb.succeed(result)
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "deploy")
+		return
+	}
+
+	if isVerbose(cmd) && !result.Interrupted {
+		cmd.Printf("Deploy completed: %d uploaded, %d unchanged, %d deleted\n",
+			result.UploadedCount, result.SkippedCount, result.DeletedCount)
+	}
+}
+
+// parseCacheControlOverrides turns ".ext=value" pairs into a map keyed by lower-case extension.
+func parseCacheControlOverrides(values []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(values))
+	for _, value := range values {
+		ext, header, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(ext) == "" || strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("invalid cache-control override %q, expected '.ext=value'", value)
+		}
+		overrides[normalizeExtension(ext)] = strings.TrimSpace(header)
+	}
+	return overrides, nil
+}
+
+func normalizeExtensions(extensions []string) []string {
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		normalized = append(normalized, normalizeExtension(ext))
+	}
+	return normalized
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+func init() {
+	deployCmd.Flags().StringP("destination", "d", "", "Destination folder in S3 bucket (optional)")
+	deployCmd.Flags().Bool("delete", false, "Delete remote files that no longer exist in the source directory")
+	deployCmd.Flags().Int("max-delete", 0, "With --delete, abort before uploading if more remote files would be deleted, 0 for no limit (default from MAX_DELETE)")
+	addDeletionFlags(deployCmd)
+	deployCmd.Flags().String("compress", "none", "Pre-compress text assets: none, gzip or br")
+	deployCmd.Flags().StringSlice("compress-ext", s3client.DefaultCompressExtensions(), "File extensions to pre-compress")
+	deployCmd.Flags().StringArray("cache-control", []string{}, "Cache-Control override per extension (e.g. '.css=public, max-age=31536000')")
+	deployCmd.Flags().Int("concurrency", 8, "Number of files uploaded in parallel")
+	deployCmd.Flags().Bool("invalidate", false, "Invalidate changed paths in the CDN after the deploy")
+	deployCmd.Flags().String("distribution-id", "", "CloudFront distribution ID (overrides CLOUDFRONT_DISTRIBUTION_ID)")
+	deployCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+	deployCmd.Flags().Bool("dry-run", false, "Show what would be uploaded and deleted without changing the bucket")
+}
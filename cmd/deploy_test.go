@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Integration tests for deploy command
+// These tests require a real S3 connection and are skipped by default
+// To run these tests, set the environment variable S3_INTEGRATION_TEST=true
+
+func TestDeployCommand(t *testing.T) {
+	integrationEnv(t)
+
+	siteDir, err := os.MkdirTemp("", "deploy-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(siteDir)
+
+	if err := os.WriteFile(filepath.Join(siteDir, "index.html"), []byte("<html></html>"), 0644); err != nil {
+		t.Fatalf("Failed to write index.html: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(siteDir, "app.css"), []byte("body{}"), 0644); err != nil {
+		t.Fatalf("Failed to write app.css: %v", err)
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	deployCmd.SetArgs([]string{
+		siteDir,
+		"--destination", "test-deploy",
+		"--dry-run",
+	})
+	err = deployCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	if err != nil {
+		t.Fatalf("Deploy command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "test-deploy/index.html") {
+		t.Errorf("Output doesn't contain index.html: %s", output)
+	}
+}
+
+func TestParseCacheControlOverrides(t *testing.T) {
+	overrides, err := parseCacheControlOverrides([]string{
+		".css=public, max-age=31536000",
+		"JS = no-cache",
+	})
+	if err != nil {
+		t.Fatalf("parseCacheControlOverrides() error = %v", err)
+	}
+
+	if overrides[".css"] != "public, max-age=31536000" {
+		t.Errorf(".css = %q, want %q", overrides[".css"], "public, max-age=31536000")
+	}
+
+	if overrides[".js"] != "no-cache" {
+		t.Errorf(".js = %q, want %q", overrides[".js"], "no-cache")
+	}
+
+	for _, invalid := range []string{"no-equals-sign", ".css=", "=no-cache"} {
+		if _, err := parseCacheControlOverrides([]string{invalid}); err == nil {
+			t.Errorf("parseCacheControlOverrides(%q) should return error", invalid)
+		}
+	}
+}
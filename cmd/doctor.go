@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check which S3 operations the current credentials may perform",
+	Long: `Call every S3 API used by the commands and report which of them the current
+credentials and provider allow, so permission problems show up before a scheduled job
+fails.
+
+Write checks use a small probe object under .s3manager/doctor/ (below --prefix) that is
+deleted again afterwards; --read-only skips them. Each check names the IAM permission it
+needs and the commands that use it. Optional operations only affect single features and
+do not fail the check.
+
+The command exits with status 1 when a required operation is denied or fails.`,
+	Example: `  # Check the configured credentials
+  s3manager doctor
+
+  # Credentials limited to a prefix
+  s3manager doctor --prefix backups/
+
+  # Do not write to the bucket
+  s3manager doctor --read-only`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(cmd)
+	},
+}
+
+func runDoctor(cmd *cobra.Command) error {
+	prefix, _ := cmd.Flags().GetString("prefix")
+	readOnly, _ := cmd.Flags().GetBool("read-only")
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "doctor")
+		return nil
+	}
+
+	ctx, cancel := operationContext(cmd, 5*time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Checking permissions on bucket: %s\n", getBucketName(cmd))
+	}
+
+	result, err := client.Doctor(ctx, s3client.DoctorOptions{Prefix: prefix, ReadOnly: readOnly})
+	if err != nil {
+		utils.PrintError(err, "doctor")
+		return nil
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "doctor")
+		return nil
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("%d checks passed, %d failed\n", result.Passed, result.Failed)
+	}
+
+	if !result.OK {
+		return exitStatus(cmd, 1)
+	}
+	return nil
+}
+
+func init() {
+	doctorCmd.Flags().String("prefix", "", "Prefix under which the probe object is written, for credentials limited to a prefix")
+	doctorCmd.Flags().Bool("read-only", false, "Skip the checks that write to the bucket")
+}
@@ -1,92 +1,234 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/models"
 	"s3manager/internal/s3client"
+	"s3manager/internal/signing"
 	"s3manager/pkg/utils"
-	"slices"
 	"strings"
 	"time"
 )
 
 var downloadCmd = &cobra.Command{
-	Use:   "download [folder]",
-	Short: "Download the latest file from a specific folder",
+	Use:   "download <folder>...",
+	Short: "Download the latest file, or all files, from a specific folder",
 	Long: `Download the latest file from a specific folder in an S3 bucket.
 
 This command lists all files in the specified folder, sorts them by last modified date,
 and downloads the most recent file to the specified destination path.
 
-If no destination is specified, the file will be downloaded to the current directory.`,
+If no destination is specified, the file will be downloaded to the current directory.
+
+--recursive downloads every file below the folder instead, recreating its subfolders in
+the destination. --flatten puts all files directly into the destination. --on-conflict
+decides about local files that already exist, or keys that flatten to the same name:
+overwrite them (default), skip them, or rename the download to name-1.ext. The result
+lists the directories that were created and the files that were skipped.
+
+--regex limits the download to keys matching a regular expression: the latest matching
+file, or with --recursive every matching file.
+
+--if-none-match and --if-modified-since skip the download when the latest file still has
+the given ETag or was not modified after the given date. The result is then marked with
+"not_modified": true and carries the current ETag for the next run.
+
+--verify-signature checks the downloaded file against the signature <key>.sig uploaded
+with upload --sign, using SIGNATURE_METHOD and SIGNATURE_PUBLIC_KEY. A file that is not
+signed or fails the check is removed again. Signatures are never picked as the latest file.
+
+Files are written to <file>.tmp-<random> next to their destination and renamed into place
+once complete, so programs watching the directory never see a partial file.
+--verify-checksum also compares the checksum of the temporary file with the object first.
+
+Several folders, as arguments or comma-separated, are downloaded in one run into
+subdirectories of the destination named after them, with one combined result.
+
+Files uploaded with --sse-c-key can only be downloaded with the same key.`,
 	Example: `  # Download the latest file from a folder
   s3manager download backups/
 
   # Download to a specific destination
   s3manager download logs/ --destination /tmp/downloads/
 
+  # Download a whole folder tree, keeping files that already exist locally
+  s3manager download reports/2024/ --recursive --on-conflict skip --destination /srv/reports
+
+  # Latest file of three folders, into /restore/logs/app1, /restore/logs/app2, ...
+  s3manager download logs/app1/ logs/app2/,logs/app3/ --destination /restore
+
+  # Latest production or staging dump, by a regular expression on the key
+  s3manager download backups/ --regex '^backups/db-(prod|staging)-\d{8}\.sql\.gz$'
+
   # Download from a different bucket
   s3manager download data/ --bucket my-other-bucket
 
   # Verbose download with progress
-  s3manager download archives/ --verbose`,
-	Args: cobra.ExactArgs(1),
+  s3manager download archives/ --verbose
+
+  # Show which file would be downloaded and where, without downloading it
+  s3manager download backups/ --destination /restore --dry-run
+
+  # Only fetch the config again when it changed since the last poll
+  s3manager download config/ --confirm --if-none-match 9b2cf535f27731c974343645a3985328
+
+  # Only accept a backup signed by a key in the given keyring
+  SIGNATURE_PUBLIC_KEY=/etc/s3manager/backup-signing.gpg s3manager download backups/ --verify-signature`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		runDownload(cmd, args)
 	},
 }
 
 func runDownload(cmd *cobra.Command, args []string) {
-	folder := args[0]
+	folders := splitPrefixes(args)
+	folder := strings.Join(folders, ",")
+	if len(folders) == 0 {
+		folder, folders = "", []string{""}
+	}
 	destination, _ := cmd.Flags().GetString("destination")
 	confirm, _ := cmd.Flags().GetBool("confirm")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	recursive, _ := cmd.Flags().GetBool("recursive")
+
+	opts, err := downloadConditions(cmd)
+	if err != nil {
+		utils.PrintError(err, "download")
+		return
+	}
+	sseKey, err := sseCustomerKey(cmd, "sse-c-key")
+	if err != nil {
+		utils.PrintError(err, "download")
+		return
+	}
+	if err := folderDownloadOptions(cmd, recursive, &opts); err != nil {
+		utils.PrintError(err, "download")
+		return
+	}
+	if len(folders) > 1 {
+		if err := severalFoldersDownload(cmd); err != nil {
+			utils.PrintError(err, "download")
+			return
+		}
+	}
+	opts.VerifyChecksum, _ = cmd.Flags().GetBool("verify-checksum")
+	if opts.Regex, err = keyRegex(cmd); err != nil {
+		utils.PrintError(err, "download")
+		return
+	}
+	if verify, _ := cmd.Flags().GetBool("verify-signature"); verify {
+		if opts.Verifier, err = signing.NewVerifier(cfg); err != nil {
+			utils.PrintError(err, "download")
+			return
+		}
+	}
 
 	// If destination is empty, use current directory
 	if destination == "" {
 		destination = "."
 	}
 
-	// Show operation summary if not in confirm mode
-	if !confirm {
+	// Show operation summary if not in confirm mode and not dry-run
+	if !confirm && !dryRun {
 		bucketName := getBucketName(cmd)
 
-		fmt.Printf("Download operation summary:\n")
-		fmt.Printf("Bucket: %s\n", bucketName)
-		fmt.Printf("Folder: %s\n", folder)
-		fmt.Printf("Destination: %s\n", destination)
+		fmt.Println(i18n.T("Download operation summary:"))
+		fmt.Println(i18n.Tf("Bucket: %s", bucketName))
+		fmt.Println(i18n.Tf("Folder: %s", folder))
+		fmt.Println(i18n.Tf("Destination: %s", destination))
+		if recursive {
+			fmt.Println(i18n.T("Recursive: true"))
+		}
 
-		fmt.Print("Continue with download? (y/N): ")
-		var response string
-		_, err := fmt.Scanln(&response)
+		ok, err := newPrompter(cmd, os.Stdout).Confirm(i18n.T("Continue with download?"))
 		if err != nil {
 			utils.PrintError(err, "download")
 			return
 		}
-		if !slices.Contains([]string{"y", "yes"}, strings.ToLower(response)) {
-			fmt.Println("Download cancelled.")
+		if !ok {
+			fmt.Println(i18n.T("Download cancelled."))
 			return
 		}
 	}
 
-	client, err := s3client.New(cfg)
+	if !s3Backend() {
+		runDownloadStore(cmd, folder, destination)
+		return
+	}
+
+	jb, err := startJob(cmd, "download")
 	if err != nil {
 		utils.PrintError(err, "download")
 		return
 	}
 
-	timeout, _ := cmd.Flags().GetInt("timeout")
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "download")
+		return
+	}
+	defer showFileProgress(cmd, client)()
+	client.SetSSECustomerKey(sseKey)
+
+	ctx, cancel := operationContext(cmd, time.Hour)
 	defer cancel()
 
+	ctx, unlock, err := holdLock(ctx, cmd, client, "download")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "download")
+		return
+	}
+	defer unlock()
+
 	if isVerbose(cmd) {
 		cmd.Printf("Starting download operation...\n")
 		cmd.Printf("  Folder: %s\n", folder)
 		cmd.Printf("  Destination: %s\n", destination)
+		if opts.Regex != nil {
+			cmd.Printf("  Regex: %s\n", opts.Regex)
+		}
+		if dryRun {
+			cmd.Println("  DRY RUN MODE: No files will actually be downloaded")
+		}
+	}
+
+	if dryRun {
+		var plan *models.TransferPlan
+		if len(folders) > 1 {
+			plan, err = client.PlanDownloadFolders(ctx, folders, destination, recursive, opts)
+		} else if recursive {
+			plan, err = client.PlanDownloadFolder(ctx, folder, destination, opts)
+		} else {
+			plan, err = client.PlanDownload(ctx, folder, destination, opts)
+		}
+		if err != nil {
+			utils.PrintError(err, "download")
+			return
+		}
+		if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+			plan.BucketName = bucketFlag
+		}
+		if err := utils.PrintJSON(plan); err != nil {
+			utils.PrintError(err, "download")
+		}
+		return
 	}
 
-	result, err := client.DownloadLatestFile(ctx, folder, destination)
+	var result *models.DownloadResult
+	if len(folders) > 1 {
+		result, err = client.DownloadFolders(ctx, folders, destination, recursive, opts)
+	} else if recursive {
+		result, err = client.DownloadFolder(ctx, folder, destination, opts)
+	} else {
+		result, err = client.DownloadLatestFile(ctx, folder, destination, opts)
+	}
 	if err != nil {
+		jb.fail(err, nil)
 		utils.PrintError(err, "download")
 		return
 	}
@@ -94,6 +236,8 @@ func runDownload(cmd *cobra.Command, args []string) {
 	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
 		result.BucketName = bucketFlag
 	}
+	result.Throttling = client.Throttling()
+	jb.succeed(result)
 
 	if err := utils.PrintJSON(result); err != nil {
 		utils.PrintError(err, "download")
@@ -101,15 +245,91 @@ func runDownload(cmd *cobra.Command, args []string) {
 	}
 
 	if isVerbose(cmd) {
+		if result.NotModified {
+			cmd.Printf("Not modified, skipped: %s\n", result.Items[0].RemotePath)
+			return
+		}
 		cmd.Println("Download operation completed successfully")
+		if recursive || len(folders) > 1 {
+			cmd.Printf("Downloaded %d files, skipped %d\n", result.TotalFiles, len(result.SkippedFiles))
+			return
+		}
 		cmd.Printf("Downloaded file: %s\n", result.Items[0].LocalPath)
+		if result.Items[0].SignatureVerified {
+			cmd.Println("Signature verified")
+		}
+	}
+}
+
+// downloadConditions reads the flags that make a download conditional.
+func downloadConditions(cmd *cobra.Command) (s3client.DownloadOptions, error) {
+	ifNoneMatch, _ := cmd.Flags().GetString("if-none-match")
+	ifModifiedSince, _ := cmd.Flags().GetString("if-modified-since")
+
+	opts := s3client.DownloadOptions{IfNoneMatch: ifNoneMatch}
+	if ifModifiedSince != "" {
+		t, err := utils.ParseDate(ifModifiedSince)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --if-modified-since: %w", err)
+		}
+		opts.IfModifiedSince = t
+	}
+	return opts, nil
+}
+
+// folderDownloadOptions reads the flags of recursive downloads into opts. They need
+// --recursive, which in turn ignores the conditions of single-file downloads.
+func folderDownloadOptions(cmd *cobra.Command, recursive bool, opts *s3client.DownloadOptions) error {
+	opts.Flatten, _ = cmd.Flags().GetBool("flatten")
+	opts.OnConflict, _ = cmd.Flags().GetString("on-conflict")
+	if !recursive {
+		for _, name := range []string{"flatten", "on-conflict"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s needs --recursive", name)
+			}
+		}
+		return nil
+	}
+
+	for _, name := range []string{"if-none-match", "if-modified-since"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --recursive", name)
+		}
+	}
+	switch opts.OnConflict {
+	case s3client.ConflictOverwrite, s3client.ConflictSkip, s3client.ConflictRename:
+		return nil
+	}
+	return fmt.Errorf("invalid --on-conflict %q, expected overwrite, skip or rename", opts.OnConflict)
+}
+
+// severalFoldersDownload checks the flags of a download of several folders, which is
+// only supported against S3 and cannot be conditional.
+func severalFoldersDownload(cmd *cobra.Command) error {
+	if !s3Backend() {
+		return fmt.Errorf("downloading several folders is only supported against S3")
+	}
+	for _, name := range []string{"if-none-match", "if-modified-since"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with several folders", name)
+		}
 	}
+	return nil
 }
 
 func init() {
 	downloadCmd.Flags().StringP("destination", "d", "", "Local destination path (default: current directory)")
 	downloadCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
-	downloadCmd.Flags().Int("timeout", 3600, "Timeout in seconds for the operation (default: 1 hour)")
+	downloadCmd.Flags().Bool("dry-run", false, "Show what would be downloaded without actually downloading")
+	downloadCmd.Flags().String("if-none-match", "", "Skip the download when the latest file still has this ETag")
+	addSSECustomerKeyFlag(downloadCmd, "Decrypt a file uploaded with --sse-c-key, a file or base64 of 32 bytes")
+	downloadCmd.Flags().Bool("verify-checksum", false, "Compare the checksum of each downloaded file with the object before moving it into place")
+	downloadCmd.Flags().Bool("verify-signature", false, "Check the downloaded file against its detached signature <key>.sig and remove it when the check fails")
+	addRegexFlag(downloadCmd, "Only download objects whose full key matches this regular expression")
+	downloadCmd.Flags().BoolP("recursive", "r", false, "Download every file below the folder, recreating its subfolders")
+	downloadCmd.Flags().Bool("flatten", false, "With --recursive, put all files directly into the destination")
+	downloadCmd.Flags().String("on-conflict", s3client.ConflictOverwrite, "With --recursive, what to do about files that already exist: overwrite, skip or rename")
+	downloadCmd.Flags().String("if-modified-since", "", "Skip the download when the latest file was not modified after this date, as 2024-01-01 or RFC3339")
 
 	downloadCmd.SetUsageTemplate(`Usage:{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
@@ -12,9 +12,7 @@ import (
 // To run these tests, set the environment variable S3_INTEGRATION_TEST=true
 
 func TestDownloadCommand(t *testing.T) {
-	if os.Getenv("S3_INTEGRATION_TEST") != "true" {
-		t.Skip("Skipping integration test; set S3_INTEGRATION_TEST=true to run")
-	}
+	integrationEnv(t)
 
 	// Create a temporary directory to download files to
 	tempDir, err := os.MkdirTemp("", "download-test-*")
@@ -23,20 +21,6 @@ func TestDownloadCommand(t *testing.T) {
 	}
 	defer os.RemoveAll(tempDir)
 
-	// Set environment variables for S3 connection
-	os.Setenv("BUCKET_NAME", os.Getenv("TEST_BUCKET_NAME"))
-	os.Setenv("REGION", os.Getenv("TEST_REGION"))
-	os.Setenv("API_URL", os.Getenv("TEST_API_URL"))
-	os.Setenv("ACCESS_KEY", os.Getenv("TEST_ACCESS_KEY"))
-	os.Setenv("SECRET_KEY", os.Getenv("TEST_SECRET_KEY"))
-	defer func() {
-		os.Unsetenv("BUCKET_NAME")
-		os.Unsetenv("REGION")
-		os.Unsetenv("API_URL")
-		os.Unsetenv("ACCESS_KEY")
-		os.Unsetenv("SECRET_KEY")
-	}()
-
 	// Capture stdout
 	oldStdout := os.Stdout
 	r, w, _ := os.Pipe()
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "React to S3 event notifications",
+	Long: `React to S3 event notifications delivered through SQS.
+
+Configure the bucket to send event notifications to an SQS queue (directly or through
+SNS) and run "events listen" as a lightweight automation worker.`,
+}
+
+func init() {
+	eventsCmd.AddCommand(eventsListenCmd)
+}
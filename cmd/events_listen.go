@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/models"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"strings"
+)
+
+var eventsListenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Consume S3 event notifications from SQS and run actions",
+	Long: `Consume S3 event notifications from an SQS queue and run actions for every event.
+
+Available actions, run in this order for each accepted event:
+- --download-to: download the object, keeping its key as relative path
+- --tag: merge tags into the object's tags
+- --replicate-to: server-side copy the object to another bucket (and prefix)
+- --webhook: POST the event as JSON to a URL
+
+Each handled event is printed as JSON. A message is deleted from the queue only when
+all its actions succeeded, so failed events are retried after the visibility timeout
+and end up in the queue's dead-letter queue if one is configured.
+
+The command runs until interrupted with Ctrl-C or SIGTERM, or processes a single batch
+with --once.`,
+	Example: `  # Download every new object under uploads/ to a local directory
+  s3manager events listen --queue-url https://sqs.eu-west-1.amazonaws.com/123456789012/uploads \
+    --prefix uploads/ --download-to /srv/incoming
+
+  # Tag new objects and copy them to a backup bucket
+  s3manager events listen --queue-url "$QUEUE_URL" --tag status=received \
+    --replicate-to my-backup-bucket/replica/
+
+  # Forward all events, including deletions, to a webhook
+  s3manager events listen --queue-url "$QUEUE_URL" --event "" --webhook https://example.com/hooks/s3`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runEventsListen(cmd)
+	},
+}
+
+func runEventsListen(cmd *cobra.Command) {
+	queueURL, _ := cmd.Flags().GetString("queue-url")
+	sqsEndpoint, _ := cmd.Flags().GetString("sqs-endpoint")
+	eventPrefix, _ := cmd.Flags().GetString("event")
+	keyPrefix, _ := cmd.Flags().GetString("prefix")
+	downloadDir, _ := cmd.Flags().GetString("download-to")
+	tagFlag, _ := cmd.Flags().GetStringArray("tag")
+	replicateTo, _ := cmd.Flags().GetString("replicate-to")
+	webhook, _ := cmd.Flags().GetString("webhook")
+	once, _ := cmd.Flags().GetBool("once")
+	wait, _ := cmd.Flags().GetInt32("wait")
+	visibilityTimeout, _ := cmd.Flags().GetInt32("visibility-timeout")
+
+	tags, err := parseTags(tagFlag)
+	if err != nil {
+		utils.PrintError(err, "events listen")
+		return
+	}
+
+	replicateBucket, replicatePrefix := s3client.SplitBucketPath(replicateTo)
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "events listen")
+		return
+	}
+
+	// A worker runs until stopped unless --timeout is given explicitly
+	ctx, cancel := operationContext(cmd, 0)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Listening for S3 events on: %s\n", queueURL)
+	}
+
+	err = client.ListenEvents(ctx, s3client.ListenOptions{
+		QueueURL:    queueURL,
+		SQSEndpoint: sqsEndpoint,
+		EventPrefix: eventPrefix,
+		KeyPrefix:   keyPrefix,
+		Actions: s3client.EventActions{
+			DownloadDir:     downloadDir,
+			Tags:            tags,
+			ReplicateBucket: replicateBucket,
+			ReplicatePrefix: replicatePrefix,
+			WebhookURL:      webhook,
+		},
+		Once:              once,
+		WaitSeconds:       wait,
+		VisibilityTimeout: visibilityTimeout,
+	}, func(result models.EventResult) {
+		if result.Skipped && !isVerbose(cmd) {
+			return
+		}
+		if err := utils.PrintJSON(result); err != nil {
+			utils.PrintError(err, "events listen")
+		}
+	})
+	if err != nil {
+		utils.PrintError(err, "events listen")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Println("Stopped listening for events")
+	}
+}
+
+// parseTags turns "key=value" pairs into a map.
+func parseTags(values []string) (map[string]string, error) {
+	tags := make(map[string]string, len(values))
+	for _, value := range values {
+		key, tagValue, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected 'key=value'", value)
+		}
+		tags[strings.TrimSpace(key)] = tagValue
+	}
+	return tags, nil
+}
+
+func init() {
+	eventsListenCmd.Flags().String("queue-url", "", "URL of the SQS queue receiving the bucket notifications (required)")
+	if err := eventsListenCmd.MarkFlagRequired("queue-url"); err != nil {
+		utils.PrintError(err, "events listen")
+	}
+	eventsListenCmd.Flags().String("sqs-endpoint", "", "Custom SQS endpoint (e.g. LocalStack or ElasticMQ)")
+	eventsListenCmd.Flags().String("event", "ObjectCreated:", "Only handle events whose name starts with this, empty for all")
+	eventsListenCmd.Flags().String("prefix", "", "Only handle objects whose key starts with this prefix")
+	eventsListenCmd.Flags().String("download-to", "", "Download each object into this directory")
+	eventsListenCmd.Flags().StringArray("tag", []string{}, "Tag to merge into each object as key=value (repeatable)")
+	eventsListenCmd.Flags().String("replicate-to", "", "Copy each object to this bucket or access point ARN, optionally followed by /prefix")
+	eventsListenCmd.Flags().String("webhook", "", "POST each event as JSON to this URL")
+	eventsListenCmd.Flags().Bool("once", false, "Process one batch of messages and exit")
+	eventsListenCmd.Flags().Int32("wait", 20, "Long polling wait time in seconds (0-20)")
+	eventsListenCmd.Flags().Int32("visibility-timeout", 0, "Seconds a received message stays hidden, 0 for the queue default")
+}
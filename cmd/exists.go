@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+// Exit status of exists and wait when the lookup itself fails, to tell errors from a
+// missing object.
+const lookupExitError = 2
+
+var existsCmd = &cobra.Command{
+	Use:   "exists <key>",
+	Short: "Exit with status 0 when an object exists and 1 when it does not",
+	Long: `Look up an object with HeadObject and exit with status 0 when it exists and meets
+the optional conditions, 1 when it is missing or does not meet them and 2 when the
+lookup fails, so shell scripts can gate steps on an object without parsing JSON.
+
+--min-size requires the object to be at least that large and --newer-than requires it to
+be modified at or after that date. The result is printed as JSON unless --quiet is set.`,
+	Example: `  # Only restore when the dump has been uploaded
+  s3manager exists backups/db.sql.gz --quiet && ./restore.sh
+
+  # Require a complete dump from today
+  s3manager exists backups/db.sql.gz --min-size 100MB --newer-than 2024-03-15`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExists(cmd, args[0])
+	},
+}
+
+func runExists(cmd *cobra.Command, key string) error {
+	minSizeFlag, _ := cmd.Flags().GetString("min-size")
+	newerThanFlag, _ := cmd.Flags().GetString("newer-than")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	fail := func(err error) error {
+		utils.PrintError(err, "exists")
+		return exitStatus(cmd, lookupExitError)
+	}
+
+	var opts s3client.ExistsOptions
+	if minSizeFlag != "" {
+		size, err := utils.ParseBytes(minSizeFlag)
+		if err != nil {
+			return fail(err)
+		}
+		opts.MinSize = size
+	}
+	if newerThanFlag != "" {
+		t, err := utils.ParseDate(newerThanFlag)
+		if err != nil {
+			return fail(fmt.Errorf("invalid --newer-than: %w", err))
+		}
+		opts.NewerThan = t
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx, cancel := operationContext(cmd, time.Minute)
+	defer cancel()
+
+	result, err := client.Exists(ctx, key, opts)
+	if err != nil {
+		return fail(err)
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if !quiet {
+		if err := utils.PrintJSON(result); err != nil {
+			return fail(err)
+		}
+	}
+
+	if !result.Exists {
+		return exitStatus(cmd, 1)
+	}
+	return nil
+}
+
+func init() {
+	existsCmd.Flags().String("min-size", "", "Minimum size of the object (e.g. 100MB)")
+	existsCmd.Flags().String("newer-than", "", "Only count the object when modified at or after this date, as 2024-01-01 or RFC3339")
+	existsCmd.Flags().BoolP("quiet", "q", false, "Print nothing, only set the exit status")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+// ExitError is returned by Execute when a command printed its result and ends with a
+// non-zero exit status, like a failed check. Commands return it rather than calling
+// os.Exit, so that deferred cleanup like releasing a lock runs first.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// exitStatus returns the ExitError of code for cmd, whose result is already printed, so
+// that cobra prints neither an error nor the usage.
+func exitStatus(cmd *cobra.Command, code int) error {
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	return &ExitError{Code: code}
+}
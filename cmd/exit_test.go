@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"errors"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExitStatus(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "backups/db.sql.gz", []byte("dump"), time.Now())
+
+	if _, err := executeCommand(t, fake, "", "exists", "backups/db.sql.gz", "--quiet"); err != nil {
+		t.Errorf("exists of an existing object error = %v", err)
+	}
+
+	// A missing object ends with status 1 after the result was printed
+	output, err := executeCommand(t, fake, "", "exists", "backups/missing.sql.gz")
+	var exit *ExitError
+	if !errors.As(err, &exit) || exit.Code != 1 {
+		t.Fatalf("exists of a missing object error = %v, want exit status 1", err)
+	}
+	if !strings.Contains(output, `"exists": false`) {
+		t.Errorf("output = %s, want the result", output)
+	}
+
+	// Failures of the check itself end with status 2
+	_, err = executeCommand(t, fake, "", "exists", "backups/db.sql.gz", "--min-size", "lots")
+	if !errors.As(err, &exit) || exit.Code != lookupExitError {
+		t.Errorf("exists with an invalid size error = %v, want exit status %d", err, lookupExitError)
+	}
+}
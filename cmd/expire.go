@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var expireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "Delete objects past the expiry stamped on them at upload",
+	Long: `Delete objects past the expiry stamped on them at upload.
+
+upload --expire-after 30d tags every object it writes with s3manager:expires=<time>.
+These commands give objects their own lifetime without bucket-wide lifecycle rules.`,
+}
+
+func init() {
+	expireCmd.AddCommand(expireRunCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var expireRunCmd = &cobra.Command{
+	Use:   "run [folder]",
+	Short: "Delete the objects whose stamped expiry has passed",
+	Long: `Delete the objects below a folder, or in the whole bucket, whose s3manager:expires tag
+lies in the past.
+
+The tags of every object are read, one request per object, so point it at the folders
+that hold expiring uploads. Objects without the tag are kept, like those whose tag is
+not a valid time, which are counted as invalid. Protected prefixes, --max-delete and
+--trash apply as for delete-old. Run it from cron to enforce the expiry.`,
+	Example: `  # Delete expired scratch uploads every night
+  s3manager expire run scratch/ --confirm
+
+  # See what has expired
+  s3manager expire run --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runExpire(cmd, args)
+	},
+}
+
+func runExpire(cmd *cobra.Command, args []string) {
+	folder := ""
+	if len(args) > 0 {
+		folder = args[0]
+	}
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	applyDeletionFlags(cmd)
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "expire run")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if !confirm && !dryRun {
+		preview, err := client.ExpireObjects(ctx, folder, true)
+		if err != nil {
+			utils.PrintError(err, "expire run")
+			return
+		}
+
+		bucketName := getBucketName(cmd)
+		warning := i18n.Tf("WARNING: This will %s expired files from bucket '%s'", deletionVerb(), bucketName)
+		if folder != "" {
+			warning += i18n.Tf(" in folder '%s'", folder)
+		}
+		ok, err := confirmDeletion(newPrompter(cmd, os.Stdout), os.Stdout, warning, bucketName, preview.Count, preview.TotalSizeBytes)
+		if err != nil {
+			utils.PrintError(err, "expire run")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Operation cancelled."))
+			return
+		}
+	}
+
+	jb, err := startJob(cmd, "expire run")
+	if err != nil {
+		utils.PrintError(err, "expire run")
+		return
+	}
+
+	ctx, unlock, err := holdLock(ctx, cmd, client, "expire run")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "expire run")
+		return
+	}
+	defer unlock()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Expiring objects in bucket %s\n", getBucketName(cmd))
+		if folder != "" {
+			cmd.Printf("Folder: %s\n", folder)
+		}
+		if dryRun {
+			cmd.Println("DRY RUN MODE: No files will actually be deleted")
+		}
+	}
+
+	result, err := client.ExpireObjects(ctx, folder, dryRun)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "expire run")
+		return
+	}
+	jb.succeed(result)
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "expire run")
+	}
+}
+
+func init() {
+	expireRunCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+	expireRunCmd.Flags().Bool("dry-run", false, "Show what would be deleted without actually deleting")
+	expireRunCmd.Flags().Int("max-delete", 0, "Abort without deleting anything if more objects expired, 0 for no limit (default from MAX_DELETE)")
+	addDeletionFlags(expireRunCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+)
+
+func TestFetchUnknownLength(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	data := strings.Repeat("fetched ", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// flushing before the end sends the body chunked, without a Content-Length
+		w.Write([]byte(data[:100]))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(data[100:]))
+	}))
+	defer server.Close()
+
+	output := runCommand(t, fake, "", "fetch", server.URL+"/dump.bin", "--key", "mirror/dump.bin")
+	if !strings.Contains(output, `"size_bytes": 8000`) {
+		t.Errorf("fetch output lacks the size:\n%s", output)
+	}
+	object, ok := fake.Object("test-bucket", "mirror/dump.bin")
+	if !ok {
+		t.Fatalf("object not uploaded, bucket has %v", fake.Keys("test-bucket"))
+	}
+	if string(object.Data) != data {
+		t.Errorf("uploaded %d bytes, want %d", len(object.Data), len(data))
+	}
+}
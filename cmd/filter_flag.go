@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/filter"
+	"time"
+)
+
+// addFilterFlag registers --filter, an expression selecting objects by key, size and age
+// such as 'size>100MB and age>30d and not key~"*.json"'.
+func addFilterFlag(c *cobra.Command, usage string) {
+	c.Flags().String("filter", "", usage)
+}
+
+// objectFilter parses --filter, nil when it is not given. Ages are measured from now.
+func objectFilter(cmd *cobra.Command) (*filter.Filter, error) {
+	expr, _ := cmd.Flags().GetString("filter")
+	return filter.Parse(expr, time.Now())
+}
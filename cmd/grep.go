@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"regexp"
+	"s3manager/internal/models"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+// Value of --output for grep's default "key:line:text" lines
+const outputText = "text"
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern>",
+	Short: "Search the contents of objects for a regular expression",
+	Long: `Search the lines of the objects under a prefix for a regular expression.
+
+Objects are streamed and searched by a pool of workers while the prefix is still being
+listed; .gz objects and objects stored with Content-Encoding gzip are decompressed on the
+fly. Matches are printed as key:line:text, or as JSON Lines with --output jsonl. The
+matches of one object are printed together, objects in the order they finish.
+
+Like grep, the exit status is 0 when a line matched, 1 when none did and 2 on errors,
+including objects that could not be read.`,
+	Example: `  # Hunt for a request ID in the compressed logs of 2024
+  s3manager grep "req-7f3a9c" --prefix logs/2024/ --include "*.log.gz"
+
+  # Case-insensitive, at most 10 matches per object, as JSON Lines
+  s3manager grep -i "out of memory" --prefix logs/ -m 10 --output jsonl`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGrep(cmd, args[0])
+	},
+}
+
+func runGrep(cmd *cobra.Command, pattern string) error {
+	prefix, _ := cmd.Flags().GetString("prefix")
+	include, _ := cmd.Flags().GetString("include")
+	ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+	fixedStrings, _ := cmd.Flags().GetBool("fixed-strings")
+	workers, _ := cmd.Flags().GetInt("workers")
+	maxCount, _ := cmd.Flags().GetInt("max-count")
+
+	fail := func(err error) error {
+		utils.PrintError(err, "grep")
+		if !errors.Is(err, ErrInterrupted) {
+			return exitStatus(cmd, lookupExitError)
+		}
+		return nil
+	}
+
+	output, err := outputFormat(cmd, outputText, outputJSONL)
+	if err != nil {
+		return fail(err)
+	}
+	if workers < 1 {
+		return fail(fmt.Errorf("workers must be at least 1"))
+	}
+
+	if fixedStrings {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fail(fmt.Errorf("invalid pattern: %w", err))
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	emit := func(match models.GrepMatch) error {
+		if output == outputJSONL {
+			return utils.PrintJSONLine(match)
+		}
+		_, err := fmt.Printf("%s:%d:%s\n", match.Key, match.Line, match.Text)
+		return err
+	}
+
+	summary, err := client.Grep(ctx, s3client.GrepOptions{
+		Pattern:  re,
+		Prefix:   prefix,
+		Include:  include,
+		Workers:  workers,
+		MaxCount: maxCount,
+	}, emit)
+	if err != nil {
+		return fail(err)
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("%d matches in %d of %d objects (%s searched)\n",
+			summary.Matches, summary.MatchedObjects, summary.SearchedObjects, utils.FormatBytes(summary.SearchedBytes))
+	}
+
+	switch {
+	case summary.Errors > 0:
+		utils.PrintError(fmt.Errorf("%d objects could not be searched", summary.Errors), "grep")
+		return exitStatus(cmd, lookupExitError)
+	case summary.Matches == 0:
+		return exitStatus(cmd, 1)
+	}
+	return nil
+}
+
+func init() {
+	grepCmd.Flags().String("prefix", "", "Prefix of the objects to search (default: whole bucket)")
+	grepCmd.Flags().String("include", "", "Only search objects whose name matches this glob (e.g. '*.log.gz')")
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "Match upper and lower case alike")
+	grepCmd.Flags().BoolP("fixed-strings", "F", false, "Treat the pattern as a literal string")
+	grepCmd.Flags().Int("workers", 8, "Number of objects searched at the same time")
+	grepCmd.Flags().IntP("max-count", "m", 0, "Stop searching an object after this many matching lines, 0 for no limit")
+	grepCmd.Flags().String("output", outputText, "Output format: text for key:line:text lines, jsonl for one JSON object per match")
+}
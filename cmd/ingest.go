@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/ingest"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest <url>",
+	Short: "Pull files from an SFTP or FTP server into the bucket",
+	Long: `Pull the files of a directory on an SFTP or FTP server into the bucket, like the files
+a partner delivers to a drop directory. Files are streamed from the server into the
+bucket one at a time, without being staged on local disk, and keep their path relative
+to the directory under --destination.
+
+The source is an sftp://, ftp:// or ftps:// URL: sftp://user@host/path for SFTP,
+ftp://user@host/path for FTP and ftps://user@host/path for FTP with explicit TLS. A path
+starting with /~/ is relative to the home directory of the user, and the URL may name a
+single file instead of a directory.
+
+SFTP runs the ssh program, so host keys, keys, the SSH agent and ~/.ssh/config work as
+they do for sftp; --identity and --ssh-option add to them. Passwords are not supported
+for SFTP. FTP logs in with the password of the URL, else with INGEST_PASSWORD (or
+INGEST_PASSWORD_FILE), and anonymously without a user.
+
+A file whose size changes while it is read, like one the partner is still writing,
+fails the run instead of being stored in part. With --remove-source each file is deleted
+from the server once it is stored, or once --skip-existing finds it already stored, so
+the next run only picks up new deliveries.`,
+	Example: `  # Pull the deliveries of a partner into a dated folder
+  s3manager ingest sftp://acme@sftp.example.com/outgoing/ --destination "partners/acme/{yyyy}/{MM}/{dd}"
+
+  # Only CSV files, skipping those already in the bucket
+  s3manager ingest sftp://acme@sftp.example.com/~/outgoing --destination partners/acme \
+    --include '*.csv' --skip-existing --confirm
+
+  # Move the files off an FTPS server, logging in with a password from a file
+  INGEST_PASSWORD_FILE=/run/secrets/acme-ftp \
+    s3manager ingest ftps://acme@ftp.example.com/outgoing --destination partners/acme --remove-source --confirm
+
+  # A dedicated key and a host key that is accepted on first use
+  s3manager ingest sftp://acme@sftp.example.com:2222/outgoing --destination partners/acme \
+    --identity /etc/s3manager/acme_ed25519 --ssh-option StrictHostKeyChecking=accept-new
+
+  # See what would be pulled
+  s3manager ingest sftp://acme@sftp.example.com/outgoing --destination partners/acme --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runIngest(cmd, args[0])
+	},
+}
+
+func init() {
+	ingestCmd.Flags().StringP("destination", "d", "", "Folder of the bucket the files are stored under, may contain tokens like {yyyy}/{MM}/{dd}")
+	ingestCmd.Flags().StringArray("include", []string{}, "Only pull files matching this glob, e.g. '*.csv' (repeatable)")
+	ingestCmd.Flags().StringArray("exclude", []string{}, "Skip files matching this glob, e.g. '*.tmp' (repeatable)")
+	ingestCmd.Flags().Bool("skip-existing", false, "Skip files the bucket already has with the same size")
+	ingestCmd.Flags().Bool("remove-source", false, "Delete each file from the server once it is stored or skipped as already stored")
+	ingestCmd.Flags().StringP("identity", "i", "", "Private key ssh logs in to the SFTP server with")
+	ingestCmd.Flags().StringArray("ssh-option", []string{}, "Option passed to ssh with -o, e.g. StrictHostKeyChecking=accept-new (repeatable)")
+	ingestCmd.Flags().Var(new(bytesValue), "bandwidth-limit", "Maximum bytes per second read from the server, e.g. 10MB, 0 for no limit")
+	ingestCmd.Flags().Bool("dry-run", false, "Show what would be pulled without pulling")
+	ingestCmd.Flags().Bool("confirm", false, "Skip the confirmation prompt")
+}
+
+func runIngest(cmd *cobra.Command, sourceURL string) {
+	destination, _ := cmd.Flags().GetString("destination")
+	include, _ := cmd.Flags().GetStringArray("include")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	skipExisting, _ := cmd.Flags().GetBool("skip-existing")
+	removeSource, _ := cmd.Flags().GetBool("remove-source")
+	identity, _ := cmd.Flags().GetString("identity")
+	sshOptions, _ := cmd.Flags().GetStringArray("ssh-option")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	bandwidthLimit := int64(*cmd.Flags().Lookup("bandwidth-limit").Value.(*bytesValue))
+
+	destination, err := utils.ExpandTemplate(destination, time.Now())
+	if err != nil {
+		utils.PrintError(fmt.Errorf("invalid --destination: %w", err), "ingest")
+		return
+	}
+
+	if !confirm && !dryRun {
+		fmt.Println(i18n.T("Ingest operation summary:"))
+		fmt.Println(i18n.Tf("Source: %s", ingest.Redact(sourceURL)))
+		fmt.Println(i18n.Tf("Bucket: %s", getBucketName(cmd)))
+		fmt.Println(i18n.Tf("Destination: %s", getDestinationDisplay(destination)))
+		if len(exclude) > 0 {
+			fmt.Println(i18n.Tf("Exclude patterns: %v", exclude))
+		}
+		if removeSource {
+			fmt.Println(i18n.T("Delete ingested files: true"))
+		}
+
+		ok, err := newPrompter(cmd, os.Stdout).Confirm(i18n.T("Continue with ingest?"))
+		if err != nil {
+			utils.PrintError(err, "ingest")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Ingest cancelled."))
+			return
+		}
+	}
+
+	jb, err := startJob(cmd, "ingest")
+	if err != nil {
+		utils.PrintError(err, "ingest")
+		return
+	}
+
+	dest, err := newObjectStore()
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "ingest")
+		return
+	}
+
+	// Deliveries can be large, so there is no timeout unless --timeout sets one
+	ctx, cancel := operationContext(cmd, 0)
+	defer cancel()
+
+	client, _ := dest.(*s3client.Client)
+	ctx, unlock, err := holdLock(ctx, cmd, client, "ingest")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "ingest")
+		return
+	}
+	defer unlock()
+
+	source, err := ingest.Open(ctx, sourceURL, ingest.SourceOptions{
+		Password:   cfg.IngestPassword,
+		Identity:   identity,
+		SSHOptions: sshOptions,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to connect to %s: %w", ingest.Redact(sourceURL), err)
+		jb.fail(err, nil)
+		utils.PrintError(err, "ingest")
+		return
+	}
+	defer source.Close()
+
+	opts := ingest.Options{
+		Destination:    destination,
+		Include:        include,
+		Exclude:        exclude,
+		SkipExisting:   skipExisting,
+		RemoveSource:   removeSource,
+		BandwidthLimit: bandwidthLimit,
+		DryRun:         dryRun,
+	}
+	if isVerbose(cmd) {
+		opts.OnIngested = func(file ingest.File, key string, ingested int) {
+			cmd.Printf("Stored %s as %s (%s), %d files so far\n", file.Path, key, utils.FormatBytes(file.Size), ingested)
+		}
+	}
+
+	result, err := ingest.Ingest(ctx, source, sourceURL, dest, opts)
+	if err != nil && (result == nil || !result.Interrupted) {
+		jb.fail(err, nil)
+		utils.PrintError(err, "ingest")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	if err != nil {
+		jb.fail(err, result)
+	} else {
+		jb.succeed(result)
+	}
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "ingest")
+		return
+	}
+
+	if isVerbose(cmd) && !result.Interrupted {
+		cmd.Printf("Ingest completed: %d stored, %d skipped\n", result.IngestedCount, result.SkippedCount)
+	}
+}
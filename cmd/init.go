@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"s3manager/config"
+	"s3manager/internal/schedule"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate an example .env and systemd units or crontab lines for jobs",
+	Long: `Generate files that set s3manager up on a host: an example .env with every
+configuration variable, and systemd units or crontab lines that run a job on a schedule
+without a daemon of their own.`,
+}
+
+var initEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print an example .env with every configuration variable",
+	Long: `Print a commented .env with the required variables and every optional one,
+commented out with an example value. Redirect it to .env in the directory the jobs run in
+and fill in the credentials.`,
+	Example: `  s3manager init env > .env`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(config.ExampleEnv)
+	},
+}
+
+func init() {
+	initCmd.AddCommand(initEnvCmd)
+	initCmd.AddCommand(initSystemdCmd)
+	initCmd.AddCommand(initCronCmd)
+}
+
+// addJobFlags adds the flags that describe the scheduled job to c.
+func addJobFlags(c *cobra.Command, scheduleUsage string) {
+	c.Flags().String("job", "", "Name of the job: an alias of the config file, or the name of the command given after --")
+	c.Flags().String("schedule", "daily", scheduleUsage)
+	c.Flags().String("working-dir", "", "Directory the job runs in and reads .env from (default: the current directory)")
+	c.Flags().String("env-file", "", "Environment file loaded in addition to .env")
+	c.Flags().String("binary", "", "Path of the s3manager binary the job runs (default: this binary)")
+	c.MarkFlagRequired("job")
+}
+
+// scheduledJob returns the job described by the flags of cmd. It runs the command line
+// args, or the alias named by --job when there are none. --config and --profile given to
+// init are passed on to the job.
+func scheduledJob(cmd *cobra.Command, args []string) (schedule.Job, error) {
+	name, _ := cmd.Flags().GetString("job")
+	when, _ := cmd.Flags().GetString("schedule")
+	workingDir, _ := cmd.Flags().GetString("working-dir")
+	envFile, _ := cmd.Flags().GetString("env-file")
+	binary, _ := cmd.Flags().GetString("binary")
+
+	job := schedule.Job{Name: name, Schedule: when}
+	if binary == "" {
+		path, err := os.Executable()
+		if err == nil {
+			path, err = filepath.EvalSymlinks(path)
+		}
+		if err != nil {
+			return job, fmt.Errorf("failed to locate the binary, use --binary: %w", err)
+		}
+		binary = path
+	}
+	var err error
+	if job.WorkingDir, err = absolutePath(workingDir); err != nil {
+		return job, err
+	}
+	if envFile != "" {
+		if job.EnvFile, err = absolutePath(envFile); err != nil {
+			return job, err
+		}
+	}
+
+	job.Command = []string{binary}
+	for _, flag := range []string{"config", "profile"} {
+		if value, _ := cmd.Flags().GetString(flag); cmd.Flags().Changed(flag) && value != "" {
+			if flag == "config" {
+				if value, err = filepath.Abs(value); err != nil {
+					return job, err
+				}
+			}
+			job.Command = append(job.Command, "--"+flag, value)
+		}
+	}
+
+	if len(args) > 0 {
+		job.Command = append(job.Command, args...)
+		return job, job.Validate()
+	}
+	if err := checkAlias(cmd, name); err != nil {
+		return job, err
+	}
+	job.Command = append(job.Command, name)
+	return job, job.Validate()
+}
+
+// checkAlias checks that the config file of cmd defines the alias name.
+func checkAlias(cmd *cobra.Command, name string) error {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		path = config.ProfilesPath()
+		if _, err := os.Stat(path); path == "" || err != nil {
+			return fmt.Errorf("no config file with an alias %s, give the command of the job after --", name)
+		}
+	}
+	profiles, err := config.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := profiles.Aliases[name]; !ok {
+		return fmt.Errorf("no alias %s in %s, define it in the [alias] section or give the command of the job after --", name, path)
+	}
+	return nil
+}
+
+// absolutePath returns path made absolute, the current directory when it is empty.
+func absolutePath(path string) (string, error) {
+	if path == "" {
+		return os.Getwd()
+	}
+	return filepath.Abs(path)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/schedule"
+	"s3manager/pkg/utils"
+)
+
+var initCronCmd = &cobra.Command{
+	Use:   "cron --job <name> [-- command...]",
+	Short: "Generate a crontab line that runs a job on a schedule",
+	Long: `Print the crontab line that runs a job on --schedule, for hosts without systemd.
+
+The job is an alias of the [alias] section of the config file or the command line given
+after --, like for init systemd. The line changes to --working-dir, where the job finds
+.env, loads --env-file if given, and appends the output of the job to --log-file. Add it
+with crontab -e. Jobs that would ask for confirmation need --confirm or --yes.`,
+	Example: `  # Nightly run of the alias nightly-backup
+  s3manager init cron --job nightly-backup --schedule "0 3 * * *" --log-file /var/log/s3manager-backup.log
+
+  # Append a weekly clean-up to the crontab of the current user
+  (crontab -l; s3manager init cron --job cleanup --schedule weekly -- delete-old --days 90 --folder logs --confirm) | crontab -`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runInitCron(cmd, args)
+	},
+}
+
+func init() {
+	addJobFlags(initCronCmd, "Crontab schedule, e.g. \"0 3 * * *\", daily or @reboot")
+	initCronCmd.Flags().String("log-file", "", "File the output of the job is appended to (default: mailed by cron)")
+}
+
+func runInitCron(cmd *cobra.Command, args []string) {
+	job, err := scheduledJob(cmd, args)
+	if err != nil {
+		utils.PrintError(err, "init cron")
+		return
+	}
+	job.LogFile, _ = cmd.Flags().GetString("log-file")
+
+	line, err := schedule.CrontabLine(job)
+	if err != nil {
+		utils.PrintError(err, "init cron")
+		return
+	}
+	fmt.Println(line)
+}
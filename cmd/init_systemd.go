@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"s3manager/internal/schedule"
+	"s3manager/pkg/utils"
+)
+
+var initSystemdCmd = &cobra.Command{
+	Use:   "systemd --job <name> [-- command...]",
+	Short: "Generate a systemd service and timer that run a job on a schedule",
+	Long: `Generate the oneshot service s3manager-<job>.service, which runs the job once, and
+the timer s3manager-<job>.timer, which starts it on --schedule.
+
+The job is an alias of the [alias] section of the config file, which the service runs by
+name so that the config file stays the one place to change it, or the command line given
+after --. --config and --profile given to init are passed on to the job. The service
+runs in --working-dir, the current directory by default, where it finds .env.
+
+The units are printed, or written to --output-dir, e.g. /etc/systemd/system. Runs missed
+while the machine was off are caught up when it boots, and stopping the service stops
+the job like Ctrl-C. Jobs that would ask for confirmation need --confirm or --yes, since
+there is no terminal to answer on.`,
+	Example: `  # Nightly run of the alias nightly-backup from the config file
+  sudo s3manager init systemd --job nightly-backup --schedule "*-*-* 03:00:00" \
+    --output-dir /etc/systemd/system
+  sudo systemctl daemon-reload && sudo systemctl enable --now s3manager-nightly-backup.timer
+
+  # Weekly clean-up given on the command line
+  s3manager init systemd --job cleanup --schedule weekly -- delete-old --days 90 --folder logs --confirm`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runInitSystemd(cmd, args)
+	},
+}
+
+func init() {
+	addJobFlags(initSystemdCmd, "systemd calendar event of the timer, e.g. daily, hourly or *-*-* 03:00:00")
+	initSystemdCmd.Flags().String("user", "", "User the service runs as (default: root for system units)")
+	initSystemdCmd.Flags().String("randomized-delay", "", "Random delay of the start, e.g. 15m, so that many hosts do not start at once")
+	initSystemdCmd.Flags().String("output-dir", "", "Write the units to this directory instead of printing them")
+}
+
+func runInitSystemd(cmd *cobra.Command, args []string) {
+	job, err := scheduledJob(cmd, args)
+	if err != nil {
+		utils.PrintError(err, "init systemd")
+		return
+	}
+	job.User, _ = cmd.Flags().GetString("user")
+	job.RandomizedDelay, _ = cmd.Flags().GetString("randomized-delay")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+
+	service, err := schedule.SystemdService(job)
+	if err != nil {
+		utils.PrintError(err, "init systemd")
+		return
+	}
+	timer, err := schedule.SystemdTimer(job)
+	if err != nil {
+		utils.PrintError(err, "init systemd")
+		return
+	}
+	units := []struct{ name, content string }{
+		{job.UnitName() + ".service", service},
+		{job.UnitName() + ".timer", timer},
+	}
+
+	if outputDir == "" {
+		for i, unit := range units {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("# %s\n%s", unit.name, unit.content)
+		}
+		return
+	}
+	for _, unit := range units {
+		path := filepath.Join(outputDir, unit.name)
+		if err := os.WriteFile(path, []byte(unit.content), 0644); err != nil {
+			utils.PrintError(fmt.Errorf("failed to write unit: %w", err), "init systemd")
+			return
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	fmt.Printf("Enable the timer with: systemctl daemon-reload && systemctl enable --now %s.timer\n", job.UnitName())
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Write listing snapshots into the bucket and compare them",
+	Long: `Write listing snapshots into the bucket and compare them.
+
+A snapshot is the full listing of the bucket, or of a prefix, stored as a gzip-compressed
+JSON Lines or CSV object in the bucket itself. Created on a schedule, two snapshots show
+what was added, removed or changed in between.`,
+}
+
+func init() {
+	inventoryCmd.AddCommand(inventoryCreateCmd)
+	inventoryCmd.AddCommand(inventoryDiffCmd)
+}
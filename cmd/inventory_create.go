@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var inventoryCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Write a snapshot of the listing into the bucket",
+	Long: `Write a snapshot of the listing into the bucket.
+
+The objects under --source, by default the whole bucket, are written with their key,
+size, last modified time, ETag and storage class to <prefix><date>-<time>.jsonl.gz, or
+.csv.gz with --format csv. Objects under --prefix are not part of the snapshot, so
+earlier snapshots do not show up in later ones.`,
+	Example: `  # Nightly snapshot of the whole bucket
+  s3manager inventory create --prefix inventory/
+
+  # Snapshot of the backups as CSV
+  s3manager inventory create --prefix inventory/backups/ --source backups/ --format csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runInventoryCreate(cmd)
+	},
+}
+
+func runInventoryCreate(cmd *cobra.Command) {
+	prefix, _ := cmd.Flags().GetString("prefix")
+	source, _ := cmd.Flags().GetString("source")
+	format, _ := cmd.Flags().GetString("format")
+
+	jb, err := startJob(cmd, "inventory create")
+	if err != nil {
+		utils.PrintError(err, "inventory create")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "inventory create")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Writing inventory of %s to: %s\n", getDestinationDisplay(source), prefix)
+	}
+
+	result, err := client.CreateInventory(ctx, s3client.InventoryOptions{
+		Prefix: prefix,
+		Source: source,
+		Format: format,
+	})
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "inventory create")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	jb.succeed(result)
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "inventory create")
+	}
+}
+
+func init() {
+	inventoryCreateCmd.Flags().String("prefix", "inventory/", "Prefix the snapshot is written under")
+	inventoryCreateCmd.Flags().String("source", "", "Only include the objects under this prefix (default: whole bucket)")
+	inventoryCreateCmd.Flags().String("format", s3client.InventoryJSONL, "Format of the snapshot: jsonl or csv")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var inventoryDiffCmd = &cobra.Command{
+	Use:   "diff <from> <to>",
+	Short: "Compare two inventory snapshots",
+	Long: `Compare two inventory snapshots, given as their keys in the bucket.
+
+Objects are matched by key and reported as added, removed or changed, when their size or
+ETag differs, together with the change of the total size.`,
+	Example: `  # What changed between two nightly snapshots
+  s3manager inventory diff inventory/20240314-020000.jsonl.gz inventory/20240315-020000.jsonl.gz`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runInventoryDiff(cmd, args[0], args[1])
+	},
+}
+
+func runInventoryDiff(cmd *cobra.Command, from, to string) {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "inventory diff")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	diff, err := client.DiffInventory(ctx, from, to)
+	if err != nil {
+		utils.PrintError(err, "inventory diff")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		diff.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(diff); err != nil {
+		utils.PrintError(err, "inventory diff")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("%d added, %d removed, %d changed, %d unchanged\n", diff.AddedCount, diff.RemovedCount, diff.ChangedCount, diff.UnchangedCount)
+	}
+}
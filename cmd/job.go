@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"s3manager/internal/notify"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+// job reports the start and outcome of a command run to the configured monitoring pings
+// and email recipients, and runs the pre- and post-hooks around it. Dry runs are neither
+// reported nor hooked.
+type job struct {
+	command string
+	bucket  string
+	started time.Time
+	pinger  *notify.Pinger
+	mailer  *notify.Mailer
+	hooks   *notify.Hooks
+}
+
+// startJob starts a run of command. It fails when the pre-hook fails, and the command
+// must then not run; the failure is already reported.
+func startJob(cmd *cobra.Command, command string) (*job, error) {
+	j := &job{command: command, bucket: getBucketName(cmd), started: time.Now()}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return j, nil
+	}
+
+	mailer, err := notify.NewMailer(cfg)
+	if err != nil {
+		slog.Warn("Email notifications disabled", "error", err)
+	}
+	j.mailer = mailer
+
+	j.pinger = notify.NewPinger(cfg)
+	j.pinger.Start(context.Background(), command)
+
+	j.hooks = notify.NewHooks(cfg)
+	if err := j.hooks.Pre(cmd.Context(), j.report(notify.StatusStarted, nil, nil)); err != nil {
+		// The command did not run, there is nothing for the post-hook to clean up
+		j.hooks = nil
+		j.fail(err, nil)
+		return nil, err
+	}
+	return j, nil
+}
+
+// succeed reports a successful run with the command result as summary.
+func (j *job) succeed(result interface{}) {
+	j.finish(notify.StatusSuccess, nil, result)
+}
+
+// fail reports a failed run; result may carry the partial result of an interrupted run.
+func (j *job) fail(err error, result interface{}) {
+	j.finish(notify.StatusFailure, err, result)
+}
+
+func (j *job) finish(status string, err error, result interface{}) {
+	if j.pinger == nil && j.mailer == nil && j.hooks == nil {
+		return
+	}
+	report := j.report(status, err, result)
+
+	// Not tied to the command context so that runs stopped by a timeout or Ctrl-C are
+	// still reported and cleaned up after
+	if err := j.hooks.Post(context.Background(), report); err != nil {
+		slog.Warn("Post-hook failed", "command", j.command, "error", err)
+	}
+	j.pinger.Finish(context.Background(), report)
+	if err := j.mailer.Send(context.Background(), report); err != nil {
+		slog.Warn("Failed to send email notification", "error", err)
+	}
+}
+
+func (j *job) report(status string, err error, result interface{}) notify.Report {
+	duration := time.Since(j.started)
+	report := notify.Report{
+		Command:         j.command,
+		Bucket:          j.bucket,
+		Status:          status,
+		StartedAt:       utils.FormatTime(j.started),
+		Duration:        duration.Round(time.Millisecond).String(),
+		DurationSeconds: duration.Seconds(),
+		Summary:         result,
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Variables of job mode: jobEnvPrefix + COMMAND and ARGS, and jobEnvPrefix + the name of
+// any flag of the command.
+const (
+	jobEnvPrefix  = "S3MANAGER_JOB_"
+	jobEnvCommand = jobEnvPrefix + "COMMAND"
+	jobEnvArgs    = jobEnvPrefix + "ARGS"
+)
+
+var jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Run a command defined entirely by environment variables",
+	Long: `Run the command of S3MANAGER_JOB_COMMAND with the arguments of S3MANAGER_JOB_ARGS
+and a flag for every S3MANAGER_JOB_<FLAG> variable, for containers that run s3manager as
+a one-shot job, like a Kubernetes CronJob, without flags or config files.
+
+A flag variable is named after the flag in upper case with underscores for dashes, e.g.
+S3MANAGER_JOB_DESTINATION for --destination or S3MANAGER_JOB_MAX_ATTEMPTS for the
+global --max-attempts. Bool flags take true or false, and repeatable flags take several
+values: separated by commas for lists like upload --exclude, one per line for flags
+whose values may hold commas, like fetch --header. S3MANAGER_JOB_ARGS is split into words like a shell command
+line. A variable that names no flag of the command is an error, so typos do not go
+unnoticed.
+
+There is no terminal to answer prompts on, so job mode answers them with yes unless
+S3MANAGER_JOB_YES is false; MAX_DELETE and the other safety limits still apply. The
+result is printed as JSON like for the command itself, and job mode exits with status 1
+when the command reports an error, so the container fails.`,
+	Example: `  # Nightly upload of /data with 30 days of retention in a container
+  S3MANAGER_JOB_COMMAND=upload \
+  S3MANAGER_JOB_ARGS=/data \
+  S3MANAGER_JOB_DESTINATION='backups/{yyyy}/{MM}/{dd}' \
+  S3MANAGER_JOB_EXPIRE_AFTER=30d \
+  S3MANAGER_JOB_CONFIRM=true \
+  s3manager job`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// execute replaces job by the command of the environment before cobra sees it
+		return fmt.Errorf("%s is not set", jobEnvCommand)
+	},
+}
+
+// expandJob replaces the job command in args by the command line defined by the
+// environment, followed by any further arguments. It reports whether args ran job mode.
+// job --help is left alone.
+func expandJob(args []string) ([]string, bool, error) {
+	i := commandIndex(args)
+	if i < 0 || args[i] != jobCmd.Name() {
+		return args, false, nil
+	}
+	for _, arg := range args[i+1:] {
+		if arg == "-h" || arg == "--help" {
+			return args, false, nil
+		}
+	}
+
+	line, err := jobCommandLine(os.Environ())
+	if err != nil {
+		return nil, true, err
+	}
+	return append(append(append([]string(nil), args[:i]...), line...), args[i+1:]...), true, nil
+}
+
+// jobCommandLine returns the command line defined by the job variables of environ.
+func jobCommandLine(environ []string) ([]string, error) {
+	variables := make(map[string]string)
+	for _, entry := range environ {
+		if name, value, ok := strings.Cut(entry, "="); ok && strings.HasPrefix(name, jobEnvPrefix) {
+			variables[name] = value
+		}
+	}
+
+	words := strings.Fields(variables[jobEnvCommand])
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s is not set, e.g. upload or delete-old", jobEnvCommand)
+	}
+	target, rest, err := rootCmd.Find(words)
+	if err != nil || target == rootCmd || len(rest) > 0 || target == jobCmd {
+		return nil, fmt.Errorf("%s=%s is not a command", jobEnvCommand, variables[jobEnvCommand])
+	}
+	line := append([]string(nil), words...)
+
+	if _, ok := variables[jobEnvPrefix+"YES"]; !ok {
+		line = append(line, "--yes")
+	}
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		if name != jobEnvCommand && name != jobEnvArgs {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flagName := strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(name, jobEnvPrefix)), "_", "-")
+		flag := target.Flags().Lookup(flagName)
+		if flag == nil {
+			flag = target.InheritedFlags().Lookup(flagName)
+		}
+		if flag == nil {
+			return nil, fmt.Errorf("%s: %s has no flag --%s", name, target.CommandPath(), flagName)
+		}
+		for _, value := range flagValues(flag, variables[name]) {
+			line = append(line, "--"+flagName+"="+value)
+		}
+	}
+
+	positional, err := splitCommandLine(variables[jobEnvArgs])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", jobEnvArgs, err)
+	}
+	return append(line, positional...), nil
+}
+
+// flagValues splits the value of a variable into the values of flag: several for
+// repeatable flags, one for the others. Slice flags take comma-separated values, like on
+// the command line. Array flags take values with commas, like headers or regular
+// expressions, so they are separated by newlines instead.
+func flagValues(flag *pflag.Flag, value string) []string {
+	separator := ""
+	switch kind := flag.Value.Type(); {
+	case strings.HasSuffix(kind, "Slice"):
+		separator = ","
+	case strings.HasSuffix(kind, "Array"):
+		separator = "\n"
+	default:
+		return []string{value}
+	}
+	var values []string
+	for _, part := range strings.Split(value, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
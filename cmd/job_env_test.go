@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"s3manager/internal/models"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+)
+
+func TestJobCommandLine(t *testing.T) {
+	got, err := jobCommandLine([]string{
+		"S3MANAGER_JOB_COMMAND=upload",
+		`S3MANAGER_JOB_ARGS=/data "/var/My Backups"`,
+		"S3MANAGER_JOB_DESTINATION=backups/{yyyy}",
+		"S3MANAGER_JOB_EXCLUDE=*.log, *.tmp",
+		"S3MANAGER_JOB_NO_ARCHIVE=true",
+		"S3MANAGER_JOB_MAX_ATTEMPTS=5",
+		"BUCKET_NAME=ignored",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"upload", "--yes", "--destination=backups/{yyyy}", "--exclude=*.log", "--exclude=*.tmp",
+		"--max-attempts=5", "--no-archive=true", "/data", "/var/My Backups"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jobCommandLine() = %q, want %q", got, want)
+	}
+
+	// Array flags take one value per line, so headers keep their commas
+	got, err = jobCommandLine([]string{
+		"S3MANAGER_JOB_COMMAND=fetch",
+		"S3MANAGER_JOB_ARGS=https://example.com/dump.sql",
+		"S3MANAGER_JOB_HEADER=Cache-Control: max-age=0, no-store\nX-Trace: 1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"fetch", "--yes", "--header=Cache-Control: max-age=0, no-store", "--header=X-Trace: 1", "https://example.com/dump.sql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jobCommandLine() = %q, want %q", got, want)
+	}
+
+	got, err = jobCommandLine([]string{"S3MANAGER_JOB_COMMAND=report top", "S3MANAGER_JOB_YES=false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"report", "top", "--yes=false"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jobCommandLine() = %q, want %q", got, want)
+	}
+
+	for _, environ := range [][]string{
+		nil,
+		{"S3MANAGER_JOB_COMMAND=nonsense"},
+		{"S3MANAGER_JOB_COMMAND=job"},
+		{"S3MANAGER_JOB_COMMAND=upload", "S3MANAGER_JOB_DESTINATON=typo"},
+		{"S3MANAGER_JOB_COMMAND=upload", `S3MANAGER_JOB_ARGS="unterminated`},
+	} {
+		if _, err := jobCommandLine(environ); err == nil {
+			t.Errorf("jobCommandLine(%q) succeeded", environ)
+		}
+	}
+}
+
+func TestJobMode(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "dump.sql"), []byte("dump"), 0644)
+	t.Setenv("S3MANAGER_JOB_COMMAND", "upload")
+	t.Setenv("S3MANAGER_JOB_ARGS", filepath.Join(dir, "dump.sql"))
+	t.Setenv("S3MANAGER_JOB_DESTINATION", "db")
+	t.Setenv("S3MANAGER_JOB_NO_ARCHIVE", "true")
+
+	output := runCommand(t, fake, "", "job")
+	var result models.UploadResult
+	if err := json.Unmarshal([]byte(output[strings.Index(output, "{"):]), &result); err != nil {
+		t.Fatalf("invalid output %q: %v", output, err)
+	}
+	if result.TotalFiles != 1 {
+		t.Errorf("uploaded %d files, want 1", result.TotalFiles)
+	}
+	if keys := fake.Keys("test-bucket"); !reflect.DeepEqual(keys, []string{"db/dump.sql"}) {
+		t.Errorf("bucket has %v", keys)
+	}
+	// A failed job names the command, never its arguments or flag values
+	t.Setenv("S3MANAGER_JOB_ARGS", filepath.Join(dir, "missing-secret.sql"))
+	_, err := executeCommand(t, fake, "", "job")
+	if err == nil || err.Error() != "job s3manager upload failed" {
+		t.Errorf("job error = %v, want only the command path", err)
+	}
+}
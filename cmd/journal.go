@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"log/slog"
+	"s3manager/internal/journal"
+)
+
+// openJournal opens the journal of a long-running operation, identified by its parameters.
+// The --journal flag overrides the location and --resume loads a previous run.
+func openJournal(cmd *cobra.Command, operation string, params ...string) (*journal.Journal, error) {
+	path, _ := cmd.Flags().GetString("journal")
+	resume, _ := cmd.Flags().GetBool("resume")
+
+	fingerprint := journal.Fingerprint(append([]string{operation}, params...)...)
+	if path == "" {
+		path = journal.DefaultPath(operation, fingerprint)
+	}
+
+	return journal.Open(path, operation, fingerprint, resume)
+}
+
+// closeJournal keeps the journal after a failure so the operation can be resumed.
+func closeJournal(jr *journal.Journal) {
+	if jr == nil {
+		return
+	}
+	if err := jr.Close(); err != nil {
+		slog.Warn("Failed to close journal", "path", jr.Path(), "error", err)
+		return
+	}
+	slog.Info("Progress saved, rerun with --resume to continue", "journal", jr.Path())
+}
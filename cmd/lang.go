@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+)
+
+// applyLanguage selects the language of --lang, or of S3MANAGER_LANG when it is not given.
+// JSON output stays in English.
+func applyLanguage(cmd *cobra.Command) error {
+	lang, _ := cmd.Flags().GetString("lang")
+	if lang == "" {
+		lang = os.Getenv("S3MANAGER_LANG")
+	}
+	if lang == "" {
+		lang = i18n.English
+	}
+	return i18n.SetLanguage(lang)
+}
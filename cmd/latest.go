@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
+)
+
+var latestCmd = &cobra.Command{
+	Use:   "latest <prefix>...",
+	Short: "Show the newest objects under a prefix",
+	Long: `Show the newest object(s) under a prefix without downloading anything. Several
+prefixes, as arguments or comma-separated, are searched together.
+
+Each object is reported with its key, size, last modified time and age, which makes
+the command suitable for scripts that check whether a recent backup exists.
+
+The --pattern glob is matched against the object name (e.g. "*.sql.gz"), or against
+the full key when it contains a slash. --regex matches a regular expression against the
+full key, for naming conventions globs cannot express. --filter selects objects by an
+expression on their key, size and age, e.g. 'size>1GB and not key~"*.tmp"'. --tag-filter keeps only objects carrying the
+given tags, which costs one extra request per listed object. --older-than and
+--newer-than limit the objects to a range of modification dates.`,
+	Example: `  # Newest object in a folder
+  s3manager latest backups/db/
+
+  # Five newest compressed dumps
+  s3manager latest backups/ --count 5 --pattern "*.sql.gz"
+
+  # Five newest objects of three applications together
+  s3manager latest logs/app1/ logs/app2/,logs/app3/ --count 5
+
+  # Newest production or staging dump, by a regular expression on the key
+  s3manager latest backups/ --regex '^backups/db-(prod|staging)-\d{8}\.sql\.gz$'
+
+  # Newest dump that is larger than 1GB, skipping partial uploads
+  s3manager latest backups/ --filter 'size>1GB and not key~"*.partial"'
+
+  # Newest production backup, selected by tag
+  s3manager latest backups/ --tag-filter environment=production
+
+  # Newest backup taken before the incident on March 15th
+  s3manager latest backups/ --older-than 2024-03-15T08:00:00Z
+
+  # Newest object in the whole bucket
+  s3manager latest ""`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runLatest(cmd, args)
+	},
+}
+
+func runLatest(cmd *cobra.Command, args []string) {
+	prefixes := splitPrefixes(args)
+	count, _ := cmd.Flags().GetInt("count")
+	pattern, _ := cmd.Flags().GetString("pattern")
+	tagFilter, _ := cmd.Flags().GetStringArray("tag-filter")
+
+	if count <= 0 {
+		utils.PrintError(fmt.Errorf("count must be greater than 0"), "latest")
+		return
+	}
+
+	output, err := outputFormat(cmd, outputJSON, outputJSONL)
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+	exportTo, err := exportPath(cmd)
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+
+	tags, err := parseTags(tagFilter)
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+	regex, err := keyRegex(cmd)
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+	objects, err := objectFilter(cmd)
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+
+	window, err := timeWindow(cmd)
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+	if err := useListingCache(cmd, client); err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 5*time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Listing newest objects under: %s\n", strings.Join(prefixes, ", "))
+		if pattern != "" {
+			cmd.Printf("  Pattern: %s\n", pattern)
+		}
+		if regex != nil {
+			cmd.Printf("  Regex: %s\n", regex)
+		}
+		if objects != nil {
+			cmd.Printf("  Filter: %s\n", objects)
+		}
+		if len(tags) > 0 {
+			cmd.Printf("  Tag filter: %s\n", strings.Join(tagFilter, ", "))
+		}
+	}
+
+	result, err := client.LatestObjects(ctx, s3client.LatestOptions{
+		Prefixes: prefixes,
+		Count:    count,
+		Pattern:  pattern,
+		Regex:    regex,
+		Filter:   objects,
+		Tags:     tags,
+		Window:   window,
+	})
+	if err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := exportTable(cmd, exportTo, listItemTable(result.Items)); err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+
+	if output == outputJSONL {
+		for _, item := range result.Items {
+			if err := utils.PrintJSONLine(item); err != nil {
+				utils.PrintError(err, "latest")
+				return
+			}
+		}
+	} else if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "latest")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("%d of %d matching objects shown\n", result.Count, result.MatchedCount)
+	}
+}
+
+func init() {
+	latestCmd.Flags().IntP("count", "n", 1, "Number of newest objects to show")
+	latestCmd.Flags().StringArray("tag-filter", []string{}, "Only show objects carrying this tag, as key=value (repeatable, all must match)")
+	latestCmd.Flags().StringP("pattern", "p", "", "Glob matched against object names (e.g. '*.tar.gz')")
+	addFilterFlag(latestCmd, "Only show objects matching this expression, e.g. 'size>1GB and age<2d'")
+	addRegexFlag(latestCmd, "Regular expression matched against full keys (e.g. '^backups/db-(prod|staging)-\\d{8}\\.sql\\.gz$')")
+	addTimeWindowFlags(latestCmd)
+	addOutputFlag(latestCmd)
+	addExportFlag(latestCmd)
+	addCacheFlags(latestCmd)
+}
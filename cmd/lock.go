@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"s3manager/internal/lockfile"
+	"s3manager/internal/s3client"
+	"time"
+)
+
+// holdLock takes the locks requested for the rest of the command: the local --lockfile,
+// which keeps an overrunning cron job from starting twice on one host, and the
+// distributed --lock-name, which does the same across hosts. The returned context is
+// cancelled when the distributed lock is lost and unlock releases both. Dry runs take
+// no locks.
+func holdLock(ctx context.Context, cmd *cobra.Command, client *s3client.Client, command string) (context.Context, func(), error) {
+	path, _ := cmd.Flags().GetString("lockfile")
+	name, _ := cmd.Flags().GetString("lock-name")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		return ctx, func() {}, nil
+	}
+
+	var local *lockfile.Lock
+	if path != "" {
+		wait, _ := cmd.Flags().GetDuration("lockfile-wait")
+		if isVerbose(cmd) && wait > 0 {
+			cmd.Printf("Waiting up to %s for lock file: %s\n", wait, path)
+		}
+
+		var err error
+		local, err = lockfile.Acquire(ctx, path, wait)
+		if err != nil {
+			return ctx, func() {}, err
+		}
+	}
+	releaseLocal := func() {
+		if err := local.Release(); err != nil {
+			slog.Warn("Failed to release lock file", "path", path, "error", err)
+		}
+	}
+
+	if name == "" {
+		return ctx, releaseLocal, nil
+	}
+	if client == nil {
+		releaseLocal()
+		return ctx, func() {}, fmt.Errorf("--lock-name is only available with STORAGE_BACKEND=s3")
+	}
+	ttl, _ := cmd.Flags().GetDuration("lock-ttl")
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	lock, err := client.AcquireLock(ctx, name, s3client.LockOptions{
+		Command: command,
+		TTL:     ttl,
+		OnLost:  cancel,
+	})
+	if err != nil {
+		cancel(err)
+		releaseLocal()
+		return ctx, func() {}, err
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Acquired lock: %s\n", name)
+	}
+
+	unlock := func() {
+		// The command context may already be cancelled, release with a fresh one
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer releaseCancel()
+		if err := lock.Release(releaseCtx); err != nil {
+			slog.Warn("Failed to release lock", "lock", name, "error", err)
+		}
+		cancel(nil)
+		releaseLocal()
+	}
+	return ctx, unlock, nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+)
+
+// bytesValue is a size flag like 256MB or 1GiB.
+type bytesValue int64
+
+func (b *bytesValue) String() string {
+	if *b == 0 {
+		return "0"
+	}
+	return utils.FormatBytes(int64(*b))
+}
+
+func (b *bytesValue) Set(value string) error {
+	size, err := utils.ParseBytes(value)
+	if err != nil {
+		return fmt.Errorf("invalid size %q: %w", value, err)
+	}
+	*b = bytesValue(size)
+	return nil
+}
+
+func (b *bytesValue) Type() string {
+	return "size"
+}
+
+// memoryBudget returns the upload buffer budget of --memory-budget and --low-memory,
+// current when neither is given. --low-memory only ever lowers the budget.
+func memoryBudget(cmd *cobra.Command, current int64) int64 {
+	if flag := cmd.Flag("memory-budget"); flag != nil && flag.Changed {
+		if value, ok := flag.Value.(*bytesValue); ok {
+			current = int64(*value)
+		}
+	}
+	if low, _ := cmd.Flags().GetBool("low-memory"); low && (current <= 0 || current > s3client.LowMemoryBudget) {
+		current = s3client.LowMemoryBudget
+	}
+	return current
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"s3manager/config"
+	"s3manager/internal/i18n"
+	"s3manager/internal/journal"
+	"s3manager/internal/models"
+	"s3manager/internal/s3client"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy a bucket to another bucket or provider",
+	Long: `Copy the objects of the configured bucket to the same keys in another bucket, which
+may belong to another provider or storage backend.
+
+The destination is configured by DEST_ variables that replace the storage settings of
+the source: DEST_STORAGE_BACKEND, DEST_API_URL, DEST_ACCESS_KEY, DEST_SECRET_KEY,
+DEST_BUCKET_NAME, DEST_REGION, DEST_PROVIDER and the DEST_AZURE_ variables. Settings
+without a DEST_ variable are shared with the source; set one empty to clear it, like
+DEST_API_URL= for AWS. --dest-bucket overrides DEST_BUCKET_NAME.
+
+Objects are streamed through this machine, --concurrency at a time and no faster than
+--bandwidth-limit. Between S3 buckets they keep their content type and other headers,
+user metadata, tags and storage class; --storage-class replaces the class for
+destinations that do not offer it. Other backends only receive the content. The plan
+and every copied object are recorded in a journal, so an interrupted migration continues
+with --resume where it stopped. A final pass lists the destination and checks that every
+planned object arrived with its size, and between S3 buckets with its attributes; the
+command exits with status 1 when one did not.`,
+	Example: `  # See what would be copied
+  DEST_API_URL=https://s3.new-provider.example DEST_ACCESS_KEY=... DEST_SECRET_KEY=... \
+    s3manager migrate --dest-bucket backups --dry-run
+
+  # Copy at most 50MB/s, without logs
+  s3manager migrate --dest-bucket backups --bandwidth-limit 50MB --exclude '*.log' --confirm
+
+  # Continue after an interruption
+  s3manager migrate --dest-bucket backups --bandwidth-limit 50MB --exclude '*.log' --confirm --resume`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrate(cmd)
+	},
+}
+
+func init() {
+	migrateCmd.Flags().String("dest-bucket", "", "Bucket, container or file:// directory to copy to (default from DEST_BUCKET_NAME)")
+	migrateCmd.Flags().String("prefix", "", "Only copy the objects under this prefix")
+	migrateCmd.Flags().StringArray("include", []string{}, "Only copy objects matching this glob, e.g. '*.sql.gz' (repeatable)")
+	migrateCmd.Flags().StringArray("exclude", []string{}, "Skip objects matching this glob, e.g. '*.log' (repeatable)")
+	migrateCmd.Flags().Bool("skip-existing", false, "Skip objects the destination already has with the same size")
+	migrateCmd.Flags().Var(new(bytesValue), "bandwidth-limit", "Maximum bytes per second read from the source, e.g. 50MB, 0 for no limit")
+	migrateCmd.Flags().Int("concurrency", 4, "Number of objects copied in parallel")
+	migrateCmd.Flags().String("storage-class", "", "Storage class of the copies between S3 buckets, e.g. STANDARD (default: that of each source object)")
+	migrateCmd.Flags().Bool("no-verify", false, "Skip the final check of the destination")
+	migrateCmd.Flags().Bool("dry-run", false, "Show what would be copied without copying")
+	migrateCmd.Flags().Bool("confirm", false, "Skip the confirmation prompt")
+	migrateCmd.Flags().Bool("resume", false, "Continue an interrupted run from its journal instead of re-listing the source")
+	migrateCmd.Flags().String("journal", "", "Journal file path (default: per-operation file in the user cache directory)")
+}
+
+func runMigrate(cmd *cobra.Command) error {
+	prefix, _ := cmd.Flags().GetString("prefix")
+	include, _ := cmd.Flags().GetStringArray("include")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	skipExisting, _ := cmd.Flags().GetBool("skip-existing")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	storageClass, _ := cmd.Flags().GetString("storage-class")
+	noVerify, _ := cmd.Flags().GetBool("no-verify")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	bandwidthLimit := int64(*cmd.Flags().Lookup("bandwidth-limit").Value.(*bytesValue))
+
+	destCfg, err := config.Destination(cfg)
+	if err != nil {
+		utils.PrintError(err, "migrate")
+		return nil
+	}
+	if destBucket, _ := cmd.Flags().GetString("dest-bucket"); destBucket != "" {
+		destCfg.BucketName = destBucket
+	}
+	if destCfg.BucketName == "" {
+		utils.PrintError(fmt.Errorf("--dest-bucket or DEST_BUCKET_NAME is required"), "migrate")
+		return nil
+	}
+	if destCfg.BucketName == cfg.BucketName && destCfg.ApiURL == cfg.ApiURL && destCfg.Backend == cfg.Backend {
+		utils.PrintError(fmt.Errorf("source and destination are both %s", cfg.BucketName), "migrate")
+		return nil
+	}
+
+	if !confirm && !dryRun {
+		fmt.Println(i18n.T("Migrate operation summary:"))
+		fmt.Println(i18n.Tf("Source: %s", getBucketName(cmd)))
+		fmt.Println(i18n.Tf("Destination: %s", describeStore(destCfg)))
+		if prefix != "" {
+			fmt.Println(i18n.Tf("Prefix: %s", prefix))
+		}
+		fmt.Println(i18n.Tf("WARNING: objects with the same keys in %s will be overwritten", destCfg.BucketName))
+
+		ok, err := newPrompter(cmd, os.Stdout).Confirm(i18n.T("Continue with migration?"))
+		if err != nil {
+			utils.PrintError(err, "migrate")
+			return nil
+		}
+		if !ok {
+			fmt.Println(i18n.T("Migration cancelled."))
+			return nil
+		}
+	}
+
+	jb, err := startJob(cmd, "migrate")
+	if err != nil {
+		utils.PrintError(err, "migrate")
+		return nil
+	}
+
+	source, err := newObjectStore()
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "migrate")
+		return nil
+	}
+	dest, err := objectStoreFor(destCfg)
+	if err != nil {
+		err = fmt.Errorf("destination: %w", err)
+		jb.fail(err, nil)
+		utils.PrintError(err, "migrate")
+		return nil
+	}
+
+	// A migration runs for hours, so there is no timeout unless --timeout sets one
+	ctx, cancel := operationContext(cmd, 0)
+	defer cancel()
+
+	client, _ := source.(*s3client.Client)
+	ctx, unlock, err := holdLock(ctx, cmd, client, "migrate")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "migrate")
+		return nil
+	}
+	defer unlock()
+
+	var jr *journal.Journal
+	if !dryRun {
+		params := append([]string{describeStore(cfg), describeStore(destCfg), prefix, strconv.FormatBool(skipExisting)}, include...)
+		jr, err = openJournal(cmd, "migrate", append(append(params, "exclude"), exclude...)...)
+		if err != nil {
+			jb.fail(err, nil)
+			utils.PrintError(err, "migrate")
+			return nil
+		}
+		if jr.Resumed() && isVerbose(cmd) {
+			cmd.Printf("Resuming from journal: %s (%d objects already copied)\n", jr.Path(), jr.DoneCount())
+		}
+	}
+
+	opts := storage.MigrateOptions{
+		Prefix:         prefix,
+		Include:        include,
+		Exclude:        exclude,
+		SkipExisting:   skipExisting,
+		BandwidthLimit: bandwidthLimit,
+		Concurrency:    concurrency,
+		StorageClass:   storageClass,
+		Verify:         !noVerify,
+		DryRun:         dryRun,
+		Journal:        jr,
+	}
+	if isVerbose(cmd) {
+		opts.OnCopied = func(key string, size int64, copied int) {
+			cmd.Printf("Copied %s (%s), %d objects so far\n", key, utils.FormatBytes(size), copied)
+		}
+	}
+
+	result, err := storage.Migrate(ctx, source, dest, opts)
+	if result != nil {
+		result.Throttling = storeThrottling(source, dest)
+	}
+	if bucketFlag := getBucketName(cmd); result != nil && bucketFlag != cfg.BucketName {
+		result.SourceBucket = bucketFlag
+	}
+	if err != nil {
+		closeJournal(jr)
+		if result == nil || !result.Interrupted {
+			jb.fail(err, nil)
+			utils.PrintError(err, "migrate")
+			return nil
+		}
+		jb.fail(err, result)
+	} else if !result.Verified && !noVerify && !dryRun {
+		// The copies are done, a rerun with --resume only repeats the verification
+		closeJournal(jr)
+		jb.fail(fmt.Errorf("%d objects are missing or different in %s", len(result.Mismatches), result.DestBucket), result)
+	} else {
+		if err := jr.Remove(); err != nil {
+			slog.Warn("Failed to remove journal", "path", jr.Path(), "error", err)
+		}
+		jb.succeed(result)
+	}
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "migrate")
+		return nil
+	}
+
+	if isVerbose(cmd) && !result.Interrupted {
+		cmd.Printf("Migration completed: %d copied, %d skipped, %d already copied\n", result.CopiedCount, result.SkippedCount, result.ResumedCount)
+	}
+	if err == nil && !result.Verified && !noVerify && !dryRun {
+		return exitStatus(cmd, 1)
+	}
+	return nil
+}
+
+// storeThrottling adds up the throttling of the S3 stores among stores, nil when none
+// of them was throttled.
+func storeThrottling(stores ...storage.ObjectStore) *models.Throttling {
+	var total *models.Throttling
+	for _, store := range stores {
+		client, ok := store.(*s3client.Client)
+		if !ok {
+			continue
+		}
+		throttling := client.Throttling()
+		switch {
+		case throttling == nil:
+		case total == nil:
+			total = throttling
+		default:
+			total.ThrottledRequests += throttling.ThrottledRequests
+			total.ConcurrencyReductions += throttling.ConcurrencyReductions
+			total.MinConcurrency = min(total.MinConcurrency, throttling.MinConcurrency)
+			total.FirstThrottled = min(total.FirstThrottled, throttling.FirstThrottled)
+			total.LastThrottled = max(total.LastThrottled, throttling.LastThrottled)
+		}
+	}
+	return total
+}
+
+// describeStore names the bucket of c together with the backend or endpoint it is on.
+func describeStore(c *config.Config) string {
+	switch {
+	case c.Backend != "" && !strings.EqualFold(c.Backend, "s3"):
+		return c.Backend + ":" + c.BucketName
+	case c.ApiURL != "":
+		return c.BucketName + " at " + c.ApiURL
+	}
+	return c.BucketName
+}
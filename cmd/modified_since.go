@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"s3manager/internal/runstate"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
+)
+
+// lastRunValue makes --modified-since use the start of the last successful run.
+const lastRunValue = "@last-run"
+
+// incremental is the resolved --modified-since of an upload.
+type incremental struct {
+	since time.Time
+	// state and key are set for @last-run, whose successful runs are recorded
+	state *runstate.State
+	key   string
+	start time.Time
+}
+
+// parseModifiedSince resolves --modified-since for uploads to destination. A date is
+// used as is; @last-run looks up the last successful upload to the same destination in
+// the state file and uploads everything when there is none yet.
+func parseModifiedSince(cmd *cobra.Command, destination string) (incremental, error) {
+	value, _ := cmd.Flags().GetString("modified-since")
+	inc := incremental{start: time.Now()}
+	if value == "" {
+		return inc, nil
+	}
+
+	if value != lastRunValue {
+		t, err := utils.ParseDate(value)
+		if err != nil {
+			return inc, fmt.Errorf("invalid --modified-since, expected a date or %s: %w", lastRunValue, err)
+		}
+		inc.since = t
+		return inc, nil
+	}
+
+	path, _ := cmd.Flags().GetString("state-file")
+	if path == "" {
+		path = runstate.DefaultPath()
+	}
+	state, err := runstate.Load(path)
+	if err != nil {
+		return inc, err
+	}
+	inc.state = state
+	inc.key = fmt.Sprintf("upload %s/%s", getBucketName(cmd), strings.Trim(destination, "/"))
+	if lastRun, ok := state.LastRun(inc.key); ok {
+		inc.since = lastRun
+	} else {
+		slog.Info("No previous run recorded, uploading all files", "destination", inc.key, "state_file", path)
+	}
+	return inc, nil
+}
+
+// recordRun saves the start of this run as the last run, so files changed while it was
+// uploading are picked up by the next one.
+func (inc incremental) recordRun() {
+	if inc.state == nil {
+		return
+	}
+	if err := inc.state.Record(inc.key, inc.start); err != nil {
+		slog.Warn("Failed to record the last run", "destination", inc.key, "error", err)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/export"
+	"s3manager/internal/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Values of --output
+const (
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputCSV   = "csv"
+)
+
+// addOutputFlag registers --output for commands that can stream their items as JSON Lines.
+func addOutputFlag(c *cobra.Command) {
+	c.Flags().String("output", outputJSON, "Output format: json for one result document, jsonl for one line per item as it is found")
+}
+
+// addReportOutputFlag registers --output for reports, which can also be printed as CSV.
+func addReportOutputFlag(c *cobra.Command) {
+	c.Flags().String("output", outputJSON, "Output format: json or csv")
+}
+
+// outputFormat returns the --output value after checking that it is one of formats.
+func outputFormat(cmd *cobra.Command, formats ...string) (string, error) {
+	format, _ := cmd.Flags().GetString("output")
+	if slices.Contains(formats, format) {
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid --output %q, expected %s", format, strings.Join(formats, " or "))
+}
+
+// addExportFlag registers --export for commands whose result is a table of rows.
+func addExportFlag(c *cobra.Command) {
+	c.Flags().String("export", "", "Also write the rows to this .csv or .parquet file")
+}
+
+// exportPath returns the --export file after checking its format, so a wrong extension
+// fails before the listing.
+func exportPath(cmd *cobra.Command) (string, error) {
+	path, _ := cmd.Flags().GetString("export")
+	if path == "" {
+		return "", nil
+	}
+	if _, err := export.FormatOf(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// exportTable writes table to path, if one was given.
+func exportTable(cmd *cobra.Command, path string, table export.Table) error {
+	if path == "" {
+		return nil
+	}
+	if err := export.WriteFile(path, table); err != nil {
+		return err
+	}
+	if isVerbose(cmd) {
+		cmd.Printf("Exported %d rows to %s\n", len(table.Rows), path)
+	}
+	return nil
+}
+
+// listItemTable is the table of listed objects.
+func listItemTable(items []models.ListItem) export.Table {
+	table := export.Table{Columns: []export.Column{
+		{Name: "key", Kind: export.String},
+		{Name: "size_bytes", Kind: export.Int64},
+		{Name: "last_modified", Kind: export.Time},
+		{Name: "age_seconds", Kind: export.Int64},
+		{Name: "storage_class", Kind: export.String},
+		{Name: "etag", Kind: export.String},
+	}}
+	for _, item := range items {
+		lastModified, _ := time.Parse(time.RFC3339, item.LastModified)
+		table.Rows = append(table.Rows, []any{item.Key, item.Size, lastModified, item.AgeSeconds, item.StorageClass, item.ETag})
+	}
+	return table
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var pingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Check that the bucket is reachable with the current configuration",
+	Long: `Send a HeadBucket and a single-key ListObjectsV2 request and report their latency,
+the endpoint that answered, its TLS certificate and the provider it looks like.
+
+Warnings are reported when the certificate expires within 14 days, when the endpoint does
+not look like the configured PROVIDER, or when the bucket is in another region than the
+one requests are signed for. Nothing is written to the bucket, which makes ping a quick
+smoke test for new configurations in CI pipelines.
+
+The command exits with status 1 when a request fails.`,
+	Example: `  # Check the configured bucket
+  s3manager ping
+
+  # Check another bucket with a short timeout
+  s3manager ping --bucket staging-backups --timeout 10s`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPing(cmd)
+	},
+}
+
+func runPing(cmd *cobra.Command) error {
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "ping")
+		return nil
+	}
+
+	ctx, cancel := operationContext(cmd, time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Pinging bucket: %s\n", getBucketName(cmd))
+	}
+
+	result, err := client.Ping(ctx)
+	if err != nil {
+		utils.PrintError(err, "ping")
+		return nil
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "ping")
+		return nil
+	}
+
+	if !result.OK {
+		return exitStatus(cmd, 1)
+	}
+	return nil
+}
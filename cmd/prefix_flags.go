@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"s3manager/internal/i18n"
+	"s3manager/internal/storage"
+	"strings"
+)
+
+// splitPrefixes returns the folders or prefixes given as repeated or comma-separated
+// values, without the spaces around them. A comma inside a prefix is escaped as "\,".
+// None means the whole bucket.
+func splitPrefixes(values []string) []string {
+	var prefixes []string
+	for _, value := range values {
+		for _, prefix := range storage.SplitFolders(value) {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+// describeFolders returns the part of a warning naming folders, "" for the whole bucket.
+func describeFolders(folders []string) string {
+	switch len(folders) {
+	case 0:
+		return ""
+	case 1:
+		return i18n.Tf(" in folder '%s'", folders[0])
+	}
+	return i18n.Tf(" in folders '%s'", strings.Join(folders, "', '"))
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"s3manager/config"
+	"strings"
+)
+
+// applyProfile sets the flags that were not given on the command line to the defaults of
+// the selected profile in the config file. Settings scoped to the command take precedence
+// over plain flag names, and the selected profile over [default].
+func applyProfile(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	explicit := path != ""
+	if !explicit {
+		path = config.ProfilesPath()
+	}
+	profile, _ := cmd.Flags().GetString("profile")
+	if profile == "" {
+		profile = os.Getenv("S3MANAGER_PROFILE")
+	}
+
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) && !explicit && profile == "" {
+		return nil
+	}
+	profiles, err := config.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+	settings, err := profiles.Settings(profile)
+	if err != nil {
+		return err
+	}
+
+	values, err := profileValues(cmd, settings)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for name, setting := range values {
+		for _, value := range setting.values {
+			if err := cmd.Flags().Set(name, value); err != nil {
+				return fmt.Errorf("%s:%d: invalid value for --%s: %w", path, setting.line, name, err)
+			}
+		}
+	}
+
+	if isVerbose(cmd) && len(values) > 0 {
+		cmd.Printf("Using %d flag defaults from %s\n", len(values), path)
+	}
+	return nil
+}
+
+type profileValue struct {
+	values   []string
+	priority int
+	line     int
+}
+
+// profileValues picks the values of the flags of cmd from settings. Flags given on the
+// command line keep their value.
+func profileValues(cmd *cobra.Command, settings []config.Setting) (map[string]*profileValue, error) {
+	commandPath := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	sections := make(map[string]int)
+
+	values := make(map[string]*profileValue)
+	for _, setting := range settings {
+		scoped := setting.Command != ""
+		if scoped && setting.Command != commandPath {
+			continue
+		}
+		flag := cmd.Flags().Lookup(setting.Flag)
+		if flag == nil {
+			if scoped {
+				return nil, fmt.Errorf("line %d: %s has no flag --%s", setting.Line, commandPath, setting.Flag)
+			}
+			continue
+		}
+		if flag.Changed || profileReserved(flag) {
+			continue
+		}
+
+		if _, ok := sections[setting.Profile]; !ok {
+			sections[setting.Profile] = len(sections)
+		}
+		priority := sections[setting.Profile] * 2
+		if scoped {
+			priority++
+		}
+
+		current, ok := values[flag.Name]
+		switch {
+		case !ok || priority > current.priority:
+			values[flag.Name] = &profileValue{values: []string{setting.Value}, priority: priority, line: setting.Line}
+		case priority == current.priority:
+			current.values = append(current.values, setting.Value)
+		}
+	}
+	return values, nil
+}
+
+// profileReserved reports whether flag selects the profiles and cannot be set by them.
+func profileReserved(flag *pflag.Flag) bool {
+	return flag.Name == "config" || flag.Name == "profile" || flag.Name == "help"
+}
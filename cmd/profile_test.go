@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApplyProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte(`[default]
+destination = default-dest
+limit = 5
+exclude = *.log
+exclude = .DS_Store
+top.limit = 10
+
+[prod]
+limit = 7
+bucket = prod-backups
+`), 0644)
+	t.Setenv("S3MANAGER_PROFILE", "")
+
+	run := func(args ...string) *cobra.Command {
+		t.Helper()
+		root := &cobra.Command{Use: "s3manager"}
+		root.PersistentFlags().String("config", "", "")
+		root.PersistentFlags().String("profile", "", "")
+		root.PersistentFlags().String("bucket", "", "")
+		root.PersistentFlags().Bool("verbose", false, "")
+		var ran *cobra.Command
+		top := &cobra.Command{Use: "top", Run: func(cmd *cobra.Command, args []string) { ran = cmd }}
+		top.Flags().Int("limit", 50, "")
+		top.Flags().String("destination", "", "")
+		top.Flags().StringSlice("exclude", nil, "")
+		root.AddCommand(top)
+		root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error { return applyProfile(cmd) }
+
+		root.SetArgs(append([]string{"top", "--config", path}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return ran
+	}
+
+	cmd := run()
+	if limit, _ := cmd.Flags().GetInt("limit"); limit != 10 {
+		t.Errorf("limit = %d, want the command setting 10", limit)
+	}
+	if exclude, _ := cmd.Flags().GetStringSlice("exclude"); !reflect.DeepEqual(exclude, []string{"*.log", ".DS_Store"}) {
+		t.Errorf("exclude = %v, want both repeated values", exclude)
+	}
+	if bucket, _ := cmd.Flags().GetString("bucket"); bucket != "" {
+		t.Errorf("bucket = %q, want none without a profile", bucket)
+	}
+
+	cmd = run("--profile", "prod")
+	if bucket, _ := cmd.Flags().GetString("bucket"); bucket != "prod-backups" {
+		t.Errorf("bucket = %q, want prod-backups from the profile", bucket)
+	}
+	if limit, _ := cmd.Flags().GetInt("limit"); limit != 7 {
+		t.Errorf("limit = %d, want 7 from the selected profile", limit)
+	}
+
+	cmd = run("--profile", "prod", "--limit", "3", "--destination", "cli-dest")
+	if limit, _ := cmd.Flags().GetInt("limit"); limit != 3 {
+		t.Errorf("limit = %d, want 3 from the command line", limit)
+	}
+	if destination, _ := cmd.Flags().GetString("destination"); destination != "cli-dest" {
+		t.Errorf("destination = %q, want cli-dest from the command line", destination)
+	}
+}
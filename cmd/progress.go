@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Values of --progress-format
+const (
+	progressText = "text"
+	progressJSON = "json"
+)
+
+// progressFormat returns --progress-format after checking it.
+func progressFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("progress-format")
+	switch format {
+	case progressText, progressJSON:
+	default:
+		return "", fmt.Errorf("invalid --progress-format %q, expected %s or %s", format, progressText, progressJSON)
+	}
+	if interval, _ := cmd.Flags().GetDuration("progress-interval"); interval <= 0 {
+		return "", fmt.Errorf("--progress-interval must be positive")
+	}
+	return format, nil
+}
+
+// showFileProgress reports the transfers of client. With --verbose a line per file is
+// printed as it starts and finishes, and with --progress-format json a progress event is
+// written to stderr as a JSON line every --progress-interval. The returned function
+// writes the last event and must be called once the transfers are over.
+func showFileProgress(cmd *cobra.Command, client *s3client.Client) func() {
+	verbose := isVerbose(cmd)
+	format, _ := progressFormat(cmd)
+	if !verbose && format != progressJSON {
+		return func() {}
+	}
+
+	var tracker *progressTracker
+	stop := func() {}
+	if format == progressJSON {
+		interval, _ := cmd.Flags().GetDuration("progress-interval")
+		tracker = newProgressTracker(cmd.ErrOrStderr())
+		stop = tracker.run(interval)
+	}
+
+	var mu sync.Mutex
+	client.SetProgressHandler(func(p s3client.FileProgress) {
+		if tracker != nil {
+			tracker.update(p)
+		}
+		if verbose {
+			mu.Lock()
+			defer mu.Unlock()
+			printFileProgress(cmd, p)
+		}
+	})
+	return stop
+}
+
+func printFileProgress(cmd *cobra.Command, p s3client.FileProgress) {
+	verb, pastVerb := "Uploading", "Uploaded"
+	if p.Operation == s3client.TransferDownload {
+		verb, pastVerb = "Downloading", "Downloaded"
+	}
+
+	switch p.Event {
+	case s3client.ProgressStarted:
+		cmd.Printf("  %s %s (%s)\n", verb, p.RemotePath, utils.FormatBytes(p.Size))
+	case s3client.ProgressFinished:
+		cmd.Printf("  %s %s (%s) in %s, %s\n", pastVerb, p.RemotePath, utils.FormatBytes(p.Size),
+			p.Duration.Round(time.Millisecond), utils.FormatSpeed(p.BytesPerSecond()))
+	case s3client.ProgressFailed:
+		cmd.Printf("  Failed %s after %s: %v\n", p.RemotePath, p.Duration.Round(time.Millisecond), p.Err)
+	}
+}
+
+// progressEvent is a JSON line of --progress-format json.
+type progressEvent struct {
+	Time string `json:"time"`
+	// Event is progress while the transfers run and done for the last line
+	Event          string  `json:"event"`
+	Operation      string  `json:"operation,omitempty"`
+	BytesDone      int64   `json:"bytes_done"`
+	BytesTotal     int64   `json:"bytes_total"`
+	FilesDone      int     `json:"files_done"`
+	FilesTotal     int     `json:"files_total"`
+	CurrentFile    string  `json:"current_file,omitempty"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	BytesPerSecond float64 `json:"bytes_per_second"`
+	// ETASeconds is null until bytes have moved
+	ETASeconds *float64 `json:"eta_seconds"`
+}
+
+// progressTracker adds up the progress of the transfers of a command.
+type progressTracker struct {
+	mu  sync.Mutex
+	out *json.Encoder
+	now func() time.Time
+
+	start      time.Time
+	operation  string
+	bytesDone  int64
+	bytesTotal int64
+	filesDone  int
+	filesTotal int
+	// inFlight holds the bytes counted so far of the files being transferred, in the
+	// order they started
+	inFlight []inFlightFile
+}
+
+type inFlightFile struct {
+	path  string
+	size  int64
+	bytes int64
+}
+
+func newProgressTracker(out io.Writer) *progressTracker {
+	return &progressTracker{out: json.NewEncoder(out), now: time.Now, start: time.Now()}
+}
+
+func (t *progressTracker) update(p s3client.FileProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p.Operation != "" {
+		t.operation = p.Operation
+	}
+	i := slices.IndexFunc(t.inFlight, func(f inFlightFile) bool { return f.path == p.RemotePath })
+	switch p.Event {
+	case s3client.ProgressPlanned:
+		t.filesTotal += p.Files
+		t.bytesTotal += p.Size
+	case s3client.ProgressSkipped:
+		t.filesTotal--
+		t.bytesTotal -= p.Size
+	case s3client.ProgressStarted:
+		if i < 0 {
+			t.inFlight = append(t.inFlight, inFlightFile{path: p.RemotePath, size: p.Size})
+		}
+	case s3client.ProgressTransferred:
+		if i >= 0 {
+			// Bodies read again by retries do not count twice
+			f := &t.inFlight[i]
+			counted := min(f.bytes+p.Bytes, f.size)
+			t.bytesDone += counted - f.bytes
+			f.bytes = counted
+		}
+	case s3client.ProgressFinished:
+		if i >= 0 {
+			t.bytesDone += t.inFlight[i].size - t.inFlight[i].bytes
+			t.inFlight = slices.Delete(t.inFlight, i, i+1)
+		}
+		t.filesDone++
+	case s3client.ProgressFailed:
+		if i >= 0 {
+			t.bytesDone -= t.inFlight[i].bytes
+			t.inFlight = slices.Delete(t.inFlight, i, i+1)
+		}
+	}
+}
+
+// event returns the current progress.
+func (t *progressTracker) event(name string) progressEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	elapsed := now.Sub(t.start)
+	event := progressEvent{
+		Time:           now.UTC().Format(time.RFC3339),
+		Event:          name,
+		Operation:      t.operation,
+		BytesDone:      t.bytesDone,
+		BytesTotal:     max(t.bytesTotal, t.bytesDone),
+		FilesDone:      t.filesDone,
+		FilesTotal:     max(t.filesTotal, t.filesDone),
+		ElapsedSeconds: elapsed.Seconds(),
+		BytesPerSecond: utils.BytesPerSecond(t.bytesDone, elapsed),
+	}
+	if len(t.inFlight) > 0 {
+		event.CurrentFile = t.inFlight[len(t.inFlight)-1].path
+	}
+	if event.BytesPerSecond > 0 {
+		eta := float64(event.BytesTotal-event.BytesDone) / event.BytesPerSecond
+		event.ETASeconds = &eta
+	}
+	return event
+}
+
+func (t *progressTracker) emit(name string) {
+	event := t.event(name)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out.Encode(event)
+}
+
+// run emits a progress event every interval until the returned function is called,
+// which emits the done event.
+func (t *progressTracker) run(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	stopped := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				t.emit("progress")
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(stopped)
+			<-finished
+			t.emit("done")
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"s3manager/internal/s3client"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressTracker(t *testing.T) {
+	var out bytes.Buffer
+	tracker := newProgressTracker(&out)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tracker.start = start
+	tracker.now = func() time.Time { return start.Add(2 * time.Second) }
+
+	upload := func(event, path string, size, n int64) {
+		tracker.update(s3client.FileProgress{Operation: s3client.TransferUpload, Event: event, RemotePath: path, Size: size, Bytes: n})
+	}
+	tracker.update(s3client.FileProgress{Operation: s3client.TransferUpload, Event: s3client.ProgressPlanned, Files: 3, Size: 1000})
+	upload(s3client.ProgressSkipped, "same.txt", 100, 0)
+	upload(s3client.ProgressStarted, "a.txt", 600, 0)
+	upload(s3client.ProgressTransferred, "a.txt", 600, 400)
+	// A retry reads the body again
+	upload(s3client.ProgressTransferred, "a.txt", 600, 400)
+	upload(s3client.ProgressFinished, "a.txt", 600, 0)
+	upload(s3client.ProgressStarted, "b.txt", 300, 0)
+	upload(s3client.ProgressTransferred, "b.txt", 300, 200)
+
+	event := tracker.event("progress")
+	if event.BytesDone != 800 || event.BytesTotal != 900 || event.FilesDone != 1 || event.FilesTotal != 2 || event.CurrentFile != "b.txt" {
+		t.Errorf("event = %+v, want 800 of 900 bytes, 1 of 2 files, at b.txt", event)
+	}
+	if event.BytesPerSecond != 400 || event.ETASeconds == nil || *event.ETASeconds != 0.25 {
+		t.Errorf("event = %+v, want 400 bytes/s and 0.25s left", event)
+	}
+
+	upload(s3client.ProgressFailed, "b.txt", 300, 0)
+	tracker.emit("done")
+	var done progressEvent
+	if err := json.Unmarshal(out.Bytes(), &done); err != nil {
+		t.Fatalf("event %q is not JSON: %v", out.String(), err)
+	}
+	if done.Event != "done" || done.Operation != s3client.TransferUpload || done.BytesDone != 600 || done.CurrentFile != "" {
+		t.Errorf("done = %+v, want the bytes of the failed file taken back", done)
+	}
+}
+
+func TestProgressFormatJSON(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "backups/db.sql", make([]byte, 4096), time.Now())
+
+	var stderr bytes.Buffer
+	rootCmd.SetErr(&stderr)
+	defer rootCmd.SetErr(nil)
+	output := runCommand(t, fake, "", "download", "backups", "--destination", t.TempDir(), "--progress-format", "json", "--yes")
+	if strings.Contains(output, `"bytes_done"`) {
+		t.Errorf("progress events went to stdout:\n%s", output)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	var last progressEvent
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("last line of stderr is not an event: %v\n%s\n%s", err, stderr.String(), output)
+	}
+	if last.Event != "done" || last.Operation != s3client.TransferDownload || last.BytesDone != 4096 || last.BytesTotal != 4096 || last.FilesDone != 1 {
+		t.Errorf("last event = %+v, want 4096 bytes of 1 file downloaded", last)
+	}
+}
+
+func TestProgressFormatInvalid(t *testing.T) {
+	resetCommands(rootCmd)
+	rootCmd.PersistentFlags().Set("progress-format", "xml")
+	defer resetCommands(rootCmd)
+	if _, err := progressFormat(rootCmd); err == nil {
+		t.Error("progressFormat() accepted xml")
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"s3manager/internal/i18n"
+	"strconv"
+	"strings"
+)
+
+// Prompter asks the questions of interactive commands. The answers come from the
+// terminal, from S3MANAGER_ANSWERS, or are all yes with --yes or S3MANAGER_ASSUME_YES.
+type Prompter interface {
+	// Confirm asks a yes/no question, anything but y or yes is no
+	Confirm(question string) (bool, error)
+	// ConfirmName asks to type name, e.g. the bucket name before a mass deletion
+	ConfirmName(question, name string) (bool, error)
+}
+
+// newPrompter returns the prompter of cmd, which writes its questions to out.
+func newPrompter(cmd *cobra.Command, out io.Writer) Prompter {
+	if assumeYes(cmd) {
+		return &yesPrompter{out: out}
+	}
+	if answers, ok := os.LookupEnv("S3MANAGER_ANSWERS"); ok {
+		return &scriptedPrompter{answers: strings.Split(answers, ","), out: out}
+	}
+	return &linePrompter{in: bufio.NewReader(os.Stdin), out: out}
+}
+
+// assumeYes reports whether --yes or S3MANAGER_ASSUME_YES answers every question with yes.
+func assumeYes(cmd *cobra.Command) bool {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+	value := os.Getenv("S3MANAGER_ASSUME_YES")
+	yes, err := strconv.ParseBool(value)
+	return err == nil && yes || strings.EqualFold(value, "yes")
+}
+
+// linePrompter reads one line per answer, from the terminal or whatever stdin is.
+type linePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *linePrompter) Confirm(question string) (bool, error) {
+	answer, err := p.ask(question + i18n.T(" (y/N): "))
+	if err != nil {
+		return false, err
+	}
+	return i18n.IsYes(answer), nil
+}
+
+func (p *linePrompter) ConfirmName(question, name string) (bool, error) {
+	answer, err := p.ask(question + ": ")
+	if err != nil {
+		return false, err
+	}
+	return answer == name, nil
+}
+
+func (p *linePrompter) ask(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	answer, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		if err == io.EOF {
+			return "", fmt.Errorf("no answer to %q, use --yes or S3MANAGER_ANSWERS to run without a terminal", strings.TrimSpace(prompt))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// scriptedPrompter takes the answers from a list, in the order the questions are asked.
+type scriptedPrompter struct {
+	answers []string
+	out     io.Writer
+}
+
+func (p *scriptedPrompter) Confirm(question string) (bool, error) {
+	answer, err := p.next(question + i18n.T(" (y/N): "))
+	if err != nil {
+		return false, err
+	}
+	return i18n.IsYes(answer), nil
+}
+
+func (p *scriptedPrompter) ConfirmName(question, name string) (bool, error) {
+	answer, err := p.next(question + ": ")
+	if err != nil {
+		return false, err
+	}
+	return answer == name, nil
+}
+
+func (p *scriptedPrompter) next(prompt string) (string, error) {
+	if len(p.answers) == 0 {
+		return "", fmt.Errorf("no answer left in S3MANAGER_ANSWERS for %q", strings.TrimSpace(prompt))
+	}
+	answer := strings.TrimSpace(p.answers[0])
+	p.answers = p.answers[1:]
+	fmt.Fprintln(p.out, prompt+answer)
+	return answer, nil
+}
+
+// yesPrompter answers every question with yes, and shows it did.
+type yesPrompter struct {
+	out io.Writer
+}
+
+func (p *yesPrompter) Confirm(question string) (bool, error) {
+	fmt.Fprintln(p.out, question+i18n.T(" (y/N): ")+i18n.T("yes"))
+	return true, nil
+}
+
+func (p *yesPrompter) ConfirmName(question, name string) (bool, error) {
+	fmt.Fprintln(p.out, question+": "+name)
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3fake"
+)
+
+func TestPrompters(t *testing.T) {
+	var out bytes.Buffer
+	line := &linePrompter{in: bufio.NewReader(strings.NewReader("Y\n\nmy-bucket\n")), out: &out}
+	if ok, err := line.Confirm("Continue?"); err != nil || !ok {
+		t.Errorf("Confirm() answered Y = %t, %v, want true", ok, err)
+	}
+	if ok, err := line.Confirm("Continue?"); err != nil || ok {
+		t.Errorf("Confirm() answered with an empty line = %t, %v, want the default no", ok, err)
+	}
+	if ok, err := line.ConfirmName("Type the bucket name", "my-bucket"); err != nil || !ok {
+		t.Errorf("ConfirmName() = %t, %v, want true", ok, err)
+	}
+	if _, err := line.Confirm("Continue?"); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("Confirm() without input error = %v, want a hint at --yes", err)
+	}
+
+	out.Reset()
+	scripted := &scriptedPrompter{answers: strings.Split("yes, other-bucket", ","), out: &out}
+	if ok, _ := scripted.Confirm("Continue?"); !ok {
+		t.Error("Confirm() with the scripted answer yes = false")
+	}
+	if ok, _ := scripted.ConfirmName("Type the bucket name", "my-bucket"); ok {
+		t.Error("ConfirmName() with the scripted answer other-bucket = true")
+	}
+	if _, err := scripted.Confirm("Continue?"); err == nil {
+		t.Error("Confirm() after the last scripted answer should return an error")
+	}
+	if !strings.Contains(out.String(), "Continue? (y/N): yes\n") {
+		t.Errorf("scripted prompts = %q, want the questions with their answers", out.String())
+	}
+}
+
+func TestAssumeYes(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().BoolP("yes", "y", false, "")
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags() error = %v", err)
+		}
+		return cmd
+	}
+
+	for value, want := range map[string]bool{"": false, "0": false, "1": true, "true": true, "yes": true} {
+		t.Setenv("S3MANAGER_ASSUME_YES", value)
+		if got := assumeYes(newCmd()); got != want {
+			t.Errorf("assumeYes() with S3MANAGER_ASSUME_YES=%q = %t, want %t", value, got, want)
+		}
+	}
+	t.Setenv("S3MANAGER_ASSUME_YES", "")
+	if !assumeYes(newCmd("-y")) {
+		t.Error("assumeYes() with -y = false")
+	}
+	if _, ok := newPrompter(newCmd("--yes"), &bytes.Buffer{}).(*yesPrompter); !ok {
+		t.Error("newPrompter() with --yes does not answer yes")
+	}
+}
+
+func TestDeleteOldScriptedAnswers(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "logs/old.log", []byte("old"), time.Now().AddDate(0, 0, -60))
+
+	t.Setenv("S3MANAGER_ANSWERS", "no")
+	output := runCommand(t, fake, "", "delete-old", "--days", "30")
+	if !strings.Contains(output, "Are you sure? (y/N): no") || len(fake.Keys("test-bucket")) != 1 {
+		t.Errorf("delete-old with the scripted answer no printed:\n%s", output)
+	}
+
+	output = runCommand(t, fake, "", "delete-old", "--days", "30", "--yes")
+	if !strings.Contains(output, `"deleted_count": 1`) || len(fake.Keys("test-bucket")) != 0 {
+		t.Errorf("delete-old --yes printed:\n%s", output)
+	}
+}
+
+func TestDeleteOldInRussian(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "logs/old.log", []byte("old"), time.Now().AddDate(0, 0, -60))
+
+	output := runCommand(t, fake, "да\n", "delete-old", "--days", "30", "--folder", "logs", "--lang", "ru")
+	for _, want := range []string{"ВНИМАНИЕ: команда собирается безвозвратно удалить файлы старше 30 дней", "в папке 'logs'", "Продолжить? (д/Н): ", `"deleted_count": 1`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("delete-old --lang ru output lacks %q:\n%s", want, output)
+		}
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 0 {
+		t.Errorf("objects after answering да = %v, want none", keys)
+	}
+}
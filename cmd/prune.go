@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/s3client"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the oldest objects until a folder fits within a size budget",
+	Long: `Delete the oldest objects in a folder until the rest fits within --max-total-size.
+
+Objects are kept from the newest one down for as long as they fit; that object and
+everything older is deleted. Objects under protected prefixes or matching --exclude are
+never deleted but count against the budget, like those --filter does not match. When
+they alone exceed it, the result reports over_budget.`,
+	Example: `  # Keep the backups within a 500GB quota
+  s3manager prune --max-total-size 500GB --folder backups/
+
+  # See what would be deleted
+  s3manager prune --max-total-size 500GB --folder backups/ --dry-run
+
+  # Never delete the manifests
+  s3manager prune --max-total-size 1TB --folder exports/ --exclude "*.json" --confirm
+
+  # Only make room by deleting large dumps, never small files
+  s3manager prune --max-total-size 1TB --folder backups/ --filter 'size>100MB and key~"*.sql.gz"'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPrune(cmd)
+	},
+}
+
+func runPrune(cmd *cobra.Command) {
+	folder, _ := cmd.Flags().GetString("folder")
+	maxTotalSizeFlag, _ := cmd.Flags().GetString("max-total-size")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	maxTotalSize, err := utils.ParseBytes(maxTotalSizeFlag)
+	if err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+	if err := storage.ValidatePatterns(exclude); err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+	objects, err := objectFilter(cmd)
+	if err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+
+	applyDeletionFlags(cmd)
+
+	if !s3Backend() {
+		runPruneStore(cmd, folder, maxTotalSize, exclude, objects)
+		return
+	}
+
+	opts := s3client.PruneOptions{Folder: folder, MaxTotalSize: maxTotalSize, Exclude: exclude, Filter: objects, DryRun: dryRun}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if !confirm && !dryRun {
+		previewOpts := opts
+		previewOpts.DryRun = true
+		preview, err := client.Prune(ctx, previewOpts)
+		if err != nil {
+			utils.PrintError(err, "prune")
+			return
+		}
+
+		bucketName := getBucketName(cmd)
+		warning := i18n.Tf("WARNING: This will %s the oldest files from bucket '%s'", deletionVerb(), bucketName)
+		if folder != "" {
+			warning += i18n.Tf(" in folder '%s'", folder)
+		}
+		if objects != nil {
+			warning += i18n.Tf(" matching %s", objects)
+		}
+		warning += i18n.Tf(" until it fits within %s (now %s)", utils.FormatBytes(maxTotalSize), preview.TotalSizeHuman)
+		ok, err := confirmDeletion(newPrompter(cmd, os.Stdout), os.Stdout, warning, bucketName, len(preview.DeletedFiles), preview.DeletedSizeBytes)
+		if err != nil {
+			utils.PrintError(err, "prune")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Operation cancelled."))
+			return
+		}
+	}
+
+	jb, err := startJob(cmd, "prune")
+	if err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+
+	ctx, unlock, err := holdLock(ctx, cmd, client, "prune")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "prune")
+		return
+	}
+	defer unlock()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Pruning bucket %s to %s\n", getBucketName(cmd), utils.FormatBytes(maxTotalSize))
+		if folder != "" {
+			cmd.Printf("Folder: %s\n", folder)
+		}
+		if dryRun {
+			cmd.Println("DRY RUN MODE: No files will actually be deleted")
+		}
+	}
+
+	result, err := client.Prune(ctx, opts)
+	if err != nil {
+		if result == nil || !result.Interrupted {
+			jb.fail(err, nil)
+			utils.PrintError(err, "prune")
+			return
+		}
+		jb.fail(err, result)
+	} else {
+		jb.succeed(result)
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+
+	if result.OverBudget {
+		slog.Warn("Kept objects exceed the budget", "kept", result.KeptSizeHuman, "budget", result.MaxTotalSizeHuman)
+	}
+}
+
+func init() {
+	pruneCmd.Flags().String("max-total-size", "", "Size budget of the folder, e.g. 500GB (required)")
+	if err := pruneCmd.MarkFlagRequired("max-total-size"); err != nil {
+		utils.PrintError(err, "prune")
+	}
+	pruneCmd.Flags().StringP("folder", "f", "", "Folder/prefix to prune (entire bucket if not specified)")
+	pruneCmd.Flags().StringArray("exclude", []string{}, "Never delete objects matching this glob, e.g. '*.json' (repeatable)")
+	addFilterFlag(pruneCmd, "Only delete objects matching this expression, e.g. 'size>100MB and not key~\"*.json\"'")
+	pruneCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+	pruneCmd.Flags().Bool("dry-run", false, "Show what would be deleted without actually deleting")
+	pruneCmd.Flags().Int("max-delete", 0, "Abort without deleting anything if more objects match, 0 for no limit (default from MAX_DELETE)")
+	addDeletionFlags(pruneCmd)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"regexp"
+	"s3manager/internal/storage"
+)
+
+// addRegexFlag registers --regex, which selects keys by a regular expression where the
+// globs of --pattern or --exclude cannot express a naming convention.
+func addRegexFlag(c *cobra.Command, usage string) {
+	c.Flags().String("regex", "", usage)
+}
+
+// keyRegex compiles --regex, nil when it is not given.
+func keyRegex(cmd *cobra.Command) (*regexp.Regexp, error) {
+	expr, _ := cmd.Flags().GetString("regex")
+	return storage.CompileRegex(expr)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/export"
+	"s3manager/internal/i18n"
+	"s3manager/pkg/utils"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report on the objects in the bucket",
+	Long: `Report on the objects in the bucket.
+
+Reports list a prefix without changing anything and print their result as JSON, or as
+CSV with --output csv for spreadsheets and BI tools. --export also writes the rows to a
+.csv or .parquet file.`,
+}
+
+func init() {
+	reportCmd.AddCommand(reportRetentionCmd)
+	reportCmd.AddCommand(reportDuplicatesCmd)
+	reportCmd.AddCommand(reportTopCmd)
+	reportCmd.AddCommand(reportAccessCmd)
+}
+
+// printReport prints result as JSON, or its table as CSV when format is csv.
+func printReport(format string, result interface{}, table export.Table) error {
+	if format == outputCSV {
+		// Exported files keep the column names, the printed header is for people
+		headed := table
+		headed.Columns = make([]export.Column, len(table.Columns))
+		for i, column := range table.Columns {
+			headed.Columns[i] = export.Column{Name: i18n.T(column.Name), Kind: column.Kind}
+		}
+		if err := export.WriteCSV(os.Stdout, headed); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return nil
+	}
+	return utils.PrintJSON(result)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/export"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var reportDuplicatesCmd = &cobra.Command{
+	Use:   "duplicates [prefix]",
+	Short: "Find objects with identical content",
+	Long: `Find objects with identical content and the space their copies take.
+
+Objects are grouped by size and ETag, which identifies the content of objects uploaded
+in one part. The same file uploaded with different part sizes gets different ETags;
+--verify-content downloads every object that shares its size with another one and
+groups them by SHA-256 instead, which finds those copies too at the cost of reading
+them. Empty objects and the trash are left out.
+
+Each set lists its keys oldest first with the bytes that deleting all but one copy
+would save. Without a prefix the whole bucket is searched.`,
+	Example: `  # Duplicate sets in the whole bucket
+  s3manager report duplicates
+
+  # Compare the content of uploads, as CSV
+  s3manager report duplicates uploads/ --verify-content --output csv`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runReportDuplicates(cmd, args)
+	},
+}
+
+func runReportDuplicates(cmd *cobra.Command, args []string) {
+	var prefix string
+	if len(args) > 0 {
+		prefix = args[0]
+	}
+	verifyContent, _ := cmd.Flags().GetBool("verify-content")
+
+	output, err := outputFormat(cmd, outputJSON, outputCSV)
+	if err != nil {
+		utils.PrintError(err, "report duplicates")
+		return
+	}
+	exportTo, err := exportPath(cmd)
+	if err != nil {
+		utils.PrintError(err, "report duplicates")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "report duplicates")
+		return
+	}
+	if err := useListingCache(cmd, client); err != nil {
+		utils.PrintError(err, "report duplicates")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Searching duplicates under: %s\n", getDestinationDisplay(prefix))
+		if verifyContent {
+			cmd.Println("  Comparing content by SHA-256")
+		}
+	}
+
+	report, err := client.DuplicatesReport(ctx, prefix, verifyContent)
+	if err != nil {
+		utils.PrintError(err, "report duplicates")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		report.BucketName = bucketFlag
+	}
+
+	// One row per object, the set column tells which objects are copies of each other
+	table := export.Table{Columns: []export.Column{
+		{Name: "set", Kind: export.Int64},
+		{Name: "size_bytes", Kind: export.Int64},
+		{Name: "etag", Kind: export.String},
+		{Name: "sha256", Kind: export.String},
+		{Name: "key", Kind: export.String},
+	}}
+	for i, set := range report.Sets {
+		for _, key := range set.Keys {
+			table.Rows = append(table.Rows, []any{int64(i + 1), set.SizeBytes, set.ETag, set.SHA256, key})
+		}
+	}
+	if err := exportTable(cmd, exportTo, table); err != nil {
+		utils.PrintError(err, "report duplicates")
+		return
+	}
+	if err := printReport(output, report, table); err != nil {
+		utils.PrintError(err, "report duplicates")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("%d duplicate objects in %d sets, %s could be saved\n", report.DuplicateObjects, report.SetCount, report.SavingsHuman)
+	}
+}
+
+func init() {
+	reportDuplicatesCmd.Flags().Bool("verify-content", false, "Group objects of the same size by the SHA-256 of their content, which downloads them")
+	addReportOutputFlag(reportDuplicatesCmd)
+	addExportFlag(reportDuplicatesCmd)
+	addCacheFlags(reportDuplicatesCmd)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/export"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
+)
+
+var reportRetentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "Show how the objects under a prefix are spread over age buckets",
+	Long: `Show how the objects under a prefix are spread over age buckets.
+
+Objects and bytes are counted per age bucket, by default younger than 7 days, 30 days,
+90 days and older, so lifecycle rules and --days of delete-old can be chosen from data.
+--buckets sets other upper ages. --prefix can be repeated, or comma-separated, to count
+several prefixes together.`,
+	Example: `  # Aging histogram of the backups
+  s3manager report retention --prefix backups/
+
+  # Yearly buckets as CSV
+  s3manager report retention --prefix logs/ --buckets 30d,365d,730d --output csv
+
+  # One histogram for three applications
+  s3manager report retention --prefix logs/app1/,logs/app2/,logs/app3/`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runReportRetention(cmd)
+	},
+}
+
+func runReportRetention(cmd *cobra.Command) {
+	prefixFlag, _ := cmd.Flags().GetStringArray("prefix")
+	prefixes := splitPrefixes(prefixFlag)
+	bucketsFlag, _ := cmd.Flags().GetStringSlice("buckets")
+
+	output, err := outputFormat(cmd, outputJSON, outputCSV)
+	if err != nil {
+		utils.PrintError(err, "report retention")
+		return
+	}
+	exportTo, err := exportPath(cmd)
+	if err != nil {
+		utils.PrintError(err, "report retention")
+		return
+	}
+
+	bounds := make([]time.Duration, 0, len(bucketsFlag))
+	for _, value := range bucketsFlag {
+		age, err := utils.ParseAge(value)
+		if err != nil || age == 0 {
+			utils.PrintError(fmt.Errorf("invalid --buckets age %q", value), "report retention")
+			return
+		}
+		bounds = append(bounds, age)
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "report retention")
+		return
+	}
+	if err := useListingCache(cmd, client); err != nil {
+		utils.PrintError(err, "report retention")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Counting objects by age under: %s\n", strings.Join(prefixes, ", "))
+	}
+
+	report, err := client.RetentionReport(ctx, prefixes, bounds)
+	if err != nil {
+		utils.PrintError(err, "report retention")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		report.BucketName = bucketFlag
+	}
+
+	table := export.Table{Columns: []export.Column{
+		{Name: "age", Kind: export.String},
+		{Name: "objects", Kind: export.Int64},
+		{Name: "size_bytes", Kind: export.Int64},
+		{Name: "objects_percent", Kind: export.Float64},
+		{Name: "bytes_percent", Kind: export.Float64},
+	}}
+	for _, bucket := range report.Buckets {
+		table.Rows = append(table.Rows, []any{bucket.Age, bucket.Objects, bucket.SizeBytes, bucket.ObjectsPercent, bucket.BytesPercent})
+	}
+	if err := exportTable(cmd, exportTo, table); err != nil {
+		utils.PrintError(err, "report retention")
+		return
+	}
+	if err := printReport(output, report, table); err != nil {
+		utils.PrintError(err, "report retention")
+	}
+}
+
+func init() {
+	reportRetentionCmd.Flags().StringArray("prefix", []string{}, "Prefix whose objects are counted, repeatable or comma-separated (default: whole bucket)")
+	reportRetentionCmd.Flags().StringSlice("buckets", []string{"7d", "30d", "90d"}, "Upper ages of the buckets (e.g. 7d,30d,90d), older objects are counted in a last bucket")
+	addReportOutputFlag(reportRetentionCmd)
+	addExportFlag(reportRetentionCmd)
+	addCacheFlags(reportRetentionCmd)
+}
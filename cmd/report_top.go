@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
+)
+
+var reportTopCmd = &cobra.Command{
+	Use:   "top [prefix...]",
+	Short: "Show the largest or most recently modified objects",
+	Long: `Show the largest or most recently modified objects under a prefix.
+
+--by size lists the biggest objects, for cost reviews; --by recent lists the objects
+modified last, e.g. to see what changed during an incident. Several prefixes, as
+arguments or comma-separated, are ranked together. Without a prefix the whole bucket
+is searched.`,
+	Example: `  # The 50 largest objects in the bucket
+  s3manager report top
+
+  # The 20 objects changed last under uploads/, as CSV
+  s3manager report top uploads/ --by recent --limit 20 --output csv
+
+  # The largest objects of two applications together
+  s3manager report top logs/app1/,logs/app2/`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runReportTop(cmd, args)
+	},
+}
+
+func runReportTop(cmd *cobra.Command, args []string) {
+	prefixes := splitPrefixes(args)
+	by, _ := cmd.Flags().GetString("by")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	output, err := outputFormat(cmd, outputJSON, outputCSV)
+	if err != nil {
+		utils.PrintError(err, "report top")
+		return
+	}
+	exportTo, err := exportPath(cmd)
+	if err != nil {
+		utils.PrintError(err, "report top")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "report top")
+		return
+	}
+	if err := useListingCache(cmd, client); err != nil {
+		utils.PrintError(err, "report top")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Listing top %d objects by %s under: %s\n", limit, by, getDestinationDisplay(strings.Join(prefixes, ", ")))
+	}
+
+	report, err := client.TopObjects(ctx, prefixes, by, limit)
+	if err != nil {
+		utils.PrintError(err, "report top")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		report.BucketName = bucketFlag
+	}
+
+	table := listItemTable(report.Items)
+	if err := exportTable(cmd, exportTo, table); err != nil {
+		utils.PrintError(err, "report top")
+		return
+	}
+	if err := printReport(output, report, table); err != nil {
+		utils.PrintError(err, "report top")
+	}
+}
+
+func init() {
+	reportTopCmd.Flags().String("by", s3client.TopBySize, "Order of the objects: size or recent")
+	reportTopCmd.Flags().IntP("limit", "n", 50, "Number of objects to show")
+	addReportOutputFlag(reportTopCmd)
+	addExportFlag(reportTopCmd)
+	addCacheFlags(reportTopCmd)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
+)
+
+var rewriteCmd = &cobra.Command{
+	Use:   "rewrite <prefix>",
+	Short: "Change the headers and metadata of objects in place",
+	Long: `Set the content type, Cache-Control, storage class or user metadata of every object
+under <prefix>, for example to fix historical uploads stored with a wrong content type.
+--detect-content-type sets the content type from the extension of each key, like upload
+does; keys with an unknown extension keep theirs. --include limits the rewrite to keys
+matching a glob.
+
+S3 cannot change headers in place, so each object is copied onto itself on the server.
+Objects that already have the headers are not copied, the other headers, metadata and
+tags are kept. An object that is replaced while it is rewritten fails the command instead
+of being overwritten with its older content. --dry-run lists the changes without copying
+anything.`,
+	Example: `  # Fix the content type of old uploads, checking the changes first
+  s3manager rewrite site/ --detect-content-type --dry-run
+  s3manager rewrite site/ --detect-content-type --confirm
+
+  # Long caching for hashed assets
+  s3manager rewrite site/assets/ --include "*.js" --set-cache-control "public, max-age=31536000, immutable"
+
+  # Move old reports to infrequent access
+  s3manager rewrite reports/2023/ --set-storage-class STANDARD_IA --confirm`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runRewrite(cmd, args[0])
+	},
+}
+
+func init() {
+	rewriteCmd.Flags().String("set-content-type", "", "Content type to set, e.g. 'text/css'")
+	rewriteCmd.Flags().Bool("detect-content-type", false, "Set the content type from the extension of each key")
+	rewriteCmd.Flags().String("set-cache-control", "", "Cache-Control header to set, e.g. 'public, max-age=3600'")
+	rewriteCmd.Flags().String("set-storage-class", "", "Storage class to set, e.g. STANDARD_IA or GLACIER_IR")
+	rewriteCmd.Flags().StringArray("set-metadata", []string{}, "User metadata to set, as key=value (repeatable)")
+	rewriteCmd.Flags().StringArray("remove-metadata", []string{}, "User metadata key to remove (repeatable)")
+	rewriteCmd.Flags().String("include", "", "Only rewrite objects whose name matches this glob (e.g. '*.svg')")
+	rewriteCmd.Flags().Int("concurrency", 0, "Number of objects rewritten in parallel (default from REQUEST_CONCURRENCY)")
+	rewriteCmd.Flags().Bool("dry-run", false, "Show what would change without copying anything")
+	rewriteCmd.Flags().Bool("confirm", false, "Skip the confirmation prompt")
+}
+
+func runRewrite(cmd *cobra.Command, prefix string) {
+	contentType, _ := cmd.Flags().GetString("set-content-type")
+	detectContentType, _ := cmd.Flags().GetBool("detect-content-type")
+	cacheControl, _ := cmd.Flags().GetString("set-cache-control")
+	storageClass, _ := cmd.Flags().GetString("set-storage-class")
+	metadataFlag, _ := cmd.Flags().GetStringArray("set-metadata")
+	removeMetadata, _ := cmd.Flags().GetStringArray("remove-metadata")
+	include, _ := cmd.Flags().GetString("include")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+
+	// S3 stores user metadata keys in lower case
+	metadata := make(map[string]string, len(metadataFlag))
+	for _, value := range metadataFlag {
+		key, metadataValue, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			utils.PrintError(fmt.Errorf("invalid metadata %q, expected 'key=value'", value), "rewrite")
+			return
+		}
+		metadata[strings.ToLower(strings.TrimSpace(key))] = metadataValue
+	}
+	for i, key := range removeMetadata {
+		removeMetadata[i] = strings.ToLower(key)
+	}
+	storageClass = strings.ToUpper(storageClass)
+	if contentType == "" && !detectContentType && cacheControl == "" && storageClass == "" && len(metadata) == 0 && len(removeMetadata) == 0 {
+		utils.PrintError(fmt.Errorf("nothing to change, use --set-content-type, --detect-content-type, --set-cache-control, --set-storage-class, --set-metadata or --remove-metadata"), "rewrite")
+		return
+	}
+
+	if !confirm && !dryRun {
+		fmt.Println(i18n.T("Rewrite operation summary:"))
+		fmt.Println(i18n.Tf("Bucket: %s", getBucketName(cmd)))
+		fmt.Println(i18n.Tf("Prefix: %s", prefix))
+		if include != "" {
+			fmt.Println(i18n.Tf("Include pattern: %s", include))
+		}
+
+		ok, err := newPrompter(cmd, os.Stdout).Confirm(i18n.T("Continue with rewrite?"))
+		if err != nil {
+			utils.PrintError(err, "rewrite")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Rewrite cancelled."))
+			return
+		}
+	}
+
+	jb, err := startJob(cmd, "rewrite")
+	if err != nil {
+		utils.PrintError(err, "rewrite")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "rewrite")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	result, err := client.Rewrite(ctx, prefix, s3client.RewriteOptions{
+		ContentType:       contentType,
+		DetectContentType: detectContentType,
+		CacheControl:      cacheControl,
+		StorageClass:      storageClass,
+		Metadata:          metadata,
+		RemoveMetadata:    removeMetadata,
+		Include:           include,
+		DryRun:            dryRun,
+		Concurrency:       concurrency,
+	})
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "rewrite")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	jb.succeed(result)
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "rewrite")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Rewrote %d objects, %d already up to date\n", result.Count, result.Unchanged)
+	}
+}
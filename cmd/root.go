@@ -1,8 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/spf13/cobra"
+	"os"
 	"s3manager/config"
+	"s3manager/internal/i18n"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
 )
 
 var (
@@ -14,12 +23,56 @@ var rootCmd = &cobra.Command{
 	Short: "S3 Manager tool for bucket management",
 	Long: `S3 Manager is a command-line tool for managing S3 buckets and objects.
 It provides functionality to get bucket information and manage old files.
-Configuration is loaded from .env file or environment variables`,
+Configuration is loaded from .env file or environment variables. Default values
+for flags can be kept per profile in a config file, see --config and --profile, and
+its [alias] section defines shortcuts for whole command lines`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyProfile(cmd); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		if err := applyLanguage(cmd); err != nil {
+			return err
+		}
+		return applyGlobalFlags(cmd)
+	},
 }
 
 func Execute(config *config.Config) error {
-	cfg = config
-	return rootCmd.Execute()
+	return execute(config, s3client.New, os.Args[1:])
+}
+
+// execute runs the command line args with the configuration c, creating S3 clients with
+// factory.
+func execute(c *config.Config, factory ClientFactory, args []string) error {
+	cfg = c
+	newClient = factory
+
+	args, err := expandAlias(args)
+	if err != nil {
+		return err
+	}
+	args, jobMode, err := expandJob(args)
+	if err != nil {
+		return err
+	}
+	rootCmd.SetArgs(args)
+
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	printedErrors := utils.PrintedErrors()
+	executed, err := rootCmd.ExecuteContextC(ctx)
+	if err == nil && errors.Is(context.Cause(ctx), ErrInterrupted) {
+		err = ErrInterrupted
+	}
+	// Commands print their errors as JSON and succeed, which would let a failed job
+	// container look successful. The arguments are left out, since flag values may hold
+	// secrets.
+	if err == nil && jobMode && utils.PrintedErrors() > printedErrors {
+		err = fmt.Errorf("job %s failed", executed.CommandPath())
+	}
+	return err
 }
 
 func init() {
@@ -27,9 +80,92 @@ func init() {
 	rootCmd.AddCommand(deleteOldCmd)
 	rootCmd.AddCommand(uploadCmd)
 	rootCmd.AddCommand(downloadCmd)
+	rootCmd.AddCommand(deployCmd)
+	rootCmd.AddCommand(bucketCmd)
+	rootCmd.AddCommand(checksumCmd)
+	rootCmd.AddCommand(latestCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(eventsCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(pingCmd)
+	rootCmd.AddCommand(existsCmd)
+	rootCmd.AddCommand(waitCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(inventoryCmd)
+	rootCmd.AddCommand(grepCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(copyCmd)
+	rootCmd.AddCommand(expireCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(ingestCmd)
+	rootCmd.AddCommand(fetchCmd)
+	rootCmd.AddCommand(dumpCmd)
+	rootCmd.AddCommand(appendCmd)
+	rootCmd.AddCommand(concatCmd)
+	rootCmd.AddCommand(touchCmd)
+	rootCmd.AddCommand(rewriteCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(selfUpdateCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(jobCmd)
 
 	rootCmd.PersistentFlags().StringP("bucket", "b", "", "Override bucket name from config")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().String("progress-format", progressText, "Progress of upload, download and deploy: text, the file lines of --verbose, or json for events as JSON lines on stderr")
+	rootCmd.PersistentFlags().Duration("progress-interval", time.Second, "Time between two events of --progress-format json")
+	rootCmd.PersistentFlags().BoolP("yes", "y", false, "Answer yes to every confirmation prompt (default from S3MANAGER_ASSUME_YES)")
+	rootCmd.PersistentFlags().String("lang", "", "Language of prompts, summaries and CSV report headers: "+strings.Join(i18n.Languages(), " or ")+" (default from S3MANAGER_LANG, or en)")
+	rootCmd.PersistentFlags().String("config", "", "Config file with flag defaults per profile (default from S3MANAGER_CONFIG, or s3manager/config in the user config directory)")
+	rootCmd.PersistentFlags().String("profile", "", "Profile of the config file whose flag defaults apply, in addition to [default] (default from S3MANAGER_PROFILE)")
+	rootCmd.PersistentFlags().Var(new(timeoutValue), "timeout", "Operation timeout, e.g. 90s or 45m, 0 for none (default from TIMEOUT, or depends on the command)")
+	rootCmd.PersistentFlags().String("ping-url", "", "Monitoring URL pinged on job start, success and failure (default from PING_URL)")
+	rootCmd.PersistentFlags().String("pre-hook", "", "Shell command run before upload, download, deploy, delete-old, apply, prune and inventory create; the job does not run when it fails (default from PRE_HOOK)")
+	rootCmd.PersistentFlags().String("post-hook", "", "Shell command run after those jobs succeed or fail, with the job report as JSON on stdin (default from POST_HOOK)")
+	rootCmd.PersistentFlags().String("lockfile", "", "Local lock file that keeps overlapping runs of upload, download, deploy, delete-old or apply from starting")
+	rootCmd.PersistentFlags().Duration("lockfile-wait", 0, "How long to wait for a held --lockfile before giving up, 0 to fail immediately")
+	rootCmd.PersistentFlags().String("lock-name", "", "Hold this lock in the bucket while upload, download, deploy, delete-old or apply runs")
+	rootCmd.PersistentFlags().Duration("lock-ttl", s3client.DefaultLockTTL, "Time after which a lock that is no longer renewed is considered stale and taken over")
+	rootCmd.PersistentFlags().String("temp-dir", "", "Directory for temporary archives and snapshots, e.g. on the data volume when /tmp is small (default from TEMP_DIR)")
+	rootCmd.PersistentFlags().Var(new(bytesValue), "memory-budget", "Memory for upload part buffers, e.g. 128MB; parts shrink and fewer are sent at once to fit, 0 for no limit (default from MEMORY_BUDGET)")
+	rootCmd.PersistentFlags().Bool("low-memory", false, "Keep upload buffers to three 5MB parts, for small machines")
+	rootCmd.PersistentFlags().Bool("express", false, "Treat the bucket as an S3 Express One Zone directory bucket and reject names that are not")
+	rootCmd.PersistentFlags().Bool("no-sign-request", false, "Send unsigned requests to read public buckets without credentials")
+	addSettingsFlags(rootCmd.PersistentFlags())
+}
+
+// applyGlobalFlags lets persistent flags override the values loaded from the environment.
+func applyGlobalFlags(cmd *cobra.Command) error {
+	if cfg == nil {
+		return nil
+	}
+	if cmd.Flags().Changed("ping-url") {
+		cfg.PingURL, _ = cmd.Flags().GetString("ping-url")
+	}
+	if cmd.Flags().Changed("pre-hook") {
+		cfg.PreHook, _ = cmd.Flags().GetString("pre-hook")
+	}
+	if cmd.Flags().Changed("post-hook") {
+		cfg.PostHook, _ = cmd.Flags().GetString("post-hook")
+	}
+	if cmd.Flags().Changed("temp-dir") {
+		cfg.TempDir, _ = cmd.Flags().GetString("temp-dir")
+	}
+	if cmd.Flags().Changed("no-sign-request") {
+		cfg.NoSignRequest, _ = cmd.Flags().GetBool("no-sign-request")
+	}
+	if cmd.Flags().Changed("express") {
+		cfg.Express, _ = cmd.Flags().GetBool("express")
+	}
+	if _, err := progressFormat(cmd); err != nil {
+		return err
+	}
+	return applySettings(cmd)
 }
 
 func getBucketName(cmd *cobra.Command) string {
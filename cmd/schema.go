@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"s3manager/internal/schema"
+	"s3manager/pkg/utils"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [type]",
+	Short: "Print the JSON Schema of a command result",
+	Long: `Print the JSON Schema (draft 2020-12) of one of the results the commands print, e.g.
+UploadResult, DownloadResult, DeleteResult or BucketInfo, to validate the output of
+s3manager or generate code for it. Type names are matched in any case.
+
+Without a type, the names of all types are listed. With --output-dir, the schemas of all
+types are written to that directory as <type>.schema.json.
+
+Fields without omitempty in the result are required, and results may gain new fields in
+later versions, which the schemas allow.`,
+	Example: `  # List the types
+  s3manager schema
+
+  # Print the schema of the upload result
+  s3manager schema UploadResult
+
+  # Write all schemas for code generation
+  s3manager schema --output-dir ./schemas`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runSchema(cmd, args)
+	},
+}
+
+func init() {
+	schemaCmd.Flags().String("output-dir", "", "Write the schemas of all types to this directory")
+}
+
+func runSchema(cmd *cobra.Command, args []string) {
+	if dir, _ := cmd.Flags().GetString("output-dir"); dir != "" {
+		if len(args) > 0 {
+			utils.PrintError(fmt.Errorf("--output-dir writes all schemas and takes no type"), "schema")
+			return
+		}
+		if err := writeSchemas(dir); err != nil {
+			utils.PrintError(err, "schema")
+		}
+		return
+	}
+
+	if len(args) == 0 {
+		for _, name := range schema.Names() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	data, err := schema.Get(args[0])
+	if err != nil {
+		utils.PrintError(err, "schema")
+		return
+	}
+	os.Stdout.Write(data)
+}
+
+// writeSchemas writes the schemas of all types to dir, creating it if needed.
+func writeSchemas(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range schema.Names() {
+		data, err := schema.Get(name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, schema.FileName(name)), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
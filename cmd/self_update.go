@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"s3manager/internal/buildinfo"
+	"s3manager/internal/i18n"
+	"s3manager/internal/models"
+	"s3manager/internal/update"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Replace the binary with the latest release",
+	Long: `Download the build of the latest release, or of --release, for the current platform
+from GitHub and replace the running binary with it.
+
+The download is checked against the checksums published with the release, either
+<asset>.sha256 or a checksums file, and the update fails when they do not match or when
+the release publishes none. --skip-verify installs a release without checksums anyway,
+for builds published without them; a published checksum is still checked. The new
+binary is written next to the old one and renamed over it, so a failed update leaves the
+old binary in place; on Windows the old binary is kept as <name>.old.
+
+Nothing is installed when the running version is already the latest, unless --force is
+set, which is also needed to leave development builds or to install an older release.
+The binary must be writable by the current user; updates of binaries installed by a
+package manager should go through that package manager instead.`,
+	Example: `  # Update to the latest release
+  s3manager self-update
+
+  # Install a specific release without asking
+  s3manager self-update --release v1.3.0 --force --confirm`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSelfUpdate(cmd)
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().String("release", "", "Tag of the release to install (default: the latest release)")
+	selfUpdateCmd.Flags().Bool("force", false, "Install even when the release is not newer than the running version")
+	selfUpdateCmd.Flags().Bool("skip-verify", false, "Install a release that publishes no checksums without verifying the download")
+	selfUpdateCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+}
+
+func runSelfUpdate(cmd *cobra.Command) {
+	tag, _ := cmd.Flags().GetString("release")
+	force, _ := cmd.Flags().GetBool("force")
+	skipVerify, _ := cmd.Flags().GetBool("skip-verify")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+
+	path, err := os.Executable()
+	if err == nil {
+		path, err = filepath.EvalSymlinks(path)
+	}
+	if err != nil {
+		utils.PrintError(fmt.Errorf("failed to locate the running binary: %w", err), "self-update")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 10*time.Minute)
+	defer cancel()
+
+	info := buildinfo.Get()
+	updater := newUpdater(info)
+	release, err := updater.Release(ctx, tag)
+	if err != nil {
+		utils.PrintError(err, "self-update")
+		return
+	}
+	result := &models.SelfUpdateResult{
+		PreviousVersion: info.Version,
+		Version:         info.Version,
+		Path:            path,
+		ReleaseURL:      release.URL,
+		OperationTime:   utils.FormatTime(time.Now()),
+	}
+
+	newer, err := update.Compare(release.Tag, info.Version)
+	if !force {
+		if err != nil {
+			utils.PrintError(fmt.Errorf("cannot compare version %s with release %s, use --force to install it anyway", info.Version, release.Tag), "self-update")
+			return
+		}
+		if newer <= 0 {
+			if isVerbose(cmd) {
+				cmd.Printf("s3manager %s is up to date\n", info.Version)
+			}
+			if err := utils.PrintJSON(result); err != nil {
+				utils.PrintError(err, "self-update")
+			}
+			return
+		}
+	}
+
+	if !confirm {
+		ok, err := newPrompter(cmd, os.Stdout).Confirm(i18n.Tf("Replace s3manager %s at %s with %s?", info.Version, path, release.Tag))
+		if err != nil {
+			utils.PrintError(err, "self-update")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Update cancelled."))
+			return
+		}
+	}
+
+	asset, verified, err := updater.Install(ctx, release, path, skipVerify)
+	if errors.Is(err, update.ErrNoChecksum) {
+		err = fmt.Errorf("%w, use --skip-verify to install it unverified", err)
+	}
+	if err != nil {
+		utils.PrintError(err, "self-update")
+		return
+	}
+	if !verified {
+		slog.Warn("Release publishes no checksum, the download was installed without verification", "release", release.Tag, "asset", asset.Name)
+	}
+	result.Version = release.Tag
+	result.Updated = true
+	result.Asset = asset.Name
+	result.ChecksumVerified = verified
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "self-update")
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// addSettingsFlags adds the global flags that override the settings of the environment.
+// The config file sets them like any other flag, for every command or scoped to one, e.g.
+// upload.upload-concurrency = 8.
+func addSettingsFlags(flags *pflag.FlagSet) {
+	flags.Int("max-attempts", 0, "Attempts of failed requests (default from MAX_ATTEMPTS, or 3)")
+	flags.Int("throttle-max-attempts", 0, "Attempts of requests the provider asks to slow down (default from THROTTLE_MAX_ATTEMPTS, or 10)")
+	flags.Var(new(bytesValue), "upload-part-size", "Part size of multipart uploads, at least 5MB (default from UPLOAD_PART_SIZE, or 64MB)")
+	flags.Int("upload-concurrency", 0, "Parts of one upload sent at once (default from UPLOAD_CONCURRENCY, or 5)")
+	flags.Int("list-concurrency", 0, "Listing requests in flight for large prefixes (default from LIST_CONCURRENCY, or 8)")
+	flags.Int("request-concurrency", 0, "Requests about single objects in flight, like tag lookups and trash copies (default from REQUEST_CONCURRENCY, or 16)")
+	flags.Float64("rate-limit", 0, "Maximum S3 API requests per second, 0 for unlimited (default from RATE_LIMIT)")
+	flags.Int("rate-limit-burst", 0, "Requests sent at once before --rate-limit applies (default from RATE_LIMIT_BURST, or 10)")
+}
+
+// applySettings lets the settings flags override the values loaded from the environment.
+// Flags set in the config file count as given, so the file also overrides the environment.
+// --timeout is read by operationContext, which falls back to TIMEOUT.
+func applySettings(cmd *cobra.Command) error {
+	for name, setting := range map[string]*int{
+		"max-attempts":          &cfg.MaxAttempts,
+		"throttle-max-attempts": &cfg.ThrottleMaxAttempts,
+		"upload-concurrency":    &cfg.UploadConcurrency,
+		"list-concurrency":      &cfg.ListConcurrency,
+		"request-concurrency":   &cfg.RequestConcurrency,
+		"rate-limit-burst":      &cfg.RateLimitBurst,
+	} {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+		value, _ := cmd.Flags().GetInt(name)
+		if value < 1 {
+			return fmt.Errorf("--%s must be at least 1", name)
+		}
+		*setting = value
+	}
+
+	if flag := cmd.Flag("upload-part-size"); flag != nil && flag.Changed {
+		if value, ok := flag.Value.(*bytesValue); ok {
+			if *value <= 0 {
+				return fmt.Errorf("--upload-part-size must be positive")
+			}
+			cfg.PartSize = int64(*value)
+		}
+	}
+	if cmd.Flags().Changed("rate-limit") {
+		cfg.RateLimit, _ = cmd.Flags().GetFloat64("rate-limit")
+	}
+	cfg.MemoryBudget = memoryBudget(cmd, cfg.MemoryBudget)
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"s3manager/config"
+)
+
+func TestSettingsPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte(`[default]
+max-attempts = 4
+upload-concurrency = 6
+upload.upload-part-size = 128MB
+`), 0644)
+	t.Setenv("S3MANAGER_PROFILE", "")
+
+	run := func(command string, args ...string) *config.Config {
+		t.Helper()
+		saved := cfg
+		t.Cleanup(func() { cfg = saved })
+		settings := config.DefaultSettings()
+		settings.MaxAttempts = 2
+		settings.ListConcurrency = 3
+		cfg = &config.Config{Settings: settings}
+
+		root := &cobra.Command{Use: "s3manager"}
+		root.PersistentFlags().String("config", "", "")
+		root.PersistentFlags().String("profile", "", "")
+		root.PersistentFlags().Bool("verbose", false, "")
+		root.PersistentFlags().Var(new(bytesValue), "memory-budget", "")
+		root.PersistentFlags().Bool("low-memory", false, "")
+		addSettingsFlags(root.PersistentFlags())
+		for _, name := range []string{"upload", "download"} {
+			root.AddCommand(&cobra.Command{Use: name, Run: func(cmd *cobra.Command, args []string) {}})
+		}
+		root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			if err := applyProfile(cmd); err != nil {
+				return err
+			}
+			return applySettings(cmd)
+		}
+
+		root.SetArgs(append([]string{command, "--config", path}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("Execute(%s %v) error = %v", command, args, err)
+		}
+		return cfg
+	}
+
+	got := run("download")
+	if got.ListConcurrency != 3 {
+		t.Errorf("ListConcurrency = %d, want 3 from the environment", got.ListConcurrency)
+	}
+	if got.MaxAttempts != 4 || got.UploadConcurrency != 6 {
+		t.Errorf("MaxAttempts, UploadConcurrency = %d, %d, want 4, 6 from the config file", got.MaxAttempts, got.UploadConcurrency)
+	}
+	if got.PartSize != config.DefaultSettings().PartSize {
+		t.Errorf("PartSize = %d, want the default outside of upload", got.PartSize)
+	}
+
+	got = run("upload", "--max-attempts", "1")
+	if got.PartSize != 128<<20 {
+		t.Errorf("PartSize = %d, want 128MB from the upload setting", got.PartSize)
+	}
+	if got.MaxAttempts != 1 {
+		t.Errorf("MaxAttempts = %d, want 1 from the command line", got.MaxAttempts)
+	}
+}
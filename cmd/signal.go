@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ErrInterrupted is returned by Execute when the command was stopped by SIGINT or SIGTERM.
+var ErrInterrupted = errors.New("interrupted")
+
+// signalContext returns a context that is cancelled on the first SIGINT or SIGTERM, so
+// running operations can stop cleanly and report what they finished. After that the
+// default signal handling is restored and a second signal terminates immediately.
+func signalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			slog.Warn("Received signal, stopping the operation (send again to force exit)", "signal", sig.String())
+			cancel(ErrInterrupted)
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
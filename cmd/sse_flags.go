@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+)
+
+// addSSECustomerKeyFlag registers --sse-c-key, the customer-provided key objects are
+// encrypted with on the server.
+func addSSECustomerKeyFlag(c *cobra.Command, usage string) {
+	c.Flags().String("sse-c-key", "", usage)
+}
+
+// sseCustomerKey parses the SSE-C key of the flag name, nil when it is not given.
+func sseCustomerKey(cmd *cobra.Command, name string) (*s3client.SSECustomerKey, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return nil, nil
+	}
+	key, err := s3client.ParseSSECustomerKey(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", name, err)
+	}
+	return key, nil
+}
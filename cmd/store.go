@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"regexp"
+	"s3manager/config"
+	"s3manager/internal/azblob"
+	"s3manager/internal/filter"
+	"s3manager/internal/i18n"
+	"s3manager/internal/localfs"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+	"strings"
+	"time"
+)
+
+// Upload, download, delete-old and prune work with every STORAGE_BACKEND through
+// storage.ObjectStore. The other commands rely on S3 features and only run against S3.
+
+// s3Backend reports whether STORAGE_BACKEND selects S3, which the S3-specific code paths
+// of the commands handle. A file:// BUCKET_NAME selects the local directory backend.
+func s3Backend() bool {
+	return (cfg.Backend == "" || strings.EqualFold(cfg.Backend, "s3")) && !localfs.IsURL(cfg.BucketName)
+}
+
+// newObjectStore returns the container or bucket of the configured STORAGE_BACKEND.
+func newObjectStore() (storage.ObjectStore, error) {
+	return objectStoreFor(cfg)
+}
+
+// objectStoreFor returns the container or bucket c selects, which need not be the
+// configured one.
+func objectStoreFor(c *config.Config) (storage.ObjectStore, error) {
+	backend := strings.ToLower(c.Backend)
+	if localfs.IsURL(c.BucketName) {
+		backend = "file"
+	}
+
+	switch backend {
+	case "", "s3":
+		client, err := newClient(c)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case "azure":
+		client, err := azblob.New(c)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case "file":
+		store, err := localfs.New(c.BucketName)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q, expected s3, azure or file", c.Backend)
+	}
+}
+
+// checkS3OnlyFlags rejects flags that need S3 when another backend is configured.
+func checkS3OnlyFlags(cmd *cobra.Command, names ...string) error {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s is only available with STORAGE_BACKEND=s3", name)
+		}
+	}
+	return nil
+}
+
+func runUploadStore(cmd *cobra.Command, paths []string, destination string, archive bool, archiveName string, excludePatterns []string, inc incremental) {
+	if err := checkS3OnlyFlags(cmd, "sign", "sse-c-key", "delete-source", "move-source-to", "expire-after"); err != nil {
+		utils.PrintError(err, "upload")
+		return
+	}
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		result := createDryRunResult(paths, destination, archive, archiveName, getBucketName(cmd), excludePatterns)
+		if err := utils.PrintJSON(result); err != nil {
+			utils.PrintError(err, "upload")
+		}
+		return
+	}
+
+	jb, err := startJob(cmd, "upload")
+	if err != nil {
+		utils.PrintError(err, "upload")
+		return
+	}
+
+	store, err := newObjectStore()
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "upload")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	ctx, unlock, err := holdLock(ctx, cmd, nil, "upload")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "upload")
+		return
+	}
+	defer unlock()
+
+	result, err := storage.Upload(ctx, store, paths, destination, archive, archiveName, excludePatterns, inc.since, cfg.TempDir)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "upload")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	jb.succeed(result)
+	inc.recordRun()
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "upload")
+	}
+}
+
+func runDownloadStore(cmd *cobra.Command, folder, destination string) {
+	if err := checkS3OnlyFlags(cmd, "dry-run", "if-none-match", "if-modified-since", "verify-signature", "verify-checksum", "sse-c-key", "recursive", "flatten", "on-conflict", "regex"); err != nil {
+		utils.PrintError(err, "download")
+		return
+	}
+
+	jb, err := startJob(cmd, "download")
+	if err != nil {
+		utils.PrintError(err, "download")
+		return
+	}
+
+	store, err := newObjectStore()
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "download")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	ctx, unlock, err := holdLock(ctx, cmd, nil, "download")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "download")
+		return
+	}
+	defer unlock()
+
+	result, err := storage.DownloadLatest(ctx, store, folder, destination)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "download")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	jb.succeed(result)
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "download")
+	}
+}
+
+func runDeleteOldStore(cmd *cobra.Command, folders []string, days int, exclude []string, regex *regexp.Regexp, objects *filter.Filter, dateFromKey *storage.KeyDate, window storage.TimeWindow) {
+	if err := checkS3OnlyFlags(cmd, "tag-filter", "unused-for", "plan-out", "trash", "resume", "journal"); err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	opts := storage.DeleteOptions{Folders: folders, DaysOld: days, DryRun: dryRun, MaxDelete: cfg.MaxDelete, Exclude: exclude, Regex: regex, Filter: objects, DateFromKey: dateFromKey, Window: window}
+	if !cfg.AllowProtected {
+		opts.ProtectedPrefixes = cfg.ProtectedPrefixes
+	}
+
+	store, err := newObjectStore()
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if !confirm && !dryRun {
+		previewOpts := opts
+		previewOpts.DryRun = true
+		preview, err := storage.DeleteOld(ctx, store, previewOpts)
+		if err != nil {
+			utils.PrintError(err, "delete-old")
+			return
+		}
+
+		bucketName := getBucketName(cmd)
+		warning := i18n.Tf("WARNING: This will %s files", deletionVerb())
+		if days > 0 {
+			warning += i18n.Tf(" older than %d days (%s)", days, time.Now().AddDate(0, 0, -days).Format("2006-01-02"))
+		}
+		warning += describeWindow(window)
+		warning += i18n.Tf(" from '%s'", bucketName)
+		warning += describeFolders(folders)
+		if len(exclude) > 0 {
+			warning += i18n.Tf(" except %s", strings.Join(exclude, ", "))
+		}
+		if regex != nil {
+			warning += i18n.Tf(" whose key matches %s", regex)
+		}
+		if objects != nil {
+			warning += i18n.Tf(" matching %s", objects)
+		}
+		ok, err := confirmDeletion(newPrompter(cmd, os.Stdout), os.Stdout, warning, bucketName, len(preview.DeletedFiles), preview.TotalSizeBytes)
+		if err != nil {
+			utils.PrintError(err, "delete-old")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Operation cancelled."))
+			return
+		}
+	}
+
+	jb, err := startJob(cmd, "delete-old")
+	if err != nil {
+		utils.PrintError(err, "delete-old")
+		return
+	}
+
+	ctx, unlock, err := holdLock(ctx, cmd, nil, "delete-old")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "delete-old")
+		return
+	}
+	defer unlock()
+
+	result, err := storage.DeleteOld(ctx, store, opts)
+	if err != nil {
+		if result == nil || !result.Interrupted {
+			jb.fail(err, nil)
+			utils.PrintError(err, "delete-old")
+			return
+		}
+		jb.fail(err, result)
+	} else {
+		jb.succeed(result)
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "delete-old")
+	}
+}
+
+func runPruneStore(cmd *cobra.Command, folder string, maxTotalSize int64, exclude []string, objects *filter.Filter) {
+	if err := checkS3OnlyFlags(cmd, "trash"); err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	opts := storage.PruneOptions{Folder: folder, MaxTotalSize: maxTotalSize, Exclude: exclude, Filter: objects, MaxDelete: cfg.MaxDelete, DryRun: dryRun}
+	if !cfg.AllowProtected {
+		opts.ProtectedPrefixes = cfg.ProtectedPrefixes
+	}
+
+	store, err := newObjectStore()
+	if err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if !confirm && !dryRun {
+		previewOpts := opts
+		previewOpts.DryRun = true
+		preview, err := storage.Prune(ctx, store, previewOpts)
+		if err != nil {
+			utils.PrintError(err, "prune")
+			return
+		}
+
+		bucketName := getBucketName(cmd)
+		warning := i18n.Tf("WARNING: This will %s the oldest files from bucket '%s'", deletionVerb(), bucketName)
+		if folder != "" {
+			warning += i18n.Tf(" in folder '%s'", folder)
+		}
+		if objects != nil {
+			warning += i18n.Tf(" matching %s", objects)
+		}
+		warning += i18n.Tf(" until it fits within %s (now %s)", utils.FormatBytes(maxTotalSize), preview.TotalSizeHuman)
+		ok, err := confirmDeletion(newPrompter(cmd, os.Stdout), os.Stdout, warning, bucketName, len(preview.DeletedFiles), preview.DeletedSizeBytes)
+		if err != nil {
+			utils.PrintError(err, "prune")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Operation cancelled."))
+			return
+		}
+	}
+
+	jb, err := startJob(cmd, "prune")
+	if err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+
+	ctx, unlock, err := holdLock(ctx, cmd, nil, "prune")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "prune")
+		return
+	}
+	defer unlock()
+
+	result, err := storage.Prune(ctx, store, opts)
+	if err != nil {
+		if result == nil || !result.Interrupted {
+			jb.fail(err, nil)
+			utils.PrintError(err, "prune")
+			return
+		}
+		jb.fail(err, result)
+	} else {
+		jb.succeed(result)
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "prune")
+		return
+	}
+
+	if result.OverBudget {
+		slog.Warn("Kept objects exceed the budget", "kept", result.KeptSizeHuman, "budget", result.MaxTotalSizeHuman)
+	}
+}
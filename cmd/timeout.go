@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"strconv"
+	"time"
+)
+
+// timeoutValue is a duration flag that also accepts a bare number of seconds, the format
+// --timeout had before it became a duration, so existing scripts keep working.
+type timeoutValue time.Duration
+
+func (t *timeoutValue) String() string {
+	if *t == 0 {
+		return "0"
+	}
+	return time.Duration(*t).String()
+}
+
+func (t *timeoutValue) Set(value string) error {
+	duration, err := parseTimeout(value)
+	if err != nil {
+		return err
+	}
+	*t = timeoutValue(duration)
+	return nil
+}
+
+func (t *timeoutValue) Type() string {
+	return "duration"
+}
+
+func parseTimeout(value string) (time.Duration, error) {
+	var duration time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		duration = time.Duration(seconds) * time.Second
+	} else {
+		duration, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timeout %q, expected a duration like 90s or 45m", value)
+		}
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("timeout must not be negative: %s", value)
+	}
+	return duration, nil
+}
+
+// operationContext derives the context for a command from the global --timeout flag, then
+// from TIMEOUT, or from defaultTimeout when neither is set. A timeout of 0 disables the
+// deadline.
+func operationContext(cmd *cobra.Command, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := defaultTimeout
+	if cfg != nil && cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+	if flag := cmd.Flag("timeout"); flag != nil && flag.Changed {
+		if value, ok := flag.Value.(*timeoutValue); ok {
+			timeout = time.Duration(*value)
+		}
+	}
+
+	if timeout == 0 {
+		return context.WithCancel(cmd.Context())
+	}
+	return context.WithTimeout(cmd.Context(), timeout)
+}
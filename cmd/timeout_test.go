@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"45m", 45 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"300", 300 * time.Second},
+		{"0", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeout(tt.value)
+		if err != nil {
+			t.Errorf("parseTimeout(%q) error = %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseTimeout(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"soon", "-5m", "-1"} {
+		if _, err := parseTimeout(invalid); err == nil {
+			t.Errorf("parseTimeout(%q) should return error", invalid)
+		}
+	}
+}
+
+func TestOperationContext(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Var(new(timeoutValue), "timeout", "")
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags() error = %v", err)
+		}
+		cmd.SetContext(context.Background())
+		return cmd
+	}
+
+	ctx, cancel := operationContext(newCmd(), time.Hour)
+	deadline, ok := ctx.Deadline()
+	cancel()
+	if !ok || time.Until(deadline) < 59*time.Minute {
+		t.Errorf("default deadline = %v, %t, want about 1h", deadline, ok)
+	}
+
+	ctx, cancel = operationContext(newCmd("--timeout", "10s"), time.Hour)
+	deadline, ok = ctx.Deadline()
+	cancel()
+	if !ok || time.Until(deadline) > 10*time.Second {
+		t.Errorf("--timeout 10s deadline = %v, %t, want about 10s", deadline, ok)
+	}
+
+	ctx, cancel = operationContext(newCmd("--timeout", "0"), time.Hour)
+	_, ok = ctx.Deadline()
+	cancel()
+	if ok {
+		t.Errorf("--timeout 0 should not set a deadline")
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var touchCmd = &cobra.Command{
+	Use:   "touch <key|prefix>",
+	Short: "Reset the LastModified time of objects",
+	Long: `Reset the LastModified time of an object to now, or of every object under a prefix
+when no object has that key or it ends with a slash. Age-based cleanups like delete-old
+and lifecycle transitions then count from now, which keeps specific objects around.
+
+S3 has no way to change LastModified directly, so each object is copied onto itself on
+the server. Content type, metadata, storage class and tags are kept. An object that is
+replaced while it is touched fails the command instead of being overwritten with its
+older content.`,
+	Example: `  # Keep a backup out of the 30 day cleanup
+  s3manager touch backups/2026-01-15.tar.gz
+
+  # Touch everything under a folder
+  s3manager touch legal-hold/`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runTouch(cmd, args[0])
+	},
+}
+
+func runTouch(cmd *cobra.Command, target string) {
+	jb, err := startJob(cmd, "touch")
+	if err != nil {
+		utils.PrintError(err, "touch")
+		return
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "touch")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, time.Hour)
+	defer cancel()
+
+	result, err := client.Touch(ctx, target)
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "touch")
+		return
+	}
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+	jb.succeed(result)
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "touch")
+		return
+	}
+
+	if isVerbose(cmd) {
+		cmd.Printf("Touched %d objects (%s)\n", result.Count, result.TotalSizeHuman)
+	}
+}
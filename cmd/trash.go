@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Restore or purge objects moved to the trash",
+	Long: `Restore or purge objects moved to the trash.
+
+With --trash, delete-old, apply and deploy --delete move objects to
+TRASH_PREFIX/<date>/<original key> (default: .trash/) with a server-side copy instead
+of deleting them, giving an undo window on buckets without versioning. These commands
+bring trashed objects back or empty the trash for good.`,
+}
+
+func init() {
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/i18n"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete objects that have been in the trash for too long",
+	Long: `Permanently delete trash folders older than --days days.
+
+--days 0 empties the whole trash, including objects trashed today. Run it from cron to
+keep the undo window at a fixed length.`,
+	Example: `  # Keep a 30 day undo window
+  s3manager trash purge --days 30 --confirm
+
+  # See what emptying the trash would delete
+  s3manager trash purge --days 0 --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashPurge(cmd)
+	},
+}
+
+func runTrashPurge(cmd *cobra.Command) {
+	days, _ := cmd.Flags().GetInt("days")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if days < 0 {
+		utils.PrintError(fmt.Errorf("days must not be negative"), "trash purge")
+		return
+	}
+	if cmd.Flags().Changed("max-delete") {
+		cfg.MaxDelete, _ = cmd.Flags().GetInt("max-delete")
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "trash purge")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if !confirm && !dryRun {
+		preview, err := client.PurgeTrash(ctx, days, true)
+		if err != nil {
+			utils.PrintError(err, "trash purge")
+			return
+		}
+
+		warning := i18n.Tf("WARNING: This will permanently delete trashed objects older than %d days from bucket '%s'",
+			days, getBucketName(cmd))
+		ok, err := confirmDeletion(newPrompter(cmd, os.Stdout), os.Stdout, warning, getBucketName(cmd), preview.Count, preview.TotalSizeBytes)
+		if err != nil {
+			utils.PrintError(err, "trash purge")
+			return
+		}
+		if !ok {
+			fmt.Println(i18n.T("Operation cancelled."))
+			return
+		}
+	}
+
+	result, err := client.PurgeTrash(ctx, days, dryRun)
+	if err != nil {
+		utils.PrintError(err, "trash purge")
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "trash purge")
+	}
+}
+
+func init() {
+	trashPurgeCmd.Flags().IntP("days", "d", 0, "Delete trash folders older than this many days (required, 0 empties the trash)")
+	if err := trashPurgeCmd.MarkFlagRequired("days"); err != nil {
+		utils.PrintError(err, "trash purge")
+	}
+	trashPurgeCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
+	trashPurgeCmd.Flags().Bool("dry-run", false, "Show what would be deleted without deleting")
+	trashPurgeCmd.Flags().Int("max-delete", 0, "Abort without deleting anything if more objects match, 0 for no limit (default from MAX_DELETE)")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <date>",
+	Short: "Move objects trashed on a given day back to their original keys",
+	Long: `Move objects trashed on a given day (YYYY-MM-DD) back to their original keys.
+
+Objects whose original key exists again, for example because a new version was
+uploaded since, are skipped unless --overwrite is given.`,
+	Example: `  # Undo yesterday's cleanup
+  s3manager trash restore 2024-03-14
+
+  # Restore only one folder and see what would happen first
+  s3manager trash restore 2024-03-14 --prefix logs/app/ --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashRestore(cmd, args)
+	},
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) {
+	prefix, _ := cmd.Flags().GetString("prefix")
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	client, err := newClient(cfg)
+	if err != nil {
+		utils.PrintError(err, "trash restore")
+		return
+	}
+
+	ctx, cancel := operationContext(cmd, 30*time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Restoring objects trashed on %s from bucket: %s\n", args[0], getBucketName(cmd))
+	}
+
+	result, err := client.RestoreTrash(ctx, args[0], prefix, overwrite, dryRun)
+	if err != nil {
+		utils.PrintError(err, "trash restore")
+		return
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "trash restore")
+	}
+}
+
+func init() {
+	trashRestoreCmd.Flags().String("prefix", "", "Only restore objects whose original key starts with this prefix")
+	trashRestoreCmd.Flags().Bool("overwrite", false, "Replace objects that exist again at their original key")
+	trashRestoreCmd.Flags().Bool("dry-run", false, "Show what would be restored without moving anything")
+}
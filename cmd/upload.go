@@ -1,14 +1,14 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
 	"path/filepath"
+	"s3manager/internal/i18n"
 	"s3manager/internal/s3client"
+	"s3manager/internal/signing"
 	"s3manager/pkg/utils"
-	"slices"
 	"strings"
 	"time"
 )
@@ -23,7 +23,12 @@ before uploading to S3.
 You can disable archiving with the --no-archive flag to upload files individually.
 
 The destination path in S3 can be specified with the --destination flag.
-If not specified, files will be uploaded to the root of the bucket.`,
+If not specified, files will be uploaded to the root of the bucket.
+
+The destination and --archive-name can contain tokens that are expanded when the upload
+starts: {yyyy}, {yy}, {MM}, {dd}, {HH}, {mm} and {ss} for the local date and time,
+{hostname}, {unix} for the Unix time, {uuid} for a random UUID and {env:VAR} for the
+value of an environment variable.`,
 	Example: `  # Upload single file (archived by default)
   s3manager upload document.pdf
 
@@ -33,18 +38,39 @@ If not specified, files will be uploaded to the root of the bucket.`,
   # Upload to specific S3 folder
   s3manager upload data/ --destination "backups/2024"
 
+  # Partition daily backups by date and host
+  s3manager upload data/ --destination "backups/{yyyy}/{MM}/{dd}/{hostname}"
+
   # Upload without archiving (individual files)
   s3manager upload file1.txt file2.txt --no-archive
 
   # Upload with custom archive name
   s3manager upload project/ --destination "releases" --archive-name "v1.0.0"
 
+  # Name the archive after the host and run, so several hosts share one folder
+  s3manager upload /etc --destination "configs" --archive-name "{hostname}-{unix}"
+
   # Upload with different bucket
   s3manager upload data/ --bucket my-other-bucket
 
   # Exclude specific files from archive
   s3manager upload project/ --exclude "*.log" --exclude ".DS_Store"
 
+  # Only upload files changed since the last successful upload to this destination
+  s3manager upload data/ --no-archive --destination "mirror" --modified-since @last-run
+
+  # Sign the archive with the GnuPG key in SIGNING_KEY, stored as <archive>.sig
+  s3manager upload /var/backups/db --destination "backups" --sign
+
+  # Encrypt with a customer-provided key that S3 never stores
+  s3manager upload data/ --destination "vault" --sse-c-key /etc/s3manager/sse-c.key
+
+  # Scratch upload that expire run deletes after 30 days
+  s3manager upload build/ --destination scratch --expire-after 30d
+
+  # Move a spool directory to S3, each file is deleted once its upload is verified
+  s3manager upload /var/spool/exports --no-archive --delete-source --confirm
+
   # Verbose upload with progress
   s3manager upload large-folder/ --verbose`,
 	Args: cobra.MinimumNArgs(1),
@@ -60,74 +86,172 @@ func runUpload(cmd *cobra.Command, args []string) {
 	confirm, _ := cmd.Flags().GetBool("confirm")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	excludeFlag, _ := cmd.Flags().GetStringSlice("exclude")
+	sign, _ := cmd.Flags().GetBool("sign")
+	deleteSource, _ := cmd.Flags().GetBool("delete-source")
+	moveSourceTo, _ := cmd.Flags().GetString("move-source-to")
+	expireAfter, _ := cmd.Flags().GetString("expire-after")
 
 	if err := utils.ValidatePaths(args); err != nil {
 		utils.PrintError(err, "upload")
 		return
 	}
 
+	// @last-run is tracked per destination template, so that runs uploading to
+	// different dated folders share their state
+	inc, err := parseModifiedSince(cmd, destination)
+	if err != nil {
+		utils.PrintError(err, "upload")
+		return
+	}
+
+	now := time.Now()
+	destination, err = utils.ExpandTemplate(destination, now)
+	if err != nil {
+		utils.PrintError(fmt.Errorf("invalid --destination: %w", err), "upload")
+		return
+	}
+	archiveName, err = expandArchiveName(archiveName, now)
+	if err != nil {
+		utils.PrintError(err, "upload")
+		return
+	}
+
+	var signer *signing.Signer
+	if sign {
+		if signer, err = signing.NewSigner(cfg); err != nil {
+			utils.PrintError(err, "upload")
+			return
+		}
+	}
+	sseKey, err := sseCustomerKey(cmd, "sse-c-key")
+	if err != nil {
+		utils.PrintError(err, "upload")
+		return
+	}
+
 	// Determine if we should archive (default: true, unless --no-archive is specified)
 	shouldArchive := !noArchive
 
+	// Both questions share the prompter, which reads ahead on stdin
+	prompter := newPrompter(cmd, os.Stdout)
 	if len(args) == 1 && !noArchive && !confirm {
 		err := utils.ValidatePaths([]string{args[0]})
 		if err == nil {
 			if !isDirectory(args[0]) {
-				fmt.Printf("Upload single file '%s' as archive? (y/N): ", args[0])
-				var response string
-				_, err := fmt.Scanln(&response)
+				ok, err := prompter.Confirm(i18n.Tf("Upload single file '%s' as archive?", args[0]))
 				if err != nil {
 					utils.PrintError(err, "upload")
 					return
 				}
-				if slices.Contains([]string{"y", "yes"}, strings.ToLower(response)) {
+				if ok {
 					shouldArchive = false
 				}
 			}
 		}
 	}
 
+	var expires time.Time
+	if expireAfter != "" {
+		age, err := utils.ParseAge(expireAfter)
+		if err != nil {
+			utils.PrintError(fmt.Errorf("invalid --expire-after: %w", err), "upload")
+			return
+		}
+		expires = now.Add(age)
+	}
+
+	var disposal *s3client.SourceDisposal
+	if deleteSource || moveSourceTo != "" {
+		if shouldArchive {
+			utils.PrintError(fmt.Errorf("--delete-source and --move-source-to need --no-archive"), "upload")
+			return
+		}
+		disposal = &s3client.SourceDisposal{MoveTo: moveSourceTo}
+	}
+
 	// Show operation summary if not in confirm mode and not dry-run
 	if !confirm && !dryRun {
 		bucketName := getBucketName(cmd)
 
-		fmt.Printf("Upload operation summary:\n")
-		fmt.Printf("Bucket: %s\n", bucketName)
-		fmt.Printf("Destination: %s\n", getDestinationDisplay(destination))
-		fmt.Printf("Files/Folders: %v\n", args)
-		fmt.Printf("Archive: %t\n", shouldArchive)
+		fmt.Println(i18n.T("Upload operation summary:"))
+		fmt.Println(i18n.Tf("Bucket: %s", bucketName))
+		fmt.Println(i18n.Tf("Destination: %s", getDestinationDisplay(destination)))
+		fmt.Println(i18n.Tf("Files/Folders: %v", args))
+		fmt.Println(i18n.Tf("Archive: %t", shouldArchive))
 
 		if shouldArchive && archiveName != "" {
-			fmt.Printf("Archive name: %s\n", archiveName)
+			fmt.Println(i18n.Tf("Archive name: %s", archiveName))
 		}
 
 		if len(excludeFlag) > 0 {
-			fmt.Printf("Exclude patterns: %v\n", excludeFlag)
+			fmt.Println(i18n.Tf("Exclude patterns: %v", excludeFlag))
+		}
+
+		if !inc.since.IsZero() {
+			fmt.Println(i18n.Tf("Modified since: %s", utils.FormatTime(inc.since)))
+		}
+
+		if signer != nil {
+			fmt.Println(i18n.Tf("Signature: %s", signer.Method()))
+		}
+
+		if !expires.IsZero() {
+			fmt.Println(i18n.Tf("Expires: %s", utils.FormatTime(expires)))
+		}
+
+		if moveSourceTo != "" {
+			fmt.Println(i18n.Tf("Move uploaded files to: %s", moveSourceTo))
+		} else if deleteSource {
+			fmt.Println(i18n.T("Delete uploaded files: true"))
 		}
 
-		fmt.Print("Continue with upload? (y/N): ")
-		var response string
-		_, err := fmt.Scanln(&response)
+		ok, err := prompter.Confirm(i18n.T("Continue with upload?"))
 		if err != nil {
 			utils.PrintError(err, "upload")
 			return
 		}
-		if !slices.Contains([]string{"y", "yes"}, strings.ToLower(response)) {
-			fmt.Println("Upload cancelled.")
+		if !ok {
+			fmt.Println(i18n.T("Upload cancelled."))
 			return
 		}
 	}
 
-	client, err := s3client.New(cfg)
+	if !s3Backend() {
+		runUploadStore(cmd, args, destination, shouldArchive, archiveName, excludeFlag, inc)
+		return
+	}
+
+	jb, err := startJob(cmd, "upload")
+	if err != nil {
+		utils.PrintError(err, "upload")
+		return
+	}
+
+	client, err := newClient(cfg)
 	if err != nil {
+		jb.fail(err, nil)
 		utils.PrintError(err, "upload")
 		return
 	}
+	defer showFileProgress(cmd, client)()
+	if signer != nil {
+		client.SetSigner(signer)
+	}
+	client.SetSSECustomerKey(sseKey)
+	client.SetSourceDisposal(disposal)
+	client.SetExpiry(expires)
 
-	timeout, _ := cmd.Flags().GetInt("timeout")
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	ctx, cancel := operationContext(cmd, time.Hour)
 	defer cancel()
 
+	ctx, unlock, err := holdLock(ctx, cmd, client, "upload")
+	if err != nil {
+		jb.fail(err, nil)
+		utils.PrintError(err, "upload")
+		return
+	}
+	defer unlock()
+
 	if isVerbose(cmd) {
 		cmd.Printf("Starting upload operation...\n")
 		cmd.Printf("  Paths: %v\n", args)
@@ -136,32 +260,47 @@ func runUpload(cmd *cobra.Command, args []string) {
 		if len(excludeFlag) > 0 {
 			cmd.Printf("  Exclude patterns: %v\n", excludeFlag)
 		}
+		if !inc.since.IsZero() {
+			cmd.Printf("  Modified since: %s\n", utils.FormatTime(inc.since))
+		}
 		if dryRun {
 			cmd.Println("  DRY RUN MODE: No files will actually be uploaded")
 		}
 	}
 
 	if dryRun {
-		result := createDryRunResult(args, destination, shouldArchive, getBucketName(cmd), excludeFlag)
+		result := createDryRunResult(args, destination, shouldArchive, archiveName, getBucketName(cmd), excludeFlag)
 		if err := utils.PrintJSON(result); err != nil {
 			utils.PrintError(err, "upload")
 			return
 		}
 	} else {
-		result, err := client.UploadFiles(ctx, args, destination, shouldArchive, excludeFlag)
-		if err != nil {
+		result, err := client.UploadFiles(ctx, args, destination, shouldArchive, archiveName, excludeFlag, inc.since)
+		if err != nil && (result == nil || !result.Interrupted) {
+			jb.fail(err, nil)
 			utils.PrintError(err, "upload")
 			return
 		}
+		result.Throttling = client.Throttling()
 
 		if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
 			result.BucketName = bucketFlag
 		}
 
+		if err != nil {
+			jb.fail(err, result)
+		} else {
+			jb.succeed(result)
+			inc.recordRun()
+		}
+
 		if err := utils.PrintJSON(result); err != nil {
 			utils.PrintError(err, "upload")
 			return
 		}
+		if result.Interrupted {
+			return
+		}
 	}
 
 	if isVerbose(cmd) {
@@ -169,6 +308,19 @@ func runUpload(cmd *cobra.Command, args []string) {
 	}
 }
 
+// expandArchiveName expands the tokens in an --archive-name. The result is a file name,
+// so it may not contain a slash; the destination places the archive in a folder.
+func expandArchiveName(name string, now time.Time) (string, error) {
+	expanded, err := utils.ExpandTemplate(name, now)
+	if err != nil {
+		return "", fmt.Errorf("invalid --archive-name: %w", err)
+	}
+	if strings.Contains(expanded, "/") {
+		return "", fmt.Errorf("invalid --archive-name %q: must be a file name, use --destination for folders", expanded)
+	}
+	return expanded, nil
+}
+
 func isDirectory(path string) bool {
 	fileInfo, err := os.Stat(path)
 	if err != nil {
@@ -184,16 +336,15 @@ func getDestinationDisplay(destination string) string {
 	return destination
 }
 
-func createDryRunResult(paths []string, destination string, shouldArchive bool, bucketName string, excludePatterns []string) interface{} {
+func createDryRunResult(paths []string, destination string, shouldArchive bool, archiveName string, bucketName string, excludePatterns []string) interface{} {
 	items := make([]interface{}, 0)
 
 	if shouldArchive {
-		archiveName := utils.GenerateArchiveName(paths, ".zip")
 		remotePath := destination
 		if remotePath != "" && !strings.HasSuffix(remotePath, "/") {
 			remotePath += "/"
 		}
-		remotePath += archiveName
+		remotePath += utils.ArchiveName(archiveName, paths, ".zip")
 
 		items = append(items, map[string]interface{}{
 			"local_path":  strings.Join(paths, ", "),
@@ -239,13 +390,19 @@ func createDryRunResult(paths []string, destination string, shouldArchive bool,
 }
 
 func init() {
-	uploadCmd.Flags().StringP("destination", "d", "", "Destination folder in S3 bucket (optional)")
+	uploadCmd.Flags().StringP("destination", "d", "", "Destination folder in S3 bucket (optional), may contain tokens like {yyyy}/{MM}/{dd}")
 	uploadCmd.Flags().Bool("no-archive", false, "Upload files individually without creating archive")
-	uploadCmd.Flags().StringP("archive-name", "a", "", "Custom name for the archive file (only used with archiving)")
+	uploadCmd.Flags().StringP("archive-name", "a", "", "Custom name for the archive file (only used with archiving), may contain tokens like {hostname}-{unix}")
 	uploadCmd.Flags().Bool("confirm", false, "Skip confirmation prompt")
 	uploadCmd.Flags().Bool("dry-run", false, "Show what would be uploaded without actually uploading")
-	uploadCmd.Flags().Int("timeout", 3600, "Timeout in seconds for the operation (default: 1 hour)")
 	uploadCmd.Flags().StringSliceP("exclude", "e", []string{}, "Exclude files by pattern (e.g. '*.log', '.DS_Store')")
+	uploadCmd.Flags().String("modified-since", "", "Only upload files modified since this date (2024-01-01 or RFC3339), or since the last successful upload to the destination with @last-run")
+	uploadCmd.Flags().Bool("sign", false, "Upload a detached signature <key>.sig of every file, made with SIGNATURE_METHOD and SIGNING_KEY")
+	addSSECustomerKeyFlag(uploadCmd, "Encrypt the uploaded files with this SSE-C key, a file or base64 of 32 bytes")
+	uploadCmd.Flags().String("expire-after", "", "Tag the uploads to expire after this age, e.g. 30d, for expire run to delete them")
+	uploadCmd.Flags().Bool("delete-source", false, "Delete each local file once the checksum of its upload is verified (needs --no-archive)")
+	uploadCmd.Flags().String("move-source-to", "", "Move each local file to this directory once the checksum of its upload is verified, instead of deleting it")
+	uploadCmd.Flags().String("state-file", "", "File in which @last-run keeps the time of the last upload per destination (default in the user cache directory)")
 
 	uploadCmd.SetUsageTemplate(`Usage:{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Integration tests for upload command
@@ -13,9 +14,7 @@ import (
 // To run these tests, set the environment variable S3_INTEGRATION_TEST=true
 
 func TestUploadCommand(t *testing.T) {
-	if os.Getenv("S3_INTEGRATION_TEST") != "true" {
-		t.Skip("Skipping integration test; set S3_INTEGRATION_TEST=true to run")
-	}
+	integrationEnv(t)
 
 	tempFile, err := os.CreateTemp("", "upload-test-*.txt")
 	if err != nil {
@@ -31,19 +30,6 @@ func TestUploadCommand(t *testing.T) {
 		t.Fatalf("Failed to close temp file: %v", err)
 	}
 
-	os.Setenv("BUCKET_NAME", os.Getenv("TEST_BUCKET_NAME"))
-	os.Setenv("REGION", os.Getenv("TEST_REGION"))
-	os.Setenv("API_URL", os.Getenv("TEST_API_URL"))
-	os.Setenv("ACCESS_KEY", os.Getenv("TEST_ACCESS_KEY"))
-	os.Setenv("SECRET_KEY", os.Getenv("TEST_SECRET_KEY"))
-	defer func() {
-		os.Unsetenv("BUCKET_NAME")
-		os.Unsetenv("REGION")
-		os.Unsetenv("API_URL")
-		os.Unsetenv("ACCESS_KEY")
-		os.Unsetenv("SECRET_KEY")
-	}()
-
 	oldStdout := os.Stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
@@ -134,7 +120,7 @@ func TestCreateDryRunResult(t *testing.T) {
 	destination := "test-folder"
 	bucketName := "test-bucket"
 
-	result1 := createDryRunResult(paths, destination, true, bucketName)
+	result1 := createDryRunResult(paths, destination, true, "", bucketName, nil)
 	resultMap1, ok := result1.(map[string]interface{})
 	if !ok {
 		t.Fatalf("createDryRunResult() did not return a map")
@@ -157,7 +143,7 @@ func TestCreateDryRunResult(t *testing.T) {
 		t.Errorf("items length = %d, want %d", len(items1), 1)
 	}
 
-	result2 := createDryRunResult(paths, destination, false, bucketName)
+	result2 := createDryRunResult(paths, destination, false, "", bucketName, nil)
 	resultMap2, ok := result2.(map[string]interface{})
 	if !ok {
 		t.Fatalf("createDryRunResult() did not return a map")
@@ -171,4 +157,23 @@ func TestCreateDryRunResult(t *testing.T) {
 	if len(items2) != 2 {
 		t.Errorf("items length = %d, want %d", len(items2), 2)
 	}
+
+	result3 := createDryRunResult(paths, destination, true, "web-01", bucketName, nil)
+	items3 := result3.(map[string]interface{})["items"].([]interface{})
+	if remotePath := items3[0].(map[string]interface{})["remote_path"]; remotePath != "test-folder/web-01.zip" {
+		t.Errorf("remote_path = %v, want test-folder/web-01.zip", remotePath)
+	}
+}
+
+func TestExpandArchiveName(t *testing.T) {
+	t.Setenv("S3MANAGER_TEST_SITE", "eu-1")
+	now := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	name, err := expandArchiveName("db-{env:S3MANAGER_TEST_SITE}-{yyyy}{MM}{dd}", now)
+	if err != nil || name != "db-eu-1-20240315" {
+		t.Errorf("expandArchiveName() = %q, %v, want db-eu-1-20240315", name, err)
+	}
+	if _, err := expandArchiveName("{yyyy}/db", now); err == nil {
+		t.Errorf("expandArchiveName() should reject names with a slash")
+	}
 }
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"s3manager/internal/buildinfo"
+	"s3manager/internal/models"
+	"s3manager/internal/update"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build metadata, and check for a newer release",
+	Long: `Print the version, commit, build date, Go version and platform of the binary.
+
+With --check, the latest release is looked up on GitHub and update_available tells
+whether it is newer than the running version; self-update installs it. Development builds
+have the version dev and cannot be compared with releases. GITHUB_TOKEN is sent when set,
+to avoid the rate limit of anonymous requests, and S3MANAGER_RELEASES_URL points the
+lookup to a mirror of the releases API.`,
+	Example: `  # Show the build metadata
+  s3manager version
+
+  # Print only the version
+  s3manager version --short
+
+  # Check for a newer release
+  s3manager version --check`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runVersion(cmd)
+	},
+}
+
+func init() {
+	versionCmd.Flags().Bool("check", false, "Look up the latest release on GitHub and report whether it is newer")
+	versionCmd.Flags().Bool("short", false, "Print only the version")
+	rootCmd.Version = buildinfo.Get().Version
+}
+
+func runVersion(cmd *cobra.Command) {
+	check, _ := cmd.Flags().GetBool("check")
+	short, _ := cmd.Flags().GetBool("short")
+
+	info := buildinfo.Get()
+	if short {
+		fmt.Println(info.Version)
+		return
+	}
+	result := &models.VersionResult{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildDate: info.Date,
+		GoVersion: info.GoVersion,
+		Platform:  info.Platform,
+	}
+
+	if check {
+		ctx, cancel := operationContext(cmd, time.Minute)
+		defer cancel()
+
+		release, err := newUpdater(info).Release(ctx, "")
+		if err != nil {
+			utils.PrintError(err, "version")
+			return
+		}
+		result.LatestVersion = release.Tag
+		result.ReleaseURL = release.URL
+		newer, err := update.Compare(release.Tag, info.Version)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("cannot compare version %s with the latest release %s", info.Version, release.Tag))
+		}
+		result.UpdateAvailable = err == nil && newer > 0
+	}
+
+	if err := utils.PrintJSON(result); err != nil {
+		utils.PrintError(err, "version")
+	}
+}
+
+// newUpdater returns the updater for the releases API of S3MANAGER_RELEASES_URL, GitHub by
+// default.
+func newUpdater(info buildinfo.Info) *update.Updater {
+	return update.New(os.Getenv("S3MANAGER_RELEASES_URL"), os.Getenv("GITHUB_TOKEN"), "s3manager/"+info.Version)
+}
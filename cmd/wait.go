@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/s3client"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait <key|pattern>",
+	Short: "Block until a matching object exists",
+	Long: `Poll the bucket every --interval until an object with the given key, or matching
+the given glob, exists and meets the optional conditions. This lets a pipeline consume an
+artifact that another system produces on its own schedule.
+
+A pattern containing a slash is matched against whole keys, e.g. "exports/*/done.json";
+only the part before the first wildcard is listed. --min-size and --newer-than ignore
+objects that are too small or too old, like a partial upload or yesterday's export.
+
+The global --timeout bounds the wait (default 10m, 0 waits forever). The newest matching
+object is printed as JSON unless --quiet is set, and the exit status is 0 when it was
+found, 1 when the timeout passed first and 2 on errors.`,
+	Example: `  # Wait up to 10 minutes for today's export
+  s3manager wait "exports/$(date -u +%F)/done.json"
+
+  # Wait up to 2 hours for a complete dump, checking every minute
+  s3manager wait "backups/db-*.sql.gz" --min-size 100MB --newer-than 2024-03-15 --timeout 2h --interval 1m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWait(cmd, args[0])
+	},
+}
+
+func runWait(cmd *cobra.Command, pattern string) error {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	minSizeFlag, _ := cmd.Flags().GetString("min-size")
+	newerThanFlag, _ := cmd.Flags().GetString("newer-than")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	fail := func(err error) error {
+		utils.PrintError(err, "wait")
+		// An interrupted wait exits like every other interrupted command
+		if !errors.Is(err, ErrInterrupted) {
+			return exitStatus(cmd, lookupExitError)
+		}
+		return nil
+	}
+
+	opts := s3client.WaitOptions{Pattern: pattern, Interval: interval}
+	if minSizeFlag != "" {
+		size, err := utils.ParseBytes(minSizeFlag)
+		if err != nil {
+			return fail(err)
+		}
+		opts.MinSize = size
+	}
+	if newerThanFlag != "" {
+		t, err := utils.ParseDate(newerThanFlag)
+		if err != nil {
+			return fail(fmt.Errorf("invalid --newer-than: %w", err))
+		}
+		opts.NewerThan = t
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	ctx, cancel := operationContext(cmd, 10*time.Minute)
+	defer cancel()
+
+	if isVerbose(cmd) {
+		cmd.Printf("Waiting for %s in bucket: %s\n", pattern, getBucketName(cmd))
+	}
+
+	result, err := client.Wait(ctx, opts)
+	if err != nil {
+		return fail(err)
+	}
+
+	if bucketFlag := getBucketName(cmd); bucketFlag != cfg.BucketName {
+		result.BucketName = bucketFlag
+	}
+
+	if !quiet {
+		if err := utils.PrintJSON(result); err != nil {
+			return fail(err)
+		}
+	}
+
+	if !result.Found {
+		return exitStatus(cmd, 1)
+	}
+	return nil
+}
+
+func init() {
+	waitCmd.Flags().Duration("interval", 15*time.Second, "Time between two checks of the bucket")
+	waitCmd.Flags().String("min-size", "", "Minimum size of the object (e.g. 100MB)")
+	waitCmd.Flags().String("newer-than", "", "Only count objects modified at or after this date, as 2024-01-01 or RFC3339")
+	waitCmd.Flags().BoolP("quiet", "q", false, "Print nothing, only set the exit status")
+}
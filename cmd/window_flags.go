@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"s3manager/internal/i18n"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+	"time"
+)
+
+// addTimeWindowFlags registers --older-than and --newer-than, which select objects by
+// absolute modification dates.
+func addTimeWindowFlags(c *cobra.Command) {
+	c.Flags().String("older-than", "", "Only objects modified before this date, as 2024-01-01 or RFC3339")
+	c.Flags().String("newer-than", "", "Only objects modified at or after this date, as 2024-01-01 or RFC3339")
+}
+
+// timeWindow parses --older-than and --newer-than.
+func timeWindow(cmd *cobra.Command) (storage.TimeWindow, error) {
+	var window storage.TimeWindow
+	for _, bound := range []struct {
+		flag string
+		t    *time.Time
+	}{{"older-than", &window.Before}, {"newer-than", &window.After}} {
+		value, _ := cmd.Flags().GetString(bound.flag)
+		if value == "" {
+			continue
+		}
+		t, err := utils.ParseDate(value)
+		if err != nil {
+			return window, fmt.Errorf("invalid --%s: %w", bound.flag, err)
+		}
+		*bound.t = t
+	}
+	if !window.Before.IsZero() && !window.After.Before(window.Before) {
+		return window, fmt.Errorf("--newer-than must be before --older-than")
+	}
+	return window, nil
+}
+
+// describeWindow describes window for confirmation prompts.
+func describeWindow(window storage.TimeWindow) string {
+	switch {
+	case !window.After.IsZero() && !window.Before.IsZero():
+		return i18n.Tf(" modified between %s and %s", utils.FormatTime(window.After), utils.FormatTime(window.Before))
+	case !window.After.IsZero():
+		return i18n.Tf(" modified since %s", utils.FormatTime(window.After))
+	case !window.Before.IsZero():
+		return i18n.Tf(" modified before %s", utils.FormatTime(window.Before))
+	}
+	return ""
+}
@@ -1,38 +1,364 @@
 package config
 
 import (
+	"fmt"
 	"github.com/joho/godotenv"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"s3manager/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
+	// Backend selects the storage service: s3 (default) or azure (STORAGE_BACKEND)
+	Backend string
+
 	ApiURL     string
 	AccessKey  string
 	SecretKey  string
 	BucketName string
 	Region     string
+	// Provider selects compatibility settings for non-AWS services (PROVIDER)
+	Provider string
+	// NoSignRequest sends unsigned requests, for public buckets without credentials
+	NoSignRequest bool
+	// Express marks BucketName as a directory bucket (S3 Express One Zone). Names ending
+	// in --x-s3 are recognised without it.
+	Express bool
+
+	// Azure Blob Storage account of STORAGE_BACKEND=azure, BucketName is the container
+	AzureAccount  string
+	AzureKey      string
+	AzureSASToken string
+	// AzureEndpoint replaces https://<account>.blob.core.windows.net, e.g. for Azurite
+	AzureEndpoint string
+
+	CloudFrontDistributionID string
+	CDNPurgeURL              string
+	CDNPurgeToken            string
+
+	// Settings hold the timeouts, retries, concurrency and rate limits of every command
+	Settings
+
+	// MaxDelete aborts deletions of more objects, 0 disables the limit
+	MaxDelete int
+	// ProtectedPrefixes are never deleted unless AllowProtected is set
+	ProtectedPrefixes []string
+	AllowProtected    bool
+	// DeleteExclude globs name objects delete-old keeps however old they are
+	DeleteExclude []string
+	// UseTrash moves deleted objects under TrashPrefix instead of deleting them
+	UseTrash    bool
+	TrashPrefix string
+	// S3 server access logs of BucketName, used to find objects nobody has read
+	AccessLogBucket string
+	AccessLogPrefix string
+
+	// TempDir holds temporary archives and snapshots instead of the system temporary
+	// directory (TEMP_DIR)
+	TempDir string
+
+	// ListingCacheDir keeps the listings of --cached, s3manager/listings in the user
+	// cache directory by default (LISTING_CACHE_DIR)
+	ListingCacheDir string
+	// ListingCacheTTL is how long a cached listing is used (LISTING_CACHE_TTL)
+	ListingCacheTTL time.Duration
+
+	// Detached signatures of uploads, made with gpg (default) or minisign
+	SignatureMethod string
+	// SigningKey is the GnuPG key ID or the minisign secret key file
+	SigningKey string
+	// SignaturePublicKey is the GnuPG keyring, the fingerprint of the GnuPG signing key,
+	// or the minisign public key file
+	SignaturePublicKey string
+
+	// Deletions above either threshold require typing the bucket name to confirm
+	ConfirmThresholdObjects int
+	ConfirmThresholdBytes   int64
+
+	PingURL      string
+	PingStartURL string
+	PingFailURL  string
+
+	// Shell commands run before and after jobs, with the job report on stdin
+	PreHook  string
+	PostHook string
+
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+	EmailFrom     string
+	EmailTo       string
+	EmailNotifyOn string
+
+	// IngestPassword logs ingest in to FTP servers whose URL has no password
+	IngestPassword string
+	// DumpDSN is the database dump connects to when --dsn is not given
+	DumpDSN string
+
+	// storedSecrets are the credentials of the secret store, which Destination reads the
+	// DEST_ credentials from as well
+	storedSecrets map[string]string
 }
 
 func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		slog.Warn(".env file not found, using environment variables only")
 	}
+	stored, err := loadSecretStore()
+	if err != nil {
+		return nil, err
+	}
 
+	secrets := &secretReader{stored: stored}
 	config := &Config{
+		Backend: getEnv("STORAGE_BACKEND", ""),
+
 		ApiURL:     getEnv("API_URL", ""),
-		AccessKey:  getEnv("ACCESS_KEY", ""),
-		SecretKey:  getEnv("SECRET_KEY", ""),
+		AccessKey:  secrets.get("ACCESS_KEY"),
+		SecretKey:  secrets.get("SECRET_KEY"),
 		BucketName: getEnv("BUCKET_NAME", ""),
 		Region:     getEnv("REGION", ""),
+		Provider:   getEnv("PROVIDER", ""),
+
+		AzureAccount:  getEnv("AZURE_STORAGE_ACCOUNT", ""),
+		AzureKey:      secrets.get("AZURE_STORAGE_KEY"),
+		AzureSASToken: secrets.get("AZURE_STORAGE_SAS_TOKEN"),
+		AzureEndpoint: getEnv("AZURE_BLOB_ENDPOINT", ""),
+
+		CloudFrontDistributionID: getEnv("CLOUDFRONT_DISTRIBUTION_ID", ""),
+		CDNPurgeURL:              getEnv("CDN_PURGE_URL", ""),
+		CDNPurgeToken:            secrets.get("CDN_PURGE_TOKEN"),
+
+		Settings: loadSettings(),
+
+		MaxDelete:         getEnvInt("MAX_DELETE", 0),
+		ProtectedPrefixes: getEnvList("PROTECTED_PREFIXES"),
+		DeleteExclude:     getEnvList("DELETE_EXCLUDE"),
+		TrashPrefix:       getEnv("TRASH_PREFIX", ".trash/"),
+		AccessLogBucket:   getEnv("ACCESS_LOG_BUCKET", ""),
+		AccessLogPrefix:   getEnv("ACCESS_LOG_PREFIX", ""),
+
+		TempDir: getEnv("TEMP_DIR", ""),
+
+		ListingCacheDir: getEnv("LISTING_CACHE_DIR", defaultListingCacheDir()),
+		ListingCacheTTL: getEnvDuration("LISTING_CACHE_TTL", 15*time.Minute),
+
+		SignatureMethod:    getEnv("SIGNATURE_METHOD", ""),
+		SigningKey:         getEnv("SIGNING_KEY", ""),
+		SignaturePublicKey: getEnv("SIGNATURE_PUBLIC_KEY", ""),
+
+		ConfirmThresholdObjects: getEnvInt("CONFIRM_THRESHOLD_OBJECTS", 1000),
+		ConfirmThresholdBytes:   getEnvBytes("CONFIRM_THRESHOLD_BYTES", 10<<30),
+
+		PingURL:      getEnv("PING_URL", ""),
+		PingStartURL: getEnv("PING_START_URL", ""),
+		PingFailURL:  getEnv("PING_FAIL_URL", ""),
+
+		PreHook:  getEnv("PRE_HOOK", ""),
+		PostHook: getEnv("POST_HOOK", ""),
+
+		SMTPHost:      getEnv("SMTP_HOST", ""),
+		SMTPPort:      getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:  secrets.get("SMTP_PASSWORD"),
+		EmailFrom:     getEnv("EMAIL_FROM", ""),
+		EmailTo:       getEnv("EMAIL_TO", ""),
+		EmailNotifyOn: getEnv("EMAIL_NOTIFY_ON", "always"),
+
+		IngestPassword: secrets.get("INGEST_PASSWORD"),
+		DumpDSN:        secrets.get("DUMP_DSN"),
+
+		storedSecrets: stored,
+	}
+	if secrets.err != nil {
+		return nil, secrets.err
 	}
 
 	return config, nil
 }
 
+// Destination returns the configuration of the bucket that migrate copies to: cfg with
+// the storage settings replaced by their DEST_ variables, e.g. DEST_API_URL and
+// DEST_BUCKET_NAME. Settings whose DEST_ variable is not set are shared with the source;
+// set it empty to clear one, like DEST_API_URL= for AWS. Credentials may be given in
+// files like for Load, e.g. DEST_SECRET_KEY_FILE. The destination is always written with
+// credentials.
+func Destination(cfg *Config) (*Config, error) {
+	secrets := &secretReader{stored: cfg.storedSecrets}
+	dest := *cfg
+	dest.Backend = getDestEnv("STORAGE_BACKEND", cfg.Backend)
+	dest.ApiURL = getDestEnv("API_URL", cfg.ApiURL)
+	dest.AccessKey = secrets.getDest("ACCESS_KEY", cfg.AccessKey)
+	dest.SecretKey = secrets.getDest("SECRET_KEY", cfg.SecretKey)
+	dest.BucketName = getDestEnv("BUCKET_NAME", "")
+	dest.Region = getDestEnv("REGION", cfg.Region)
+	dest.Provider = getDestEnv("PROVIDER", cfg.Provider)
+	dest.AzureAccount = getDestEnv("AZURE_STORAGE_ACCOUNT", cfg.AzureAccount)
+	dest.AzureKey = secrets.getDest("AZURE_STORAGE_KEY", cfg.AzureKey)
+	dest.AzureSASToken = secrets.getDest("AZURE_STORAGE_SAS_TOKEN", cfg.AzureSASToken)
+	dest.AzureEndpoint = getDestEnv("AZURE_BLOB_ENDPOINT", cfg.AzureEndpoint)
+	dest.NoSignRequest = false
+	dest.Express = false
+	if secrets.err != nil {
+		return nil, secrets.err
+	}
+	return &dest, nil
+}
+
+// getDestEnv returns DEST_<key>, also when it is set empty, and defaultValue when it is
+// not set.
+func getDestEnv(key, defaultValue string) string {
+	if value, ok := os.LookupEnv("DEST_" + key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// secretReader reads the variables that hold credentials. Instead of the credential
+// itself, <key>_FILE may give the path of a file holding it, like a Kubernetes or Docker
+// secret mounted as a file, so that it does not show in the environment of the process.
+// Trailing line breaks of the file are ignored. Credentials set in neither way are taken
+// from stored, those of the secret store. The first error is kept in err.
+type secretReader struct {
+	stored map[string]string
+	err    error
+}
+
+// get returns the credential of key, "" when neither key, <key>_FILE nor the secret
+// store set it.
+func (r *secretReader) get(key string) string {
+	value := os.Getenv(key)
+	path := os.Getenv(key + "_FILE")
+	if value != "" && path != "" {
+		r.fail(fmt.Errorf("both %s and %s_FILE are set", key, key))
+		return ""
+	}
+	if path != "" {
+		return r.read(key, path)
+	}
+	if value == "" {
+		return r.stored[key]
+	}
+	return value
+}
+
+// getDest returns the credential of DEST_<key> like getDestEnv, also read from
+// DEST_<key>_FILE.
+func (r *secretReader) getDest(key, defaultValue string) string {
+	path := os.Getenv("DEST_" + key + "_FILE")
+	if _, ok := os.LookupEnv("DEST_" + key); ok && path != "" {
+		r.fail(fmt.Errorf("both DEST_%s and DEST_%s_FILE are set", key, key))
+		return ""
+	}
+	if path != "" {
+		return r.read("DEST_"+key, path)
+	}
+	if value, ok := r.stored["DEST_"+key]; ok {
+		defaultValue = value
+	}
+	return getDestEnv(key, defaultValue)
+}
+
+func (r *secretReader) read(key, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.fail(fmt.Errorf("failed to read %s_FILE: %w", key, err))
+		return ""
+	}
+	return strings.TrimRight(string(data), "\r\n")
+}
+
+func (r *secretReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid integer in environment, using default", "key", key, "value", value)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Warn("Invalid number in environment, using default", "key", key, "value", value)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvBytes(key string, defaultValue int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := utils.ParseBytes(value)
+	if err != nil {
+		slog.Warn("Invalid size in environment, using default", "key", key, "value", value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvDuration accepts a duration like 45m or a number of seconds.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		slog.Warn("Invalid duration in environment, using default", "key", key, "value", value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvList splits a comma-separated variable, ignoring empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// defaultListingCacheDir returns s3manager/listings in the user cache directory, "" when
+// there is none.
+func defaultListingCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "s3manager", "listings")
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"github.com/joho/godotenv"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -113,3 +118,215 @@ func TestLoad(t *testing.T) {
 		t.Errorf("config.Region = %s, want %s", config.Region, "")
 	}
 }
+
+func TestGetEnvNumbers(t *testing.T) {
+	os.Setenv("TEST_INT", "8")
+	os.Setenv("TEST_FLOAT", "2.5")
+	os.Setenv("TEST_BAD", "many")
+	os.Setenv("TEST_BYTES", "2GB")
+	defer func() {
+		os.Unsetenv("TEST_BYTES")
+		os.Unsetenv("TEST_INT")
+		os.Unsetenv("TEST_FLOAT")
+		os.Unsetenv("TEST_BAD")
+	}()
+
+	if result := getEnvInt("TEST_INT", 1); result != 8 {
+		t.Errorf("getEnvInt() = %d, want %d", result, 8)
+	}
+
+	if result := getEnvInt("TEST_BAD", 1); result != 1 {
+		t.Errorf("getEnvInt() with invalid value = %d, want %d", result, 1)
+	}
+
+	if result := getEnvFloat("TEST_FLOAT", 0); result != 2.5 {
+		t.Errorf("getEnvFloat() = %f, want %f", result, 2.5)
+	}
+
+	if result := getEnvFloat("NON_EXISTENT_VAR", 1.5); result != 1.5 {
+		t.Errorf("getEnvFloat() with missing value = %f, want %f", result, 1.5)
+	}
+
+	if result := getEnvBytes("TEST_BYTES", 0); result != 2<<30 {
+		t.Errorf("getEnvBytes() = %d, want %d", result, int64(2<<30))
+	}
+
+	if result := getEnvBytes("TEST_BAD", 100); result != 100 {
+		t.Errorf("getEnvBytes() with invalid value = %d, want %d", result, 100)
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	os.Setenv("TEST_LIST", " wal/, ,backups/base/,")
+	defer os.Unsetenv("TEST_LIST")
+
+	result := getEnvList("TEST_LIST")
+	if len(result) != 2 || result[0] != "wal/" || result[1] != "backups/base/" {
+		t.Errorf("getEnvList() = %v, want [wal/ backups/base/]", result)
+	}
+
+	if result := getEnvList("NON_EXISTENT_VAR"); len(result) != 0 {
+		t.Errorf("getEnvList() with missing value = %v, want empty", result)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte(`# Team defaults
+[default]
+exclude = *.log
+upload.destination = backups/{hostname}
+
+[prod]
+bucket = prod-backups
+report  top.limit = 20
+`), 0644)
+
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("LoadProfiles() error = %v", err)
+	}
+	settings, err := profiles.Settings("prod")
+	if err != nil {
+		t.Fatalf("Settings() error = %v", err)
+	}
+	want := []Setting{
+		{Flag: "exclude", Value: "*.log", Profile: "default", Line: 3},
+		{Command: "upload", Flag: "destination", Value: "backups/{hostname}", Profile: "default", Line: 4},
+		{Flag: "bucket", Value: "prod-backups", Profile: "prod", Line: 7},
+		{Command: "report top", Flag: "limit", Value: "20", Profile: "prod", Line: 8},
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("Settings() = %+v, want %+v", settings, want)
+	}
+
+	if settings, _ := profiles.Settings(""); len(settings) != 2 {
+		t.Errorf("Settings(\"\") = %+v, want only [default]", settings)
+	}
+	if _, err := profiles.Settings("staging"); err == nil {
+		t.Errorf("Settings() of an undefined profile should return error")
+	}
+
+	for _, invalid := range []string{"bucket = outside", "[default]\nno value", "[default\nbucket = x"} {
+		os.WriteFile(path, []byte(invalid), 0644)
+		if _, err := LoadProfiles(path); err == nil {
+			t.Errorf("LoadProfiles(%q) should return error", invalid)
+		}
+	}
+}
+
+func TestDestination(t *testing.T) {
+	t.Setenv("DEST_API_URL", "")
+	t.Setenv("DEST_ACCESS_KEY", "dest-key")
+	t.Setenv("DEST_BUCKET_NAME", "new-bucket")
+
+	source := &Config{ApiURL: "https://s3.old.example", AccessKey: "key", SecretKey: "secret", BucketName: "old-bucket", Region: "eu-1", NoSignRequest: true}
+	dest, err := Destination(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Config{AccessKey: "dest-key", SecretKey: "secret", BucketName: "new-bucket", Region: "eu-1"}
+	if !reflect.DeepEqual(*dest, want) {
+		t.Errorf("Destination() = %+v, want %+v", *dest, want)
+	}
+	if source.BucketName != "old-bucket" || source.ApiURL == "" {
+		t.Errorf("Destination() changed the source configuration: %+v", *source)
+	}
+}
+
+func TestSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	accessKey := filepath.Join(dir, "access-key")
+	secretKey := filepath.Join(dir, "secret-key")
+	os.WriteFile(accessKey, []byte("file-access-key\n"), 0600)
+	os.WriteFile(secretKey, []byte("file-secret-key"), 0600)
+	t.Setenv("ACCESS_KEY", "")
+	t.Setenv("ACCESS_KEY_FILE", accessKey)
+	t.Setenv("SECRET_KEY", "")
+	t.Setenv("SECRET_KEY_FILE", secretKey)
+	t.Setenv("DEST_SECRET_KEY_FILE", accessKey)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AccessKey != "file-access-key" || cfg.SecretKey != "file-secret-key" {
+		t.Errorf("Load() read the keys %q and %q", cfg.AccessKey, cfg.SecretKey)
+	}
+	dest, err := Destination(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest.AccessKey != "file-access-key" || dest.SecretKey != "file-access-key" {
+		t.Errorf("Destination() read the keys %q and %q", dest.AccessKey, dest.SecretKey)
+	}
+
+	t.Setenv("SECRET_KEY", "env-secret-key")
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted both SECRET_KEY and SECRET_KEY_FILE")
+	}
+	t.Setenv("SECRET_KEY", "")
+	t.Setenv("SECRET_KEY_FILE", filepath.Join(dir, "missing"))
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted a missing SECRET_KEY_FILE")
+	}
+}
+
+func TestSecretStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/s3manager" || r.Header.Get("X-Vault-Token") != "vault-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"access_key":"vault-access-key","secret_key":"vault-secret-key","dest_secret_key":"vault-dest-key","path":"/tmp/evil"}}`))
+	}))
+	defer server.Close()
+	t.Setenv("SECRETS_PROVIDER", "vault")
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "vault-token")
+	t.Setenv("VAULT_SECRET_PATH", "secret/s3manager")
+	t.Setenv("ACCESS_KEY", "")
+	t.Setenv("SECRET_KEY", "env-secret-key")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AccessKey != "vault-access-key" || cfg.SecretKey != "env-secret-key" {
+		t.Errorf("Load() read the keys %q and %q", cfg.AccessKey, cfg.SecretKey)
+	}
+	// The store stays out of the environment that hooks and tools inherit
+	if os.Getenv("ACCESS_KEY") != "" || os.Getenv("PATH") == "/tmp/evil" {
+		t.Errorf("Load() copied the secret store into the environment")
+	}
+	dest, err := Destination(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest.SecretKey != "vault-dest-key" {
+		t.Errorf("Destination() secret key = %q, want the one of the store", dest.SecretKey)
+	}
+
+	t.Setenv("ACCESS_KEY", "")
+	t.Setenv("VAULT_TOKEN", "wrong")
+	if _, err := Load(); err == nil {
+		t.Error("Load() ignored a failure of the secret store")
+	}
+	t.Setenv("SECRETS_PROVIDER", "keychain")
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted an unknown SECRETS_PROVIDER")
+	}
+}
+
+func TestExampleEnv(t *testing.T) {
+	values, err := godotenv.Unmarshal(ExampleEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"ACCESS_KEY", "SECRET_KEY", "BUCKET_NAME", "REGION"} {
+		if _, ok := values[key]; !ok {
+			t.Errorf("%s is missing from the example", key)
+		}
+	}
+}
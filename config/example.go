@@ -0,0 +1,9 @@
+package config
+
+import _ "embed"
+
+// ExampleEnv is a commented .env file with every variable Load reads, printed by
+// s3manager init env.
+//
+//go:embed example.env
+var ExampleEnv string
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the section of the config file that applies to every profile
+	DefaultProfile = "default"
+	// AliasSection holds user-defined commands instead of flag defaults
+	AliasSection = "alias"
+)
+
+// Profiles are the sections of a config file holding default flag values. The file is
+// INI-like:
+//
+//	[default]
+//	exclude = *.log
+//	upload.destination = backups/{hostname}
+//
+//	[prod]
+//	bucket = prod-backups
+//	delete-old.days = 90
+//
+//	[alias]
+//	backup-db = upload /var/backups/db --destination backups/db --no-archive
+//
+// A key is a flag name, optionally scoped to a command by its path and a dot. Repeated
+// keys give repeatable flags several values. The [alias] section names command lines
+// instead.
+type Profiles struct {
+	Path     string
+	sections map[string][]Setting
+	// Aliases map the name of a user-defined command to the command line it runs
+	Aliases map[string]string
+}
+
+// Setting is one key of a profile.
+type Setting struct {
+	// Command is the command path the setting is scoped to, e.g. "report top", or ""
+	Command string
+	Flag    string
+	Value   string
+	// Profile and Line locate the setting in the file for error messages
+	Profile string
+	Line    int
+}
+
+// ProfilesPath returns S3MANAGER_CONFIG, or the config file in the user configuration
+// directory, ~/.config/s3manager/config on Linux.
+func ProfilesPath() string {
+	if path := os.Getenv("S3MANAGER_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "s3manager", "config")
+}
+
+// LoadProfiles parses the config file at path.
+func LoadProfiles(path string) (*Profiles, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	profiles := &Profiles{Path: path, sections: make(map[string][]Setting), Aliases: make(map[string]string)}
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(text, "["); ok {
+			name, ok = strings.CutSuffix(name, "]")
+			if name = strings.TrimSpace(name); !ok || name == "" {
+				return nil, fmt.Errorf("%s:%d: invalid section %s", path, line, text)
+			}
+			section = name
+			if _, ok := profiles.sections[section]; !ok && section != AliasSection {
+				profiles.sections[section] = nil
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, line)
+		}
+		if section == "" {
+			return nil, fmt.Errorf("%s:%d: %s is outside of a [profile] section", path, line, strings.TrimSpace(key))
+		}
+		if section == AliasSection {
+			name := strings.TrimSpace(key)
+			if name == "" || strings.ContainsAny(name, " \t") || strings.HasPrefix(name, "-") {
+				return nil, fmt.Errorf("%s:%d: invalid alias name %q", path, line, name)
+			}
+			profiles.Aliases[name] = strings.TrimSpace(value)
+			continue
+		}
+		setting := Setting{Value: strings.TrimSpace(value), Profile: section, Line: line}
+		key = strings.TrimSpace(key)
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			setting.Command = strings.Join(strings.Fields(key[:i]), " ")
+			key = key[i+1:]
+		}
+		setting.Flag = strings.TrimPrefix(strings.TrimSpace(key), "--")
+		if setting.Flag == "" {
+			return nil, fmt.Errorf("%s:%d: missing flag name", path, line)
+		}
+		profiles.sections[section] = append(profiles.sections[section], setting)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return profiles, nil
+}
+
+// Settings returns the settings of the default section followed by those of profile, so
+// that later settings override earlier ones.
+func (p *Profiles) Settings(profile string) ([]Setting, error) {
+	settings := p.sections[DefaultProfile]
+	if profile == "" || profile == DefaultProfile {
+		return settings, nil
+	}
+	if profile == AliasSection {
+		return nil, fmt.Errorf("[%s] in %s holds aliases, it is not a profile", AliasSection, p.Path)
+	}
+	selected, ok := p.sections[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not defined in %s", profile, p.Path)
+	}
+	return append(append([]Setting(nil), settings...), selected...), nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"s3manager/internal/secrets"
+)
+
+const secretStoreTimeout = time.Minute
+
+// credentialKeys are the variables a secret store may hold, those read with
+// secretReader. DEST_ variants of them configure the destination of migrate.
+var credentialKeys = map[string]bool{
+	"ACCESS_KEY":              true,
+	"SECRET_KEY":              true,
+	"AZURE_STORAGE_KEY":       true,
+	"AZURE_STORAGE_SAS_TOKEN": true,
+	"CDN_PURGE_TOKEN":         true,
+	"SMTP_PASSWORD":           true,
+	"INGEST_PASSWORD":         true,
+	"DUMP_DSN":                true,
+}
+
+// loadSecretStore fetches the credentials held by the secret store of SECRETS_PROVIDER,
+// nil when there is none. They are kept out of the environment, so that programs run by
+// hooks or dumps do not inherit them; secretReader falls back to them when the
+// environment, .env and <key>_FILE do not set a credential. Other variables of the store
+// are ignored.
+func loadSecretStore() (map[string]string, error) {
+	provider, err := secretStore()
+	if err != nil || provider == nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretStoreTimeout)
+	defer cancel()
+	values, err := provider.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]string, len(values))
+	for name, value := range values {
+		if !credentialKeys[strings.TrimPrefix(name, "DEST_")] {
+			slog.Warn("Ignoring a variable of the secret store that is not a credential", "name", name)
+			continue
+		}
+		stored[name] = value
+	}
+	return stored, nil
+}
+
+// secretStore returns the secret store of SECRETS_PROVIDER, nil when it is not set.
+func secretStore() (secrets.Provider, error) {
+	r := &secretReader{}
+	var provider secrets.Provider
+	switch name := strings.ToLower(getEnv("SECRETS_PROVIDER", "")); name {
+	case "":
+		return nil, nil
+	case secrets.ProviderVault:
+		provider = &secrets.Vault{
+			Addr:      getEnv("VAULT_ADDR", ""),
+			Namespace: getEnv("VAULT_NAMESPACE", ""),
+			Path:      getEnv("VAULT_SECRET_PATH", ""),
+			Token:     r.get("VAULT_TOKEN"),
+			RoleID:    getEnv("VAULT_ROLE_ID", ""),
+			SecretID:  r.get("VAULT_SECRET_ID"),
+			Role:      getEnv("VAULT_ROLE", ""),
+			JWTFile:   getEnv("VAULT_JWT_FILE", ""),
+			AuthMount: getEnv("VAULT_AUTH_MOUNT", ""),
+		}
+	case secrets.ProviderSOPS:
+		provider = &secrets.SOPS{
+			File:   getEnv("SOPS_FILE", ""),
+			Binary: getEnv("SOPS_BINARY", ""),
+		}
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q, expected %s or %s", name, secrets.ProviderVault, secrets.ProviderSOPS)
+	}
+	return provider, r.err
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"time"
+)
+
+// Settings are the operational knobs shared by every command. Each one starts at the
+// default of DefaultSettings, then is overridden by its environment variable, then by the
+// config file and the command line through the global flag of the same name, e.g.
+// UPLOAD_CONCURRENCY, upload.upload-concurrency = 8 and --upload-concurrency.
+type Settings struct {
+	// Timeout replaces the default timeout of every command, 0 keeps the default of each
+	// command (TIMEOUT)
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts of failed requests (MAX_ATTEMPTS)
+	MaxAttempts int
+	// ThrottleMaxAttempts is the number of attempts of requests the provider answers with
+	// SlowDown, at least MaxAttempts (THROTTLE_MAX_ATTEMPTS)
+	ThrottleMaxAttempts int
+
+	// PartSize of multipart uploads, at least 5MB (UPLOAD_PART_SIZE)
+	PartSize int64
+	// UploadConcurrency is the number of parts of one upload sent at once (UPLOAD_CONCURRENCY)
+	UploadConcurrency int
+	// DeleteConcurrency is the number of batch deletes in flight (DELETE_CONCURRENCY)
+	DeleteConcurrency int
+	// ListConcurrency is the number of listing requests in flight for large prefixes
+	// (LIST_CONCURRENCY)
+	ListConcurrency int
+	// RequestConcurrency is the number of requests about single objects in flight, like
+	// tag lookups, trash copies and access log reads (REQUEST_CONCURRENCY)
+	RequestConcurrency int
+
+	// DeleteBatchesPerSecond throttles batch deletes, 0 for no limit (DELETE_BATCHES_PER_SECOND)
+	DeleteBatchesPerSecond float64
+	// RateLimit is the maximum of API requests per second, 0 for no limit (RATE_LIMIT)
+	RateLimit      float64
+	RateLimitBurst int
+
+	// MemoryBudget bounds the upload buffers in bytes, 0 for no limit (MEMORY_BUDGET)
+	MemoryBudget int64
+}
+
+// DefaultSettings returns the settings used when nothing overrides them.
+func DefaultSettings() Settings {
+	return Settings{
+		MaxAttempts:         3,
+		ThrottleMaxAttempts: 10,
+		PartSize:            64 * 1024 * 1024,
+		UploadConcurrency:   5,
+		DeleteConcurrency:   4,
+		ListConcurrency:     8,
+		RequestConcurrency:  16,
+		RateLimitBurst:      10,
+	}
+}
+
+// WithDefaults returns s with the settings that must be positive replaced by their
+// default when they are not.
+func (s Settings) WithDefaults() Settings {
+	defaults := DefaultSettings()
+	positive := func(value *int, defaultValue int) {
+		if *value <= 0 {
+			*value = defaultValue
+		}
+	}
+	positive(&s.MaxAttempts, defaults.MaxAttempts)
+	positive(&s.ThrottleMaxAttempts, defaults.ThrottleMaxAttempts)
+	positive(&s.UploadConcurrency, defaults.UploadConcurrency)
+	positive(&s.DeleteConcurrency, defaults.DeleteConcurrency)
+	positive(&s.ListConcurrency, defaults.ListConcurrency)
+	positive(&s.RequestConcurrency, defaults.RequestConcurrency)
+	positive(&s.RateLimitBurst, defaults.RateLimitBurst)
+	if s.PartSize <= 0 {
+		s.PartSize = defaults.PartSize
+	}
+	s.Timeout = max(s.Timeout, 0)
+	return s
+}
+
+// loadSettings reads the settings from the environment.
+func loadSettings() Settings {
+	defaults := DefaultSettings()
+	return Settings{
+		Timeout:             getEnvDuration("TIMEOUT", 0),
+		MaxAttempts:         getEnvInt("MAX_ATTEMPTS", defaults.MaxAttempts),
+		ThrottleMaxAttempts: getEnvInt("THROTTLE_MAX_ATTEMPTS", defaults.ThrottleMaxAttempts),
+
+		PartSize:           getEnvBytes("UPLOAD_PART_SIZE", defaults.PartSize),
+		UploadConcurrency:  getEnvInt("UPLOAD_CONCURRENCY", defaults.UploadConcurrency),
+		DeleteConcurrency:  getEnvInt("DELETE_CONCURRENCY", defaults.DeleteConcurrency),
+		ListConcurrency:    getEnvInt("LIST_CONCURRENCY", defaults.ListConcurrency),
+		RequestConcurrency: getEnvInt("REQUEST_CONCURRENCY", defaults.RequestConcurrency),
+
+		DeleteBatchesPerSecond: getEnvFloat("DELETE_BATCHES_PER_SECOND", 0),
+		RateLimit:              getEnvFloat("RATE_LIMIT", 0),
+		RateLimitBurst:         getEnvInt("RATE_LIMIT_BURST", defaults.RateLimitBurst),
+
+		MemoryBudget: getEnvBytes("MEMORY_BUDGET", 0),
+	}.WithDefaults()
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadSettings(t *testing.T) {
+	t.Setenv("TIMEOUT", "90")
+	t.Setenv("MAX_ATTEMPTS", "5")
+	t.Setenv("UPLOAD_PART_SIZE", "16MB")
+	t.Setenv("UPLOAD_CONCURRENCY", "0")
+	t.Setenv("LIST_CONCURRENCY", "many")
+	t.Setenv("REQUEST_CONCURRENCY", "32")
+
+	settings := loadSettings()
+	if settings.Timeout != 90*time.Second {
+		t.Errorf("Timeout = %v, want 90s", settings.Timeout)
+	}
+	if settings.MaxAttempts != 5 || settings.RequestConcurrency != 32 {
+		t.Errorf("MaxAttempts, RequestConcurrency = %d, %d, want 5, 32", settings.MaxAttempts, settings.RequestConcurrency)
+	}
+	if settings.PartSize != 16<<20 {
+		t.Errorf("PartSize = %d, want 16MB", settings.PartSize)
+	}
+
+	defaults := DefaultSettings()
+	if settings.UploadConcurrency != defaults.UploadConcurrency {
+		t.Errorf("UploadConcurrency = %d, want the default %d for 0", settings.UploadConcurrency, defaults.UploadConcurrency)
+	}
+	if settings.ListConcurrency != defaults.ListConcurrency {
+		t.Errorf("ListConcurrency = %d, want the default %d for an invalid value", settings.ListConcurrency, defaults.ListConcurrency)
+	}
+
+	t.Setenv("TIMEOUT", "45m")
+	if got := loadSettings().Timeout; got != 45*time.Minute {
+		t.Errorf("Timeout = %v, want 45m", got)
+	}
+}
+
+func TestSettingsWithDefaults(t *testing.T) {
+	got := Settings{DeleteConcurrency: 2, RateLimit: 5, MaxAttempts: -1}.WithDefaults()
+	want := DefaultSettings()
+	want.DeleteConcurrency = 2
+	want.RateLimit = 5
+	if got != want {
+		t.Errorf("WithDefaults() = %+v, want %+v", got, want)
+	}
+}
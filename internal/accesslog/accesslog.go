@@ -0,0 +1,141 @@
+// Package accesslog parses S3 server access logs to find out when objects were last read.
+package accesslog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayout is the format of the bracketed request time, e.g. [06/Feb/2019:00:00:38 +0000]
+const timeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Field positions in a log record
+const (
+	fieldBucket    = 1
+	fieldTime      = 2
+	fieldOperation = 6
+	fieldKey       = 7
+	fieldStatus    = 9
+)
+
+// readOperations are the operations that read an object's data.
+var readOperations = map[string]bool{
+	"REST.GET.OBJECT":      true,
+	"REST.COPY.OBJECT_GET": true,
+}
+
+// Record is one request from an access log.
+type Record struct {
+	Bucket    string
+	Time      time.Time
+	Operation string
+	Key       string
+	Status    int
+}
+
+// IsRead reports whether the request successfully read the object's data.
+func (r Record) IsRead() bool {
+	return readOperations[r.Operation] && r.Status > 0 && r.Status < 400
+}
+
+// ParseLine parses a single log record. Fields added to the format after the
+// HTTP status are ignored.
+func ParseLine(line string) (Record, error) {
+	fields := splitFields(line)
+	if len(fields) <= fieldStatus {
+		return Record{}, fmt.Errorf("invalid access log record: %q", line)
+	}
+
+	requestTime, err := time.Parse(timeLayout, strings.Trim(fields[fieldTime], "[]"))
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid access log time %q: %w", fields[fieldTime], err)
+	}
+
+	// Keys are URL-encoded in the log
+	key := fields[fieldKey]
+	if key == "-" {
+		key = ""
+	} else if decoded, err := url.PathUnescape(key); err == nil {
+		key = decoded
+	}
+
+	// "-" when the request had no response, e.g. the connection was closed
+	status, _ := strconv.Atoi(fields[fieldStatus])
+
+	return Record{
+		Bucket:    fields[fieldBucket],
+		Time:      requestTime,
+		Operation: fields[fieldOperation],
+		Key:       key,
+		Status:    status,
+	}, nil
+}
+
+// LastReads adds the reads of objects in bucket found in r to lastRead, keeping the
+// latest time per key. Records that cannot be parsed are skipped and counted.
+func LastReads(r io.Reader, bucket string, lastRead map[string]time.Time) (skipped int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		record, err := ParseLine(line)
+		if err != nil {
+			skipped++
+			continue
+		}
+		if record.Bucket != bucket || !record.IsRead() {
+			continue
+		}
+		if record.Time.After(lastRead[record.Key]) {
+			lastRead[record.Key] = record.Time
+		}
+	}
+	return skipped, scanner.Err()
+}
+
+// splitFields splits a record on spaces, keeping [bracketed] and "quoted" fields whole.
+func splitFields(line string) []string {
+	var fields []string
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+
+		var end int
+		switch line[i] {
+		case '[':
+			end = closing(line, i, ']')
+		case '"':
+			end = closing(line, i, '"')
+		default:
+			end = strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				end = len(line)
+			} else {
+				end += i
+			}
+		}
+		fields = append(fields, strings.Trim(line[i:end], `"`))
+		i = end
+	}
+	return fields
+}
+
+// closing returns the index just past the delimiter closing the field opened at start.
+func closing(line string, start int, delim byte) int {
+	end := strings.IndexByte(line[start+1:], delim)
+	if end < 0 {
+		return len(line)
+	}
+	return start + end + 2
+}
@@ -0,0 +1,60 @@
+package accesslog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleLog = `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be my-bucket [06/Feb/2019:00:00:38 +0000] 192.0.2.3 79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be 3E57427F3EXAMPLE REST.GET.OBJECT reports/q1%2B%20draft.pdf "GET /my-bucket/reports/q1%2B%20draft.pdf HTTP/1.1" 200 - 113 113 7 - "-" "S3Console/0.4" - s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234= SigV4 ECDHE-RSA-AES128-GCM-SHA256 AuthHeader my-bucket.s3.us-west-1.amazonaws.com TLSV1.2 - -
+79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be my-bucket [07/Feb/2019:10:00:00 +0000] 192.0.2.3 - 3E57427F3EXAMPLF REST.GET.OBJECT reports/q1%2B%20draft.pdf "GET /my-bucket/reports/q1%2B%20draft.pdf HTTP/1.1" 304 - - 113 7 - "-" "curl/8.0" -
+79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be my-bucket [08/Feb/2019:10:00:00 +0000] 192.0.2.3 - 3E57427F3EXAMPLG REST.GET.OBJECT missing.txt "GET /my-bucket/missing.txt HTTP/1.1" 404 NoSuchKey 243 - 7 - "-" "curl/8.0" -
+79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be my-bucket [08/Feb/2019:11:00:00 +0000] 192.0.2.3 - 3E57427F3EXAMPLH REST.PUT.OBJECT uploads/new.bin "PUT /my-bucket/uploads/new.bin HTTP/1.1" 200 - - 2048 7 - "-" "curl/8.0" -
+79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be other-bucket [09/Feb/2019:10:00:00 +0000] 192.0.2.3 - 3E57427F3EXAMPLI REST.GET.OBJECT reports/other.pdf "GET /other-bucket/reports/other.pdf HTTP/1.1" 200 - 10 10 7 - "-" "curl/8.0" -
+not a log record
+`
+
+func TestParseLine(t *testing.T) {
+	record, err := ParseLine(strings.Split(sampleLog, "\n")[0])
+	if err != nil {
+		t.Fatalf("ParseLine() error = %v", err)
+	}
+
+	want := Record{
+		Bucket:    "my-bucket",
+		Time:      time.Date(2019, 2, 6, 0, 0, 38, 0, time.UTC),
+		Operation: "REST.GET.OBJECT",
+		Key:       "reports/q1+ draft.pdf",
+		Status:    200,
+	}
+	if record.Bucket != want.Bucket || !record.Time.Equal(want.Time) || record.Operation != want.Operation ||
+		record.Key != want.Key || record.Status != want.Status {
+		t.Errorf("ParseLine() = %+v, want %+v", record, want)
+	}
+	if !record.IsRead() {
+		t.Errorf("IsRead() = false for a successful GET")
+	}
+
+	if _, err := ParseLine("my-bucket [bad time] x"); err == nil {
+		t.Errorf("ParseLine() with a truncated record should return error")
+	}
+}
+
+func TestLastReads(t *testing.T) {
+	lastRead := map[string]time.Time{}
+	skipped, err := LastReads(strings.NewReader(sampleLog), "my-bucket", lastRead)
+	if err != nil {
+		t.Fatalf("LastReads() error = %v", err)
+	}
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1", skipped)
+	}
+
+	// Failed reads, writes and other buckets do not count
+	if len(lastRead) != 1 {
+		t.Fatalf("lastRead = %v, want only the report", lastRead)
+	}
+	if got := lastRead["reports/q1+ draft.pdf"]; !got.Equal(time.Date(2019, 2, 7, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("last read = %v, want the later conditional GET", got)
+	}
+}
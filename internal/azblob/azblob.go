@@ -0,0 +1,380 @@
+// Package azblob is a storage backend for Azure Blob Storage containers. It talks to the
+// Blob service REST API directly and authenticates with the storage account key (Shared
+// Key) or a SAS token.
+package azblob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appConfig "s3manager/config"
+	"s3manager/internal/storage"
+)
+
+const (
+	apiVersion = "2021-08-06"
+	// Blobs up to this size are stored with a single Put Blob request, larger ones are
+	// uploaded in blocks of this size and committed with Put Block List
+	blockSize = 64 << 20
+	// Requests are not limited as a whole, since blobs are streamed, but a service that
+	// stops answering fails the request instead of hanging it
+	responseHeaderTimeout = 2 * time.Minute
+)
+
+// Error is a failed Blob service request.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("azure blob request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("azure blob request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+type Client struct {
+	httpClient *http.Client
+	account    string
+	// key is the decoded account key, nil when requests carry a SAS token instead
+	key       []byte
+	sasToken  url.Values
+	container string
+	endpoint  string
+}
+
+var _ storage.ObjectStore = (*Client)(nil)
+
+// New returns a client for the container named by BucketName in the configured account.
+func New(cfg *appConfig.Config) (*Client, error) {
+	if cfg.AzureAccount == "" {
+		return nil, errors.New("AZURE_STORAGE_ACCOUNT is required for STORAGE_BACKEND=azure")
+	}
+	if cfg.BucketName == "" {
+		return nil, errors.New("BUCKET_NAME must name the Azure container")
+	}
+
+	c := &Client{
+		httpClient: newHTTPClient(),
+		account:    cfg.AzureAccount,
+		container:  cfg.BucketName,
+		endpoint:   strings.TrimSuffix(cfg.AzureEndpoint, "/"),
+	}
+	if c.endpoint == "" {
+		c.endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", cfg.AzureAccount)
+	}
+
+	switch {
+	case cfg.AzureSASToken != "":
+		token, err := url.ParseQuery(strings.TrimPrefix(cfg.AzureSASToken, "?"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AZURE_STORAGE_SAS_TOKEN: %w", err)
+		}
+		c.sasToken = token
+	case cfg.AzureKey != "":
+		key, err := base64.StdEncoding.DecodeString(cfg.AzureKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AZURE_STORAGE_KEY, expected the base64 account key: %w", err)
+		}
+		c.key = key
+	default:
+		return nil, errors.New("AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN is required for STORAGE_BACKEND=azure")
+	}
+
+	return c, nil
+}
+
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+	return &http.Client{Transport: transport}
+}
+
+// Name returns the container.
+func (c *Client) Name() string {
+	return c.container
+}
+
+type listResult struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			LastModified  string `xml:"Last-Modified"`
+			ContentLength int64  `xml:"Content-Length"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List returns every blob whose name starts with prefix.
+func (c *Client) List(ctx context.Context, prefix string) ([]storage.Object, error) {
+	var objects []storage.Object
+	marker := ""
+
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, 0, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list blobs: %w", err)
+		}
+		var page listResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse blob list: %w", err)
+		}
+
+		for _, blob := range page.Blobs {
+			lastModified, err := http.ParseTime(blob.Properties.LastModified)
+			if err != nil {
+				return nil, fmt.Errorf("invalid last modified time of %s: %w", blob.Name, err)
+			}
+			objects = append(objects, storage.Object{
+				Key:          blob.Name,
+				Size:         blob.Properties.ContentLength,
+				LastModified: lastModified,
+			})
+		}
+
+		if page.NextMarker == "" {
+			return objects, nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+// Put stores body as a block blob.
+func (c *Client) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	if size >= 0 && size <= blockSize {
+		resp, err := c.do(ctx, http.MethodPut, key, nil, body, size, http.Header{"X-Ms-Blob-Type": {"BlockBlob"}})
+		if err != nil {
+			return fmt.Errorf("failed to upload blob %s: %w", key, err)
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	var blockList bytes.Buffer
+	blockList.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	buf := make([]byte, blockSize)
+	for i := 0; ; i++ {
+		n, err := io.ReadFull(body, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+
+		// Block IDs of a blob must all have the same length
+		id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", i)))
+		query := url.Values{"comp": {"block"}, "blockid": {id}}
+		resp, err := c.do(ctx, http.MethodPut, key, query, bytes.NewReader(buf[:n]), int64(n), nil)
+		if err != nil {
+			return fmt.Errorf("failed to upload block %d of %s: %w", i, key, err)
+		}
+		resp.Body.Close()
+		fmt.Fprintf(&blockList, "<Latest>%s</Latest>", id)
+
+		if n < blockSize {
+			break
+		}
+	}
+	blockList.WriteString("</BlockList>")
+
+	resp, err := c.do(ctx, http.MethodPut, key, url.Values{"comp": {"blocklist"}}, &blockList, int64(blockList.Len()), nil)
+	if err != nil {
+		return fmt.Errorf("failed to commit blocks of %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get opens the content of a blob.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, 0, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes blobs one by one. Blobs that no longer exist count as deleted.
+func (c *Client) Delete(ctx context.Context, keys []string) ([]string, error) {
+	deleted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, 0, nil)
+		var azErr *Error
+		switch {
+		case err == nil:
+			resp.Body.Close()
+		case errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound:
+		default:
+			return deleted, fmt.Errorf("failed to delete blob %s: %w", key, err)
+		}
+		deleted = append(deleted, key)
+	}
+	return deleted, nil
+}
+
+// do sends a request for a blob, or for the container when key is empty, and turns
+// error responses into *Error.
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	rawURL := c.endpoint + "/" + url.PathEscape(c.container)
+	if key != "" {
+		segments := strings.Split(key, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		rawURL += "/" + strings.Join(segments, "/")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Azure endpoint: %w", err)
+	}
+
+	if query == nil {
+		query = url.Values{}
+	}
+	for name, values := range c.sasToken {
+		query[name] = values
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+	}
+	req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", apiVersion)
+	if c.key != nil {
+		req.Header.Set("Authorization", "SharedKey "+c.account+":"+c.signature(req))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// The query of the URL carries the SAS token
+		redacted := *u
+		redacted.RawQuery = ""
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("%s %s: %w", method, redacted.String(), err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		azErr := &Error{StatusCode: resp.StatusCode, Code: resp.Header.Get("X-Ms-Error-Code")}
+		var details struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if xml.NewDecoder(resp.Body).Decode(&details) == nil {
+			azErr.Code = details.Code
+			azErr.Message = details.Message
+		}
+		return nil, azErr
+	}
+	return resp, nil
+}
+
+// signature signs req with the account key as described for the Shared Key scheme of
+// the Blob service.
+func (c *Client) signature(req *http.Request) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(stringToSign(c.account, req)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func stringToSign(account string, req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	parts := []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is sent instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}
+	return strings.Join(parts, "\n") + "\n" + canonicalizedHeaders(req.Header) + canonicalizedResource(account, req.URL)
+}
+
+func canonicalizedHeaders(header http.Header) string {
+	var names []string
+	for name := range header {
+		if strings.HasPrefix(strings.ToLower(name), "x-ms-") {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s:%s\n", name, strings.TrimSpace(header.Get(name)))
+	}
+	return b.String()
+}
+
+func canonicalizedResource(account string, u *url.URL) string {
+	var b strings.Builder
+	b.WriteString("/" + account)
+	if u.Path == "" {
+		b.WriteString("/")
+	} else {
+		b.WriteString(u.EscapedPath())
+	}
+
+	query := u.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		fmt.Fprintf(&b, "\n%s:%s", strings.ToLower(name), strings.Join(values, ","))
+	}
+	return b.String()
+}
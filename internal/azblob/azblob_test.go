@@ -0,0 +1,179 @@
+package azblob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"s3manager/config"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStringToSign(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPut, "https://acct.blob.core.windows.net/backups/db%20dump.sql?comp=block&blockid=MDA%3D", strings.NewReader("data"))
+	req.Header.Set("X-Ms-Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+	req.Header.Set("X-Ms-Version", apiVersion)
+	req.Header.Set("Content-Type", "application/sql")
+
+	want := "PUT\n\n\n4\n\napplication/sql\n\n\n\n\n\n\n" +
+		"x-ms-date:Mon, 02 Jan 2006 15:04:05 GMT\nx-ms-version:" + apiVersion + "\n" +
+		"/acct/backups/db%20dump.sql\nblockid:MDA=\ncomp:block"
+	if got := stringToSign("acct", req); got != want {
+		t.Errorf("stringToSign() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// fakeContainer serves the Blob service operations the client uses.
+type fakeContainer struct {
+	mu    sync.Mutex
+	blobs map[string]string
+}
+
+func (f *fakeContainer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey acct:") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/backups/")
+	switch {
+	case r.URL.Query().Get("comp") == "list":
+		// One blob per page to exercise the continuation marker
+		var names []string
+		for name := range f.blobs {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) && name > r.URL.Query().Get("marker") {
+				names = append(names, name)
+			}
+		}
+		fmt.Fprint(w, `<EnumerationResults><Blobs>`)
+		if len(names) > 0 {
+			first := names[0]
+			for _, name := range names {
+				first = min(first, name)
+			}
+			fmt.Fprintf(w, `<Blob><Name>%s</Name><Properties><Last-Modified>Mon, 02 Jan 2006 15:04:05 GMT</Last-Modified><Content-Length>%d</Content-Length></Properties></Blob>`, first, len(f.blobs[first]))
+			if len(names) > 1 {
+				fmt.Fprintf(w, `</Blobs><NextMarker>%s</NextMarker></EnumerationResults>`, first)
+				return
+			}
+		}
+		fmt.Fprint(w, `</Blobs><NextMarker/></EnumerationResults>`)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.blobs[key] = string(data)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet:
+		data, ok := f.blobs[key]
+		if !ok {
+			w.Header().Set("X-Ms-Error-Code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, data)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>BlobNotFound</Code><Message>The specified blob does not exist.</Message></Error>`)
+			return
+		}
+		delete(f.blobs, key)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(&config.Config{
+		BucketName:    "backups",
+		AzureAccount:  "acct",
+		AzureKey:      "c2VjcmV0",
+		AzureEndpoint: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return client
+}
+
+func TestClient(t *testing.T) {
+	fake := &fakeContainer{blobs: map[string]string{"db/old.sql": "old"}}
+	client := newTestClient(t, fake)
+	ctx := context.Background()
+
+	if err := client.Put(ctx, "db/new dump.sql", strings.NewReader("new"), 3); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if fake.blobs["db/new dump.sql"] != "new" {
+		t.Errorf("blobs = %v, want db/new dump.sql stored", fake.blobs)
+	}
+
+	objects, err := client.List(ctx, "db/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(objects) != 2 || objects[0].Key != "db/new dump.sql" || objects[1].Size != 3 {
+		t.Errorf("List() = %+v", objects)
+	}
+
+	body, err := client.Get(ctx, "db/old.sql")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "old" {
+		t.Errorf("Get() = %q, want old", data)
+	}
+	if _, err := client.Get(ctx, "db/missing.sql"); err == nil || !strings.Contains(err.Error(), "BlobNotFound") {
+		t.Errorf("Get() of a missing blob error = %v, want BlobNotFound", err)
+	}
+
+	deleted, err := client.Delete(ctx, []string{"db/old.sql", "db/missing.sql"})
+	if err != nil || len(deleted) != 2 {
+		t.Errorf("Delete() = %v, %v, want both keys, a missing blob counts as deleted", deleted, err)
+	}
+	if _, ok := fake.blobs["db/old.sql"]; ok {
+		t.Errorf("db/old.sql was not deleted")
+	}
+}
+
+func TestClientErrorsHideSASToken(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	client, err := New(&config.Config{
+		BucketName:    "backups",
+		AzureAccount:  "acct",
+		AzureSASToken: "?sv=2021-08-06&sp=rl&sig=secret",
+		AzureEndpoint: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = client.Get(context.Background(), "db/old.sql")
+	if err == nil {
+		t.Fatal("Get() from a closed server should return error")
+	}
+	if strings.Contains(err.Error(), "sig=") || !strings.Contains(err.Error(), "/backups/db/old.sql") {
+		t.Errorf("Get() error = %q, want the blob URL without the SAS token", err)
+	}
+}
+
+func TestNewRequiresCredentials(t *testing.T) {
+	if _, err := New(&config.Config{BucketName: "backups", AzureAccount: "acct"}); err == nil {
+		t.Errorf("New() without a key or SAS token should return error")
+	}
+	if _, err := New(&config.Config{BucketName: "backups", AzureAccount: "acct", AzureKey: "not base64!"}); err == nil {
+		t.Errorf("New() with an invalid key should return error")
+	}
+}
@@ -0,0 +1,64 @@
+// Package buildinfo describes the running binary. Release builds set the version, commit
+// and date with
+//
+//	go build -ldflags "-X s3manager/internal/buildinfo.Version=v1.4.0 \
+//	  -X s3manager/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X s3manager/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Other builds fall back to what the Go toolchain recorded, like the commit of the checkout
+// that was built.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// DevVersion is the version of builds that did not set one.
+const DevVersion = "dev"
+
+// Set by the linker, see the package documentation.
+var (
+	Version = ""
+	Commit  = ""
+	Date    = ""
+)
+
+// Info is the build metadata of the running binary.
+type Info struct {
+	Version   string
+	Commit    string
+	Date      string
+	GoVersion string
+	Platform  string
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.Date == "":
+				info.Date = setting.Value
+			case setting.Key == "vcs.modified" && setting.Value == "true" && info.Commit != "" && Commit == "":
+				info.Commit += "-dirty"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = DevVersion
+	}
+	return info
+}
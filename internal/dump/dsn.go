@@ -0,0 +1,171 @@
+package dump
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"s3manager/pkg/utils"
+)
+
+// engineSchemes are the URL schemes of the DSNs of each engine.
+var engineSchemes = map[string][]string{
+	EnginePostgres: {"postgres", "postgresql"},
+	EngineMySQL:    {"mysql", "mariadb"},
+}
+
+// NormalizeEngine returns the engine called name, accepting postgres for pg and mariadb
+// for mysql.
+func NormalizeEngine(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "pg", "postgres", "postgresql":
+		return EnginePostgres, nil
+	case "mysql", "mariadb":
+		return EngineMySQL, nil
+	}
+	return "", fmt.Errorf("unsupported database %q, expected pg or mysql", name)
+}
+
+// connection is the database a DSN points to.
+type connection struct {
+	engine   string
+	user     string
+	password string
+	host     string
+	port     string
+	database string
+	// dsn is the DSN without its password
+	dsn *url.URL
+}
+
+func parseDSN(engine, dsn string) (*connection, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("no DSN, give it with --dsn or DUMP_DSN")
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		// the error of url.Parse quotes the DSN, password included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid DSN %q: %w", Redact(dsn), err)
+	}
+	schemes := engineSchemes[engine]
+	if !contains(schemes, u.Scheme) {
+		return nil, fmt.Errorf("invalid DSN %q for %s, expected a %s:// URL", Redact(dsn), engine, schemes[0])
+	}
+
+	conn := &connection{
+		engine:   engine,
+		user:     u.User.Username(),
+		host:     u.Hostname(),
+		port:     u.Port(),
+		database: strings.Trim(u.Path, "/"),
+	}
+	conn.password, _ = u.User.Password()
+	if conn.database == "" && engine == EnginePostgres {
+		return nil, fmt.Errorf("the DSN %q names no database", Redact(dsn))
+	}
+	withoutPassword := *u
+	if conn.user != "" {
+		withoutPassword.User = url.User(conn.user)
+	}
+	conn.dsn = &withoutPassword
+	return conn, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// tool returns the dump tool of the engine.
+func (c *connection) tool() string {
+	if c.engine == EngineMySQL {
+		return "mysqldump"
+	}
+	return "pg_dump"
+}
+
+// label names the dumped database in file names and the manifest.
+func (c *connection) label() string {
+	if c.database == "" {
+		return "all-databases"
+	}
+	return c.database
+}
+
+// command returns the arguments and environment that run the tool against the database,
+// with extra passed before the database name. The password goes to the tool through
+// the environment or an option file rather than the command line, where other users
+// could see it; cleanup removes the option file.
+func (c *connection) command(tempDir string, extra []string) (args, env []string, cleanup func(), err error) {
+	cleanup = func() {}
+	switch c.engine {
+	case EnginePostgres:
+		// a password prompt would hang a scheduled job
+		args = append([]string{"--no-password", "--dbname", c.dsn.String()}, extra...)
+		if c.password != "" {
+			env = append(env, "PGPASSWORD="+c.password)
+		}
+		return args, env, cleanup, nil
+	}
+
+	if c.password != "" {
+		path, err := writeOptionFile(tempDir, c.password)
+		if err != nil {
+			return nil, nil, cleanup, err
+		}
+		// mysqldump only accepts --defaults-extra-file as its first argument
+		args = append(args, "--defaults-extra-file="+path)
+		cleanup = func() { utils.CleanupTempFile(path) }
+	}
+	if c.host != "" {
+		args = append(args, "--host", c.host)
+	}
+	if c.port != "" {
+		args = append(args, "--port", c.port)
+	}
+	if c.user != "" {
+		args = append(args, "--user", c.user)
+	}
+	// a consistent snapshot of InnoDB tables without locking them, with stored procedures
+	args = append(args, "--single-transaction", "--routines")
+	args = append(args, extra...)
+	if c.database == "" {
+		args = append(args, "--all-databases")
+	} else {
+		args = append(args, c.database)
+	}
+	return args, env, cleanup, nil
+}
+
+// writeOptionFile writes a MySQL option file with the password for the client, readable
+// by the current user only.
+func writeOptionFile(tempDir, password string) (string, error) {
+	dir, err := utils.TempDir(tempDir)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(dir, "s3manager-mysql-*.cnf")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the mysqldump option file: %w", err)
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(password)
+	_, err = fmt.Fprintf(file, "[client]\npassword=\"%s\"\n", escaped)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write the mysqldump option file: %w", err)
+	}
+	return file.Name(), nil
+}
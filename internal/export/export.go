@@ -0,0 +1,177 @@
+// Package export writes tabular command results to CSV and Parquet files, so listings and
+// reports can be loaded into spreadsheets and data warehouses.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+)
+
+// Formats, named after their file extension
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Kind is the type of the values of a column.
+type Kind int
+
+const (
+	String Kind = iota
+	Int64
+	Float64
+	Time
+)
+
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Table is a result as rows of values. Each value has the Go type of the kind of its
+// column: string, int64, float64 or time.Time.
+type Table struct {
+	Columns []Column
+	Rows    [][]any
+}
+
+// FormatOf returns the format of path from its extension.
+func FormatOf(path string) (string, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	switch format {
+	case FormatCSV, FormatParquet:
+		return format, nil
+	}
+	return "", fmt.Errorf("cannot export to %s, expected a .csv or .parquet file", path)
+}
+
+// WriteFile writes t to path in the format of its extension. The file is replaced only
+// when it was written completely.
+func WriteFile(path string, t Table) error {
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if format == FormatParquet {
+		err = WriteParquet(tmp, t)
+	} else {
+		err = WriteCSV(tmp, t)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to export to %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteCSV writes t as CSV with a header row. Times are formatted as RFC3339.
+func WriteCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		header[i] = column.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, value := range row {
+			record[i] = formatValue(value)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// WriteParquet writes t as a Snappy-compressed Parquet file with one row group. Times
+// are stored as UTC timestamps in milliseconds.
+func WriteParquet(w io.Writer, t Table) error {
+	group := parquet.Group{}
+	for _, column := range t.Columns {
+		group[column.Name] = parquetNode(column.Kind)
+	}
+	schema := parquet.NewSchema("s3manager", group)
+
+	// The leaf columns of a group are ordered by name, not in the order of the table
+	index := make(map[string]int, len(t.Columns))
+	for i, path := range schema.Columns() {
+		index[path[0]] = i
+	}
+
+	rows := make([]parquet.Row, 0, len(t.Rows))
+	for _, values := range t.Rows {
+		row := make(parquet.Row, len(values))
+		for i, value := range values {
+			columnIndex := index[t.Columns[i].Name]
+			row[columnIndex] = parquetValue(value).Level(0, 0, columnIndex)
+		}
+		rows = append(rows, row)
+	}
+
+	pw := parquet.NewWriter(w, schema, parquet.Compression(&snappy.Codec{}))
+	if _, err := pw.WriteRows(rows); err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+func parquetNode(kind Kind) parquet.Node {
+	switch kind {
+	case Int64:
+		return parquet.Int(64)
+	case Float64:
+		return parquet.Leaf(parquet.DoubleType)
+	case Time:
+		return parquet.Timestamp(parquet.Millisecond)
+	}
+	return parquet.String()
+}
+
+func parquetValue(value any) parquet.Value {
+	if v, ok := value.(time.Time); ok {
+		return parquet.ValueOf(v.UnixMilli())
+	}
+	return parquet.ValueOf(value)
+}
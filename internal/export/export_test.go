@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func testTable() Table {
+	return Table{
+		Columns: []Column{
+			{Name: "key", Kind: String},
+			{Name: "size_bytes", Kind: Int64},
+			{Name: "last_modified", Kind: Time},
+			{Name: "percent", Kind: Float64},
+		},
+		Rows: [][]any{
+			{"backups/db.sql.gz", int64(1048576), time.Date(2024, 3, 15, 14, 22, 33, 0, time.UTC), 62.5},
+			{"backups/web, \"new\".tar", int64(0), time.Time{}, 37.5},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testTable()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "key,size_bytes,last_modified,percent\n" +
+		"backups/db.sql.gz,1048576,2024-03-15T14:22:33Z,62.5\n" +
+		"\"backups/web, \"\"new\"\".tar\",0,,37.5\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteFileParquet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "objects.parquet")
+	if err := WriteFile(path, testTable()); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	type row struct {
+		Key          string    `parquet:"key"`
+		SizeBytes    int64     `parquet:"size_bytes"`
+		LastModified time.Time `parquet:"last_modified,timestamp(millisecond)"`
+		Percent      float64   `parquet:"percent"`
+	}
+	rows, err := parquet.ReadFile[row](path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("read %d rows, want 2", len(rows))
+	}
+	first := rows[0]
+	if first.Key != "backups/db.sql.gz" || first.SizeBytes != 1048576 || first.Percent != 62.5 ||
+		!first.LastModified.Equal(time.Date(2024, 3, 15, 14, 22, 33, 0, time.UTC)) {
+		t.Errorf("first row = %+v", first)
+	}
+	if rows[1].Key != `backups/web, "new".tar` {
+		t.Errorf("second row = %+v", rows[1])
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("the export left %d files, want only the parquet file", len(entries))
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for path, want := range map[string]string{"out.csv": FormatCSV, "/tmp/OUT.Parquet": FormatParquet, "out.json": ""} {
+		format, err := FormatOf(path)
+		if format != want || (err != nil) != (want == "") {
+			t.Errorf("FormatOf(%s) = %q, %v, want %q", path, format, err, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Package filter evaluates the --filter expressions that select objects by key, size and
+// age, e.g.
+//
+//	size>100MB and age>30d and not key~"*.json"
+//
+// A comparison is a field, an operator and a value. The fields are key, name (the last
+// element of the key), class (the storage class), size, age and modified. Sizes are
+// written like 512, 100MB or 1.5GiB, ages like 36h, 30d or 2w and dates like 2024-01-01 or
+// RFC3339. Every field supports =, !=, <, <=, > and >=; key, name and class also support ~
+// and !~, which match a glob like --exclude: against the name unless the glob contains a
+// slash. Comparisons combine with not, and, or and parentheses, in that order of
+// precedence. Values with spaces or operator characters are quoted with " or '.
+package filter
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"s3manager/pkg/utils"
+)
+
+// Object is what a filter sees of a stored object.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	StorageClass string
+}
+
+// Filter is a parsed filter expression.
+type Filter struct {
+	source string
+	root   node
+	now    time.Time
+}
+
+// Parse parses expr. Ages are measured from now, so that a long run judges every object
+// against the same point in time. An empty expression gives nil, which matches every
+// object.
+func Parse(expr string, now time.Time) (*Filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s at offset %d", p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+	}
+	return &Filter{source: expr, root: root, now: now}, nil
+}
+
+// Match reports whether obj satisfies the filter. A nil filter matches every object.
+func (f *Filter) Match(obj Object) bool {
+	return f == nil || f.root.match(obj, f.now)
+}
+
+// String returns the expression as given, "" for a nil filter.
+func (f *Filter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.source
+}
+
+type node interface {
+	match(obj Object, now time.Time) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(obj Object, now time.Time) bool {
+	return n.left.match(obj, now) && n.right.match(obj, now)
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(obj Object, now time.Time) bool {
+	return n.left.match(obj, now) || n.right.match(obj, now)
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) match(obj Object, now time.Time) bool {
+	return !n.operand.match(obj, now)
+}
+
+// comparison compares one field of an object with a value parsed for that field.
+type comparison struct {
+	op      string
+	compare func(obj Object, now time.Time) int
+	// glob is set for ~ and !~
+	glob  string
+	field func(obj Object) string
+}
+
+func (n comparison) match(obj Object, now time.Time) bool {
+	switch n.op {
+	case "~":
+		return matchGlob(n.glob, n.field(obj))
+	case "!~":
+		return !matchGlob(n.glob, n.field(obj))
+	}
+	c := n.compare(obj, now)
+	switch n.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// matchGlob matches pattern against the name of key, or against the full key when the
+// pattern contains a slash.
+func matchGlob(pattern, key string) bool {
+	name := key
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(key)
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+var stringFields = map[string]func(Object) string{
+	"key":   func(obj Object) string { return obj.Key },
+	"name":  func(obj Object) string { return path.Base(obj.Key) },
+	"class": func(obj Object) string { return obj.StorageClass },
+}
+
+// newComparison parses value for field and returns the comparison with op.
+func newComparison(field, op, value string) (node, error) {
+	if get, ok := stringFields[field]; ok {
+		if op == "~" || op == "!~" {
+			if _, err := path.Match(value, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob %q: %w", value, err)
+			}
+			return comparison{op: op, glob: value, field: get}, nil
+		}
+		return comparison{op: op, compare: func(obj Object, _ time.Time) int {
+			return strings.Compare(get(obj), value)
+		}}, nil
+	}
+	if op == "~" || op == "!~" {
+		return nil, fmt.Errorf("%s only applies to key, name and class, not %s", op, field)
+	}
+
+	switch field {
+	case "size":
+		size, err := utils.ParseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return comparison{op: op, compare: func(obj Object, _ time.Time) int {
+			return cmp.Compare(obj.Size, size)
+		}}, nil
+	case "age":
+		age, err := utils.ParseAge(value)
+		if err != nil {
+			return nil, err
+		}
+		return comparison{op: op, compare: func(obj Object, now time.Time) int {
+			return cmp.Compare(now.Sub(obj.LastModified), age)
+		}}, nil
+	case "modified":
+		t, err := utils.ParseDate(value)
+		if err != nil {
+			return nil, err
+		}
+		return comparison{op: op, compare: func(obj Object, _ time.Time) int {
+			return obj.LastModified.Compare(t)
+		}}, nil
+	}
+	return nil, fmt.Errorf("unknown field %q, expected key, name, class, size, age or modified", field)
+}
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	dump := Object{Key: "backups/db-prod.sql.gz", Size: 200 << 20, LastModified: now.AddDate(0, 0, -40), StorageClass: "STANDARD"}
+	manifest := Object{Key: "backups/manifest.json", Size: 300 << 20, LastModified: now.AddDate(0, 0, -40), StorageClass: "STANDARD"}
+	recent := Object{Key: "backups/db-staging.sql.gz", Size: 150 << 20, LastModified: now.AddDate(0, 0, -2), StorageClass: "GLACIER"}
+	small := Object{Key: "logs/app.log", Size: 10, LastModified: now.AddDate(0, 0, -90)}
+
+	tests := []struct {
+		expr string
+		want []bool // dump, manifest, recent, small
+	}{
+		{`size>100MB and age>30d and not key~"*.json"`, []bool{true, false, false, false}},
+		{`size > 100MB AND age > 30d AND key !~ '*.json'`, []bool{true, false, false, false}},
+		{`age<7d or size<1KB`, []bool{false, false, true, true}},
+		{`not (age<7d or size<1KB)`, []bool{true, true, false, false}},
+		{`size>=1KB and age>30d or class=GLACIER`, []bool{true, true, true, false}},
+		{`key~"backups/db-*"`, []bool{true, false, true, false}},
+		{`name=app.log`, []bool{false, false, false, true}},
+		{`modified<2024-03-01`, []bool{true, true, false, true}},
+		{`class!=STANDARD`, []bool{false, false, true, true}},
+		{`size=10`, []bool{false, false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := Parse(tt.expr, now)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			for i, obj := range []Object{dump, manifest, recent, small} {
+				if got := f.Match(obj); got != tt.want[i] {
+					t.Errorf("Match(%s) = %v, want %v", obj.Key, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	f, err := Parse("  ", time.Now())
+	if err != nil || f != nil {
+		t.Fatalf("Parse() = %v, %v, want nil filter", f, err)
+	}
+	if !f.Match(Object{Key: "any"}) || f.String() != "" {
+		t.Errorf("nil filter should match every object")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		`size>`,
+		`size>lots`,
+		`age>soon`,
+		`modified>yesterday`,
+		`color=red`,
+		`size~"*.json"`,
+		`key~"[bad"`,
+		`(size>1MB`,
+		`size>1MB and`,
+		`size>1MB age>1d`,
+		`key="unterminated`,
+		`and size>1MB`,
+		`size 1MB`,
+	} {
+		if _, err := Parse(expr, time.Now()); err == nil {
+			t.Errorf("Parse(%q) should return error", expr)
+		}
+	}
+}
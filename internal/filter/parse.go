@@ -0,0 +1,208 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// operators are tried longest first
+var operators = []string{"!=", "<=", ">=", "!~", "=", "<", ">", "~"}
+
+// tokenize splits expr into words, quoted strings, operators and parentheses.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		r := rune(expr[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", offset: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", offset: i})
+			i++
+		case r == '"' || r == '\'':
+			value, n, err := unquote(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w at offset %d", err, i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, offset: i})
+			i += n
+		default:
+			if op := operatorAt(expr[i:]); op != "" {
+				tokens = append(tokens, token{kind: tokenOperator, text: op, offset: i})
+				i += len(op)
+				continue
+			}
+			start := i
+			for i < len(expr) && !unicode.IsSpace(rune(expr[i])) && !strings.ContainsRune(`()"'`, rune(expr[i])) && operatorAt(expr[i:]) == "" {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: expr[start:i], offset: start})
+		}
+	}
+	return tokens, nil
+}
+
+func operatorAt(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// unquote reads the quoted string at the start of s, where a backslash escapes the next
+// character, and returns its value and length in s.
+func unquote(s string) (string, int, error) {
+	quote := s[0]
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				value.WriteByte(s[i])
+			}
+		case quote:
+			return value.String(), i + 1, nil
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated %c quote", quote)
+}
+
+// parser is a recursive descent parser of
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = field operator value
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// keyword consumes the next token when it is the word kw, in any case.
+func (p *parser) keyword(kw string) bool {
+	t, ok := p.peek()
+	if ok && t.kind == tokenWord && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.keyword("not") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end, expected a comparison")
+	}
+	if t.kind == tokenOpen {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokenClose {
+			return nil, fmt.Errorf("missing ) for ( at offset %d", t.offset)
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	field, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end, expected a comparison")
+	}
+	if field.kind != tokenWord {
+		return nil, fmt.Errorf("expected a field at offset %d, got %s", field.offset, field.text)
+	}
+	p.pos++
+
+	op, ok := p.peek()
+	if !ok || op.kind != tokenOperator {
+		return nil, fmt.Errorf("expected an operator after %s", field.text)
+	}
+	p.pos++
+
+	value, ok := p.peek()
+	if !ok || (value.kind != tokenWord && value.kind != tokenString) {
+		return nil, fmt.Errorf("expected a value after %s%s", field.text, op.text)
+	}
+	p.pos++
+
+	n, err := newComparison(strings.ToLower(field.text), op.text, value.text)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s%s: %w", field.text, op.text, value.text, err)
+	}
+	return n, nil
+}
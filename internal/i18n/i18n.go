@@ -0,0 +1,81 @@
+// Package i18n translates the human-facing messages of the commands: confirmation
+// prompts, operation summaries and the headers of CSV reports. Messages are identified by
+// their English text, which is also used when a language has no translation for them.
+// JSON output, logs and exported files are never translated, so scripts can rely on them.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	English = "en"
+	Russian = "ru"
+)
+
+// catalogs map the English text of a message to its translation
+var catalogs = map[string]map[string]string{
+	Russian: russian,
+}
+
+// yesWords are the answers that confirm a prompt, in addition to y and yes
+var yesWords = map[string][]string{
+	Russian: {"д", "да"},
+}
+
+var current atomic.Value
+
+// Languages returns the supported languages.
+func Languages() []string {
+	languages := []string{English}
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	slices.Sort(languages[1:])
+	return languages
+}
+
+// SetLanguage selects the language of the messages. Locale names like ru_RU.UTF-8 select
+// their language.
+func SetLanguage(lang string) error {
+	name := strings.ToLower(lang)
+	if i := strings.IndexAny(name, "_-."); i >= 0 {
+		name = name[:i]
+	}
+	if name != English && catalogs[name] == nil {
+		return fmt.Errorf("unsupported language %q, expected %s", lang, strings.Join(Languages(), " or "))
+	}
+	current.Store(name)
+	return nil
+}
+
+// Language returns the selected language, English unless another one was selected.
+func Language() string {
+	if lang, ok := current.Load().(string); ok {
+		return lang
+	}
+	return English
+}
+
+// T returns message in the selected language.
+func T(message string) string {
+	if translated, ok := catalogs[Language()][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Tf formats the translation of format with args.
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// IsYes reports whether answer confirms a prompt: y or yes, or their equivalent in the
+// selected language.
+func IsYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes" || slices.Contains(yesWords[Language()], answer)
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	t.Cleanup(func() { SetLanguage(English) })
+
+	if err := SetLanguage("ru_RU.UTF-8"); err != nil || Language() != Russian {
+		t.Fatalf("SetLanguage(ru_RU.UTF-8) = %v, language %s, want ru", err, Language())
+	}
+	if got := Tf("Bucket: %s", "backups"); got != "Бакет: backups" {
+		t.Errorf("Tf() = %q, want the Russian summary line", got)
+	}
+	if got := T("no translation"); got != "no translation" {
+		t.Errorf("T() = %q, want the English message without a translation", got)
+	}
+	for answer, want := range map[string]bool{"да": true, "Д": true, "yes": true, "нет": false, "": false} {
+		if got := IsYes(answer); got != want {
+			t.Errorf("IsYes(%q) in Russian = %t, want %t", answer, got, want)
+		}
+	}
+
+	if err := SetLanguage("EN"); err != nil || T("Bucket: %s") != "Bucket: %s" {
+		t.Errorf("SetLanguage(EN) = %v, want English messages", err)
+	}
+	if IsYes("да") {
+		t.Error("IsYes(да) in English = true")
+	}
+	if err := SetLanguage("de"); err == nil {
+		t.Error("SetLanguage(de) should return an error")
+	}
+	if got := Languages(); !slices.Equal(got, []string{English, Russian}) {
+		t.Errorf("Languages() = %v", got)
+	}
+}
+
+// Translations must take the arguments of the message in the same order
+func TestCatalogVerbs(t *testing.T) {
+	verbs := regexp.MustCompile(`%[a-z]`)
+	for lang, catalog := range catalogs {
+		for message, translated := range catalog {
+			if !slices.Equal(verbs.FindAllString(message, -1), verbs.FindAllString(translated, -1)) {
+				t.Errorf("%s translation of %q has other verbs: %q", lang, message, translated)
+			}
+		}
+	}
+}
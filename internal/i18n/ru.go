@@ -0,0 +1,102 @@
+package i18n
+
+var russian = map[string]string{
+	// Prompts
+	" (y/N): ":                            " (д/Н): ",
+	"yes":                                 "да",
+	"Are you sure?":                       "Продолжить?",
+	"Continue?":                           "Продолжить?",
+	"Type the bucket name to proceed":     "Введите имя бакета для подтверждения",
+	"Impact: %d objects, %s":              "Затронуто: %d объектов, %s",
+	"This is more than %d objects or %s.": "Это больше %d объектов или %s.",
+	"Continue with upload?":               "Начать загрузку?",
+	"Continue with download?":             "Начать скачивание?",
+	"Continue with deploy?":               "Начать публикацию?",
+	"Continue with migration?":            "Начать перенос?",
+	"Continue with ingest?":               "Начать приём файлов?",
+	"Continue with rewrite?":              "Начать перезапись заголовков?",
+	"Replace s3manager %s at %s with %s?": "Заменить s3manager %s в %s на %s?",
+	"Upload single file '%s' as archive?": "Загрузить файл '%s' в виде архива?",
+	"Operation cancelled.":                "Операция отменена.",
+	"Upload cancelled.":                   "Загрузка отменена.",
+	"Download cancelled.":                 "Скачивание отменено.",
+	"Deploy cancelled.":                   "Публикация отменена.",
+	"Migration cancelled.":                "Перенос отменён.",
+	"Ingest cancelled.":                   "Приём файлов отменён.",
+	"Rewrite cancelled.":                  "Перезапись заголовков отменена.",
+	"Update cancelled.":                   "Обновление отменено.",
+
+	// Deletion warnings, built from a sentence and the fragments that apply
+	"permanently delete":                                               "безвозвратно удалить",
+	"move to the trash":                                                "переместить в корзину",
+	"WARNING: This will %s files":                                      "ВНИМАНИЕ: команда собирается %s файлы",
+	"WARNING: This will %s expired files from bucket '%s'":             "ВНИМАНИЕ: команда собирается %s файлы с истёкшим сроком из бакета '%s'",
+	"WARNING: This will %s the oldest files from bucket '%s'":          "ВНИМАНИЕ: команда собирается %s самые старые файлы из бакета '%s'",
+	"WARNING: This will %s the objects planned at %s from bucket '%s'": "ВНИМАНИЕ: команда собирается %s объекты плана от %s из бакета '%s'",
+	"WARNING: This will permanently delete trashed objects older than %d days from bucket '%s'": "ВНИМАНИЕ: команда собирается безвозвратно удалить объекты корзины старше %d дней из бакета '%s'",
+	" older than %d days (%s)":          " старше %d дней (%s)",
+	" not read or modified since %s":    ", которые не читались и не менялись с %s",
+	" modified between %s and %s":       ", изменённые между %s и %s",
+	" modified since %s":                ", изменённые с %s",
+	" modified before %s":               ", изменённые до %s",
+	" from bucket '%s'":                 " из бакета '%s'",
+	" from '%s'":                        " из '%s'",
+	" in folder '%s'":                   " в папке '%s'",
+	" in folders '%s'":                  " в папках '%s'",
+	" tagged %s":                        " с тегами %s",
+	" except %s":                        ", кроме %s",
+	" whose key matches %s":             ", ключ которых соответствует %s",
+	" matching %s":                      ", подходящие под %s",
+	" dated by their key":               " по дате из ключа",
+	" until it fits within %s (now %s)": ", пока размер не станет меньше %s (сейчас %s)",
+	"This will disable static website hosting for bucket '%s'":      "Хостинг статического сайта для бакета '%s' будет отключён",
+	"WARNING: remote files missing from '%s' will be deleted":       "ВНИМАНИЕ: файлы бакета, которых нет в '%s', будут удалены",
+	"WARNING: objects with the same keys in %s will be overwritten": "ВНИМАНИЕ: объекты с теми же ключами в %s будут перезаписаны",
+
+	// Operation summaries
+	"Upload operation summary:":   "Параметры загрузки:",
+	"Download operation summary:": "Параметры скачивания:",
+	"Deploy operation summary:":   "Параметры публикации:",
+	"Migrate operation summary:":  "Параметры переноса:",
+	"Ingest operation summary:":   "Параметры приёма файлов:",
+	"Rewrite operation summary:":  "Параметры перезаписи заголовков:",
+	"Bucket: %s":                  "Бакет: %s",
+	"Source: %s":                  "Источник: %s",
+	"Destination: %s":             "Назначение: %s",
+	"Folder: %s":                  "Папка: %s",
+	"Prefix: %s":                  "Префикс: %s",
+	"Files/Folders: %v":           "Файлы и папки: %v",
+	"Archive: %t":                 "Архив: %t",
+	"Archive name: %s":            "Имя архива: %s",
+	"Exclude patterns: %v":        "Исключения: %v",
+	"Include pattern: %s":         "Только объекты: %s",
+	"Modified since: %s":          "Изменённые с: %s",
+	"Signature: %s":               "Подпись: %s",
+	"Expires: %s":                 "Срок хранения до: %s",
+	"Move uploaded files to: %s":  "Переместить загруженные файлы в: %s",
+	"Delete uploaded files: true": "Удалить загруженные файлы: да",
+	"Delete ingested files: true": "Удалить принятые файлы с сервера: да",
+	"Compression: %s":             "Сжатие: %s",
+	"Recursive: true":             "Рекурсивно: да",
+
+	// Headers of CSV reports
+	"key":                   "ключ",
+	"name":                  "имя",
+	"size_bytes":            "размер (байт)",
+	"compressed_size_bytes": "сжатый размер (байт)",
+	"last_modified":         "изменён",
+	"modified":              "изменён",
+	"age":                   "возраст",
+	"age_seconds":           "возраст (с)",
+	"storage_class":         "класс хранения",
+	"etag":                  "ETag",
+	"sha256":                "SHA-256",
+	"set":                   "группа",
+	"objects":               "объектов",
+	"objects_percent":       "объектов (%)",
+	"bytes_percent":         "байт (%)",
+	"issue":                 "проблема",
+	"owner":                 "владелец",
+	"grantee":               "получатель",
+	"permission":            "право",
+}
@@ -0,0 +1,304 @@
+package ingest
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+// resumeDelay is the wait before the first Range request, which grows with each one.
+var resumeDelay = time.Second
+
+// checksumAlgorithms are the digests a fetch can be verified against.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Checksum is the expected digest of fetched content.
+type Checksum struct {
+	Algorithm string
+	// Digest is lower-case hex
+	Digest string
+}
+
+// ParseChecksum parses a checksum like sha256:<hex>. A bare hex digest is taken for the
+// algorithm of its length.
+func ParseChecksum(s string) (Checksum, error) {
+	algorithm, digest, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		digest = algorithm
+		switch len(digest) {
+		case 32:
+			algorithm = "md5"
+		case 40:
+			algorithm = "sha1"
+		case 64:
+			algorithm = "sha256"
+		case 128:
+			algorithm = "sha512"
+		}
+	}
+	algorithm = strings.ToLower(algorithm)
+	newHash, known := checksumAlgorithms[algorithm]
+	if !known {
+		return Checksum{}, fmt.Errorf("invalid checksum %q, expected md5, sha1, sha256 or sha512 followed by : and the hex digest", s)
+	}
+	digest = strings.ToLower(digest)
+	if raw, err := hex.DecodeString(digest); err != nil || len(raw) != newHash().Size() {
+		return Checksum{}, fmt.Errorf("invalid %s digest %q", algorithm, digest)
+	}
+	return Checksum{Algorithm: algorithm, Digest: digest}, nil
+}
+
+// FetchOptions configure how Fetch downloads.
+type FetchOptions struct {
+	// Header is sent with every request, like an Authorization header
+	Header http.Header
+	// Checksums must all match the content, otherwise nothing is stored
+	Checksums []Checksum
+	// Retries is how often an interrupted download is continued with a Range request
+	Retries int
+	// BandwidthLimit bounds the bytes per second read from the server, 0 for no limit
+	BandwidthLimit int64
+	UserAgent      string
+	Client         *http.Client
+}
+
+// Fetch streams the resource at rawURL into dest under key. When the connection breaks
+// and the server supports ranges, the download continues where it stopped with a Range
+// request, as long as the resource is unchanged. The content is checked against the
+// length the server announced and against opts.Checksums before the upload completes, so
+// that a truncated or corrupt download is never stored.
+func Fetch(ctx context.Context, rawURL string, dest storage.ObjectStore, key string, opts FetchOptions) (*models.FetchResult, error) {
+	startTime := time.Now()
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	hashes := map[string]hash.Hash{"sha256": sha256.New()}
+	for _, checksum := range opts.Checksums {
+		if _, ok := hashes[checksum.Algorithm]; !ok {
+			hashes[checksum.Algorithm] = checksumAlgorithms[checksum.Algorithm]()
+		}
+	}
+
+	body := &resumingBody{ctx: ctx, url: rawURL, opts: opts, size: -1, retries: opts.Retries}
+	if err := body.open(); err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	writers := make([]io.Writer, 0, len(hashes))
+	for _, h := range hashes {
+		writers = append(writers, h)
+	}
+	reader := &verifyingReader{
+		r:       io.TeeReader(utils.ThrottledReader(ctx, body, utils.NewBandwidthLimiter(opts.BandwidthLimit)), io.MultiWriter(writers...)),
+		body:    body,
+		hashes:  hashes,
+		expects: opts.Checksums,
+	}
+	if err := dest.Put(ctx, key, reader, body.size); err != nil {
+		return nil, fmt.Errorf("failed to store %s as %s: %w", Redact(rawURL), key, err)
+	}
+
+	duration := time.Since(startTime)
+	throughput := utils.BytesPerSecond(body.offset, duration)
+	result := &models.FetchResult{
+		URL:             Redact(rawURL),
+		BucketName:      dest.Name(),
+		Key:             key,
+		SizeBytes:       body.offset,
+		SizeHuman:       utils.FormatBytes(body.offset),
+		Checksums:       make(map[string]string, len(hashes)),
+		Resumed:         body.resumed,
+		OperationTime:   utils.FormatTime(startTime),
+		Duration:        duration.String(),
+		ThroughputBytes: throughput,
+		ThroughputHuman: utils.FormatSpeed(throughput),
+	}
+	for algorithm, h := range hashes {
+		result.Checksums[algorithm] = hex.EncodeToString(h.Sum(nil))
+	}
+	for _, checksum := range opts.Checksums {
+		result.Verified = append(result.Verified, checksum.Algorithm)
+	}
+	sort.Strings(result.Verified)
+	return result, nil
+}
+
+// verifyingReader fails at the end of the content when its length or a digest is not
+// what was expected, so that the upload reading it is aborted.
+type verifyingReader struct {
+	r       io.Reader
+	body    *resumingBody
+	hashes  map[string]hash.Hash
+	expects []Checksum
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	if err != io.EOF {
+		return n, err
+	}
+	if v.body.size >= 0 && v.body.offset != v.body.size {
+		return n, fmt.Errorf("received %d of %d bytes", v.body.offset, v.body.size)
+	}
+	for _, checksum := range v.expects {
+		if got := hex.EncodeToString(v.hashes[checksum.Algorithm].Sum(nil)); got != checksum.Digest {
+			return n, fmt.Errorf("%s checksum mismatch: expected %s, got %s", checksum.Algorithm, checksum.Digest, got)
+		}
+	}
+	return n, io.EOF
+}
+
+// resumingBody reads the body of a GET request and continues it with a Range request
+// when the connection breaks.
+type resumingBody struct {
+	ctx  context.Context
+	url  string
+	opts FetchOptions
+	body io.ReadCloser
+	// offset is the number of bytes read, size the length of the resource or -1
+	offset int64
+	size   int64
+	// ranges is set when the server accepts Range requests
+	ranges bool
+	// validator is the ETag or Last-Modified of the resource, so that a resumed download
+	// does not mix two versions of it
+	validator string
+	retries   int
+	resumed   int
+}
+
+func (r *resumingBody) request(offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range r.opts.Header {
+		req.Header[name] = values
+	}
+	if r.opts.UserAgent != "" {
+		req.Header.Set("User-Agent", r.opts.UserAgent)
+	}
+	// transparent decompression would break lengths and ranges
+	req.Header.Set("Accept-Encoding", "identity")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if r.validator != "" {
+			req.Header.Set("If-Range", r.validator)
+		}
+	}
+	return r.opts.Client.Do(req)
+}
+
+// open sends the first request.
+func (r *resumingBody) open() error {
+	resp, err := r.request(0)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", Redact(r.url), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return fmt.Errorf("failed to fetch %s: %s", Redact(r.url), resp.Status)
+	}
+	r.body = resp.Body
+	r.size = resp.ContentLength
+	r.ranges = strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes")
+	// weak ETags cannot validate a range
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		r.validator = etag
+	} else {
+		r.validator = resp.Header.Get("Last-Modified")
+	}
+	return nil
+}
+
+// resume continues the download at offset.
+func (r *resumingBody) resume() error {
+	r.body.Close()
+	resp, err := r.request(r.offset)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return fmt.Errorf("%s changed on the server while it was downloaded", Redact(r.url))
+		}
+		return fmt.Errorf("failed to resume %s: %s", Redact(r.url), resp.Status)
+	}
+	if start, ok := rangeStart(resp.Header.Get("Content-Range")); !ok || start != r.offset {
+		resp.Body.Close()
+		return fmt.Errorf("failed to resume %s: unexpected range %q", Redact(r.url), resp.Header.Get("Content-Range"))
+	}
+	r.body = resp.Body
+	return nil
+}
+
+func (r *resumingBody) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == io.EOF && r.size >= 0 && r.offset < r.size {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil || err == io.EOF || n > 0 {
+			// a read error is returned again by the next Read
+			if err != nil && err != io.EOF {
+				err = nil
+			}
+			return n, err
+		}
+		if r.ctx.Err() != nil || !r.ranges || r.retries <= 0 {
+			return 0, err
+		}
+		r.retries--
+		r.resumed++
+		select {
+		case <-r.ctx.Done():
+			return 0, context.Cause(r.ctx)
+		case <-time.After(time.Duration(r.resumed) * resumeDelay):
+		}
+		if err := r.resume(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (r *resumingBody) Close() error {
+	return r.body.Close()
+}
+
+// rangeStart returns the first byte of a Content-Range like bytes 100-199/200.
+func rangeStart(contentRange string) (int64, bool) {
+	spec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	return start, err == nil
+}
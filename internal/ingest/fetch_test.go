@@ -0,0 +1,153 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"s3manager/internal/localfs"
+)
+
+// newFlakyServer serves content, but breaks the first connections off after cut bytes.
+func newFlakyServer(t *testing.T, content []byte, cut int, breaks int32) *httptest.Server {
+	t.Helper()
+	var broken atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if broken.Load() >= breaks {
+			http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+			return
+		}
+		broken.Add(1)
+		start := 0
+		if r.Header.Get("Range") != "" {
+			fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)-start))
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			w.Header().Set("Accept-Ranges", "bytes")
+		}
+		w.Write(content[start:min(start+cut, len(content))])
+		// hijack to drop the connection before the announced length
+		if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchResumes(t *testing.T) {
+	delay := resumeDelay
+	resumeDelay = time.Millisecond
+	t.Cleanup(func() { resumeDelay = delay })
+	content := bytes.Repeat([]byte("abcdefghij"), 10000)
+	server := newFlakyServer(t, content, 30000, 2)
+	dir := t.TempDir()
+	dest, _ := localfs.New("file://" + dir)
+
+	sum := sha256.Sum256(content)
+	checksum, err := ParseChecksum("sha256:" + hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := FetchOptions{
+		Header:    http.Header{"Authorization": {"Bearer token"}},
+		Checksums: []Checksum{checksum},
+		Retries:   3,
+	}
+	result, err := Fetch(context.Background(), server.URL+"/file.bin", dest, "mirror/file.bin", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Resumed != 2 || result.SizeBytes != int64(len(content)) || result.Checksums["sha256"] != checksum.Digest || len(result.Verified) != 1 {
+		t.Errorf("got %+v", result)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "mirror/file.bin"))
+	if !bytes.Equal(got, content) {
+		t.Errorf("stored %d bytes, want %d", len(got), len(content))
+	}
+}
+
+func TestFetchChecksumMismatch(t *testing.T) {
+	server := newFlakyServer(t, []byte("tampered"), 0, 0)
+	dir := t.TempDir()
+	dest, _ := localfs.New("file://" + dir)
+
+	checksum, _ := ParseChecksum(strings.Repeat("0", 64))
+	opts := FetchOptions{Header: http.Header{"Authorization": {"Bearer token"}}, Checksums: []Checksum{checksum}}
+	_, err := Fetch(context.Background(), server.URL+"/file.bin", dest, "file.bin", opts)
+	if err == nil || !strings.Contains(err.Error(), "sha256 checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "file.bin")); err == nil {
+		t.Error("a download with the wrong checksum was stored")
+	}
+
+	opts.Header = nil
+	if _, err := Fetch(context.Background(), server.URL+"/file.bin", dest, "file.bin", opts); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected the status of the server, got %v", err)
+	}
+}
+
+func TestFetchWithoutRanges(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 5000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.Write(content[:1000])
+		if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	dest, _ := localfs.New("file://" + dir)
+
+	if _, err := Fetch(context.Background(), server.URL, dest, "file.bin", FetchOptions{Retries: 3}); err == nil {
+		t.Fatal("expected an error for a truncated download")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("a truncated download was stored: %v", entries)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	tests := []struct {
+		in        string
+		algorithm string
+		ok        bool
+	}{
+		{"sha256:" + strings.Repeat("A", 64), "sha256", true},
+		{strings.Repeat("a", 32), "md5", true},
+		{"SHA512:" + strings.Repeat("0", 128), "sha512", true},
+		{"sha1:" + strings.Repeat("0", 64), "", false},
+		{"crc32:00000000", "", false},
+		{"sha256:xyz", "", false},
+	}
+	for _, tt := range tests {
+		checksum, err := ParseChecksum(tt.in)
+		if (err == nil) != tt.ok || checksum.Algorithm != tt.algorithm {
+			t.Errorf("ParseChecksum(%q) = %+v, %v", tt.in, checksum, err)
+		}
+		if tt.ok && checksum.Digest != strings.ToLower(checksum.Digest) {
+			t.Errorf("ParseChecksum(%q) did not lower the digest", tt.in)
+		}
+	}
+}
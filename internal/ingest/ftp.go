@@ -0,0 +1,397 @@
+package ingest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The FTP client logs in, lists directories with MLSD and downloads in passive mode.
+// ftps:// switches the connection to TLS with AUTH TLS, explicit FTPS.
+
+type ftpSource struct {
+	conn      net.Conn
+	text      *textproto.Conn
+	host      string
+	tlsConfig *tls.Config
+	root      string
+	stop      func() bool
+	// noMLSD is set when the server does not support MLSD
+	noMLSD bool
+}
+
+func openFTP(ctx context.Context, u *url.URL, opts SourceOptions) (Source, error) {
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "21")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s := &ftpSource{conn: conn, text: textproto.NewConn(conn), host: u.Hostname(), root: sourcePath(u)}
+	// closing the connection ends a command that is waiting for the server
+	s.stop = context.AfterFunc(ctx, func() { conn.Close() })
+	if err := s.login(u, opts); err != nil {
+		s.Close()
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *ftpSource) login(u *url.URL, opts SourceOptions) error {
+	if _, _, err := s.text.ReadResponse(2); err != nil {
+		return fmt.Errorf("ftp: %w", err)
+	}
+	if strings.EqualFold(u.Scheme, "ftps") {
+		if _, err := s.cmd(2, "AUTH TLS"); err != nil {
+			return err
+		}
+		// the data connections resume the TLS session of the control connection, which
+		// servers like vsftpd require
+		s.tlsConfig = &tls.Config{ServerName: s.host, ClientSessionCache: tls.NewLRUClientSessionCache(4)}
+		s.conn = tls.Client(s.conn, s.tlsConfig)
+		s.text = textproto.NewConn(s.conn)
+	}
+
+	user := u.User.Username()
+	password, ok := u.User.Password()
+	if !ok {
+		password = opts.Password
+	}
+	if user == "" {
+		user, password = "anonymous", "anonymous@"
+	}
+	code, err := s.cmd(0, "USER %s", user)
+	if err != nil {
+		return err
+	}
+	switch {
+	case code == 331 || code == 332:
+		if _, err := s.cmd(2, "PASS %s", password); err != nil {
+			return err
+		}
+	case code/100 != 2:
+		return fmt.Errorf("ftp: login as %s refused with %d", user, code)
+	}
+
+	if s.tlsConfig != nil {
+		if _, err := s.cmd(2, "PBSZ 0"); err != nil {
+			return err
+		}
+		if _, err := s.cmd(2, "PROT P"); err != nil {
+			return err
+		}
+	}
+	_, err = s.cmd(2, "TYPE I")
+	return err
+}
+
+// cmd sends a command and reads its response, which must start with the digit expect
+// unless it is 0. It returns the code of the response.
+func (s *ftpSource) cmd(expect int, format string, args ...any) (int, error) {
+	id, err := s.text.Cmd(format, args...)
+	if err != nil {
+		return 0, err
+	}
+	s.text.StartResponse(id)
+	defer s.text.EndResponse(id)
+	code, message, err := s.text.ReadResponse(expect)
+	if err != nil {
+		if _, ok := err.(*textproto.Error); ok {
+			return code, &ftpError{command: strings.Fields(format)[0], code: code, message: message}
+		}
+		return code, err
+	}
+	return code, nil
+}
+
+// ftpError is a response of the server that failed a command.
+type ftpError struct {
+	command string
+	code    int
+	message string
+}
+
+func (e *ftpError) Error() string {
+	return fmt.Sprintf("ftp: %s: %d %s", e.command, e.code, e.message)
+}
+
+// errorCode returns the code of the response that failed with err, 0 for other errors.
+func errorCode(err error) int {
+	var ftpErr *ftpError
+	if errors.As(err, &ftpErr) {
+		return ftpErr.code
+	}
+	return 0
+}
+
+// data opens a passive data connection and sends command, which transfers over it.
+func (s *ftpSource) data(format string, args ...any) (net.Conn, error) {
+	address, err := s.passive()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("tcp", address, 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("ftp: failed to open data connection: %w", err)
+	}
+	if _, err := s.cmd(1, format, args...); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if s.tlsConfig != nil {
+		conn = tls.Client(conn, s.tlsConfig)
+	}
+	return conn, nil
+}
+
+// passive returns the address of the data connection, from EPSV or else PASV. The host
+// of the control connection is used with PASV too, rather than the address the server
+// may give from behind NAT.
+func (s *ftpSource) passive() (string, error) {
+	id, err := s.text.Cmd("EPSV")
+	if err != nil {
+		return "", err
+	}
+	s.text.StartResponse(id)
+	code, message, err := s.text.ReadResponse(2)
+	s.text.EndResponse(id)
+	if err == nil && code == 229 {
+		start, end := strings.Index(message, "(|||"), strings.LastIndex(message, "|)")
+		if start >= 0 && end > start+4 {
+			return net.JoinHostPort(s.host, message[start+4:end]), nil
+		}
+	}
+
+	id, err = s.text.Cmd("PASV")
+	if err != nil {
+		return "", err
+	}
+	s.text.StartResponse(id)
+	code, message, err = s.text.ReadResponse(2)
+	s.text.EndResponse(id)
+	if err != nil {
+		return "", fmt.Errorf("ftp: PASV: %d %s", code, message)
+	}
+	start, end := strings.Index(message, "("), strings.LastIndex(message, ")")
+	if start < 0 || end < start {
+		return "", fmt.Errorf("ftp: invalid PASV response %q", message)
+	}
+	fields := strings.Split(message[start+1:end], ",")
+	if len(fields) != 6 {
+		return "", fmt.Errorf("ftp: invalid PASV response %q", message)
+	}
+	high, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+	low, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+	if err1 != nil || err2 != nil {
+		return "", fmt.Errorf("ftp: invalid PASV response %q", message)
+	}
+	return net.JoinHostPort(s.host, strconv.Itoa(high<<8|low)), nil
+}
+
+// transferDone reads the response that ends a transfer.
+func (s *ftpSource) transferDone() error {
+	code, message, err := s.text.ReadResponse(2)
+	if err != nil {
+		if _, ok := err.(*textproto.Error); ok {
+			return fmt.Errorf("ftp: transfer failed: %d %s", code, message)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *ftpSource) List(ctx context.Context) ([]File, error) {
+	var files []File
+	if err := s.walk(ctx, s.root, &files); err != nil {
+		// a path that is a file cannot be listed
+		if size, ok := s.size(s.root); ok {
+			return []File{{Path: s.root, Name: path.Base(s.root), Size: size}}, nil
+		}
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// walk adds the regular files below dir to files. Servers without MLSD, like vsftpd, are
+// listed with NLST, and names whose SIZE is unknown are taken for directories.
+func (s *ftpSource) walk(ctx context.Context, dir string, files *[]File) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	entries, err := s.mlsd(dir)
+	if err != nil && !s.noMLSD {
+		return err
+	}
+	if s.noMLSD {
+		if entries, err = s.nlst(dir); err != nil {
+			return err
+		}
+	}
+	for _, entry := range entries {
+		p := joinPath(dir, entry.name)
+		switch entry.typ {
+		case "dir":
+			if err := s.walk(ctx, p, files); err != nil {
+				return err
+			}
+		case "file":
+			*files = append(*files, File{Path: p, Name: relativeName(s.root, p), Size: entry.size, LastModified: entry.modified})
+		}
+	}
+	return nil
+}
+
+type ftpEntry struct {
+	name     string
+	typ      string
+	size     int64
+	modified time.Time
+}
+
+// mlsd lists dir with MLSD, whose facts tell files from directories. It sets noMLSD when
+// the server does not know the command.
+func (s *ftpSource) mlsd(dir string) ([]ftpEntry, error) {
+	if s.noMLSD {
+		return nil, nil
+	}
+	listing, err := s.listing("MLSD", dir)
+	if code := errorCode(err); code == 500 || code == 502 || code == 504 {
+		s.noMLSD = true
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var entries []ftpEntry
+	for _, line := range listing {
+		facts, name, ok := strings.Cut(line, " ")
+		if !ok || name == "" {
+			continue
+		}
+		entry := ftpEntry{name: name}
+		for _, fact := range strings.Split(facts, ";") {
+			key, value, _ := strings.Cut(fact, "=")
+			switch strings.ToLower(key) {
+			case "type":
+				entry.typ = strings.ToLower(value)
+			case "size":
+				entry.size, _ = strconv.ParseInt(value, 10, 64)
+			case "modify":
+				if len(value) >= 14 {
+					entry.modified, _ = time.Parse("20060102150405", value[:14])
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// nlst lists dir with NLST, which only gives names; SIZE tells files from directories.
+func (s *ftpSource) nlst(dir string) ([]ftpEntry, error) {
+	listing, err := s.listing("NLST", dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	var entries []ftpEntry
+	for _, line := range listing {
+		name := path.Base(line)
+		if name == "." || name == ".." {
+			continue
+		}
+		entry := ftpEntry{name: name, typ: "dir"}
+		if size, ok := s.size(joinPath(dir, name)); ok {
+			entry.typ, entry.size = "file", size
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// listing returns the lines a listing command sends over a data connection.
+func (s *ftpSource) listing(command, dir string) ([]string, error) {
+	conn, err := s.data("%s %s", command, dir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(conn)
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transferDone(); err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// size returns the size of the file at p, false when it is not a file.
+func (s *ftpSource) size(p string) (int64, bool) {
+	id, err := s.text.Cmd("SIZE %s", p)
+	if err != nil {
+		return 0, false
+	}
+	s.text.StartResponse(id)
+	defer s.text.EndResponse(id)
+	code, message, err := s.text.ReadResponse(213)
+	if err != nil || code != 213 {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(message), 10, 64)
+	return size, err == nil
+}
+
+func (s *ftpSource) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	conn, err := s.data("RETR %s", path)
+	if err != nil {
+		return nil, err
+	}
+	return &ftpFile{Conn: conn, source: s}, nil
+}
+
+// ftpFile is the data connection of a download. Closing it reads the response that says
+// whether the whole file was sent.
+type ftpFile struct {
+	net.Conn
+	source *ftpSource
+}
+
+func (f *ftpFile) Close() error {
+	f.Conn.Close()
+	return f.source.transferDone()
+}
+
+func (s *ftpSource) Remove(ctx context.Context, path string) error {
+	_, err := s.cmd(2, "DELE %s", path)
+	return err
+}
+
+func (s *ftpSource) Close() error {
+	s.stop()
+	s.cmd(0, "QUIT")
+	return s.text.Close()
+}
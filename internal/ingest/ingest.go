@@ -0,0 +1,279 @@
+// Package ingest pulls files from SFTP and FTP servers into a bucket, like the files
+// partners deliver to a drop directory, and fetches single resources over HTTP. Files are
+// streamed from the server into the bucket without being staged on local disk.
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+// File is a regular file on the source server.
+type File struct {
+	// Path is the path of the file on the server
+	Path string
+	// Name is the path of the file relative to the directory being ingested, or its base
+	// name when a single file is ingested
+	Name         string
+	Size         int64
+	LastModified time.Time
+}
+
+// Source is a directory or file on a server that files are ingested from.
+type Source interface {
+	// List returns the regular files of the source, in its subdirectories too, sorted by
+	// name.
+	List(ctx context.Context) ([]File, error)
+	// Open opens the content of the file at path. Only one file may be open at a time.
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+	// Remove deletes the file at path from the server.
+	Remove(ctx context.Context, path string) error
+	Close() error
+}
+
+// SourceOptions configure the connection to the server.
+type SourceOptions struct {
+	// Password logs in to an FTP server; the password of the URL takes precedence
+	Password string
+	// Identity is the private key ssh logs in to an SFTP server with, optional
+	Identity string
+	// SSHOptions are passed to ssh as -o options, like StrictHostKeyChecking=accept-new
+	SSHOptions []string
+	// SSHCommand is the ssh program, ssh from the PATH when it is empty
+	SSHCommand string
+}
+
+// Open connects to the server of rawURL, an sftp://, ftp:// or ftps:// URL like
+// sftp://partner@example.com/outgoing/. A path starting with /~/ is relative to the home
+// directory of the user.
+func Open(ctx context.Context, rawURL string, opts SourceOptions) (Source, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source URL: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid source URL %s: no host", Redact(rawURL))
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "sftp":
+		return openSFTP(ctx, u, opts)
+	case "ftp", "ftps":
+		return openFTP(ctx, u, opts)
+	}
+	return nil, fmt.Errorf("unsupported source %s, expected an sftp://, ftp:// or ftps:// URL", Redact(rawURL))
+}
+
+// Redact returns rawURL without the password it may hold, for results and logs.
+func Redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// sourcePath returns the path of the URL on the server: the path relative to the home
+// directory for /~/ paths, the home directory itself for an empty path.
+func sourcePath(u *url.URL) string {
+	p := u.Path
+	switch {
+	case p == "" || p == "/~" || p == "/~/":
+		return "."
+	case strings.HasPrefix(p, "/~/"):
+		return strings.TrimPrefix(p, "/~/")
+	}
+	return p
+}
+
+// relativeName returns the name of file below the directory root.
+func relativeName(root, file string) string {
+	root = strings.TrimSuffix(root, "/")
+	if root == "." {
+		return strings.TrimPrefix(file, "./")
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(file, root), "/")
+}
+
+// Options select the files Ingest pulls and where they go.
+type Options struct {
+	// Destination is the folder of the bucket the files are stored under, by the path
+	// relative to the source
+	Destination string
+	// Include keeps only the files matching one of its globs, Exclude drops those
+	// matching one of its globs
+	Include []string
+	Exclude []string
+	// SkipExisting skips files the bucket already has with the same size
+	SkipExisting bool
+	// RemoveSource deletes each file from the server once it is stored, and each file
+	// SkipExisting skips as already stored
+	RemoveSource bool
+	// BandwidthLimit bounds the bytes per second read from the server, 0 for no limit
+	BandwidthLimit int64
+	DryRun         bool
+	// OnIngested is called after each stored file with the number stored so far
+	OnIngested func(file File, key string, ingested int)
+}
+
+// Ingest stores the files of source in dest under opts.Destination, one at a time. A
+// file whose size changes while it is read, like one the partner is still writing, fails
+// the run rather than being stored truncated.
+func Ingest(ctx context.Context, source Source, sourceURL string, dest storage.ObjectStore, opts Options) (*models.IngestResult, error) {
+	startTime := time.Now()
+
+	for _, patterns := range [][]string{opts.Include, opts.Exclude} {
+		if err := storage.ValidatePatterns(patterns); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &models.IngestResult{
+		Source:        Redact(sourceURL),
+		BucketName:    dest.Name(),
+		Destination:   opts.Destination,
+		Include:       opts.Include,
+		Exclude:       opts.Exclude,
+		Files:         []models.IngestedFile{},
+		OperationTime: utils.FormatTime(startTime),
+		DryRun:        opts.DryRun,
+	}
+
+	files, err := source.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", result.Source, err)
+	}
+	var selected []File
+	for _, file := range files {
+		if len(opts.Include) > 0 && !storage.MatchesAny(file.Name, opts.Include) {
+			continue
+		}
+		if storage.MatchesAny(file.Name, opts.Exclude) {
+			continue
+		}
+		selected = append(selected, file)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+	result.PlannedCount = len(selected)
+
+	var existing map[string]int64
+	if opts.SkipExisting {
+		objects, err := dest.List(ctx, opts.Destination)
+		if err != nil {
+			return nil, err
+		}
+		existing = make(map[string]int64, len(objects))
+		for _, obj := range objects {
+			existing[obj.Key] = obj.Size
+		}
+	}
+
+	limiter := utils.NewBandwidthLimiter(opts.BandwidthLimit)
+	for _, file := range selected {
+		key := storage.RemotePath(opts.Destination, file.Name)
+		if size, ok := existing[key]; ok && size == file.Size {
+			result.SkippedCount++
+			if opts.RemoveSource && !opts.DryRun {
+				if err := source.Remove(ctx, file.Path); err != nil {
+					return interruptedIngest(ctx, result, startTime, fmt.Errorf("%s is already stored but could not be removed from the server: %w", file.Path, err))
+				}
+				result.RemovedCount++
+			}
+			continue
+		}
+		ingested := models.IngestedFile{Path: file.Path, Key: key, SizeBytes: file.Size}
+		if !opts.DryRun {
+			if err := ingestFile(ctx, source, dest, file, key, limiter); err != nil {
+				return interruptedIngest(ctx, result, startTime, err)
+			}
+			if opts.RemoveSource {
+				if err := source.Remove(ctx, file.Path); err != nil {
+					return interruptedIngest(ctx, result, startTime, fmt.Errorf("stored %s but failed to remove it from the server: %w", file.Path, err))
+				}
+				ingested.Removed = true
+				result.RemovedCount++
+			}
+		}
+		result.Files = append(result.Files, ingested)
+		result.IngestedCount++
+		result.TotalSizeBytes += file.Size
+		if opts.OnIngested != nil && !opts.DryRun {
+			opts.OnIngested(file, key, result.IngestedCount)
+		}
+	}
+
+	finishIngest(result, startTime)
+	return result, nil
+}
+
+func ingestFile(ctx context.Context, source Source, dest storage.ObjectStore, file File, key string, limiter *utils.RateLimiter) error {
+	body, err := source.Open(ctx, file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file.Path, err)
+	}
+	reader := &sizeCheckReader{r: utils.ThrottledReader(ctx, body, limiter), path: file.Path, size: file.Size}
+	if err := dest.Put(ctx, key, reader, file.Size); err != nil {
+		body.Close()
+		return fmt.Errorf("failed to store %s as %s: %w", file.Path, key, err)
+	}
+	if err := body.Close(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// sizeCheckReader fails when the file it reads does not have the size it was listed
+// with, so that a file that is still being written is not stored in part.
+type sizeCheckReader struct {
+	r    io.Reader
+	path string
+	size int64
+	read int64
+}
+
+func (s *sizeCheckReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.read += int64(n)
+	if s.read > s.size || err == io.EOF && s.read < s.size {
+		return n, fmt.Errorf("size of %s changed from %d to %d bytes while it was read", s.path, s.size, s.read)
+	}
+	return n, err
+}
+
+func finishIngest(result *models.IngestResult, startTime time.Time) {
+	duration := time.Since(startTime)
+	throughput := utils.BytesPerSecond(result.TotalSizeBytes, duration)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.IngestDuration = duration.String()
+	result.ThroughputBytes = throughput
+	result.ThroughputHuman = utils.FormatSpeed(throughput)
+}
+
+// interruptedIngest returns what was stored so far when ctx was cancelled, and only the
+// error otherwise.
+func interruptedIngest(ctx context.Context, result *models.IngestResult, startTime time.Time, err error) (*models.IngestResult, error) {
+	if ctx.Err() == nil {
+		return nil, err
+	}
+	finishIngest(result, startTime)
+	result.Interrupted = true
+	result.Error = err.Error()
+	return result, err
+}
+
+// joinPath joins a directory of the server and a name in it.
+func joinPath(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+	return path.Join(dir, name)
+}
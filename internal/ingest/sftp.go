@@ -0,0 +1,671 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The SFTP client speaks version 3 of the protocol, which every server supports, over
+// the sftp subsystem of the ssh program. ssh logs in, so host keys, keys, the agent and
+// ~/.ssh/config work as they do for the sftp command.
+//
+// Ingesting only lists, reads and removes files, which takes eight of the requests of the
+// protocol, so the client implements those rather than depending on github.com/pkg/sftp
+// and its dependencies; the rest of the file is packet encoding and the pipelined reads.
+// Should ingest need to write to servers or use more of the protocol, sftp.NewClientPipe
+// of that package can replace sftpConn over the same ssh pipes.
+
+const (
+	fxpInit      = 1
+	fxpVersion   = 2
+	fxpOpen      = 3
+	fxpClose     = 4
+	fxpRead      = 5
+	fxpStat      = 17
+	fxpOpendir   = 11
+	fxpReaddir   = 12
+	fxpRemove    = 13
+	fxpStatus    = 101
+	fxpHandle    = 102
+	fxpData      = 103
+	fxpName      = 104
+	fxpAttrs     = 105
+	fxfRead      = 1
+	fxOK         = 0
+	fxEOF        = 1
+	fxNoSuchFile = 2
+
+	attrSize        = 0x1
+	attrUIDGID      = 0x2
+	attrPermissions = 0x4
+	attrACModTime   = 0x8
+	attrExtended    = 0x80000000
+
+	modeType    = 0170000
+	modeDir     = 0040000
+	modeRegular = 0100000
+	modeSymlink = 0120000
+
+	// sftpChunk is the size of a read request, which every server accepts
+	sftpChunk = 32 * 1024
+	// sftpInflight is the number of read requests a download keeps in flight to hide the
+	// latency of the connection
+	sftpInflight = 16
+	// maxSFTPPacket bounds the packets accepted from the server
+	maxSFTPPacket = 256 * 1024
+)
+
+type sftpSource struct {
+	conn *sftpConn
+	root string
+}
+
+func openSFTP(ctx context.Context, u *url.URL, opts SourceOptions) (Source, error) {
+	if _, ok := u.User.Password(); ok || opts.Password != "" {
+		return nil, fmt.Errorf("sftp logs in with SSH keys or the SSH agent, not with a password")
+	}
+	command := opts.SSHCommand
+	if command == "" {
+		command = "ssh"
+	}
+	args, err := sshArgs(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ssh := exec.CommandContext(ctx, command, args...)
+	stdin, err := ssh.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := ssh.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &lastLine{}
+	ssh.Stderr = stderr
+	if err := ssh.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command, err)
+	}
+	conn := newSFTPConn(stdout, stdin, func() error {
+		stdin.Close()
+		return ssh.Wait()
+	})
+	if err := conn.init(ctx); err != nil {
+		conn.Close()
+		if message := stderr.String(); message != "" {
+			return nil, fmt.Errorf("ssh: %s", strings.TrimPrefix(message, "ssh: "))
+		}
+		return nil, err
+	}
+	return &sftpSource{conn: conn, root: sourcePath(u)}, nil
+}
+
+// sshArgs returns the arguments of ssh to start the sftp subsystem on the server of u.
+// The host and the user come from the URL, which may not be trusted: ssh would take a
+// host starting with a dash, like -oProxyCommand=..., for an option that runs a command,
+// so such hosts and users are rejected and the options are ended before the host.
+func sshArgs(u *url.URL, opts SourceOptions) ([]string, error) {
+	host, user := u.Hostname(), u.User.Username()
+	if strings.HasPrefix(host, "-") {
+		return nil, fmt.Errorf("invalid sftp host %q", host)
+	}
+	if strings.HasPrefix(user, "-") {
+		return nil, fmt.Errorf("invalid sftp user %q", user)
+	}
+
+	args := []string{"-o", "BatchMode=yes"}
+	if port := u.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	if user != "" {
+		args = append(args, "-l", user)
+	}
+	if opts.Identity != "" {
+		args = append(args, "-i", opts.Identity)
+	}
+	for _, option := range opts.SSHOptions {
+		args = append(args, "-o", option)
+	}
+	return append(args, "-s", "--", host, "sftp"), nil
+}
+
+func (s *sftpSource) List(ctx context.Context) ([]File, error) {
+	attrs, err := s.conn.stat(ctx, s.root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.root, err)
+	}
+	if attrs.mode&modeType != modeDir {
+		name := s.root[strings.LastIndex(s.root, "/")+1:]
+		return []File{{Path: s.root, Name: name, Size: attrs.size, LastModified: attrs.mtime}}, nil
+	}
+	var files []File
+	if err := s.walk(ctx, s.root, &files); err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// walk adds the regular files below dir to files. Symbolic links are followed to the
+// files they point to, but not to directories, which could loop.
+func (s *sftpSource) walk(ctx context.Context, dir string, files *[]File) error {
+	entries, err := s.conn.readDir(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		p := joinPath(dir, entry.name)
+		attrs := entry.attrs
+		if attrs.mode&modeType == modeSymlink {
+			if attrs, err = s.conn.stat(ctx, p); err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			if attrs.mode&modeType == modeDir {
+				continue
+			}
+		}
+		switch attrs.mode & modeType {
+		case modeDir:
+			if err := s.walk(ctx, p, files); err != nil {
+				return err
+			}
+		case modeRegular:
+			*files = append(*files, File{Path: p, Name: relativeName(s.root, p), Size: attrs.size, LastModified: attrs.mtime})
+		}
+	}
+	return nil
+}
+
+func (s *sftpSource) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	handle, err := s.conn.handle(ctx, fxpOpen, func(b *sftpBuffer) {
+		b.string(path)
+		b.uint32(fxfRead)
+		b.uint32(0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sftpFile{ctx: ctx, conn: s.conn, handle: handle}, nil
+}
+
+func (s *sftpSource) Remove(ctx context.Context, path string) error {
+	id, err := s.conn.send(fxpRemove, func(b *sftpBuffer) { b.string(path) })
+	if err != nil {
+		return err
+	}
+	return s.conn.status(ctx, id)
+}
+
+func (s *sftpSource) Close() error {
+	return s.conn.Close()
+}
+
+// sftpFile reads a file with up to sftpInflight read requests in flight.
+type sftpFile struct {
+	ctx     context.Context
+	conn    *sftpConn
+	handle  string
+	offset  uint64
+	pending []sftpRead
+	buf     []byte
+	eof     bool
+}
+
+type sftpRead struct {
+	id     uint32
+	offset uint64
+	length uint32
+}
+
+func (f *sftpFile) request(offset uint64, length uint32) (sftpRead, error) {
+	id, err := f.conn.send(fxpRead, func(b *sftpBuffer) {
+		b.string(f.handle)
+		b.uint64(offset)
+		b.uint32(length)
+	})
+	return sftpRead{id: id, offset: offset, length: length}, err
+}
+
+func (f *sftpFile) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if f.eof {
+			return 0, io.EOF
+		}
+		for len(f.pending) < sftpInflight {
+			read, err := f.request(f.offset, sftpChunk)
+			if err != nil {
+				return 0, err
+			}
+			f.pending = append(f.pending, read)
+			f.offset += sftpChunk
+		}
+		read := f.pending[0]
+		f.pending = f.pending[1:]
+		typ, data, err := f.conn.response(f.ctx, read.id)
+		if err != nil {
+			return 0, err
+		}
+		switch typ {
+		case fxpData:
+			d := sftpDecoder{data: data}
+			f.buf = []byte(d.string())
+			if d.err != nil {
+				return 0, d.err
+			}
+			// a short read that is not at the end of the file leaves a gap, which is
+			// requested before the reads already in flight
+			if n := uint32(len(f.buf)); n > 0 && n < read.length {
+				rest, err := f.request(read.offset+uint64(n), read.length-n)
+				if err != nil {
+					return 0, err
+				}
+				f.pending = append([]sftpRead{rest}, f.pending...)
+			}
+		case fxpStatus:
+			if err := statusError(data); err != io.EOF {
+				if err == nil {
+					err = fmt.Errorf("sftp: empty read")
+				}
+				return 0, err
+			}
+			f.eof = true
+		default:
+			return 0, fmt.Errorf("sftp: unexpected response %d to a read", typ)
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+// Close waits for the reads in flight, which all answer EOF at the end of the file, and
+// closes the handle.
+func (f *sftpFile) Close() error {
+	for _, read := range f.pending {
+		if _, _, err := f.conn.response(f.ctx, read.id); err != nil {
+			return err
+		}
+	}
+	f.pending = nil
+	id, err := f.conn.send(fxpClose, func(b *sftpBuffer) { b.string(f.handle) })
+	if err != nil {
+		return err
+	}
+	return f.conn.status(f.ctx, id)
+}
+
+// sftpConn exchanges packets with an SFTP server. Responses are matched to requests by
+// their id, those that arrive before they are waited for are kept.
+type sftpConn struct {
+	r       *bufio.Reader
+	w       io.Writer
+	closeFn func() error
+	nextID  uint32
+	early   map[uint32]sftpPacket
+	mu      sync.Mutex
+	closed  bool
+}
+
+type sftpPacket struct {
+	typ  byte
+	data []byte
+}
+
+func newSFTPConn(r io.Reader, w io.Writer, closeFn func() error) *sftpConn {
+	return &sftpConn{r: bufio.NewReaderSize(r, 64*1024), w: w, closeFn: closeFn, early: make(map[uint32]sftpPacket)}
+}
+
+func (c *sftpConn) init(ctx context.Context) error {
+	if err := c.write(fxpInit, func(b *sftpBuffer) { b.uint32(3) }); err != nil {
+		return err
+	}
+	typ, _, err := c.readPacket(ctx)
+	if err != nil {
+		return fmt.Errorf("sftp: no response from the server: %w", err)
+	}
+	if typ != fxpVersion {
+		return fmt.Errorf("sftp: unexpected response %d to init", typ)
+	}
+	return nil
+}
+
+func (c *sftpConn) write(typ byte, build func(*sftpBuffer)) error {
+	b := &sftpBuffer{data: []byte{0, 0, 0, 0, typ}}
+	build(b)
+	binary.BigEndian.PutUint32(b.data, uint32(len(b.data)-4))
+	_, err := c.w.Write(b.data)
+	return err
+}
+
+// send sends a request and returns its id.
+func (c *sftpConn) send(typ byte, build func(*sftpBuffer)) (uint32, error) {
+	c.nextID++
+	id := c.nextID
+	err := c.write(typ, func(b *sftpBuffer) {
+		b.uint32(id)
+		build(b)
+	})
+	return id, err
+}
+
+// readPacket reads the next packet. The ssh process is killed when the context of the
+// source is done, which ends the read.
+func (c *sftpConn) readPacket(ctx context.Context) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, context.Cause(ctx)
+		}
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > maxSFTPPacket {
+		return 0, nil, fmt.Errorf("sftp: invalid packet length %d", length)
+	}
+	data := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, context.Cause(ctx)
+		}
+		return 0, nil, err
+	}
+	return header[4], data, nil
+}
+
+// response returns the type and the payload after the id of the response to request
+// id.
+func (c *sftpConn) response(ctx context.Context, id uint32) (byte, []byte, error) {
+	if packet, ok := c.early[id]; ok {
+		delete(c.early, id)
+		return packet.typ, packet.data, nil
+	}
+	for {
+		typ, data, err := c.readPacket(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(data) < 4 {
+			return 0, nil, fmt.Errorf("sftp: short packet")
+		}
+		got := binary.BigEndian.Uint32(data)
+		if got == id {
+			return typ, data[4:], nil
+		}
+		c.early[got] = sftpPacket{typ: typ, data: data[4:]}
+	}
+}
+
+// status waits for the status response to request id.
+func (c *sftpConn) status(ctx context.Context, id uint32) error {
+	typ, data, err := c.response(ctx, id)
+	if err != nil {
+		return err
+	}
+	if typ != fxpStatus {
+		return fmt.Errorf("sftp: unexpected response %d", typ)
+	}
+	return statusError(data)
+}
+
+// handle sends a request that opens a file or directory and returns its handle.
+func (c *sftpConn) handle(ctx context.Context, typ byte, build func(*sftpBuffer)) (string, error) {
+	id, err := c.send(typ, build)
+	if err != nil {
+		return "", err
+	}
+	got, data, err := c.response(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	switch got {
+	case fxpHandle:
+		d := sftpDecoder{data: data}
+		handle := d.string()
+		return handle, d.err
+	case fxpStatus:
+		if err := statusError(data); err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("sftp: unexpected response %d", got)
+}
+
+func (c *sftpConn) stat(ctx context.Context, path string) (sftpAttrs, error) {
+	id, err := c.send(fxpStat, func(b *sftpBuffer) { b.string(path) })
+	if err != nil {
+		return sftpAttrs{}, err
+	}
+	typ, data, err := c.response(ctx, id)
+	if err != nil {
+		return sftpAttrs{}, err
+	}
+	switch typ {
+	case fxpAttrs:
+		d := sftpDecoder{data: data}
+		attrs := d.attrs()
+		return attrs, d.err
+	case fxpStatus:
+		if err := statusError(data); err != nil {
+			return sftpAttrs{}, err
+		}
+	}
+	return sftpAttrs{}, fmt.Errorf("sftp: unexpected response %d", typ)
+}
+
+type sftpEntry struct {
+	name  string
+	attrs sftpAttrs
+}
+
+func (c *sftpConn) readDir(ctx context.Context, dir string) ([]sftpEntry, error) {
+	handle, err := c.handle(ctx, fxpOpendir, func(b *sftpBuffer) { b.string(dir) })
+	if err != nil {
+		return nil, err
+	}
+	var entries []sftpEntry
+	for {
+		id, err := c.send(fxpReaddir, func(b *sftpBuffer) { b.string(handle) })
+		if err != nil {
+			return nil, err
+		}
+		typ, data, err := c.response(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if typ == fxpStatus {
+			if err := statusError(data); err != io.EOF {
+				if err == nil {
+					err = fmt.Errorf("sftp: empty directory listing")
+				}
+				return nil, err
+			}
+			break
+		}
+		if typ != fxpName {
+			return nil, fmt.Errorf("sftp: unexpected response %d to readdir", typ)
+		}
+		d := sftpDecoder{data: data}
+		for count := d.uint32(); count > 0 && d.err == nil; count-- {
+			name := d.string()
+			longName := d.string()
+			attrs := d.attrs()
+			// servers without permissions in the attributes still list ls -l lines
+			if attrs.flags&attrPermissions == 0 && strings.HasPrefix(longName, "d") {
+				attrs.mode = modeDir
+			} else if attrs.flags&attrPermissions == 0 {
+				attrs.mode = modeRegular
+			}
+			if name != "." && name != ".." {
+				entries = append(entries, sftpEntry{name: name, attrs: attrs})
+			}
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+	id, err := c.send(fxpClose, func(b *sftpBuffer) { b.string(handle) })
+	if err != nil {
+		return nil, err
+	}
+	return entries, c.status(ctx, id)
+}
+
+func (c *sftpConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if c.closeFn == nil {
+		return nil
+	}
+	return c.closeFn()
+}
+
+// statusError returns the error of a status response, io.EOF for the end of a file or
+// directory and nil for success.
+func statusError(data []byte) error {
+	d := sftpDecoder{data: data}
+	code := d.uint32()
+	message := d.string()
+	if d.err != nil {
+		return d.err
+	}
+	switch code {
+	case fxOK:
+		return nil
+	case fxEOF:
+		return io.EOF
+	case fxNoSuchFile:
+		return errNotFound
+	}
+	if message == "" {
+		message = fmt.Sprintf("error %d", code)
+	}
+	return fmt.Errorf("sftp: %s", message)
+}
+
+var errNotFound = errors.New("no such file or directory")
+
+type sftpAttrs struct {
+	flags uint32
+	size  int64
+	mode  uint32
+	mtime time.Time
+}
+
+type sftpBuffer struct {
+	data []byte
+}
+
+func (b *sftpBuffer) uint32(v uint32) {
+	b.data = binary.BigEndian.AppendUint32(b.data, v)
+}
+
+func (b *sftpBuffer) uint64(v uint64) {
+	b.data = binary.BigEndian.AppendUint64(b.data, v)
+}
+
+func (b *sftpBuffer) string(s string) {
+	b.uint32(uint32(len(s)))
+	b.data = append(b.data, s...)
+}
+
+// sftpDecoder reads the fields of a packet; the first error is kept in err.
+type sftpDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *sftpDecoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.data) {
+		d.err = fmt.Errorf("sftp: short packet")
+		return nil
+	}
+	v := d.data[:n]
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *sftpDecoder) uint32() uint32 {
+	if v := d.take(4); v != nil {
+		return binary.BigEndian.Uint32(v)
+	}
+	return 0
+}
+
+func (d *sftpDecoder) uint64() uint64 {
+	if v := d.take(8); v != nil {
+		return binary.BigEndian.Uint64(v)
+	}
+	return 0
+}
+
+func (d *sftpDecoder) string() string {
+	return string(d.take(int(d.uint32())))
+}
+
+func (d *sftpDecoder) attrs() sftpAttrs {
+	var a sftpAttrs
+	a.flags = d.uint32()
+	if a.flags&attrSize != 0 {
+		a.size = int64(d.uint64())
+	}
+	if a.flags&attrUIDGID != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if a.flags&attrPermissions != 0 {
+		a.mode = d.uint32()
+	}
+	if a.flags&attrACModTime != 0 {
+		d.uint32()
+		a.mtime = time.Unix(int64(d.uint32()), 0).UTC()
+	}
+	if a.flags&attrExtended != 0 {
+		for count := d.uint32(); count > 0 && d.err == nil; count-- {
+			d.string()
+			d.string()
+		}
+	}
+	return a
+}
+
+// lastLine keeps the last line written to it, the message ssh exits with.
+type lastLine struct {
+	mu   sync.Mutex
+	line string
+}
+
+func (l *lastLine) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		if s := strings.TrimSpace(string(line)); s != "" {
+			l.line = s
+		}
+	}
+	return len(p), nil
+}
+
+func (l *lastLine) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.line
+}
@@ -0,0 +1,293 @@
+package journal
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	recordHeader       = "header"
+	recordPlanned      = "planned"
+	recordPlanComplete = "plan_complete"
+	recordDone         = "done"
+)
+
+// Entry is an object planned for processing.
+type Entry struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+type record struct {
+	Type        string            `json:"type"`
+	Operation   string            `json:"operation,omitempty"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+	CreatedAt   string            `json:"created_at,omitempty"`
+	Key         string            `json:"key,omitempty"`
+	Size        int64             `json:"size,omitempty"`
+	Meta        map[string]string `json:"meta,omitempty"`
+}
+
+// Journal persists the plan of a long operation and which items were processed, as JSON
+// lines, so an interrupted run can continue without re-scanning the bucket.
+// A nil *Journal is valid and records nothing.
+type Journal struct {
+	mu           sync.Mutex
+	path         string
+	file         *os.File
+	writer       *bufio.Writer
+	planned      []Entry
+	planComplete bool
+	meta         map[string]string
+	done         map[string]bool
+}
+
+// Fingerprint identifies an operation by its parameters so a journal is never resumed
+// by a different command line.
+func Fingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// DefaultPath returns the journal location used when no explicit path is given.
+func DefaultPath(operation, fingerprint string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "s3manager", "journals", fmt.Sprintf("%s-%s.jsonl", operation, fingerprint[:16]))
+}
+
+// Open creates a journal at path. With resume set, an existing journal for the same
+// operation and fingerprint is loaded; otherwise any existing file is replaced.
+func Open(path, operation, fingerprint string, resume bool) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	j := &Journal{
+		path: path,
+		meta: map[string]string{},
+		done: map[string]bool{},
+	}
+
+	if resume {
+		if err := j.load(operation, fingerprint); err != nil {
+			return nil, err
+		}
+	}
+
+	if j.planComplete {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open journal: %w", err)
+		}
+		j.file = file
+		j.writer = bufio.NewWriter(file)
+		// Terminate a possibly torn last line so new records start on their own line
+		if err := j.writer.WriteByte('\n'); err != nil {
+			return nil, fmt.Errorf("failed to write journal: %w", err)
+		}
+		return j, j.sync()
+	}
+
+	// Nothing usable to resume from: start a fresh journal
+	j.planned = nil
+	j.done = map[string]bool{}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+	j.file = file
+	j.writer = bufio.NewWriter(file)
+
+	if err := j.write(record{
+		Type:        recordHeader,
+		Operation:   operation,
+		Fingerprint: fingerprint,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+	}); err != nil {
+		return nil, err
+	}
+	return j, j.sync()
+}
+
+func (j *Journal) load(operation, fingerprint string) error {
+	file, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	first := true
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// A torn line from a crash is expected and skipped
+			continue
+		}
+
+		if first {
+			if rec.Type != recordHeader || rec.Operation != operation || rec.Fingerprint != fingerprint {
+				return fmt.Errorf("journal %s belongs to a different operation", j.path)
+			}
+			first = false
+			continue
+		}
+
+		switch rec.Type {
+		case recordPlanned:
+			j.planned = append(j.planned, Entry{Key: rec.Key, Size: rec.Size})
+		case recordPlanComplete:
+			j.planComplete = true
+			for k, v := range rec.Meta {
+				j.meta[k] = v
+			}
+		case recordDone:
+			j.done[rec.Key] = true
+		}
+	}
+	return scanner.Err()
+}
+
+// Path returns the journal file location.
+func (j *Journal) Path() string {
+	if j == nil {
+		return ""
+	}
+	return j.path
+}
+
+// Resumed reports whether a complete plan was loaded from a previous run.
+func (j *Journal) Resumed() bool {
+	return j != nil && j.planComplete
+}
+
+// Plan returns the planned entries and metadata loaded from a previous run.
+func (j *Journal) Plan() ([]Entry, map[string]string) {
+	if j == nil {
+		return nil, nil
+	}
+	return j.planned, j.meta
+}
+
+// RecordPlan stores the full list of items the operation is going to process.
+func (j *Journal) RecordPlan(entries []Entry, meta map[string]string) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, entry := range entries {
+		if err := j.write(record{Type: recordPlanned, Key: entry.Key, Size: entry.Size}); err != nil {
+			return err
+		}
+	}
+	if err := j.write(record{Type: recordPlanComplete, Meta: meta}); err != nil {
+		return err
+	}
+
+	j.planned = entries
+	j.planComplete = true
+	j.meta = meta
+	return j.sync()
+}
+
+// IsDone reports whether key was processed by this or a previous run.
+func (j *Journal) IsDone(key string) bool {
+	if j == nil {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done[key]
+}
+
+// DoneCount returns the number of processed keys.
+func (j *Journal) DoneCount() int {
+	if j == nil {
+		return 0
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.done)
+}
+
+// MarkDone records keys as processed and flushes them to disk.
+func (j *Journal) MarkDone(keys ...string) error {
+	if j == nil || len(keys) == 0 {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, key := range keys {
+		if err := j.write(record{Type: recordDone, Key: key}); err != nil {
+			return err
+		}
+		j.done[key] = true
+	}
+	return j.sync()
+}
+
+// Close flushes and closes the journal, keeping it for a later --resume.
+func (j *Journal) Close() error {
+	if j == nil || j.file == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.writer.Flush(); err != nil {
+		return err
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Remove closes and deletes the journal after the operation completed.
+func (j *Journal) Remove() error {
+	if j == nil {
+		return nil
+	}
+	if err := j.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
+
+func (j *Journal) write(rec record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal record: %w", err)
+	}
+	if _, err := j.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+func (j *Journal) sync() error {
+	if err := j.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return j.file.Sync()
+}
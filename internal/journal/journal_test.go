@@ -0,0 +1,122 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalResume(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "journal-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "delete.jsonl")
+	fingerprint := Fingerprint("bucket", "logs/", "30")
+
+	j, err := Open(path, "delete-old", fingerprint, false)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if j.Resumed() {
+		t.Errorf("fresh journal should not be resumed")
+	}
+
+	entries := []Entry{{Key: "a", Size: 1}, {Key: "b", Size: 2}, {Key: "c", Size: 3}}
+	if err := j.RecordPlan(entries, map[string]string{"cutoff_date": "2024-01-01T00:00:00Z"}); err != nil {
+		t.Fatalf("RecordPlan() error = %v", err)
+	}
+	if err := j.MarkDone("a", "b"); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+	j.Close()
+
+	// Simulate a torn write from a crash
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"type":"done","ke`)
+	f.Close()
+
+	resumed, err := Open(path, "delete-old", fingerprint, true)
+	if err != nil {
+		t.Fatalf("Open() resume error = %v", err)
+	}
+	defer resumed.Close()
+
+	if !resumed.Resumed() {
+		t.Fatalf("journal should be resumed")
+	}
+
+	plan, meta := resumed.Plan()
+	if len(plan) != 3 {
+		t.Errorf("plan length = %d, want %d", len(plan), 3)
+	}
+
+	if meta["cutoff_date"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("meta cutoff_date = %s", meta["cutoff_date"])
+	}
+
+	if !resumed.IsDone("a") || !resumed.IsDone("b") || resumed.IsDone("c") {
+		t.Errorf("done markers not restored correctly")
+	}
+
+	if _, err := Open(path, "delete-old", Fingerprint("other"), true); err == nil {
+		t.Errorf("Open() with a different fingerprint should return error")
+	}
+
+	if err := resumed.Remove(); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("journal file should be removed")
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	var j *Journal
+
+	if j.Resumed() || j.IsDone("a") {
+		t.Errorf("nil journal should report nothing")
+	}
+
+	if err := j.MarkDone("a"); err != nil {
+		t.Errorf("nil journal MarkDone() error = %v", err)
+	}
+
+	if err := j.Remove(); err != nil {
+		t.Errorf("nil journal Remove() error = %v", err)
+	}
+}
+
+func TestJournalAppendAfterTornLine(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "journal-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "delete.jsonl")
+	j, _ := Open(path, "delete-old", "fp", false)
+	j.RecordPlan([]Entry{{Key: "a"}, {Key: "b"}}, nil)
+	j.Close()
+
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"type":"do`)
+	f.Close()
+
+	resumed, _ := Open(path, "delete-old", "fp", true)
+	resumed.MarkDone("a")
+	resumed.Close()
+
+	again, err := Open(path, "delete-old", "fp", true)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer again.Close()
+
+	if !again.IsDone("a") {
+		t.Errorf("marker written after a torn line was lost")
+	}
+}
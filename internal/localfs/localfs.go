@@ -0,0 +1,188 @@
+// Package localfs is a storage backend that keeps objects as files in a local directory,
+// for testing workflows without network access and for offline mirrors. Object keys map
+// to paths below the root, "db/dump.sql" to <root>/db/dump.sql.
+package localfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"s3manager/internal/storage"
+)
+
+const (
+	// Scheme of the BUCKET_NAME URLs that select this backend
+	Scheme = "file://"
+
+	// Files are written under this name first and renamed once complete
+	tempPrefix = ".s3manager-tmp-"
+)
+
+type Store struct {
+	root string
+}
+
+var _ storage.ObjectStore = (*Store)(nil)
+
+// IsURL reports whether bucket is a file:// URL.
+func IsURL(bucket string) bool {
+	return strings.HasPrefix(bucket, Scheme)
+}
+
+// New returns a store for the directory of a file:// URL, or of a plain path. The
+// directory is created when it does not exist.
+func New(location string) (*Store, error) {
+	root := location
+	if IsURL(location) {
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file URL %q: %w", location, err)
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("invalid file URL %q, expected file:///absolute/path", location)
+		}
+		root = filepath.FromSlash(u.Path)
+	}
+	if root == "" {
+		return nil, fmt.Errorf("no directory in %q", location)
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", root, err)
+	}
+	return &Store{root: root}, nil
+}
+
+// Name returns the root directory.
+func (s *Store) Name() string {
+	return s.root
+}
+
+// path returns the file of key, refusing keys that would leave the root.
+func (s *Store) path(key string) (string, error) {
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid key %q for a local directory", key)
+	}
+	return filepath.Join(s.root, name), nil
+}
+
+// List returns every file whose key starts with prefix. Directories themselves are not
+// objects.
+func (s *Store) List(ctx context.Context, prefix string) ([]storage.Object, error) {
+	var objects []storage.Object
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), tempPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, storage.Object{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", s.root, err)
+	}
+	return objects, nil
+}
+
+// Put writes body to the file of key. The content is written to a temporary file that
+// replaces the old one only when complete, so readers never see a partial object.
+func (s *Store) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", key, err)
+	}
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens the file of key.
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return file, nil
+}
+
+// Delete removes the files of keys and the directories left empty. Missing files count
+// as deleted.
+func (s *Store) Delete(ctx context.Context, keys []string) ([]string, error) {
+	deleted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		path, err := s.path(key)
+		if err != nil {
+			return deleted, err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return deleted, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		deleted = append(deleted, key)
+		s.removeEmptyDirs(filepath.Dir(path))
+	}
+	return deleted, nil
+}
+
+// removeEmptyDirs removes dir and its parents up to the root for as long as they are
+// empty, like prefixes disappear from a bucket with their last object.
+func (s *Store) removeEmptyDirs(dir string) {
+	for dir != s.root && strings.HasPrefix(dir, s.root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
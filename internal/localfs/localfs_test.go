@@ -0,0 +1,117 @@
+package localfs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"s3manager/internal/storage"
+)
+
+func newTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "localfs-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	store, err := New("file://" + filepath.ToSlash(dir) + "/mirror")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return store, filepath.Join(dir, "mirror")
+}
+
+func TestStore(t *testing.T) {
+	store, root := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "db/2024/dump.sql", strings.NewReader("dump"), 4); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put(ctx, "web.tar", strings.NewReader("web"), 3); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "db", "2024", "dump.sql")); err != nil || string(data) != "dump" {
+		t.Errorf("file content = %q, %v, want dump", data, err)
+	}
+
+	objects, err := store.List(ctx, "db/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != "db/2024/dump.sql" || objects[0].Size != 4 {
+		t.Errorf("List() = %+v, want db/2024/dump.sql", objects)
+	}
+
+	body, err := store.Get(ctx, "web.tar")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "web" {
+		t.Errorf("Get() = %q, want web", data)
+	}
+
+	deleted, err := store.Delete(ctx, []string{"db/2024/dump.sql", "missing.txt"})
+	if err != nil || len(deleted) != 2 {
+		t.Errorf("Delete() = %v, %v, want both keys", deleted, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "db")); !os.IsNotExist(err) {
+		t.Errorf("empty directories should be removed with their last file, stat error = %v", err)
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Errorf("the root directory should be kept: %v", err)
+	}
+}
+
+func TestStoreRejectsKeysOutsideRoot(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	for _, key := range []string{"../escape.txt", "db/../../escape.txt", "/etc/passwd"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x"), 1); err == nil {
+			t.Errorf("Put(%q) should return error", key)
+		}
+	}
+	if _, err := New("file://backup-host/var/mirror"); err == nil {
+		t.Errorf("New() with a remote host should return error")
+	}
+}
+
+// The storage operations run end to end against a directory, without any network access.
+func TestStorageOperations(t *testing.T) {
+	store, root := newTestStore(t)
+	ctx := context.Background()
+
+	src, err := os.MkdirTemp("", "localfs-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	os.WriteFile(filepath.Join(src, "app.log"), []byte("log line"), 0644)
+
+	if _, err := storage.Upload(ctx, store, []string{filepath.Join(src, "app.log")}, "logs", false, "", nil, time.Time{}, ""); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	// Age the uploaded log past the retention
+	old := time.Now().AddDate(0, 0, -10)
+	if err := os.Chtimes(filepath.Join(root, "logs", "app.log"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := storage.DeleteOld(ctx, store, storage.DeleteOptions{Folders: []string{"logs"}, DaysOld: 7})
+	if err != nil {
+		t.Fatalf("DeleteOld() error = %v", err)
+	}
+	if result.DeletedCount != 1 || result.DeletedFiles[0] != "logs/app.log" {
+		t.Errorf("DeleteOld() = %+v, want logs/app.log deleted", result)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package lockfile
+
+import (
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("lock files are not supported on this platform")
+
+func tryLock(*os.File) error {
+	return errUnsupported
+}
+
+func unlock(*os.File) error {
+	return errUnsupported
+}
@@ -0,0 +1,94 @@
+package lockfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Wait between attempts while waiting for the lock
+const pollInterval = 500 * time.Millisecond
+
+// ErrLocked is returned when another process holds the lock.
+var ErrLocked = errors.New("lock file is held by another process")
+
+var errWouldBlock = errors.New("would block")
+
+// Lock is an exclusive advisory lock on a local file. The operating system releases it
+// when the process exits, so a crashed run never leaves a stale lock behind. A nil *Lock
+// is valid and does nothing.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire locks the file at path, creating it if needed, and records the process ID in
+// it. When another process holds the lock, Acquire waits for up to wait for it to be
+// released, or fails immediately with ErrLocked when wait is 0.
+func Acquire(ctx context.Context, path string, wait time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock file directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		err := tryLock(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errWouldBlock) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w: %s (pid %s)", ErrLocked, path, holderPID(path))
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			file.Close()
+			return nil, fmt.Errorf("gave up waiting for lock file %s: %w", path, context.Cause(ctx))
+		}
+	}
+
+	// The PID only helps people find the holder; the lock itself is the flock
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Release unlocks the file. The file is left in place: removing it would let a process
+// that already opened it lock a file nobody else can see.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	l.file.Truncate(0)
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to unlock %s: %w", l.file.Name(), err)
+	}
+	return l.file.Close()
+}
+
+func holderPID(path string) string {
+	data, err := os.ReadFile(path)
+	pid := strings.TrimSpace(string(data))
+	if err != nil || pid == "" {
+		return "unknown"
+	}
+	return pid
+}
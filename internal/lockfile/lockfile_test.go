@@ -0,0 +1,80 @@
+package lockfile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireIsExclusive(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "lockfile-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	path := filepath.Join(tempDir, "run", "backup.lock")
+
+	lock, err := Acquire(context.Background(), path, 0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("lock file = %q, want the process ID", data)
+	}
+
+	// flock locks belong to the open file, so a second open conflicts within one process too
+	if _, err := Acquire(context.Background(), path, 0); !errors.Is(err, ErrLocked) {
+		t.Errorf("second Acquire() error = %v, want ErrLocked", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	lock, err = Acquire(context.Background(), path, 0)
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "lockfile-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	path := filepath.Join(tempDir, "backup.lock")
+
+	held, err := Acquire(context.Background(), path, 0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		held.Release()
+	}()
+
+	lock, err := Acquire(context.Background(), path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("waiting Acquire() error = %v", err)
+	}
+	lock.Release()
+
+	held, _ = Acquire(context.Background(), path, 0)
+	defer held.Release()
+	start := time.Now()
+	if _, err := Acquire(context.Background(), path, 600*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Errorf("Acquire() error = %v, want ErrLocked after waiting", err)
+	}
+	if waited := time.Since(start); waited < 500*time.Millisecond {
+		t.Errorf("Acquire() gave up after %s, want it to wait", waited)
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+type ArchiveMember struct {
+	Name           string `json:"name"`
+	LocalPath      string `json:"local_path,omitempty"`
+	Size           int64  `json:"size"`
+	SizeHuman      string `json:"size_human"`
+	CompressedSize int64  `json:"compressed_size"`
+	Modified       string `json:"modified,omitempty"`
+}
+
+type ArchiveExtractResult struct {
+	BucketName       string          `json:"bucket_name"`
+	Key              string          `json:"key"`
+	Destination      string          `json:"destination"`
+	Members          []ArchiveMember `json:"members"`
+	TotalFiles       int             `json:"total_files"`
+	TotalSizeBytes   int64           `json:"total_size_bytes"`
+	TotalSizeHuman   string          `json:"total_size_human"`
+	ArchiveSizeBytes int64           `json:"archive_size_bytes"`
+	ArchiveSizeHuman string          `json:"archive_size_human"`
+	FetchedBytes     int64           `json:"fetched_bytes"`
+	FetchedHuman     string          `json:"fetched_human"`
+	Requests         int             `json:"requests"`
+	OperationTime    string          `json:"operation_time"`
+	Duration         string          `json:"duration"`
+}
+
+type ArchiveListResult struct {
+	BucketName          string          `json:"bucket_name"`
+	Key                 string          `json:"key"`
+	Members             []ArchiveMember `json:"members"`
+	TotalFiles          int             `json:"total_files"`
+	TotalSizeBytes      int64           `json:"total_size_bytes"`
+	TotalSizeHuman      string          `json:"total_size_human"`
+	CompressedSizeBytes int64           `json:"compressed_size_bytes"`
+	CompressedSizeHuman string          `json:"compressed_size_human"`
+	ArchiveSizeBytes    int64           `json:"archive_size_bytes"`
+	ArchiveSizeHuman    string          `json:"archive_size_human"`
+	FetchedBytes        int64           `json:"fetched_bytes"`
+	FetchedHuman        string          `json:"fetched_human"`
+	Requests            int             `json:"requests"`
+	OperationTime       string          `json:"operation_time"`
+}
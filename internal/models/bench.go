@@ -0,0 +1,26 @@
+package models
+
+type BenchPhase struct {
+	Duration        string  `json:"duration"`
+	ThroughputBytes float64 `json:"throughput_bytes_per_sec"`
+	ThroughputHuman string  `json:"throughput_human"`
+	// Requests are the part requests, whose latencies are reported
+	Requests   int    `json:"requests"`
+	LatencyP50 string `json:"latency_p50"`
+	LatencyP90 string `json:"latency_p90"`
+	LatencyP99 string `json:"latency_p99"`
+	LatencyMax string `json:"latency_max"`
+}
+
+type BenchResult struct {
+	BucketName    string     `json:"bucket_name"`
+	Key           string     `json:"key"`
+	SizeBytes     int64      `json:"size_bytes"`
+	SizeHuman     string     `json:"size_human"`
+	PartSizeBytes int64      `json:"part_size_bytes"`
+	PartSizeHuman string     `json:"part_size_human"`
+	Parallel      int        `json:"parallel"`
+	Upload        BenchPhase `json:"upload"`
+	Download      BenchPhase `json:"download"`
+	OperationTime string     `json:"operation_time"`
+}
@@ -0,0 +1,56 @@
+package models
+
+type WebsiteRedirect struct {
+	HostName             string `json:"host_name,omitempty"`
+	Protocol             string `json:"protocol,omitempty"`
+	HttpRedirectCode     string `json:"http_redirect_code,omitempty"`
+	ReplaceKeyPrefixWith string `json:"replace_key_prefix_with,omitempty"`
+	ReplaceKeyWith       string `json:"replace_key_with,omitempty"`
+}
+
+type WebsiteCondition struct {
+	KeyPrefixEquals             string `json:"key_prefix_equals,omitempty"`
+	HttpErrorCodeReturnedEquals string `json:"http_error_code_returned_equals,omitempty"`
+}
+
+type WebsiteRoutingRule struct {
+	Condition *WebsiteCondition `json:"condition,omitempty"`
+	Redirect  WebsiteRedirect   `json:"redirect"`
+}
+
+type BucketWebsite struct {
+	BucketName            string               `json:"bucket_name"`
+	Enabled               bool                 `json:"enabled"`
+	IndexDocument         string               `json:"index_document,omitempty"`
+	ErrorDocument         string               `json:"error_document,omitempty"`
+	RedirectAllRequestsTo *WebsiteRedirect     `json:"redirect_all_requests_to,omitempty"`
+	RoutingRules          []WebsiteRoutingRule `json:"routing_rules,omitempty"`
+	WebsiteEndpoint       string               `json:"website_endpoint,omitempty"`
+	OperationTime         string               `json:"operation_time"`
+}
+
+type BucketTags struct {
+	BucketName    string            `json:"bucket_name"`
+	Tags          map[string]string `json:"tags"`
+	OperationTime string            `json:"operation_time"`
+}
+
+type BucketLogging struct {
+	BucketName    string `json:"bucket_name"`
+	Enabled       bool   `json:"enabled"`
+	TargetBucket  string `json:"target_bucket,omitempty"`
+	TargetPrefix  string `json:"target_prefix,omitempty"`
+	OperationTime string `json:"operation_time"`
+}
+
+type MetricsConfiguration struct {
+	ID     string            `json:"id"`
+	Prefix string            `json:"prefix,omitempty"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+type BucketMetrics struct {
+	BucketName     string                 `json:"bucket_name"`
+	Configurations []MetricsConfiguration `json:"configurations"`
+	OperationTime  string                 `json:"operation_time"`
+}
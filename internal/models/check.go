@@ -0,0 +1,16 @@
+package models
+
+type FreshnessResult struct {
+	BucketName     string    `json:"bucket_name"`
+	Prefix         string    `json:"prefix"`
+	Pattern        string    `json:"pattern,omitempty"`
+	Status         string    `json:"status"`
+	OK             bool      `json:"ok"`
+	Problems       []string  `json:"problems"`
+	MaxAge         string    `json:"max_age,omitempty"`
+	MinSizeBytes   int64     `json:"min_size_bytes,omitempty"`
+	MinSizeHuman   string    `json:"min_size_human,omitempty"`
+	Latest         *ListItem `json:"latest"`
+	CheckedObjects int       `json:"checked_objects"`
+	OperationTime  string    `json:"operation_time"`
+}
@@ -0,0 +1,18 @@
+package models
+
+type ChecksumResult struct {
+	BucketName    string `json:"bucket_name"`
+	Key           string `json:"key"`
+	Size          int64  `json:"size"`
+	RemoteETag    string `json:"remote_etag"`
+	RemoteSHA256  string `json:"remote_sha256,omitempty"`
+	PartsCount    int    `json:"parts_count"`
+	PartSize      int64  `json:"part_size,omitempty"`
+	LocalPath     string `json:"local_path,omitempty"`
+	LocalSize     int64  `json:"local_size,omitempty"`
+	LocalETag     string `json:"local_etag,omitempty"`
+	LocalSHA256   string `json:"local_sha256,omitempty"`
+	Algorithm     string `json:"algorithm,omitempty"`
+	Match         *bool  `json:"match,omitempty"`
+	OperationTime string `json:"operation_time"`
+}
@@ -0,0 +1,31 @@
+package models
+
+type CompareItem struct {
+	Key string `json:"key"`
+	// Difference is missing, extra, size, etag or checksum
+	Difference     string `json:"difference"`
+	SourceSize     int64  `json:"source_size,omitempty"`
+	DestSize       int64  `json:"dest_size,omitempty"`
+	SourceETag     string `json:"source_etag,omitempty"`
+	DestETag       string `json:"dest_etag,omitempty"`
+	SourceChecksum string `json:"source_checksum,omitempty"`
+	DestChecksum   string `json:"dest_checksum,omitempty"`
+}
+
+type CompareResult struct {
+	SourceBucket string        `json:"source_bucket"`
+	DestBucket   string        `json:"dest_bucket"`
+	Prefix       string        `json:"prefix,omitempty"`
+	Differences  []CompareItem `json:"differences"`
+	// Consistent is true when every object exists in both buckets with the same content
+	Consistent     bool `json:"consistent"`
+	SourceCount    int  `json:"source_count"`
+	DestCount      int  `json:"dest_count"`
+	MatchingCount  int  `json:"matching_count"`
+	MissingCount   int  `json:"missing_count"`
+	ExtraCount     int  `json:"extra_count"`
+	DifferentCount int  `json:"different_count"`
+	// ChecksumCount is the number of objects with different ETags whose checksums were compared
+	ChecksumCount int    `json:"checksum_count,omitempty"`
+	OperationTime string `json:"operation_time"`
+}
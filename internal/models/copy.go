@@ -0,0 +1,92 @@
+package models
+
+type CopyResult struct {
+	BucketName     string `json:"bucket_name"`
+	SourceKey      string `json:"source_key"`
+	DestinationKey string `json:"destination_key"`
+	ETag           string `json:"etag,omitempty"`
+	// SSECustomerKey is set when the copy is encrypted with a customer-provided key
+	SSECustomerKey bool   `json:"sse_c,omitempty"`
+	OperationTime  string `json:"operation_time"`
+	Duration       string `json:"duration"`
+}
+
+type AppendResult struct {
+	BucketName string `json:"bucket_name"`
+	Key        string `json:"key"`
+	// Created is set when the object did not exist and was created with the data
+	Created           bool   `json:"created,omitempty"`
+	PreviousSizeBytes int64  `json:"previous_size_bytes"`
+	AppendedBytes     int64  `json:"appended_bytes"`
+	AppendedHuman     string `json:"appended_human"`
+	SizeBytes         int64  `json:"size_bytes"`
+	SizeHuman         string `json:"size_human"`
+	// CopiedBytes were copied on the server, DownloadedBytes read back because the
+	// object was too small to be copied as a part
+	CopiedBytes     int64  `json:"copied_bytes"`
+	DownloadedBytes int64  `json:"downloaded_bytes"`
+	Parts           int    `json:"parts"`
+	ETag            string `json:"etag,omitempty"`
+	OperationTime   string `json:"operation_time"`
+	Duration        string `json:"duration"`
+}
+
+type ConcatSource struct {
+	Key       string `json:"key"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+type ConcatResult struct {
+	BucketName     string         `json:"bucket_name"`
+	DestinationKey string         `json:"destination_key"`
+	Sources        []ConcatSource `json:"sources"`
+	SizeBytes      int64          `json:"size_bytes"`
+	SizeHuman      string         `json:"size_human"`
+	// CopiedBytes were copied on the server, DownloadedBytes read back because they
+	// would have made a part smaller than 5MB
+	CopiedBytes     int64  `json:"copied_bytes"`
+	DownloadedBytes int64  `json:"downloaded_bytes"`
+	Parts           int    `json:"parts"`
+	ETag            string `json:"etag,omitempty"`
+	// DeletedSources are the sources removed after the object was assembled
+	DeletedSources []string `json:"deleted_sources,omitempty"`
+	OperationTime  string   `json:"operation_time"`
+	Duration       string   `json:"duration"`
+}
+
+type TouchResult struct {
+	BucketName string `json:"bucket_name"`
+	Target     string `json:"target"`
+	// Keys are the objects copied onto themselves, which reset their LastModified
+	Keys           []string `json:"keys"`
+	Count          int      `json:"count"`
+	TotalSizeBytes int64    `json:"total_size_bytes"`
+	TotalSizeHuman string   `json:"total_size_human"`
+	OperationTime  string   `json:"operation_time"`
+	Duration       string   `json:"duration"`
+}
+
+type HeaderChange struct {
+	Header string `json:"header"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+type RewrittenObject struct {
+	Key     string         `json:"key"`
+	Changes []HeaderChange `json:"changes"`
+}
+
+type RewriteResult struct {
+	BucketName string `json:"bucket_name"`
+	Prefix     string `json:"prefix"`
+	// Objects are the objects copied onto themselves with new headers, or that would be
+	// with DryRun
+	Objects []RewrittenObject `json:"objects"`
+	Count   int               `json:"count"`
+	// Unchanged is the number of matching objects that already had the headers
+	Unchanged     int    `json:"unchanged"`
+	DryRun        bool   `json:"dry_run,omitempty"`
+	OperationTime string `json:"operation_time"`
+	Duration      string `json:"duration"`
+}
@@ -0,0 +1,41 @@
+package models
+
+type DeployItem struct {
+	LocalPath       string `json:"local_path"`
+	RemotePath      string `json:"remote_path"`
+	Size            int64  `json:"size"`
+	ContentType     string `json:"content_type"`
+	CacheControl    string `json:"cache_control"`
+	ContentEncoding string `json:"content_encoding,omitempty"`
+}
+
+type DeployResult struct {
+	BucketName      string           `json:"bucket_name"`
+	SourcePath      string           `json:"source_path"`
+	DestinationPath string           `json:"destination_path"`
+	Uploaded        []DeployItem     `json:"uploaded"`
+	UploadedCount   int              `json:"uploaded_count"`
+	SkippedCount    int              `json:"skipped_count"`
+	DeletedFiles    []string         `json:"deleted_files"`
+	DeletedCount    int              `json:"deleted_count"`
+	ProtectedCount  int              `json:"protected_count,omitempty"`
+	TrashFolder     string           `json:"trash_folder,omitempty"`
+	TotalSizeBytes  int64            `json:"total_size_bytes"`
+	TotalSizeHuman  string           `json:"total_size_human"`
+	OperationTime   string           `json:"operation_time"`
+	DeployDuration  string           `json:"deploy_duration"`
+	DryRun          bool             `json:"dry_run,omitempty"`
+	CDNInvalidation *CDNInvalidation `json:"cdn_invalidation,omitempty"`
+	Throttling      *Throttling      `json:"throttling,omitempty"`
+	Interrupted     bool             `json:"interrupted,omitempty"`
+	Error           string           `json:"error,omitempty"`
+}
+
+type CDNInvalidation struct {
+	Provider       string   `json:"provider"`
+	DistributionID string   `json:"distribution_id,omitempty"`
+	InvalidationID string   `json:"invalidation_id,omitempty"`
+	Status         string   `json:"status,omitempty"`
+	Paths          []string `json:"paths"`
+	Error          string   `json:"error,omitempty"`
+}
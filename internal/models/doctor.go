@@ -0,0 +1,24 @@
+package models
+
+type DoctorCheck struct {
+	Operation  string `json:"operation"`
+	Permission string `json:"permission"`
+	UsedBy     string `json:"used_by"`
+	Status     string `json:"status"`
+	// Optional operations only limit what is reported, commands still work without them
+	Optional bool   `json:"optional,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type DoctorResult struct {
+	BucketName    string        `json:"bucket_name"`
+	Provider      string        `json:"provider,omitempty"`
+	APIEndpoint   string        `json:"api_endpoint,omitempty"`
+	ProbeKey      string        `json:"probe_key,omitempty"`
+	Checks        []DoctorCheck `json:"checks"`
+	Passed        int           `json:"passed"`
+	Failed        int           `json:"failed"`
+	Warnings      []string      `json:"warnings,omitempty"`
+	OK            bool          `json:"ok"`
+	OperationTime string        `json:"operation_time"`
+}
@@ -1,19 +1,33 @@
 package models
 
 type DownloadItem struct {
-	RemotePath   string `json:"remote_path"`
-	LocalPath    string `json:"local_path"`
-	Size         int64  `json:"size"`
-	LastModified string `json:"last_modified"`
+	RemotePath        string `json:"remote_path"`
+	LocalPath         string `json:"local_path"`
+	Size              int64  `json:"size"`
+	LastModified      string `json:"last_modified"`
+	StorageClass      string `json:"storage_class,omitempty"`
+	ETag              string `json:"etag,omitempty"`
+	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"`
+	ChecksumType      string `json:"checksum_type,omitempty"`
+	SignatureVerified bool   `json:"signature_verified,omitempty"`
+	ChecksumVerified  bool   `json:"checksum_verified,omitempty"`
 }
 
 type DownloadResult struct {
-	BucketName       string         `json:"bucket_name"`
-	SourcePath       string         `json:"source_path"`
-	Items            []DownloadItem `json:"items"`
-	TotalFiles       int            `json:"total_files"`
-	TotalSizeBytes   int64          `json:"total_size_bytes"`
-	TotalSizeHuman   string         `json:"total_size_human"`
-	OperationTime    string         `json:"operation_time"`
-	DownloadDuration string         `json:"download_duration"`
+	BucketName  string         `json:"bucket_name"`
+	SourcePath  string         `json:"source_path"`
+	Items       []DownloadItem `json:"items"`
+	NotModified bool           `json:"not_modified,omitempty"`
+	// CreatedDirectories and SkippedFiles are reported by recursive downloads: the local
+	// directories they created, and the keys left out because their file already existed
+	CreatedDirectories []string    `json:"created_directories,omitempty"`
+	SkippedFiles       []string    `json:"skipped_files,omitempty"`
+	TotalFiles         int         `json:"total_files"`
+	TotalSizeBytes     int64       `json:"total_size_bytes"`
+	TotalSizeHuman     string      `json:"total_size_human"`
+	OperationTime      string      `json:"operation_time"`
+	DownloadDuration   string      `json:"download_duration"`
+	ThroughputBytes    float64     `json:"throughput_bytes_per_sec"`
+	ThroughputHuman    string      `json:"throughput_human"`
+	Throttling         *Throttling `json:"throttling,omitempty"`
 }
@@ -0,0 +1,34 @@
+package models
+
+// DumpManifest describes a database dump. dump stores it next to the dump, under the key
+// of the dump followed by .manifest.json.
+type DumpManifest struct {
+	// Engine is pg or mysql
+	Engine   string `json:"engine"`
+	Database string `json:"database"`
+	Host     string `json:"host,omitempty"`
+	// Tool is the program that wrote the dump, ToolVersion the version it reports
+	Tool        string `json:"tool"`
+	ToolVersion string `json:"tool_version,omitempty"`
+	Key         string `json:"key"`
+	Compression string `json:"compression"`
+	// DumpSizeBytes is the size of the output of the tool, SizeBytes and SHA256 are the
+	// size and digest of the stored, compressed object
+	DumpSizeBytes int64  `json:"dump_size_bytes"`
+	SizeBytes     int64  `json:"size_bytes"`
+	SHA256        string `json:"sha256"`
+	StartedAt     string `json:"started_at"`
+	FinishedAt    string `json:"finished_at"`
+	Duration      string `json:"duration"`
+}
+
+type DumpResult struct {
+	BucketName      string       `json:"bucket_name"`
+	Key             string       `json:"key"`
+	ManifestKey     string       `json:"manifest_key"`
+	SizeHuman       string       `json:"size_human"`
+	DumpSizeHuman   string       `json:"dump_size_human"`
+	ThroughputBytes float64      `json:"throughput_bytes_per_sec"`
+	ThroughputHuman string       `json:"throughput_human"`
+	Manifest        DumpManifest `json:"manifest"`
+}
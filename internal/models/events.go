@@ -0,0 +1,20 @@
+package models
+
+type EventAction struct {
+	Action string `json:"action"`
+	Target string `json:"target,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type EventResult struct {
+	MessageID     string        `json:"message_id"`
+	EventName     string        `json:"event_name"`
+	EventTime     string        `json:"event_time,omitempty"`
+	BucketName    string        `json:"bucket_name"`
+	Key           string        `json:"key"`
+	Size          int64         `json:"size"`
+	Actions       []EventAction `json:"actions"`
+	Skipped       bool          `json:"skipped,omitempty"`
+	OperationTime string        `json:"operation_time"`
+}
@@ -0,0 +1,16 @@
+package models
+
+type ExistsResult struct {
+	BucketName    string   `json:"bucket_name"`
+	Key           string   `json:"key"`
+	Status        string   `json:"status"`
+	Exists        bool     `json:"exists"`
+	Problems      []string `json:"problems"`
+	SizeBytes     int64    `json:"size_bytes,omitempty"`
+	SizeHuman     string   `json:"size_human,omitempty"`
+	LastModified  string   `json:"last_modified,omitempty"`
+	MinSizeBytes  int64    `json:"min_size_bytes,omitempty"`
+	MinSizeHuman  string   `json:"min_size_human,omitempty"`
+	NewerThan     string   `json:"newer_than,omitempty"`
+	OperationTime string   `json:"operation_time"`
+}
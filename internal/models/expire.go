@@ -0,0 +1,23 @@
+package models
+
+type ExpireItem struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type ExpireResult struct {
+	BucketName     string       `json:"bucket_name"`
+	Folder         string       `json:"folder"`
+	Items          []ExpireItem `json:"items"`
+	Count          int          `json:"count"`
+	TotalSizeBytes int64        `json:"total_size_bytes"`
+	TotalSizeHuman string       `json:"total_size_human"`
+	// CheckedCount is the number of objects whose tags were read
+	CheckedCount   int    `json:"checked_count"`
+	ProtectedCount int    `json:"protected_count,omitempty"`
+	InvalidCount   int    `json:"invalid_count,omitempty"`
+	TrashFolder    string `json:"trash_folder,omitempty"`
+	OperationTime  string `json:"operation_time"`
+	DryRun         bool   `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+type GrepMatch struct {
+	Key  string `json:"key"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+type GrepSummary struct {
+	BucketName      string `json:"bucket_name"`
+	Prefix          string `json:"prefix"`
+	Pattern         string `json:"pattern"`
+	SearchedObjects int    `json:"searched_objects"`
+	SearchedBytes   int64  `json:"searched_bytes"`
+	MatchedObjects  int    `json:"matched_objects"`
+	Matches         int    `json:"matches"`
+	Errors          int    `json:"errors"`
+	OperationTime   string `json:"operation_time"`
+}
@@ -0,0 +1,55 @@
+package models
+
+type IngestedFile struct {
+	// Path is the path of the file on the server
+	Path      string `json:"path"`
+	Key       string `json:"key"`
+	SizeBytes int64  `json:"size_bytes"`
+	// Removed is set when the file was deleted from the server after it was stored
+	Removed bool `json:"removed,omitempty"`
+}
+
+type IngestResult struct {
+	// Source is the URL of the server, without its password
+	Source      string   `json:"source"`
+	BucketName  string   `json:"bucket_name"`
+	Destination string   `json:"destination,omitempty"`
+	Include     []string `json:"include,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
+	// PlannedCount is the number of files selected for ingestion
+	PlannedCount  int `json:"planned_count"`
+	IngestedCount int `json:"ingested_count"`
+	// SkippedCount holds files already in the bucket with the same size
+	SkippedCount    int            `json:"skipped_count"`
+	RemovedCount    int            `json:"removed_count,omitempty"`
+	Files           []IngestedFile `json:"files"`
+	TotalSizeBytes  int64          `json:"total_size_bytes"`
+	TotalSizeHuman  string         `json:"total_size_human"`
+	OperationTime   string         `json:"operation_time"`
+	IngestDuration  string         `json:"ingest_duration"`
+	ThroughputBytes float64        `json:"throughput_bytes_per_sec"`
+	ThroughputHuman string         `json:"throughput_human"`
+	DryRun          bool           `json:"dry_run,omitempty"`
+	Interrupted     bool           `json:"interrupted,omitempty"`
+	Error           string         `json:"error,omitempty"`
+}
+
+type FetchResult struct {
+	// URL is the fetched URL, without its password
+	URL        string `json:"url"`
+	BucketName string `json:"bucket_name"`
+	Key        string `json:"key"`
+	SizeBytes  int64  `json:"size_bytes"`
+	SizeHuman  string `json:"size_human"`
+	// Checksums are the digests of the content by algorithm, sha256 and those that were
+	// verified
+	Checksums map[string]string `json:"checksums"`
+	// Verified lists the algorithms whose expected digest matched
+	Verified []string `json:"verified,omitempty"`
+	// Resumed counts the Range requests that continued an interrupted download
+	Resumed         int     `json:"resumed,omitempty"`
+	OperationTime   string  `json:"operation_time"`
+	Duration        string  `json:"duration"`
+	ThroughputBytes float64 `json:"throughput_bytes_per_sec"`
+	ThroughputHuman string  `json:"throughput_human"`
+}
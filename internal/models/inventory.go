@@ -0,0 +1,48 @@
+package models
+
+// InventoryRecord is one object of an inventory snapshot.
+type InventoryRecord struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"last_modified"`
+	ETag         string `json:"etag,omitempty"`
+	StorageClass string `json:"storage_class,omitempty"`
+}
+
+type InventoryResult struct {
+	BucketName        string `json:"bucket_name"`
+	Key               string `json:"key"`
+	Format            string `json:"format"`
+	Source            string `json:"source"`
+	ObjectCount       int64  `json:"object_count"`
+	TotalSizeBytes    int64  `json:"total_size_bytes"`
+	TotalSizeHuman    string `json:"total_size_human"`
+	SnapshotSizeBytes int64  `json:"snapshot_size_bytes"`
+	SnapshotSizeHuman string `json:"snapshot_size_human"`
+	OperationTime     string `json:"operation_time"`
+	Duration          string `json:"duration"`
+}
+
+type InventoryChange struct {
+	Key          string `json:"key"`
+	Change       string `json:"change"`
+	Size         int64  `json:"size"`
+	PreviousSize int64  `json:"previous_size,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	PreviousETag string `json:"previous_etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+type InventoryDiff struct {
+	BucketName     string            `json:"bucket_name"`
+	From           string            `json:"from"`
+	To             string            `json:"to"`
+	Changes        []InventoryChange `json:"changes"`
+	AddedCount     int               `json:"added_count"`
+	RemovedCount   int               `json:"removed_count"`
+	ChangedCount   int               `json:"changed_count"`
+	UnchangedCount int               `json:"unchanged_count"`
+	SizeDeltaBytes int64             `json:"size_delta_bytes"`
+	SizeDeltaHuman string            `json:"size_delta_human"`
+	OperationTime  string            `json:"operation_time"`
+}
@@ -0,0 +1,31 @@
+package models
+
+type ListItem struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	SizeHuman    string `json:"size_human"`
+	LastModified string `json:"last_modified"`
+	Age          string `json:"age"`
+	AgeSeconds   int64  `json:"age_seconds"`
+	StorageClass string `json:"storage_class,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	// ChecksumAlgorithm and ChecksumType describe the additional checksum stored with
+	// the object, if any
+	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"`
+	ChecksumType      string `json:"checksum_type,omitempty"`
+}
+
+type LatestResult struct {
+	BucketName    string            `json:"bucket_name"`
+	Prefix        string            `json:"prefix"`
+	Pattern       string            `json:"pattern,omitempty"`
+	Regex         string            `json:"regex,omitempty"`
+	Filter        string            `json:"filter,omitempty"`
+	TagFilter     map[string]string `json:"tag_filter,omitempty"`
+	OlderThan     string            `json:"older_than,omitempty"`
+	NewerThan     string            `json:"newer_than,omitempty"`
+	Items         []ListItem        `json:"items"`
+	Count         int               `json:"count"`
+	MatchedCount  int               `json:"matched_count"`
+	OperationTime string            `json:"operation_time"`
+}
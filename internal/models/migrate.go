@@ -0,0 +1,40 @@
+package models
+
+type MigrateMismatch struct {
+	Key string `json:"key"`
+	// Problem is missing, size or attributes
+	Problem    string `json:"problem"`
+	SourceSize int64  `json:"source_size"`
+	DestSize   int64  `json:"dest_size,omitempty"`
+	// Attributes are the names of the attributes that differ, for an attributes problem
+	Attributes []string `json:"attributes,omitempty"`
+}
+
+type MigrateResult struct {
+	SourceBucket string   `json:"source_bucket"`
+	DestBucket   string   `json:"dest_bucket"`
+	Prefix       string   `json:"prefix,omitempty"`
+	Include      []string `json:"include,omitempty"`
+	Exclude      []string `json:"exclude,omitempty"`
+	// PlannedCount is the number of objects selected for the migration
+	PlannedCount int `json:"planned_count"`
+	CopiedCount  int `json:"copied_count"`
+	// SkippedCount holds objects already in the destination with the same size
+	SkippedCount int `json:"skipped_count"`
+	// ResumedCount holds objects a previous run copied, according to the journal
+	ResumedCount   int    `json:"resumed_count,omitempty"`
+	TotalSizeBytes int64  `json:"total_size_bytes"`
+	TotalSizeHuman string `json:"total_size_human"`
+	// Verified is set when the final pass found every planned object in the destination
+	Verified        bool              `json:"verified"`
+	VerifiedCount   int               `json:"verified_count"`
+	Mismatches      []MigrateMismatch `json:"mismatches,omitempty"`
+	OperationTime   string            `json:"operation_time"`
+	MigrateDuration string            `json:"migrate_duration"`
+	ThroughputBytes float64           `json:"throughput_bytes_per_sec"`
+	ThroughputHuman string            `json:"throughput_human"`
+	Throttling      *Throttling       `json:"throttling,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+	Interrupted     bool              `json:"interrupted,omitempty"`
+	Error           string            `json:"error,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+type PingCheck struct {
+	Operation string `json:"operation"`
+	Status    string `json:"status"`
+	Latency   string `json:"latency"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type PingTLS struct {
+	Version           string `json:"version"`
+	CipherSuite       string `json:"cipher_suite"`
+	ServerName        string `json:"server_name"`
+	CertSubject       string `json:"cert_subject"`
+	CertIssuer        string `json:"cert_issuer"`
+	CertNotAfter      string `json:"cert_not_after"`
+	CertDaysRemaining int    `json:"cert_days_remaining"`
+}
+
+type PingResult struct {
+	BucketName string `json:"bucket_name"`
+	Provider   string `json:"provider,omitempty"`
+	// DetectedProvider is guessed from the endpoint host and the Server header
+	DetectedProvider string      `json:"detected_provider,omitempty"`
+	Endpoint         string      `json:"endpoint,omitempty"`
+	Region           string      `json:"region"`
+	BucketRegion     string      `json:"bucket_region,omitempty"`
+	Server           string      `json:"server,omitempty"`
+	TLS              *PingTLS    `json:"tls,omitempty"`
+	Checks           []PingCheck `json:"checks"`
+	Warnings         []string    `json:"warnings,omitempty"`
+	OK               bool        `json:"ok"`
+	OperationTime    string      `json:"operation_time"`
+}
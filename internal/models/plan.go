@@ -0,0 +1,56 @@
+package models
+
+type PlanItem struct {
+	Action       string `json:"action"`
+	Source       string `json:"source"`
+	Destination  string `json:"destination"`
+	Size         int64  `json:"size"`
+	SizeHuman    string `json:"size_human"`
+	LastModified string `json:"last_modified,omitempty"`
+	Overwrite    bool   `json:"overwrite,omitempty"`
+}
+
+type TransferPlan struct {
+	Operation      string     `json:"operation"`
+	BucketName     string     `json:"bucket_name"`
+	Source         string     `json:"source"`
+	Destination    string     `json:"destination"`
+	Items          []PlanItem `json:"items"`
+	TotalFiles     int        `json:"total_files"`
+	TotalSizeBytes int64      `json:"total_size_bytes"`
+	TotalSizeHuman string     `json:"total_size_human"`
+	OverwriteCount int        `json:"overwrite_count"`
+	OperationTime  string     `json:"operation_time"`
+	DryRun         bool       `json:"dry_run"`
+}
+
+type PlanObject struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+}
+
+// DeletionPlan is a reviewed list of objects that "apply" deletes verbatim.
+type DeletionPlan struct {
+	Version        int               `json:"version"`
+	Operation      string            `json:"operation"`
+	BucketName     string            `json:"bucket_name"`
+	Folder         string            `json:"folder"`
+	DaysOld        int               `json:"days_old"`
+	TagFilter      map[string]string `json:"tag_filter,omitempty"`
+	UnusedFor      string            `json:"unused_for,omitempty"`
+	DateFromKey    string            `json:"date_from_key,omitempty"`
+	Regex          string            `json:"regex,omitempty"`
+	Filter         string            `json:"filter,omitempty"`
+	CutoffDate     string            `json:"cutoff_date"`
+	NewerThan      string            `json:"newer_than,omitempty"`
+	CreatedAt      string            `json:"created_at"`
+	Objects        []PlanObject      `json:"objects"`
+	TotalObjects   int               `json:"total_objects"`
+	TotalSizeBytes int64             `json:"total_size_bytes"`
+	TotalSizeHuman string            `json:"total_size_human"`
+	ProtectedCount int               `json:"protected_count,omitempty"`
+	ExcludedCount  int               `json:"excluded_count,omitempty"`
+	UndatedCount   int               `json:"undated_count,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+type PruneResult struct {
+	BucketName        string   `json:"bucket_name"`
+	Folder            string   `json:"folder"`
+	Filter            string   `json:"filter,omitempty"`
+	MaxTotalSizeBytes int64    `json:"max_total_size_bytes"`
+	MaxTotalSizeHuman string   `json:"max_total_size_human"`
+	TotalSizeBytes    int64    `json:"total_size_bytes"`
+	TotalSizeHuman    string   `json:"total_size_human"`
+	KeptCount         int      `json:"kept_count"`
+	KeptSizeBytes     int64    `json:"kept_size_bytes"`
+	KeptSizeHuman     string   `json:"kept_size_human"`
+	DeletedFiles      []string `json:"deleted_files"`
+	DeletedCount      int      `json:"deleted_count"`
+	DeletedSizeBytes  int64    `json:"deleted_size_bytes"`
+	DeletedSizeHuman  string   `json:"deleted_size_human"`
+	// OverBudget is set when the kept objects alone exceed the budget, e.g. because they
+	// are protected
+	OverBudget     bool   `json:"over_budget,omitempty"`
+	ProtectedCount int    `json:"protected_count,omitempty"`
+	ExcludedCount  int    `json:"excluded_count,omitempty"`
+	TrashFolder    string `json:"trash_folder,omitempty"`
+	DryRun         bool   `json:"dry_run,omitempty"`
+	Interrupted    bool   `json:"interrupted,omitempty"`
+	Error          string `json:"error,omitempty"`
+	OperationTime  string `json:"operation_time"`
+}
@@ -0,0 +1,86 @@
+package models
+
+type AgeBucket struct {
+	// Age is the range of ages of the bucket, as "<7d" or ">=90d"
+	Age            string  `json:"age"`
+	Objects        int64   `json:"objects"`
+	SizeBytes      int64   `json:"size_bytes"`
+	SizeHuman      string  `json:"size_human"`
+	ObjectsPercent float64 `json:"objects_percent"`
+	BytesPercent   float64 `json:"bytes_percent"`
+}
+
+type RetentionReport struct {
+	BucketName     string      `json:"bucket_name"`
+	Prefix         string      `json:"prefix"`
+	Buckets        []AgeBucket `json:"buckets"`
+	TotalObjects   int64       `json:"total_objects"`
+	TotalSizeBytes int64       `json:"total_size_bytes"`
+	TotalSizeHuman string      `json:"total_size_human"`
+	OperationTime  string      `json:"operation_time"`
+}
+
+type DuplicateSet struct {
+	SizeBytes int64  `json:"size_bytes"`
+	SizeHuman string `json:"size_human"`
+	ETag      string `json:"etag,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+	// Keys are ordered oldest first, the first one is usually the original
+	Keys         []string `json:"keys"`
+	SavingsBytes int64    `json:"savings_bytes"`
+	SavingsHuman string   `json:"savings_human"`
+}
+
+type DuplicatesReport struct {
+	BucketName       string         `json:"bucket_name"`
+	Prefix           string         `json:"prefix"`
+	VerifiedContent  bool           `json:"verified_content"`
+	Sets             []DuplicateSet `json:"sets"`
+	SetCount         int            `json:"set_count"`
+	DuplicateObjects int            `json:"duplicate_objects"`
+	SavingsBytes     int64          `json:"savings_bytes"`
+	SavingsHuman     string         `json:"savings_human"`
+	ScannedObjects   int64          `json:"scanned_objects"`
+	HashedObjects    int            `json:"hashed_objects,omitempty"`
+	OperationTime    string         `json:"operation_time"`
+}
+
+type TopReport struct {
+	BucketName     string     `json:"bucket_name"`
+	Prefix         string     `json:"prefix"`
+	By             string     `json:"by"`
+	Limit          int        `json:"limit"`
+	Items          []ListItem `json:"items"`
+	Count          int        `json:"count"`
+	ScannedObjects int64      `json:"scanned_objects"`
+	OperationTime  string     `json:"operation_time"`
+}
+
+type AccessOwner struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Objects int    `json:"objects"`
+}
+
+type AccessFinding struct {
+	Key        string `json:"key"`
+	Issue      string `json:"issue"`
+	Owner      string `json:"owner"`
+	Grantee    string `json:"grantee,omitempty"`
+	Permission string `json:"permission,omitempty"`
+}
+
+type AccessReport struct {
+	BucketName      string          `json:"bucket_name"`
+	Prefix          string          `json:"prefix"`
+	BucketOwner     string          `json:"bucket_owner"`
+	ObjectOwnership string          `json:"object_ownership,omitempty"`
+	ListedObjects   int             `json:"listed_objects"`
+	CheckedObjects  int             `json:"checked_objects"`
+	Sampled         bool            `json:"sampled"`
+	Owners          []AccessOwner   `json:"owners"`
+	Findings        []AccessFinding `json:"findings"`
+	FindingCount    int             `json:"finding_count"`
+	AffectedObjects int             `json:"affected_objects"`
+	OperationTime   string          `json:"operation_time"`
+}
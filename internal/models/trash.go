@@ -0,0 +1,20 @@
+package models
+
+type TrashItem struct {
+	Key      string `json:"key"`
+	TrashKey string `json:"trash_key"`
+	Size     int64  `json:"size"`
+}
+
+type TrashResult struct {
+	BucketName     string      `json:"bucket_name"`
+	Operation      string      `json:"operation"`
+	TrashPrefix    string      `json:"trash_prefix"`
+	Items          []TrashItem `json:"items"`
+	Count          int         `json:"count"`
+	SkippedKeys    []string    `json:"skipped_keys,omitempty"`
+	TotalSizeBytes int64       `json:"total_size_bytes"`
+	TotalSizeHuman string      `json:"total_size_human"`
+	OperationTime  string      `json:"operation_time"`
+	DryRun         bool        `json:"dry_run,omitempty"`
+}
@@ -3,14 +3,26 @@ package models
 import "time"
 
 type BucketInfo struct {
-	BucketName     string    `json:"bucket_name"`
-	Region         string    `json:"region"`
-	CreationDate   time.Time `json:"creation_date"`
-	ObjectCount    int64     `json:"object_count"`
-	TotalSizeBytes int64     `json:"total_size_bytes"`
-	TotalSizeHuman string    `json:"total_size_human"`
-	LastModified   time.Time `json:"last_modified"`
-	APIEndpoint    string    `json:"api_endpoint,omitempty"`
+	BucketName string `json:"bucket_name"`
+	Region     string `json:"region"`
+	// CreationDate is null when ListBuckets is denied
+	CreationDate   *time.Time `json:"creation_date"`
+	ObjectCount    int64      `json:"object_count"`
+	TotalSizeBytes int64      `json:"total_size_bytes"`
+	TotalSizeHuman string     `json:"total_size_human"`
+	LastModified   time.Time  `json:"last_modified"`
+	APIEndpoint    string     `json:"api_endpoint,omitempty"`
+	Warnings       []string   `json:"warnings,omitempty"`
+}
+
+// Throttling summarises the requests a provider answered with SlowDown or similar
+// errors, which were retried with fewer requests in flight.
+type Throttling struct {
+	ThrottledRequests     int64  `json:"throttled_requests"`
+	ConcurrencyReductions int    `json:"concurrency_reductions"`
+	MinConcurrency        int    `json:"min_concurrency"`
+	FirstThrottled        string `json:"first_throttled"`
+	LastThrottled         string `json:"last_throttled"`
 }
 
 type ErrorResponse struct {
@@ -20,13 +32,27 @@ type ErrorResponse struct {
 }
 
 type DeleteResult struct {
-	BucketName     string   `json:"bucket_name"`
-	Folder         string   `json:"folder"`
-	DaysOld        int      `json:"days_old"`
-	DeletedFiles   []string `json:"deleted_files"`
-	DeletedCount   int      `json:"deleted_count"`
-	TotalSizeBytes int64    `json:"total_size_bytes"`
-	TotalSizeHuman string   `json:"total_size_human"`
-	OperationTime  string   `json:"operation_time"`
-	CutoffDate     string   `json:"cutoff_date"`
+	BucketName     string            `json:"bucket_name"`
+	Folder         string            `json:"folder"`
+	DaysOld        int               `json:"days_old"`
+	TagFilter      map[string]string `json:"tag_filter,omitempty"`
+	UnusedFor      string            `json:"unused_for,omitempty"`
+	DateFromKey    string            `json:"date_from_key,omitempty"`
+	Regex          string            `json:"regex,omitempty"`
+	Filter         string            `json:"filter,omitempty"`
+	DeletedFiles   []string          `json:"deleted_files"`
+	DeletedCount   int               `json:"deleted_count"`
+	TotalSizeBytes int64             `json:"total_size_bytes"`
+	TotalSizeHuman string            `json:"total_size_human"`
+	OperationTime  string            `json:"operation_time"`
+	CutoffDate     string            `json:"cutoff_date"`
+	NewerThan      string            `json:"newer_than,omitempty"`
+	Resumed        bool              `json:"resumed,omitempty"`
+	ProtectedCount int               `json:"protected_count,omitempty"`
+	ExcludedCount  int               `json:"excluded_count,omitempty"`
+	UndatedCount   int               `json:"undated_count,omitempty"`
+	TrashFolder    string            `json:"trash_folder,omitempty"`
+	Throttling     *Throttling       `json:"throttling,omitempty"`
+	Interrupted    bool              `json:"interrupted,omitempty"`
+	Error          string            `json:"error,omitempty"`
 }
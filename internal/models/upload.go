@@ -5,8 +5,16 @@ import "time"
 type UploadItem struct {
 	LocalPath  string `json:"local_path"`
 	RemotePath string `json:"remote_path"`
+	// Signature is the key of the detached signature uploaded with the file
+	Signature string `json:"signature,omitempty"`
+	// Expires is the time the object is tagged to expire at by upload --expire-after
+	Expires    string `json:"expires,omitempty"`
 	Size       int64  `json:"size"`
 	IsArchived bool   `json:"is_archived"`
+	// SourceRemoved is set when the local file was deleted or moved after its upload
+	// was verified, SourceMovedTo is where it was moved to
+	SourceRemoved bool   `json:"source_removed,omitempty"`
+	SourceMovedTo string `json:"source_moved_to,omitempty"`
 }
 
 type UploadResult struct {
@@ -20,6 +28,14 @@ type UploadResult struct {
 	ArchiveCreated  bool         `json:"archive_created"`
 	ArchivePath     string       `json:"archive_path,omitempty"`
 	UploadDuration  string       `json:"upload_duration"`
+	ThroughputBytes float64      `json:"throughput_bytes_per_sec"`
+	ThroughputHuman string       `json:"throughput_human"`
+	// ModifiedSince is set for incremental uploads, which skip files last modified before it
+	ModifiedSince string      `json:"modified_since,omitempty"`
+	SkippedCount  int         `json:"skipped_count,omitempty"`
+	Throttling    *Throttling `json:"throttling,omitempty"`
+	Interrupted   bool        `json:"interrupted,omitempty"`
+	Error         string      `json:"error,omitempty"`
 }
 
 type ArchiveInfo struct {
@@ -29,4 +45,6 @@ type ArchiveInfo struct {
 	OriginalSize     int64     `json:"original_size"`
 	CompressionRatio float64   `json:"compression_ratio"`
 	CreatedAt        time.Time `json:"created_at"`
+	FileCount        int       `json:"file_count"`
+	SkippedCount     int       `json:"skipped_count,omitempty"`
 }
@@ -0,0 +1,35 @@
+package models
+
+// OutputVersion is the version of the JSON results, which every result object carries
+// as schema_version. It is raised when a result changes in a way that can break
+// scripts, like a renamed or removed field; new fields do not raise it.
+//
+// Version 1 is the output before schema_version was added, version 2 adds it. Only the
+// current version is written: scripts check schema_version rather than pin an older
+// one.
+const OutputVersion = 2
+
+type VersionResult struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	// The latest release is looked up with version --check
+	LatestVersion   string   `json:"latest_version,omitempty"`
+	UpdateAvailable bool     `json:"update_available,omitempty"`
+	ReleaseURL      string   `json:"release_url,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+type SelfUpdateResult struct {
+	PreviousVersion string `json:"previous_version"`
+	Version         string `json:"version"`
+	Path            string `json:"path"`
+	Updated         bool   `json:"updated"`
+	Asset           string `json:"asset,omitempty"`
+	// ChecksumVerified is false when --skip-verify installed a release without checksums
+	ChecksumVerified bool   `json:"checksum_verified"`
+	ReleaseURL       string `json:"release_url,omitempty"`
+	OperationTime    string `json:"operation_time"`
+}
@@ -0,0 +1,13 @@
+package models
+
+type WaitResult struct {
+	BucketName string `json:"bucket_name"`
+	Pattern    string `json:"pattern"`
+	Found      bool   `json:"found"`
+	// TimedOut is set when the deadline passed before a matching object appeared
+	TimedOut      bool      `json:"timed_out"`
+	Object        *ListItem `json:"object"`
+	Attempts      int       `json:"attempts"`
+	Waited        string    `json:"waited"`
+	OperationTime string    `json:"operation_time"`
+}
@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"s3manager/config"
+)
+
+const (
+	NotifyAlways  = "always"
+	NotifyFailure = "failure"
+
+	// smtpsPort is the SMTP submission port that expects TLS from the first byte.
+	smtpsPort = 465
+
+	smtpTimeout = 30 * time.Second
+)
+
+// Mailer emails job reports over SMTP. A nil *Mailer is valid and sends nothing.
+type Mailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+	onlyFail bool
+}
+
+// NewMailer returns a mailer for the configured SMTP server, or nil when SMTP_HOST or
+// EMAIL_TO are not set.
+func NewMailer(cfg *config.Config) (*Mailer, error) {
+	var to []string
+	for _, address := range strings.Split(cfg.EmailTo, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			to = append(to, address)
+		}
+	}
+	if cfg.SMTPHost == "" || len(to) == 0 {
+		return nil, nil
+	}
+
+	switch cfg.EmailNotifyOn {
+	case "", NotifyAlways, NotifyFailure:
+	default:
+		return nil, fmt.Errorf("invalid EMAIL_NOTIFY_ON %q, expected %s or %s", cfg.EmailNotifyOn, NotifyAlways, NotifyFailure)
+	}
+
+	from := cfg.EmailFrom
+	if from == "" {
+		from = cfg.SMTPUsername
+	}
+	if from == "" {
+		return nil, fmt.Errorf("EMAIL_FROM is required for email notifications")
+	}
+
+	return &Mailer{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     from,
+		to:       to,
+		onlyFail: cfg.EmailNotifyOn == NotifyFailure,
+	}, nil
+}
+
+// Send emails a summary of the report with the full report attached as JSON.
+func (m *Mailer) Send(ctx context.Context, report Report) error {
+	if m == nil || (m.onlyFail && report.Status == StatusSuccess) {
+		return nil
+	}
+
+	message, err := m.buildMessage(report, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	var conn net.Conn
+	if m.port == smtpsPort {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: m.host}}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if m.port != smtpsPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				return fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	for _, to := range m.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (m *Mailer) buildMessage(report Report, now time.Time) ([]byte, error) {
+	attachment, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := "succeeded"
+	if report.Status != StatusSuccess {
+		outcome = "failed"
+	}
+	subject := fmt.Sprintf("[s3manager] %s %s", report.Command, outcome)
+	if report.Bucket != "" {
+		subject += " on " + report.Bucket
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "Command:  %s\r\n", report.Command)
+	if report.Bucket != "" {
+		fmt.Fprintf(&body, "Bucket:   %s\r\n", report.Bucket)
+	}
+	fmt.Fprintf(&body, "Status:   %s\r\n", report.Status)
+	fmt.Fprintf(&body, "Started:  %s\r\n", report.StartedAt)
+	fmt.Fprintf(&body, "Duration: %s\r\n", report.Duration)
+	if report.Error != "" {
+		fmt.Fprintf(&body, "Error:    %s\r\n", report.Error)
+	}
+	body.WriteString("\r\nThe full report is attached.\r\n")
+
+	filename := fmt.Sprintf("s3manager-%s-%s.json", report.Command, now.UTC().Format("20060102-150405"))
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(m.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.Write(body.Bytes())
+
+	fmt.Fprintf(&msg, "\r\n--%s\r\n", boundary)
+	fmt.Fprintf(&msg, "Content-Type: application/json; name=%q\r\n", filename)
+	fmt.Fprintf(&msg, "Content-Disposition: attachment; filename=%q\r\n", filename)
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString(attachment)
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	msg.WriteString(encoded + "\r\n")
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+
+	return msg.Bytes(), nil
+}
+
+func randomBoundary() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate MIME boundary: %w", err)
+	}
+	return "s3manager-" + hex.EncodeToString(buf), nil
+}
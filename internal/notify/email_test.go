@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"s3manager/config"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts one message without TLS or authentication and returns its data.
+func fakeSMTPServer(t *testing.T) (host string, port int, received <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "220 localhost ESMTP\r\n")
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					messages <- data.String()
+					fmt.Fprint(conn, "250 OK\r\n")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+
+			switch command := strings.ToUpper(strings.Fields(line)[0]); command {
+			case "EHLO", "HELO":
+				fmt.Fprint(conn, "250 localhost\r\n")
+			case "DATA":
+				inData = true
+				fmt.Fprint(conn, "354 Go ahead\r\n")
+			case "QUIT":
+				fmt.Fprint(conn, "221 Bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "250 OK\r\n")
+			}
+		}
+	}()
+
+	host, portString, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ = strconv.Atoi(portString)
+	return host, port, messages
+}
+
+func TestNewMailer(t *testing.T) {
+	mailer, err := NewMailer(&config.Config{SMTPHost: "smtp.example.com"})
+	if mailer != nil || err != nil {
+		t.Errorf("NewMailer() without recipients = %v, %v, want nil", mailer, err)
+	}
+
+	if _, err := NewMailer(&config.Config{SMTPHost: "smtp.example.com", EmailTo: "ops@example.com"}); err == nil {
+		t.Errorf("NewMailer() without sender should return error")
+	}
+
+	if _, err := NewMailer(&config.Config{SMTPHost: "smtp.example.com", EmailTo: "ops@example.com", EmailFrom: "a@example.com", EmailNotifyOn: "sometimes"}); err == nil {
+		t.Errorf("NewMailer() with invalid EMAIL_NOTIFY_ON should return error")
+	}
+
+	mailer, err = NewMailer(&config.Config{SMTPHost: "smtp.example.com", SMTPUsername: "bot@example.com", EmailTo: "a@example.com, b@example.com"})
+	if err != nil {
+		t.Fatalf("NewMailer() error = %v", err)
+	}
+	if mailer.from != "bot@example.com" || len(mailer.to) != 2 {
+		t.Errorf("mailer = %+v, want sender from username and 2 recipients", mailer)
+	}
+}
+
+func TestMailerSend(t *testing.T) {
+	host, port, received := fakeSMTPServer(t)
+
+	mailer, err := NewMailer(&config.Config{
+		SMTPHost:  host,
+		SMTPPort:  port,
+		EmailFrom: "s3manager@example.com",
+		EmailTo:   "ops@example.com",
+	})
+	if err != nil {
+		t.Fatalf("NewMailer() error = %v", err)
+	}
+
+	err = mailer.Send(context.Background(), Report{
+		Command:  "delete-old",
+		Bucket:   "my-bucket",
+		Status:   StatusFailure,
+		Duration: "1.5s",
+		Error:    "access denied",
+		Summary:  map[string]int{"deleted_count": 10},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case message := <-received:
+		for _, want := range []string{
+			"Subject: [s3manager] delete-old failed on my-bucket",
+			"Error:    access denied",
+			"Content-Disposition: attachment; filename=\"s3manager-delete-old-",
+		} {
+			if !strings.Contains(message, want) {
+				t.Errorf("message doesn't contain %q:\n%s", want, message)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestMailerOnlyFailures(t *testing.T) {
+	mailer := &Mailer{host: "127.0.0.1", port: 1, from: "a@example.com", to: []string{"b@example.com"}, onlyFail: true}
+
+	// Nothing is dialled for a successful run, so the unreachable server is never used
+	if err := mailer.Send(context.Background(), Report{Command: "upload", Status: StatusSuccess}); err != nil {
+		t.Errorf("Send() for success with EMAIL_NOTIFY_ON=failure error = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"s3manager/config"
+)
+
+// Hooks run user commands before and after a job, e.g. to quiesce a database before a
+// backup and to rotate local files after it. Each command gets the job report as JSON on
+// stdin. A nil *Hooks is valid and runs nothing.
+type Hooks struct {
+	pre  string
+	post string
+}
+
+// NewHooks returns the configured hooks, or nil when none are set.
+func NewHooks(cfg *config.Config) *Hooks {
+	if cfg.PreHook == "" && cfg.PostHook == "" {
+		return nil
+	}
+	return &Hooks{pre: cfg.PreHook, post: cfg.PostHook}
+}
+
+// Pre runs the pre-hook. The job must not run when it fails.
+func (h *Hooks) Pre(ctx context.Context, report Report) error {
+	if h == nil || h.pre == "" {
+		return nil
+	}
+	if err := runHook(ctx, "pre", h.pre, report); err != nil {
+		return fmt.Errorf("pre-hook failed: %w", err)
+	}
+	return nil
+}
+
+// Post runs the post-hook, after successful and failed runs alike; the status of the
+// report tells them apart.
+func (h *Hooks) Post(ctx context.Context, report Report) error {
+	if h == nil || h.post == "" {
+		return nil
+	}
+	if err := runHook(ctx, "post", h.post, report); err != nil {
+		return fmt.Errorf("post-hook failed: %w", err)
+	}
+	return nil
+}
+
+// runHook runs command with the shell. Its output goes to stderr, so it does not mix
+// with the JSON result on stdout.
+func runHook(ctx context.Context, stage, command string, report Report) error {
+	input, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	shell, flag := "/bin/sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	hook := exec.CommandContext(ctx, shell, flag, command)
+	hook.Stdin = bytes.NewReader(input)
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+	hook.Env = append(os.Environ(),
+		"S3MANAGER_HOOK="+stage,
+		"S3MANAGER_COMMAND="+report.Command,
+		"S3MANAGER_BUCKET="+report.Bucket,
+		"S3MANAGER_STATUS="+report.Status,
+	)
+	return hook.Run()
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"s3manager/config"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are run with /bin/sh in this test")
+	}
+	if h := NewHooks(&config.Config{}); h != nil {
+		t.Errorf("NewHooks() without commands = %+v, want nil", h)
+	}
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	h := NewHooks(&config.Config{
+		PreHook:  `cat > ` + input + ` && echo "$S3MANAGER_HOOK $S3MANAGER_COMMAND $S3MANAGER_STATUS" > ` + filepath.Join(dir, "env"),
+		PostHook: `[ "$S3MANAGER_STATUS" = success ] || exit 3`,
+	})
+
+	report := Report{Command: "upload", Bucket: "backups", Status: StatusStarted, StartedAt: "2024-03-15T02:00:00Z"}
+	if err := h.Pre(context.Background(), report); err != nil {
+		t.Fatalf("Pre() error = %v", err)
+	}
+	var got Report
+	data, _ := os.ReadFile(input)
+	if err := json.Unmarshal(data, &got); err != nil || got.Command != "upload" || got.Status != StatusStarted {
+		t.Errorf("hook input = %s, %v, want the report", data, err)
+	}
+	if env, _ := os.ReadFile(filepath.Join(dir, "env")); strings.TrimSpace(string(env)) != "pre upload started" {
+		t.Errorf("hook environment = %q, want pre upload started", env)
+	}
+
+	report.Status = StatusSuccess
+	if err := h.Post(context.Background(), report); err != nil {
+		t.Errorf("Post() error = %v", err)
+	}
+	report.Status = StatusFailure
+	if err := h.Post(context.Background(), report); err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Post() error = %v, want exit status 3", err)
+	}
+
+	var none *Hooks
+	if err := none.Pre(context.Background(), report); err != nil {
+		t.Errorf("nil Hooks Pre() error = %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"s3manager/config"
+)
+
+const (
+	// disabledURL turns off a single ping that would otherwise be derived from PING_URL.
+	disabledURL = "-"
+
+	pingTimeout = 10 * time.Second
+)
+
+// Pinger calls monitoring URLs such as healthchecks.io or Dead Man's Snitch when a job
+// starts, succeeds or fails. A nil *Pinger is valid and sends nothing. Ping failures are
+// logged and never fail the job itself.
+type Pinger struct {
+	startURL   string
+	successURL string
+	failURL    string
+	client     *http.Client
+}
+
+// NewPinger returns a pinger for the configured URLs, or nil when none are set. Start and
+// failure URLs default to the healthchecks.io "/start" and "/fail" endpoints of PING_URL.
+func NewPinger(cfg *config.Config) *Pinger {
+	p := &Pinger{
+		startURL:   cfg.PingStartURL,
+		successURL: cfg.PingURL,
+		failURL:    cfg.PingFailURL,
+		client:     &http.Client{Timeout: pingTimeout},
+	}
+
+	if base := strings.TrimRight(cfg.PingURL, "/"); base != "" {
+		if p.startURL == "" {
+			p.startURL = base + "/start"
+		}
+		if p.failURL == "" {
+			p.failURL = base + "/fail"
+		}
+	}
+	for _, url := range []*string{&p.startURL, &p.successURL, &p.failURL} {
+		if *url == disabledURL {
+			*url = ""
+		}
+	}
+
+	if p.startURL == "" && p.successURL == "" && p.failURL == "" {
+		return nil
+	}
+	return p
+}
+
+// Start signals that command has begun.
+func (p *Pinger) Start(ctx context.Context, command string) {
+	if p == nil || p.startURL == "" {
+		return
+	}
+	p.send(ctx, p.startURL, map[string]string{"command": command, "status": "start"})
+}
+
+// Finish sends the report to the success or failure URL depending on its status.
+func (p *Pinger) Finish(ctx context.Context, report Report) {
+	if p == nil {
+		return
+	}
+	url := p.successURL
+	if report.Status != StatusSuccess {
+		url = p.failURL
+	}
+	if url == "" {
+		return
+	}
+	p.send(ctx, url, report)
+}
+
+func (p *Pinger) send(ctx context.Context, url string, payload interface{}) {
+	if err := p.post(ctx, url, payload); err != nil {
+		slog.Warn("Failed to send monitoring ping", "url", url, "error", err)
+	}
+}
+
+func (p *Pinger) post(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode ping body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create ping request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send ping: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("ping returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"s3manager/config"
+	"sync"
+	"testing"
+)
+
+func TestNewPinger(t *testing.T) {
+	if p := NewPinger(&config.Config{}); p != nil {
+		t.Errorf("NewPinger() without URLs = %+v, want nil", p)
+	}
+
+	p := NewPinger(&config.Config{PingURL: "https://hc-ping.com/abc/"})
+	if p.startURL != "https://hc-ping.com/abc/start" || p.failURL != "https://hc-ping.com/abc/fail" {
+		t.Errorf("derived URLs = %s, %s, want healthchecks.io endpoints", p.startURL, p.failURL)
+	}
+
+	p = NewPinger(&config.Config{PingURL: "https://nosnch.in/abc", PingStartURL: "-", PingFailURL: "-"})
+	if p.startURL != "" || p.failURL != "" || p.successURL != "https://nosnch.in/abc" {
+		t.Errorf("URLs = %q, %q, %q, want only the success ping", p.startURL, p.successURL, p.failURL)
+	}
+}
+
+func TestPingerSendsReports(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] = body
+		mu.Unlock()
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	p := NewPinger(&config.Config{PingURL: server.URL + "/job"})
+	p.Start(context.Background(), "upload")
+	p.Finish(context.Background(), Report{Command: "upload", Status: StatusFailure, Error: "boom", Summary: map[string]int{"total_files": 3}})
+	p.Finish(context.Background(), Report{Command: "upload", Status: StatusSuccess})
+
+	if _, ok := bodies["/job/start"]; !ok {
+		t.Errorf("start ping was not sent")
+	}
+	if _, ok := bodies["/job"]; !ok {
+		t.Errorf("success ping was not sent")
+	}
+
+	var report Report
+	if err := json.Unmarshal(bodies["/job/fail"], &report); err != nil {
+		t.Fatalf("failure ping body is not JSON: %v", err)
+	}
+	if report.Error != "boom" || report.Command != "upload" || report.Summary == nil {
+		t.Errorf("failure report = %+v, want command, error and summary", report)
+	}
+
+	// A failing monitoring endpoint is only logged
+	var nilPinger *Pinger
+	nilPinger.Start(context.Background(), "upload")
+	NewPinger(&config.Config{PingURL: server.URL + "/broken"}).Finish(context.Background(), Report{Status: StatusSuccess})
+}
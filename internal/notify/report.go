@@ -0,0 +1,20 @@
+package notify
+
+const (
+	// StatusStarted is the status of the report given to pre-hooks
+	StatusStarted = "started"
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Report summarizes a finished job run, or a starting one for pre-hooks.
+type Report struct {
+	Command         string      `json:"command"`
+	Bucket          string      `json:"bucket,omitempty"`
+	Status          string      `json:"status"`
+	StartedAt       string      `json:"started_at"`
+	Duration        string      `json:"duration"`
+	DurationSeconds float64     `json:"duration_seconds"`
+	Error           string      `json:"error,omitempty"`
+	Summary         interface{} `json:"summary,omitempty"`
+}
@@ -0,0 +1,81 @@
+// Package runstate remembers when an operation last completed for a destination, so the
+// next run can limit itself to what changed since. The state is a small JSON file that
+// maps destinations to timestamps.
+package runstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State holds the last-run timestamps loaded from a state file.
+type State struct {
+	path     string
+	lastRuns map[string]time.Time
+}
+
+// DefaultPath returns the state file used when no explicit path is given.
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "s3manager", "last-run.json")
+}
+
+// Load reads the state file at path. A missing file is an empty state.
+func Load(path string) (*State, error) {
+	s := &State{path: path, lastRuns: map[string]time.Time{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.lastRuns); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// LastRun returns when the run for key last completed.
+func (s *State) LastRun(key string) (time.Time, bool) {
+	t, ok := s.lastRuns[key]
+	return t, ok
+}
+
+// Record stores t as the last run for key and saves the file. The file is replaced
+// atomically so a crash never leaves a truncated state behind.
+func (s *State) Record(key string, t time.Time) error {
+	s.lastRuns[key] = t.UTC()
+
+	data, err := json.MarshalIndent(s.lastRuns, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package runstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestState(t *testing.T) {
+	dir, err := os.MkdirTemp("", "runstate-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state", "last-run.json")
+
+	state, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing file error = %v", err)
+	}
+	if _, ok := state.LastRun("upload my-bucket/backups"); ok {
+		t.Errorf("LastRun() of an empty state should not be found")
+	}
+
+	run := time.Date(2024, 3, 15, 2, 0, 0, 0, time.UTC)
+	if err := state.Record("upload my-bucket/backups", run); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := state.Record("upload my-bucket/logs", run.Add(time.Hour)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	state, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, ok := state.LastRun("upload my-bucket/backups"); !ok || !got.Equal(run) {
+		t.Errorf("LastRun() = %v, %t, want %v", got, ok, run)
+	}
+	if got, _ := state.LastRun("upload my-bucket/logs"); !got.Equal(run.Add(time.Hour)) {
+		t.Errorf("LastRun() = %v, want %v", got, run.Add(time.Hour))
+	}
+
+	os.WriteFile(path, []byte("{truncated"), 0644)
+	if _, err := Load(path); err == nil {
+		t.Errorf("Load() of a corrupt file should return error")
+	}
+}
@@ -0,0 +1,261 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Issues found by the access report
+const (
+	// The object is owned by another account than the bucket, typically after a
+	// cross-account upload without bucket-owner-full-control
+	AccessForeignOwner = "foreign_owner"
+	// Everyone, even anonymous users, is granted access
+	AccessPublicGrant = "public_grant"
+	// Every AWS account is granted access
+	AccessAuthenticatedGrant = "authenticated_users_grant"
+	// An account other than the bucket and object owners is granted access
+	AccessCrossAccountGrant = "cross_account_grant"
+)
+
+// Grantee groups that open objects beyond the accounts involved
+const (
+	allUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// AccessOptions select the objects of the access report.
+type AccessOptions struct {
+	Prefix string
+	// Sample checks this many randomly chosen objects instead of all, 0 checks all
+	Sample int
+}
+
+// AccessReport reads the ACLs of the objects under Prefix and reports objects owned by
+// other accounts than the bucket, and grants to everyone, to all AWS accounts or to
+// accounts other than the owners. The trash is left out.
+func (c *Client) AccessReport(ctx context.Context, opts AccessOptions) (*models.AccessReport, error) {
+	if err := c.checkSupported(featureACL); err != nil {
+		return nil, err
+	}
+	if opts.Sample < 0 {
+		return nil, fmt.Errorf("sample size must not be negative")
+	}
+	startTime := time.Now()
+
+	bucketACL, err := c.s3Client.GetBucketAcl(ctx, &s3.GetBucketAclInput{
+		Bucket: aws.String(c.config.BucketName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket ACL: %w", err)
+	}
+	bucketOwner := ownerID(bucketACL.Owner)
+	ownership, err := c.objectOwnership(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, listed, err := c.sampleKeys(ctx, opts.Prefix, opts.Sample)
+	if err != nil {
+		return nil, err
+	}
+	acls, err := c.objectACLs(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AccessReport{
+		BucketName:      c.config.BucketName,
+		Prefix:          opts.Prefix,
+		BucketOwner:     bucketOwner,
+		ObjectOwnership: ownership,
+		ListedObjects:   listed,
+		CheckedObjects:  len(keys),
+		Sampled:         len(keys) < listed,
+		Owners:          []models.AccessOwner{},
+		Findings:        []models.AccessFinding{},
+		OperationTime:   utils.FormatTime(startTime),
+	}
+	owners := make(map[string]*models.AccessOwner)
+	for i, key := range keys {
+		owner := ownerID(acls[i].Owner)
+		if owners[owner] == nil {
+			owners[owner] = &models.AccessOwner{ID: owner}
+			if acls[i].Owner != nil {
+				owners[owner].Name = aws.ToString(acls[i].Owner.DisplayName)
+			}
+		}
+		owners[owner].Objects++
+
+		findings := accessFindings(key, owner, bucketOwner, acls[i].Grants)
+		if len(findings) > 0 {
+			report.Findings = append(report.Findings, findings...)
+			report.AffectedObjects++
+		}
+	}
+	for _, owner := range owners {
+		report.Owners = append(report.Owners, *owner)
+	}
+	sort.Slice(report.Owners, func(i, j int) bool {
+		if report.Owners[i].Objects != report.Owners[j].Objects {
+			return report.Owners[i].Objects > report.Owners[j].Objects
+		}
+		return report.Owners[i].ID < report.Owners[j].ID
+	})
+	report.FindingCount = len(report.Findings)
+	return report, nil
+}
+
+// accessFindings returns the issues of the object key owned by owner with grants.
+func accessFindings(key, owner, bucketOwner string, grants []types.Grant) []models.AccessFinding {
+	var findings []models.AccessFinding
+	if owner != bucketOwner {
+		findings = append(findings, models.AccessFinding{Key: key, Issue: AccessForeignOwner, Owner: owner})
+	}
+	for _, grant := range grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		finding := models.AccessFinding{Key: key, Owner: owner, Permission: string(grant.Permission)}
+		switch grantee := grant.Grantee; grantee.Type {
+		case types.TypeGroup:
+			switch aws.ToString(grantee.URI) {
+			case allUsersGroup:
+				finding.Issue = AccessPublicGrant
+			case authenticatedUsersGroup:
+				finding.Issue = AccessAuthenticatedGrant
+			}
+			finding.Grantee = aws.ToString(grantee.URI)
+		case types.TypeCanonicalUser:
+			if id := aws.ToString(grantee.ID); id != owner && id != bucketOwner {
+				finding.Issue = AccessCrossAccountGrant
+				finding.Grantee = id
+			}
+		case types.TypeAmazonCustomerByEmail:
+			finding.Issue = AccessCrossAccountGrant
+			finding.Grantee = aws.ToString(grantee.EmailAddress)
+		}
+		if finding.Issue != "" {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// objectOwnership returns the object ownership setting of the bucket, "" when the bucket
+// has none, which means ObjectWriter.
+func (c *Client) objectOwnership(ctx context.Context) (string, error) {
+	resp, err := c.s3Client.GetBucketOwnershipControls(ctx, &s3.GetBucketOwnershipControlsInput{
+		Bucket: aws.String(c.config.BucketName),
+	})
+	if err != nil {
+		if hasErrorCode(err, "OwnershipControlsNotFoundError") {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get bucket ownership controls: %w", err)
+	}
+	if resp.OwnershipControls == nil || len(resp.OwnershipControls.Rules) == 0 {
+		return "", nil
+	}
+	return string(resp.OwnershipControls.Rules[0].ObjectOwnership), nil
+}
+
+// sampleKeys lists the keys under prefix outside the trash and returns them sorted, or a
+// uniform random sample of size of them. Only the sample is kept while listing. It also
+// returns the number of listed keys.
+func (c *Client) sampleKeys(ctx context.Context, prefix string, size int) ([]string, int, error) {
+	var mu sync.Mutex
+	var keys []string
+	listed := 0
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		key := aws.ToString(obj.Key)
+		if c.inTrash(key) {
+			return false
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		listed++
+		switch {
+		case size == 0 || len(keys) < size:
+			keys = append(keys, key)
+		default:
+			if i := rand.IntN(listed); i < size {
+				keys[i] = key
+			}
+		}
+		return false
+	})
+	if _, err := lister.listAll(ctx, prefix); err != nil {
+		return nil, 0, fmt.Errorf("failed to list objects: %w", err)
+	}
+	sort.Strings(keys)
+	return keys, listed, nil
+}
+
+// objectACLs reads the ACLs of keys in parallel, in the order of keys.
+func (c *Client) objectACLs(ctx context.Context, keys []string) ([]*s3.GetObjectAclOutput, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	acls := make([]*s3.GetObjectAclOutput, len(keys))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, c.settings().RequestConcurrency)
+
+	for i, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			acl, err := c.s3Client.GetObjectAcl(ctx, &s3.GetObjectAclInput{
+				Bucket: aws.String(c.config.BucketName),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to get ACL of %s: %w", key, err)
+					cancel()
+				}
+				return
+			}
+			acls[i] = acl
+		}(i, key)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, context.Cause(ctx)
+	}
+	return acls, nil
+}
+
+func ownerID(owner *types.Owner) string {
+	if owner == nil {
+		return ""
+	}
+	return aws.ToString(owner.ID)
+}
@@ -0,0 +1,80 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"s3manager/config"
+	"s3manager/internal/models"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestAccessReport(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	now := time.Now()
+	for _, key := range []string{"data/clean.txt", "data/foreign.txt", "data/public.txt", "data/shared.txt", "other/foreign.txt"} {
+		fake.PutObject("test-bucket", key, []byte(key), now)
+	}
+	fake.SetACL("test-bucket", "data/foreign.txt", "partner-account")
+	fake.SetACL("test-bucket", "data/public.txt", s3fake.BucketOwner,
+		s3fake.Grant{URI: allUsersGroup, Permission: "READ"})
+	fake.SetACL("test-bucket", "data/shared.txt", s3fake.BucketOwner,
+		s3fake.Grant{ID: s3fake.BucketOwner, Permission: "READ"},
+		s3fake.Grant{ID: "auditor-account", Permission: "READ_ACP"})
+	fake.SetACL("test-bucket", "other/foreign.txt", "partner-account")
+
+	client := newTestClient(t, fake, nil)
+	report, err := client.AccessReport(context.Background(), AccessOptions{Prefix: "data/"})
+	if err != nil {
+		t.Fatalf("AccessReport() error = %v", err)
+	}
+
+	if report.BucketOwner != s3fake.BucketOwner || report.CheckedObjects != 4 || report.Sampled {
+		t.Errorf("AccessReport() = %+v, want 4 objects of %s checked", report, s3fake.BucketOwner)
+	}
+	wantOwners := []models.AccessOwner{{ID: s3fake.BucketOwner, Objects: 3}, {ID: "partner-account", Objects: 1}}
+	if !reflect.DeepEqual(report.Owners, wantOwners) {
+		t.Errorf("Owners = %+v, want %+v", report.Owners, wantOwners)
+	}
+	wantFindings := []models.AccessFinding{
+		{Key: "data/foreign.txt", Issue: AccessForeignOwner, Owner: "partner-account"},
+		{Key: "data/public.txt", Issue: AccessPublicGrant, Owner: s3fake.BucketOwner, Grantee: allUsersGroup, Permission: "READ"},
+		{Key: "data/shared.txt", Issue: AccessCrossAccountGrant, Owner: s3fake.BucketOwner, Grantee: "auditor-account", Permission: "READ_ACP"},
+	}
+	if !reflect.DeepEqual(report.Findings, wantFindings) {
+		t.Errorf("Findings = %+v, want %+v", report.Findings, wantFindings)
+	}
+	if report.AffectedObjects != 3 {
+		t.Errorf("AffectedObjects = %d, want 3", report.AffectedObjects)
+	}
+}
+
+func TestAccessReportSample(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		fake.PutObject("test-bucket", key, []byte(key), time.Now())
+	}
+
+	client := newTestClient(t, fake, nil)
+	report, err := client.AccessReport(context.Background(), AccessOptions{Sample: 4})
+	if err != nil {
+		t.Fatalf("AccessReport() error = %v", err)
+	}
+	if report.ListedObjects != 6 || report.CheckedObjects != 4 || !report.Sampled {
+		t.Errorf("AccessReport() listed %d, checked %d, sampled %v, want 6, 4, true",
+			report.ListedObjects, report.CheckedObjects, report.Sampled)
+	}
+}
+
+func TestAccessReportUnsupported(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) { cfg.Provider = "gcs" })
+	if _, err := client.AccessReport(context.Background(), AccessOptions{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("AccessReport() error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,159 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/accesslog"
+	"s3manager/pkg/utils"
+)
+
+// ErrAccessLogsIncomplete is returned when the access logs start after the period that
+// has to be checked for reads, so unread objects cannot be told apart from unlogged ones.
+var ErrAccessLogsIncomplete = errors.New("access logs do not cover the whole period")
+
+// withoutReadSince drops the items that were read after since according to the server
+// access logs in AccessLogBucket.
+func withoutReadSince[T any](ctx context.Context, c *Client, items []T, key func(T) string, since time.Time) ([]T, error) {
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	lastRead, err := c.lastReads(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var unread []T
+	for _, item := range items {
+		if !lastRead[key(item)].After(since) {
+			unread = append(unread, item)
+		}
+	}
+	return unread, nil
+}
+
+// lastReads returns the last read time of every object of the bucket read since then.
+// It fails when the oldest log was written after since, e.g. because logging was only
+// enabled recently or old logs were expired.
+func (c *Client) lastReads(ctx context.Context, since time.Time) (map[string]time.Time, error) {
+	if c.config.AccessLogBucket == "" {
+		return nil, fmt.Errorf("no access log bucket configured, set ACCESS_LOG_BUCKET or --access-log-bucket")
+	}
+
+	var logs []types.Object
+	var oldest time.Time
+	paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.config.AccessLogBucket),
+		Prefix: aws.String(c.config.AccessLogPrefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list access logs: %w", err)
+		}
+		for _, obj := range page.Contents {
+			written := aws.ToTime(obj.LastModified)
+			if oldest.IsZero() || written.Before(oldest) {
+				oldest = written
+			}
+			if !written.Before(since) {
+				logs = append(logs, obj)
+			}
+		}
+	}
+
+	if oldest.IsZero() {
+		return nil, fmt.Errorf("%w: no logs found in s3://%s/%s", ErrAccessLogsIncomplete,
+			c.config.AccessLogBucket, c.config.AccessLogPrefix)
+	}
+	if oldest.After(since) {
+		return nil, fmt.Errorf("%w: the oldest log is from %s, reads since %s are needed",
+			ErrAccessLogsIncomplete, utils.FormatTime(oldest), utils.FormatTime(since))
+	}
+
+	return c.readAccessLogs(ctx, logs)
+}
+
+// readAccessLogs downloads the given log objects in parallel and merges their reads.
+func (c *Client) readAccessLogs(ctx context.Context, logs []types.Object) (map[string]time.Time, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	var skipped int
+	lastRead := make(map[string]time.Time)
+	// Log objects are small and numerous, so several are read at once
+	sem := make(chan struct{}, c.settings().RequestConcurrency)
+
+	for _, obj := range logs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			reads := make(map[string]time.Time)
+			n, err := c.readAccessLog(ctx, key, reads)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			skipped += n
+			for objectKey, readAt := range reads {
+				if readAt.After(lastRead[objectKey]) {
+					lastRead[objectKey] = readAt
+				}
+			}
+		}(aws.ToString(obj.Key))
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if skipped > 0 {
+		slog.Warn("Skipped unparsable access log records", "count", skipped)
+	}
+	return lastRead, nil
+}
+
+func (c *Client) readAccessLog(ctx context.Context, key string, reads map[string]time.Time) (int, error) {
+	resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.AccessLogBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read access log %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	skipped, err := accesslog.LastReads(resp.Body, c.config.BucketName, reads)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read access log %s: %w", key, err)
+	}
+	return skipped, nil
+}
@@ -0,0 +1,74 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"s3manager/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteOldFilesUnusedFor(t *testing.T) {
+	now := time.Now().UTC()
+	recentRead := now.Add(-48 * time.Hour).Format("02/Jan/2006:15:04:05 -0700")
+	logRecord := func(key string) string {
+		return fmt.Sprintf(`owner test-bucket [%s] 192.0.2.3 - REQ REST.GET.OBJECT %s "GET /test-bucket/%s HTTP/1.1" 200 - 10 10 7 - "-" "curl/8.0" -`+"\n", recentRead, key, key)
+	}
+	oldestLog := "2020-01-01T00:00:00Z"
+
+	var deleted []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/logs-bucket" || r.URL.Path == "/logs-bucket/":
+			fmt.Fprintf(w, `<ListBucketResult>
+				<Contents><Key>access/2020-01-01-00-00-00-AAAA</Key><LastModified>%s</LastModified><Size>1</Size></Contents>
+				<Contents><Key>access/recent-BBBB</Key><LastModified>%s</LastModified><Size>1</Size></Contents>
+			</ListBucketResult>`, oldestLog, now.Add(-24*time.Hour).Format(time.RFC3339))
+		case r.URL.Path == "/logs-bucket/access/recent-BBBB":
+			fmt.Fprint(w, logRecord("assets/read.png"))
+		case r.URL.Path == "/logs-bucket/access/2020-01-01-00-00-00-AAAA":
+			t.Errorf("logs from before the period should not be read")
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `<ListBucketResult>
+				<Contents><Key>assets/read.png</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>10</Size></Contents>
+				<Contents><Key>assets/unread.png</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>20</Size></Contents>
+			</ListBucketResult>`)
+		default:
+			deleted = append(deleted, "request")
+			fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+		}
+	})
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.AccessLogBucket = "logs-bucket"
+		cfg.AccessLogPrefix = "access/"
+	})
+	opts := DeleteOptions{Folders: []string{"assets"}, UnusedFor: 180 * 24 * time.Hour, DryRun: true}
+
+	result, err := client.DeleteOldFiles(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if strings.Join(result.DeletedFiles, ",") != "assets/unread.png" || result.UnusedFor != "180d" {
+		t.Errorf("result = %+v, want only the unread object", result)
+	}
+
+	plan, err := client.PlanDeleteOld(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("PlanDeleteOld() error = %v", err)
+	}
+	if plan.TotalObjects != 1 || plan.Objects[0].Key != "assets/unread.png" {
+		t.Errorf("plan = %+v, want only the unread object", plan)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("dry run sent %d delete requests", len(deleted))
+	}
+
+	// Logs starting inside the period cannot prove that an object was unused
+	oldestLog = now.Add(-30 * 24 * time.Hour).Format(time.RFC3339)
+	if _, err := client.DeleteOldFiles(context.Background(), opts); !errors.Is(err, ErrAccessLogsIncomplete) {
+		t.Errorf("DeleteOldFiles() error = %v, want ErrAccessLogsIncomplete", err)
+	}
+}
@@ -0,0 +1,110 @@
+package s3client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Append adds the content of body to the end of the object key, creating the object when
+// it does not exist. S3 cannot change objects in place, so the object is written again as
+// a multipart upload whose first parts are copied from the current object on the server
+// and whose last parts hold body; only an object smaller than 5MB is downloaded for it.
+// Content type, metadata and tags are kept. The upload only completes when the object
+// was not replaced meanwhile, so that concurrent appends fail instead of losing data.
+func (c *Client) Append(ctx context.Context, key string, body io.Reader) (*models.AppendResult, error) {
+	startTime := time.Now()
+	result := &models.AppendResult{
+		BucketName:    c.config.BucketName,
+		Key:           key,
+		OperationTime: utils.FormatTime(startTime),
+	}
+
+	head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	var input *s3.CreateMultipartUploadInput
+	switch {
+	case hasErrorCode(err, "NotFound", "NoSuchKey"):
+		result.Created = true
+		input = objectHeaders{ContentType: aws.String(c.detectContentType(key))}.multipartInput(c.config.BucketName, key)
+	case err != nil:
+		return nil, fmt.Errorf("failed to check %s: %w", key, err)
+	default:
+		result.PreviousSizeBytes = aws.ToInt64(head.ContentLength)
+		input = headersOf(head).multipartInput(c.config.BucketName, key)
+		if err := c.setUploadTags(ctx, input, key); err != nil {
+			return nil, err
+		}
+	}
+
+	// Nothing to append leaves the object as it is
+	reader := bufio.NewReader(body)
+	if _, err := reader.Peek(1); err == io.EOF {
+		result.Created = false
+		result.SizeBytes = result.PreviousSizeBytes
+		return finishAppend(result, startTime), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the data to append: %w", err)
+	}
+
+	m, err := c.newComposer(ctx, input, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	etag := ""
+	if !result.Created {
+		etag = aws.ToString(head.ETag)
+		if err := m.addObject(ctx, key, etag, result.PreviousSizeBytes); err != nil {
+			m.abort()
+			return nil, conflictError(key, err)
+		}
+	}
+	appended, err := m.addReader(ctx, reader)
+	if err != nil {
+		m.abort()
+		return nil, fmt.Errorf("failed to append to %s: %w", key, err)
+	}
+	newETag, err := m.complete(ctx, etag, result.Created)
+	if err != nil {
+		m.abort()
+		return nil, conflictError(key, fmt.Errorf("failed to append to %s: %w", key, err))
+	}
+
+	result.AppendedBytes = appended
+	result.SizeBytes = result.PreviousSizeBytes + appended
+	result.CopiedBytes = m.copied
+	result.DownloadedBytes = m.downloaded
+	result.Parts = len(m.parts)
+	result.ETag = strings.Trim(newETag, `"`)
+	return finishAppend(result, startTime), nil
+}
+
+// ErrConcurrentWrite is returned when an object changed while it was being rewritten.
+var ErrConcurrentWrite = errors.New("the object was changed by another writer")
+
+// conflictError reports a failed precondition of a rewrite of key as ErrConcurrentWrite.
+func conflictError(key string, err error) error {
+	if isConditionFailed(err) {
+		return fmt.Errorf("%w: %s changed while it was rewritten, nothing was written", ErrConcurrentWrite, key)
+	}
+	return err
+}
+
+func finishAppend(result *models.AppendResult, startTime time.Time) *models.AppendResult {
+	result.AppendedHuman = utils.FormatBytes(result.AppendedBytes)
+	result.SizeHuman = utils.FormatBytes(result.SizeBytes)
+	result.Duration = time.Since(startTime).Truncate(time.Millisecond).String()
+	return result
+}
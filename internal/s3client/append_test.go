@@ -0,0 +1,102 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	// A missing object is created
+	result, err := client.Append(ctx, "logs/app.log", strings.NewReader("line 1\n"))
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if !result.Created || result.SizeBytes != 7 {
+		t.Errorf("Append() = %+v, want a created object of 7 bytes", result)
+	}
+
+	// A small object is read back and written again with the data
+	fake.SetTags("test-bucket", "logs/app.log", map[string]string{"team": "ops"})
+	result, err = client.Append(ctx, "logs/app.log", strings.NewReader("line 2\n"))
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if result.Created || result.PreviousSizeBytes != 7 || result.DownloadedBytes != 7 || result.CopiedBytes != 0 || result.Parts != 1 {
+		t.Errorf("Append() = %+v, want 7 bytes read back into a single part", result)
+	}
+	obj, _ := fake.Object("test-bucket", "logs/app.log")
+	if string(obj.Data) != "line 1\nline 2\n" {
+		t.Errorf("object holds %q", obj.Data)
+	}
+	if !reflect.DeepEqual(obj.Tags, map[string]string{"team": "ops"}) {
+		t.Errorf("tags = %v, want them kept", obj.Tags)
+	}
+
+	// Nothing to append leaves the object alone
+	result, err = client.Append(ctx, "logs/app.log", strings.NewReader(""))
+	if err != nil || result.AppendedBytes != 0 || result.SizeBytes != 14 {
+		t.Errorf("Append() of nothing = %+v, %v", result, err)
+	}
+}
+
+func TestAppendCopiesLargeObjects(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+
+	existing := bytes.Repeat([]byte("0123456789"), 600*1024)
+	fake.PutObject("test-bucket", "logs/big.log", existing, time.Now())
+
+	result, err := client.Append(context.Background(), "logs/big.log", strings.NewReader("tail\n"))
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if result.CopiedBytes != int64(len(existing)) || result.DownloadedBytes != 0 || result.Parts != 2 {
+		t.Errorf("Append() = %+v, want the object copied on the server", result)
+	}
+	obj, _ := fake.Object("test-bucket", "logs/big.log")
+	if !bytes.Equal(obj.Data, append(existing, "tail\n"...)) {
+		t.Errorf("object has %d bytes, want %d", len(obj.Data), len(existing)+5)
+	}
+	if fake.Uploads() != 0 {
+		t.Errorf("%d multipart uploads left behind", fake.Uploads())
+	}
+}
+
+func TestAppendConcurrentWrite(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "logs/app.log", []byte("line 1\n"), time.Now())
+
+	// Another writer replaces the object while the parts are uploaded
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Query().Has("partNumber") {
+			fake.PutObject("test-bucket", "logs/app.log", []byte("rotated\n"), time.Now())
+		}
+		fake.ServeHTTP(w, r)
+	})
+	client := newTestClient(t, handler, nil)
+
+	_, err := client.Append(context.Background(), "logs/app.log", strings.NewReader("line 2\n"))
+	if !errors.Is(err, ErrConcurrentWrite) {
+		t.Fatalf("Append() error = %v, want ErrConcurrentWrite", err)
+	}
+	if obj, _ := fake.Object("test-bucket", "logs/app.log"); string(obj.Data) != "rotated\n" {
+		t.Errorf("object holds %q, want the write of the other writer", obj.Data)
+	}
+	if fake.Uploads() != 0 {
+		t.Errorf("%d multipart uploads left behind", fake.Uploads())
+	}
+}
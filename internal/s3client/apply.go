@@ -0,0 +1,229 @@
+package s3client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+const (
+	deletionPlanVersion = 1
+
+	// Number of keys quoted in errors about a plan
+	errorExamples = 5
+)
+
+// ErrPlanDrift is returned by ApplyDeletionPlan when objects in the plan were modified
+// after it was made.
+var ErrPlanDrift = errors.New("bucket changed since the plan was made")
+
+// PlanDeleteOld lists the objects DeleteOldFiles would remove with opts, with the ETags
+// that ApplyDeletionPlan later uses to detect changes. Like a real run, it fails when
+// more objects than MaxDelete match.
+func (c *Client) PlanDeleteOld(ctx context.Context, opts DeleteOptions) (*models.DeletionPlan, error) {
+	now := time.Now()
+	cutoffDate := opts.cutoff(now)
+
+	objects, err := c.listFolders(ctx, opts.Folders)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.DeletionPlan{
+		Version:     deletionPlanVersion,
+		Operation:   "delete-old",
+		BucketName:  c.config.BucketName,
+		Folder:      storage.JoinFolders(opts.Folders),
+		DaysOld:     opts.DaysOld,
+		TagFilter:   opts.Tags,
+		UnusedFor:   formatUnusedFor(opts.UnusedFor),
+		DateFromKey: opts.dateFromKey(),
+		Regex:       storage.RegexString(opts.Regex),
+		Filter:      opts.Filter.String(),
+		CutoffDate:  utils.FormatTime(cutoffDate),
+		NewerThan:   storage.FormatBound(opts.Window.After),
+		CreatedAt:   utils.FormatTime(now),
+		Objects:     []models.PlanObject{},
+	}
+
+	var old []types.Object
+	for _, obj := range objects {
+		if obj.LastModified == nil {
+			continue
+		}
+		due, dated := opts.due(obj, cutoffDate)
+		if !dated {
+			plan.UndatedCount++
+			continue
+		}
+		if due {
+			old = append(old, obj)
+		}
+	}
+	objectKey := func(obj types.Object) string { return aws.ToString(obj.Key) }
+	old, plan.ProtectedCount = withoutProtected(c, old, objectKey)
+	old, plan.ExcludedCount = withoutKeys(old, objectKey, opts.excludes)
+	old, err = filterOld(ctx, c, old, objectKey, opts, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range old {
+		plan.Objects = append(plan.Objects, models.PlanObject{
+			Key:          aws.ToString(obj.Key),
+			Size:         aws.ToInt64(obj.Size),
+			ETag:         strings.Trim(aws.ToString(obj.ETag), `"`),
+			LastModified: obj.LastModified.Format(time.RFC3339),
+		})
+		plan.TotalSizeBytes += aws.ToInt64(obj.Size)
+	}
+	plan.TotalObjects = len(plan.Objects)
+	plan.TotalSizeHuman = utils.FormatBytes(plan.TotalSizeBytes)
+
+	if err := c.checkMaxDelete(plan.TotalObjects); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ApplyDeletionPlan deletes exactly the objects listed in plan. It refuses to run when
+// any of them was overwritten since planning, so that newer data is never deleted based
+// on a stale review. Objects that are already gone are skipped, and objects created
+// after planning are left alone.
+func (c *Client) ApplyDeletionPlan(ctx context.Context, plan *models.DeletionPlan) (*models.DeleteResult, error) {
+	if plan.BucketName != c.config.BucketName {
+		return nil, fmt.Errorf("plan is for bucket %s, not %s", plan.BucketName, c.config.BucketName)
+	}
+
+	var protected []string
+	for _, planned := range plan.Objects {
+		if c.isProtected(planned.Key) {
+			protected = append(protected, planned.Key)
+		}
+	}
+	if len(protected) > 0 {
+		return nil, fmt.Errorf("%w: plan deletes %d protected objects (%s), use --allow-protected to apply it anyway",
+			ErrProtected, len(protected), strings.Join(firstKeys(protected), ", "))
+	}
+
+	current, err := c.listFolders(ctx, storage.SplitFolders(plan.Folder))
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]types.Object, len(current))
+	for _, obj := range current {
+		existing[aws.ToString(obj.Key)] = obj
+	}
+
+	var changed []string
+	var toDelete []types.ObjectIdentifier
+	sizes := make(map[string]int64, len(plan.Objects))
+	for _, planned := range plan.Objects {
+		obj, ok := existing[planned.Key]
+		if !ok {
+			continue
+		}
+		if strings.Trim(aws.ToString(obj.ETag), `"`) != planned.ETag || aws.ToInt64(obj.Size) != planned.Size {
+			changed = append(changed, planned.Key)
+			continue
+		}
+		toDelete = append(toDelete, types.ObjectIdentifier{Key: aws.String(planned.Key)})
+		sizes[planned.Key] = planned.Size
+	}
+
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("%w: %d planned objects were modified (%s), create a new plan",
+			ErrPlanDrift, len(changed), strings.Join(firstKeys(changed), ", "))
+	}
+
+	if err := c.checkMaxDelete(len(toDelete)); err != nil {
+		return nil, err
+	}
+
+	deleted, err := c.removeObjects(ctx, toDelete, nil)
+
+	result := &models.DeleteResult{
+		BucketName:    c.config.BucketName,
+		Folder:        plan.Folder,
+		DaysOld:       plan.DaysOld,
+		DeletedFiles:  make([]string, 0, len(deleted)),
+		OperationTime: utils.FormatTime(time.Now()),
+		TagFilter:     plan.TagFilter,
+		UnusedFor:     plan.UnusedFor,
+		CutoffDate:    plan.CutoffDate,
+		TrashFolder:   c.TrashFolder(),
+	}
+	for _, key := range deleted {
+		result.DeletedFiles = append(result.DeletedFiles, key)
+		result.TotalSizeBytes += sizes[key]
+	}
+	result.DeletedCount = len(result.DeletedFiles)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+
+	if err != nil {
+		if ctx.Err() == nil {
+			return nil, err
+		}
+		result.Interrupted = true
+		result.Error = err.Error()
+		return result, err
+	}
+	return result, nil
+}
+
+// WriteDeletionPlan saves plan as indented JSON for review.
+func WriteDeletionPlan(path string, plan *models.DeletionPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// ReadDeletionPlan loads a plan written by WriteDeletionPlan.
+func ReadDeletionPlan(path string) (*models.DeletionPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var plan models.DeletionPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	if plan.Version != deletionPlanVersion {
+		return nil, fmt.Errorf("unsupported plan version %d in %s", plan.Version, path)
+	}
+	if plan.BucketName == "" {
+		return nil, fmt.Errorf("plan %s has no bucket name", path)
+	}
+	return &plan, nil
+}
+
+func firstKeys(keys []string) []string {
+	if len(keys) > errorExamples {
+		return keys[:errorExamples]
+	}
+	return keys
+}
+
+func folderPrefix(folder string) string {
+	if folder != "" && !strings.HasSuffix(folder, "/") {
+		return folder + "/"
+	}
+	return folder
+}
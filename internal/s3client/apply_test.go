@@ -0,0 +1,95 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDeletionPlanRoundTrip(t *testing.T) {
+	var mu sync.Mutex
+	listing := `<ListBucketResult>
+<Contents><Key>logs/old-1.log</Key><LastModified>2020-01-01T00:00:00Z</LastModified><ETag>"aaa"</ETag><Size>10</Size></Contents>
+<Contents><Key>logs/old-2.log</Key><LastModified>2020-01-02T00:00:00Z</LastModified><ETag>"bbb"</ETag><Size>20</Size></Contents>
+<Contents><Key>logs/new.log</Key><LastModified>2099-01-01T00:00:00Z</LastModified><ETag>"ccc"</ETag><Size>30</Size></Contents>
+</ListBucketResult>`
+	var deletedKeys []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, listing)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		for _, part := range strings.Split(string(body), "<Key>")[1:] {
+			deletedKeys = append(deletedKeys, part[:strings.Index(part, "</Key>")])
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	})
+	client := newTestClient(t, handler, nil)
+
+	plan, err := client.PlanDeleteOld(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30})
+	if err != nil {
+		t.Fatalf("PlanDeleteOld() error = %v", err)
+	}
+	if plan.TotalObjects != 2 || plan.TotalSizeBytes != 30 || plan.Objects[0].ETag != "aaa" {
+		t.Fatalf("plan = %+v, want the two old objects with their ETags", plan)
+	}
+	if len(deletedKeys) != 0 {
+		t.Fatalf("planning deleted %v", deletedKeys)
+	}
+
+	tempDir, err := os.MkdirTemp("", "deletion-plan-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "plan.json")
+	if err := WriteDeletionPlan(path, plan); err != nil {
+		t.Fatalf("WriteDeletionPlan() error = %v", err)
+	}
+	plan, err = ReadDeletionPlan(path)
+	if err != nil {
+		t.Fatalf("ReadDeletionPlan() error = %v", err)
+	}
+
+	// old-2 was overwritten after planning
+	mu.Lock()
+	listing = strings.Replace(listing, `"bbb"`, `"changed"`, 1)
+	mu.Unlock()
+	if _, err := client.ApplyDeletionPlan(context.Background(), plan); !errors.Is(err, ErrPlanDrift) {
+		t.Fatalf("ApplyDeletionPlan() error = %v, want ErrPlanDrift", err)
+	}
+	if len(deletedKeys) != 0 {
+		t.Fatalf("a drifted plan deleted %v", deletedKeys)
+	}
+
+	// old-2 is gone instead: only old-1 is left to delete
+	mu.Lock()
+	listing = strings.Replace(listing, `<Contents><Key>logs/old-2.log</Key>`, `<Contents><Key>logs/other.log</Key>`, 1)
+	mu.Unlock()
+	result, err := client.ApplyDeletionPlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyDeletionPlan() error = %v", err)
+	}
+	if len(deletedKeys) != 1 || deletedKeys[0] != "logs/old-1.log" {
+		t.Errorf("deleted keys = %v, want only [logs/old-1.log]", deletedKeys)
+	}
+	if result.DeletedCount != 1 || result.TotalSizeBytes != 10 {
+		t.Errorf("result = %+v, want 1 object and 10 bytes", result)
+	}
+
+	plan.BucketName = "other-bucket"
+	if _, err := client.ApplyDeletionPlan(context.Background(), plan); err == nil {
+		t.Errorf("ApplyDeletionPlan() should refuse a plan for another bucket")
+	}
+}
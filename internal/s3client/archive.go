@@ -0,0 +1,272 @@
+package s3client
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Ranged reads of an archive fetch at least this much, so that streaming a member does
+// not cost a request per small read of the decompressor
+const archiveReadBlock = 4 * 1024 * 1024
+
+// rangeReader reads an object with ranged GETs, keeping the last fetched block. All
+// ranges are requested for the ETag seen when it was opened, so an archive replaced in
+// the meantime fails instead of mixing two versions.
+type rangeReader struct {
+	c    *Client
+	ctx  context.Context
+	key  string
+	etag *string
+	size int64
+
+	mu       sync.Mutex
+	block    []byte
+	blockOff int64
+	fetched  int64
+	requests int
+}
+
+func (c *Client) newRangeReader(ctx context.Context, key string) (*rangeReader, error) {
+	head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	return &rangeReader{c: c, ctx: ctx, key: key, etag: head.ETag, size: aws.ToInt64(head.ContentLength)}, nil
+}
+
+func (r *rangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	end := min(off+int64(len(p)), r.size)
+	if off < r.blockOff || end > r.blockOff+int64(len(r.block)) {
+		if err := r.fetch(off, max(end, min(off+archiveReadBlock, r.size))); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.block[off-r.blockOff:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// fetch replaces the block with the bytes from start up to end.
+func (r *rangeReader) fetch(start, end int64) error {
+	resp, err := r.c.s3Client.GetObject(r.ctx, &s3.GetObjectInput{
+		Bucket:  aws.String(r.c.config.BucketName),
+		Key:     aws.String(r.key),
+		Range:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1)),
+		IfMatch: r.etag,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", r.key, err)
+	}
+	defer resp.Body.Close()
+
+	block := make([]byte, end-start)
+	if _, err := io.ReadFull(resp.Body, block); err != nil {
+		return fmt.Errorf("failed to read %s: %w", r.key, err)
+	}
+	r.block, r.blockOff = block, start
+	r.fetched += end - start
+	r.requests++
+	return nil
+}
+
+// openArchive reads the central directory of the zip archive at key, without
+// downloading the members.
+func (c *Client) openArchive(ctx context.Context, key string) (*zip.Reader, *rangeReader, error) {
+	r, err := c.newRangeReader(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	archive, err := zip.NewReader(r, r.size)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%s is not a readable zip archive: %w", key, err)
+	}
+	return archive, r, nil
+}
+
+// ListArchive returns the members of the zip archive at key. Only the central directory
+// at the end of the archive is fetched.
+func (c *Client) ListArchive(ctx context.Context, key string) (*models.ArchiveListResult, error) {
+	archive, r, err := c.openArchive(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ArchiveListResult{
+		BucketName:       c.config.BucketName,
+		Key:              key,
+		Members:          make([]models.ArchiveMember, 0, len(archive.File)),
+		ArchiveSizeBytes: r.size,
+		ArchiveSizeHuman: utils.FormatBytes(r.size),
+		OperationTime:    utils.FormatTime(time.Now()),
+	}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		member := newArchiveMember(file)
+		result.Members = append(result.Members, member)
+		result.TotalSizeBytes += member.Size
+		result.CompressedSizeBytes += member.CompressedSize
+	}
+
+	result.TotalFiles = len(result.Members)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.CompressedSizeHuman = utils.FormatBytes(result.CompressedSizeBytes)
+	result.FetchedBytes = r.fetched
+	result.FetchedHuman = utils.FormatBytes(r.fetched)
+	result.Requests = r.requests
+	return result, nil
+}
+
+// ExtractArchive fetches the members of the zip archive at key whose names match one of
+// members, exactly or as a glob, and writes them below destination with their paths in
+// the archive. Only the central directory and the matching members are downloaded.
+func (c *Client) ExtractArchive(ctx context.Context, key string, members []string, destination string) (*models.ArchiveExtractResult, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("at least one member is required")
+	}
+	for _, member := range members {
+		if _, err := path.Match(member, ""); err != nil {
+			return nil, fmt.Errorf("invalid member pattern %q: %w", member, err)
+		}
+	}
+	startTime := time.Now()
+
+	archive, r, err := c.openArchive(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ArchiveExtractResult{
+		BucketName:       c.config.BucketName,
+		Key:              key,
+		Destination:      destination,
+		Members:          []models.ArchiveMember{},
+		ArchiveSizeBytes: r.size,
+		ArchiveSizeHuman: utils.FormatBytes(r.size),
+		OperationTime:    utils.FormatTime(startTime),
+	}
+	var matched []*zip.File
+	var need int64
+	for _, file := range archive.File {
+		if !file.FileInfo().IsDir() && matchesMember(file.Name, members) {
+			matched = append(matched, file)
+			need += int64(file.UncompressedSize64)
+		}
+	}
+	if err := utils.CheckFreeSpace(destination, need); err != nil {
+		return nil, err
+	}
+
+	for _, file := range matched {
+		localPath, err := extractMember(file, destination)
+		if err != nil {
+			return nil, err
+		}
+		member := newArchiveMember(file)
+		member.LocalPath = localPath
+		result.Members = append(result.Members, member)
+		result.TotalSizeBytes += member.Size
+	}
+	if len(result.Members) == 0 {
+		return nil, fmt.Errorf("no member of %s matches %v", key, members)
+	}
+
+	result.TotalFiles = len(result.Members)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.FetchedBytes = r.fetched
+	result.FetchedHuman = utils.FormatBytes(r.fetched)
+	result.Requests = r.requests
+	result.Duration = time.Since(startTime).Truncate(time.Millisecond).String()
+	return result, nil
+}
+
+func matchesMember(name string, members []string) bool {
+	for _, member := range members {
+		if matched, _ := path.Match(member, name); matched || member == name {
+			return true
+		}
+	}
+	return false
+}
+
+func newArchiveMember(file *zip.File) models.ArchiveMember {
+	member := models.ArchiveMember{
+		Name:           file.Name,
+		Size:           int64(file.UncompressedSize64),
+		SizeHuman:      utils.FormatBytes(int64(file.UncompressedSize64)),
+		CompressedSize: int64(file.CompressedSize64),
+	}
+	if !file.Modified.IsZero() {
+		member.Modified = utils.FormatTime(file.Modified)
+	}
+	return member
+}
+
+// extractMember writes file below destination. Names that would leave destination are
+// refused. The content is written to a temporary file that is renamed once the CRC of
+// the member was verified.
+func extractMember(file *zip.File, destination string) (string, error) {
+	name := filepath.FromSlash(file.Name)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("refusing to extract %q outside of the destination", file.Name)
+	}
+	localPath := filepath.Join(destination, name)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", file.Name, err)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", localPath, err)
+	}
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), localPath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to extract %s: %w", file.Name, err)
+	}
+	return localPath, nil
+}
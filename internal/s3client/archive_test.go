@@ -0,0 +1,121 @@
+package s3client
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func newTestArchive(t *testing.T, members map[string][]byte, order []string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range order {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(members[name])
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractArchive(t *testing.T) {
+	// Random data does not compress, so the archive is larger than a read block
+	large := make([]byte, 3*archiveReadBlock)
+	rand.New(rand.NewSource(1)).Read(large)
+	members := map[string][]byte{
+		"db/schema.sql": []byte("CREATE TABLE users (id int);\n"),
+		"db/seed.sql":   []byte("INSERT INTO users VALUES (1);\n"),
+		"media/big.bin": large,
+	}
+	archive := newTestArchive(t, members, []string{"db/schema.sql", "db/seed.sql", "media/big.bin"})
+
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "backups/site.zip", archive, time.Now())
+	client := newTestClient(t, fake, nil)
+
+	dest := t.TempDir()
+	result, err := client.ExtractArchive(context.Background(), "backups/site.zip", []string{"db/schema.sql"}, dest)
+	if err != nil {
+		t.Fatalf("ExtractArchive() error = %v", err)
+	}
+	if result.TotalFiles != 1 || result.Members[0].Name != "db/schema.sql" {
+		t.Fatalf("result = %+v, want db/schema.sql", result)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "db", "schema.sql"))
+	if err != nil || !bytes.Equal(data, members["db/schema.sql"]) {
+		t.Errorf("extracted content = %q, %v", data, err)
+	}
+	if result.FetchedBytes >= result.ArchiveSizeBytes {
+		t.Errorf("fetched %d of %d bytes, want only part of the archive", result.FetchedBytes, result.ArchiveSizeBytes)
+	}
+
+	result, err = client.ExtractArchive(context.Background(), "backups/site.zip", []string{"db/*.sql"}, dest)
+	if err != nil || result.TotalFiles != 2 {
+		t.Errorf("ExtractArchive(db/*.sql) = %+v, %v, want both SQL files", result, err)
+	}
+
+	if _, err := client.ExtractArchive(context.Background(), "backups/site.zip", []string{"missing.txt"}, dest); err == nil {
+		t.Errorf("ExtractArchive() of a missing member should return error")
+	}
+}
+
+func TestExtractArchiveRejectsUnsafeNames(t *testing.T) {
+	members := map[string][]byte{"../escape.txt": []byte("x")}
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "evil.zip", newTestArchive(t, members, []string{"../escape.txt"}), time.Now())
+	client := newTestClient(t, fake, nil)
+
+	dest := t.TempDir()
+	if _, err := client.ExtractArchive(context.Background(), "evil.zip", []string{"../escape.txt"}, dest); err == nil {
+		t.Errorf("ExtractArchive() should refuse members outside of the destination")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "escape.txt")); !os.IsNotExist(err) {
+		t.Errorf("escape.txt should not be written, stat error = %v", err)
+	}
+}
+
+func TestListArchive(t *testing.T) {
+	large := make([]byte, 3*archiveReadBlock)
+	rand.New(rand.NewSource(1)).Read(large)
+	members := map[string][]byte{
+		"media/big.bin": large,
+		"db/schema.sql": []byte("CREATE TABLE users (id int);\n"),
+	}
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "backups/site.zip", newTestArchive(t, members, []string{"media/big.bin", "db/schema.sql"}), time.Now())
+	client := newTestClient(t, fake, nil)
+
+	result, err := client.ListArchive(context.Background(), "backups/site.zip")
+	if err != nil {
+		t.Fatalf("ListArchive() error = %v", err)
+	}
+	if result.TotalFiles != 2 || result.Members[0].Name != "media/big.bin" || result.Members[1].Name != "db/schema.sql" {
+		t.Fatalf("members = %+v, want media/big.bin and db/schema.sql", result.Members)
+	}
+	if result.TotalSizeBytes != int64(len(large))+29 {
+		t.Errorf("TotalSizeBytes = %d, want %d", result.TotalSizeBytes, len(large)+29)
+	}
+	if result.Requests != 1 || result.FetchedBytes > 64*1024 {
+		t.Errorf("fetched %d bytes in %d requests, want only the central directory", result.FetchedBytes, result.Requests)
+	}
+
+	fake.PutObject("test-bucket", "notes.txt", []byte("not an archive"), time.Now())
+	if _, err := client.ListArchive(context.Background(), "notes.txt"); err == nil {
+		t.Errorf("ListArchive() of a plain object should return error")
+	}
+}
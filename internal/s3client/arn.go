@@ -0,0 +1,66 @@
+package s3client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Access points are addressed by ARN wherever a bucket name is expected, e.g.
+// arn:aws:s3:eu-west-1:123456789012:accesspoint/backups, or without a region for a
+// multi-region access point.
+const accessPointResource = "accesspoint/"
+
+// isAccessPoint reports whether bucket is an access point ARN rather than a bucket name.
+func isAccessPoint(bucket string) bool {
+	parsed, err := arn.Parse(bucket)
+	return err == nil && strings.HasPrefix(parsed.Resource, accessPointResource)
+}
+
+// validateBucket rejects ARNs of anything but an access point.
+func validateBucket(bucket string) error {
+	if arn.IsARN(bucket) && !isAccessPoint(bucket) {
+		return fmt.Errorf("unsupported bucket ARN %q, only access point ARNs can be used as a bucket", bucket)
+	}
+	return nil
+}
+
+// accessPointRegion returns the region in an access point ARN, empty for a multi-region
+// access point or a plain bucket name.
+func accessPointRegion(bucket string) string {
+	parsed, err := arn.Parse(bucket)
+	if err != nil {
+		return ""
+	}
+	return parsed.Region
+}
+
+// SplitBucketPath splits "bucket/prefix" into the bucket and the prefix. The bucket may
+// be an access point ARN, whose resource contains a slash itself.
+func SplitBucketPath(target string) (bucket, prefix string) {
+	if arn.IsARN(target) {
+		i := strings.Index(target, accessPointResource)
+		if i < 0 {
+			return target, ""
+		}
+		name := i + len(accessPointResource)
+		end := strings.IndexByte(target[name:], '/')
+		if end < 0 {
+			return target, ""
+		}
+		return target[:name+end], target[name+end+1:]
+	}
+	bucket, prefix, _ = strings.Cut(target, "/")
+	return bucket, prefix
+}
+
+// copySource returns the URL-encoded CopySource of key in bucket. Objects behind an
+// access point are named <access point ARN>/object/<key>.
+func copySource(bucket, key string) string {
+	if isAccessPoint(bucket) {
+		return url.PathEscape(bucket + "/object/" + key)
+	}
+	return url.PathEscape(bucket + "/" + key)
+}
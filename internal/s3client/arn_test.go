@@ -0,0 +1,59 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"s3manager/config"
+	"testing"
+)
+
+const testAccessPoint = "arn:aws:s3:eu-west-1:123456789012:accesspoint/backups"
+
+func TestSplitBucketPath(t *testing.T) {
+	tests := []struct {
+		target, bucket, prefix string
+	}{
+		{"my-bucket", "my-bucket", ""},
+		{"my-bucket/replica/", "my-bucket", "replica/"},
+		{testAccessPoint, testAccessPoint, ""},
+		{testAccessPoint + "/replica/", testAccessPoint, "replica/"},
+		{"arn:aws:s3::123456789012:accesspoint/mfzwi23gnjvgw.mrap/daily", "arn:aws:s3::123456789012:accesspoint/mfzwi23gnjvgw.mrap", "daily"},
+	}
+
+	for _, tt := range tests {
+		bucket, prefix := SplitBucketPath(tt.target)
+		if bucket != tt.bucket || prefix != tt.prefix {
+			t.Errorf("SplitBucketPath(%q) = %q, %q, want %q, %q", tt.target, bucket, prefix, tt.bucket, tt.prefix)
+		}
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	if got := copySource("my-bucket", "a b.txt"); got != "my-bucket%2Fa%20b.txt" {
+		t.Errorf("copySource() = %q", got)
+	}
+	want := "arn:aws:s3:eu-west-1:123456789012:accesspoint%2Fbackups%2Fobject%2Fdb.sql"
+	if got := copySource(testAccessPoint, "db.sql"); got != want {
+		t.Errorf("copySource() = %q, want %q", got, want)
+	}
+}
+
+func TestNewWithAccessPoint(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		cfg.BucketName = testAccessPoint
+		cfg.Region = ""
+	})
+
+	if client.awsConfig.Region != "eu-west-1" {
+		t.Errorf("region = %q, want the access point's region", client.awsConfig.Region)
+	}
+	if _, err := client.GetBucketWebsite(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetBucketWebsite() error = %v, want ErrUnsupported", err)
+	}
+
+	_, err := New(&config.Config{BucketName: "arn:aws:iam::123456789012:role/backup", Region: "us-east-1"})
+	if err == nil {
+		t.Errorf("New() with a non access point ARN should return error")
+	}
+}
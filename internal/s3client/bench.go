@@ -0,0 +1,220 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Benchmark objects are written next to the doctor probes
+const benchPrefix = ".s3manager/bench/"
+
+type BenchOptions struct {
+	// Size of the synthetic object that is uploaded and downloaded again.
+	Size int64
+	// PartSize and Parallel configure the multipart transfers like the upload settings.
+	PartSize int64
+	Parallel int
+	// Prefix is where the benchmark object is written, for credentials limited to a prefix.
+	Prefix string
+}
+
+// Bench uploads a synthetic object with the given part size and concurrency, downloads it
+// again and reports the throughput of both transfers and the latency of their part
+// requests. The object is deleted afterwards.
+func (c *Client) Bench(ctx context.Context, opts BenchOptions) (*models.BenchResult, error) {
+	if opts.Size <= 0 {
+		return nil, fmt.Errorf("size must be positive")
+	}
+	if opts.PartSize < manager.MinUploadPartSize {
+		return nil, fmt.Errorf("part size must be at least %s", utils.FormatBytes(manager.MinUploadPartSize))
+	}
+	if opts.Parallel < 1 {
+		return nil, fmt.Errorf("parallel must be at least 1")
+	}
+
+	startTime := time.Now()
+	key := fmt.Sprintf("%s%s%s-%d", opts.Prefix, benchPrefix, strings.ReplaceAll(lockOwner(), ":", "-"), startTime.UnixNano())
+
+	result := &models.BenchResult{
+		BucketName:    c.config.BucketName,
+		Key:           key,
+		SizeBytes:     opts.Size,
+		SizeHuman:     utils.FormatBytes(opts.Size),
+		PartSizeBytes: opts.PartSize,
+		PartSizeHuman: utils.FormatBytes(opts.PartSize),
+		Parallel:      opts.Parallel,
+		OperationTime: utils.FormatTime(startTime),
+	}
+
+	uploadLatencies := &latencyRecorder{operations: []string{"PutObject", "UploadPart"}}
+	uploader := c.newUploader()
+	uploader.PartSize = opts.PartSize
+	uploader.Concurrency = opts.Parallel
+	uploader.ClientOptions = append(uploader.ClientOptions, uploadLatencies.register)
+
+	uploadStart := time.Now()
+	err := c.upload(ctx, uploader, &s3.PutObjectInput{
+		Bucket:        aws.String(c.config.BucketName),
+		Key:           aws.String(key),
+		Body:          newSyntheticReader(opts.Size),
+		ContentLength: aws.Int64(opts.Size),
+		ContentType:   aws.String("application/octet-stream"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload benchmark object: %w", err)
+	}
+	defer c.removeBenchObject(key)
+	result.Upload = uploadLatencies.phase(opts.Size, time.Since(uploadStart))
+
+	downloadLatencies := &latencyRecorder{operations: []string{"GetObject"}}
+	downloader := manager.NewDownloader(c.s3Client, func(d *manager.Downloader) {
+		d.PartSize = opts.PartSize
+		d.Concurrency = opts.Parallel
+		d.ClientOptions = append(d.ClientOptions, downloadLatencies.register)
+	})
+
+	downloadStart := time.Now()
+	n, err := downloader.Download(ctx, discardWriterAt{}, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download benchmark object: %w", err)
+	}
+	if n != opts.Size {
+		return nil, fmt.Errorf("downloaded %d bytes of the benchmark object, want %d", n, opts.Size)
+	}
+	result.Download = downloadLatencies.phase(opts.Size, time.Since(downloadStart))
+
+	return result, nil
+}
+
+func (c *Client) removeBenchObject(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		slog.Warn("Failed to delete benchmark object", "key", key, "error", err)
+	}
+}
+
+// latencyRecorder collects the duration of every call of the given operations, retries
+// included, made by a client it is registered with.
+type latencyRecorder struct {
+	operations []string
+
+	mu        sync.Mutex
+	latencies []time.Duration
+}
+
+func (r *latencyRecorder) register(o *s3.Options) {
+	o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("BenchLatency", func(
+			ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+		) (middleware.InitializeOutput, middleware.Metadata, error) {
+			start := time.Now()
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if err == nil && r.records(middleware.GetOperationName(ctx)) {
+				r.mu.Lock()
+				r.latencies = append(r.latencies, time.Since(start))
+				r.mu.Unlock()
+			}
+			return out, metadata, err
+		}), middleware.Before)
+	})
+}
+
+func (r *latencyRecorder) records(operation string) bool {
+	for _, op := range r.operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// phase summarizes a transfer of size bytes that took duration.
+func (r *latencyRecorder) phase(size int64, duration time.Duration) models.BenchPhase {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	throughput := utils.BytesPerSecond(size, duration)
+	return models.BenchPhase{
+		Duration:        duration.String(),
+		ThroughputBytes: throughput,
+		ThroughputHuman: utils.FormatSpeed(throughput),
+		Requests:        len(r.latencies),
+		LatencyP50:      percentile(r.latencies, 50).String(),
+		LatencyP90:      percentile(r.latencies, 90).String(),
+		LatencyP99:      percentile(r.latencies, 99).String(),
+		LatencyMax:      percentile(r.latencies, 100).String(),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// syntheticReader produces size bytes of random data by repeating a random block, so
+// large benchmarks do not need the whole object in memory.
+type syntheticReader struct {
+	block     []byte
+	offset    int
+	remaining int64
+}
+
+func newSyntheticReader(size int64) *syntheticReader {
+	block := make([]byte, 1024*1024+7)
+	rand.New(rand.NewSource(time.Now().UnixNano())).Read(block)
+	return &syntheticReader{block: block, remaining: size}
+}
+
+func (r *syntheticReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n := 0
+	for n < len(p) {
+		copied := copy(p[n:], r.block[r.offset:])
+		n += copied
+		r.offset = (r.offset + copied) % len(r.block)
+	}
+	r.remaining -= int64(n)
+	return n, nil
+}
+
+// discardWriterAt is the download target of the benchmark, nothing is written to disk.
+type discardWriterAt struct{}
+
+func (discardWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return len(p), nil
+}
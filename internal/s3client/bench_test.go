@@ -0,0 +1,64 @@
+package s3client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBench(t *testing.T) {
+	cfg, server := testBucket(t)
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	result, err := client.Bench(context.Background(), BenchOptions{Size: 12 * 1024 * 1024, PartSize: 5 * 1024 * 1024, Parallel: 2})
+	if err != nil {
+		t.Fatalf("Bench() error = %v", err)
+	}
+
+	if !strings.HasPrefix(result.Key, benchPrefix) {
+		t.Errorf("Key = %s, want it under %s", result.Key, benchPrefix)
+	}
+	// 12MB in 5MB parts
+	if result.Upload.Requests != 3 || result.Download.Requests != 3 {
+		t.Errorf("Requests = %d uploaded, %d downloaded, want 3 parts each", result.Upload.Requests, result.Download.Requests)
+	}
+	if result.Upload.ThroughputBytes <= 0 || result.Download.LatencyMax == "0s" {
+		t.Errorf("Bench() = %+v, want throughput and latencies", result)
+	}
+	if server != nil && len(server.Keys(cfg.BucketName)) != 0 {
+		t.Errorf("Keys = %v, the benchmark object should be deleted", server.Keys(cfg.BucketName))
+	}
+}
+
+func TestBenchRejectsSmallParts(t *testing.T) {
+	cfg, _ := testBucket(t)
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.Bench(context.Background(), BenchOptions{Size: 1024, PartSize: 1024, Parallel: 1}); err == nil {
+		t.Errorf("Bench() with 1KB parts should return error")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for _, tt := range []struct {
+		p    float64
+		want time.Duration
+	}{{50, 5}, {90, 9}, {99, 10}, {100, 10}} {
+		if got := percentile(latencies, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,50 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetBucketInfoWithoutOptionalPermissions(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("list-type") {
+			fmt.Fprint(w, `<ListBucketResult>
+				<Contents><Key>a.txt</Key><LastModified>2024-03-01T00:00:00Z</LastModified><Size>10</Size></Contents>
+			</ListBucketResult>`)
+			return
+		}
+		// GetBucketLocation and ListBuckets are denied
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+	})
+	client := newTestClient(t, handler, nil)
+
+	info, err := client.GetBucketInfo(context.Background())
+	if err != nil {
+		t.Fatalf("GetBucketInfo() error = %v", err)
+	}
+	if info.Region != "unknown" || info.CreationDate != nil {
+		t.Errorf("region = %q, creation date = %v, want unknown and nil", info.Region, info.CreationDate)
+	}
+	if info.ObjectCount != 1 || info.TotalSizeBytes != 10 {
+		t.Errorf("info = %+v, want the listed object counted", info)
+	}
+	if len(info.Warnings) != 2 {
+		t.Errorf("warnings = %v, want one per failed lookup", info.Warnings)
+	}
+}
+
+func TestGetBucketInfoListingDenied(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+	})
+	client := newTestClient(t, handler, nil)
+
+	// Without ListBucket there is nothing to report
+	if _, err := client.GetBucketInfo(context.Background()); err == nil {
+		t.Errorf("GetBucketInfo() should fail when listing is denied")
+	}
+}
@@ -0,0 +1,78 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// GetBucketLogging returns where the server access logs of the bucket are delivered.
+func (c *Client) GetBucketLogging(ctx context.Context) (*models.BucketLogging, error) {
+	if err := c.checkSupported(featureBucketLogging); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.s3Client.GetBucketLogging(ctx, &s3.GetBucketLoggingInput{
+		Bucket: aws.String(c.config.BucketName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket logging: %w", err)
+	}
+
+	result := &models.BucketLogging{
+		BucketName:    c.config.BucketName,
+		OperationTime: utils.FormatTime(time.Now()),
+	}
+	if logging := resp.LoggingEnabled; logging != nil {
+		result.Enabled = true
+		result.TargetBucket = aws.ToString(logging.TargetBucket)
+		result.TargetPrefix = aws.ToString(logging.TargetPrefix)
+	}
+	return result, nil
+}
+
+// PutBucketLogging delivers the server access logs of the bucket to targetPrefix in
+// targetBucket, or disables logging when targetBucket is empty. The target bucket has to
+// be in the same region and allow the logging service to write to it.
+func (c *Client) PutBucketLogging(ctx context.Context, targetBucket, targetPrefix string) (*models.BucketLogging, error) {
+	if err := c.checkSupported(featureBucketLogging); err != nil {
+		return nil, err
+	}
+	if targetBucket == "" && targetPrefix != "" {
+		return nil, fmt.Errorf("a target prefix needs a target bucket")
+	}
+	// Logs written to the logged bucket itself are logged again, endlessly
+	if targetBucket == c.config.BucketName && targetPrefix == "" {
+		return nil, fmt.Errorf("logging into the bucket itself needs a target prefix to keep the logs apart")
+	}
+
+	status := &types.BucketLoggingStatus{}
+	if targetBucket != "" {
+		status.LoggingEnabled = &types.LoggingEnabled{
+			TargetBucket: aws.String(targetBucket),
+			TargetPrefix: aws.String(targetPrefix),
+		}
+	}
+	_, err := c.s3Client.PutBucketLogging(ctx, &s3.PutBucketLoggingInput{
+		Bucket:              aws.String(c.config.BucketName),
+		BucketLoggingStatus: status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket logging: %w", err)
+	}
+
+	return &models.BucketLogging{
+		BucketName:    c.config.BucketName,
+		Enabled:       targetBucket != "",
+		TargetBucket:  targetBucket,
+		TargetPrefix:  targetPrefix,
+		OperationTime: utils.FormatTime(time.Now()),
+	}, nil
+}
@@ -0,0 +1,55 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"testing"
+)
+
+func TestBucketLogging(t *testing.T) {
+	fake := s3fake.New("test-bucket", "logs")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	logging, err := client.GetBucketLogging(ctx)
+	if err != nil {
+		t.Fatalf("GetBucketLogging() error = %v", err)
+	}
+	if logging.Enabled {
+		t.Errorf("GetBucketLogging() of a new bucket = %+v, want disabled", logging)
+	}
+
+	if _, err := client.PutBucketLogging(ctx, "logs", "test-bucket/"); err != nil {
+		t.Fatalf("PutBucketLogging() error = %v", err)
+	}
+	logging, err = client.GetBucketLogging(ctx)
+	if err != nil {
+		t.Fatalf("GetBucketLogging() error = %v", err)
+	}
+	if !logging.Enabled || logging.TargetBucket != "logs" || logging.TargetPrefix != "test-bucket/" {
+		t.Errorf("GetBucketLogging() = %+v, want logs/test-bucket/", logging)
+	}
+
+	if _, err := client.PutBucketLogging(ctx, "", ""); err != nil {
+		t.Fatalf("PutBucketLogging() disabling error = %v", err)
+	}
+	if logging, _ = client.GetBucketLogging(ctx); logging.Enabled {
+		t.Errorf("GetBucketLogging() after disabling = %+v", logging)
+	}
+
+	if _, err := client.PutBucketLogging(ctx, "test-bucket", ""); err == nil {
+		t.Error("PutBucketLogging() into the bucket itself without a prefix succeeded")
+	}
+}
+
+func TestBucketLoggingUnsupported(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) { cfg.Provider = "minio" })
+	if _, err := client.GetBucketLogging(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetBucketLogging() error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,145 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Metrics configuration IDs are up to 64 letters, digits, dots, dashes and underscores
+var metricsIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// GetBucketMetrics returns the request metrics configurations of the bucket. Each one
+// publishes CloudWatch request metrics for the objects matching its filter.
+func (c *Client) GetBucketMetrics(ctx context.Context) (*models.BucketMetrics, error) {
+	if err := c.checkSupported(featureRequestMetrics); err != nil {
+		return nil, err
+	}
+
+	configurations := []models.MetricsConfiguration{}
+	input := &s3.ListBucketMetricsConfigurationsInput{Bucket: aws.String(c.config.BucketName)}
+	for {
+		resp, err := c.s3Client.ListBucketMetricsConfigurations(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list metrics configurations: %w", err)
+		}
+		for _, configuration := range resp.MetricsConfigurationList {
+			configurations = append(configurations, metricsConfiguration(configuration))
+		}
+		if !aws.ToBool(resp.IsTruncated) || resp.NextContinuationToken == nil {
+			break
+		}
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+
+	return &models.BucketMetrics{
+		BucketName:     c.config.BucketName,
+		Configurations: configurations,
+		OperationTime:  utils.FormatTime(time.Now()),
+	}, nil
+}
+
+// PutBucketMetrics creates or replaces the metrics configuration id, which covers the
+// objects below prefix carrying all of tags, or the whole bucket without either. It
+// returns all configurations of the bucket afterwards.
+func (c *Client) PutBucketMetrics(ctx context.Context, id, prefix string, tags map[string]string) (*models.BucketMetrics, error) {
+	if err := c.checkSupported(featureRequestMetrics); err != nil {
+		return nil, err
+	}
+	if !metricsIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid metrics configuration ID %q, expected up to 64 letters, digits, '.', '-' or '_'", id)
+	}
+
+	_, err := c.s3Client.PutBucketMetricsConfiguration(ctx, &s3.PutBucketMetricsConfigurationInput{
+		Bucket: aws.String(c.config.BucketName),
+		Id:     aws.String(id),
+		MetricsConfiguration: &types.MetricsConfiguration{
+			Id:     aws.String(id),
+			Filter: metricsFilter(prefix, tags),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put metrics configuration %s: %w", id, err)
+	}
+	return c.GetBucketMetrics(ctx)
+}
+
+// DeleteBucketMetrics removes the metrics configuration id and returns the remaining
+// configurations of the bucket.
+func (c *Client) DeleteBucketMetrics(ctx context.Context, id string) (*models.BucketMetrics, error) {
+	if err := c.checkSupported(featureRequestMetrics); err != nil {
+		return nil, err
+	}
+
+	_, err := c.s3Client.DeleteBucketMetricsConfiguration(ctx, &s3.DeleteBucketMetricsConfigurationInput{
+		Bucket: aws.String(c.config.BucketName),
+		Id:     aws.String(id),
+	})
+	if err != nil {
+		if hasErrorCode(err, "NoSuchConfiguration") {
+			return nil, fmt.Errorf("metrics configuration %s does not exist", id)
+		}
+		return nil, fmt.Errorf("failed to delete metrics configuration %s: %w", id, err)
+	}
+	return c.GetBucketMetrics(ctx)
+}
+
+// metricsFilter returns the narrowest filter type S3 accepts for prefix and tags, nil
+// for the whole bucket.
+func metricsFilter(prefix string, tags map[string]string) types.MetricsFilter {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tagSet := make([]types.Tag, 0, len(keys))
+	for _, key := range keys {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	switch {
+	case prefix == "" && len(tagSet) == 0:
+		return nil
+	case len(tagSet) == 0:
+		return &types.MetricsFilterMemberPrefix{Value: prefix}
+	case prefix == "" && len(tagSet) == 1:
+		return &types.MetricsFilterMemberTag{Value: tagSet[0]}
+	}
+	and := types.MetricsAndOperator{Tags: tagSet}
+	if prefix != "" {
+		and.Prefix = aws.String(prefix)
+	}
+	return &types.MetricsFilterMemberAnd{Value: and}
+}
+
+func metricsConfiguration(configuration types.MetricsConfiguration) models.MetricsConfiguration {
+	result := models.MetricsConfiguration{ID: aws.ToString(configuration.Id)}
+	addTag := func(tag types.Tag) {
+		if result.Tags == nil {
+			result.Tags = make(map[string]string)
+		}
+		result.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	switch filter := configuration.Filter.(type) {
+	case *types.MetricsFilterMemberPrefix:
+		result.Prefix = filter.Value
+	case *types.MetricsFilterMemberTag:
+		addTag(filter.Value)
+	case *types.MetricsFilterMemberAnd:
+		result.Prefix = aws.ToString(filter.Value.Prefix)
+		for _, tag := range filter.Value.Tags {
+			addTag(tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"s3manager/config"
+	"s3manager/internal/models"
+	"s3manager/internal/s3fake"
+	"testing"
+)
+
+func TestBucketMetrics(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	metrics, err := client.GetBucketMetrics(ctx)
+	if err != nil {
+		t.Fatalf("GetBucketMetrics() error = %v", err)
+	}
+	if len(metrics.Configurations) != 0 {
+		t.Errorf("Configurations of a new bucket = %v, want none", metrics.Configurations)
+	}
+
+	put := func(id, prefix string, tags map[string]string) {
+		t.Helper()
+		if _, err := client.PutBucketMetrics(ctx, id, prefix, tags); err != nil {
+			t.Fatalf("PutBucketMetrics(%s) error = %v", id, err)
+		}
+	}
+	put("EntireBucket", "", nil)
+	put("uploads", "uploads/", nil)
+	put("team", "", map[string]string{"team": "backend"})
+	put("hot", "cache/", map[string]string{"tier": "hot", "team": "web"})
+
+	metrics, err = client.DeleteBucketMetrics(ctx, "EntireBucket")
+	if err != nil {
+		t.Fatalf("DeleteBucketMetrics() error = %v", err)
+	}
+	want := []models.MetricsConfiguration{
+		{ID: "hot", Prefix: "cache/", Tags: map[string]string{"tier": "hot", "team": "web"}},
+		{ID: "team", Tags: map[string]string{"team": "backend"}},
+		{ID: "uploads", Prefix: "uploads/"},
+	}
+	if !reflect.DeepEqual(metrics.Configurations, want) {
+		t.Errorf("Configurations = %+v, want %+v", metrics.Configurations, want)
+	}
+
+	if _, err := client.PutBucketMetrics(ctx, "has space", "", nil); err == nil {
+		t.Error("PutBucketMetrics() with an invalid ID succeeded")
+	}
+}
+
+func TestBucketMetricsUnsupported(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) { cfg.Provider = "wasabi" })
+	if _, err := client.GetBucketMetrics(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetBucketMetrics() error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,135 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Limits S3 puts on the tags of a bucket
+const (
+	maxBucketTags        = 50
+	maxBucketTagKeyLen   = 128
+	maxBucketTagValueLen = 256
+)
+
+// GetBucketTags returns the tags of the bucket, which become cost allocation tags once
+// they are activated in the billing console.
+func (c *Client) GetBucketTags(ctx context.Context) (*models.BucketTags, error) {
+	if err := c.checkSupported(featureBucketTagging); err != nil {
+		return nil, err
+	}
+	tags, err := c.bucketTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BucketTags{
+		BucketName:    c.config.BucketName,
+		Tags:          tags,
+		OperationTime: utils.FormatTime(time.Now()),
+	}, nil
+}
+
+// PutBucketTags sets the tags in set and removes the keys in remove, keeping the other
+// tags of the bucket unless replace is set. S3 only replaces the whole tag set, so the
+// current tags are read first. Without any tags left the tag set is deleted.
+func (c *Client) PutBucketTags(ctx context.Context, set map[string]string, remove []string, replace bool) (*models.BucketTags, error) {
+	if err := c.checkSupported(featureBucketTagging); err != nil {
+		return nil, err
+	}
+	for key, value := range set {
+		if err := validateBucketTag(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := make(map[string]string)
+	if !replace {
+		current, err := c.bucketTags(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tags = current
+	}
+	maps.Copy(tags, set)
+	for _, key := range remove {
+		delete(tags, key)
+	}
+
+	for key := range tags {
+		if strings.HasPrefix(key, "aws:") {
+			return nil, fmt.Errorf("the bucket has the system tag %s, which cannot be written back; manage its tags where it was created", key)
+		}
+	}
+	if len(tags) > maxBucketTags {
+		return nil, fmt.Errorf("a bucket can have at most %d tags, this would set %d", maxBucketTags, len(tags))
+	}
+
+	var err error
+	if len(tags) == 0 {
+		_, err = c.s3Client.DeleteBucketTagging(ctx, &s3.DeleteBucketTaggingInput{
+			Bucket: aws.String(c.config.BucketName),
+		})
+	} else {
+		tagSet := make([]types.Tag, 0, len(tags))
+		for _, key := range slices.Sorted(maps.Keys(tags)) {
+			tagSet = append(tagSet, types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+		}
+		_, err = c.s3Client.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+			Bucket:  aws.String(c.config.BucketName),
+			Tagging: &types.Tagging{TagSet: tagSet},
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket tags: %w", err)
+	}
+
+	return &models.BucketTags{
+		BucketName:    c.config.BucketName,
+		Tags:          tags,
+		OperationTime: utils.FormatTime(time.Now()),
+	}, nil
+}
+
+// bucketTags returns the tags of the bucket, empty when it has none.
+func (c *Client) bucketTags(ctx context.Context) (map[string]string, error) {
+	resp, err := c.s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+		Bucket: aws.String(c.config.BucketName),
+	})
+	if err != nil {
+		if hasErrorCode(err, "NoSuchTagSet", "NoSuchTagSetError") {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to get bucket tags: %w", err)
+	}
+
+	tags := make(map[string]string, len(resp.TagSet))
+	for _, tag := range resp.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func validateBucketTag(key, value string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("tag keys must not be empty")
+	case strings.HasPrefix(key, "aws:"):
+		return fmt.Errorf("tag key %s uses the reserved aws: prefix", key)
+	case len(key) > maxBucketTagKeyLen:
+		return fmt.Errorf("tag key %s is longer than %d characters", key, maxBucketTagKeyLen)
+	case len(value) > maxBucketTagValueLen:
+		return fmt.Errorf("value of tag %s is longer than %d characters", key, maxBucketTagValueLen)
+	}
+	return nil
+}
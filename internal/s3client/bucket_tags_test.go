@@ -0,0 +1,57 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"testing"
+)
+
+func TestBucketTags(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	expect := func(want map[string]string) {
+		t.Helper()
+		result, err := client.GetBucketTags(ctx)
+		if err != nil {
+			t.Fatalf("GetBucketTags() error = %v", err)
+		}
+		if !reflect.DeepEqual(result.Tags, want) {
+			t.Errorf("Tags = %v, want %v", result.Tags, want)
+		}
+	}
+	put := func(set map[string]string, remove []string, replace bool) {
+		t.Helper()
+		if _, err := client.PutBucketTags(ctx, set, remove, replace); err != nil {
+			t.Fatalf("PutBucketTags() error = %v", err)
+		}
+	}
+
+	expect(map[string]string{})
+	put(map[string]string{"team": "backend", "env": "prod"}, nil, false)
+	expect(map[string]string{"team": "backend", "env": "prod"})
+	put(map[string]string{"env": "staging"}, []string{"team"}, false)
+	expect(map[string]string{"env": "staging"})
+	put(map[string]string{"cost-center": "42"}, nil, true)
+	expect(map[string]string{"cost-center": "42"})
+	put(nil, []string{"cost-center"}, false)
+	expect(map[string]string{})
+
+	if _, err := client.PutBucketTags(ctx, map[string]string{"aws:createdBy": "me"}, nil, false); err == nil {
+		t.Error("PutBucketTags() with an aws: key succeeded")
+	}
+}
+
+func TestBucketTagsUnsupported(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) { cfg.Provider = "gcs" })
+	if _, err := client.GetBucketTags(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetBucketTags() error = %v, want ErrUnsupported", err)
+	}
+}
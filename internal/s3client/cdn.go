@@ -0,0 +1,174 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	cftypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+
+	"s3manager/internal/models"
+)
+
+const (
+	CDNProviderCloudFront = "cloudfront"
+	CDNProviderWebhook    = "webhook"
+
+	// Above this many paths a single wildcard is cheaper than listing every file
+	maxInvalidationPaths = 1000
+)
+
+// CDNConfigured reports whether a CloudFront distribution or purge webhook is available.
+func (c *Client) CDNConfigured(distributionID string) bool {
+	return distributionID != "" || c.config.CloudFrontDistributionID != "" || c.config.CDNPurgeURL != ""
+}
+
+// InvalidateCDN purges the given paths from CloudFront when a distribution ID is known,
+// otherwise from the configured purge webhook. The returned invalidation carries the
+// error message as well so it can be reported alongside a successful upload.
+func (c *Client) InvalidateCDN(ctx context.Context, distributionID string, paths []string) (*models.CDNInvalidation, error) {
+	if distributionID == "" {
+		distributionID = c.config.CloudFrontDistributionID
+	}
+
+	var invalidation *models.CDNInvalidation
+	var err error
+	switch {
+	case distributionID != "":
+		invalidation, err = c.invalidateCloudFront(ctx, distributionID, paths)
+	case c.config.CDNPurgeURL != "":
+		invalidation, err = c.purgeWebhook(ctx, paths)
+	default:
+		return nil, fmt.Errorf("no CDN configured: set CLOUDFRONT_DISTRIBUTION_ID or CDN_PURGE_URL")
+	}
+
+	if err != nil {
+		invalidation.Error = err.Error()
+	}
+	return invalidation, err
+}
+
+func (c *Client) invalidateCloudFront(ctx context.Context, distributionID string, paths []string) (*models.CDNInvalidation, error) {
+	paths = cloudFrontPaths(paths)
+	invalidation := &models.CDNInvalidation{
+		Provider:       CDNProviderCloudFront,
+		DistributionID: distributionID,
+		Paths:          paths,
+	}
+
+	client := cloudfront.NewFromConfig(c.awsConfig)
+	resp, err := client.CreateInvalidation(ctx, &cloudfront.CreateInvalidationInput{
+		DistributionId: aws.String(distributionID),
+		InvalidationBatch: &cftypes.InvalidationBatch{
+			CallerReference: aws.String("s3manager-" + strconv.FormatInt(time.Now().UnixNano(), 10)),
+			Paths: &cftypes.Paths{
+				Quantity: aws.Int32(int32(len(paths))),
+				Items:    paths,
+			},
+		},
+	})
+	if err != nil {
+		return invalidation, fmt.Errorf("failed to create CloudFront invalidation: %w", err)
+	}
+
+	if resp.Invalidation != nil {
+		invalidation.InvalidationID = aws.ToString(resp.Invalidation.Id)
+		invalidation.Status = aws.ToString(resp.Invalidation.Status)
+	}
+	return invalidation, nil
+}
+
+// cloudFrontPaths escapes every segment of paths like the URLs CloudFront caches, so that
+// keys with spaces or non-ASCII characters are invalidated. A trailing * stays a wildcard.
+func cloudFrontPaths(paths []string) []string {
+	escaped := make([]string, len(paths))
+	for i, path := range paths {
+		path, wildcard := strings.CutSuffix(path, "*")
+		segments := strings.Split(path, "/")
+		for j, segment := range segments {
+			segments[j] = url.PathEscape(segment)
+		}
+		escaped[i] = strings.Join(segments, "/")
+		if wildcard {
+			escaped[i] += "*"
+		}
+	}
+	return escaped
+}
+
+func (c *Client) purgeWebhook(ctx context.Context, paths []string) (*models.CDNInvalidation, error) {
+	invalidation := &models.CDNInvalidation{
+		Provider: CDNProviderWebhook,
+		Paths:    paths,
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"bucket": c.config.BucketName,
+		"paths":  paths,
+	})
+	if err != nil {
+		return invalidation, fmt.Errorf("failed to encode purge request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.CDNPurgeURL, bytes.NewReader(body))
+	if err != nil {
+		return invalidation, fmt.Errorf("failed to create purge request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.CDNPurgeToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.CDNPurgeToken)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return invalidation, fmt.Errorf("failed to call purge webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	invalidation.Status = resp.Status
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return invalidation, fmt.Errorf("purge webhook returned %s", resp.Status)
+	}
+	return invalidation, nil
+}
+
+// InvalidationPaths converts changed object keys into CDN paths. Index documents also
+// invalidate their directory URL, and large change sets collapse into a prefix wildcard.
+func InvalidationPaths(keys []string, destinationPath string) []string {
+	if len(keys) == 0 {
+		return []string{}
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	for _, key := range keys {
+		add("/" + key)
+		if key == "index.html" || strings.HasSuffix(key, "/index.html") {
+			add("/" + strings.TrimSuffix(key, "index.html"))
+		}
+	}
+
+	if len(paths) > maxInvalidationPaths {
+		prefix := strings.Trim(destinationPath, "/")
+		if prefix == "" {
+			return []string{"/*"}
+		}
+		return []string{"/" + prefix + "/*"}
+	}
+	return paths
+}
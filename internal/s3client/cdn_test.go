@@ -0,0 +1,100 @@
+package s3client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"s3manager/config"
+	"testing"
+)
+
+func TestInvalidationPaths(t *testing.T) {
+	paths := InvalidationPaths([]string{"site/index.html", "site/app.js", "index.html"}, "site")
+	expected := []string{"/site/index.html", "/site/", "/site/app.js", "/index.html", "/"}
+
+	if len(paths) != len(expected) {
+		t.Fatalf("InvalidationPaths() = %v, want %v", paths, expected)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("paths[%d] = %s, want %s", i, paths[i], expected[i])
+		}
+	}
+
+	var many []string
+	for i := 0; i <= maxInvalidationPaths; i++ {
+		many = append(many, fmt.Sprintf("site/file-%d.js", i))
+	}
+
+	if paths := InvalidationPaths(many, "/site/"); len(paths) != 1 || paths[0] != "/site/*" {
+		t.Errorf("InvalidationPaths() with many keys = %v, want [/site/*]", paths)
+	}
+
+	if paths := InvalidationPaths(many, ""); len(paths) != 1 || paths[0] != "/*" {
+		t.Errorf("InvalidationPaths() with many keys at root = %v, want [/*]", paths)
+	}
+}
+
+func TestCloudFrontPaths(t *testing.T) {
+	got := cloudFrontPaths([]string{"/site/annual report.pdf", "/site/café/", "/my site/*", "/*"})
+	want := []string{"/site/annual%20report.pdf", "/site/caf%C3%A9/", "/my%20site/*", "/*"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cloudFrontPaths() = %v, want %v", got, want)
+	}
+}
+
+func TestInvalidateCDNWebhook(t *testing.T) {
+	var received map[string]interface{}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	c := &Client{config: &config.Config{
+		BucketName:    "site",
+		CDNPurgeURL:   server.URL,
+		CDNPurgeToken: "secret",
+	}}
+
+	invalidation, err := c.InvalidateCDN(context.Background(), "", []string{"/index.html"})
+	if err != nil {
+		t.Fatalf("InvalidateCDN() error = %v", err)
+	}
+
+	if invalidation.Provider != CDNProviderWebhook {
+		t.Errorf("Provider = %s, want %s", invalidation.Provider, CDNProviderWebhook)
+	}
+
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %s, want %s", auth, "Bearer secret")
+	}
+
+	if received["bucket"] != "site" {
+		t.Errorf("bucket = %v, want %s", received["bucket"], "site")
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	c.config.CDNPurgeURL = failing.URL
+	invalidation, err = c.InvalidateCDN(context.Background(), "", []string{"/index.html"})
+	if err == nil {
+		t.Errorf("InvalidateCDN() with failing webhook should return error")
+	}
+	if invalidation == nil || invalidation.Error == "" {
+		t.Errorf("failed invalidation should carry the error message")
+	}
+
+	c.config.CDNPurgeURL = ""
+	if _, err := c.InvalidateCDN(context.Background(), "", []string{"/"}); err == nil {
+		t.Errorf("InvalidateCDN() without configuration should return error")
+	}
+}
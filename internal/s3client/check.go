@@ -0,0 +1,78 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	FreshnessOK       = "ok"
+	FreshnessMissing  = "missing"
+	FreshnessStale    = "stale"
+	FreshnessTooSmall = "too_small"
+)
+
+type FreshnessOptions struct {
+	Prefix  string
+	Pattern string
+	// MaxAge and MinSize are not checked when zero.
+	MaxAge  time.Duration
+	MinSize int64
+}
+
+// CheckFreshness verifies that the newest object under the prefix exists, is younger than
+// MaxAge and at least MinSize bytes. A failed check is reported in the result, not as an
+// error; errors mean the check itself could not run.
+func (c *Client) CheckFreshness(ctx context.Context, opts FreshnessOptions) (*models.FreshnessResult, error) {
+	latest, err := c.LatestObjects(ctx, LatestOptions{Prefixes: []string{opts.Prefix}, Count: 1, Pattern: opts.Pattern})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.FreshnessResult{
+		BucketName:     c.config.BucketName,
+		Prefix:         opts.Prefix,
+		Pattern:        opts.Pattern,
+		Problems:       []string{},
+		CheckedObjects: latest.MatchedCount,
+		OperationTime:  latest.OperationTime,
+	}
+	if opts.MaxAge > 0 {
+		result.MaxAge = opts.MaxAge.String()
+	}
+	if opts.MinSize > 0 {
+		result.MinSizeBytes = opts.MinSize
+		result.MinSizeHuman = utils.FormatBytes(opts.MinSize)
+	}
+
+	if len(latest.Items) == 0 {
+		result.Status = FreshnessMissing
+		result.Problems = append(result.Problems, fmt.Sprintf("no objects found under %q", opts.Prefix))
+		return result, nil
+	}
+
+	newest := latest.Items[0]
+	result.Latest = &newest
+
+	if age := time.Duration(newest.AgeSeconds) * time.Second; opts.MaxAge > 0 && age > opts.MaxAge {
+		result.Status = FreshnessStale
+		result.Problems = append(result.Problems, fmt.Sprintf("newest object is %s old, more than %s", age, opts.MaxAge))
+	}
+	if opts.MinSize > 0 && newest.Size < opts.MinSize {
+		if result.Status == "" {
+			result.Status = FreshnessTooSmall
+		}
+		result.Problems = append(result.Problems, fmt.Sprintf("newest object is %s, smaller than %s",
+			utils.FormatBytes(newest.Size), utils.FormatBytes(opts.MinSize)))
+	}
+
+	if result.Status == "" {
+		result.Status = FreshnessOK
+		result.OK = true
+	}
+	return result, nil
+}
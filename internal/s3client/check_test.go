@@ -0,0 +1,51 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCheckFreshness(t *testing.T) {
+	modified := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("prefix") == "empty/" {
+			fmt.Fprint(w, `<ListBucketResult></ListBucketResult>`)
+			return
+		}
+		fmt.Fprintf(w, `<ListBucketResult><Contents><Key>backups/db.sql.gz</Key><LastModified>%s</LastModified><Size>1024</Size></Contents></ListBucketResult>`, modified)
+	})
+	client := newTestClient(t, handler, nil)
+
+	tests := []struct {
+		name     string
+		opts     FreshnessOptions
+		status   string
+		problems int
+	}{
+		{"fresh", FreshnessOptions{Prefix: "backups/", MaxAge: 3 * time.Hour, MinSize: 1024}, FreshnessOK, 0},
+		{"stale", FreshnessOptions{Prefix: "backups/", MaxAge: time.Hour}, FreshnessStale, 1},
+		{"too small", FreshnessOptions{Prefix: "backups/", MinSize: 2048}, FreshnessTooSmall, 1},
+		{"stale and too small", FreshnessOptions{Prefix: "backups/", MaxAge: time.Hour, MinSize: 2048}, FreshnessStale, 2},
+		{"missing", FreshnessOptions{Prefix: "empty/", MaxAge: time.Hour}, FreshnessMissing, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.CheckFreshness(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("CheckFreshness() error = %v", err)
+			}
+
+			if result.Status != tt.status || len(result.Problems) != tt.problems {
+				t.Errorf("status = %s, problems = %v, want %s with %d problems", result.Status, result.Problems, tt.status, tt.problems)
+			}
+
+			if result.OK != (tt.status == FreshnessOK) {
+				t.Errorf("OK = %t for status %s", result.OK, result.Status)
+			}
+		})
+	}
+}
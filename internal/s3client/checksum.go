@@ -0,0 +1,143 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	ChecksumAuto   = "auto"
+	ChecksumETag   = "etag"
+	ChecksumSHA256 = "sha256"
+)
+
+// VerifyChecksum reads the remote checksums of key and, when localPath is set, recomputes
+// them for the local file. partSize overrides the part size detected from the first part.
+func (c *Client) VerifyChecksum(ctx context.Context, key, localPath, algorithm string, partSize int64) (*models.ChecksumResult, error) {
+	bucketName := c.config.BucketName
+
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(bucketName),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = c.sseCustomerKey.headers()
+
+	head, err := c.s3Client.HeadObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to head object %s: %w", key, err)
+	}
+
+	result := &models.ChecksumResult{
+		BucketName:    bucketName,
+		Key:           key,
+		Size:          aws.ToInt64(head.ContentLength),
+		RemoteETag:    strings.Trim(aws.ToString(head.ETag), `"`),
+		RemoteSHA256:  aws.ToString(head.ChecksumSHA256),
+		PartsCount:    multipartCount(strings.Trim(aws.ToString(head.ETag), `"`)),
+		OperationTime: utils.FormatTime(time.Now()),
+	}
+
+	if result.PartsCount > 0 {
+		if partSize == 0 {
+			partSize, err = c.firstPartSize(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.PartSize = partSize
+	}
+
+	if localPath == "" {
+		return result, nil
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access path %s: %w", localPath, err)
+	}
+	result.LocalPath = localPath
+	result.LocalSize = info.Size()
+
+	if algorithm == ChecksumAuto {
+		algorithm = ChecksumETag
+		if result.RemoteSHA256 != "" || !c.supports(featureETagMD5) {
+			algorithm = ChecksumSHA256
+		}
+	}
+	if algorithm == ChecksumETag {
+		if err := c.checkSupported(featureETagMD5); err != nil {
+			return nil, err
+		}
+	}
+	result.Algorithm = algorithm
+
+	var match bool
+	switch algorithm {
+	case ChecksumETag:
+		if result.PartsCount > 0 {
+			result.LocalETag, err = utils.MultipartETag(localPath, result.PartSize)
+		} else {
+			result.LocalETag, err = utils.FileMD5(localPath)
+		}
+		match = result.LocalETag == result.RemoteETag
+	case ChecksumSHA256:
+		if result.RemoteSHA256 == "" {
+			return nil, fmt.Errorf("object %s has no SHA-256 checksum", key)
+		}
+		if strings.Contains(result.RemoteSHA256, "-") {
+			result.LocalSHA256, err = utils.CompositeSHA256(localPath, result.PartSize)
+		} else {
+			result.LocalSHA256, err = utils.FileSHA256(localPath)
+		}
+		match = result.LocalSHA256 == result.RemoteSHA256
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	match = match && result.LocalSize == result.Size
+	result.Match = &match
+	return result, nil
+}
+
+// firstPartSize asks S3 for the size of part 1, which is the part size used for the upload.
+func (c *Client) firstPartSize(ctx context.Context, key string) (int64, error) {
+	input := &s3.HeadObjectInput{
+		Bucket:     aws.String(c.config.BucketName),
+		Key:        aws.String(key),
+		PartNumber: aws.Int32(1),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = c.sseCustomerKey.headers()
+
+	head, err := c.s3Client.HeadObject(ctx, input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to detect part size, pass --part-size explicitly: %w", err)
+	}
+	return aws.ToInt64(head.ContentLength), nil
+}
+
+// multipartCount returns the part count encoded in a multipart ETag, or 0 for single-part objects.
+func multipartCount(etag string) int {
+	_, suffix, ok := strings.Cut(etag, "-")
+	if !ok {
+		return 0
+	}
+	var parts int
+	if _, err := fmt.Sscanf(suffix, "%d", &parts); err != nil {
+		return 0
+	}
+	return parts
+}
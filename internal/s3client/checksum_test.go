@@ -0,0 +1,20 @@
+package s3client
+
+import "testing"
+
+func TestMultipartCount(t *testing.T) {
+	tests := []struct {
+		etag     string
+		expected int
+	}{
+		{"5d41402abc4b2a76b9719d911017c592", 0},
+		{"d41d8cd98f00b204e9800998ecf8427e-3", 3},
+		{"d41d8cd98f00b204e9800998ecf8427e-x", 0},
+	}
+
+	for _, tt := range tests {
+		if result := multipartCount(tt.etag); result != tt.expected {
+			t.Errorf("multipartCount(%s) = %d, want %d", tt.etag, result, tt.expected)
+		}
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -19,58 +21,170 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 
 	appConfig "s3manager/config"
+	"s3manager/internal/localfs"
 	"s3manager/internal/models"
+	"s3manager/internal/signing"
+	"s3manager/internal/storage"
 	"s3manager/pkg/utils"
 )
 
 type Client struct {
-	s3Client *s3.Client
-	config   *appConfig.Config
+	s3Client  *s3.Client
+	awsConfig aws.Config
+	config    *appConfig.Config
+	provider  providerPreset
+	// accessPoint is set when BucketName is an access point ARN
+	accessPoint bool
+	// express is set for directory buckets (S3 Express One Zone)
+	express  bool
+	progress func(FileProgress)
+	signer   *signing.Signer
+	// sseCustomerKey encrypts uploads and decrypts downloads with SSE-C
+	sseCustomerKey *SSECustomerKey
+	// sourceDisposal removes files after their upload was verified
+	sourceDisposal *SourceDisposal
+	// expires stamps uploads with ExpiresTag, unless it is zero
+	expires time.Time
+	// throttle reduces the requests in flight while the provider asks to slow down
+	throttle *utils.AdaptiveLimiter
+	// listingCacheDir holds the cached listings of the bucket, listingCache is set when
+	// listings are read from there
+	listingCacheDir string
+	listingCache    *listingCache
 }
 
 func New(cfg *appConfig.Config) (*Client, error) {
+	if cfg.Backend != "" && !strings.EqualFold(cfg.Backend, "s3") {
+		return nil, fmt.Errorf("this command needs S3, it does not support STORAGE_BACKEND=%s", cfg.Backend)
+	}
+	if localfs.IsURL(cfg.BucketName) {
+		return nil, fmt.Errorf("this command needs S3, it does not support local directories (%s)", cfg.BucketName)
+	}
+
+	provider, err := providerPresetFor(cfg.Provider, cfg.ApiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateBucket(cfg.BucketName); err != nil {
+		return nil, err
+	}
+	accessPoint := isAccessPoint(cfg.BucketName)
+
+	express := cfg.Express || isDirectoryBucket(cfg.BucketName)
+	if express {
+		if err := validateDirectoryBucket(cfg.BucketName); err != nil {
+			return nil, err
+		}
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = accessPointRegion(cfg.BucketName)
+	}
+	if region == "" {
+		region = provider.region
+	}
+	if region == "" && accessPoint {
+		// Multi-region access points are signed for all regions, the client still needs one
+		region = "us-east-1"
+	}
+
+	var credentialsProvider aws.CredentialsProvider = credentials.StaticCredentialsProvider{
+		Value: aws.Credentials{
+			AccessKeyID:     cfg.AccessKey,
+			SecretAccessKey: cfg.SecretKey,
+		},
+	}
+	if cfg.NoSignRequest {
+		// Requests with anonymous credentials are sent without a signature
+		credentialsProvider = aws.AnonymousCredentials{}
+	}
+
 	awsConfig, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(cfg.Region),
-		config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
-			Value: aws.Credentials{
-				AccessKeyID:     cfg.AccessKey,
-				SecretAccessKey: cfg.SecretKey,
-			},
-		}),
+		config.WithRegion(region),
+		config.WithCredentialsProvider(credentialsProvider),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	provider.apply(&awsConfig)
+	settings := cfg.Settings.WithDefaults()
+	awsConfig.Retryer = func() aws.Retryer {
+		return newThrottleRetryer(settings.MaxAttempts, settings.ThrottleMaxAttempts)
+	}
+
+	// A single limiter is shared by every SDK client created from this config
+	throttle := utils.NewAdaptiveLimiter()
+	awsConfig.APIOptions = append(awsConfig.APIOptions, adaptiveConcurrencyMiddleware(throttle))
+	if limiter := utils.NewRateLimiter(settings.RateLimit, settings.RateLimitBurst); limiter != nil {
+		awsConfig.APIOptions = append(awsConfig.APIOptions, rateLimitMiddleware(limiter))
+	}
+
+	apiURL := cfg.ApiURL
+	if apiURL == "" {
+		apiURL = provider.endpoint
+	}
+	cacheDir := listingCacheDir(cfg.ListingCacheDir, apiURL, region, cfg.BucketName)
+	if cacheDir != "" {
+		awsConfig.APIOptions = append(awsConfig.APIOptions, invalidateListingsMiddleware(cacheDir))
+	}
 
 	var s3Client *s3.Client
-	if cfg.ApiURL != "" {
+	if apiURL != "" {
 		s3Client = s3.NewFromConfig(awsConfig, func(o *s3.Options) {
-			o.BaseEndpoint = aws.String(cfg.ApiURL)
-			o.UsePathStyle = true
+			o.BaseEndpoint = aws.String(apiURL)
+			// Access points and directory buckets are only reachable by host name
+			o.UsePathStyle = provider.pathStyle && !accessPoint && !express
 		})
 	} else {
-		s3Client = s3.NewFromConfig(awsConfig)
+		s3Client = s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+			// Requests go to the access point's region, not the one configured
+			o.UseARNRegion = accessPoint
+		})
 	}
 
 	return &Client{
-		s3Client: s3Client,
-		config:   cfg,
+		s3Client:    s3Client,
+		awsConfig:   awsConfig,
+		config:      cfg,
+		provider:    provider,
+		accessPoint: accessPoint,
+		express:     express,
+		throttle:    throttle,
+
+		listingCacheDir: cacheDir,
 	}, nil
 }
 
+// unknownValue is reported for bucket details the credentials may not look up
+const unknownValue = "unknown"
+
+// GetBucketInfo lists the bucket to report its size. The region and creation date come
+// from GetBucketLocation and ListBuckets, which scoped IAM policies often deny, so those
+// lookups failing only adds a warning to the result.
 func (c *Client) GetBucketInfo(ctx context.Context) (*models.BucketInfo, error) {
 	bucketName := c.config.BucketName
+	var warnings []string
 
-	locationResp, err := c.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
-		Bucket: aws.String(bucketName),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get bucket location: %w", err)
+	var region string
+	if c.supports(featureBucketLocation) {
+		locationResp, err := c.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
+			Bucket: aws.String(bucketName),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to get bucket location: %w", err)
+			}
+			region = unknownValue
+			warnings = append(warnings, fmt.Sprintf("region unknown, failed to get bucket location: %v", err))
+		} else {
+			region = string(locationResp.LocationConstraint)
+		}
 	}
-
-	region := string(locationResp.LocationConstraint)
 	if region == "" {
 		region = c.config.Region // Use configured a region as a fallback
 	}
@@ -79,36 +193,34 @@ func (c *Client) GetBucketInfo(ctx context.Context) (*models.BucketInfo, error)
 	var totalSize int64
 	var lastModified time.Time
 
-	paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucketName),
-	})
-
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list objects: %w", err)
-		}
-
-		objectCount += int64(len(page.Contents))
-		for _, obj := range page.Contents {
-			totalSize += *obj.Size
-			if obj.LastModified != nil && obj.LastModified.After(lastModified) {
-				lastModified = *obj.LastModified
-			}
+	// Objects are only counted, none is kept in memory
+	var mu sync.Mutex
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		objectCount++
+		totalSize += aws.ToInt64(obj.Size)
+		if obj.LastModified != nil && obj.LastModified.After(lastModified) {
+			lastModified = *obj.LastModified
 		}
+		return false
+	})
+	if _, err := lister.listAll(ctx, ""); err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
 	}
 
-	bucketsResp, err := c.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	creationDate, err := c.bucketCreationDate(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list buckets: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to list buckets: %w", err)
+		}
+		warnings = append(warnings, fmt.Sprintf("creation date unknown, failed to list buckets: %v", err))
+	} else if creationDate == nil {
+		warnings = append(warnings, "creation date unknown, the bucket is not in the list of buckets")
 	}
 
-	var creationDate time.Time
-	for _, bucket := range bucketsResp.Buckets {
-		if *bucket.Name == bucketName {
-			creationDate = *bucket.CreationDate
-			break
-		}
+	for _, warning := range warnings {
+		slog.Warn(warning, "bucket", bucketName)
 	}
 
 	return &models.BucketInfo{
@@ -120,84 +232,35 @@ func (c *Client) GetBucketInfo(ctx context.Context) (*models.BucketInfo, error)
 		TotalSizeHuman: utils.FormatBytes(totalSize),
 		LastModified:   lastModified,
 		APIEndpoint:    c.config.ApiURL,
+		Warnings:       warnings,
 	}, nil
 }
 
-func (c *Client) DeleteOldFiles(ctx context.Context, folder string, daysOld int, dryMode bool) (*models.DeleteResult, error) {
-	bucketName := c.config.BucketName
-	cutoffDate := time.Now().AddDate(0, 0, -daysOld)
-
-	prefix := folder
-	if !strings.HasSuffix(prefix, "/") && prefix != "" {
-		prefix += "/"
+// bucketCreationDate finds the bucket in the account's bucket list, nil when it is not
+// listed.
+func (c *Client) bucketCreationDate(ctx context.Context) (*time.Time, error) {
+	if c.express {
+		return c.directoryBucketCreationDate(ctx)
 	}
 
-	var toDelete []types.ObjectIdentifier
-	var deletedFiles []string
-	var totalSize int64
-
-	paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucketName),
-		Prefix: aws.String(prefix),
-	})
-
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list objects: %w", err)
-		}
-
-		for _, obj := range page.Contents {
-			if obj.LastModified != nil && obj.LastModified.Before(cutoffDate) {
-				toDelete = append(toDelete, types.ObjectIdentifier{
-					Key: obj.Key,
-				})
-				deletedFiles = append(deletedFiles, *obj.Key)
-				totalSize += *obj.Size
-			}
-		}
+	resp, err := c.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
 	}
-
-	deletedCount := 0
-	if !dryMode {
-		for i := 0; i < len(toDelete); i += 1000 {
-			end := i + 1000
-			if end > len(toDelete) {
-				end = len(toDelete)
-			}
-
-			batch := toDelete[i:end]
-			if len(batch) == 0 {
-				continue
-			}
-
-			_, err := c.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
-				Bucket: aws.String(bucketName),
-				Delete: &types.Delete{
-					Objects: batch,
-				},
-			})
-			if err != nil {
-				return nil, fmt.Errorf("failed to delete objects batch: %w", err)
-			}
-			deletedCount += len(batch)
+	for _, bucket := range resp.Buckets {
+		if aws.ToString(bucket.Name) == c.config.BucketName {
+			return bucket.CreationDate, nil
 		}
 	}
-
-	return &models.DeleteResult{
-		BucketName:     bucketName,
-		Folder:         folder,
-		DaysOld:        daysOld,
-		DeletedFiles:   deletedFiles,
-		DeletedCount:   deletedCount,
-		TotalSizeBytes: totalSize,
-		TotalSizeHuman: utils.FormatBytes(totalSize),
-		OperationTime:  utils.FormatTime(time.Now()),
-		CutoffDate:     utils.FormatTime(cutoffDate),
-	}, nil
+	return nil, nil
 }
 
-func (c *Client) UploadFiles(ctx context.Context, paths []string, destinationPath string, shouldArchive bool, excludePatterns []string) (*models.UploadResult, error) {
+// UploadFiles uploads paths to destinationPath. When ctx is cancelled part-way the
+// files uploaded so far are returned as an interrupted result together with the error.
+// The archive is named archiveName, or gets a generated name when it is empty. A
+// non-zero modifiedSince skips files last modified before it; when no file is left,
+// nothing is uploaded.
+func (c *Client) UploadFiles(ctx context.Context, paths []string, destinationPath string, shouldArchive bool, archiveName string, excludePatterns []string, modifiedSince time.Time) (*models.UploadResult, error) {
 	startTime := time.Now()
 	bucketName := c.config.BucketName
 
@@ -207,91 +270,154 @@ func (c *Client) UploadFiles(ctx context.Context, paths []string, destinationPat
 
 	var uploadItems []models.UploadItem
 	var totalSize int64
+	var skipped int
 	var archivePath string
 	var archiveCreated bool
 
-	uploader := manager.NewUploader(c.s3Client, func(u *manager.Uploader) {
-		// Configure uploader options for no checksums
-		u.ClientOptions = append(u.ClientOptions, func(o *s3.Options) {
-			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
-
-			// Disable response checksum validation
-			o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	buildResult := func() *models.UploadResult {
+		duration := time.Since(startTime)
+		throughput := utils.BytesPerSecond(totalSize, duration)
+		return &models.UploadResult{
+			BucketName:      bucketName,
+			DestinationPath: destinationPath,
+			Items:           uploadItems,
+			TotalFiles:      len(uploadItems),
+			TotalSizeBytes:  totalSize,
+			TotalSizeHuman:  utils.FormatBytes(totalSize),
+			OperationTime:   utils.FormatTime(startTime),
+			ArchiveCreated:  archiveCreated,
+			ArchivePath:     archivePath,
+			UploadDuration:  duration.String(),
+			ThroughputBytes: throughput,
+			ThroughputHuman: utils.FormatSpeed(throughput),
+			ModifiedSince:   storage.FormatBound(modifiedSince),
+			SkippedCount:    skipped,
+		}
+	}
 
-			// Disable logging of skipped checksum validation
-			o.DisableLogOutputChecksumValidationSkipped = true
-		})
+	uploader := c.newUploader()
 
-		// Set part size for multipart uploads (optional optimization)
-		u.PartSize = 64 * 1024 * 1024 // 64MB parts
-		u.Concurrency = 5             // Number of concurrent uploads
+	if shouldArchive {
+		if c.sourceDisposal != nil {
+			return nil, fmt.Errorf("source files can only be removed when they are uploaded individually")
+		}
+		tempDir, err := utils.TempDir(c.config.TempDir)
+		if err != nil {
+			return nil, err
+		}
+		archivePath = filepath.Join(tempDir, utils.ArchiveName(archiveName, paths, ".zip"))
 
-		// Disable leave parts on error for cleaner uploads
-		u.LeavePartsOnError = false
-	})
+		// Registered before the archive is written so that failed and interrupted
+		// uploads do not leave it behind either
+		defer func(path string) {
+			err := utils.CleanupTempFile(path)
+			if err != nil {
+				slog.Warn("Failed to clean up temporary archive file", "path", path, "error", err)
+			}
+		}(archivePath)
 
-	if shouldArchive {
-		archivePath = filepath.Join(os.TempDir(), utils.GenerateArchiveName(paths, ".zip"))
-		archiveInfo, err := utils.CreateArchive(paths, archivePath, excludePatterns)
+		size, err := utils.ArchiveInputSize(paths, excludePatterns, modifiedSince)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create archive: %w", err)
+			return nil, err
+		}
+		if err := utils.CheckFreeSpace(filepath.Dir(archivePath), size); err != nil {
+			return nil, fmt.Errorf("cannot create archive, use --temp-dir or TEMP_DIR for a larger volume: %w", err)
+		}
+		archiveInfo, err := utils.CreateArchiveSince(ctx, paths, archivePath, excludePatterns, modifiedSince)
+		if err != nil {
+			err = fmt.Errorf("failed to create archive: %w", err)
+			if ctx.Err() != nil {
+				return interruptedUpload(buildResult(), err)
+			}
+			return nil, err
+		}
+		skipped = archiveInfo.SkippedCount
+		if !modifiedSince.IsZero() && archiveInfo.FileCount == 0 {
+			return buildResult(), nil
 		}
 
 		archiveCreated = true
 		totalSize = archiveInfo.CompressedSize
+		c.planTransfers(TransferUpload, 1, archiveInfo.CompressedSize)
 
 		remotePath := c.buildRemotePath(destinationPath, filepath.Base(archivePath))
 		if err := c.uploadSingleFile(ctx, uploader, archivePath, remotePath); err != nil {
-			return nil, fmt.Errorf("failed to upload archive: %w", err)
+			err = fmt.Errorf("failed to upload archive: %w", err)
+			if ctx.Err() != nil {
+				totalSize = 0
+				return interruptedUpload(buildResult(), err)
+			}
+			return nil, err
 		}
 
 		uploadItems = append(uploadItems, models.UploadItem{
 			LocalPath:  strings.Join(paths, ", "),
 			RemotePath: remotePath,
+			Signature:  c.signatureKey(remotePath),
+			Expires:    c.formatExpiry(),
 			Size:       archiveInfo.CompressedSize,
 			IsArchived: true,
 		})
-
-		defer func(path string) {
-			err := utils.CleanupTempFile(path)
-			if err != nil {
-				slog.Warn("Failed to clean up temporary archive file", "path", path, "error", err)
-			}
-		}(archivePath)
 	} else {
+		if err := c.planUploads(paths, modifiedSince); err != nil {
+			return nil, err
+		}
 		for _, path := range paths {
-			items, size, err := c.uploadPath(ctx, uploader, path, destinationPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to upload %s: %w", path, err)
-			}
+			items, size, skippedFiles, err := c.uploadPath(ctx, uploader, path, destinationPath, modifiedSince)
 			uploadItems = append(uploadItems, items...)
 			totalSize += size
+			skipped += skippedFiles
+			if err != nil {
+				err = fmt.Errorf("failed to upload %s: %w", path, err)
+				if ctx.Err() != nil {
+					return interruptedUpload(buildResult(), err)
+				}
+				return nil, err
+			}
 		}
 	}
 
-	duration := time.Since(startTime)
+	return buildResult(), nil
+}
 
-	return &models.UploadResult{
-		BucketName:      bucketName,
-		DestinationPath: destinationPath,
-		Items:           uploadItems,
-		TotalFiles:      len(uploadItems),
-		TotalSizeBytes:  totalSize,
-		TotalSizeHuman:  utils.FormatBytes(totalSize),
-		OperationTime:   utils.FormatTime(startTime),
-		ArchiveCreated:  archiveCreated,
-		ArchivePath:     archivePath,
-		UploadDuration:  duration.String(),
-	}, nil
+func interruptedUpload(result *models.UploadResult, err error) (*models.UploadResult, error) {
+	result.Interrupted = true
+	result.Error = err.Error()
+	return result, err
+}
+
+func (c *Client) newUploader() *manager.Uploader {
+	return manager.NewUploader(c.s3Client, func(u *manager.Uploader) {
+		// Configure uploader options for no checksums
+		u.ClientOptions = append(u.ClientOptions, func(o *s3.Options) {
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+
+			// Disable response checksum validation
+			o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+
+			// Disable logging of skipped checksum validation
+			o.DisableLogOutputChecksumValidationSkipped = true
+		})
+
+		// Set part size for multipart uploads, smaller under a memory budget
+		settings := c.settings()
+		u.PartSize, u.Concurrency = c.transferSettings(settings.PartSize, settings.UploadConcurrency, 1)
+
+		// Disable leave parts on error for cleaner uploads
+		u.LeavePartsOnError = false
+	})
 }
 
-func (c *Client) uploadPath(ctx context.Context, uploader *manager.Uploader, localPath, destinationPath string) ([]models.UploadItem, int64, error) {
+// uploadPath uploads a file, or the files below a directory, and returns them with their
+// total size and the number of files skipped as modified before modifiedSince.
+func (c *Client) uploadPath(ctx context.Context, uploader *manager.Uploader, localPath, destinationPath string, modifiedSince time.Time) ([]models.UploadItem, int64, int, error) {
 	var items []models.UploadItem
 	var totalSize int64
+	var skipped int
 
 	fileInfo, err := os.Stat(localPath)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to stat %s: %w", localPath, err)
+		return nil, 0, 0, fmt.Errorf("failed to stat %s: %w", localPath, err)
 	}
 
 	if fileInfo.IsDir() {
@@ -299,25 +425,43 @@ func (c *Client) uploadPath(ctx context.Context, uploader *manager.Uploader, loc
 			if err != nil {
 				return err
 			}
+			if err := ctx.Err(); err != nil {
+				return context.Cause(ctx)
+			}
 
 			if !info.IsDir() {
+				if info.ModTime().Before(modifiedSince) {
+					skipped++
+					return nil
+				}
+
 				relPath, err := filepath.Rel(localPath, path)
 				if err != nil {
 					return err
 				}
 
-				remotePath := c.buildRemotePath(destinationPath, filepath.Join(filepath.Base(localPath), relPath))
+				relPath = filepath.Join(filepath.Base(localPath), relPath)
+				remotePath := c.buildRemotePath(destinationPath, relPath)
 
 				if err := c.uploadSingleFile(ctx, uploader, path, remotePath); err != nil {
 					return err
 				}
 
-				items = append(items, models.UploadItem{
+				item := models.UploadItem{
 					LocalPath:  path,
 					RemotePath: remotePath,
+					Signature:  c.signatureKey(remotePath),
+					Expires:    c.formatExpiry(),
 					Size:       info.Size(),
 					IsArchived: false,
-				})
+				}
+				if c.sourceDisposal != nil {
+					if item.SourceMovedTo, err = c.disposeSource(ctx, path, remotePath, relPath); err != nil {
+						return err
+					}
+					item.SourceRemoved = true
+				}
+				items = append(items, item)
 
 				totalSize += info.Size()
 			}
@@ -325,26 +469,41 @@ func (c *Client) uploadPath(ctx context.Context, uploader *manager.Uploader, loc
 		})
 
 		if err != nil {
-			return nil, 0, err
+			// Items uploaded before the failure are still reported
+			return items, totalSize, skipped, err
 		}
 	} else {
+		if fileInfo.ModTime().Before(modifiedSince) {
+			return nil, 0, 1, nil
+		}
+
 		remotePath := c.buildRemotePath(destinationPath, filepath.Base(localPath))
 
 		if err := c.uploadSingleFile(ctx, uploader, localPath, remotePath); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 
-		items = append(items, models.UploadItem{
+		item := models.UploadItem{
 			LocalPath:  localPath,
 			RemotePath: remotePath,
+			Signature:  c.signatureKey(remotePath),
+			Expires:    c.formatExpiry(),
 			Size:       fileInfo.Size(),
 			IsArchived: false,
-		})
+		}
+		if c.sourceDisposal != nil {
+			if item.SourceMovedTo, err = c.disposeSource(ctx, localPath, remotePath, filepath.Base(localPath)); err != nil {
+				// The file is uploaded, report it with the error
+				return []models.UploadItem{item}, fileInfo.Size(), 0, err
+			}
+			item.SourceRemoved = true
+		}
+		items = append(items, item)
 
 		totalSize = fileInfo.Size()
 	}
 
-	return items, totalSize, nil
+	return items, totalSize, skipped, nil
 }
 
 func (c *Client) uploadSingleFile(ctx context.Context, uploader *manager.Uploader, localPath, remotePath string) error {
@@ -368,12 +527,11 @@ func (c *Client) uploadSingleFile(ctx context.Context, uploader *manager.Uploade
 
 	// Configure the uploader to use multipart uploads for large files
 	// The AWS SDK will automatically use multipart uploads for files larger than the PartSize
-	uploader.PartSize = 5 * 1024 * 1024 // 5MB per part
-	uploader.Concurrency = 5            // 5 concurrent uploads
+	uploader.PartSize, uploader.Concurrency = c.transferSettings(manager.MinUploadPartSize, c.settings().UploadConcurrency, 1)
 
 	var checksumStr *string
 	h := sha256.New()
-	if _, err := io.Copy(h, file); err != nil {
+	if _, err := io.Copy(h, utils.ContextReader(ctx, file)); err != nil {
 		return fmt.Errorf("failed to calculate checksum: %w", err)
 	}
 	checksum := h.Sum(nil)
@@ -384,22 +542,66 @@ func (c *Client) uploadSingleFile(ctx context.Context, uploader *manager.Uploade
 		return fmt.Errorf("failed to reset file pointer: %w", err)
 	}
 
-	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+	signature, err := c.sign(ctx, localPath)
+	if err != nil {
+		return err
+	}
+
+	transfer := c.trackTransfer(TransferUpload, localPath, remotePath, fileInfo.Size())
+	input := &s3.PutObjectInput{
 		Bucket:         aws.String(c.config.BucketName),
 		Key:            aws.String(remotePath),
-		Body:           file,
+		Body:           transfer.body(file),
 		ContentType:    aws.String(contentType),
 		ContentLength:  aws.Int64(fileInfo.Size()),
 		ChecksumSHA256: checksumStr,
-	})
+		Tagging:        c.expiryTagging(),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = c.sseCustomerKey.headers()
+
+	err = c.upload(ctx, uploader, input)
+	transfer.finished(err)
 
 	if err != nil {
 		return fmt.Errorf("failed to upload to S3: %w", err)
 	}
 
+	if signature != nil {
+		return c.putSignature(ctx, remotePath, signature)
+	}
 	return nil
 }
 
+// upload runs the uploader and makes sure a multipart upload stopped by cancellation does
+// not leave orphaned parts. The uploader aborts failed uploads itself, but it does so with
+// the already cancelled context, so the abort request never reaches S3.
+func (c *Client) upload(ctx context.Context, uploader *manager.Uploader, input *s3.PutObjectInput) error {
+	_, err := uploader.Upload(ctx, input)
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	var multipartErr manager.MultiUploadFailure
+	if errors.As(err, &multipartErr) && multipartErr.UploadID() != "" {
+		c.abortMultipartUpload(aws.ToString(input.Key), multipartErr.UploadID())
+	}
+	return err
+}
+
+func (c *Client) abortMultipartUpload(key, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := c.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.config.BucketName),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		slog.Warn("Failed to abort multipart upload", "key", key, "upload_id", uploadID, "error", err)
+	}
+}
+
 func (c *Client) buildRemotePath(destinationPath, filename string) string {
 	if destinationPath == "" {
 		return filename
@@ -414,108 +616,207 @@ func (c *Client) buildRemotePath(destinationPath, filename string) string {
 	return destinationPath + filename
 }
 
-func (c *Client) DownloadLatestFile(ctx context.Context, folder, destinationPath string) (*models.DownloadResult, error) {
+// DownloadLatestFile downloads the newest object in folder to destinationPath. When the
+// object matches the conditions of opts nothing is downloaded and the result is marked
+// as not modified.
+func (c *Client) DownloadLatestFile(ctx context.Context, folder, destinationPath string, opts DownloadOptions) (*models.DownloadResult, error) {
 	startTime := time.Now()
 	bucketName := c.config.BucketName
 
-	prefix := folder
-	if !strings.HasSuffix(prefix, "/") && prefix != "" {
-		prefix += "/"
-	}
-
-	var objects []types.Object
-	paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucketName),
-		Prefix: aws.String(prefix),
-	})
-
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list objects: %w", err)
-		}
-
-		objects = append(objects, page.Contents...)
+	latestObject, localFilePath, err := c.latestDownload(ctx, folder, destinationPath, opts)
+	if err != nil {
+		return nil, err
 	}
-
-	if len(objects) == 0 {
-		return nil, fmt.Errorf("no files found in folder: %s", folder)
+	if opts.unchanged(latestObject) {
+		return c.notModified(folder, latestObject, localFilePath, startTime), nil
 	}
 
-	sort.Slice(objects, func(i, j int) bool {
-		return objects[i].LastModified.After(*objects[j].LastModified)
-	})
-
-	latestObject := objects[0]
-
 	if err := os.MkdirAll(destinationPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create destination directory: %w", err)
 	}
-
-	fileName := filepath.Base(*latestObject.Key)
-	localFilePath := filepath.Join(destinationPath, fileName)
-
-	file, err := os.Create(localFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+	if err := utils.CheckFreeSpace(destinationPath, *latestObject.Size); err != nil {
+		return nil, err
 	}
-	defer file.Close()
+	c.planTransfers(TransferDownload, 1, *latestObject.Size)
 
-	downloader := manager.NewDownloader(c.s3Client)
-	_, err = downloader.Download(ctx, file, &s3.GetObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    latestObject.Key,
-	})
+	item, err := c.fetchObject(ctx, latestObject, localFilePath, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download file: %w", err)
+		return nil, err
 	}
 
 	duration := time.Since(startTime)
-
-	downloadItem := models.DownloadItem{
-		RemotePath:   *latestObject.Key,
-		LocalPath:    localFilePath,
-		Size:         *latestObject.Size,
-		LastModified: latestObject.LastModified.Format(time.RFC3339),
-	}
+	throughput := utils.BytesPerSecond(*latestObject.Size, duration)
 
 	result := &models.DownloadResult{
 		BucketName:       bucketName,
 		SourcePath:       folder,
-		Items:            []models.DownloadItem{downloadItem},
+		Items:            []models.DownloadItem{item},
 		TotalFiles:       1,
 		TotalSizeBytes:   *latestObject.Size,
 		TotalSizeHuman:   utils.FormatBytes(*latestObject.Size),
 		OperationTime:    utils.FormatTime(startTime),
 		DownloadDuration: duration.String(),
+		ThroughputBytes:  throughput,
+		ThroughputHuman:  utils.FormatSpeed(throughput),
 	}
 
 	return result, nil
 }
 
+// fetchObject downloads obj to localPath. The content goes to a temporary file next to
+// it that is renamed into place only after the download, and the checks requested by
+// opts, succeeded. Otherwise the temporary file is removed and localPath is untouched.
+func (c *Client) fetchObject(ctx context.Context, obj types.Object, localPath string, opts DownloadOptions) (models.DownloadItem, error) {
+	file, err := utils.CreateTempFor(localPath)
+	if err != nil {
+		return models.DownloadItem{}, err
+	}
+	tmpPath := file.Name()
+	committed := false
+	defer func() {
+		file.Close()
+		if committed {
+			return
+		}
+		// Do not leave a truncated or unverified file behind
+		if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to remove partial download", "path", tmpPath, "error", err)
+		}
+	}()
+
+	transfer := c.trackTransfer(TransferDownload, localPath, *obj.Key, aws.ToInt64(obj.Size))
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    obj.Key,
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = c.sseCustomerKey.headers()
+
+	downloader := manager.NewDownloader(c.s3Client)
+	_, err = downloader.Download(ctx, transfer.writerAt(file), input)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	transfer.finished(err)
+	if err != nil {
+		return models.DownloadItem{}, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	item := newDownloadItem(obj, localPath)
+	if opts.VerifyChecksum {
+		check, err := c.VerifyChecksum(ctx, *obj.Key, tmpPath, ChecksumAuto, 0)
+		if err != nil {
+			return models.DownloadItem{}, fmt.Errorf("failed to verify checksum: %w", err)
+		}
+		if !*check.Match {
+			return models.DownloadItem{}, fmt.Errorf("checksum of the download of %s does not match", *obj.Key)
+		}
+		item.ChecksumVerified = true
+	}
+	if opts.Verifier != nil {
+		// A file that fails verification must not be used
+		if err := c.verifySignature(ctx, opts.Verifier, *obj.Key, tmpPath); err != nil {
+			return models.DownloadItem{}, err
+		}
+		item.SignatureVerified = true
+	}
+
+	if err := utils.CommitTemp(tmpPath, localPath); err != nil {
+		return models.DownloadItem{}, err
+	}
+	committed = true
+	return item, nil
+}
+
+// notModified is the result of a conditional download that was skipped. The item
+// describes the current object, so callers can keep its ETag for the next run.
+func (c *Client) notModified(folder string, obj types.Object, localFilePath string, startTime time.Time) *models.DownloadResult {
+	return &models.DownloadResult{
+		BucketName:       c.config.BucketName,
+		SourcePath:       folder,
+		Items:            []models.DownloadItem{newDownloadItem(obj, localFilePath)},
+		NotModified:      true,
+		TotalSizeHuman:   utils.FormatBytes(0),
+		OperationTime:    utils.FormatTime(startTime),
+		DownloadDuration: time.Since(startTime).String(),
+		ThroughputHuman:  utils.FormatSpeed(0),
+	}
+}
+
+func newDownloadItem(obj types.Object, localFilePath string) models.DownloadItem {
+	return models.DownloadItem{
+		RemotePath:        aws.ToString(obj.Key),
+		LocalPath:         localFilePath,
+		Size:              aws.ToInt64(obj.Size),
+		LastModified:      aws.ToTime(obj.LastModified).Format(time.RFC3339),
+		StorageClass:      string(obj.StorageClass),
+		ETag:              objectETag(obj),
+		ChecksumAlgorithm: checksumAlgorithms(obj),
+		ChecksumType:      string(obj.ChecksumType),
+	}
+}
+
+// latestDownload finds the newest object in folder and the local path it is downloaded to.
+func (c *Client) latestDownload(ctx context.Context, folder, destinationPath string, opts DownloadOptions) (types.Object, string, error) {
+	prefix := folder
+	if !strings.HasSuffix(prefix, "/") && prefix != "" {
+		prefix += "/"
+	}
+
+	objects, err := c.listObjects(ctx, prefix)
+	if err != nil {
+		return types.Object{}, "", err
+	}
+	// Signatures are uploaded after their file, they are never the latest backup
+	objects = slices.DeleteFunc(objects, func(obj types.Object) bool {
+		return isSignature(aws.ToString(obj.Key)) || !storage.MatchesRegex(aws.ToString(obj.Key), opts.Regex)
+	})
+
+	if len(objects) == 0 {
+		return types.Object{}, "", fmt.Errorf("no files found in folder: %s", folder)
+	}
+
+	sortNewestFirst(objects)
+
+	latestObject := objects[0]
+	return latestObject, filepath.Join(destinationPath, filepath.Base(*latestObject.Key)), nil
+}
+
 func (c *Client) detectContentType(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
 
 	contentTypes := map[string]string{
-		".txt":  "text/plain",
-		".html": "text/html",
-		".css":  "text/css",
-		".js":   "application/javascript",
-		".json": "application/json",
-		".xml":  "application/xml",
-		".pdf":  "application/pdf",
-		".zip":  "application/zip",
-		".tar":  "application/x-tar",
-		".gz":   "application/gzip",
-		".jpg":  "image/jpeg",
-		".jpeg": "image/jpeg",
-		".png":  "image/png",
-		".gif":  "image/gif",
-		".svg":  "image/svg+xml",
-		".mp3":  "audio/mpeg",
-		".mp4":  "video/mp4",
-		".avi":  "video/x-msvideo",
-		".mov":  "video/quicktime",
+		".txt":         "text/plain",
+		".html":        "text/html",
+		".htm":         "text/html",
+		".css":         "text/css",
+		".js":          "application/javascript",
+		".mjs":         "application/javascript",
+		".json":        "application/json",
+		".map":         "application/json",
+		".webmanifest": "application/manifest+json",
+		".xml":         "application/xml",
+		".pdf":         "application/pdf",
+		".zip":         "application/zip",
+		".tar":         "application/x-tar",
+		".gz":          "application/gzip",
+		".wasm":        "application/wasm",
+		".jpg":         "image/jpeg",
+		".jpeg":        "image/jpeg",
+		".png":         "image/png",
+		".gif":         "image/gif",
+		".svg":         "image/svg+xml",
+		".webp":        "image/webp",
+		".avif":        "image/avif",
+		".ico":         "image/x-icon",
+		".woff":        "font/woff",
+		".woff2":       "font/woff2",
+		".ttf":         "font/ttf",
+		".otf":         "font/otf",
+		".mp3":         "audio/mpeg",
+		".mp4":         "video/mp4",
+		".webm":        "video/webm",
+		".avi":         "video/x-msvideo",
+		".mov":         "video/quicktime",
 	}
 
 	if contentType, exists := contentTypes[ext]; exists {
@@ -524,3 +825,17 @@ func (c *Client) detectContentType(filename string) string {
 
 	return "application/octet-stream"
 }
+
+// hasErrorCode reports whether err is an S3 API error with one of the given codes.
+func hasErrorCode(err error, codes ...string) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.ErrorCode() == code {
+			return true
+		}
+	}
+	return false
+}
@@ -1,29 +1,52 @@
 package s3client
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
 	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"strings"
 	"testing"
 	"time"
 )
 
-// Integration tests for S3 client
-// These tests require a real S3 connection and are skipped by default
-// To run these tests, set the environment variable S3_INTEGRATION_TEST=true
+// Tests of the S3 client against a bucket. They run against the in-memory fake S3 by
+// default; set S3_INTEGRATION_TEST=true to run them against the real bucket configured
+// by the TEST_* environment variables instead.
+
+// testBucket returns the configuration of the bucket to test against, and the fake
+// server backing it unless a real bucket is used.
+func testBucket(t *testing.T) (*config.Config, *s3fake.Server) {
+	t.Helper()
+
+	if os.Getenv("S3_INTEGRATION_TEST") == "true" {
+		return &config.Config{
+			BucketName: os.Getenv("TEST_BUCKET_NAME"),
+			Region:     os.Getenv("TEST_REGION"),
+			ApiURL:     os.Getenv("TEST_API_URL"),
+			AccessKey:  os.Getenv("TEST_ACCESS_KEY"),
+			SecretKey:  os.Getenv("TEST_SECRET_KEY"),
+			Settings:   config.Settings{DeleteConcurrency: 1},
+		}, nil
+	}
+
+	server := s3fake.New("test-bucket")
+	t.Cleanup(server.Close)
+	return &config.Config{
+		BucketName: "test-bucket",
+		Region:     "us-east-1",
+		ApiURL:     server.URL(),
+		AccessKey:  "test",
+		SecretKey:  "test",
+		Settings:   config.Settings{DeleteConcurrency: 1},
+	}, server
+}
 
 func TestGetBucketInfo(t *testing.T) {
-	if os.Getenv("S3_INTEGRATION_TEST") != "true" {
-		t.Skip("Skipping integration test; set S3_INTEGRATION_TEST=true to run")
-	}
-
-	cfg := &config.Config{
-		BucketName: os.Getenv("TEST_BUCKET_NAME"),
-		Region:     os.Getenv("TEST_REGION"),
-		ApiURL:     os.Getenv("TEST_API_URL"),
-		AccessKey:  os.Getenv("TEST_ACCESS_KEY"),
-		SecretKey:  os.Getenv("TEST_SECRET_KEY"),
-	}
+	cfg, _ := testBucket(t)
 
 	client, err := New(cfg)
 	if err != nil {
@@ -41,24 +64,14 @@ func TestGetBucketInfo(t *testing.T) {
 }
 
 func TestDeleteOldFiles(t *testing.T) {
-	if os.Getenv("S3_INTEGRATION_TEST") != "true" {
-		t.Skip("Skipping integration test; set S3_INTEGRATION_TEST=true to run")
-	}
-
-	cfg := &config.Config{
-		BucketName: os.Getenv("TEST_BUCKET_NAME"),
-		Region:     os.Getenv("TEST_REGION"),
-		ApiURL:     os.Getenv("TEST_API_URL"),
-		AccessKey:  os.Getenv("TEST_ACCESS_KEY"),
-		SecretKey:  os.Getenv("TEST_SECRET_KEY"),
-	}
+	cfg, _ := testBucket(t)
 
 	client, err := New(cfg)
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
-	result, err := client.DeleteOldFiles(context.Background(), "test", 30, true)
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"test"}, DaysOld: 30, DryRun: true})
 	if err != nil {
 		t.Fatalf("DeleteOldFiles() error = %v", err)
 	}
@@ -77,17 +90,7 @@ func TestDeleteOldFiles(t *testing.T) {
 }
 
 func TestUploadFiles(t *testing.T) {
-	if os.Getenv("S3_INTEGRATION_TEST") != "true" {
-		t.Skip("Skipping integration test; set S3_INTEGRATION_TEST=true to run")
-	}
-
-	cfg := &config.Config{
-		BucketName: os.Getenv("TEST_BUCKET_NAME"),
-		Region:     os.Getenv("TEST_REGION"),
-		ApiURL:     os.Getenv("TEST_API_URL"),
-		AccessKey:  os.Getenv("TEST_ACCESS_KEY"),
-		SecretKey:  os.Getenv("TEST_SECRET_KEY"),
-	}
+	cfg, _ := testBucket(t)
 
 	client, err := New(cfg)
 	if err != nil {
@@ -109,7 +112,7 @@ func TestUploadFiles(t *testing.T) {
 	}
 
 	destinationPath := "test-" + time.Now().Format("20060102-150405")
-	result, err := client.UploadFiles(context.Background(), []string{tempFile.Name()}, destinationPath, false)
+	result, err := client.UploadFiles(context.Background(), []string{tempFile.Name()}, destinationPath, false, "", nil, time.Time{})
 	if err != nil {
 		t.Fatalf("UploadFiles() error = %v", err)
 	}
@@ -134,3 +137,89 @@ func TestUploadFiles(t *testing.T) {
 		t.Errorf("TotalSizeBytes = %d, want %d", result.TotalSizeBytes, len(content))
 	}
 }
+
+func TestUploadDownloadRoundTrip(t *testing.T) {
+	cfg, server := testBucket(t)
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "s3client-roundtrip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Larger than the 5MB part size, so it is uploaded and downloaded in parts
+	content := bytes.Repeat([]byte("0123456789abcdef"), 400*1024)
+	localPath := filepath.Join(dir, "backup.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	folder := "roundtrip-" + time.Now().Format("20060102-150405")
+	if _, err := client.UploadFiles(ctx, []string{localPath}, folder, false, "", nil, time.Time{}); err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	if server != nil {
+		obj, ok := server.Object(cfg.BucketName, folder+"/backup.bin")
+		if !ok || !strings.HasSuffix(obj.ETag, "-2") {
+			t.Errorf("uploaded object = %v (found %v), want a 2-part multipart upload", obj.ETag, ok)
+		}
+		if server.Uploads() != 0 {
+			t.Errorf("%d multipart uploads left open", server.Uploads())
+		}
+	}
+
+	downloadDir := filepath.Join(dir, "download")
+	result, err := client.DownloadLatestFile(ctx, folder, downloadDir, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("DownloadLatestFile() error = %v", err)
+	}
+	downloaded, err := os.ReadFile(result.Items[0].LocalPath)
+	if err != nil || !bytes.Equal(downloaded, content) {
+		t.Errorf("downloaded %d bytes (%v), want the %d uploaded bytes", len(downloaded), err, len(content))
+	}
+	if item := result.Items[0]; item.StorageClass == "" || item.ETag == "" {
+		t.Errorf("item = %+v, want the storage class and ETag of the object", item)
+	}
+
+	deleted, err := client.DeleteOldFiles(ctx, DeleteOptions{Folders: []string{folder}})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if deleted.DeletedCount != 1 {
+		t.Errorf("DeletedCount = %d, want the uploaded file deleted", deleted.DeletedCount)
+	}
+}
+
+func TestDeleteOldFilesKeepsRecent(t *testing.T) {
+	cfg, server := testBucket(t)
+	if server == nil {
+		t.Skip("needs objects with old modification times, which only the fake can create")
+	}
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	old := time.Now().AddDate(0, 0, -45)
+	for i := 0; i < 1500; i++ {
+		server.PutObject(cfg.BucketName, fmt.Sprintf("logs/old-%04d.log", i), []byte("x"), old)
+	}
+	server.PutObject(cfg.BucketName, "logs/today.log", []byte("x"), time.Now())
+	server.PutObject(cfg.BucketName, "db/old.sql", []byte("x"), old)
+
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 1500 {
+		t.Errorf("DeletedCount = %d, want 1500 over two list pages and delete batches", result.DeletedCount)
+	}
+	if keys := server.Keys(cfg.BucketName); strings.Join(keys, ",") != "db/old.sql,logs/today.log" {
+		t.Errorf("remaining keys = %v", keys)
+	}
+}
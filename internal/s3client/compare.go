@@ -0,0 +1,247 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	DifferenceMissing  = "missing"
+	DifferenceExtra    = "extra"
+	DifferenceSize     = "size"
+	DifferenceETag     = "etag"
+	DifferenceChecksum = "checksum"
+)
+
+// CompareOptions select the objects Compare looks at and how their content is compared.
+type CompareOptions struct {
+	Prefix string
+	// Checksums compares the additional checksums of objects whose ETags differ, which
+	// happens for equal content uploaded with another part size or encryption
+	Checksums bool
+}
+
+type comparePair struct {
+	source, dest types.Object
+}
+
+// Compare checks that the objects under opts.Prefix in the bucket of c are in the bucket
+// of dest with the same size and ETag, and reports the keys missing from dest, the extra
+// keys only dest has and the objects that differ. Objects in the trash are ignored.
+func (c *Client) Compare(ctx context.Context, dest *Client, opts CompareOptions) (*models.CompareResult, error) {
+	startTime := time.Now()
+
+	sourceObjects, err := c.listObjects(ctx, opts.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.config.BucketName, err)
+	}
+	destObjects, err := dest.listObjects(ctx, opts.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dest.config.BucketName, err)
+	}
+
+	result := &models.CompareResult{
+		SourceBucket:  c.config.BucketName,
+		DestBucket:    dest.config.BucketName,
+		Prefix:        opts.Prefix,
+		Differences:   []models.CompareItem{},
+		OperationTime: utils.FormatTime(startTime),
+	}
+
+	remaining := make(map[string]types.Object, len(destObjects))
+	for _, obj := range destObjects {
+		if key := aws.ToString(obj.Key); !dest.inTrash(key) {
+			remaining[key] = obj
+			result.DestCount++
+		}
+	}
+
+	var etagMismatches []comparePair
+	for _, obj := range sourceObjects {
+		key := aws.ToString(obj.Key)
+		if c.inTrash(key) {
+			continue
+		}
+		result.SourceCount++
+
+		destObj, ok := remaining[key]
+		delete(remaining, key)
+		item := models.CompareItem{
+			Key:        key,
+			SourceSize: aws.ToInt64(obj.Size),
+			SourceETag: objectETag(obj),
+		}
+		switch {
+		case !ok:
+			item.Difference = DifferenceMissing
+		case aws.ToInt64(obj.Size) != aws.ToInt64(destObj.Size):
+			item.Difference = DifferenceSize
+		case objectETag(obj) != objectETag(destObj) && opts.Checksums:
+			etagMismatches = append(etagMismatches, comparePair{source: obj, dest: destObj})
+			continue
+		case objectETag(obj) != objectETag(destObj):
+			item.Difference = DifferenceETag
+		default:
+			result.MatchingCount++
+			continue
+		}
+		if ok {
+			item.DestSize, item.DestETag = aws.ToInt64(destObj.Size), objectETag(destObj)
+		}
+		result.Differences = append(result.Differences, item)
+	}
+
+	for key, obj := range remaining {
+		result.Differences = append(result.Differences, models.CompareItem{
+			Key:        key,
+			Difference: DifferenceExtra,
+			DestSize:   aws.ToInt64(obj.Size),
+			DestETag:   objectETag(obj),
+		})
+	}
+
+	if len(etagMismatches) > 0 {
+		compared, err := c.compareChecksums(ctx, dest, etagMismatches)
+		if err != nil {
+			return nil, err
+		}
+		result.ChecksumCount = len(etagMismatches)
+		result.MatchingCount += len(etagMismatches) - len(compared)
+		result.Differences = append(result.Differences, compared...)
+	}
+
+	sort.Slice(result.Differences, func(i, j int) bool {
+		return result.Differences[i].Key < result.Differences[j].Key
+	})
+	for _, item := range result.Differences {
+		switch item.Difference {
+		case DifferenceMissing:
+			result.MissingCount++
+		case DifferenceExtra:
+			result.ExtraCount++
+		default:
+			result.DifferentCount++
+		}
+	}
+	result.Consistent = len(result.Differences) == 0
+	return result, nil
+}
+
+// compareChecksums reads the additional checksums of both objects of pairs and returns the
+// differences: checksum when both have a checksum of the same algorithm that differs,
+// etag when there is none to compare.
+func (c *Client) compareChecksums(ctx context.Context, dest *Client, pairs []comparePair) ([]models.CompareItem, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	var differences []models.CompareItem
+	sem := make(chan struct{}, c.settings().RequestConcurrency)
+
+	for _, pair := range pairs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(pair comparePair) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			key := aws.ToString(pair.source.Key)
+			sourceChecksum, err := c.objectChecksum(ctx, key)
+			var destChecksum string
+			if err == nil {
+				destChecksum, err = dest.objectChecksum(ctx, key)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			if sourceChecksum != "" && sourceChecksum == destChecksum {
+				return
+			}
+			item := models.CompareItem{
+				Key:            key,
+				Difference:     DifferenceETag,
+				SourceSize:     aws.ToInt64(pair.source.Size),
+				DestSize:       aws.ToInt64(pair.dest.Size),
+				SourceETag:     objectETag(pair.source),
+				DestETag:       objectETag(pair.dest),
+				SourceChecksum: sourceChecksum,
+				DestChecksum:   destChecksum,
+			}
+			if sameChecksumAlgorithm(sourceChecksum, destChecksum) {
+				item.Difference = DifferenceChecksum
+			}
+			differences = append(differences, item)
+		}(pair)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return differences, nil
+}
+
+// objectChecksum returns the strongest additional checksum of key as algorithm:value,
+// "" when the object has none.
+func (c *Client) objectChecksum(ctx context.Context, key string) (string, error) {
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(c.config.BucketName),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = c.sseCustomerKey.headers()
+	head, err := c.s3Client.HeadObject(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksum of %s in %s: %w", key, c.config.BucketName, err)
+	}
+
+	checksums := []struct {
+		algorithm string
+		value     *string
+	}{
+		{"sha256", head.ChecksumSHA256},
+		{"sha1", head.ChecksumSHA1},
+		{"crc64nvme", head.ChecksumCRC64NVME},
+		{"crc32c", head.ChecksumCRC32C},
+		{"crc32", head.ChecksumCRC32},
+	}
+	for _, checksum := range checksums {
+		if value := aws.ToString(checksum.value); value != "" {
+			return checksum.algorithm + ":" + value, nil
+		}
+	}
+	return "", nil
+}
+
+func sameChecksumAlgorithm(a, b string) bool {
+	algorithmA, _, okA := strings.Cut(a, ":")
+	algorithmB, _, okB := strings.Cut(b, ":")
+	return okA && okB && algorithmA == algorithmB
+}
@@ -0,0 +1,79 @@
+package s3client
+
+import (
+	"context"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	fake := s3fake.New("source", "dest")
+	defer fake.Close()
+	source := newTestClient(t, fake, func(cfg *config.Config) { cfg.BucketName = "source" })
+	dest := newTestClient(t, fake, func(cfg *config.Config) { cfg.BucketName = "dest" })
+
+	now := time.Now()
+	for _, bucket := range []string{"source", "dest"} {
+		fake.PutObject(bucket, "db/same.sql", []byte("same"), now)
+		fake.PutObject(bucket, "db/retagged.sql", []byte("retagged"), now)
+		fake.PutObject(bucket, "other/outside.sql", []byte("outside"), now)
+	}
+	fake.SetETag("dest", "db/retagged.sql", `"0123456789abcdef0123456789abcdef-2"`)
+	fake.PutObject("source", "db/missing.sql", []byte("missing"), now)
+	fake.PutObject("source", "db/size.sql", []byte("short"), now)
+	fake.PutObject("dest", "db/size.sql", []byte("longer"), now)
+	fake.PutObject("dest", "db/extra.sql", []byte("extra"), now)
+
+	for _, checksums := range []bool{false, true} {
+		result, err := source.Compare(context.Background(), dest, CompareOptions{Prefix: "db/", Checksums: checksums})
+		if err != nil {
+			t.Fatalf("Compare() error = %v", err)
+		}
+		if result.Consistent {
+			t.Error("Consistent = true, want false")
+		}
+
+		want := map[string]string{
+			"db/extra.sql":    DifferenceExtra,
+			"db/missing.sql":  DifferenceMissing,
+			"db/retagged.sql": DifferenceETag,
+			"db/size.sql":     DifferenceSize,
+		}
+		if len(result.Differences) != len(want) {
+			t.Fatalf("Differences = %+v, want %v", result.Differences, want)
+		}
+		for _, item := range result.Differences {
+			if want[item.Key] != item.Difference {
+				t.Errorf("%s: Difference = %q, want %q", item.Key, item.Difference, want[item.Key])
+			}
+		}
+		if result.SourceCount != 4 || result.DestCount != 4 || result.MatchingCount != 1 {
+			t.Errorf("counts = %d source, %d dest, %d matching, want 4, 4, 1", result.SourceCount, result.DestCount, result.MatchingCount)
+		}
+		if result.MissingCount != 1 || result.ExtraCount != 1 || result.DifferentCount != 2 {
+			t.Errorf("counts = %d missing, %d extra, %d different, want 1, 1, 2", result.MissingCount, result.ExtraCount, result.DifferentCount)
+		}
+		if wantChecked := map[bool]int{false: 0, true: 1}[checksums]; result.ChecksumCount != wantChecked {
+			t.Errorf("ChecksumCount = %d, want %d", result.ChecksumCount, wantChecked)
+		}
+	}
+
+	result, err := source.Compare(context.Background(), dest, CompareOptions{Prefix: "other/"})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if !result.Consistent || result.MatchingCount != 1 {
+		t.Errorf("Compare(other/) = %+v, want one matching object", result)
+	}
+}
+
+func TestSameChecksumAlgorithm(t *testing.T) {
+	if !sameChecksumAlgorithm("sha256:abc", "sha256:def") {
+		t.Error("sha256 and sha256 are not the same algorithm")
+	}
+	if sameChecksumAlgorithm("sha256:abc", "crc32:def") || sameChecksumAlgorithm("", "crc32:def") {
+		t.Error("different or missing algorithms are the same")
+	}
+}
@@ -0,0 +1,243 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// maxCopyPartSize is the largest part UploadPartCopy copies in one request.
+const maxCopyPartSize = 5 * 1024 * 1024 * 1024
+
+// maxCopyObjectSize is the largest object CopyObject copies, larger ones are copied in
+// parts. Tests lower it to avoid 5GB objects.
+var maxCopyObjectSize int64 = maxCopyPartSize
+
+// composer assembles an object as a multipart upload from objects of the bucket, copied
+// on the server, and from data sent by the client. Every part but the last must be at
+// least 5MB, so objects smaller than that, and the ends of objects that would leave too
+// small a part, are downloaded and uploaded again together with their neighbours.
+type composer struct {
+	c        *Client
+	key      string
+	uploadID string
+	partSize int64
+	parts    []types.CompletedPart
+	pending  []byte
+	// sourceSSE decrypts the objects that are added, sse encrypts the parts; both are nil
+	// without SSE-C
+	sourceSSE, sse *SSECustomerKey
+	// copied is the number of bytes copied on the server, downloaded the number read
+	// from objects of the bucket, uploaded the number sent in parts
+	copied, downloaded, uploaded int64
+}
+
+// newComposer starts the multipart upload of input, encrypted with sse. sourceSSE
+// decrypts the objects added to it.
+func (c *Client) newComposer(ctx context.Context, input *s3.CreateMultipartUploadInput, sourceSSE, sse *SSECustomerKey) (*composer, error) {
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = sse.headers()
+	resp, err := c.s3Client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the upload of %s: %w", aws.ToString(input.Key), err)
+	}
+	partSize, _ := c.transferSettings(c.settings().PartSize, 1, 1)
+	return &composer{
+		c:         c,
+		key:       aws.ToString(input.Key),
+		uploadID:  aws.ToString(resp.UploadId),
+		partSize:  partSize,
+		pending:   make([]byte, 0, partSize),
+		sourceSSE: sourceSSE,
+		sse:       sse,
+	}, nil
+}
+
+// addObject appends the object key of size bytes, which must still have etag.
+func (m *composer) addObject(ctx context.Context, key, etag string, size int64) error {
+	if int64(len(m.pending)) >= manager.MinUploadPartSize {
+		if err := m.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	var offset int64
+	if len(m.pending) > 0 {
+		// top the pending data up to a full part with the start of the object, unless
+		// the rest would be too small to copy
+		need := manager.MinUploadPartSize - int64(len(m.pending))
+		if size-need < manager.MinUploadPartSize {
+			return m.download(ctx, key, etag, 0, size)
+		}
+		if err := m.download(ctx, key, etag, 0, need); err != nil {
+			return err
+		}
+		if err := m.flush(ctx); err != nil {
+			return err
+		}
+		offset = need
+	} else if size < manager.MinUploadPartSize {
+		return m.download(ctx, key, etag, 0, size)
+	}
+
+	// equal parts of at most 5GB, each at least as large as the 5MB left at the end
+	remaining := size - offset
+	count := (remaining + maxCopyPartSize - 1) / maxCopyPartSize
+	partSize := (remaining + count - 1) / count
+	for start := offset; start < size; start += partSize {
+		end := min(start+partSize, size)
+		if err := m.checkPartCount(); err != nil {
+			return err
+		}
+		number := int32(len(m.parts) + 1)
+		input := &s3.UploadPartCopyInput{
+			Bucket:            aws.String(m.c.config.BucketName),
+			Key:               aws.String(m.key),
+			UploadId:          aws.String(m.uploadID),
+			PartNumber:        aws.Int32(number),
+			CopySource:        aws.String(copySource(m.c.config.BucketName, key)),
+			CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1)),
+			CopySourceIfMatch: aws.String(etag),
+		}
+		input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5 = m.sourceSSE.headers()
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = m.sse.headers()
+		resp, err := m.c.s3Client.UploadPartCopy(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", key, err)
+		}
+		var partETag *string
+		if resp.CopyPartResult != nil {
+			partETag = resp.CopyPartResult.ETag
+		}
+		m.parts = append(m.parts, types.CompletedPart{ETag: partETag, PartNumber: aws.Int32(number)})
+		m.copied += end - start
+	}
+	return nil
+}
+
+// download appends the bytes from start to end of the object key to the pending data.
+func (m *composer) download(ctx context.Context, key, etag string, start, end int64) error {
+	if start == end {
+		return nil
+	}
+	input := &s3.GetObjectInput{
+		Bucket:  aws.String(m.c.config.BucketName),
+		Key:     aws.String(key),
+		Range:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1)),
+		IfMatch: aws.String(etag),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = m.sourceSSE.headers()
+	resp, err := m.c.s3Client.GetObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if int64(len(data)) != end-start {
+		return fmt.Errorf("failed to read %s: got %d bytes instead of %d", key, len(data), end-start)
+	}
+	m.pending = append(m.pending, data...)
+	m.downloaded += int64(len(data))
+	if int64(len(m.pending)) >= m.partSize {
+		return m.flush(ctx)
+	}
+	return nil
+}
+
+// addReader appends the content of r and returns its length.
+func (m *composer) addReader(ctx context.Context, r io.Reader) (int64, error) {
+	var total int64
+	for {
+		if int64(len(m.pending)) >= m.partSize {
+			if err := m.flush(ctx); err != nil {
+				return total, err
+			}
+		}
+		start := len(m.pending)
+		n, err := io.ReadFull(r, m.pending[start:m.partSize])
+		m.pending = m.pending[:start+n]
+		total += int64(n)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// flush uploads the pending data as the next part.
+func (m *composer) flush(ctx context.Context) error {
+	if len(m.pending) == 0 {
+		return nil
+	}
+	if err := m.checkPartCount(); err != nil {
+		return err
+	}
+	number := int32(len(m.parts) + 1)
+	input := &s3.UploadPartInput{
+		Bucket:        aws.String(m.c.config.BucketName),
+		Key:           aws.String(m.key),
+		UploadId:      aws.String(m.uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          bytes.NewReader(m.pending),
+		ContentLength: aws.Int64(int64(len(m.pending))),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = m.sse.headers()
+	resp, err := m.c.s3Client.UploadPart(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d of %s: %w", number, m.key, err)
+	}
+	m.parts = append(m.parts, types.CompletedPart{ETag: resp.ETag, PartNumber: aws.Int32(number)})
+	m.uploaded += int64(len(m.pending))
+	m.pending = m.pending[:0]
+	return nil
+}
+
+func (m *composer) checkPartCount() error {
+	if len(m.parts) >= int(manager.MaxUploadParts) {
+		return fmt.Errorf("%s would have more than %d parts, raise UPLOAD_PART_SIZE", m.key, manager.MaxUploadParts)
+	}
+	return nil
+}
+
+// complete uploads the pending data and completes the upload. A non-empty ifMatch only
+// replaces the object with that ETag, create only creates the object when it does not
+// exist, so that the object is not overwritten when another writer changed it meanwhile.
+// It returns the ETag of the object.
+func (m *composer) complete(ctx context.Context, ifMatch string, create bool) (string, error) {
+	if err := m.flush(ctx); err != nil {
+		return "", err
+	}
+	input := &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(m.c.config.BucketName),
+		Key:             aws.String(m.key),
+		UploadId:        aws.String(m.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: m.parts},
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = m.sse.headers()
+	if ifMatch != "" {
+		input.IfMatch = aws.String(ifMatch)
+	}
+	if create {
+		input.IfNoneMatch = aws.String("*")
+	}
+	resp, err := m.c.s3Client.CompleteMultipartUpload(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(resp.ETag), nil
+}
+
+// abort discards the parts uploaded so far.
+func (m *composer) abort() {
+	m.c.abortMultipartUpload(m.key, m.uploadID)
+}
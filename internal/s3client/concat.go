@@ -0,0 +1,155 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// ConcatOptions configure Concat.
+type ConcatOptions struct {
+	// Prefix adds the objects under it, in key order, after the sources given by key
+	Prefix string
+	// DeleteSources removes the sources once the destination is stored
+	DeleteSources bool
+}
+
+// concatSource is an object that is part of a concatenation.
+type concatSource struct {
+	key, etag string
+	size      int64
+}
+
+// Concat stores the sources, one after the other, as the object destination. The sources
+// are copied on the server as parts of a multipart upload; sources smaller than 5MB,
+// which S3 does not accept as parts, are downloaded and uploaded together with their
+// neighbours. Sources that change while they are copied fail the concatenation.
+func (c *Client) Concat(ctx context.Context, destination string, keys []string, opts ConcatOptions) (*models.ConcatResult, error) {
+	startTime := time.Now()
+
+	var sources []concatSource
+	for _, key := range keys {
+		head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(c.config.BucketName),
+			Key:    aws.String(key),
+		})
+		if hasErrorCode(err, "NotFound", "NoSuchKey") {
+			return nil, fmt.Errorf("source %s does not exist", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", key, err)
+		}
+		sources = append(sources, concatSource{key: key, etag: aws.ToString(head.ETag), size: aws.ToInt64(head.ContentLength)})
+	}
+	if opts.Prefix != "" {
+		objects, err := c.listObjects(ctx, opts.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(objects, func(a, b types.Object) int { return strings.Compare(aws.ToString(a.Key), aws.ToString(b.Key)) })
+		for _, obj := range objects {
+			key := aws.ToString(obj.Key)
+			if key == destination || c.inTrash(key) {
+				continue
+			}
+			sources = append(sources, concatSource{key: key, etag: aws.ToString(obj.ETag), size: aws.ToInt64(obj.Size)})
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no objects to concatenate")
+	}
+
+	result := &models.ConcatResult{
+		BucketName:     c.config.BucketName,
+		DestinationKey: destination,
+		Sources:        make([]models.ConcatSource, 0, len(sources)),
+		OperationTime:  utils.FormatTime(startTime),
+	}
+	var deletes []string
+	var protected []string
+	// the destination may be one of the sources, it must not change while it is read
+	destinationETag := ""
+	for _, source := range sources {
+		result.Sources = append(result.Sources, models.ConcatSource{Key: source.key, SizeBytes: source.size})
+		result.SizeBytes += source.size
+		if source.key == destination {
+			destinationETag = source.etag
+			continue
+		}
+		if opts.DeleteSources && !slices.Contains(deletes, source.key) {
+			deletes = append(deletes, source.key)
+			if c.isProtected(source.key) {
+				protected = append(protected, source.key)
+			}
+		}
+	}
+	if len(protected) > 0 {
+		return nil, fmt.Errorf("%w: cannot delete %d protected sources (%s), use --allow-protected to delete them anyway",
+			ErrProtected, len(protected), strings.Join(firstKeys(protected), ", "))
+	}
+
+	contentType := aws.String(c.detectContentType(destination))
+	if result.SizeBytes == 0 {
+		// a multipart upload needs at least one part
+		resp, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(c.config.BucketName),
+			Key:         aws.String(destination),
+			Body:        bytes.NewReader(nil),
+			ContentType: contentType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", destination, err)
+		}
+		result.ETag = strings.Trim(aws.ToString(resp.ETag), `"`)
+	} else {
+		m, err := c.newComposer(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      aws.String(c.config.BucketName),
+			Key:         aws.String(destination),
+			ContentType: contentType,
+		}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range sources {
+			if err := m.addObject(ctx, source.key, source.etag, source.size); err != nil {
+				m.abort()
+				return nil, conflictError(source.key, err)
+			}
+		}
+		etag, err := m.complete(ctx, destinationETag, false)
+		if err != nil {
+			m.abort()
+			return nil, conflictError(destination, fmt.Errorf("failed to store %s: %w", destination, err))
+		}
+		result.CopiedBytes = m.copied
+		result.DownloadedBytes = m.downloaded
+		result.Parts = len(m.parts)
+		result.ETag = strings.Trim(etag, `"`)
+	}
+
+	if len(deletes) > 0 {
+		objects := make([]types.ObjectIdentifier, 0, len(deletes))
+		for _, key := range deletes {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+		deleted, err := c.removeObjects(ctx, objects, nil)
+		result.DeletedSources = deleted
+		if err != nil {
+			return nil, fmt.Errorf("stored %s but failed to delete its sources: %w", destination, err)
+		}
+	}
+
+	result.SizeHuman = utils.FormatBytes(result.SizeBytes)
+	result.Duration = time.Since(startTime).Truncate(time.Millisecond).String()
+	return result, nil
+}
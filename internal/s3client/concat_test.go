@@ -0,0 +1,82 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestConcat(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+
+	// small chunks around a large one, which is copied on the server but for its
+	// start, which tops the first chunk up to a full part
+	large := bytes.Repeat([]byte("0123456789"), 1200*1024)
+	fake.PutObject("test-bucket", "export/part-1.csv", []byte("a,b\n"), time.Now())
+	fake.PutObject("test-bucket", "export/part-2.csv", large, time.Now())
+	fake.PutObject("test-bucket", "export/part-3.csv", []byte("y,z\n"), time.Now())
+
+	result, err := client.Concat(context.Background(), "export.csv", nil, ConcatOptions{Prefix: "export/", DeleteSources: true})
+	if err != nil {
+		t.Fatalf("Concat() error = %v", err)
+	}
+	want := append(append([]byte("a,b\n"), large...), "y,z\n"...)
+	obj, ok := fake.Object("test-bucket", "export.csv")
+	if !ok || !bytes.Equal(obj.Data, want) {
+		t.Fatalf("object has %d bytes, want %d", len(obj.Data), len(want))
+	}
+	if result.SizeBytes != int64(len(want)) || len(result.Sources) != 3 || result.CopiedBytes < int64(len(large))-5*1024*1024 {
+		t.Errorf("Concat() = %+v, want 3 sources partly copied on the server", result)
+	}
+	if len(result.DeletedSources) != 3 || len(fake.Keys("test-bucket")) != 1 {
+		t.Errorf("deleted %v, bucket holds %v", result.DeletedSources, fake.Keys("test-bucket"))
+	}
+	if fake.Uploads() != 0 {
+		t.Errorf("%d multipart uploads left behind", fake.Uploads())
+	}
+}
+
+func TestConcatOntoSource(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	fake.PutObject("test-bucket", "a.log", []byte("a\n"), time.Now())
+	fake.PutObject("test-bucket", "b.log", []byte("b\n"), time.Now())
+
+	if _, err := client.Concat(context.Background(), "a.log", []string{"a.log", "b.log"}, ConcatOptions{DeleteSources: true}); err != nil {
+		t.Fatalf("Concat() error = %v", err)
+	}
+	if obj, _ := fake.Object("test-bucket", "a.log"); string(obj.Data) != "a\nb\n" {
+		t.Errorf("a.log holds %q", obj.Data)
+	}
+	if _, ok := fake.Object("test-bucket", "b.log"); ok {
+		t.Error("b.log was not deleted")
+	}
+
+	if _, err := client.Concat(context.Background(), "c.log", []string{"a.log", "missing.log"}, ConcatOptions{}); err == nil {
+		t.Error("Concat() of a missing source succeeded")
+	}
+}
+
+func TestConcatProtectedSources(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) {
+		cfg.ProtectedPrefixes = []string{"keep/"}
+	})
+	fake.PutObject("test-bucket", "keep/a.log", []byte("a\n"), time.Now())
+
+	_, err := client.Concat(context.Background(), "all.log", []string{"keep/a.log"}, ConcatOptions{DeleteSources: true})
+	if !errors.Is(err, ErrProtected) {
+		t.Fatalf("Concat() error = %v, want ErrProtected", err)
+	}
+	if _, ok := fake.Object("test-bucket", "all.log"); ok {
+		t.Error("all.log was stored")
+	}
+}
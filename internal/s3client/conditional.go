@@ -0,0 +1,54 @@
+package s3client
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/signing"
+)
+
+// DownloadOptions make a download conditional on the object having changed since the
+// caller last fetched it, like the If-None-Match and If-Modified-Since request headers.
+type DownloadOptions struct {
+	// IfNoneMatch skips the download when the object still has this ETag. Quotes are
+	// optional and "*" matches any object.
+	IfNoneMatch string
+	// IfModifiedSince skips the download when the object was not modified after this
+	// time. It is ignored when IfNoneMatch is set, as in HTTP.
+	IfModifiedSince time.Time
+	// Verifier, when set, checks the downloaded file against the detached signature
+	// stored next to the object. A file that fails the check is removed again.
+	Verifier *signing.Verifier
+	// VerifyChecksum compares the checksum of the downloaded file with that of the object
+	// before the file is moved into place
+	VerifyChecksum bool
+	// Regex limits the download to the keys it matches: the latest matching file, or
+	// every matching file of DownloadFolder
+	Regex *regexp.Regexp
+
+	// Flatten makes DownloadFolder put every file directly into the destination instead
+	// of recreating the folders below the downloaded one
+	Flatten bool
+	// OnConflict decides what DownloadFolder does when a local file already exists, or
+	// when two keys flatten to the same name: ConflictOverwrite (default), ConflictSkip
+	// or ConflictRename
+	OnConflict string
+}
+
+// unchanged reports whether obj matches the conditions, so downloading it again would
+// fetch the same content.
+func (o DownloadOptions) unchanged(obj types.Object) bool {
+	if o.IfNoneMatch != "" {
+		etag := strings.Trim(o.IfNoneMatch, `"`)
+		return etag == "*" || etag == objectETag(obj)
+	}
+	if !o.IfModifiedSince.IsZero() {
+		// Last-Modified has a resolution of one second
+		return !aws.ToTime(obj.LastModified).Truncate(time.Second).After(o.IfModifiedSince)
+	}
+	return false
+}
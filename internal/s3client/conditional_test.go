@@ -0,0 +1,63 @@
+package s3client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestDownloadLatestFileConditional(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	modified := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	fake.PutObject("test-bucket", "config/app.yaml", []byte("port: 8080\n"), modified)
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	first, err := client.DownloadLatestFile(ctx, "config", dir, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("DownloadLatestFile() error = %v", err)
+	}
+	etag := first.Items[0].ETag
+	if first.NotModified || etag == "" {
+		t.Fatalf("first download = %+v, want the object with its ETag", first)
+	}
+
+	tests := []struct {
+		name        string
+		opts        DownloadOptions
+		notModified bool
+	}{
+		{"same etag", DownloadOptions{IfNoneMatch: etag}, true},
+		{"quoted etag", DownloadOptions{IfNoneMatch: `"` + etag + `"`}, true},
+		{"any etag", DownloadOptions{IfNoneMatch: "*"}, true},
+		{"other etag", DownloadOptions{IfNoneMatch: "0123456789abcdef"}, false},
+		{"not modified since", DownloadOptions{IfModifiedSince: modified}, true},
+		{"modified since", DownloadOptions{IfModifiedSince: modified.Add(-time.Second)}, false},
+		{"etag wins over date", DownloadOptions{IfNoneMatch: "0123456789abcdef", IfModifiedSince: modified}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "config")
+			result, err := client.DownloadLatestFile(ctx, "config", dest, tt.opts)
+			if err != nil {
+				t.Fatalf("DownloadLatestFile() error = %v", err)
+			}
+			if result.NotModified != tt.notModified {
+				t.Errorf("NotModified = %t, want %t", result.NotModified, tt.notModified)
+			}
+			_, statErr := os.Stat(filepath.Join(dest, "app.yaml"))
+			if downloaded := statErr == nil; downloaded == tt.notModified {
+				t.Errorf("file downloaded = %t, want %t", downloaded, !tt.notModified)
+			}
+			if tt.notModified && (result.TotalFiles != 0 || result.Items[0].ETag != etag) {
+				t.Errorf("result = %+v, want no files and the current object", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Copy copies the object at sourceKey to destinationKey on the server. sourceSSEKey
+// decrypts a source encrypted with SSE-C, the copy is encrypted with the client's key.
+// Copying an object onto itself with a different key rotates its key. Objects larger
+// than 5GB, which CopyObject cannot copy, are copied as a multipart upload of part
+// copies with their headers and tags.
+func (c *Client) Copy(ctx context.Context, sourceKey, destinationKey string, sourceSSEKey *SSECustomerKey) (*models.CopyResult, error) {
+	if sourceKey == destinationKey && sourceSSEKey == nil && c.sseCustomerKey == nil {
+		return nil, fmt.Errorf("cannot copy %s onto itself without changing its encryption key", sourceKey)
+	}
+	startTime := time.Now()
+	result := &models.CopyResult{
+		BucketName:     c.config.BucketName,
+		SourceKey:      sourceKey,
+		DestinationKey: destinationKey,
+		SSECustomerKey: c.sseCustomerKey != nil,
+		OperationTime:  utils.FormatTime(startTime),
+	}
+
+	head, err := c.headEncryptedObject(ctx, sourceKey, sourceSSEKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", sourceKey, err)
+	}
+	if aws.ToInt64(head.ContentLength) > maxCopyObjectSize {
+		// a copy onto itself must not overwrite a newer object written meanwhile
+		ifMatch := ""
+		if sourceKey == destinationKey {
+			ifMatch = aws.ToString(head.ETag)
+		}
+		etag, err := c.copyInParts(ctx, sourceKey, destinationKey, head, headersOf(head), ifMatch, sourceSSEKey, c.sseCustomerKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy %s to %s: %w", sourceKey, destinationKey, err)
+		}
+		result.ETag = strings.Trim(etag, `"`)
+		result.Duration = time.Since(startTime).Truncate(time.Millisecond).String()
+		return result, nil
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(c.config.BucketName),
+		Key:        aws.String(destinationKey),
+		CopySource: aws.String(copySource(c.config.BucketName, sourceKey)),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = c.sseCustomerKey.headers()
+	input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5 = sourceSSEKey.headers()
+
+	resp, err := c.s3Client.CopyObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy %s to %s: %w", sourceKey, destinationKey, err)
+	}
+
+	result.Duration = time.Since(startTime).Truncate(time.Millisecond).String()
+	if resp.CopyObjectResult != nil {
+		result.ETag = strings.Trim(aws.ToString(resp.CopyObjectResult.ETag), `"`)
+	}
+	return result, nil
+}
@@ -0,0 +1,415 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/filter"
+	"s3manager/internal/journal"
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+// ErrMaxDeleteExceeded is returned when a deletion would remove more objects than the
+// configured MaxDelete limit. Nothing is deleted in that case.
+var ErrMaxDeleteExceeded = storage.ErrMaxDeleteExceeded
+
+type DeleteOptions struct {
+	// Folders are searched for old objects, the whole bucket when there is none
+	Folders []string
+	DaysOld int
+	DryRun  bool
+	// Tags limits the deletion to objects carrying all of these tags.
+	Tags map[string]string
+	// UnusedFor limits the deletion to objects neither written nor read for this long,
+	// according to the server access logs.
+	UnusedFor time.Duration
+	// Exclude keeps the objects matching one of these globs, such as manifests stored
+	// next to the data, however old they are.
+	Exclude []string
+	// DateFromKey dates objects by the date in their key instead of LastModified, which
+	// lifecycle rewrites and replication reset. Objects without a date are kept.
+	DateFromKey *storage.KeyDate
+	// Regex limits the deletion to the keys it matches, for naming conventions globs
+	// cannot express.
+	Regex *regexp.Regexp
+	// Filter limits the deletion to the objects matching the expression, whose ages are
+	// those of LastModified.
+	Filter *filter.Filter
+	// Window limits the deletion to objects modified within it, its upper bound is an
+	// absolute cutoff that applies in addition to DaysOld.
+	Window storage.TimeWindow
+	// Journal records the candidate list and deleted batches so an interrupted run can resume.
+	Journal *journal.Journal
+}
+
+// cutoff returns the time objects must have been last modified before to be deleted.
+func (opts DeleteOptions) cutoff(now time.Time) time.Time {
+	cutoff := now.AddDate(0, 0, -opts.DaysOld)
+	if unused := now.Add(-opts.UnusedFor); unused.Before(cutoff) {
+		cutoff = unused
+	}
+	return opts.Window.Cutoff(cutoff)
+}
+
+// due reports whether the object matches Regex and Filter and is dated before cutoff and
+// within the window. dated is false when DateFromKey finds no date in its key.
+func (opts DeleteOptions) due(obj types.Object, cutoff time.Time) (due, dated bool) {
+	key := aws.ToString(obj.Key)
+	if !storage.MatchesRegex(key, opts.Regex) || !opts.Filter.Match(filterObject(obj)) {
+		return false, true
+	}
+	modified, ok := opts.DateFromKey.Modified(key, aws.ToTime(obj.LastModified))
+	if !ok {
+		return false, false
+	}
+	return modified.Before(cutoff) && opts.Window.Contains(modified), true
+}
+
+func (c *Client) DeleteOldFiles(ctx context.Context, opts DeleteOptions) (*models.DeleteResult, error) {
+	bucketName := c.config.BucketName
+	now := time.Now()
+	cutoffDate := opts.cutoff(now)
+
+	var candidates []journal.Entry
+	undatedCount := 0
+	resumed := opts.Journal.Resumed()
+	if resumed {
+		planned, meta := opts.Journal.Plan()
+		candidates = planned
+		if cutoff, err := time.Parse(time.RFC3339, meta["cutoff_date"]); err == nil {
+			cutoffDate = cutoff
+		}
+	} else {
+		for _, prefix := range storage.FolderPrefixes(opts.Folders) {
+			old, undated, err := c.listOlderThan(ctx, prefix, cutoffDate, opts)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, old...)
+			undatedCount += undated
+		}
+		var err error
+		// A resumed journal already holds the filtered candidates
+		candidates, err = filterOld(ctx, c, candidates, func(e journal.Entry) string { return e.Key }, opts, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Filtered on resume too, in case the protected prefixes changed since planning
+	entryKey := func(e journal.Entry) string { return e.Key }
+	candidates, protectedCount := withoutProtected(c, candidates, entryKey)
+	candidates, excludedCount := withoutKeys(candidates, entryKey, opts.excludes)
+	candidates, _ = withoutKeys(candidates, entryKey, c.inTrash)
+
+	if !opts.DryRun {
+		if err := c.checkMaxDelete(len(candidates)); err != nil {
+			return nil, err
+		}
+		if !resumed {
+			if err := opts.Journal.RecordPlan(candidates, map[string]string{"cutoff_date": utils.FormatTime(cutoffDate)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var toDelete []types.ObjectIdentifier
+	deletedFiles := make([]string, 0, len(candidates))
+	var totalSize int64
+	for _, entry := range candidates {
+		deletedFiles = append(deletedFiles, entry.Key)
+		totalSize += entry.Size
+		if !opts.Journal.IsDone(entry.Key) {
+			toDelete = append(toDelete, types.ObjectIdentifier{Key: aws.String(entry.Key)})
+		}
+	}
+
+	deletedCount := 0
+	if !opts.DryRun {
+		deleted, err := c.removeObjects(ctx, toDelete, opts.Journal)
+		if err != nil {
+			if ctx.Err() == nil {
+				return nil, err
+			}
+			return c.interruptedDelete(opts, candidates, deleted, cutoffDate, resumed, err)
+		}
+		deletedCount = len(candidates) - len(toDelete) + len(deleted)
+	}
+
+	return &models.DeleteResult{
+		BucketName:     bucketName,
+		Folder:         storage.JoinFolders(opts.Folders),
+		DaysOld:        opts.DaysOld,
+		TagFilter:      opts.Tags,
+		UnusedFor:      formatUnusedFor(opts.UnusedFor),
+		DeletedFiles:   deletedFiles,
+		DeletedCount:   deletedCount,
+		TotalSizeBytes: totalSize,
+		TotalSizeHuman: utils.FormatBytes(totalSize),
+		OperationTime:  utils.FormatTime(time.Now()),
+		CutoffDate:     utils.FormatTime(cutoffDate),
+		NewerThan:      storage.FormatBound(opts.Window.After),
+		Resumed:        resumed,
+		ProtectedCount: protectedCount,
+		ExcludedCount:  excludedCount,
+		UndatedCount:   undatedCount,
+		DateFromKey:    opts.dateFromKey(),
+		Regex:          storage.RegexString(opts.Regex),
+		Filter:         opts.Filter.String(),
+		TrashFolder:    c.TrashFolder(),
+	}, nil
+}
+
+// excludes reports whether key matches one of the Exclude globs.
+func (opts DeleteOptions) excludes(key string) bool {
+	return storage.MatchesAny(key, opts.Exclude)
+}
+
+// dateFromKey reports the DateFromKey pattern, empty when objects are dated by
+// LastModified.
+func (opts DeleteOptions) dateFromKey() string {
+	if opts.DateFromKey == nil {
+		return ""
+	}
+	return opts.DateFromKey.String()
+}
+
+// interruptedDelete reports the objects removed before ctx was cancelled: those this run
+// deleted plus those a resumed journal had already marked done.
+func (c *Client) interruptedDelete(opts DeleteOptions, candidates []journal.Entry, deleted []string, cutoffDate time.Time, resumed bool, err error) (*models.DeleteResult, error) {
+	deletedNow := make(map[string]bool, len(deleted))
+	for _, key := range deleted {
+		deletedNow[key] = true
+	}
+
+	deletedFiles := make([]string, 0, len(deleted))
+	var totalSize int64
+	for _, entry := range candidates {
+		if deletedNow[entry.Key] || opts.Journal.IsDone(entry.Key) {
+			deletedFiles = append(deletedFiles, entry.Key)
+			totalSize += entry.Size
+		}
+	}
+
+	return &models.DeleteResult{
+		BucketName:     c.config.BucketName,
+		Folder:         storage.JoinFolders(opts.Folders),
+		DaysOld:        opts.DaysOld,
+		TagFilter:      opts.Tags,
+		UnusedFor:      formatUnusedFor(opts.UnusedFor),
+		DateFromKey:    opts.dateFromKey(),
+		Regex:          storage.RegexString(opts.Regex),
+		Filter:         opts.Filter.String(),
+		DeletedFiles:   deletedFiles,
+		DeletedCount:   len(deletedFiles),
+		TotalSizeBytes: totalSize,
+		TotalSizeHuman: utils.FormatBytes(totalSize),
+		OperationTime:  utils.FormatTime(time.Now()),
+		CutoffDate:     utils.FormatTime(cutoffDate),
+		NewerThan:      storage.FormatBound(opts.Window.After),
+		Resumed:        resumed,
+		TrashFolder:    c.TrashFolder(),
+		Interrupted:    true,
+		Error:          err.Error(),
+	}, err
+}
+
+// filterOld applies the tag and access log filters of opts to objects past the cutoff.
+func filterOld[T any](ctx context.Context, c *Client, items []T, key func(T) string, opts DeleteOptions, now time.Time) ([]T, error) {
+	items, err := withTags(ctx, c, items, key, opts.Tags)
+	if err != nil || opts.UnusedFor <= 0 {
+		return items, err
+	}
+	return withoutReadSince(ctx, c, items, key, now.Add(-opts.UnusedFor))
+}
+
+// formatUnusedFor reports an UnusedFor period in days, matching the --unused-for flag.
+func formatUnusedFor(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Hours()/24, 'f', -1, 64) + "d"
+}
+
+// checkMaxDelete guards against misconfigured prefixes wiping a bucket by refusing
+// deletions of more than MaxDelete objects.
+func (c *Client) checkMaxDelete(count int) error {
+	if c.config.MaxDelete > 0 && count > c.config.MaxDelete {
+		return fmt.Errorf("%w: %d objects match, the limit is %d (--max-delete or MAX_DELETE)",
+			ErrMaxDeleteExceeded, count, c.config.MaxDelete)
+	}
+	return nil
+}
+
+// listOlderThan returns the objects under prefix that opts deletes at cutoffDate, and how
+// many were skipped because opts.DateFromKey found no date in their key.
+func (c *Client) listOlderThan(ctx context.Context, prefix string, cutoffDate time.Time, opts DeleteOptions) ([]journal.Entry, int, error) {
+	var undated atomic.Int64
+
+	listPrefix, matches := c.listPrefix(prefix)
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		if obj.LastModified == nil || !matches(*obj.Key) {
+			return false
+		}
+		due, dated := opts.due(obj, cutoffDate)
+		if !dated {
+			undated.Add(1)
+		}
+		return due
+	})
+	objects, err := lister.listAll(ctx, listPrefix)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	candidates := make([]journal.Entry, 0, len(objects))
+	for _, obj := range objects {
+		candidates = append(candidates, journal.Entry{
+			Key:  *obj.Key,
+			Size: *obj.Size,
+		})
+	}
+	return candidates, int(undated.Load()), nil
+}
+
+// deleteObjects removes the given objects in batches of 1000, the DeleteObjects API limit.
+// Batches are sent by up to DeleteConcurrency workers, throttled to DeleteBatchesPerSecond,
+// and every deleted batch is marked in jr (which may be nil). The deleted keys are
+// returned even when an error stops the remaining batches.
+func (c *Client) deleteObjects(ctx context.Context, objects []types.ObjectIdentifier, jr *journal.Journal) ([]string, error) {
+	var batches [][]types.ObjectIdentifier
+	for i := 0; i < len(objects); i += 1000 {
+		end := i + 1000
+		if end > len(objects) {
+			end = len(objects)
+		}
+		batches = append(batches, objects[i:end])
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	workers := c.config.DeleteConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(batches) {
+		workers = len(batches)
+	}
+	limiter := utils.NewRateLimiter(c.config.DeleteBatchesPerSecond, workers)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	var deletedKeys []string
+
+	jobs := make(chan []types.ObjectIdentifier)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				deleted, err := c.deleteBatch(ctx, limiter, batch)
+				if markErr := jr.MarkDone(deleted...); markErr != nil && err == nil {
+					err = markErr
+				}
+
+				mu.Lock()
+				deletedKeys = append(deletedKeys, deleted...)
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, batch := range batches {
+		select {
+		case jobs <- batch:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return deletedKeys, firstErr
+	}
+	return deletedKeys, ctx.Err()
+}
+
+// deleteBatch sends one DeleteObjects request and returns the keys that were deleted.
+func (c *Client) deleteBatch(ctx context.Context, limiter *utils.RateLimiter, batch []types.ObjectIdentifier) ([]string, error) {
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	if !c.supports(featureBatchDelete) {
+		return c.deleteEach(ctx, batch)
+	}
+
+	resp, err := c.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(c.config.BucketName),
+		Delete: &types.Delete{
+			Objects: batch,
+			Quiet:   aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete objects batch: %w", err)
+	}
+
+	failed := make(map[string]bool, len(resp.Errors))
+	for _, deleteErr := range resp.Errors {
+		failed[aws.ToString(deleteErr.Key)] = true
+	}
+
+	deleted := make([]string, 0, len(batch))
+	for _, obj := range batch {
+		if !failed[aws.ToString(obj.Key)] {
+			deleted = append(deleted, aws.ToString(obj.Key))
+		}
+	}
+
+	if len(resp.Errors) > 0 {
+		first := resp.Errors[0]
+		return deleted, fmt.Errorf("failed to delete %d objects, first %s: %s",
+			len(resp.Errors), aws.ToString(first.Key), aws.ToString(first.Message))
+	}
+	return deleted, nil
+}
+
+// deleteEach deletes a batch one object at a time, for providers without DeleteObjects.
+// The batch still counts as one against DeleteBatchesPerSecond.
+func (c *Client) deleteEach(ctx context.Context, batch []types.ObjectIdentifier) ([]string, error) {
+	deleted := make([]string, 0, len(batch))
+	for _, obj := range batch {
+		_, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(c.config.BucketName),
+			Key:    obj.Key,
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete %s: %w", aws.ToString(obj.Key), err)
+		}
+		deleted = append(deleted, aws.ToString(obj.Key))
+	}
+	return deleted, nil
+}
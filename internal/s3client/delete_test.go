@@ -0,0 +1,383 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"s3manager/config"
+	"s3manager/internal/filter"
+	"s3manager/internal/journal"
+	"s3manager/internal/s3fake"
+	"s3manager/internal/storage"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// newTestClient returns a client talking to a local HTTP server instead of S3.
+func newTestClient(t *testing.T, handler http.Handler, mutate func(*config.Config)) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		ApiURL:     server.URL,
+		AccessKey:  "test",
+		SecretKey:  "test",
+		BucketName: "test-bucket",
+		Region:     "us-east-1",
+		Settings:   config.Settings{DeleteConcurrency: 1},
+	}
+	if mutate != nil {
+		mutate(cfg)
+	}
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestDeleteObjectsConcurrentBatches(t *testing.T) {
+	var requests, inFlight, maxInFlight int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "<Key>fail-me</Key>") {
+			fmt.Fprint(w, `<DeleteResult><Error><Key>fail-me</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error></DeleteResult>`)
+			return
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	})
+
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.DeleteConcurrency = 3
+	})
+
+	objects := make([]types.ObjectIdentifier, 2500)
+	for i := range objects {
+		objects[i] = types.ObjectIdentifier{Key: aws.String(fmt.Sprintf("key-%d", i))}
+	}
+
+	deleted, err := client.deleteObjects(context.Background(), objects, nil)
+	if err != nil {
+		t.Fatalf("deleteObjects() error = %v", err)
+	}
+
+	if len(deleted) != 2500 {
+		t.Errorf("deleted = %d, want %d", len(deleted), 2500)
+	}
+
+	if requests != 3 {
+		t.Errorf("requests = %d, want %d batches", requests, 3)
+	}
+
+	if maxInFlight > 3 {
+		t.Errorf("max concurrent requests = %d, want at most %d", maxInFlight, 3)
+	}
+
+	objects = []types.ObjectIdentifier{{Key: aws.String("ok")}, {Key: aws.String("fail-me")}}
+	deleted, err = client.deleteObjects(context.Background(), objects, nil)
+	if err == nil {
+		t.Errorf("deleteObjects() with per-key errors should return error")
+	}
+
+	if len(deleted) != 1 || deleted[0] != "ok" {
+		t.Errorf("deleted = %v, want [ok]", deleted)
+	}
+}
+
+func TestDeleteOldFilesResumesFromJournal(t *testing.T) {
+	var listed bool
+	var deletedKeys []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			listed = true
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		for _, part := range strings.Split(string(body), "<Key>")[1:] {
+			deletedKeys = append(deletedKeys, part[:strings.Index(part, "</Key>")])
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	})
+	client := newTestClient(t, handler, nil)
+
+	tempDir, err := os.MkdirTemp("", "delete-journal-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "delete.jsonl")
+	jr, _ := journal.Open(path, "delete-old", "fp", false)
+	jr.RecordPlan([]journal.Entry{{Key: "logs/a", Size: 1}, {Key: "logs/b", Size: 2}, {Key: "logs/c", Size: 3}},
+		map[string]string{"cutoff_date": "2024-01-01T00:00:00Z"})
+	jr.MarkDone("logs/a")
+	jr.Close()
+
+	jr, err = journal.Open(path, "delete-old", "fp", true)
+	if err != nil {
+		t.Fatalf("journal.Open() error = %v", err)
+	}
+	defer jr.Close()
+
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, Journal: jr})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+
+	if listed {
+		t.Errorf("resumed run should not list the bucket again")
+	}
+
+	if len(deletedKeys) != 2 || deletedKeys[0] != "logs/b" || deletedKeys[1] != "logs/c" {
+		t.Errorf("deleted keys = %v, want [logs/b logs/c]", deletedKeys)
+	}
+
+	if result.DeletedCount != 3 || result.TotalSizeBytes != 6 || !result.Resumed {
+		t.Errorf("result = %+v, want 3 deleted, 6 bytes, resumed", result)
+	}
+
+	if result.CutoffDate != "2024-01-01T00:00:00Z" {
+		t.Errorf("CutoffDate = %s, want the journaled cutoff", result.CutoffDate)
+	}
+}
+
+func TestDeleteOldFilesReportsPartialResultOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		// The first batch succeeds and the run is interrupted during the second one
+		if atomic.AddInt32(&requests, 1) > 1 {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	})
+	client := newTestClient(t, handler, nil)
+
+	tempDir, err := os.MkdirTemp("", "delete-cancel-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	entries := make([]journal.Entry, 1500)
+	for i := range entries {
+		entries[i] = journal.Entry{Key: fmt.Sprintf("logs/%04d", i), Size: 1}
+	}
+	path := filepath.Join(tempDir, "delete.jsonl")
+	jr, _ := journal.Open(path, "delete-old", "fp", false)
+	jr.RecordPlan(entries, map[string]string{"cutoff_date": "2024-01-01T00:00:00Z"})
+	jr.Close()
+
+	jr, err = journal.Open(path, "delete-old", "fp", true)
+	if err != nil {
+		t.Fatalf("journal.Open() error = %v", err)
+	}
+	defer jr.Close()
+
+	result, err := client.DeleteOldFiles(ctx, DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, Journal: jr})
+	if err == nil {
+		t.Fatalf("DeleteOldFiles() should fail when the context is cancelled")
+	}
+
+	if result == nil || !result.Interrupted {
+		t.Fatalf("result = %+v, want an interrupted partial result", result)
+	}
+
+	if result.DeletedCount != 1000 || len(result.DeletedFiles) != 1000 || result.TotalSizeBytes != 1000 {
+		t.Errorf("deleted = %d (%d files, %d bytes), want only the first batch", result.DeletedCount, len(result.DeletedFiles), result.TotalSizeBytes)
+	}
+
+	if jr.DoneCount() != 1000 {
+		t.Errorf("journal done = %d, want %d", jr.DoneCount(), 1000)
+	}
+}
+
+func TestDeleteOldFilesRespectsMaxDelete(t *testing.T) {
+	var deleteRequests int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(&deleteRequests, 1)
+			fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+			return
+		}
+		fmt.Fprint(w, `<ListBucketResult>
+<Contents><Key>logs/a</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>1</Size></Contents>
+<Contents><Key>logs/b</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>1</Size></Contents>
+<Contents><Key>logs/c</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>1</Size></Contents>
+</ListBucketResult>`)
+	})
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.MaxDelete = 2
+	})
+
+	_, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30})
+	if !errors.Is(err, ErrMaxDeleteExceeded) {
+		t.Fatalf("DeleteOldFiles() error = %v, want ErrMaxDeleteExceeded", err)
+	}
+	if deleteRequests != 0 {
+		t.Errorf("delete requests = %d, want none", deleteRequests)
+	}
+
+	if _, err := client.PlanDeleteOld(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30}); !errors.Is(err, ErrMaxDeleteExceeded) {
+		t.Errorf("PlanDeleteOld() error = %v, want ErrMaxDeleteExceeded", err)
+	}
+
+	// Dry runs still show the whole candidate set
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, DryRun: true})
+	if err != nil || len(result.DeletedFiles) != 3 {
+		t.Errorf("dry run = %+v, %v, want all three candidates", result, err)
+	}
+
+	client.config.MaxDelete = 3
+	result, err = client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30})
+	if err != nil || result.DeletedCount != 3 {
+		t.Errorf("DeleteOldFiles() at the limit = %+v, %v, want 3 deleted", result, err)
+	}
+}
+
+func TestDeleteOldFilesDatesByKey(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	// Replication reset LastModified of every backup to today
+	for _, key := range []string{"backups/2020-01-01/db.sql", "backups/2099-01-01/db.sql", "backups/README"} {
+		fake.PutObject("test-bucket", key, []byte("x"), time.Now())
+	}
+	client := newTestClient(t, fake, nil)
+
+	dateFromKey, err := storage.NewKeyDate(`(\d{4}-\d{2}-\d{2})/`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"backups"}, DaysOld: 30, DateFromKey: dateFromKey})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 1 || result.DeletedFiles[0] != "backups/2020-01-01/db.sql" || result.UndatedCount != 1 {
+		t.Errorf("DeleteOldFiles() = %+v, want the 2020 backup deleted and README undated", result)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 2 {
+		t.Errorf("Keys = %v, want the 2099 backup and README kept", keys)
+	}
+}
+
+func TestDeleteOldFilesWithinWindow(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	for _, day := range []int{1, 15, 28} {
+		fake.PutObject("test-bucket", fmt.Sprintf("logs/2023-02-%02d.log", day), []byte("x"), time.Date(2023, 2, day, 0, 0, 0, 0, time.UTC))
+	}
+	client := newTestClient(t, fake, nil)
+
+	// Everything from February 10th up to March 1st
+	window := storage.TimeWindow{
+		After:  time.Date(2023, 2, 10, 0, 0, 0, 0, time.UTC),
+		Before: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs"}, Window: window})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 2 || result.CutoffDate != "2023-03-01T00:00:00Z" || result.NewerThan != "2023-02-10T00:00:00Z" {
+		t.Errorf("DeleteOldFiles() = %+v, want 2 objects deleted between the bounds", result)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 1 || keys[0] != "logs/2023-02-01.log" {
+		t.Errorf("Keys = %v, want logs/2023-02-01.log kept", keys)
+	}
+}
+
+func TestDeleteOldFilesInSeveralFolders(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	old := time.Now().AddDate(0, 0, -30)
+	for _, key := range []string{"logs/app1/a.log", "logs/app1/b.log", "logs/app2/a.log", "logs/app3/a.log", "logs/app10/a.log"} {
+		fake.PutObject("test-bucket", key, []byte("x"), old)
+	}
+	client := newTestClient(t, fake, nil)
+
+	// logs/app1 is given twice and must not be counted twice, logs/app10 is not logs/app1
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs/app1", "logs/app2/", "logs/app1"}, DaysOld: 7})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 3 || result.Folder != "logs/app1,logs/app2/,logs/app1" {
+		t.Errorf("DeleteOldFiles() = %d deleted in %q, want 3 in the three folders", result.DeletedCount, result.Folder)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 2 {
+		t.Errorf("Keys = %v, want logs/app3 and logs/app10 kept", keys)
+	}
+}
+
+func TestDeleteOldFilesMatchingRegex(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	old := time.Now().AddDate(0, 0, -30)
+	for _, key := range []string{"backups/db-prod-20240301.sql.gz", "backups/db-staging-20240301.sql.gz", "backups/db-dev-20240301.sql.gz", "backups/db-prod-latest.sql.gz"} {
+		fake.PutObject("test-bucket", key, []byte("x"), old)
+	}
+	client := newTestClient(t, fake, nil)
+
+	regex := regexp.MustCompile(`^backups/db-(prod|staging)-\d{8}\.sql\.gz$`)
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"backups"}, DaysOld: 7, Regex: regex})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 2 || result.Regex != regex.String() {
+		t.Errorf("DeleteOldFiles() = %d deleted with regex %q, want prod and staging dumps", result.DeletedCount, result.Regex)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 2 {
+		t.Errorf("Keys = %v, want the dev and latest dumps kept", keys)
+	}
+}
+
+func TestDeleteOldFilesWithFilter(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	now := time.Now()
+	fake.PutObject("test-bucket", "exports/big.csv", []byte(strings.Repeat("x", 2048)), now.AddDate(0, 0, -40))
+	fake.PutObject("test-bucket", "exports/big.json", []byte(strings.Repeat("x", 2048)), now.AddDate(0, 0, -40))
+	fake.PutObject("test-bucket", "exports/small.csv", []byte("x"), now.AddDate(0, 0, -40))
+	fake.PutObject("test-bucket", "exports/new.csv", []byte(strings.Repeat("x", 2048)), now)
+	client := newTestClient(t, fake, nil)
+
+	objects, err := filter.Parse(`size>1KB and age>30d and not key~"*.json"`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := client.PlanDeleteOld(context.Background(), DeleteOptions{Folders: []string{"exports"}, DaysOld: 1, Filter: objects})
+	if err != nil {
+		t.Fatalf("PlanDeleteOld() error = %v", err)
+	}
+	if plan.TotalObjects != 1 || plan.Objects[0].Key != "exports/big.csv" || plan.Filter != objects.String() {
+		t.Errorf("PlanDeleteOld() = %+v, want only exports/big.csv", plan)
+	}
+}
@@ -0,0 +1,429 @@
+package s3client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	CompressionNone   = ""
+	CompressionGzip   = "gzip"
+	CompressionBrotli = "br"
+
+	htmlCacheControl  = "public, max-age=0, must-revalidate"
+	assetCacheControl = "public, max-age=86400"
+)
+
+type DeployOptions struct {
+	// CacheControl overrides the Cache-Control header per file extension (e.g. ".css").
+	CacheControl map[string]string
+	// Compression is one of CompressionNone, CompressionGzip or CompressionBrotli.
+	Compression string
+	// CompressExtensions lists the extensions that are pre-compressed when Compression is set.
+	CompressExtensions []string
+	// DeleteRemoved removes remote objects under the destination that no longer exist locally.
+	DeleteRemoved bool
+	DryRun        bool
+	Concurrency   int
+}
+
+type deployFile struct {
+	localPath  string
+	remotePath string
+	size       int64
+}
+
+func DefaultCompressExtensions() []string {
+	return []string{".html", ".htm", ".css", ".js", ".mjs", ".json", ".map", ".svg", ".xml", ".txt", ".webmanifest"}
+}
+
+func (c *Client) DeploySite(ctx context.Context, sourceDir, destinationPath string, opts DeployOptions) (*models.DeployResult, error) {
+	startTime := time.Now()
+
+	switch opts.Compression {
+	case CompressionNone, CompressionGzip, CompressionBrotli:
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", opts.Compression)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	opts.Concurrency = c.fileConcurrency(opts.Concurrency)
+
+	files, err := collectDeployFiles(sourceDir, c.buildRemotePath(destinationPath, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	remoteETags, err := c.listETags(ctx, c.buildRemotePath(destinationPath, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	local := make(map[string]bool, len(files))
+	for _, f := range files {
+		local[f.remotePath] = true
+	}
+
+	if opts.DeleteRemoved && !opts.DryRun {
+		// Checked before uploading anything so that a wrong destination fails early
+		removed := 0
+		for key := range remoteETags {
+			if !local[key] && !c.isProtected(key) && !c.inTrash(key) {
+				removed++
+			}
+		}
+		if err := c.checkMaxDelete(removed); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &models.DeployResult{
+		BucketName:      c.config.BucketName,
+		SourcePath:      sourceDir,
+		DestinationPath: destinationPath,
+		Uploaded:        []models.DeployItem{},
+		DeletedFiles:    []string{},
+		OperationTime:   utils.FormatTime(startTime),
+		DryRun:          opts.DryRun,
+	}
+
+	// Assets go first so that freshly uploaded HTML never references files that are not there yet
+	assets, pages := splitDeployFiles(files)
+	if !opts.DryRun {
+		var size int64
+		for _, f := range files {
+			size += f.size
+		}
+		c.planTransfers(TransferUpload, len(files), size)
+	}
+	uploader := c.newUploader()
+	settings := c.settings()
+	uploader.PartSize, uploader.Concurrency = c.transferSettings(settings.PartSize, settings.UploadConcurrency, opts.Concurrency)
+	for _, phase := range [][]deployFile{assets, pages} {
+		if err := c.deployFiles(ctx, uploader, phase, remoteETags, opts, result); err != nil {
+			return interruptedDeploy(ctx, result, startTime, err)
+		}
+	}
+
+	if opts.DeleteRemoved {
+		var toDelete []types.ObjectIdentifier
+		for key := range remoteETags {
+			if local[key] || c.inTrash(key) {
+				continue
+			}
+			if c.isProtected(key) {
+				result.ProtectedCount++
+				continue
+			}
+			toDelete = append(toDelete, types.ObjectIdentifier{Key: aws.String(key)})
+			result.DeletedFiles = append(result.DeletedFiles, key)
+		}
+		slices.Sort(result.DeletedFiles)
+
+		if !opts.DryRun {
+			result.TrashFolder = c.TrashFolder()
+			deleted, err := c.removeObjects(ctx, toDelete, nil)
+			if err != nil {
+				result.DeletedFiles = deleted
+				slices.Sort(result.DeletedFiles)
+				return interruptedDeploy(ctx, result, startTime, err)
+			}
+		}
+	}
+
+	finishDeploy(result, startTime)
+	return result, nil
+}
+
+func finishDeploy(result *models.DeployResult, startTime time.Time) {
+	result.UploadedCount = len(result.Uploaded)
+	result.DeletedCount = len(result.DeletedFiles)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.DeployDuration = time.Since(startTime).String()
+}
+
+// interruptedDeploy returns what was deployed so far when ctx was cancelled, and only the
+// error otherwise.
+func interruptedDeploy(ctx context.Context, result *models.DeployResult, startTime time.Time, err error) (*models.DeployResult, error) {
+	if ctx.Err() == nil {
+		return nil, err
+	}
+	finishDeploy(result, startTime)
+	result.Interrupted = true
+	result.Error = err.Error()
+	return result, err
+}
+
+func (c *Client) deployFiles(ctx context.Context, uploader *manager.Uploader, files []deployFile, remoteETags map[string]string, opts DeployOptions, result *models.DeployResult) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, opts.Concurrency)
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(file deployFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			item, skipped, err := c.deployFile(ctx, uploader, file, remoteETags[file.remotePath], opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				if firstErr == nil {
+					firstErr = err
+				}
+			case skipped:
+				result.SkippedCount++
+			default:
+				result.Uploaded = append(result.Uploaded, *item)
+				result.TotalSizeBytes += item.Size
+			}
+		}(file)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (c *Client) deployFile(ctx context.Context, uploader *manager.Uploader, file deployFile, remoteETag string, opts DeployOptions) (*models.DeployItem, bool, error) {
+	encoding := CompressionNone
+	if opts.Compression != CompressionNone && slices.Contains(opts.CompressExtensions, strings.ToLower(filepath.Ext(file.localPath))) {
+		encoding = opts.Compression
+	}
+
+	body, err := readDeployBody(file.localPath, encoding)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if c.deployUnchanged(ctx, file.remotePath, body, remoteETag, uploader.PartSize) {
+		if !opts.DryRun {
+			c.skipTransfer(TransferUpload, file.localPath, file.remotePath, file.size)
+		}
+		return nil, true, nil
+	}
+
+	item := &models.DeployItem{
+		LocalPath:       file.localPath,
+		RemotePath:      file.remotePath,
+		Size:            int64(len(body)),
+		ContentType:     siteContentType(c.detectContentType(file.localPath)),
+		CacheControl:    cacheControlFor(file.localPath, opts.CacheControl),
+		ContentEncoding: encoding,
+	}
+
+	if opts.DryRun {
+		return item, false, nil
+	}
+
+	transfer := c.trackTransfer(TransferUpload, file.localPath, file.remotePath, item.Size)
+	input := &s3.PutObjectInput{
+		Bucket:       aws.String(c.config.BucketName),
+		Key:          aws.String(file.remotePath),
+		Body:         transfer.body(bytes.NewReader(body)),
+		ContentType:  aws.String(item.ContentType),
+		CacheControl: aws.String(item.CacheControl),
+	}
+	if encoding != CompressionNone {
+		input.ContentEncoding = aws.String(encoding)
+	}
+
+	err = c.upload(ctx, uploader, input)
+	transfer.finished(err)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to upload %s: %w", file.localPath, err)
+	}
+
+	return item, false, nil
+}
+
+// deployUnchanged reports whether the object key, whose ETag is remoteETag, holds body.
+// The ETag of an object uploaded in parts is the MD5 of the part MD5s, so it is recomputed
+// with the part size of the uploader, or with the size of the first part of the object
+// when the part counts differ because it was uploaded with other settings.
+func (c *Client) deployUnchanged(ctx context.Context, key string, body []byte, remoteETag string, partSize int64) bool {
+	parts := multipartCount(remoteETag)
+	if parts == 0 {
+		sum := md5.Sum(body)
+		return remoteETag == hex.EncodeToString(sum[:])
+	}
+	if partSize <= 0 || int64(parts) != (int64(len(body))+partSize-1)/partSize {
+		size, err := c.firstPartSize(ctx, key)
+		if err != nil || size <= 0 {
+			return false
+		}
+		partSize = size
+	}
+	return multipartBodyETag(body, partSize) == remoteETag
+}
+
+// multipartBodyETag returns the ETag of body uploaded in parts of partSize.
+func multipartBodyETag(body []byte, partSize int64) string {
+	combined := md5.New()
+	parts := 0
+	for start := int64(0); start < int64(len(body)); start += partSize {
+		sum := md5.Sum(body[start:min(start+partSize, int64(len(body)))])
+		combined.Write(sum[:])
+		parts++
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(combined.Sum(nil)), parts)
+}
+
+// listETags returns the ETag of every object under prefix, keyed by object key.
+func (c *Client) listETags(ctx context.Context, prefix string) (map[string]string, error) {
+	etags := make(map[string]string)
+
+	listPrefix, matches := c.listPrefix(prefix)
+	paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.config.BucketName),
+		Prefix: aws.String(listPrefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			if matches(*obj.Key) {
+				etags[*obj.Key] = strings.Trim(aws.ToString(obj.ETag), `"`)
+			}
+		}
+	}
+
+	return etags, nil
+}
+
+func collectDeployFiles(sourceDir, prefix string) ([]deployFile, error) {
+	info, err := os.Stat(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access path %s: %w", sourceDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("deploy source must be a directory: %s", sourceDir)
+	}
+
+	var files []deployFile
+	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, deployFile{
+			localPath:  path,
+			remotePath: prefix + filepath.ToSlash(relPath),
+			size:       info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", sourceDir, err)
+	}
+
+	return files, nil
+}
+
+func splitDeployFiles(files []deployFile) (assets, pages []deployFile) {
+	for _, f := range files {
+		if isHTMLFile(f.localPath) {
+			pages = append(pages, f)
+		} else {
+			assets = append(assets, f)
+		}
+	}
+	return assets, pages
+}
+
+func isHTMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".html" || ext == ".htm"
+}
+
+func cacheControlFor(path string, overrides map[string]string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if value, ok := overrides[ext]; ok {
+		return value
+	}
+	if isHTMLFile(path) {
+		return htmlCacheControl
+	}
+	return assetCacheControl
+}
+
+func siteContentType(contentType string) string {
+	if strings.HasPrefix(contentType, "text/") || contentType == "application/javascript" || contentType == "application/json" {
+		return contentType + "; charset=utf-8"
+	}
+	return contentType
+}
+
+func readDeployBody(path, encoding string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case CompressionGzip:
+		writer, err = gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+	case CompressionBrotli:
+		writer = brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	default:
+		return data, nil
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,148 @@
+package s3client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+)
+
+func TestSplitDeployFiles(t *testing.T) {
+	files := []deployFile{
+		{localPath: "site/index.html"},
+		{localPath: "site/app.js"},
+		{localPath: "site/about/INDEX.HTM"},
+		{localPath: "site/logo.png"},
+	}
+
+	assets, pages := splitDeployFiles(files)
+
+	if len(assets) != 2 {
+		t.Errorf("assets length = %d, want %d", len(assets), 2)
+	}
+
+	if len(pages) != 2 {
+		t.Errorf("pages length = %d, want %d", len(pages), 2)
+	}
+}
+
+func TestCacheControlFor(t *testing.T) {
+	overrides := map[string]string{".css": "public, max-age=31536000, immutable"}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"index.html", htmlCacheControl},
+		{"style.css", "public, max-age=31536000, immutable"},
+		{"app.js", assetCacheControl},
+	}
+
+	for _, tt := range tests {
+		if result := cacheControlFor(tt.path, overrides); result != tt.expected {
+			t.Errorf("cacheControlFor(%s) = %s, want %s", tt.path, result, tt.expected)
+		}
+	}
+}
+
+func TestCollectDeployFiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "deploy-files-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "css"), 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+	os.WriteFile(filepath.Join(tempDir, "index.html"), []byte("<html></html>"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "css", "app.css"), []byte("body{}"), 0644)
+
+	files, err := collectDeployFiles(tempDir, "site/")
+	if err != nil {
+		t.Fatalf("collectDeployFiles() error = %v", err)
+	}
+
+	remote := map[string]bool{}
+	for _, f := range files {
+		remote[f.remotePath] = true
+	}
+
+	for _, key := range []string{"site/index.html", "site/css/app.css"} {
+		if !remote[key] {
+			t.Errorf("collectDeployFiles() missing %s, got %v", key, remote)
+		}
+	}
+
+	if _, err := collectDeployFiles(filepath.Join(tempDir, "index.html"), ""); err == nil {
+		t.Errorf("collectDeployFiles() with a file should return error")
+	}
+}
+
+func TestReadDeployBodyGzip(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "deploy-body-*.html")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	content := []byte("<html><body>hello hello hello</body></html>")
+	tempFile.Write(content)
+	tempFile.Close()
+
+	body, err := readDeployBody(tempFile.Name(), CompressionGzip)
+	if err != nil {
+		t.Fatalf("readDeployBody() error = %v", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("body is not gzip: %v", err)
+	}
+	decoded, _ := io.ReadAll(reader)
+	if !bytes.Equal(decoded, content) {
+		t.Errorf("decoded body = %q, want %q", decoded, content)
+	}
+
+	// Compressed output must be deterministic so unchanged files are skipped by ETag
+	again, _ := readDeployBody(tempFile.Name(), CompressionGzip)
+	if !bytes.Equal(body, again) {
+		t.Errorf("readDeployBody() is not deterministic")
+	}
+}
+
+func TestDeploySkipsUnchangedMultipartFiles(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) { cfg.Settings.PartSize = 5 << 20 })
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0644)
+	os.WriteFile(filepath.Join(dir, "video.mp4"), bytes.Repeat([]byte("frame"), 3<<20), 0644)
+
+	result, err := client.DeploySite(ctx, dir, "site", DeployOptions{})
+	if err != nil {
+		t.Fatalf("DeploySite() error = %v", err)
+	}
+	if result.UploadedCount != 2 {
+		t.Fatalf("DeploySite() uploaded %d files, want 2", result.UploadedCount)
+	}
+	if obj, _ := fake.Object("test-bucket", "site/video.mp4"); !strings.Contains(obj.ETag, "-") {
+		t.Fatalf("video.mp4 has ETag %s, want a multipart upload", obj.ETag)
+	}
+
+	result, err = client.DeploySite(ctx, dir, "site", DeployOptions{})
+	if err != nil {
+		t.Fatalf("DeploySite() error = %v", err)
+	}
+	if result.UploadedCount != 0 || result.SkippedCount != 2 {
+		t.Errorf("DeploySite() again = %d uploaded, %d skipped, want both files skipped", result.UploadedCount, result.SkippedCount)
+	}
+}
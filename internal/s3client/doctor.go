@@ -0,0 +1,238 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	DoctorOK          = "ok"
+	DoctorDenied      = "denied"
+	DoctorFailed      = "failed"
+	DoctorUnsupported = "unsupported"
+	DoctorSkipped     = "skipped"
+
+	// Probe objects are written next to the lock objects
+	doctorPrefix = ".s3manager/doctor/"
+)
+
+type DoctorOptions struct {
+	// Prefix is where the probe object is written, for credentials limited to a prefix.
+	Prefix string
+	// ReadOnly skips every probe that writes to the bucket.
+	ReadOnly bool
+}
+
+// doctorProbe is one API call made by Doctor.
+type doctorProbe struct {
+	operation  string
+	permission string
+	usedBy     string
+	optional   bool
+	// write probes change the bucket and are skipped in read-only mode
+	write bool
+	// needsProbe probes work on the probe object and are skipped when it was not written
+	needsProbe bool
+	feature    string
+	run        func(ctx context.Context) error
+}
+
+// Doctor calls every S3 API the commands rely on and reports which of them the current
+// credentials may use. Write checks go to a probe object that is removed afterwards.
+// Failed checks are reported in the result, errors mean the checks could not run.
+func (c *Client) Doctor(ctx context.Context, opts DoctorOptions) (*models.DoctorResult, error) {
+	bucket := aws.String(c.config.BucketName)
+	probeKey := fmt.Sprintf("%s%s%s-%d", opts.Prefix, doctorPrefix, strings.ReplaceAll(lockOwner(), ":", "-"), time.Now().UnixNano())
+	copyKey := probeKey + ".copy"
+	var uploadID *string
+	var probeWritten, copyWritten bool
+
+	probes := []doctorProbe{
+		{operation: "HeadBucket", permission: "s3:ListBucket", usedBy: "all commands", run: func(ctx context.Context) error {
+			_, err := c.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: bucket})
+			return err
+		}},
+		{operation: "ListObjectsV2", permission: "s3:ListBucket", usedBy: "bucket-info, delete-old, download, deploy, latest, check freshness", run: func(ctx context.Context) error {
+			listPrefix, _ := c.listPrefix(opts.Prefix)
+			_, err := c.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: bucket, Prefix: aws.String(listPrefix), MaxKeys: aws.Int32(1)})
+			return err
+		}},
+		{operation: "GetBucketLocation", permission: "s3:GetBucketLocation", usedBy: "bucket-info region", optional: true, feature: featureBucketLocation, run: func(ctx context.Context) error {
+			_, err := c.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: bucket})
+			return err
+		}},
+		{operation: "ListBuckets", permission: "s3:ListAllMyBuckets", usedBy: "bucket-info creation date", optional: true, run: func(ctx context.Context) error {
+			_, err := c.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+			return err
+		}},
+		{operation: "GetBucketWebsite", permission: "s3:GetBucketWebsite", usedBy: "bucket website", optional: true, feature: featureWebsite, run: func(ctx context.Context) error {
+			_, err := c.s3Client.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{Bucket: bucket})
+			if hasErrorCode(err, "NoSuchWebsiteConfiguration") {
+				return nil
+			}
+			return err
+		}},
+		{operation: "PutObject", permission: "s3:PutObject", usedBy: "upload, deploy, --lock-name", write: true, run: func(ctx context.Context) error {
+			_, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{Bucket: bucket, Key: aws.String(probeKey), Body: strings.NewReader("s3manager doctor probe\n")})
+			probeWritten = err == nil
+			return err
+		}},
+		{operation: "GetObject", permission: "s3:GetObject", usedBy: "download, checksum, events listen", needsProbe: true, run: func(ctx context.Context) error {
+			resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: bucket, Key: aws.String(probeKey)})
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		}},
+		{operation: "PutObjectTagging", permission: "s3:PutObjectTagging", usedBy: "events listen --tag", optional: true, write: true, needsProbe: true, feature: featureTagging, run: func(ctx context.Context) error {
+			_, err := c.s3Client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{Bucket: bucket, Key: aws.String(probeKey), Tagging: &types.Tagging{
+				TagSet: []types.Tag{{Key: aws.String("s3manager-doctor"), Value: aws.String("probe")}},
+			}})
+			return err
+		}},
+		{operation: "GetObjectTagging", permission: "s3:GetObjectTagging", usedBy: "--tag-filter, events listen --tag", optional: true, needsProbe: true, feature: featureTagging, run: func(ctx context.Context) error {
+			_, err := c.s3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{Bucket: bucket, Key: aws.String(probeKey)})
+			return err
+		}},
+		{operation: "CopyObject", permission: "s3:GetObject, s3:PutObject", usedBy: "--trash, trash restore, events listen --replicate-to", optional: true, write: true, needsProbe: true, run: func(ctx context.Context) error {
+			err := c.copyObject(ctx, c.config.BucketName, probeKey, c.config.BucketName, copyKey)
+			copyWritten = err == nil
+			return err
+		}},
+		{operation: "CreateMultipartUpload", permission: "s3:PutObject", usedBy: "upload and download of large files", write: true, run: func(ctx context.Context) error {
+			resp, err := c.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: bucket, Key: aws.String(probeKey + ".multipart")})
+			if err == nil {
+				uploadID = resp.UploadId
+			}
+			return err
+		}},
+		{operation: "AbortMultipartUpload", permission: "s3:AbortMultipartUpload", usedBy: "interrupted uploads", write: true, run: func(ctx context.Context) error {
+			if uploadID == nil {
+				return errSkipProbe
+			}
+			_, err := c.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{Bucket: bucket, Key: aws.String(probeKey + ".multipart"), UploadId: uploadID})
+			return err
+		}},
+		{operation: "DeleteObjects", permission: "s3:DeleteObject", usedBy: "delete-old, apply, deploy --delete, trash", write: true, feature: featureBatchDelete, run: func(ctx context.Context) error {
+			resp, err := c.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{Bucket: bucket, Delete: &types.Delete{
+				Objects: []types.ObjectIdentifier{{Key: aws.String(copyKey)}},
+				Quiet:   aws.Bool(true),
+			}})
+			if err != nil {
+				return err
+			}
+			// Denied keys are reported per object, not as a request error
+			if len(resp.Errors) > 0 {
+				return &smithy.GenericAPIError{Code: aws.ToString(resp.Errors[0].Code), Message: aws.ToString(resp.Errors[0].Message)}
+			}
+			copyWritten = false
+			return nil
+		}},
+		{operation: "DeleteObject", permission: "s3:DeleteObject", usedBy: "--lock-name", write: true, needsProbe: true, run: func(ctx context.Context) error {
+			_, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: aws.String(probeKey)})
+			if err == nil {
+				probeWritten = false
+			}
+			// Without DeleteObjects the copy is still there
+			if err == nil && copyWritten && !c.supports(featureBatchDelete) {
+				_, err = c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: aws.String(copyKey)})
+				copyWritten = err != nil
+			}
+			return err
+		}},
+	}
+
+	result := &models.DoctorResult{
+		BucketName:  c.config.BucketName,
+		Provider:    c.config.Provider,
+		APIEndpoint: c.config.ApiURL,
+		Checks:      make([]models.DoctorCheck, 0, len(probes)),
+	}
+	if !opts.ReadOnly {
+		result.ProbeKey = probeKey
+	}
+
+	for _, probe := range probes {
+		check := models.DoctorCheck{
+			Operation:  probe.operation,
+			Permission: probe.permission,
+			UsedBy:     probe.usedBy,
+			Optional:   probe.optional,
+		}
+
+		switch {
+		case (probe.write || probe.needsProbe) && opts.ReadOnly:
+			check.Status = DoctorSkipped
+			check.Error = "read-only mode"
+		case probe.needsProbe && !probeWritten:
+			check.Status = DoctorSkipped
+			check.Error = "needs the probe object, which could not be written"
+		case probe.feature != "" && !c.supports(probe.feature):
+			check.Status = DoctorUnsupported
+			check.Error = c.checkSupported(probe.feature).Error()
+		default:
+			err := probe.run(ctx)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			check.Status = doctorStatus(err)
+			switch {
+			case err == errSkipProbe:
+				check.Error = "no multipart upload was created"
+			case err != nil:
+				check.Error = err.Error()
+			}
+		}
+
+		switch check.Status {
+		case DoctorOK:
+			result.Passed++
+		case DoctorDenied, DoctorFailed:
+			if !probe.optional {
+				result.Failed++
+			}
+		}
+		result.Checks = append(result.Checks, check)
+	}
+
+	// Best effort, a denied delete was already reported above
+	if copyWritten {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("probe object %s could not be deleted", copyKey))
+	}
+	if probeWritten {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("probe object %s could not be deleted", probeKey))
+	}
+
+	result.OK = result.Failed == 0
+	result.OperationTime = utils.FormatTime(time.Now())
+	return result, nil
+}
+
+// errSkipProbe is returned by a probe that could not run because an earlier one failed.
+var errSkipProbe = errors.New("probe skipped")
+
+func doctorStatus(err error) string {
+	switch {
+	case err == nil:
+		return DoctorOK
+	case err == errSkipProbe:
+		return DoctorSkipped
+	case hasErrorCode(err, "AccessDenied", "AllAccessDisabled", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch"):
+		return DoctorDenied
+	case hasErrorCode(err, "NotImplemented", "MethodNotAllowed"):
+		return DoctorUnsupported
+	default:
+		return DoctorFailed
+	}
+}
@@ -0,0 +1,87 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	var writes int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPut && query.Has("tagging"):
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+		case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+			writes++
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+		case r.Method == http.MethodPut:
+			writes++
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPost && query.Has("delete"):
+			// The bucket policy denies deleting objects
+			fmt.Fprint(w, `<DeleteResult><Error><Key>k</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error></DeleteResult>`)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case query.Has("website"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchWebsiteConfiguration</Code></Error>`)
+		case query.Has("location"):
+			fmt.Fprint(w, `<LocationConstraint>eu-west-1</LocationConstraint>`)
+		case query.Has("list-type"):
+			fmt.Fprint(w, `<ListBucketResult></ListBucketResult>`)
+		case query.Has("tagging"):
+			fmt.Fprint(w, `<Tagging><TagSet></TagSet></Tagging>`)
+		case r.URL.Path == "/":
+			fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`)
+		default:
+			fmt.Fprint(w, "probe")
+		}
+	})
+	client := newTestClient(t, handler, nil)
+
+	result, err := client.Doctor(context.Background(), DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+
+	statuses := map[string]string{}
+	for _, check := range result.Checks {
+		statuses[check.Operation] = check.Status
+	}
+	want := map[string]string{
+		"HeadBucket":           DoctorOK,
+		"GetObject":            DoctorOK,
+		"GetBucketWebsite":     DoctorOK,
+		"PutObjectTagging":     DoctorDenied,
+		"AbortMultipartUpload": DoctorOK,
+		"DeleteObjects":        DoctorDenied,
+		"DeleteObject":         DoctorOK,
+	}
+	for operation, status := range want {
+		if statuses[operation] != status {
+			t.Errorf("%s status = %q, want %q", operation, statuses[operation], status)
+		}
+	}
+
+	// The denied tagging is optional, the denied batch delete is not
+	if result.OK || result.Failed != 1 {
+		t.Errorf("result OK = %v, failed = %d, want a single required failure", result.OK, result.Failed)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("warnings = %v, want the copy that could not be deleted", result.Warnings)
+	}
+
+	writes = 0
+	result, err = client.Doctor(context.Background(), DoctorOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	if writes != 0 || !result.OK || result.ProbeKey != "" {
+		t.Errorf("read-only doctor wrote %d objects, result = %+v", writes, result)
+	}
+}
@@ -0,0 +1,211 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+const (
+	ConflictOverwrite = "overwrite"
+	ConflictSkip      = "skip"
+	ConflictRename    = "rename"
+)
+
+// folderDownload is a file of a recursive download and where it goes.
+type folderDownload struct {
+	obj       types.Object
+	localPath string
+	// skip is set when the local file exists and OnConflict is ConflictSkip
+	skip      bool
+	overwrite bool
+}
+
+// DownloadFolder downloads every file below folder into destinationPath. The folders
+// below folder are recreated unless opts.Flatten is set, and opts.OnConflict decides
+// about files that already exist. The conditions of opts do not apply.
+func (c *Client) DownloadFolder(ctx context.Context, folder, destinationPath string, opts DownloadOptions) (*models.DownloadResult, error) {
+	startTime := time.Now()
+
+	downloads, err := c.folderDownloads(ctx, folder, destinationPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.DownloadResult{
+		BucketName:    c.config.BucketName,
+		SourcePath:    folder,
+		Items:         []models.DownloadItem{},
+		OperationTime: utils.FormatTime(startTime),
+	}
+	var need int64
+	var files int
+	for _, d := range downloads {
+		if !d.skip {
+			need += aws.ToInt64(d.obj.Size)
+			files++
+		}
+	}
+	if err := utils.CheckFreeSpace(destinationPath, need); err != nil {
+		return nil, err
+	}
+	c.planTransfers(TransferDownload, files, need)
+
+	for _, d := range downloads {
+		if d.skip {
+			result.SkippedFiles = append(result.SkippedFiles, aws.ToString(d.obj.Key))
+			continue
+		}
+		created, err := createDirectories(filepath.Dir(d.localPath))
+		result.CreatedDirectories = append(result.CreatedDirectories, created...)
+		if err != nil {
+			return nil, err
+		}
+
+		item, err := c.fetchObject(ctx, d.obj, d.localPath, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", aws.ToString(d.obj.Key), err)
+		}
+		result.Items = append(result.Items, item)
+		result.TotalSizeBytes += item.Size
+	}
+
+	duration := time.Since(startTime)
+	throughput := utils.BytesPerSecond(result.TotalSizeBytes, duration)
+	result.TotalFiles = len(result.Items)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.DownloadDuration = duration.String()
+	result.ThroughputBytes = throughput
+	result.ThroughputHuman = utils.FormatSpeed(throughput)
+	return result, nil
+}
+
+// PlanDownloadFolder reports what DownloadFolder would download without touching the
+// local file system.
+func (c *Client) PlanDownloadFolder(ctx context.Context, folder, destinationPath string, opts DownloadOptions) (*models.TransferPlan, error) {
+	downloads, err := c.folderDownloads(ctx, folder, destinationPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := newTransferPlan(PlanActionDownload, c.config.BucketName, folder, destinationPath)
+	for _, d := range downloads {
+		if d.skip {
+			continue
+		}
+		addPlanItem(plan, models.PlanItem{
+			Action:       PlanActionDownload,
+			Source:       aws.ToString(d.obj.Key),
+			Destination:  d.localPath,
+			Size:         aws.ToInt64(d.obj.Size),
+			LastModified: aws.ToTime(d.obj.LastModified).Format(time.RFC3339),
+			Overwrite:    d.overwrite,
+		})
+	}
+	return plan, nil
+}
+
+// folderDownloads lists the files below folder and resolves their local paths.
+func (c *Client) folderDownloads(ctx context.Context, folder, destinationPath string, opts DownloadOptions) ([]folderDownload, error) {
+	switch opts.OnConflict {
+	case "", ConflictOverwrite, ConflictSkip, ConflictRename:
+	default:
+		return nil, fmt.Errorf("invalid conflict handling %q, expected %s, %s or %s", opts.OnConflict, ConflictOverwrite, ConflictSkip, ConflictRename)
+	}
+
+	prefix := folder
+	if !strings.HasSuffix(prefix, "/") && prefix != "" {
+		prefix += "/"
+	}
+	objects, err := c.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	objects = slices.DeleteFunc(objects, func(obj types.Object) bool {
+		key := aws.ToString(obj.Key)
+		// Folder markers have no content, and signatures are checked rather than downloaded
+		return strings.HasSuffix(key, "/") || (opts.Verifier != nil && isSignature(key)) || !storage.MatchesRegex(key, opts.Regex)
+	})
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no files found in folder: %s", folder)
+	}
+	slices.SortFunc(objects, func(a, b types.Object) int {
+		return strings.Compare(aws.ToString(a.Key), aws.ToString(b.Key))
+	})
+
+	claimed := make(map[string]bool)
+	downloads := make([]folderDownload, 0, len(objects))
+	for _, obj := range objects {
+		rel := strings.TrimPrefix(aws.ToString(obj.Key), prefix)
+		if opts.Flatten {
+			rel = path.Base(rel)
+		}
+		localPath, err := eventLocalPath(destinationPath, rel)
+		if err != nil {
+			return nil, err
+		}
+
+		d := folderDownload{obj: obj, localPath: localPath}
+		if claimed[localPath] || localFileExists(localPath) {
+			switch opts.OnConflict {
+			case ConflictSkip:
+				d.skip = true
+			case ConflictRename:
+				d.localPath = freeLocalPath(localPath, claimed)
+			default:
+				d.overwrite = true
+			}
+		}
+		claimed[d.localPath] = true
+		downloads = append(downloads, d)
+	}
+	return downloads, nil
+}
+
+// freeLocalPath numbers localPath as name-1.ext, name-2.ext, ... until it names neither
+// an existing file nor one claimed by the same download.
+func freeLocalPath(localPath string, claimed map[string]bool) string {
+	ext := filepath.Ext(localPath)
+	base := strings.TrimSuffix(localPath, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, n, ext)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) && !claimed[candidate] {
+			return candidate
+		}
+	}
+}
+
+// createDirectories creates dir and its missing parents and returns those it created,
+// outermost first.
+func createDirectories(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); !os.IsNotExist(err) {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	slices.Reverse(missing)
+	return missing, nil
+}
@@ -0,0 +1,146 @@
+package s3client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func newFolderFake(t *testing.T) *s3fake.Server {
+	t.Helper()
+	fake := s3fake.New("test-bucket")
+	now := time.Now()
+	fake.PutObject("test-bucket", "reports/2024/01/summary.csv", []byte("jan"), now)
+	fake.PutObject("test-bucket", "reports/2024/02/summary.csv", []byte("feb"), now)
+	fake.PutObject("test-bucket", "reports/2024/index.html", []byte("<html>"), now)
+	fake.PutObject("test-bucket", "reports/2024/empty/", nil, now)
+	fake.PutObject("test-bucket", "other/skip.txt", []byte("no"), now)
+	return fake
+}
+
+func TestDownloadFolderPreservesStructure(t *testing.T) {
+	fake := newFolderFake(t)
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	dest := filepath.Join(t.TempDir(), "out")
+
+	result, err := client.DownloadFolder(context.Background(), "reports/2024", dest, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("DownloadFolder() error = %v", err)
+	}
+	if result.TotalFiles != 3 || result.TotalSizeBytes != 12 {
+		t.Errorf("DownloadFolder() = %d files, %d bytes, want 3 files, 12 bytes", result.TotalFiles, result.TotalSizeBytes)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "02", "summary.csv")); err != nil || string(data) != "feb" {
+		t.Errorf("02/summary.csv = %q, %v", data, err)
+	}
+	want := []string{dest, filepath.Join(dest, "01"), filepath.Join(dest, "02")}
+	if !reflect.DeepEqual(result.CreatedDirectories, want) {
+		t.Errorf("CreatedDirectories = %v, want %v", result.CreatedDirectories, want)
+	}
+}
+
+func TestDownloadFolderConflicts(t *testing.T) {
+	tests := []struct {
+		onConflict string
+		wantFiles  []string
+		wantSkip   int
+	}{
+		{ConflictOverwrite, []string{"index.html", "summary.csv"}, 0},
+		{ConflictSkip, []string{"index.html", "summary.csv"}, 2},
+		{ConflictRename, []string{"index.html", "summary-1.csv", "summary-2.csv", "summary.csv"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.onConflict, func(t *testing.T) {
+			fake := newFolderFake(t)
+			defer fake.Close()
+			client := newTestClient(t, fake, nil)
+
+			// The flattened summary.csv files collide with each other and with a local file
+			dest := t.TempDir()
+			os.WriteFile(filepath.Join(dest, "summary.csv"), []byte("local"), 0644)
+
+			opts := DownloadOptions{Flatten: true, OnConflict: tt.onConflict}
+			result, err := client.DownloadFolder(context.Background(), "reports/2024/", dest, opts)
+			if err != nil {
+				t.Fatalf("DownloadFolder() error = %v", err)
+			}
+			if len(result.SkippedFiles) != tt.wantSkip {
+				t.Errorf("SkippedFiles = %v, want %d", result.SkippedFiles, tt.wantSkip)
+			}
+			entries, _ := os.ReadDir(dest)
+			var files []string
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("files = %v, want %v", files, tt.wantFiles)
+			}
+			if tt.onConflict == ConflictSkip {
+				if data, _ := os.ReadFile(filepath.Join(dest, "summary.csv")); string(data) != "local" {
+					t.Errorf("skipped file was replaced by %q", data)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanDownloadFolder(t *testing.T) {
+	fake := newFolderFake(t)
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	dest := filepath.Join(t.TempDir(), "out")
+
+	plan, err := client.PlanDownloadFolder(context.Background(), "reports/2024", dest, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("PlanDownloadFolder() error = %v", err)
+	}
+	if plan.TotalFiles != 3 {
+		t.Errorf("TotalFiles = %d, want 3", plan.TotalFiles)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("the plan created the destination")
+	}
+	plan, err = client.PlanDownloadFolder(context.Background(), "reports/2024", dest, DownloadOptions{Regex: regexp.MustCompile(`/\d{2}/summary\.csv$`)})
+	if err != nil {
+		t.Fatalf("PlanDownloadFolder() error = %v", err)
+	}
+	if plan.TotalFiles != 2 {
+		t.Errorf("TotalFiles with regex = %d, want the 2 monthly summaries", plan.TotalFiles)
+	}
+}
+
+func TestDownloadFolders(t *testing.T) {
+	fake := newFolderFake(t)
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	dest := filepath.Join(t.TempDir(), "out")
+
+	folders := []string{"reports/2024/01", "reports/2024/02/"}
+	result, err := client.DownloadFolders(context.Background(), folders, dest, false, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("DownloadFolders() error = %v", err)
+	}
+	if result.TotalFiles != 2 || result.TotalSizeBytes != 6 || result.SourcePath != "reports/2024/01,reports/2024/02/" {
+		t.Errorf("DownloadFolders() = %d files, %d bytes from %q, want 2 files, 6 bytes", result.TotalFiles, result.TotalSizeBytes, result.SourcePath)
+	}
+	// Both files are called summary.csv, each folder gets its own directory
+	for folder, want := range map[string]string{"01": "jan", "02": "feb"} {
+		if data, err := os.ReadFile(filepath.Join(dest, "reports", "2024", folder, "summary.csv")); err != nil || string(data) != want {
+			t.Errorf("%s/summary.csv = %q, %v, want %q", folder, data, err, want)
+		}
+	}
+
+	plan, err := client.PlanDownloadFolders(context.Background(), []string{"reports/2024", "other"}, dest, true, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("PlanDownloadFolders() error = %v", err)
+	}
+	if plan.TotalFiles != 4 {
+		t.Errorf("PlanDownloadFolders() TotalFiles = %d, want 4", plan.TotalFiles)
+	}
+}
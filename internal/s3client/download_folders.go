@@ -0,0 +1,81 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+// FolderDestination returns where a download of several folders puts the files of folder:
+// the directory of destinationPath named after it, so that equal file names of different
+// folders do not collide.
+func FolderDestination(destinationPath, folder string) string {
+	return filepath.Join(destinationPath, filepath.FromSlash(strings.Trim(folder, "/")))
+}
+
+// DownloadFolders downloads the latest file of every folder, or with recursive every file
+// below it, into its FolderDestination. The result combines the downloads of all folders.
+// The conditions of opts do not apply.
+func (c *Client) DownloadFolders(ctx context.Context, folders []string, destinationPath string, recursive bool, opts DownloadOptions) (*models.DownloadResult, error) {
+	startTime := time.Now()
+
+	download := c.DownloadFolder
+	if !recursive {
+		opts.IfNoneMatch, opts.IfModifiedSince = "", time.Time{}
+		download = c.DownloadLatestFile
+	}
+
+	result := &models.DownloadResult{
+		BucketName:    c.config.BucketName,
+		SourcePath:    storage.JoinFolders(folders),
+		Items:         []models.DownloadItem{},
+		OperationTime: utils.FormatTime(startTime),
+	}
+	for _, folder := range folders {
+		downloaded, err := download(ctx, folder, FolderDestination(destinationPath, folder), opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", folder, err)
+		}
+		result.Items = append(result.Items, downloaded.Items...)
+		result.CreatedDirectories = append(result.CreatedDirectories, downloaded.CreatedDirectories...)
+		result.SkippedFiles = append(result.SkippedFiles, downloaded.SkippedFiles...)
+		result.TotalSizeBytes += downloaded.TotalSizeBytes
+	}
+
+	duration := time.Since(startTime)
+	throughput := utils.BytesPerSecond(result.TotalSizeBytes, duration)
+	result.TotalFiles = len(result.Items)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.DownloadDuration = duration.String()
+	result.ThroughputBytes = throughput
+	result.ThroughputHuman = utils.FormatSpeed(throughput)
+	return result, nil
+}
+
+// PlanDownloadFolders reports what DownloadFolders would download without touching the
+// local file system.
+func (c *Client) PlanDownloadFolders(ctx context.Context, folders []string, destinationPath string, recursive bool, opts DownloadOptions) (*models.TransferPlan, error) {
+	plan := newTransferPlan(PlanActionDownload, c.config.BucketName, storage.JoinFolders(folders), destinationPath)
+	for _, folder := range folders {
+		var planned *models.TransferPlan
+		var err error
+		if recursive {
+			planned, err = c.PlanDownloadFolder(ctx, folder, FolderDestination(destinationPath, folder), opts)
+		} else {
+			planned, err = c.PlanDownload(ctx, folder, FolderDestination(destinationPath, folder), opts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", folder, err)
+		}
+		for _, item := range planned.Items {
+			addPlanItem(plan, item)
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,70 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestDownloadLatestFileIsAtomic(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	// Objects encrypted with SSE-C cannot be read without the key
+	client.SetSSECustomerKey(newSSECustomerKey(bytes.Repeat([]byte{3}, 32)))
+	src := filepath.Join(t.TempDir(), "db.sql")
+	os.WriteFile(src, []byte("new dump"), 0644)
+	if _, err := client.UploadFiles(ctx, []string{src}, "backups", false, "", nil, time.Time{}); err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	client.SetSSECustomerKey(nil)
+
+	dest := t.TempDir()
+	os.WriteFile(filepath.Join(dest, "db.sql"), []byte("old dump"), 0644)
+	if _, err := client.DownloadLatestFile(ctx, "backups", dest, DownloadOptions{}); err == nil {
+		t.Fatal("DownloadLatestFile() succeeded without the SSE-C key")
+	}
+
+	entries, _ := os.ReadDir(dest)
+	if len(entries) != 1 {
+		t.Errorf("destination holds %d files, want only db.sql", len(entries))
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "db.sql")); string(data) != "old dump" {
+		t.Errorf("db.sql = %q, want the old file untouched", data)
+	}
+}
+
+func TestDownloadLatestFileVerifiesChecksum(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	src := filepath.Join(t.TempDir(), "db.sql")
+	os.WriteFile(src, []byte("dump"), 0644)
+	if _, err := client.UploadFiles(ctx, []string{src}, "backups", false, "", nil, time.Time{}); err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+
+	dest := t.TempDir()
+	result, err := client.DownloadLatestFile(ctx, "backups", dest, DownloadOptions{VerifyChecksum: true})
+	if err != nil {
+		t.Fatalf("DownloadLatestFile() error = %v", err)
+	}
+	if !result.Items[0].ChecksumVerified {
+		t.Error("ChecksumVerified = false")
+	}
+	info, err := os.Stat(filepath.Join(dest, "db.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+}
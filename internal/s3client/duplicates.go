@@ -0,0 +1,161 @@
+package s3client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// duplicateCandidate is what the duplicates report keeps of a listed object.
+type duplicateCandidate struct {
+	key          string
+	etag         string
+	lastModified time.Time
+}
+
+// DuplicatesReport finds objects under prefix with identical content. Objects are
+// grouped by size and ETag. An ETag identifies the content of single-part uploads, but
+// the same file uploaded with different part sizes gets different multipart ETags, so
+// with verifyContent objects of the same size are downloaded and grouped by their
+// SHA-256 instead. Empty objects and the trash are left out.
+func (c *Client) DuplicatesReport(ctx context.Context, prefix string, verifyContent bool) (*models.DuplicatesReport, error) {
+	startTime := time.Now()
+
+	var mu sync.Mutex
+	var scanned int64
+	bySize := make(map[int64][]duplicateCandidate)
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		key := aws.ToString(obj.Key)
+		size := aws.ToInt64(obj.Size)
+
+		mu.Lock()
+		defer mu.Unlock()
+		scanned++
+		if size > 0 && !c.inTrash(key) {
+			bySize[size] = append(bySize[size], duplicateCandidate{
+				key:          key,
+				etag:         objectETag(obj),
+				lastModified: aws.ToTime(obj.LastModified),
+			})
+		}
+		return false
+	})
+	if _, err := lister.listAll(ctx, prefix); err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	report := &models.DuplicatesReport{
+		BucketName:      c.config.BucketName,
+		Prefix:          prefix,
+		VerifiedContent: verifyContent,
+		Sets:            []models.DuplicateSet{},
+		ScannedObjects:  scanned,
+		OperationTime:   utils.FormatTime(startTime),
+	}
+
+	for size, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		groups := groupByETag(candidates)
+		if verifyContent {
+			var err error
+			groups, err = c.groupBySHA256(ctx, candidates)
+			if err != nil {
+				return nil, err
+			}
+			report.HashedObjects += len(candidates)
+		}
+
+		for digest, group := range groups {
+			if len(group) < 2 {
+				continue
+			}
+			sort.Slice(group, func(i, j int) bool {
+				if !group[i].lastModified.Equal(group[j].lastModified) {
+					return group[i].lastModified.Before(group[j].lastModified)
+				}
+				return group[i].key < group[j].key
+			})
+
+			set := models.DuplicateSet{SizeBytes: size, SizeHuman: utils.FormatBytes(size)}
+			if verifyContent {
+				set.SHA256 = digest
+			} else {
+				set.ETag = digest
+			}
+			for _, candidate := range group {
+				set.Keys = append(set.Keys, candidate.key)
+			}
+			set.SavingsBytes = size * int64(len(group)-1)
+			set.SavingsHuman = utils.FormatBytes(set.SavingsBytes)
+
+			report.Sets = append(report.Sets, set)
+			report.DuplicateObjects += len(group) - 1
+			report.SavingsBytes += set.SavingsBytes
+		}
+	}
+
+	sort.Slice(report.Sets, func(i, j int) bool {
+		if report.Sets[i].SavingsBytes != report.Sets[j].SavingsBytes {
+			return report.Sets[i].SavingsBytes > report.Sets[j].SavingsBytes
+		}
+		return report.Sets[i].Keys[0] < report.Sets[j].Keys[0]
+	})
+	report.SetCount = len(report.Sets)
+	report.SavingsHuman = utils.FormatBytes(report.SavingsBytes)
+	return report, nil
+}
+
+func groupByETag(candidates []duplicateCandidate) map[string][]duplicateCandidate {
+	groups := make(map[string][]duplicateCandidate)
+	for _, candidate := range candidates {
+		// Without an ETag nothing is known about the content
+		if candidate.etag != "" {
+			groups[candidate.etag] = append(groups[candidate.etag], candidate)
+		}
+	}
+	return groups
+}
+
+func (c *Client) groupBySHA256(ctx context.Context, candidates []duplicateCandidate) (map[string][]duplicateCandidate, error) {
+	groups := make(map[string][]duplicateCandidate)
+	for _, candidate := range candidates {
+		digest, err := c.objectSHA256(ctx, candidate.key)
+		if err != nil {
+			return nil, err
+		}
+		groups[digest] = append(groups[digest], candidate)
+	}
+	return groups, nil
+}
+
+// objectSHA256 streams the content of key through SHA-256.
+func (c *Client) objectSHA256(ctx context.Context, key string) (string, error) {
+	resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
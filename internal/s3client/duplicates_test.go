@@ -0,0 +1,54 @@
+package s3client
+
+import (
+	"context"
+	"s3manager/internal/s3fake"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDuplicatesReport(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	old := time.Now().Add(-48 * time.Hour)
+	dump := []byte("the same database dump")
+	fake.PutObject("test-bucket", "uploads/dump.sql", dump, old)
+	fake.PutObject("test-bucket", "uploads/copy/dump.sql", dump, old.Add(time.Hour))
+	fake.PutObject("test-bucket", "uploads/dump (1).sql", dump, old.Add(2*time.Hour))
+	// Same size and content, but uploaded in parts
+	fake.PutObject("test-bucket", "uploads/multipart.sql", dump, old.Add(3*time.Hour))
+	fake.SetETag("test-bucket", "uploads/multipart.sql", "0123456789abcdef0123456789abcdef-2")
+	// Same size, other content
+	fake.PutObject("test-bucket", "uploads/other.sql", []byte("another database dump!"), old)
+	fake.PutObject("test-bucket", "uploads/empty-1", nil, old)
+	fake.PutObject("test-bucket", "uploads/empty-2", nil, old)
+	client := newTestClient(t, fake, nil)
+
+	report, err := client.DuplicatesReport(context.Background(), "uploads/", false)
+	if err != nil {
+		t.Fatalf("DuplicatesReport() error = %v", err)
+	}
+	wantKeys := []string{"uploads/dump.sql", "uploads/copy/dump.sql", "uploads/dump (1).sql"}
+	if report.SetCount != 1 || !slices.Equal(report.Sets[0].Keys, wantKeys) {
+		t.Fatalf("sets = %+v, want one set of %v", report.Sets, wantKeys)
+	}
+	size := int64(len(dump))
+	if report.DuplicateObjects != 2 || report.SavingsBytes != 2*size || report.Sets[0].ETag == "" {
+		t.Errorf("report = %+v, want 2 duplicates saving %d bytes", report, 2*size)
+	}
+	if report.ScannedObjects != 7 {
+		t.Errorf("ScannedObjects = %d, want 7", report.ScannedObjects)
+	}
+
+	report, err = client.DuplicatesReport(context.Background(), "uploads/", true)
+	if err != nil {
+		t.Fatalf("DuplicatesReport() error = %v", err)
+	}
+	if report.SetCount != 1 || len(report.Sets[0].Keys) != 4 || report.Sets[0].SHA256 == "" {
+		t.Errorf("sets = %+v, want the multipart copy found by content", report.Sets)
+	}
+	if report.HashedObjects != 5 {
+		t.Errorf("HashedObjects = %d, want the 5 objects of the same size", report.HashedObjects)
+	}
+}
@@ -0,0 +1,383 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	EventActionDownload  = "download"
+	EventActionTag       = "tag"
+	EventActionReplicate = "replicate"
+	EventActionWebhook   = "webhook"
+
+	eventActionOK     = "ok"
+	eventActionFailed = "failed"
+
+	// Wait between receive attempts after SQS returned an error
+	receiveRetryDelay = 5 * time.Second
+)
+
+// S3Event is a single record of an S3 event notification.
+type S3Event struct {
+	Name   string `json:"event_name"`
+	Time   string `json:"event_time"`
+	Bucket string `json:"bucket_name"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag,omitempty"`
+}
+
+// EventActions configures what is done for every accepted event. Empty fields are skipped.
+type EventActions struct {
+	// DownloadDir receives a copy of the object, keeping its key as relative path.
+	DownloadDir string
+	// Tags are merged into the object's existing tags.
+	Tags map[string]string
+	// ReplicateBucket receives a server-side copy of the object under ReplicatePrefix.
+	ReplicateBucket string
+	ReplicatePrefix string
+	// WebhookURL receives the event as a JSON POST.
+	WebhookURL string
+}
+
+type ListenOptions struct {
+	QueueURL string
+	// SQSEndpoint overrides the SQS endpoint, e.g. for LocalStack or ElasticMQ.
+	SQSEndpoint string
+	// EventPrefix selects events by name such as "ObjectCreated:"; empty accepts all.
+	EventPrefix string
+	// KeyPrefix selects events by object key.
+	KeyPrefix string
+	Actions   EventActions
+	// Once stops after the first receive call instead of polling until ctx is cancelled.
+	Once              bool
+	WaitSeconds       int32
+	VisibilityTimeout int32
+}
+
+type s3EventRecord struct {
+	EventName string `json:"eventName"`
+	EventTime string `json:"eventTime"`
+	S3        struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key  string `json:"key"`
+			Size int64  `json:"size"`
+			ETag string `json:"eTag"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+type s3EventMessage struct {
+	Records []s3EventRecord `json:"Records"`
+	// Set on the test message S3 sends when notifications are configured
+	Event string `json:"Event"`
+	// Set when the notification was delivered through SNS
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// ParseS3Events decodes an S3 event notification delivered directly or through SNS.
+// The test event S3 sends when notifications are configured yields no events.
+func ParseS3Events(body string) ([]S3Event, error) {
+	var message s3EventMessage
+	if err := json.Unmarshal([]byte(body), &message); err != nil {
+		return nil, fmt.Errorf("invalid event message: %w", err)
+	}
+
+	if message.Type == "Notification" && message.Message != "" {
+		return ParseS3Events(message.Message)
+	}
+	if message.Event == "s3:TestEvent" {
+		return nil, nil
+	}
+	if message.Records == nil {
+		return nil, fmt.Errorf("message is not an S3 event notification")
+	}
+
+	events := make([]S3Event, 0, len(message.Records))
+	for _, record := range message.Records {
+		// Keys are form-encoded in notifications, with spaces as '+'
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object key %q: %w", record.S3.Object.Key, err)
+		}
+		events = append(events, S3Event{
+			Name:   record.EventName,
+			Time:   record.EventTime,
+			Bucket: record.S3.Bucket.Name,
+			Key:    key,
+			Size:   record.S3.Object.Size,
+			ETag:   record.S3.Object.ETag,
+		})
+	}
+	return events, nil
+}
+
+// ListenEvents consumes S3 event notifications from an SQS queue and runs the configured
+// actions for each event, passing every result to handle. A message is deleted once all
+// of its events were handled; otherwise it becomes visible again and is retried. It
+// returns when ctx is cancelled.
+func (c *Client) ListenEvents(ctx context.Context, opts ListenOptions, handle func(models.EventResult)) error {
+	if opts.QueueURL == "" {
+		return fmt.Errorf("queue URL is required")
+	}
+
+	client := sqs.NewFromConfig(c.awsConfig, func(o *sqs.Options) {
+		if opts.SQSEndpoint != "" {
+			o.BaseEndpoint = aws.String(opts.SQSEndpoint)
+		}
+	})
+
+	for {
+		input := &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(opts.QueueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     opts.WaitSeconds,
+		}
+		if opts.VisibilityTimeout > 0 {
+			input.VisibilityTimeout = opts.VisibilityTimeout
+		}
+
+		resp, err := client.ReceiveMessage(ctx, input)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && opts.Once:
+			return fmt.Errorf("failed to receive messages: %w", err)
+		case err != nil:
+			slog.Warn("Failed to receive messages, retrying", "error", err)
+			select {
+			case <-time.After(receiveRetryDelay):
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+
+		for _, msg := range resp.Messages {
+			if c.processMessage(ctx, opts, msg, handle) {
+				_, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+					QueueUrl:      aws.String(opts.QueueURL),
+					ReceiptHandle: msg.ReceiptHandle,
+				})
+				if err != nil {
+					slog.Warn("Failed to delete processed message", "message_id", aws.ToString(msg.MessageId), "error", err)
+				}
+			}
+		}
+
+		if opts.Once {
+			return nil
+		}
+	}
+}
+
+// processMessage handles every event in msg and reports whether it can be deleted.
+func (c *Client) processMessage(ctx context.Context, opts ListenOptions, msg sqstypes.Message, handle func(models.EventResult)) bool {
+	messageID := aws.ToString(msg.MessageId)
+
+	events, err := ParseS3Events(aws.ToString(msg.Body))
+	if err != nil {
+		// Left on the queue so that its redrive policy can move it to a dead-letter queue
+		slog.Warn("Skipping message that is not an S3 event", "message_id", messageID, "error", err)
+		return false
+	}
+
+	done := true
+	for _, event := range events {
+		result := c.HandleEvent(ctx, event, opts)
+		result.MessageID = messageID
+		handle(result)
+
+		for _, action := range result.Actions {
+			if action.Status != eventActionOK {
+				done = false
+			}
+		}
+	}
+	return done && ctx.Err() == nil
+}
+
+// HandleEvent runs the configured actions for one event. Events not matching the
+// event or key prefix are marked as skipped.
+func (c *Client) HandleEvent(ctx context.Context, event S3Event, opts ListenOptions) models.EventResult {
+	result := models.EventResult{
+		EventName:     event.Name,
+		EventTime:     event.Time,
+		BucketName:    event.Bucket,
+		Key:           event.Key,
+		Size:          event.Size,
+		Actions:       []models.EventAction{},
+		OperationTime: utils.FormatTime(time.Now()),
+	}
+
+	if !strings.HasPrefix(event.Name, opts.EventPrefix) || !strings.HasPrefix(event.Key, opts.KeyPrefix) {
+		result.Skipped = true
+		return result
+	}
+
+	run := func(action, target string, fn func() error) {
+		entry := models.EventAction{Action: action, Target: target, Status: eventActionOK}
+		if err := fn(); err != nil {
+			entry.Status = eventActionFailed
+			entry.Error = err.Error()
+		}
+		result.Actions = append(result.Actions, entry)
+	}
+
+	actions := opts.Actions
+	if actions.DownloadDir != "" {
+		localPath, err := eventLocalPath(actions.DownloadDir, event.Key)
+		run(EventActionDownload, localPath, func() error {
+			if err != nil {
+				return err
+			}
+			return c.downloadObject(ctx, event.Bucket, event.Key, localPath)
+		})
+	}
+	if len(actions.Tags) > 0 {
+		run(EventActionTag, event.Key, func() error {
+			return c.mergeObjectTags(ctx, event.Bucket, event.Key, actions.Tags)
+		})
+	}
+	if actions.ReplicateBucket != "" {
+		targetKey := actions.ReplicatePrefix + event.Key
+		run(EventActionReplicate, actions.ReplicateBucket+"/"+targetKey, func() error {
+			return c.copyObject(ctx, event.Bucket, event.Key, actions.ReplicateBucket, targetKey)
+		})
+	}
+	if actions.WebhookURL != "" {
+		run(EventActionWebhook, actions.WebhookURL, func() error {
+			return postEventWebhook(ctx, actions.WebhookURL, event)
+		})
+	}
+
+	return result
+}
+
+// eventLocalPath maps an object key below dir, refusing keys that would escape it.
+func eventLocalPath(dir, key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to download key outside the destination: %q", key)
+	}
+	return filepath.Join(dir, cleaned), nil
+}
+
+func (c *Client) downloadObject(ctx context.Context, bucket, key, localPath string) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	file, err := utils.CreateTempFor(localPath)
+	if err != nil {
+		return err
+	}
+
+	downloader := manager.NewDownloader(c.s3Client)
+	_, err = downloader.Download(ctx, file, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = utils.CommitTemp(file.Name(), localPath)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c *Client) mergeObjectTags(ctx context.Context, bucket, key string, tags map[string]string) error {
+	current, err := c.s3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object tags: %w", err)
+	}
+
+	merged := make([]types.Tag, 0, len(current.TagSet)+len(tags))
+	for _, tag := range current.TagSet {
+		if _, replaced := tags[aws.ToString(tag.Key)]; !replaced {
+			merged = append(merged, tag)
+		}
+	}
+	for k, v := range tags {
+		merged = append(merged, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	_, err = c.s3Client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: merged},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object tags: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) copyObject(ctx context.Context, sourceBucket, sourceKey, targetBucket, targetKey string) error {
+	_, err := c.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(targetBucket),
+		Key:        aws.String(targetKey),
+		CopySource: aws.String(copySource(sourceBucket, sourceKey)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	return nil
+}
+
+func postEventWebhook(ctx context.Context, webhookURL string, event S3Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package s3client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"s3manager/internal/models"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testEventBody = `{"Records":[{"eventName":"ObjectCreated:Put","eventTime":"2024-05-01T10:00:00.000Z",` +
+	`"s3":{"bucket":{"name":"test-bucket"},"object":{"key":"uploads/my+report%282%29.csv","size":42,"eTag":"abc"}}}]}`
+
+func TestParseS3Events(t *testing.T) {
+	events, err := ParseS3Events(testEventBody)
+	if err != nil {
+		t.Fatalf("ParseS3Events() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	event := events[0]
+	if event.Key != "uploads/my report(2).csv" || event.Bucket != "test-bucket" || event.Size != 42 || event.Name != "ObjectCreated:Put" {
+		t.Errorf("event = %+v, want the decoded record", event)
+	}
+
+	wrapped, _ := json.Marshal(map[string]string{"Type": "Notification", "Message": testEventBody})
+	events, err = ParseS3Events(string(wrapped))
+	if err != nil || len(events) != 1 || events[0].Key != "uploads/my report(2).csv" {
+		t.Errorf("SNS wrapped message = %+v, %v, want the inner record", events, err)
+	}
+
+	events, err = ParseS3Events(`{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"test-bucket"}`)
+	if err != nil || len(events) != 0 {
+		t.Errorf("test event = %+v, %v, want no events", events, err)
+	}
+
+	if _, err := ParseS3Events(`{"hello":"world"}`); err == nil {
+		t.Errorf("ParseS3Events() should reject messages that are not S3 events")
+	}
+}
+
+func TestEventLocalPath(t *testing.T) {
+	path, err := eventLocalPath("/data", "uploads/a.txt")
+	if err != nil || path != filepath.Join("/data", "uploads", "a.txt") {
+		t.Errorf("eventLocalPath() = %s, %v, want /data/uploads/a.txt", path, err)
+	}
+
+	for _, key := range []string{"../etc/passwd", "a/../../b", ".."} {
+		if _, err := eventLocalPath("/data", key); err == nil {
+			t.Errorf("eventLocalPath(%q) should be rejected", key)
+		}
+	}
+}
+
+func TestHandleEventRunsActions(t *testing.T) {
+	var mu sync.Mutex
+	var putTagging, webhookBody string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/hook":
+			webhookBody = string(body)
+		case r.Method == http.MethodGet && r.URL.Query().Has("tagging"):
+			fmt.Fprint(w, `<Tagging><TagSet><Tag><Key>owner</Key><Value>ops</Value></Tag><Tag><Key>status</Key><Value>new</Value></Tag></TagSet></Tagging>`)
+		case r.Method == http.MethodPut && r.URL.Query().Has("tagging"):
+			putTagging = string(body)
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, "content")
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	})
+	client := newTestClient(t, handler, nil)
+
+	tempDir, err := os.MkdirTemp("", "events-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	opts := ListenOptions{
+		EventPrefix: "ObjectCreated:",
+		KeyPrefix:   "uploads/",
+		Actions: EventActions{
+			DownloadDir: tempDir,
+			Tags:        map[string]string{"status": "received"},
+			WebhookURL:  client.config.ApiURL + "/hook",
+		},
+	}
+	event := S3Event{Name: "ObjectCreated:Put", Bucket: "test-bucket", Key: "uploads/a.txt", Size: 7}
+
+	result := client.HandleEvent(context.Background(), event, opts)
+	if result.Skipped || len(result.Actions) != 3 {
+		t.Fatalf("result = %+v, want three actions", result)
+	}
+	for _, action := range result.Actions {
+		if action.Status != eventActionOK {
+			t.Errorf("action %s failed: %s", action.Action, action.Error)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "uploads", "a.txt"))
+	if err != nil || string(data) != "content" {
+		t.Errorf("downloaded file = %q, %v, want the object content", data, err)
+	}
+
+	if !strings.Contains(putTagging, "<Key>owner</Key><Value>ops</Value>") || !strings.Contains(putTagging, "<Key>status</Key><Value>received</Value>") ||
+		strings.Contains(putTagging, "<Value>new</Value>") {
+		t.Errorf("put tagging = %s, want existing tags merged with the new ones", putTagging)
+	}
+
+	if !strings.Contains(webhookBody, `"key":"uploads/a.txt"`) {
+		t.Errorf("webhook body = %s, want the event", webhookBody)
+	}
+
+	event.Name = "ObjectRemoved:Delete"
+	if result := client.HandleEvent(context.Background(), event, opts); !result.Skipped || len(result.Actions) != 0 {
+		t.Errorf("result = %+v, want events not matching the filter skipped", result)
+	}
+}
+
+func TestListenEventsDeletesHandledMessages(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	var webhookCalls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSQS.ReceiveMessage":
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			json.NewEncoder(w).Encode(map[string]any{"Messages": []map[string]string{
+				{"MessageId": "m-1", "ReceiptHandle": "r-1", "Body": testEventBody, "MD5OfBody": md5Hex(testEventBody)},
+				{"MessageId": "m-2", "ReceiptHandle": "r-2", "Body": "not json", "MD5OfBody": md5Hex("not json")},
+			}})
+		case "AmazonSQS.DeleteMessage":
+			var input struct{ ReceiptHandle string }
+			json.Unmarshal(body, &input)
+			deleted = append(deleted, input.ReceiptHandle)
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			fmt.Fprint(w, `{}`)
+		default:
+			webhookCalls++
+		}
+	})
+	client := newTestClient(t, handler, nil)
+
+	var results []models.EventResult
+	err := client.ListenEvents(context.Background(), ListenOptions{
+		QueueURL:    client.config.ApiURL + "/123456789012/events",
+		SQSEndpoint: client.config.ApiURL,
+		Actions:     EventActions{WebhookURL: client.config.ApiURL + "/hook"},
+		Once:        true,
+	}, func(result models.EventResult) {
+		results = append(results, result)
+	})
+	if err != nil {
+		t.Fatalf("ListenEvents() error = %v", err)
+	}
+
+	if len(results) != 1 || results[0].MessageID != "m-1" || results[0].Key != "uploads/my report(2).csv" {
+		t.Errorf("results = %+v, want the single S3 event", results)
+	}
+
+	if webhookCalls != 1 {
+		t.Errorf("webhook calls = %d, want 1", webhookCalls)
+	}
+
+	// The unparseable message stays on the queue for its dead-letter policy
+	if len(deleted) != 1 || deleted[0] != "r-1" {
+		t.Errorf("deleted receipts = %v, want only [r-1]", deleted)
+	}
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
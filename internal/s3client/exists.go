@@ -0,0 +1,75 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+type ExistsOptions struct {
+	// MinSize and NewerThan are not checked when zero.
+	MinSize   int64
+	NewerThan time.Time
+}
+
+// Exists looks up key with HeadObject and checks it against the conditions of opts. A
+// missing object or a failed condition is reported in the result, not as an error.
+func (c *Client) Exists(ctx context.Context, key string, opts ExistsOptions) (*models.ExistsResult, error) {
+	result := &models.ExistsResult{
+		BucketName:    c.config.BucketName,
+		Key:           key,
+		Problems:      []string{},
+		OperationTime: utils.FormatTime(time.Now()),
+	}
+	if opts.MinSize > 0 {
+		result.MinSizeBytes = opts.MinSize
+		result.MinSizeHuman = utils.FormatBytes(opts.MinSize)
+	}
+	if !opts.NewerThan.IsZero() {
+		result.NewerThan = utils.FormatTime(opts.NewerThan)
+	}
+
+	head, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if hasErrorCode(err, "NotFound", "NoSuchKey") {
+		result.Status = FreshnessMissing
+		result.Problems = append(result.Problems, fmt.Sprintf("object %q does not exist", key))
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", key, err)
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+	modified := aws.ToTime(head.LastModified)
+	result.SizeBytes = size
+	result.SizeHuman = utils.FormatBytes(size)
+	result.LastModified = utils.FormatTime(modified)
+
+	if !opts.NewerThan.IsZero() && modified.Before(opts.NewerThan) {
+		result.Status = FreshnessStale
+		result.Problems = append(result.Problems, fmt.Sprintf("object was modified at %s, before %s",
+			result.LastModified, result.NewerThan))
+	}
+	if opts.MinSize > 0 && size < opts.MinSize {
+		if result.Status == "" {
+			result.Status = FreshnessTooSmall
+		}
+		result.Problems = append(result.Problems, fmt.Sprintf("object is %s, smaller than %s",
+			result.SizeHuman, result.MinSizeHuman))
+	}
+
+	if result.Status == "" {
+		result.Status = FreshnessOK
+		result.Exists = true
+	}
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package s3client
+
+import (
+	"context"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestExists(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	modified := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	fake.PutObject("test-bucket", "backups/db.sql.gz", make([]byte, 1024), modified)
+	client := newTestClient(t, fake, nil)
+
+	tests := []struct {
+		name     string
+		key      string
+		opts     ExistsOptions
+		status   string
+		problems int
+	}{
+		{"exists", "backups/db.sql.gz", ExistsOptions{}, FreshnessOK, 0},
+		{"conditions met", "backups/db.sql.gz", ExistsOptions{MinSize: 1024, NewerThan: modified.Add(-time.Hour)}, FreshnessOK, 0},
+		{"too small", "backups/db.sql.gz", ExistsOptions{MinSize: 2048}, FreshnessTooSmall, 1},
+		{"stale and too small", "backups/db.sql.gz", ExistsOptions{MinSize: 2048, NewerThan: modified.Add(time.Hour)}, FreshnessStale, 2},
+		{"missing", "backups/web.tar", ExistsOptions{}, FreshnessMissing, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.Exists(context.Background(), tt.key, tt.opts)
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if result.Status != tt.status || len(result.Problems) != tt.problems {
+				t.Errorf("status = %s, problems = %v, want %s with %d problems", result.Status, result.Problems, tt.status, tt.problems)
+			}
+			if result.Exists != (tt.status == FreshnessOK) {
+				t.Errorf("Exists = %t for status %s", result.Exists, result.Status)
+			}
+		})
+	}
+}
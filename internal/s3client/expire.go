@@ -0,0 +1,116 @@
+package s3client
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// ExpiresTag is the object tag that upload --expire-after stamps with the time the
+// object expires, in RFC 3339. ExpireObjects deletes the objects past it.
+const ExpiresTag = "s3manager:expires"
+
+// SetExpiry tags every uploaded object, and its signature, with ExpiresTag set to
+// expires. The zero time uploads objects without an expiry.
+func (c *Client) SetExpiry(expires time.Time) {
+	c.expires = expires
+}
+
+// expiryTagging returns the Tagging header of uploads, nil without an expiry.
+func (c *Client) expiryTagging() *string {
+	if c.expires.IsZero() {
+		return nil
+	}
+	return aws.String(url.Values{ExpiresTag: {c.formatExpiry()}}.Encode())
+}
+
+func (c *Client) formatExpiry() string {
+	if c.expires.IsZero() {
+		return ""
+	}
+	return c.expires.UTC().Format(time.RFC3339)
+}
+
+// ExpireObjects deletes the objects below folder whose ExpiresTag lies in the past.
+// Objects without the tag are kept, like those with a value that is not a time, which
+// are counted as invalid. Protected prefixes and the trash apply as for delete-old.
+func (c *Client) ExpireObjects(ctx context.Context, folder string, dryRun bool) (*models.ExpireResult, error) {
+	if err := c.checkSupported(featureTagging); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	prefix := folder
+	if !strings.HasSuffix(prefix, "/") && prefix != "" {
+		prefix += "/"
+	}
+	objects, err := c.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	objectKey := func(obj types.Object) string { return aws.ToString(obj.Key) }
+	objects, _ = withoutKeys(objects, objectKey, c.inTrash)
+	objects, protectedCount := withoutProtected(c, objects, objectKey)
+
+	result := &models.ExpireResult{
+		BucketName:     c.config.BucketName,
+		Folder:         folder,
+		Items:          []models.ExpireItem{},
+		CheckedCount:   len(objects),
+		ProtectedCount: protectedCount,
+		TrashFolder:    c.TrashFolder(),
+		DryRun:         dryRun,
+	}
+	expiries := make(map[string]time.Time)
+	expired, err := filterTagged(ctx, c, objects, objectKey, func(key string, tags map[string]string) bool {
+		value, ok := tags[ExpiresTag]
+		if !ok {
+			return false
+		}
+		expiresAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			slog.Warn("Ignoring invalid expiry tag", "key", key, "value", value)
+			result.InvalidCount++
+			return false
+		}
+		expiries[key] = expiresAt
+		return !expiresAt.After(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	toDelete := make([]types.ObjectIdentifier, 0, len(expired))
+	for _, obj := range expired {
+		key := objectKey(obj)
+		toDelete = append(toDelete, types.ObjectIdentifier{Key: obj.Key})
+		result.Items = append(result.Items, models.ExpireItem{
+			Key:       key,
+			Size:      aws.ToInt64(obj.Size),
+			ExpiresAt: utils.FormatTime(expiries[key]),
+		})
+		result.TotalSizeBytes += aws.ToInt64(obj.Size)
+	}
+	result.Count = len(result.Items)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.OperationTime = utils.FormatTime(now)
+
+	if dryRun || len(toDelete) == 0 {
+		return result, nil
+	}
+	if err := c.checkMaxDelete(len(toDelete)); err != nil {
+		return nil, err
+	}
+	if _, err := c.removeObjects(ctx, toDelete, nil); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
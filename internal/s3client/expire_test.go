@@ -0,0 +1,62 @@
+package s3client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestExpireObjects(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	upload := func(name string, expires time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(name), 0644)
+		client.SetExpiry(expires)
+		result, err := client.UploadFiles(ctx, []string{path}, "tmp", false, "", nil, time.Time{})
+		if err != nil {
+			t.Fatalf("UploadFiles() error = %v", err)
+		}
+		if want := client.formatExpiry(); result.Items[0].Expires != want {
+			t.Errorf("Expires = %q, want %q", result.Items[0].Expires, want)
+		}
+	}
+	upload("old.bin", time.Now().Add(-time.Hour))
+	upload("new.bin", time.Now().Add(time.Hour))
+	upload("kept.bin", time.Time{})
+	upload("broken.bin", time.Now().Add(-time.Hour))
+	fake.SetTags("test-bucket", "tmp/broken.bin", map[string]string{ExpiresTag: "tomorrow"})
+
+	obj, _ := fake.Object("test-bucket", "tmp/old.bin")
+	if _, ok := obj.Tags[ExpiresTag]; !ok {
+		t.Fatalf("tags = %v, want %s", obj.Tags, ExpiresTag)
+	}
+
+	plan, err := client.ExpireObjects(ctx, "tmp", true)
+	if err != nil {
+		t.Fatalf("ExpireObjects(dry run) error = %v", err)
+	}
+	if plan.Count != 1 || plan.Items[0].Key != "tmp/old.bin" || plan.CheckedCount != 4 || plan.InvalidCount != 1 {
+		t.Errorf("ExpireObjects(dry run) = %+v", plan)
+	}
+	if _, ok := fake.Object("test-bucket", "tmp/old.bin"); !ok {
+		t.Fatal("dry run deleted tmp/old.bin")
+	}
+
+	if _, err := client.ExpireObjects(ctx, "tmp", false); err != nil {
+		t.Fatalf("ExpireObjects() error = %v", err)
+	}
+	want := []string{"tmp/broken.bin", "tmp/kept.bin", "tmp/new.bin"}
+	if keys := fake.Keys("test-bucket"); !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
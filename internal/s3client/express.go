@@ -0,0 +1,61 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Directory buckets (S3 Express One Zone) are named <base>--<zone id>--x-s3, e.g.
+// staging--use1-az4--x-s3. The SDK recognises the suffix and takes care of the zonal
+// endpoint and of the CreateSession tokens the requests are signed with.
+const directoryBucketSuffix = "--x-s3"
+
+var directoryBucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,61}--[a-z0-9-]+-az[0-9]+--x-s3$`)
+
+// isDirectoryBucket reports whether bucket is named like a directory bucket.
+func isDirectoryBucket(bucket string) bool {
+	return strings.HasSuffix(bucket, directoryBucketSuffix)
+}
+
+// validateDirectoryBucket checks the name of a bucket used with --express.
+func validateDirectoryBucket(bucket string) error {
+	if !directoryBucketName.MatchString(bucket) {
+		return fmt.Errorf("invalid directory bucket name %q, expected <name>--<zone id>--x-s3, e.g. staging--use1-az4--x-s3", bucket)
+	}
+	return nil
+}
+
+// listPrefix returns the prefix to list keys under prefix with, and a filter for the
+// listed keys. Directory buckets only accept prefixes that end with a delimiter, so a
+// partial key name is listed from its directory and the rest of the keys filtered out.
+func (c *Client) listPrefix(prefix string) (string, func(key string) bool) {
+	if !c.express || prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix, func(string) bool { return true }
+	}
+	dir := prefix[:strings.LastIndex(prefix, "/")+1]
+	return dir, func(key string) bool { return strings.HasPrefix(key, prefix) }
+}
+
+// directoryBucketCreationDate looks the bucket up with ListDirectoryBuckets, directory
+// buckets are not returned by ListBuckets.
+func (c *Client) directoryBucketCreationDate(ctx context.Context) (*time.Time, error) {
+	paginator := s3.NewListDirectoryBucketsPaginator(c.s3Client, &s3.ListDirectoryBucketsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, bucket := range page.Buckets {
+			if aws.ToString(bucket.Name) == c.config.BucketName {
+				return bucket.CreationDate, nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,83 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"s3manager/config"
+	"testing"
+)
+
+const testDirectoryBucket = "staging--use1-az4--x-s3"
+
+func TestValidateDirectoryBucket(t *testing.T) {
+	tests := []struct {
+		bucket  string
+		wantErr bool
+	}{
+		{testDirectoryBucket, false},
+		{"logs--usw2-lax1-az1--x-s3", false},
+		{"staging", true},
+		{"staging--x-s3", true},
+		{"Staging--use1-az4--x-s3", true},
+	}
+
+	for _, tt := range tests {
+		err := validateDirectoryBucket(tt.bucket)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateDirectoryBucket(%q) error = %v, wantErr %v", tt.bucket, err, tt.wantErr)
+		}
+	}
+}
+
+func TestListPrefix(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		cfg.BucketName = testDirectoryBucket
+	})
+
+	tests := []struct {
+		prefix, listPrefix string
+		key                string
+		match              bool
+	}{
+		{"", "", "backup.tar.gz", true},
+		{"backups/", "backups/", "backups/db.sql", true},
+		{"backups/db-", "backups/", "backups/db-1.sql", true},
+		{"backups/db-", "backups/", "backups/web-1.tar", false},
+		{"db-", "", "db-1.sql", true},
+		{"db-", "", "backups/db-1.sql", false},
+	}
+
+	for _, tt := range tests {
+		listPrefix, matches := client.listPrefix(tt.prefix)
+		if listPrefix != tt.listPrefix {
+			t.Errorf("listPrefix(%q) = %q, want %q", tt.prefix, listPrefix, tt.listPrefix)
+		}
+		if got := matches(tt.key); got != tt.match {
+			t.Errorf("listPrefix(%q) matches %q = %v, want %v", tt.prefix, tt.key, got, tt.match)
+		}
+	}
+}
+
+func TestNewWithDirectoryBucket(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler(), func(cfg *config.Config) {
+		cfg.BucketName = testDirectoryBucket
+	})
+
+	if !client.express {
+		t.Errorf("express = false, want true for a --x-s3 bucket name")
+	}
+	if _, err := client.GetBucketWebsite(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetBucketWebsite() error = %v, want ErrUnsupported", err)
+	}
+	items := []string{"a.txt"}
+	_, err := withTags(context.Background(), client, items, func(key string) string { return key }, map[string]string{"env": "prod"})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("withTags() error = %v, want ErrUnsupported", err)
+	}
+
+	_, err = New(&config.Config{BucketName: "staging", Region: "us-east-1", Express: true})
+	if err == nil {
+		t.Errorf("New() with --express and a general purpose bucket name should return error")
+	}
+}
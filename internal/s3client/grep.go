@@ -0,0 +1,160 @@
+package s3client
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Longest line grep can match, longer lines fail the object
+const maxGrepLine = 16 * 1024 * 1024
+
+type GrepOptions struct {
+	Pattern *regexp.Regexp
+	Prefix  string
+	// Include is a glob the objects must match, like the --pattern of latest.
+	Include string
+	// Workers is the number of objects searched at the same time.
+	Workers int
+	// MaxCount stops searching an object after this many matching lines, 0 for no limit.
+	MaxCount int
+}
+
+// Grep searches the lines of the objects under opts.Prefix for opts.Pattern. Objects
+// are streamed, gzip-compressed ones (.gz keys or Content-Encoding gzip) decompressed on
+// the fly, by a pool of workers that start while the prefix is still being listed. The
+// matches of an object are passed to emit together and in line order; objects finish in
+// no particular order. emit is never called concurrently. Objects that cannot be read
+// are logged and counted as errors without stopping the search.
+func (c *Client) Grep(ctx context.Context, opts GrepOptions, emit func(models.GrepMatch) error) (*models.GrepSummary, error) {
+	if opts.Pattern == nil {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	startTime := time.Now()
+	summary := &models.GrepSummary{
+		BucketName:    c.config.BucketName,
+		Prefix:        opts.Prefix,
+		Pattern:       opts.Pattern.String(),
+		OperationTime: utils.FormatTime(startTime),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objects := make(chan types.Object)
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		if !matchesPattern(aws.ToString(obj.Key), opts.Include) {
+			return false
+		}
+		select {
+		case objects <- obj:
+		case <-ctx.Done():
+		}
+		return false
+	})
+	var listErr error
+	go func() {
+		defer close(objects)
+		_, listErr = lister.listAll(ctx, opts.Prefix)
+	}()
+
+	var mu sync.Mutex
+	var emitErr error
+	var wg sync.WaitGroup
+	for i := 0; i < max(opts.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range objects {
+				key := aws.ToString(obj.Key)
+				matches, err := c.grepObject(ctx, key, opts)
+
+				mu.Lock()
+				summary.SearchedObjects++
+				summary.SearchedBytes += aws.ToInt64(obj.Size)
+				if err != nil && ctx.Err() == nil {
+					slog.Warn("Failed to search object", "key", key, "error", err)
+					summary.Errors++
+				}
+				if len(matches) > 0 {
+					summary.MatchedObjects++
+					summary.Matches += len(matches)
+				}
+				for _, match := range matches {
+					if emitErr != nil {
+						break
+					}
+					if emitErr = emit(match); emitErr != nil {
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if emitErr != nil {
+		return summary, emitErr
+	}
+	if listErr != nil {
+		return summary, fmt.Errorf("failed to list objects: %w", listErr)
+	}
+	if ctx.Err() != nil {
+		return summary, context.Cause(ctx)
+	}
+	return summary, nil
+}
+
+// grepObject returns the matching lines of key.
+func (c *Client) grepObject(ctx context.Context, key string, opts GrepOptions) ([]models.GrepMatch, error) {
+	resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if strings.HasSuffix(key, ".gz") || aws.ToString(resp.ContentEncoding) == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	var matches []models.GrepMatch
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxGrepLine)
+	for line := 1; scanner.Scan(); line++ {
+		if !opts.Pattern.Match(scanner.Bytes()) {
+			continue
+		}
+		matches = append(matches, models.GrepMatch{Key: key, Line: line, Text: scanner.Text()})
+		if opts.MaxCount > 0 && len(matches) >= opts.MaxCount {
+			return matches, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return matches, fmt.Errorf("failed to read: %w", err)
+	}
+	return matches, nil
+}
@@ -0,0 +1,91 @@
+package s3client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"s3manager/internal/models"
+	"s3manager/internal/s3fake"
+	"sort"
+	"testing"
+	"time"
+)
+
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGrep(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	now := time.Now()
+	fake.PutObject("test-bucket", "logs/2024/app-1.log.gz", gzipData(t, "start\nERROR req-42 failed\nok\nerror req-43 failed\n"), now)
+	fake.PutObject("test-bucket", "logs/2024/app-2.log.gz", gzipData(t, "ERROR req-44 failed\n"), now)
+	fake.PutObject("test-bucket", "logs/2024/app-3.log", []byte("ERROR plain text is not included\n"), now)
+	fake.PutObject("test-bucket", "logs/2023/app.log.gz", gzipData(t, "ERROR outside the prefix\n"), now)
+	for i := 0; i < 20; i++ {
+		fake.PutObject("test-bucket", fmt.Sprintf("logs/2024/quiet-%02d.log.gz", i), gzipData(t, "nothing to see\n"), now)
+	}
+	client := newTestClient(t, fake, nil)
+
+	var matches []models.GrepMatch
+	summary, err := client.Grep(context.Background(), GrepOptions{
+		Pattern: regexp.MustCompile(`ERROR req-\d+`),
+		Prefix:  "logs/2024/",
+		Include: "*.log.gz",
+		Workers: 4,
+	}, func(match models.GrepMatch) error {
+		matches = append(matches, match)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Grep() error = %v", err)
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Key < matches[j].Key })
+	want := []models.GrepMatch{
+		{Key: "logs/2024/app-1.log.gz", Line: 2, Text: "ERROR req-42 failed"},
+		{Key: "logs/2024/app-2.log.gz", Line: 1, Text: "ERROR req-44 failed"},
+	}
+	if len(matches) != len(want) || matches[0] != want[0] || matches[1] != want[1] {
+		t.Errorf("matches = %+v, want %+v", matches, want)
+	}
+	if summary.SearchedObjects != 22 || summary.MatchedObjects != 2 || summary.Matches != 2 || summary.Errors != 0 {
+		t.Errorf("summary = %+v, want 2 matches in 2 of 22 objects", summary)
+	}
+
+	// A broken archive is counted without stopping the search, and -m limits each object
+	fake.PutObject("test-bucket", "logs/2024/broken.log.gz", []byte("not gzip"), now)
+	summary, err = client.Grep(context.Background(), GrepOptions{
+		Pattern:  regexp.MustCompile(`(?i)error`),
+		Prefix:   "logs/2024/",
+		Include:  "*.log.gz",
+		Workers:  2,
+		MaxCount: 1,
+	}, func(models.GrepMatch) error { return nil })
+	if err != nil {
+		t.Fatalf("Grep() error = %v", err)
+	}
+	if summary.Errors != 1 || summary.Matches != 2 {
+		t.Errorf("summary = %+v, want 1 error and one match in each of 2 objects", summary)
+	}
+
+	emitErr := errors.New("stdout closed")
+	_, err = client.Grep(context.Background(), GrepOptions{Pattern: regexp.MustCompile(`ERROR`), Prefix: "logs/"},
+		func(models.GrepMatch) error { return emitErr })
+	if !errors.Is(err, emitErr) {
+		t.Errorf("Grep() error = %v, want the emit error", err)
+	}
+}
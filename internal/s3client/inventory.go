@@ -0,0 +1,333 @@
+package s3client
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Formats of inventory snapshots
+const (
+	InventoryJSONL = "jsonl"
+	InventoryCSV   = "csv"
+)
+
+// Kinds of InventoryChange
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+var inventoryColumns = []string{"key", "size", "last_modified", "etag", "storage_class"}
+
+type InventoryOptions struct {
+	// Prefix is where the snapshot is written, its objects are not part of it.
+	Prefix string
+	// Source limits the snapshot to the objects under this prefix.
+	Source string
+	// Format is InventoryJSONL or InventoryCSV.
+	Format string
+}
+
+// CreateInventory lists the objects under opts.Source and uploads the listing as a
+// gzip-compressed snapshot named after the current time under opts.Prefix. Objects are
+// written to a temporary file as their pages arrive, so the memory does not grow with the
+// size of the bucket.
+func (c *Client) CreateInventory(ctx context.Context, opts InventoryOptions) (*models.InventoryResult, error) {
+	if opts.Format != InventoryJSONL && opts.Format != InventoryCSV {
+		return nil, fmt.Errorf("invalid format %q, expected %s or %s", opts.Format, InventoryJSONL, InventoryCSV)
+	}
+	startTime := time.Now()
+	key := fmt.Sprintf("%s%s.%s.gz", opts.Prefix, startTime.UTC().Format("20060102-150405"), opts.Format)
+
+	tempDir, err := utils.TempDir(c.config.TempDir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(tempDir, "s3manager-inventory-*.gz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		if err := utils.CleanupTempFile(tmp.Name()); err != nil {
+			slog.Warn("Failed to clean up temporary snapshot file", "path", tmp.Name(), "error", err)
+		}
+	}()
+
+	w := newInventoryWriter(tmp, opts.Format)
+	var mu sync.Mutex
+	var count, size int64
+	var writeErr error
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		objectKey := aws.ToString(obj.Key)
+		if opts.Prefix != "" && strings.HasPrefix(objectKey, opts.Prefix) {
+			return false
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if writeErr == nil {
+			writeErr = w.write(models.InventoryRecord{
+				Key:          objectKey,
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: utils.FormatTime(aws.ToTime(obj.LastModified)),
+				ETag:         objectETag(obj),
+				StorageClass: string(obj.StorageClass),
+			})
+			count++
+			size += aws.ToInt64(obj.Size)
+		}
+		return false
+	})
+	if _, err := lister.listAll(ctx, opts.Source); err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	if writeErr == nil {
+		writeErr = w.close()
+	}
+	if writeErr != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", writeErr)
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := c.uploadSingleFile(ctx, c.newUploader(), tmp.Name(), key); err != nil {
+		return nil, fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+
+	return &models.InventoryResult{
+		BucketName:        c.config.BucketName,
+		Key:               key,
+		Format:            opts.Format,
+		Source:            opts.Source,
+		ObjectCount:       count,
+		TotalSizeBytes:    size,
+		TotalSizeHuman:    utils.FormatBytes(size),
+		SnapshotSizeBytes: info.Size(),
+		SnapshotSizeHuman: utils.FormatBytes(info.Size()),
+		OperationTime:     utils.FormatTime(startTime),
+		Duration:          time.Since(startTime).Truncate(time.Millisecond).String(),
+	}, nil
+}
+
+// inventoryWriter writes the records of a snapshot through gzip.
+type inventoryWriter struct {
+	gz    *gzip.Writer
+	buf   *bufio.Writer
+	csv   *csv.Writer
+	json  *json.Encoder
+	first bool
+}
+
+func newInventoryWriter(w io.Writer, format string) *inventoryWriter {
+	gz := gzip.NewWriter(w)
+	buf := bufio.NewWriter(gz)
+	iw := &inventoryWriter{gz: gz, buf: buf, first: true}
+	if format == InventoryCSV {
+		iw.csv = csv.NewWriter(buf)
+	} else {
+		iw.json = json.NewEncoder(buf)
+	}
+	return iw
+}
+
+func (w *inventoryWriter) write(record models.InventoryRecord) error {
+	if w.json != nil {
+		return w.json.Encode(record)
+	}
+	if w.first {
+		w.first = false
+		if err := w.csv.Write(inventoryColumns); err != nil {
+			return err
+		}
+	}
+	return w.csv.Write([]string{record.Key, strconv.FormatInt(record.Size, 10), record.LastModified, record.ETag, record.StorageClass})
+}
+
+func (w *inventoryWriter) close() error {
+	if w.csv != nil {
+		if w.first {
+			if err := w.csv.Write(inventoryColumns); err != nil {
+				return err
+			}
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+// DiffInventory compares the snapshots from and to, given as keys in the bucket. Objects
+// are matched by key, and changed when their size or ETag differs. Changes are sorted by
+// key; the snapshot from is held in memory.
+func (c *Client) DiffInventory(ctx context.Context, from, to string) (*models.InventoryDiff, error) {
+	startTime := time.Now()
+
+	previous := make(map[string]models.InventoryRecord)
+	err := c.readInventory(ctx, from, func(record models.InventoryRecord) error {
+		previous[record.Key] = record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.InventoryDiff{
+		BucketName:    c.config.BucketName,
+		From:          from,
+		To:            to,
+		Changes:       []models.InventoryChange{},
+		OperationTime: utils.FormatTime(startTime),
+	}
+	err = c.readInventory(ctx, to, func(record models.InventoryRecord) error {
+		old, ok := previous[record.Key]
+		delete(previous, record.Key)
+		change := models.InventoryChange{
+			Key:          record.Key,
+			Size:         record.Size,
+			ETag:         record.ETag,
+			LastModified: record.LastModified,
+		}
+		switch {
+		case !ok:
+			change.Change = ChangeAdded
+			diff.AddedCount++
+		case old.Size != record.Size || old.ETag != record.ETag:
+			change.Change = ChangeChanged
+			change.PreviousSize = old.Size
+			change.PreviousETag = old.ETag
+			diff.ChangedCount++
+		default:
+			diff.UnchangedCount++
+			return nil
+		}
+		diff.Changes = append(diff.Changes, change)
+		diff.SizeDeltaBytes += record.Size - old.Size
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, old := range previous {
+		diff.Changes = append(diff.Changes, models.InventoryChange{
+			Key:          old.Key,
+			Change:       ChangeRemoved,
+			PreviousSize: old.Size,
+			PreviousETag: old.ETag,
+			LastModified: old.LastModified,
+		})
+		diff.RemovedCount++
+		diff.SizeDeltaBytes -= old.Size
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].Key < diff.Changes[j].Key })
+
+	diff.SizeDeltaHuman = utils.FormatBytes(diff.SizeDeltaBytes)
+	if diff.SizeDeltaBytes < 0 {
+		diff.SizeDeltaHuman = "-" + utils.FormatBytes(-diff.SizeDeltaBytes)
+	}
+	return diff, nil
+}
+
+// readInventory calls fn for every record of the snapshot at key. The format is taken
+// from the key, ".csv.gz" or ".jsonl.gz".
+func (c *Client) readInventory(ctx context.Context, key string, fn func(models.InventoryRecord) error) error {
+	var format string
+	switch {
+	case strings.HasSuffix(key, "."+InventoryCSV+".gz"):
+		format = InventoryCSV
+	case strings.HasSuffix(key, "."+InventoryJSONL+".gz"):
+		format = InventoryJSONL
+	default:
+		return fmt.Errorf("%s is not an inventory snapshot, expected a .jsonl.gz or .csv.gz key", key)
+	}
+
+	resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", key, err)
+	}
+	if format == InventoryCSV {
+		err = readInventoryCSV(gz, fn)
+	} else {
+		err = readInventoryJSONL(gz, fn)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", key, err)
+	}
+	return nil
+}
+
+func readInventoryJSONL(r io.Reader, fn func(models.InventoryRecord) error) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var record models.InventoryRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func readInventoryCSV(r io.Reader, fn func(models.InventoryRecord) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(inventoryColumns)
+	if _, err := reader.Read(); err != nil {
+		return fmt.Errorf("missing header: %w", err)
+	}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		size, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid size of %s: %w", row[0], err)
+		}
+		record := models.InventoryRecord{Key: row[0], Size: size, LastModified: row[2], ETag: row[3], StorageClass: row[4]}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package s3client
+
+import (
+	"context"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInventoryCreateAndDiff(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	modified := time.Now().Add(-time.Hour)
+	fake.PutObject("test-bucket", "backups/db.sql", []byte("dump v1"), modified)
+	fake.PutObject("test-bucket", "backups/web.tar", []byte("web"), modified)
+	fake.PutObject("test-bucket", "backups/old.tar", []byte("old archive"), modified)
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	first, err := client.CreateInventory(ctx, InventoryOptions{Prefix: "inventory/", Format: InventoryJSONL})
+	if err != nil {
+		t.Fatalf("CreateInventory() error = %v", err)
+	}
+	if first.ObjectCount != 3 || !strings.HasPrefix(first.Key, "inventory/") || !strings.HasSuffix(first.Key, ".jsonl.gz") {
+		t.Errorf("first snapshot = %+v, want 3 objects in inventory/*.jsonl.gz", first)
+	}
+
+	fake.PutObject("test-bucket", "backups/db.sql", []byte("dump v2 is larger"), time.Now())
+	fake.PutObject("test-bucket", "backups/new.tar", []byte("new"), time.Now())
+	if _, err := client.Delete(ctx, []string{"backups/old.tar"}); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := client.CreateInventory(ctx, InventoryOptions{Prefix: "inventory/", Format: InventoryCSV})
+	if err != nil {
+		t.Fatalf("CreateInventory() error = %v", err)
+	}
+	// The first snapshot is under the inventory prefix and left out
+	if second.ObjectCount != 3 || !strings.HasSuffix(second.Key, ".csv.gz") {
+		t.Errorf("second snapshot = %+v, want 3 objects in a .csv.gz", second)
+	}
+
+	diff, err := client.DiffInventory(ctx, first.Key, second.Key)
+	if err != nil {
+		t.Fatalf("DiffInventory() error = %v", err)
+	}
+	want := []struct{ key, change string }{
+		{"backups/db.sql", ChangeChanged},
+		{"backups/new.tar", ChangeAdded},
+		{"backups/old.tar", ChangeRemoved},
+	}
+	if len(diff.Changes) != len(want) {
+		t.Fatalf("changes = %+v, want %d", diff.Changes, len(want))
+	}
+	for i, w := range want {
+		if diff.Changes[i].Key != w.key || diff.Changes[i].Change != w.change {
+			t.Errorf("change %d = %+v, want %s %s", i, diff.Changes[i], w.key, w.change)
+		}
+	}
+	if diff.UnchangedCount != 1 || diff.SizeDeltaBytes != 10+3-11 {
+		t.Errorf("diff = %+v, want 1 unchanged and a delta of 2 bytes", diff)
+	}
+
+	if _, err := client.DiffInventory(ctx, "backups/db.sql", second.Key); err == nil {
+		t.Errorf("DiffInventory() of an object that is not a snapshot should return error")
+	}
+}
@@ -0,0 +1,177 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/filter"
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+// LatestOptions select the objects LatestObjects reports. Every option that is set must
+// match.
+type LatestOptions struct {
+	// Prefixes are searched together, the whole bucket when there is none
+	Prefixes []string
+	// Count is the number of newest objects to return, at least 1
+	Count int
+	// Pattern is a glob matched against the object name, or against the full key when it
+	// contains a slash
+	Pattern string
+	// Regex is matched against the full key
+	Regex  *regexp.Regexp
+	Filter *filter.Filter
+	// Tags are carried by every returned object
+	Tags   map[string]string
+	Window storage.TimeWindow
+}
+
+// LatestObjects returns the newest objects selected by opts without downloading them.
+func (c *Client) LatestObjects(ctx context.Context, opts LatestOptions) (*models.LatestResult, error) {
+	if opts.Pattern != "" {
+		if _, err := path.Match(opts.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", opts.Pattern, err)
+		}
+	}
+	count := max(opts.Count, 1)
+
+	var objects []types.Object
+	for _, prefix := range storage.DistinctPrefixes(opts.Prefixes) {
+		listed, err := c.listObjects(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, listed...)
+	}
+
+	// Signatures are uploaded after their file, they are never the newest object
+	var matched []types.Object
+	for _, obj := range objects {
+		key := aws.ToString(obj.Key)
+		if !isSignature(key) && matchesPattern(key, opts.Pattern) && storage.MatchesRegex(key, opts.Regex) && opts.Filter.Match(filterObject(obj)) && opts.Window.Contains(aws.ToTime(obj.LastModified)) {
+			matched = append(matched, obj)
+		}
+	}
+	matched, err := withTags(ctx, c, matched, func(obj types.Object) string { return aws.ToString(obj.Key) }, opts.Tags)
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(matched)
+
+	now := time.Now()
+	items := make([]models.ListItem, 0, count)
+	for _, obj := range matched[:min(count, len(matched))] {
+		items = append(items, newListItem(obj, now))
+	}
+
+	return &models.LatestResult{
+		BucketName:    c.config.BucketName,
+		Prefix:        strings.Join(opts.Prefixes, ","),
+		Pattern:       opts.Pattern,
+		Regex:         storage.RegexString(opts.Regex),
+		Filter:        opts.Filter.String(),
+		TagFilter:     opts.Tags,
+		OlderThan:     storage.FormatBound(opts.Window.Before),
+		NewerThan:     storage.FormatBound(opts.Window.After),
+		Items:         items,
+		Count:         len(items),
+		MatchedCount:  len(matched),
+		OperationTime: utils.FormatTime(now),
+	}, nil
+}
+
+// listObjects returns every object under prefix. Prefixes with more than a page of
+// objects are listed in shards, one per common prefix, LIST_CONCURRENCY at a time.
+func (c *Client) listObjects(ctx context.Context, prefix string) ([]types.Object, error) {
+	listPrefix, matches := c.listPrefix(prefix)
+	lister := c.newShardedLister(func(obj types.Object) bool { return matches(aws.ToString(obj.Key)) })
+	objects, err := lister.listAll(ctx, listPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return objects, nil
+}
+
+// listFolders returns every object in folders, each object once.
+func (c *Client) listFolders(ctx context.Context, folders []string) ([]types.Object, error) {
+	var objects []types.Object
+	for _, prefix := range storage.FolderPrefixes(folders) {
+		listed, err := c.listObjects(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, listed...)
+	}
+	return objects, nil
+}
+
+// filterObject returns what a --filter expression sees of obj.
+func filterObject(obj types.Object) filter.Object {
+	return filter.Object{
+		Key:          aws.ToString(obj.Key),
+		Size:         aws.ToInt64(obj.Size),
+		LastModified: aws.ToTime(obj.LastModified),
+		StorageClass: string(obj.StorageClass),
+	}
+}
+
+func matchesPattern(key, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+	name := key
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(key)
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+func sortNewestFirst(objects []types.Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return aws.ToTime(objects[i].LastModified).After(aws.ToTime(objects[j].LastModified))
+	})
+}
+
+func newListItem(obj types.Object, now time.Time) models.ListItem {
+	lastModified := aws.ToTime(obj.LastModified)
+	age := now.Sub(lastModified).Truncate(time.Second)
+	size := aws.ToInt64(obj.Size)
+
+	return models.ListItem{
+		Key:               aws.ToString(obj.Key),
+		Size:              size,
+		SizeHuman:         utils.FormatBytes(size),
+		LastModified:      utils.FormatTime(lastModified),
+		Age:               age.String(),
+		AgeSeconds:        int64(age.Seconds()),
+		StorageClass:      string(obj.StorageClass),
+		ETag:              objectETag(obj),
+		ChecksumAlgorithm: checksumAlgorithms(obj),
+		ChecksumType:      string(obj.ChecksumType),
+	}
+}
+
+// objectETag returns the ETag of obj without quotes.
+func objectETag(obj types.Object) string {
+	return strings.Trim(aws.ToString(obj.ETag), `"`)
+}
+
+// checksumAlgorithms lists the algorithms of the additional checksums of obj.
+func checksumAlgorithms(obj types.Object) string {
+	algorithms := make([]string, 0, len(obj.ChecksumAlgorithm))
+	for _, algorithm := range obj.ChecksumAlgorithm {
+		algorithms = append(algorithms, string(algorithm))
+	}
+	return strings.Join(algorithms, ",")
+}
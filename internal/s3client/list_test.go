@@ -0,0 +1,121 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"s3manager/config"
+	"s3manager/internal/storage"
+	"testing"
+	"time"
+)
+
+func TestLatestObjects(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<ListBucketResult>
+<Contents><Key>backups/db-1.sql.gz</Key><LastModified>2024-03-01T00:00:00Z</LastModified><Size>100</Size></Contents>
+<Contents><Key>backups/db-3.sql.gz</Key><LastModified>2024-03-03T00:00:00Z</LastModified><Size>300</Size><ETag>"0cc1-2"</ETag><StorageClass>GLACIER</StorageClass><ChecksumAlgorithm>SHA256</ChecksumAlgorithm><ChecksumType>COMPOSITE</ChecksumType></Contents>
+<Contents><Key>backups/notes.txt</Key><LastModified>2024-03-04T00:00:00Z</LastModified><Size>5</Size></Contents>
+<Contents><Key>backups/db-2.sql.gz</Key><LastModified>2024-03-02T00:00:00Z</LastModified><Size>200</Size></Contents>
+</ListBucketResult>`)
+	})
+	client := newTestClient(t, handler, nil)
+
+	result, err := client.LatestObjects(context.Background(), LatestOptions{Prefixes: []string{"backups/"}, Count: 2, Pattern: "*.sql.gz"})
+	if err != nil {
+		t.Fatalf("LatestObjects() error = %v", err)
+	}
+
+	if result.Count != 2 || result.MatchedCount != 3 {
+		t.Fatalf("count = %d, matched = %d, want 2 and 3", result.Count, result.MatchedCount)
+	}
+
+	if result.Items[0].Key != "backups/db-3.sql.gz" || result.Items[1].Key != "backups/db-2.sql.gz" {
+		t.Errorf("items = %+v, want db-3 then db-2", result.Items)
+	}
+
+	if result.Items[0].Size != 300 || result.Items[0].AgeSeconds <= 0 {
+		t.Errorf("item = %+v, want size 300 and a positive age", result.Items[0])
+	}
+	if item := result.Items[0]; item.StorageClass != "GLACIER" || item.ETag != "0cc1-2" || item.ChecksumAlgorithm != "SHA256" || item.ChecksumType != "COMPOSITE" {
+		t.Errorf("item = %+v, want the storage class, ETag and checksum of the listing", item)
+	}
+
+	result, err = client.LatestObjects(context.Background(), LatestOptions{Prefixes: []string{"backups/"}, Count: 1})
+	if err != nil {
+		t.Fatalf("LatestObjects() error = %v", err)
+	}
+	if result.Items[0].Key != "backups/notes.txt" {
+		t.Errorf("newest = %s, want backups/notes.txt", result.Items[0].Key)
+	}
+
+	window := storage.TimeWindow{
+		After:  time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		Before: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+	}
+	result, err = client.LatestObjects(context.Background(), LatestOptions{Prefixes: []string{"backups/"}, Count: 5, Window: window})
+	if err != nil {
+		t.Fatalf("LatestObjects() error = %v", err)
+	}
+	if result.Count != 1 || result.Items[0].Key != "backups/db-2.sql.gz" || result.NewerThan != "2024-03-02T00:00:00Z" {
+		t.Errorf("LatestObjects() in window = %+v, want only db-2", result)
+	}
+
+	regex := regexp.MustCompile(`^backups/db-[12]\.sql\.gz$`)
+	result, err = client.LatestObjects(context.Background(), LatestOptions{Prefixes: []string{"backups/"}, Count: 5, Regex: regex})
+	if err != nil {
+		t.Fatalf("LatestObjects() error = %v", err)
+	}
+	if result.Count != 2 || result.Items[0].Key != "backups/db-2.sql.gz" || result.Regex != regex.String() {
+		t.Errorf("LatestObjects() with regex = %+v, want db-2 and db-1", result)
+	}
+
+	if _, err := client.LatestObjects(context.Background(), LatestOptions{Prefixes: []string{"backups/"}, Count: 1, Pattern: "[bad"}); err == nil {
+		t.Errorf("LatestObjects() with invalid pattern should return error")
+	}
+}
+
+func TestMatchesPattern(t *testing.T) {
+	tests := []struct {
+		key     string
+		pattern string
+		want    bool
+	}{
+		{"backups/db.sql.gz", "", true},
+		{"backups/db.sql.gz", "*.sql.gz", true},
+		{"backups/db.sql.gz", "*.tar", false},
+		{"backups/db.sql.gz", "backups/*.gz", true},
+		{"other/db.sql.gz", "backups/*.gz", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesPattern(tt.key, tt.pattern); got != tt.want {
+			t.Errorf("matchesPattern(%q, %q) = %t, want %t", tt.key, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestLatestObjectsUnsigned(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization = %q, want an unsigned request", auth)
+		}
+		fmt.Fprint(w, `<ListBucketResult>
+<Contents><Key>data/part-0.csv</Key><LastModified>2024-03-01T00:00:00Z</LastModified><Size>100</Size></Contents>
+</ListBucketResult>`)
+	})
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.AccessKey = ""
+		cfg.SecretKey = ""
+		cfg.NoSignRequest = true
+	})
+
+	result, err := client.LatestObjects(context.Background(), LatestOptions{Prefixes: []string{"data/"}, Count: 1})
+	if err != nil {
+		t.Fatalf("LatestObjects() error = %v", err)
+	}
+	if result.Count != 1 {
+		t.Errorf("LatestObjects() count = %d, want 1", result.Count)
+	}
+}
@@ -0,0 +1,203 @@
+package s3client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// listingCache keeps the listings of a bucket on disk, one file per prefix, so repeated
+// read-only commands skip the LIST requests. Any request that may change the bucket
+// removes the listings of the bucket, also when the cache is not used.
+type listingCache struct {
+	// dir holds the listings of one bucket at one endpoint
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+type cachedListing struct {
+	Created time.Time      `json:"created"`
+	Prefix  string         `json:"prefix"`
+	Objects []cachedObject `json:"objects"`
+}
+
+type cachedObject struct {
+	Key               string    `json:"key"`
+	Size              int64     `json:"size"`
+	ETag              string    `json:"etag,omitempty"`
+	LastModified      time.Time `json:"last_modified"`
+	StorageClass      string    `json:"storage_class,omitempty"`
+	ChecksumAlgorithm []string  `json:"checksum_algorithm,omitempty"`
+	ChecksumType      string    `json:"checksum_type,omitempty"`
+}
+
+// listingCacheDir returns the directory of the cached listings of bucket at endpoint
+// below root, "" without root.
+func listingCacheDir(root, endpoint, region, bucket string) string {
+	if root == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(endpoint + "\n" + region + "\n" + bucket))
+	return filepath.Join(root, hex.EncodeToString(sum[:8]))
+}
+
+// UseListingCache makes listings come from the cache while they are younger than ttl,
+// LISTING_CACHE_TTL when ttl is 0, and stores the listings it has to request. Only
+// read-only commands should use it, a listing may miss changes made by other clients
+// during ttl.
+func (c *Client) UseListingCache(ttl time.Duration) error {
+	if c.listingCacheDir == "" {
+		return fmt.Errorf("no directory for the listing cache, set LISTING_CACHE_DIR")
+	}
+	if ttl <= 0 {
+		ttl = c.config.ListingCacheTTL
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("the listing cache needs a positive TTL")
+	}
+	c.listingCache = &listingCache{dir: c.listingCacheDir, ttl: ttl, now: time.Now}
+	return nil
+}
+
+func (lc *listingCache) path(prefix string) string {
+	sum := sha256.Sum256([]byte(prefix))
+	return filepath.Join(lc.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// load returns the cached listing of prefix, false when there is none or it expired.
+func (lc *listingCache) load(prefix string) ([]types.Object, bool) {
+	data, err := os.ReadFile(lc.path(prefix))
+	if err != nil {
+		return nil, false
+	}
+	var listing cachedListing
+	if err := json.Unmarshal(data, &listing); err != nil || listing.Prefix != prefix {
+		return nil, false
+	}
+	if lc.now().Sub(listing.Created) > lc.ttl {
+		return nil, false
+	}
+
+	objects := make([]types.Object, 0, len(listing.Objects))
+	for _, o := range listing.Objects {
+		obj := types.Object{
+			Key:          aws.String(o.Key),
+			Size:         aws.Int64(o.Size),
+			LastModified: aws.Time(o.LastModified),
+			StorageClass: types.ObjectStorageClass(o.StorageClass),
+			ChecksumType: types.ChecksumType(o.ChecksumType),
+		}
+		if o.ETag != "" {
+			obj.ETag = aws.String(`"` + o.ETag + `"`)
+		}
+		for _, algorithm := range o.ChecksumAlgorithm {
+			obj.ChecksumAlgorithm = append(obj.ChecksumAlgorithm, types.ChecksumAlgorithm(algorithm))
+		}
+		objects = append(objects, obj)
+	}
+	return objects, true
+}
+
+// store writes the listing of prefix. The file is only readable by the user, keys can
+// be sensitive.
+func (lc *listingCache) store(prefix string, objects []types.Object) error {
+	listing := cachedListing{Created: lc.now(), Prefix: prefix, Objects: make([]cachedObject, 0, len(objects))}
+	for _, obj := range objects {
+		o := cachedObject{
+			Key:          aws.ToString(obj.Key),
+			Size:         aws.ToInt64(obj.Size),
+			ETag:         objectETag(obj),
+			LastModified: aws.ToTime(obj.LastModified),
+			StorageClass: string(obj.StorageClass),
+			ChecksumType: string(obj.ChecksumType),
+		}
+		for _, algorithm := range obj.ChecksumAlgorithm {
+			o.ChecksumAlgorithm = append(o.ChecksumAlgorithm, string(algorithm))
+		}
+		listing.Objects = append(listing.Objects, o)
+	}
+	data, err := json.Marshal(listing)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(lc.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create listing cache: %w", err)
+	}
+	path := lc.path(prefix)
+	file, err := os.CreateTemp(lc.dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write listing cache: %w", err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to write listing cache: %w", err)
+	}
+	return nil
+}
+
+// list returns the objects under prefix from the cache, or lists them with l and stores
+// them. l.keep sees the cached objects like listed ones.
+func (lc *listingCache) list(ctx context.Context, l *shardedLister, prefix string) ([]types.Object, error) {
+	if objects, ok := lc.load(prefix); ok {
+		slog.Debug("Using cached listing", "prefix", prefix, "objects", len(objects))
+		return l.filter(objects), nil
+	}
+
+	var mu sync.Mutex
+	var all []types.Object
+	keep := l.keep
+	l.keep = func(obj types.Object) bool {
+		mu.Lock()
+		all = append(all, obj)
+		mu.Unlock()
+		return keep(obj)
+	}
+	defer func() { l.keep = keep }()
+
+	objects, err := l.list(ctx, prefix, listShardDepth)
+	if err != nil {
+		return nil, err
+	}
+	if err := lc.store(prefix, all); err != nil {
+		slog.Warn("Failed to cache listing", "prefix", prefix, "error", err)
+	}
+	return objects, nil
+}
+
+// invalidateListingsMiddleware removes the cached listings of dir before every request
+// that is not a GET or HEAD, the ones that may change the bucket.
+func invalidateListingsMiddleware(dir string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("S3ManagerInvalidateListings",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if req, ok := in.Request.(*smithyhttp.Request); ok && req.Method != http.MethodGet && req.Method != http.MethodHead {
+					if err := os.RemoveAll(dir); err != nil {
+						slog.Warn("Failed to clear listing cache", "path", dir, "error", err)
+					}
+				}
+				return next.HandleFinalize(ctx, in)
+			}), middleware.Before)
+	}
+}
@@ -0,0 +1,72 @@
+package s3client
+
+import (
+	"context"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListingCache(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	modified := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	fake.PutObject("test-bucket", "logs/a.log", []byte("aaa"), modified)
+	cacheDir := t.TempDir()
+	client := newTestClient(t, fake, func(c *config.Config) {
+		c.ListingCacheDir = cacheDir
+		c.ListingCacheTTL = time.Hour
+	})
+	ctx := context.Background()
+
+	keys := func() string {
+		t.Helper()
+		objects, err := client.listObjects(ctx, "logs/")
+		if err != nil {
+			t.Fatalf("listObjects() error = %v", err)
+		}
+		var keys []string
+		for _, obj := range objects {
+			keys = append(keys, *obj.Key+"="+objectETag(obj))
+		}
+		return strings.Join(keys, ",")
+	}
+
+	if err := client.UseListingCache(0); err != nil {
+		t.Fatalf("UseListingCache() error = %v", err)
+	}
+	first := keys()
+	if !strings.HasPrefix(first, "logs/a.log=") || strings.HasSuffix(first, "=") {
+		t.Fatalf("first listing = %q, want logs/a.log with its ETag", first)
+	}
+
+	// Changes by other clients are not seen until the listing expires
+	fake.PutObject("test-bucket", "logs/b.log", []byte("bbb"), modified)
+	if cached := keys(); cached != first {
+		t.Errorf("cached listing = %q, want %q", cached, first)
+	}
+	client.listingCache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if expired := keys(); !strings.Contains(expired, "logs/b.log") {
+		t.Errorf("listing after the TTL = %q, want logs/b.log", expired)
+	}
+	client.listingCache.now = time.Now
+
+	// A change made through a client clears the cache of the bucket
+	if _, err := client.Delete(ctx, []string{"logs/a.log"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if after := keys(); strings.Contains(after, "logs/a.log") {
+		t.Errorf("listing after a delete = %q, want logs/a.log gone", after)
+	}
+}
+
+func TestUseListingCacheWithoutDirectory(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	if err := client.UseListingCache(time.Minute); err == nil {
+		t.Error("UseListingCache() without LISTING_CACHE_DIR succeeded")
+	}
+}
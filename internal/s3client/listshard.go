@@ -0,0 +1,174 @@
+package s3client
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/storage"
+)
+
+// Levels of "/" below the listed prefix at which a large listing is split
+const listShardDepth = 2
+
+// shardedLister lists a prefix with one paginator per common prefix when it holds more
+// than a page of objects. The semaphore bounds the requests in flight across all shards;
+// it is only held for the duration of a request, so nested shards cannot deadlock.
+type shardedLister struct {
+	c   *Client
+	sem chan struct{}
+	// keep selects the objects to return as pages arrive, so filtered listings do not
+	// hold every object. It is called from several goroutines.
+	keep func(obj types.Object) bool
+}
+
+func (c *Client) newShardedLister(keep func(obj types.Object) bool) *shardedLister {
+	return &shardedLister{c: c, sem: make(chan struct{}, c.settings().ListConcurrency), keep: keep}
+}
+
+func (l *shardedLister) filter(objects []types.Object) []types.Object {
+	kept := objects[:0]
+	for _, obj := range objects {
+		if l.keep(obj) {
+			kept = append(kept, obj)
+		}
+	}
+	return kept
+}
+
+func (l *shardedLister) listPage(ctx context.Context, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-l.sem }()
+	return l.c.s3Client.ListObjectsV2(ctx, input)
+}
+
+// listAll returns every object under prefix, from the listing cache when the client uses
+// one.
+func (l *shardedLister) listAll(ctx context.Context, prefix string) ([]types.Object, error) {
+	if l.c.listingCache != nil {
+		return l.c.listingCache.list(ctx, l, prefix)
+	}
+	return l.list(ctx, prefix, listShardDepth)
+}
+
+// listEach returns every object under prefixes, each object once.
+func (l *shardedLister) listEach(ctx context.Context, prefixes []string) ([]types.Object, error) {
+	var objects []types.Object
+	for _, prefix := range storage.DistinctPrefixes(prefixes) {
+		listed, err := l.listAll(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, listed...)
+	}
+	return objects, nil
+}
+
+// list returns every object under prefix. A prefix that fits in one page costs a single
+// request like a plain listing; larger ones are split up to depth levels deep.
+func (l *shardedLister) list(ctx context.Context, prefix string, depth int) ([]types.Object, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(l.c.config.BucketName),
+		Prefix: aws.String(prefix),
+	}
+	page, err := l.listPage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	// The first page is listed again by the shards, keep must see every object once
+	if aws.ToBool(page.IsTruncated) && depth > 0 && cap(l.sem) > 1 {
+		return l.listShards(ctx, prefix, depth)
+	}
+
+	objects := l.filter(page.Contents)
+	for aws.ToBool(page.IsTruncated) {
+		input.ContinuationToken = page.NextContinuationToken
+		if page, err = l.listPage(ctx, input); err != nil {
+			return nil, err
+		}
+		objects = append(objects, l.filter(page.Contents)...)
+	}
+	return objects, nil
+}
+
+// listShards lists the objects directly under prefix with a delimiter and every common
+// prefix below it concurrently.
+func (l *shardedLister) listShards(ctx context.Context, prefix string, depth int) ([]types.Object, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(l.c.config.BucketName),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}
+	var objects []types.Object
+	var shards []string
+	for {
+		page, err := l.listPage(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, l.filter(page.Contents)...)
+		for _, common := range page.CommonPrefixes {
+			shards = append(shards, aws.ToString(common.Prefix))
+		}
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		input.ContinuationToken = page.NextContinuationToken
+	}
+	if len(shards) == 0 {
+		return objects, nil
+	}
+	slog.Debug("Listing prefix in shards", "prefix", prefix, "shards", len(shards))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]types.Object, len(shards))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for range min(cap(l.sem), len(shards)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				shard, err := l.list(ctx, shards[i], depth-1)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				results[i] = shard
+			}
+		}()
+	}
+	for i := range shards {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	for _, shard := range results {
+		objects = append(objects, shard...)
+	}
+	// The same order as a single paginator returns
+	sort.Slice(objects, func(i, j int) bool { return aws.ToString(objects[i].Key) < aws.ToString(objects[j].Key) })
+	return objects, nil
+}
@@ -0,0 +1,72 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestListObjectsInShards(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	var want []string
+	add := func(key string) {
+		fake.PutObject("test-bucket", key, []byte("x"), time.Now())
+		want = append(want, key)
+	}
+	// More than a page in total, one day holding more than a page on its own
+	for day := 1; day <= 3; day++ {
+		for i := range 400 * day {
+			add(fmt.Sprintf("logs/2024-03-%02d/%04d.log", day, i))
+		}
+	}
+	add("logs/README")
+	add("logs/2024-03-02.tar")
+	fake.PutObject("test-bucket", "other/file", []byte("x"), time.Now())
+	sort.Strings(want)
+
+	var delimited atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("delimiter") != "" {
+			delimited.Add(1)
+		}
+		fake.ServeHTTP(w, r)
+	})
+
+	for _, concurrency := range []int{1, 4} {
+		client := newTestClient(t, handler, func(cfg *config.Config) { cfg.ListConcurrency = concurrency })
+		delimited.Store(0)
+
+		objects, err := client.listObjects(context.Background(), "logs/")
+		if err != nil {
+			t.Fatalf("listObjects() error = %v", err)
+		}
+		if len(objects) != len(want) {
+			t.Fatalf("listObjects() with concurrency %d = %d objects, want %d", concurrency, len(objects), len(want))
+		}
+		for i, obj := range objects {
+			if aws.ToString(obj.Key) != want[i] {
+				t.Fatalf("listObjects() with concurrency %d [%d] = %s, want %s", concurrency, i, aws.ToString(obj.Key), want[i])
+			}
+		}
+		if sharded := delimited.Load() > 0; sharded != (concurrency > 1) {
+			t.Errorf("delimiter requests = %d with concurrency %d", delimited.Load(), concurrency)
+		}
+
+		info, err := client.GetBucketInfo(context.Background())
+		if err != nil {
+			t.Fatalf("GetBucketInfo() error = %v", err)
+		}
+		if info.ObjectCount != int64(len(want))+1 {
+			t.Errorf("GetBucketInfo() with concurrency %d = %d objects, want %d", concurrency, info.ObjectCount, len(want)+1)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"s3manager/pkg/utils"
+)
+
+const (
+	// Lock objects live next to the data they protect so that every host sees the same lock
+	lockPrefix = ".s3manager/locks/"
+
+	DefaultLockTTL = 5 * time.Minute
+)
+
+// ErrLockHeld is returned when another process holds a lock that has not expired yet.
+var ErrLockHeld = errors.New("lock is held by another process")
+
+type LockOptions struct {
+	// Command is recorded in the lock object to show what holds the lock.
+	Command string
+	// TTL is how long the lock stays valid without being renewed. A lock whose holder
+	// stopped renewing it for longer is stale and can be taken over.
+	TTL time.Duration
+	// OnLost is called when the lock could not be renewed or was taken over.
+	OnLost func(error)
+}
+
+// LockInfo is the content of a lock object.
+type LockInfo struct {
+	Name       string `json:"name"`
+	Owner      string `json:"owner"`
+	Command    string `json:"command,omitempty"`
+	AcquiredAt string `json:"acquired_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+// Lock is a lock object held in the bucket, renewed in the background until released.
+// A nil *Lock is valid and does nothing.
+type Lock struct {
+	client *Client
+	key    string
+	info   LockInfo
+	ttl    time.Duration
+	onLost func(error)
+
+	mu   sync.Mutex
+	etag string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// AcquireLock takes the named lock using conditional writes, so that only one of several
+// hosts running the same job gets it. An expired lock left behind by a crashed process is
+// taken over; a live one yields an error wrapping ErrLockHeld.
+func (c *Client) AcquireLock(ctx context.Context, name string, opts LockOptions) (*Lock, error) {
+	if name == "" || strings.HasPrefix(name, "/") {
+		return nil, fmt.Errorf("invalid lock name: %q", name)
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultLockTTL
+	}
+
+	l := &Lock{
+		client: c,
+		key:    lockPrefix + name + ".lock",
+		info:   LockInfo{Name: name, Owner: lockOwner(), Command: opts.Command},
+		ttl:    opts.TTL,
+		onLost: opts.OnLost,
+	}
+	l.info.AcquiredAt = utils.FormatTime(time.Now())
+
+	// The lock may be released between the failed create and reading it, so try twice
+	for attempt := 0; attempt < 2; attempt++ {
+		err := l.put(ctx, "")
+		if err == nil {
+			l.startRenewal()
+			return l, nil
+		}
+		if !isConditionFailed(err) {
+			return nil, fmt.Errorf("failed to create lock %s: %w", name, err)
+		}
+
+		current, etag, err := c.readLock(ctx, l.key)
+		if hasErrorCode(err, "NoSuchKey", "NotFound") {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read lock %s: %w", name, err)
+		}
+
+		expires, _ := time.Parse(time.RFC3339, current.ExpiresAt)
+		if time.Now().Before(expires) {
+			return nil, fmt.Errorf("%w: %s held by %s (%s) until %s", ErrLockHeld, name, current.Owner, current.Command, current.ExpiresAt)
+		}
+
+		// Stale lock: replace it only if nobody else took it over in the meantime
+		slog.Warn("Taking over stale lock", "lock", name, "owner", current.Owner, "expired_at", current.ExpiresAt)
+		err = l.put(ctx, etag)
+		if err == nil {
+			l.startRenewal()
+			return l, nil
+		}
+		if isConditionFailed(err) {
+			return nil, fmt.Errorf("%w: %s was taken over by another process", ErrLockHeld, name)
+		}
+		return nil, fmt.Errorf("failed to take over lock %s: %w", name, err)
+	}
+
+	return nil, fmt.Errorf("%w: %s is changing hands, try again", ErrLockHeld, name)
+}
+
+// Info returns the content of the held lock.
+func (l *Lock) Info() LockInfo {
+	if l == nil {
+		return LockInfo{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.info
+}
+
+// Release stops renewing the lock and deletes it, unless another process took it over.
+func (l *Lock) Release(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	close(l.stop)
+	<-l.done
+
+	l.mu.Lock()
+	etag := l.etag
+	l.mu.Unlock()
+
+	_, err := l.client.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:  aws.String(l.client.config.BucketName),
+		Key:     aws.String(l.key),
+		IfMatch: aws.String(etag),
+	})
+	if err != nil && !isConditionFailed(err) {
+		return fmt.Errorf("failed to release lock %s: %w", l.info.Name, err)
+	}
+	return nil
+}
+
+// put writes the lock object, creating it when etag is empty and replacing the object
+// with that ETag otherwise.
+func (l *Lock) put(ctx context.Context, etag string) error {
+	l.mu.Lock()
+	l.info.ExpiresAt = utils.FormatTime(time.Now().Add(l.ttl))
+	body, err := json.Marshal(l.info)
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(l.client.config.BucketName),
+		Key:         aws.String(l.key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	}
+	if etag == "" {
+		input.IfNoneMatch = aws.String("*")
+	} else {
+		input.IfMatch = aws.String(etag)
+	}
+
+	resp, err := l.client.s3Client.PutObject(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.etag = aws.ToString(resp.ETag)
+	l.mu.Unlock()
+	return nil
+}
+
+// startRenewal extends the lock well before it expires, for as long as it is held.
+func (l *Lock) startRenewal() {
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+			}
+
+			l.mu.Lock()
+			etag := l.etag
+			l.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			err := l.put(ctx, etag)
+			cancel()
+			if err == nil {
+				continue
+			}
+
+			if isConditionFailed(err) {
+				err = fmt.Errorf("lock %s was taken over by another process", l.info.Name)
+			} else {
+				err = fmt.Errorf("failed to renew lock %s: %w", l.info.Name, err)
+			}
+			slog.Error("Lost lock", "lock", l.info.Name, "error", err)
+			if l.onLost != nil {
+				l.onLost(err)
+			}
+			return
+		}
+	}()
+}
+
+func (c *Client) readLock(ctx context.Context, key string) (*LockInfo, string, error) {
+	resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		// An unreadable lock never expires on its own, treat it as stale
+		info = LockInfo{Owner: "unknown"}
+	}
+	return &info, aws.ToString(resp.ETag), nil
+}
+
+func isConditionFailed(err error) bool {
+	return hasErrorCode(err, "PreconditionFailed", "ConditionalRequestConflict")
+}
+
+func lockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
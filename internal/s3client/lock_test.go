@@ -0,0 +1,171 @@
+package s3client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLockStore serves a single object with S3's conditional write semantics.
+type fakeLockStore struct {
+	mu      sync.Mutex
+	body    []byte
+	version int
+}
+
+func (s *fakeLockStore) etag() string {
+	return fmt.Sprintf(`"v%d"`, s.version)
+}
+
+func (s *fakeLockStore) replace(body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+	s.version++
+}
+
+func (s *fakeLockStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exists := s.body != nil
+	preconditionFailed := func() {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`)
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			preconditionFailed()
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || match != s.etag()) {
+			preconditionFailed()
+			return
+		}
+		s.body = body
+		s.version++
+		w.Header().Set("ETag", s.etag())
+	case http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Header().Set("ETag", s.etag())
+		w.Write(s.body)
+	case http.MethodDelete:
+		if match := r.Header.Get("If-Match"); match != "" && exists && match != s.etag() {
+			preconditionFailed()
+			return
+		}
+		s.body = nil
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *fakeLockStore) info(t *testing.T) *LockInfo {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.body == nil {
+		return nil
+	}
+	var info LockInfo
+	if err := json.Unmarshal(s.body, &info); err != nil {
+		t.Fatalf("invalid lock object: %v", err)
+	}
+	return &info
+}
+
+func TestAcquireLockIsExclusive(t *testing.T) {
+	store := &fakeLockStore{}
+	client := newTestClient(t, store, nil)
+	ctx := context.Background()
+
+	lock, err := client.AcquireLock(ctx, "nightly-prune", LockOptions{Command: "delete-old", TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+
+	info := store.info(t)
+	if info == nil || info.Name != "nightly-prune" || info.Command != "delete-old" || info.Owner != lockOwner() {
+		t.Errorf("lock object = %+v, want the holder's details", info)
+	}
+
+	if _, err := client.AcquireLock(ctx, "nightly-prune", LockOptions{TTL: time.Minute}); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("second AcquireLock() error = %v, want ErrLockHeld", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if store.info(t) != nil {
+		t.Errorf("Release() should delete the lock object")
+	}
+
+	lock, err = client.AcquireLock(ctx, "nightly-prune", LockOptions{TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("AcquireLock() after release error = %v", err)
+	}
+	lock.Release(ctx)
+}
+
+func TestAcquireLockTakesOverStaleLock(t *testing.T) {
+	store := &fakeLockStore{}
+	stale, _ := json.Marshal(LockInfo{Name: "sync", Owner: "crashed-host:42", ExpiresAt: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)})
+	store.replace(stale)
+	client := newTestClient(t, store, nil)
+
+	lock, err := client.AcquireLock(context.Background(), "sync", LockOptions{TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	defer lock.Release(context.Background())
+
+	if info := store.info(t); info == nil || info.Owner != lockOwner() {
+		t.Errorf("lock object = %+v, want it taken over", info)
+	}
+}
+
+func TestLockRenewsAndReportsTakeover(t *testing.T) {
+	store := &fakeLockStore{}
+	client := newTestClient(t, store, nil)
+
+	lost := make(chan error, 1)
+	lock, err := client.AcquireLock(context.Background(), "deploy", LockOptions{
+		TTL:    300 * time.Millisecond,
+		OnLost: func(err error) { lost <- err },
+	})
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	defer lock.Release(context.Background())
+
+	first := store.info(t).ExpiresAt
+	time.Sleep(1100 * time.Millisecond)
+	if renewed := store.info(t).ExpiresAt; renewed == first {
+		t.Errorf("expires_at = %s, want the lock renewed", renewed)
+	}
+
+	// Another host replaces the lock behind our back
+	store.replace([]byte(`{"name":"deploy","owner":"other:1"}`))
+
+	select {
+	case err := <-lost:
+		if !strings.Contains(err.Error(), "taken over") {
+			t.Errorf("OnLost error = %v, want a takeover", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("OnLost was not called after the lock was taken over")
+	}
+}
@@ -0,0 +1,50 @@
+package s3client
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+
+	appConfig "s3manager/config"
+)
+
+// LowMemoryBudget is the memory budget of --low-memory: three 5MB parts in flight.
+const LowMemoryBudget = 3 * manager.MinUploadPartSize
+
+// settings returns the settings of the client, with defaults for those that are not set.
+// Multipart uploads send parts of PartSize, UploadConcurrency at a time, unless the
+// memory budget asks for less.
+func (c *Client) settings() appConfig.Settings {
+	settings := c.config.Settings.WithDefaults()
+	settings.PartSize = max(settings.PartSize, manager.MinUploadPartSize)
+	return settings
+}
+
+// transferSettings fits the part size and concurrency of multipart uploads into
+// MEMORY_BUDGET, shared by files uploads running at once. Uploads of streams buffer every
+// part in flight, so the budget bounds part size times concurrency. Without a budget
+// partSize and concurrency are returned unchanged.
+func (c *Client) transferSettings(partSize int64, concurrency, files int) (int64, int) {
+	if c.config.MemoryBudget <= 0 {
+		return partSize, concurrency
+	}
+	return fitMemoryBudget(c.config.MemoryBudget/int64(max(files, 1)), partSize, concurrency)
+}
+
+// fileConcurrency limits the number of files uploaded at once so that each of them can
+// still have a part of the minimum size in flight.
+func (c *Client) fileConcurrency(files int) int {
+	if c.config.MemoryBudget <= 0 {
+		return files
+	}
+	return max(min(files, int(c.config.MemoryBudget/manager.MinUploadPartSize)), 1)
+}
+
+// fitMemoryBudget shrinks parts first, down to the 5MB minimum of S3, then sends fewer of
+// them at once. A budget below one minimum part still uploads one part at a time.
+func fitMemoryBudget(budget, partSize int64, concurrency int) (int64, int) {
+	if budget <= 0 || partSize*int64(concurrency) <= budget {
+		return partSize, concurrency
+	}
+	partSize = max(budget/int64(concurrency), manager.MinUploadPartSize)
+	concurrency = int(max(min(int64(concurrency), budget/partSize), 1))
+	return partSize, concurrency
+}
@@ -0,0 +1,49 @@
+package s3client
+
+import (
+	"s3manager/config"
+	"testing"
+)
+
+func TestFitMemoryBudget(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		name            string
+		budget          int64
+		partSize        int64
+		concurrency     int
+		wantPartSize    int64
+		wantConcurrency int
+	}{
+		{"no budget", 0, 64 * mb, 5, 64 * mb, 5},
+		{"fits", 512 * mb, 64 * mb, 5, 64 * mb, 5},
+		{"smaller parts", 100 * mb, 64 * mb, 5, 20 * mb, 5},
+		{"fewer parts", LowMemoryBudget, 64 * mb, 5, 5 * mb, 3},
+		{"below one part", mb, 64 * mb, 5, 5 * mb, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partSize, concurrency := fitMemoryBudget(tt.budget, tt.partSize, tt.concurrency)
+			if partSize != tt.wantPartSize || concurrency != tt.wantConcurrency {
+				t.Errorf("fitMemoryBudget() = %d, %d, want %d, %d", partSize, concurrency, tt.wantPartSize, tt.wantConcurrency)
+			}
+		})
+	}
+}
+
+func TestMemoryBudgetSharedByFiles(t *testing.T) {
+	client := &Client{config: &config.Config{Settings: config.Settings{MemoryBudget: 4 * LowMemoryBudget}}}
+
+	if got := client.fileConcurrency(20); got != 12 {
+		t.Errorf("fileConcurrency(20) = %d, want 12", got)
+	}
+	partSize, concurrency := client.transferSettings(client.settings().PartSize, client.settings().UploadConcurrency, 12)
+	if partSize*int64(concurrency)*12 > client.config.MemoryBudget {
+		t.Errorf("transferSettings() = %d, %d, exceeds the budget shared by 12 files", partSize, concurrency)
+	}
+
+	uploader := client.newUploader()
+	if uploader.PartSize*int64(uploader.Concurrency) > client.config.MemoryBudget {
+		t.Errorf("newUploader() buffers %d parts of %d, exceeds the budget", uploader.Concurrency, uploader.PartSize)
+	}
+}
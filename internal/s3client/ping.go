@@ -0,0 +1,173 @@
+package s3client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Certificates expiring sooner are reported as a warning
+const pingCertWarningDays = 14
+
+// providerHosts and providerServers identify providers by endpoint host suffix and by
+// the Server response header.
+var (
+	providerHosts = map[string]string{
+		".amazonaws.com":            "aws",
+		".r2.cloudflarestorage.com": "r2",
+		".backblazeb2.com":          "b2",
+		".wasabisys.com":            "wasabi",
+		"storage.googleapis.com":    "gcs",
+	}
+	providerServers = map[string]string{
+		"amazons3":     "aws",
+		"minio":        "minio",
+		"cloudflare":   "r2",
+		"uploadserver": "gcs",
+		"ceph":         "ceph",
+	}
+)
+
+// Ping checks connectivity with HeadBucket and a one-key listing, which also proves
+// that requests with query parameters are signed correctly. It reports the latency of
+// both, the endpoint and TLS connection that served them and the provider they came
+// from. Failed checks are reported in the result, not as an error.
+func (c *Client) Ping(ctx context.Context) (*models.PingResult, error) {
+	bucket := aws.String(c.config.BucketName)
+	result := &models.PingResult{
+		BucketName: c.config.BucketName,
+		Provider:   c.config.Provider,
+		Region:     c.awsConfig.Region,
+		Checks:     []models.PingCheck{},
+		OK:         true,
+	}
+
+	checks := []struct {
+		operation string
+		run       func(ctx context.Context) (middleware.Metadata, error)
+	}{
+		{"HeadBucket", func(ctx context.Context) (middleware.Metadata, error) {
+			out, err := c.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: bucket})
+			if err != nil {
+				return middleware.Metadata{}, err
+			}
+			return out.ResultMetadata, nil
+		}},
+		{"ListObjectsV2", func(ctx context.Context) (middleware.Metadata, error) {
+			out, err := c.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: bucket, MaxKeys: aws.Int32(1)})
+			if err != nil {
+				return middleware.Metadata{}, err
+			}
+			return out.ResultMetadata, nil
+		}},
+	}
+
+	for _, check := range checks {
+		start := time.Now()
+		metadata, err := check.run(ctx)
+		latency := time.Since(start)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		pingCheck := models.PingCheck{
+			Operation: check.operation,
+			Status:    doctorStatus(err),
+			Latency:   latency.Round(time.Millisecond).String(),
+			LatencyMs: latency.Milliseconds(),
+		}
+		if err != nil {
+			pingCheck.Error = err.Error()
+			result.OK = false
+		}
+		result.Checks = append(result.Checks, pingCheck)
+
+		if result.Endpoint == "" {
+			if resp := rawResponse(metadata, err); resp != nil {
+				c.describeConnection(result, resp)
+			}
+		}
+	}
+
+	if result.TLS != nil && result.TLS.CertDaysRemaining < pingCertWarningDays {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("TLS certificate expires in %d days", result.TLS.CertDaysRemaining))
+	}
+	if result.Provider != "" && result.DetectedProvider != "" && !strings.EqualFold(result.Provider, result.DetectedProvider) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("PROVIDER is %s but the endpoint looks like %s", result.Provider, result.DetectedProvider))
+	}
+	if result.BucketRegion != "" && result.Region != "auto" && result.BucketRegion != result.Region {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("bucket is in %s, requests are signed for %s", result.BucketRegion, result.Region))
+	}
+
+	result.OperationTime = utils.FormatTime(time.Now())
+	return result, nil
+}
+
+// rawResponse returns the HTTP response of a call, which failed calls carry in their
+// error. It is nil when no response was received.
+func rawResponse(metadata middleware.Metadata, err error) *http.Response {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.Response != nil {
+		return respErr.Response.Response
+	}
+	if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+		return resp.Response
+	}
+	return nil
+}
+
+// describeConnection fills in the endpoint, server and TLS details of resp.
+func (c *Client) describeConnection(result *models.PingResult, resp *http.Response) {
+	if resp.Request != nil && resp.Request.URL != nil {
+		result.Endpoint = resp.Request.URL.Scheme + "://" + resp.Request.URL.Host
+	}
+	result.Server = resp.Header.Get("Server")
+	result.BucketRegion = resp.Header.Get("X-Amz-Bucket-Region")
+	result.DetectedProvider = detectProvider(result.Endpoint, result.Server)
+
+	if state := resp.TLS; state != nil {
+		result.TLS = &models.PingTLS{
+			Version:     tls.VersionName(state.Version),
+			CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+			ServerName:  state.ServerName,
+		}
+		if len(state.PeerCertificates) > 0 {
+			cert := state.PeerCertificates[0]
+			result.TLS.CertSubject = cert.Subject.CommonName
+			result.TLS.CertIssuer = cert.Issuer.CommonName
+			result.TLS.CertNotAfter = utils.FormatTime(cert.NotAfter)
+			result.TLS.CertDaysRemaining = int(time.Until(cert.NotAfter).Hours() / 24)
+		}
+	}
+}
+
+// detectProvider guesses the provider from the endpoint host, then the Server header.
+func detectProvider(endpoint, server string) string {
+	host := strings.ToLower(endpoint)
+	for suffix, provider := range providerHosts {
+		if strings.HasSuffix(host, suffix) {
+			return provider
+		}
+	}
+	server = strings.ToLower(server)
+	for name, provider := range providerServers {
+		if strings.Contains(server, name) {
+			return provider
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package s3client
+
+import (
+	"context"
+	"net/http"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "MinIO")
+		w.Header().Set("X-Amz-Bucket-Region", "eu-west-1")
+		fake.ServeHTTP(w, r)
+	})
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.Provider = "wasabi"
+		cfg.Region = "us-east-1"
+	})
+
+	result, err := client.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if !result.OK || len(result.Checks) != 2 || result.Checks[1].Operation != "ListObjectsV2" || result.Checks[1].Status != DoctorOK {
+		t.Errorf("Ping() = %+v, want both checks ok", result)
+	}
+	if !strings.HasPrefix(result.Endpoint, "http://127.0.0.1:") || result.Server != "MinIO" || result.TLS != nil {
+		t.Errorf("Ping() = %+v, want the plain HTTP endpoint of the test server", result)
+	}
+	// The provider and region mismatches are both reported
+	if result.DetectedProvider != "minio" || result.BucketRegion != "eu-west-1" || len(result.Warnings) != 2 {
+		t.Errorf("Ping() = %+v, want minio detected and two warnings", result)
+	}
+
+	client.config.BucketName = "missing-bucket"
+	result, err = client.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if result.OK || result.Checks[0].Status != DoctorFailed || result.Endpoint == "" {
+		t.Errorf("Ping() of a missing bucket = %+v, want a failed check with the endpoint", result)
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		endpoint, server, want string
+	}{
+		{"https://my-bucket.s3.eu-west-1.amazonaws.com", "AmazonS3", "aws"},
+		{"https://0123abcd.r2.cloudflarestorage.com", "cloudflare", "r2"},
+		{"https://s3.eu-central-1.wasabisys.com", "", "wasabi"},
+		{"https://storage.googleapis.com", "UploadServer", "gcs"},
+		{"http://minio.internal:9000", "MinIO", "minio"},
+		{"http://rgw.internal:7480", "", ""},
+	}
+	for _, tt := range tests {
+		if got := detectProvider(tt.endpoint, tt.server); got != tt.want {
+			t.Errorf("detectProvider(%q, %q) = %q, want %q", tt.endpoint, tt.server, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package s3client
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	PlanActionDownload = "download"
+	PlanActionUpload   = "upload"
+	PlanActionCopy     = "copy"
+	PlanActionDelete   = "delete"
+)
+
+// newTransferPlan starts the dry-run plan of a transfer. Commands add the items they
+// would process with addPlanItem so that every dry run reports the same shape.
+func newTransferPlan(operation, bucketName, source, destination string) *models.TransferPlan {
+	return &models.TransferPlan{
+		Operation:      operation,
+		BucketName:     bucketName,
+		Source:         source,
+		Destination:    destination,
+		Items:          []models.PlanItem{},
+		TotalSizeHuman: utils.FormatBytes(0),
+		OperationTime:  utils.FormatTime(time.Now()),
+		DryRun:         true,
+	}
+}
+
+func addPlanItem(plan *models.TransferPlan, item models.PlanItem) {
+	item.SizeHuman = utils.FormatBytes(item.Size)
+	plan.Items = append(plan.Items, item)
+	plan.TotalFiles++
+	plan.TotalSizeBytes += item.Size
+	plan.TotalSizeHuman = utils.FormatBytes(plan.TotalSizeBytes)
+	if item.Overwrite {
+		plan.OverwriteCount++
+	}
+}
+
+// localFileExists reports whether a download would replace an existing local file.
+func localFileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// PlanDownload reports what DownloadLatestFile would download without touching the
+// local file system.
+func (c *Client) PlanDownload(ctx context.Context, folder, destinationPath string, opts DownloadOptions) (*models.TransferPlan, error) {
+	latest, localPath, err := c.latestDownload(ctx, folder, destinationPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := newTransferPlan(PlanActionDownload, c.config.BucketName, folder, destinationPath)
+	addPlanItem(plan, models.PlanItem{
+		Action:       PlanActionDownload,
+		Source:       *latest.Key,
+		Destination:  localPath,
+		Size:         *latest.Size,
+		LastModified: latest.LastModified.Format(time.RFC3339),
+		Overwrite:    localFileExists(localPath),
+	})
+	return plan, nil
+}
@@ -0,0 +1,58 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanDownload(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("list-type") != "2" {
+			t.Errorf("unexpected request %s %s, a dry run should only list", r.Method, r.URL)
+		}
+		fmt.Fprint(w, `<ListBucketResult>
+<Contents><Key>backups/db-1.sql.gz</Key><LastModified>2024-03-01T00:00:00Z</LastModified><Size>100</Size></Contents>
+<Contents><Key>backups/db-2.sql.gz</Key><LastModified>2024-03-02T00:00:00Z</LastModified><Size>2048</Size></Contents>
+</ListBucketResult>`)
+	})
+	client := newTestClient(t, handler, nil)
+
+	tempDir, err := os.MkdirTemp("", "plan-download-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	destination := filepath.Join(tempDir, "restore")
+
+	plan, err := client.PlanDownload(context.Background(), "backups", destination, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("PlanDownload() error = %v", err)
+	}
+
+	if !plan.DryRun || plan.Operation != PlanActionDownload || plan.TotalFiles != 1 || plan.TotalSizeBytes != 2048 {
+		t.Fatalf("plan = %+v, want one 2048 byte download", plan)
+	}
+
+	item := plan.Items[0]
+	if item.Source != "backups/db-2.sql.gz" || item.Destination != filepath.Join(destination, "db-2.sql.gz") || item.Overwrite {
+		t.Errorf("item = %+v, want the newest object into a new file", item)
+	}
+
+	if _, err := os.Stat(destination); !os.IsNotExist(err) {
+		t.Errorf("PlanDownload() should not create the destination directory")
+	}
+
+	os.MkdirAll(destination, 0755)
+	os.WriteFile(filepath.Join(destination, "db-2.sql.gz"), []byte("old"), 0644)
+	plan, err = client.PlanDownload(context.Background(), "backups", destination, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("PlanDownload() error = %v", err)
+	}
+	if !plan.Items[0].Overwrite || plan.OverwriteCount != 1 {
+		t.Errorf("plan = %+v, want the existing local file reported as overwritten", plan)
+	}
+}
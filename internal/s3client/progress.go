@@ -0,0 +1,206 @@
+package s3client
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"s3manager/pkg/utils"
+)
+
+const (
+	TransferUpload   = "upload"
+	TransferDownload = "download"
+
+	// ProgressPlanned announces Files files of Size bytes in total before they are
+	// transferred. An operation may announce several batches.
+	ProgressPlanned = "planned"
+	ProgressStarted = "started"
+	// ProgressTransferred reports Bytes more bytes of a started file read or written.
+	ProgressTransferred = "transferred"
+	ProgressFinished    = "finished"
+	ProgressFailed      = "failed"
+	// ProgressSkipped reports a planned file of Size bytes that did not need a transfer.
+	ProgressSkipped = "skipped"
+)
+
+// FileProgress describes a single file transfer starting, moving on, finishing or failing,
+// or the files an operation is about to transfer.
+type FileProgress struct {
+	Operation  string
+	Event      string
+	LocalPath  string
+	RemotePath string
+	Size       int64
+	// Files is the number of files of a planned event.
+	Files int
+	// Bytes is the number of bytes of a transferred event. Retried requests read their
+	// body again, so the sum may exceed Size.
+	Bytes int64
+	// Duration and Err are set once the transfer has ended.
+	Duration time.Duration
+	Err      error
+}
+
+// BytesPerSecond returns the average speed of a finished transfer.
+func (p FileProgress) BytesPerSecond() float64 {
+	return utils.BytesPerSecond(p.Size, p.Duration)
+}
+
+// SetProgressHandler registers fn to be called as each file transfer starts, moves on and
+// ends. Transfers may run in parallel, so fn must be safe for concurrent use.
+func (c *Client) SetProgressHandler(fn func(FileProgress)) {
+	c.progress = fn
+}
+
+// planTransfers announces files files of size bytes to the progress handler.
+func (c *Client) planTransfers(operation string, files int, size int64) {
+	if c.progress == nil {
+		return
+	}
+	c.progress(FileProgress{Operation: operation, Event: ProgressPlanned, Files: files, Size: size})
+}
+
+// planUploads announces the files below paths that an upload of the files modified since
+// modifiedSince sends. It walks the paths only when a progress handler is registered.
+func (c *Client) planUploads(paths []string, modifiedSince time.Time) error {
+	if c.progress == nil {
+		return nil
+	}
+	var files int
+	var size int64
+	for _, path := range paths {
+		err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && !info.ModTime().Before(modifiedSince) {
+				files++
+				size += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", path, err)
+		}
+	}
+	c.planTransfers(TransferUpload, files, size)
+	return nil
+}
+
+// transfer reports the progress of one file to the progress handler.
+type transfer struct {
+	client   *Client
+	progress FileProgress
+	start    time.Time
+}
+
+// trackTransfer reports the start of a transfer and returns the transfer that reports
+// its bytes and its end.
+func (c *Client) trackTransfer(operation, localPath, remotePath string, size int64) *transfer {
+	t := &transfer{
+		client: c,
+		progress: FileProgress{
+			Operation:  operation,
+			Event:      ProgressStarted,
+			LocalPath:  localPath,
+			RemotePath: remotePath,
+			Size:       size,
+		},
+		start: time.Now(),
+	}
+	if c.progress != nil {
+		c.progress(t.progress)
+	}
+	return t
+}
+
+// skipTransfer reports that a planned file of size bytes is not transferred.
+func (c *Client) skipTransfer(operation, localPath, remotePath string, size int64) {
+	if c.progress == nil {
+		return
+	}
+	c.progress(FileProgress{Operation: operation, Event: ProgressSkipped, LocalPath: localPath, RemotePath: remotePath, Size: size})
+}
+
+// finished reports the end of the transfer, err is nil when it succeeded.
+func (t *transfer) finished(err error) {
+	if t.client.progress == nil {
+		return
+	}
+	progress := t.progress
+	progress.Duration = time.Since(t.start)
+	progress.Event = ProgressFinished
+	if err != nil {
+		progress.Event = ProgressFailed
+		progress.Err = err
+	}
+	t.client.progress(progress)
+}
+
+func (t *transfer) transferred(n int) {
+	if n <= 0 || t.client.progress == nil {
+		return
+	}
+	progress := t.progress
+	progress.Event = ProgressTransferred
+	progress.Bytes = int64(n)
+	t.client.progress(progress)
+}
+
+// uploadBody is what the uploader needs to send parts of a body in parallel.
+type uploadBody interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+// body returns b reporting the bytes the uploader reads from it.
+func (t *transfer) body(b uploadBody) uploadBody {
+	if t.client.progress == nil {
+		return b
+	}
+	return &countingBody{body: b, transfer: t}
+}
+
+// writerAt returns w reporting the bytes the downloader writes to it.
+func (t *transfer) writerAt(w io.WriterAt) io.WriterAt {
+	if t.client.progress == nil {
+		return w
+	}
+	return &countingWriterAt{w: w, transfer: t}
+}
+
+type countingBody struct {
+	body     uploadBody
+	transfer *transfer
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.transfer.transferred(n)
+	return n, err
+}
+
+func (b *countingBody) ReadAt(p []byte, off int64) (int, error) {
+	n, err := b.body.ReadAt(p, off)
+	b.transfer.transferred(n)
+	return n, err
+}
+
+func (b *countingBody) Seek(offset int64, whence int) (int64, error) {
+	return b.body.Seek(offset, whence)
+}
+
+type countingWriterAt struct {
+	w        io.WriterAt
+	transfer *transfer
+}
+
+func (w *countingWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	n, err := w.w.WriteAt(p, off)
+	w.transfer.transferred(n)
+	return n, err
+}
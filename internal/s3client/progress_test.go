@@ -0,0 +1,123 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"s3manager/internal/s3fake"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUploadFilesReportsProgress(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.URL.Path == "/test-bucket/fail.txt" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	client := newTestClient(t, handler, nil)
+
+	var mu sync.Mutex
+	var events, planned []FileProgress
+	var transferred int64
+	client.SetProgressHandler(func(p FileProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch p.Event {
+		case ProgressPlanned:
+			planned = append(planned, p)
+		case ProgressTransferred:
+			transferred += p.Bytes
+		default:
+			events = append(events, p)
+		}
+	})
+
+	tempDir, err := os.MkdirTemp("", "upload-progress-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "ok.txt")
+	if err := os.WriteFile(path, make([]byte, 2048), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := client.UploadFiles(context.Background(), []string{path}, "", false, "", nil, time.Time{})
+	if err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+
+	if len(events) != 2 || events[0].Event != ProgressStarted || events[1].Event != ProgressFinished {
+		t.Fatalf("events = %+v, want started and finished", events)
+	}
+
+	if events[1].RemotePath != "ok.txt" || events[1].Size != 2048 || events[1].Duration <= 0 {
+		t.Errorf("finished event = %+v, want ok.txt, 2048 bytes and a duration", events[1])
+	}
+
+	if len(planned) != 1 || planned[0].Files != 1 || planned[0].Size != 2048 {
+		t.Errorf("planned = %+v, want 1 file of 2048 bytes", planned)
+	}
+	// The body may be read more than once, e.g. to sign it
+	if transferred < 2048 {
+		t.Errorf("transferred = %d bytes, want at least 2048", transferred)
+	}
+
+	if result.ThroughputBytes <= 0 || result.ThroughputHuman == "" {
+		t.Errorf("throughput = %f (%q), want a positive rate", result.ThroughputBytes, result.ThroughputHuman)
+	}
+
+	events = nil
+	failPath := filepath.Join(tempDir, "fail.txt")
+	if err := os.WriteFile(failPath, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := client.UploadFiles(context.Background(), []string{failPath}, "", false, "", nil, time.Time{}); err == nil {
+		t.Fatalf("UploadFiles() should fail when S3 rejects the object")
+	}
+
+	if len(events) != 2 || events[1].Event != ProgressFailed || events[1].Err == nil {
+		t.Errorf("events = %+v, want started and failed", events)
+	}
+}
+
+func TestDownloadFolderReportsBytes(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "site/a.txt", make([]byte, 3000), time.Now())
+	fake.PutObject("test-bucket", "site/b.txt", make([]byte, 500), time.Now())
+	client := newTestClient(t, fake, nil)
+
+	var mu sync.Mutex
+	var planned []FileProgress
+	transferred := make(map[string]int64)
+	client.SetProgressHandler(func(p FileProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch p.Event {
+		case ProgressPlanned:
+			planned = append(planned, p)
+		case ProgressTransferred:
+			transferred[p.RemotePath] += p.Bytes
+		}
+	})
+
+	if _, err := client.DownloadFolder(context.Background(), "site", t.TempDir(), DownloadOptions{}); err != nil {
+		t.Fatalf("DownloadFolder() error = %v", err)
+	}
+
+	if len(planned) != 1 || planned[0].Operation != TransferDownload || planned[0].Files != 2 || planned[0].Size != 3500 {
+		t.Errorf("planned = %+v, want 2 downloads of 3500 bytes", planned)
+	}
+	if transferred["site/a.txt"] != 3000 || transferred["site/b.txt"] != 500 {
+		t.Errorf("transferred = %v, want every byte written once", transferred)
+	}
+}
@@ -0,0 +1,40 @@
+package s3client
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrProtected is returned when an operation would delete objects under a protected
+// prefix that cannot simply be skipped, such as those listed in a deletion plan.
+var ErrProtected = errors.New("objects under protected prefixes")
+
+// isProtected reports whether key lies under one of the configured protected prefixes.
+// Nothing is protected when AllowProtected is set.
+func (c *Client) isProtected(key string) bool {
+	if c.config.AllowProtected {
+		return false
+	}
+	for _, prefix := range c.config.ProtectedPrefixes {
+		if strings.HasPrefix(key, strings.TrimPrefix(prefix, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutProtected drops the items whose key is protected and returns how many were dropped.
+func withoutProtected[T any](c *Client, items []T, key func(T) string) ([]T, int) {
+	return withoutKeys(items, key, c.isProtected)
+}
+
+// withoutKeys drops the items whose key matches drop and returns how many were dropped.
+func withoutKeys[T any](items []T, key func(T) string, drop func(string) bool) ([]T, int) {
+	kept := items[:0:0]
+	for _, item := range items {
+		if !drop(key(item)) {
+			kept = append(kept, item)
+		}
+	}
+	return kept, len(items) - len(kept)
+}
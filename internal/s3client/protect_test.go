@@ -0,0 +1,96 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"s3manager/config"
+	"s3manager/internal/models"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteOldFilesSkipsProtectedPrefixes(t *testing.T) {
+	var deletedKeys []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			for _, part := range strings.Split(string(body), "<Key>")[1:] {
+				deletedKeys = append(deletedKeys, part[:strings.Index(part, "</Key>")])
+			}
+			fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+			return
+		}
+		fmt.Fprint(w, `<ListBucketResult>
+<Contents><Key>db/wal/0001</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>1</Size></Contents>
+<Contents><Key>db/dump-1.sql</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>1</Size></Contents>
+<Contents><Key>db/walnut.txt</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>1</Size></Contents>
+</ListBucketResult>`)
+	})
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.ProtectedPrefixes = []string{"db/wal/"}
+	})
+
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"db"}, DaysOld: 30})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if len(deletedKeys) != 2 || deletedKeys[0] != "db/dump-1.sql" || deletedKeys[1] != "db/walnut.txt" {
+		t.Errorf("deleted keys = %v, want everything but db/wal/", deletedKeys)
+	}
+	if result.DeletedCount != 2 || result.ProtectedCount != 1 {
+		t.Errorf("result = %+v, want 2 deleted and 1 protected", result)
+	}
+
+	plan := &models.DeletionPlan{
+		BucketName: "test-bucket",
+		Folder:     "db",
+		Objects:    []models.PlanObject{{Key: "db/wal/0001", Size: 1}},
+	}
+	if _, err := client.ApplyDeletionPlan(context.Background(), plan); !errors.Is(err, ErrProtected) {
+		t.Errorf("ApplyDeletionPlan() error = %v, want ErrProtected", err)
+	}
+
+	deletedKeys = nil
+	client.config.AllowProtected = true
+	if _, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"db"}, DaysOld: 30}); err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if len(deletedKeys) != 3 {
+		t.Errorf("deleted keys = %v, want all three with AllowProtected", deletedKeys)
+	}
+}
+
+func TestDeleteOldFilesKeepsExcluded(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	old := time.Now().AddDate(0, 0, -60)
+	for _, key := range []string{"exports/2024/data.csv", "exports/2024/manifest.json", "exports/LATEST"} {
+		fake.PutObject("test-bucket", key, []byte("x"), old)
+	}
+	client := newTestClient(t, fake, nil)
+
+	opts := DeleteOptions{Folders: []string{"exports"}, DaysOld: 30, Exclude: []string{"*.json", "LATEST"}}
+	plan, err := client.PlanDeleteOld(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("PlanDeleteOld() error = %v", err)
+	}
+	if plan.TotalObjects != 1 || plan.ExcludedCount != 2 {
+		t.Errorf("PlanDeleteOld() = %+v, want 1 object and 2 excluded", plan)
+	}
+
+	result, err := client.DeleteOldFiles(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 1 || result.ExcludedCount != 2 {
+		t.Errorf("DeleteOldFiles() = %+v, want 1 deleted and 2 excluded", result)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 2 {
+		t.Errorf("Keys = %v, want the manifest and LATEST kept", keys)
+	}
+}
@@ -0,0 +1,180 @@
+package s3client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Operations that some providers do not implement
+const (
+	featureBucketLocation = "bucket location"
+	featureWebsite        = "bucket website"
+	featureTagging        = "object tagging"
+	featureBucketTagging  = "bucket tagging"
+	featureBucketLogging  = "server access logging"
+	featureRequestMetrics = "request metrics"
+	featureACL            = "access control lists"
+	featureBatchDelete    = "multi-object delete"
+	// ETags that are MD5 digests of the content, which checksum verification relies on
+	featureETagMD5 = "MD5 ETags"
+)
+
+// ErrUnsupported is returned for operations the configured PROVIDER does not implement.
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// providerPreset holds the settings a provider needs to work with the AWS SDK.
+type providerPreset struct {
+	// Region used when REGION is not set
+	region string
+	// Endpoint used when API_URL is not set
+	endpoint string
+	// The service is only reachable through a custom API_URL
+	needsEndpoint bool
+	// Addressing the bucket in the path rather than the host name
+	pathStyle bool
+	// Only send and validate checksums when an operation requires them. Newer SDK
+	// versions add CRC32 checksums to every upload, which many providers reject.
+	checksumsWhenRequired bool
+	// Headers left out of the signature because the service rewrites them in transit
+	unsignedHeaders []string
+	unsupported     []string
+}
+
+var providerPresets = map[string]providerPreset{
+	"aws": {},
+	"minio": {
+		region:        "us-east-1",
+		needsEndpoint: true,
+		pathStyle:     true,
+		unsupported:   []string{featureBucketLocation, featureWebsite, featureBucketLogging, featureRequestMetrics},
+	},
+	// R2 has a single "auto" region and no website API, sites are served by Workers
+	"r2": {
+		region:                "auto",
+		needsEndpoint:         true,
+		pathStyle:             true,
+		checksumsWhenRequired: true,
+		unsupported:           []string{featureBucketLocation, featureWebsite, featureBucketTagging, featureBucketLogging, featureRequestMetrics, featureACL},
+	},
+	"b2": {
+		needsEndpoint:         true,
+		checksumsWhenRequired: true,
+		unsupported:           []string{featureBucketLocation, featureWebsite, featureBucketTagging, featureBucketLogging, featureRequestMetrics},
+	},
+	"wasabi": {
+		region:                "us-east-1",
+		needsEndpoint:         true,
+		checksumsWhenRequired: true,
+		unsupported:           []string{featureWebsite, featureRequestMetrics},
+	},
+	// Google Cloud Storage through its XML API, with HMAC keys as ACCESS_KEY and
+	// SECRET_KEY. Its proxies rewrite Accept-Encoding, which breaks signatures that
+	// include it, and it has no multi-object delete, website API, object tags, S3-style
+	// access logging or S3-style ACLs.
+	"gcs": {
+		region:                "auto",
+		endpoint:              "https://storage.googleapis.com",
+		pathStyle:             true,
+		checksumsWhenRequired: true,
+		unsignedHeaders:       []string{"Accept-Encoding"},
+		unsupported:           []string{featureWebsite, featureTagging, featureBucketTagging, featureBucketLogging, featureRequestMetrics, featureACL, featureBatchDelete},
+	},
+	"ceph": {
+		region:                "us-east-1",
+		needsEndpoint:         true,
+		pathStyle:             true,
+		checksumsWhenRequired: true,
+		unsupported:           []string{featureBucketLocation, featureRequestMetrics},
+	},
+}
+
+// Providers returns the names accepted by PROVIDER.
+func Providers() []string {
+	names := make([]string, 0, len(providerPresets))
+	for name := range providerPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// providerPresetFor returns the preset of provider. Without PROVIDER, a custom API_URL
+// keeps the generic behaviour of earlier versions: path-style addressing and nothing
+// else changed.
+func providerPresetFor(provider, apiURL string) (providerPreset, error) {
+	if provider == "" {
+		return providerPreset{pathStyle: apiURL != ""}, nil
+	}
+
+	preset, ok := providerPresets[strings.ToLower(provider)]
+	if !ok {
+		return providerPreset{}, fmt.Errorf("unknown PROVIDER %q, expected one of: %s",
+			provider, strings.Join(Providers(), ", "))
+	}
+	if preset.needsEndpoint && apiURL == "" {
+		return providerPreset{}, fmt.Errorf("PROVIDER %s requires API_URL to be set", provider)
+	}
+	return preset, nil
+}
+
+// apply sets the SDK options the preset needs.
+func (p providerPreset) apply(awsConfig *aws.Config) {
+	if p.checksumsWhenRequired {
+		awsConfig.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		awsConfig.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	}
+	if len(p.unsignedHeaders) > 0 {
+		awsConfig.APIOptions = append(awsConfig.APIOptions, unsignedHeadersMiddleware(p.unsignedHeaders))
+	}
+}
+
+func (p providerPreset) supports(feature string) bool {
+	for _, unsupported := range p.unsupported {
+		if unsupported == feature {
+			return false
+		}
+	}
+	return true
+}
+
+// Directory buckets have no location, website configuration, object or bucket tags,
+// access logs, request metrics or ACLs, and their ETags are not MD5 digests
+var directoryBucketUnsupported = providerPreset{
+	unsupported: []string{featureBucketLocation, featureWebsite, featureTagging, featureBucketTagging,
+		featureBucketLogging, featureRequestMetrics, featureACL, featureETagMD5},
+}
+
+// Bucket configuration that cannot be read or changed through an access point
+var accessPointUnsupported = providerPreset{
+	unsupported: []string{featureBucketLocation, featureWebsite, featureBucketTagging, featureBucketLogging,
+		featureRequestMetrics, featureACL},
+}
+
+// supports reports whether feature can be used with the provider and bucket.
+func (c *Client) supports(feature string) bool {
+	if c.accessPoint && !accessPointUnsupported.supports(feature) {
+		return false
+	}
+	if c.express && !directoryBucketUnsupported.supports(feature) {
+		return false
+	}
+	return c.provider.supports(feature)
+}
+
+// checkSupported fails with ErrUnsupported when the provider or bucket lacks feature.
+func (c *Client) checkSupported(feature string) error {
+	if c.accessPoint && !c.supports(feature) {
+		return fmt.Errorf("%w: %s is not available through an access point", ErrUnsupported, feature)
+	}
+	if c.express && !c.supports(feature) {
+		return fmt.Errorf("%w: %s is not available for directory buckets", ErrUnsupported, feature)
+	}
+	if !c.supports(feature) {
+		return fmt.Errorf("%w: %s (PROVIDER=%s)", ErrUnsupported, feature, c.config.Provider)
+	}
+	return nil
+}
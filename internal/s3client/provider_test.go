@@ -0,0 +1,123 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"s3manager/config"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestProviderPresetFor(t *testing.T) {
+	if _, err := providerPresetFor("swift", "https://storage.example.com"); err == nil {
+		t.Errorf("providerPresetFor() with an unknown provider should return error")
+	}
+	if _, err := providerPresetFor("r2", ""); err == nil {
+		t.Errorf("providerPresetFor() for r2 without API_URL should return error")
+	}
+
+	preset, err := providerPresetFor("", "http://localhost:9000")
+	if err != nil || !preset.pathStyle {
+		t.Errorf("providerPresetFor() without PROVIDER = %+v, %v, want path style for a custom endpoint", preset, err)
+	}
+	if preset, _ := providerPresetFor("AWS", ""); preset.pathStyle {
+		t.Errorf("providerPresetFor() for aws should use virtual-hosted addressing")
+	}
+}
+
+func TestProviderPresetApplied(t *testing.T) {
+	var locationRequests int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("location") {
+			locationRequests++
+		}
+		switch {
+		case r.URL.Query().Has("list-type"):
+			fmt.Fprint(w, `<ListBucketResult></ListBucketResult>`)
+		case r.URL.Query().Has("website"):
+			t.Errorf("website API should not be called for r2")
+		default:
+			fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`)
+		}
+	})
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.Provider = "r2"
+		cfg.Region = ""
+	})
+
+	if client.awsConfig.Region != "auto" {
+		t.Errorf("region = %q, want auto", client.awsConfig.Region)
+	}
+	if client.awsConfig.RequestChecksumCalculation != aws.RequestChecksumCalculationWhenRequired {
+		t.Errorf("request checksums should only be sent when required")
+	}
+
+	if _, err := client.GetBucketWebsite(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetBucketWebsite() error = %v, want ErrUnsupported", err)
+	}
+	if _, err := client.GetBucketInfo(context.Background()); err != nil {
+		t.Fatalf("GetBucketInfo() error = %v", err)
+	}
+	if locationRequests != 0 {
+		t.Errorf("GetBucketLocation was called %d times, want 0", locationRequests)
+	}
+}
+
+func TestGCSProvider(t *testing.T) {
+	var deletes []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			t.Errorf("DeleteObjects should not be used with gcs")
+		case r.Method == http.MethodDelete:
+			deletes = append(deletes, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			if strings.Contains(strings.ToLower(r.Header.Get("Authorization")), "accept-encoding") {
+				t.Errorf("Accept-Encoding should not be signed, Authorization = %s", r.Header.Get("Authorization"))
+			}
+			if r.Header.Get("Accept-Encoding") == "" {
+				t.Errorf("Accept-Encoding should still be sent")
+			}
+			fmt.Fprint(w, "content")
+		}
+	})
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.Provider = "gcs"
+		cfg.Region = ""
+	})
+
+	if client.awsConfig.Region != "auto" {
+		t.Errorf("region = %q, want auto", client.awsConfig.Region)
+	}
+
+	resp, err := client.s3Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("test-bucket"),
+		Key:    aws.String("a.txt"),
+	})
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	resp.Body.Close()
+
+	deleted, err := client.deleteObjects(context.Background(), []types.ObjectIdentifier{
+		{Key: aws.String("a.txt")},
+		{Key: aws.String("b.txt")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("deleteObjects() error = %v", err)
+	}
+	if len(deleted) != 2 || len(deletes) != 2 {
+		t.Errorf("deleteObjects() deleted %v with requests %v, want both objects deleted one by one", deleted, deletes)
+	}
+
+	if preset, err := providerPresetFor("gcs", ""); err != nil || preset.endpoint == "" {
+		t.Errorf("providerPresetFor() for gcs = %+v, %v, want the default endpoint", preset, err)
+	}
+}
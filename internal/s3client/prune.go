@@ -0,0 +1,125 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/filter"
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+	"s3manager/pkg/utils"
+)
+
+type PruneOptions struct {
+	Folder string
+	// MaxTotalSize is the byte budget of the folder.
+	MaxTotalSize int64
+	// Exclude keeps the objects matching one of these globs; they still count against
+	// the budget.
+	Exclude []string
+	// Filter, when set, keeps the objects it does not match like excluded ones.
+	Filter *filter.Filter
+	DryRun bool
+}
+
+// Prune deletes the oldest objects in the folder until the rest fits within
+// MaxTotalSize. Protected and excluded objects are never deleted but take up the budget
+// like any other object, so newer objects are deleted in their place.
+func (c *Client) Prune(ctx context.Context, opts PruneOptions) (*models.PruneResult, error) {
+	if opts.MaxTotalSize < 0 {
+		return nil, fmt.Errorf("max total size must not be negative")
+	}
+
+	objects, err := c.listObjects(ctx, folderPrefix(opts.Folder))
+	if err != nil {
+		return nil, err
+	}
+	objectKey := func(obj types.Object) string { return aws.ToString(obj.Key) }
+	objects, _ = withoutKeys(objects, objectKey, c.inTrash)
+	sortNewestFirst(objects)
+
+	result := &models.PruneResult{
+		BucketName:        c.config.BucketName,
+		Folder:            opts.Folder,
+		Filter:            opts.Filter.String(),
+		MaxTotalSizeBytes: opts.MaxTotalSize,
+		MaxTotalSizeHuman: utils.FormatBytes(opts.MaxTotalSize),
+		DeletedFiles:      []string{},
+		TrashFolder:       c.TrashFolder(),
+		DryRun:            opts.DryRun,
+	}
+
+	// Kept objects are charged first, so that deleting the oldest of the rest frees the
+	// remaining budget for the newest ones
+	var rest []types.Object
+	for _, obj := range objects {
+		size := aws.ToInt64(obj.Size)
+		result.TotalSizeBytes += size
+		switch key := objectKey(obj); {
+		case c.isProtected(key):
+			result.ProtectedCount++
+		case storage.MatchesAny(key, opts.Exclude) || !opts.Filter.Match(filterObject(obj)):
+			result.ExcludedCount++
+		default:
+			rest = append(rest, obj)
+			continue
+		}
+		result.KeptCount++
+		result.KeptSizeBytes += size
+	}
+
+	// Walking from the newest object, everything from the first one that does not fit
+	// is deleted, so a newer object is never deleted while an older one is kept
+	var toDelete []types.ObjectIdentifier
+	sizes := make(map[string]int64)
+	for _, obj := range rest {
+		size := aws.ToInt64(obj.Size)
+		if len(toDelete) == 0 && result.KeptSizeBytes+size <= opts.MaxTotalSize {
+			result.KeptCount++
+			result.KeptSizeBytes += size
+			continue
+		}
+		toDelete = append(toDelete, types.ObjectIdentifier{Key: obj.Key})
+		sizes[objectKey(obj)] = size
+		result.DeletedFiles = append(result.DeletedFiles, objectKey(obj))
+		result.DeletedSizeBytes += size
+	}
+	result.OverBudget = result.KeptSizeBytes > opts.MaxTotalSize
+
+	if !opts.DryRun && len(toDelete) > 0 {
+		if err := c.checkMaxDelete(len(toDelete)); err != nil {
+			return nil, err
+		}
+		deleted, err := c.removeObjects(ctx, toDelete, nil)
+		result.DeletedCount = len(deleted)
+		if err != nil {
+			if ctx.Err() == nil {
+				return nil, err
+			}
+			// Report only what was deleted before the interruption
+			result.DeletedFiles = deleted
+			result.DeletedSizeBytes = 0
+			for _, key := range deleted {
+				result.DeletedSizeBytes += sizes[key]
+			}
+			result.Interrupted = true
+			result.Error = err.Error()
+			finishPruneResult(result)
+			return result, err
+		}
+	}
+
+	finishPruneResult(result)
+	return result, nil
+}
+
+func finishPruneResult(result *models.PruneResult) {
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.KeptSizeHuman = utils.FormatBytes(result.KeptSizeBytes)
+	result.DeletedSizeHuman = utils.FormatBytes(result.DeletedSizeBytes)
+	result.OperationTime = utils.FormatTime(time.Now())
+}
@@ -0,0 +1,84 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"s3manager/config"
+	"s3manager/internal/filter"
+	"s3manager/internal/s3fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	now := time.Now()
+	// 100 bytes per day, newest first: day-1 .. day-5, plus a protected base backup
+	for day := 1; day <= 5; day++ {
+		fake.PutObject("test-bucket", fmt.Sprintf("backups/day-%d", day), []byte(strings.Repeat("x", 100)), now.AddDate(0, 0, -day))
+	}
+	fake.PutObject("test-bucket", "backups/base/full", []byte(strings.Repeat("x", 150)), now.AddDate(0, 0, -30))
+	client := newTestClient(t, fake, func(cfg *config.Config) {
+		cfg.ProtectedPrefixes = []string{"backups/base/"}
+	})
+
+	// The base backup takes 150 of the 400 bytes, leaving room for two daily backups
+	opts := PruneOptions{Folder: "backups", MaxTotalSize: 400, DryRun: true}
+	result, err := client.Prune(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(result.DeletedFiles) != 3 || result.DeletedFiles[0] != "backups/day-3" || result.KeptSizeBytes != 350 || result.ProtectedCount != 1 {
+		t.Errorf("Prune() dry run = %+v, want day-3 to day-5 deleted", result)
+	}
+	if result.DeletedCount != 0 || len(fake.Keys("test-bucket")) != 6 {
+		t.Errorf("dry run deleted objects")
+	}
+
+	opts.DryRun = false
+	result, err = client.Prune(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if result.DeletedCount != 3 || result.DeletedSizeBytes != 300 || result.OverBudget {
+		t.Errorf("Prune() = %+v, want 3 objects deleted", result)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 3 {
+		t.Errorf("Keys = %v, want the base backup, day-1 and day-2", keys)
+	}
+
+	// Protected objects alone exceed a smaller budget
+	result, err = client.Prune(context.Background(), PruneOptions{Folder: "backups", MaxTotalSize: 100})
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if !result.OverBudget || result.DeletedCount != 2 {
+		t.Errorf("Prune() = %+v, want both daily backups deleted and over budget", result)
+	}
+}
+
+func TestPruneWithFilter(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	now := time.Now()
+	for day := 1; day <= 4; day++ {
+		fake.PutObject("test-bucket", fmt.Sprintf("backups/day-%d.sql.gz", day), []byte(strings.Repeat("x", 100)), now.AddDate(0, 0, -day))
+	}
+	fake.PutObject("test-bucket", "backups/old.json", []byte(strings.Repeat("x", 50)), now.AddDate(0, 0, -30))
+	client := newTestClient(t, fake, nil)
+
+	// The manifest does not match, it is kept and takes 50 of the 250 bytes
+	objects, err := filter.Parse(`size>=100 and not key~"*.json"`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Prune(context.Background(), PruneOptions{Folder: "backups", MaxTotalSize: 250, Filter: objects, DryRun: true})
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(result.DeletedFiles) != 2 || result.ExcludedCount != 1 || result.Filter != objects.String() {
+		t.Errorf("Prune() = %+v, want day-3 and day-4 deleted, the manifest kept", result)
+	}
+}
@@ -0,0 +1,23 @@
+package s3client
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go/middleware"
+
+	"s3manager/pkg/utils"
+)
+
+// rateLimitMiddleware delays every request attempt, including retries, until the shared
+// limiter allows it. It runs after the retry middleware so each attempt costs one token.
+func rateLimitMiddleware(limiter *utils.RateLimiter) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("S3ManagerRateLimit",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if err := limiter.Wait(ctx); err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleFinalize(ctx, in)
+			}), middleware.After)
+	}
+}
@@ -0,0 +1,39 @@
+package s3client
+
+import (
+	"context"
+	"net/http"
+	"s3manager/config"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestRateLimitAppliesToAllRequests(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	client := newTestClient(t, handler, func(cfg *config.Config) {
+		cfg.RateLimit = 20
+		cfg.RateLimitBurst = 1
+	})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := client.s3Client.HeadBucket(context.Background(), &s3.HeadBucketInput{
+			Bucket: aws.String("test-bucket"),
+		})
+		if err != nil {
+			t.Fatalf("HeadBucket() error = %v", err)
+		}
+	}
+
+	// One request is free, the remaining four wait 50ms each
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("5 requests at 20/s took %v, want at least 180ms", elapsed)
+	}
+}
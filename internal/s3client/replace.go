@@ -0,0 +1,173 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// objectHeaders are the headers and user metadata of an object, which a copy with the
+// REPLACE metadata directive, or a multipart upload, has to set again.
+type objectHeaders struct {
+	ContentType          *string
+	CacheControl         *string
+	ContentDisposition   *string
+	ContentEncoding      *string
+	ContentLanguage      *string
+	Metadata             map[string]string
+	StorageClass         types.StorageClass
+	ServerSideEncryption types.ServerSideEncryption
+	SSEKMSKeyId          *string
+}
+
+func headersOf(head *s3.HeadObjectOutput) objectHeaders {
+	return objectHeaders{
+		ContentType:          head.ContentType,
+		CacheControl:         head.CacheControl,
+		ContentDisposition:   head.ContentDisposition,
+		ContentEncoding:      head.ContentEncoding,
+		ContentLanguage:      head.ContentLanguage,
+		Metadata:             head.Metadata,
+		StorageClass:         head.StorageClass,
+		ServerSideEncryption: head.ServerSideEncryption,
+		SSEKMSKeyId:          head.SSEKMSKeyId,
+	}
+}
+
+// multipartInput returns the input of a multipart upload of key with the headers.
+func (h objectHeaders) multipartInput(bucket, key string) *s3.CreateMultipartUploadInput {
+	return &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		ContentType:          h.ContentType,
+		CacheControl:         h.CacheControl,
+		ContentDisposition:   h.ContentDisposition,
+		ContentEncoding:      h.ContentEncoding,
+		ContentLanguage:      h.ContentLanguage,
+		Metadata:             h.Metadata,
+		StorageClass:         h.StorageClass,
+		ServerSideEncryption: h.ServerSideEncryption,
+		SSEKMSKeyId:          h.SSEKMSKeyId,
+	}
+}
+
+// headObject returns the headers of key, decrypting it with the client's SSE-C key.
+func (c *Client) headObject(ctx context.Context, key string) (*s3.HeadObjectOutput, error) {
+	return c.headEncryptedObject(ctx, key, c.sseCustomerKey)
+}
+
+// headEncryptedObject returns the headers of key, decrypting it with sse.
+func (c *Client) headEncryptedObject(ctx context.Context, key string, sse *SSECustomerKey) (*s3.HeadObjectOutput, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = sse.headers()
+	return c.s3Client.HeadObject(ctx, input)
+}
+
+// replaceObject copies the object key onto itself with headers, which resets its
+// LastModified time; its tags are kept. head is the current state of the object: the
+// copy fails with ErrConcurrentWrite when the object has been replaced since. Objects
+// larger than 5GB, which CopyObject cannot copy, are copied as a multipart upload.
+// It returns the ETag of the copy.
+func (c *Client) replaceObject(ctx context.Context, key string, head *s3.HeadObjectOutput, headers objectHeaders) (string, error) {
+	etag := aws.ToString(head.ETag)
+	if aws.ToInt64(head.ContentLength) > maxCopyObjectSize {
+		return c.copyInParts(ctx, key, key, head, headers, etag, c.sseCustomerKey, c.sseCustomerKey)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:               aws.String(c.config.BucketName),
+		Key:                  aws.String(key),
+		CopySource:           aws.String(copySource(c.config.BucketName, key)),
+		CopySourceIfMatch:    aws.String(etag),
+		MetadataDirective:    types.MetadataDirectiveReplace,
+		ContentType:          headers.ContentType,
+		CacheControl:         headers.CacheControl,
+		ContentDisposition:   headers.ContentDisposition,
+		ContentEncoding:      headers.ContentEncoding,
+		ContentLanguage:      headers.ContentLanguage,
+		Metadata:             headers.Metadata,
+		StorageClass:         headers.StorageClass,
+		ServerSideEncryption: headers.ServerSideEncryption,
+		SSEKMSKeyId:          headers.SSEKMSKeyId,
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = c.sseCustomerKey.headers()
+	input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5 = c.sseCustomerKey.headers()
+
+	resp, err := c.s3Client.CopyObject(ctx, input)
+	if err != nil {
+		return "", conflictError(key, fmt.Errorf("failed to copy %s: %w", key, err))
+	}
+	if resp.CopyObjectResult == nil {
+		return "", nil
+	}
+	return aws.ToString(resp.CopyObjectResult.ETag), nil
+}
+
+// copyLargeObject copies the object from to the key to in parts when it is too large
+// for CopyObject, keeping its headers and tags. It reports whether from is that large;
+// smaller objects are left to CopyObject.
+func (c *Client) copyLargeObject(ctx context.Context, from, to string) (bool, error) {
+	head, err := c.headObject(ctx, from)
+	if err != nil || aws.ToInt64(head.ContentLength) <= maxCopyObjectSize {
+		return false, nil
+	}
+	_, err = c.copyInParts(ctx, from, to, head, headersOf(head), "", c.sseCustomerKey, c.sseCustomerKey)
+	return true, err
+}
+
+// copyInParts copies the object source, whose current state is head, to destination as
+// a multipart upload of part copies with headers. The tags have to be set again, unlike
+// with CopyObject. A source replaced meanwhile fails the copy with ErrConcurrentWrite,
+// a non-empty ifMatch only replaces a destination with that ETag. sourceSSE decrypts an
+// SSE-C source and sse encrypts the copy, so that a key is rotated by copying an object
+// onto itself. It returns the ETag of the copy.
+func (c *Client) copyInParts(ctx context.Context, source, destination string, head *s3.HeadObjectOutput, headers objectHeaders, ifMatch string, sourceSSE, sse *SSECustomerKey) (string, error) {
+	if sse != nil {
+		// S3 rejects other server-side encryption together with SSE-C
+		headers.ServerSideEncryption, headers.SSEKMSKeyId = "", nil
+	}
+	input := headers.multipartInput(c.config.BucketName, destination)
+	if err := c.setUploadTags(ctx, input, source); err != nil {
+		return "", err
+	}
+	m, err := c.newComposer(ctx, input, sourceSSE, sse)
+	if err != nil {
+		return "", err
+	}
+	if err := m.addObject(ctx, source, aws.ToString(head.ETag), aws.ToInt64(head.ContentLength)); err != nil {
+		m.abort()
+		return "", conflictError(source, err)
+	}
+	etag, err := m.complete(ctx, ifMatch, false)
+	if err != nil {
+		m.abort()
+		return "", conflictError(destination, fmt.Errorf("failed to copy %s: %w", source, err))
+	}
+	return etag, nil
+}
+
+// setUploadTags sets the tags of the object key on the multipart upload input.
+func (c *Client) setUploadTags(ctx context.Context, input *s3.CreateMultipartUploadInput, key string) error {
+	if !c.supports(featureTagging) {
+		return nil
+	}
+	tags, err := c.objectTags(ctx, key)
+	if err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		values := url.Values{}
+		for k, v := range tags {
+			values.Set(k, v)
+		}
+		input.Tagging = aws.String(values.Encode())
+	}
+	return nil
+}
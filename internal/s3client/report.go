@@ -0,0 +1,85 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// RetentionReport counts the objects and bytes under prefixes per age bucket. bounds are
+// the exclusive upper ages of the buckets, a last bucket holds the older objects.
+func (c *Client) RetentionReport(ctx context.Context, prefixes []string, bounds []time.Duration) (*models.RetentionReport, error) {
+	if len(bounds) == 0 {
+		return nil, fmt.Errorf("at least one age bound is required")
+	}
+	bounds = append([]time.Duration(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	startTime := time.Now()
+	buckets := make([]models.AgeBucket, len(bounds)+1)
+	for i, bound := range bounds {
+		buckets[i].Age = "<" + formatAge(bound)
+	}
+	buckets[len(bounds)].Age = ">=" + formatAge(bounds[len(bounds)-1])
+
+	// Objects are only counted, none is kept in memory
+	var mu sync.Mutex
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		age := startTime.Sub(aws.ToTime(obj.LastModified))
+		i := sort.Search(len(bounds), func(i int) bool { return age < bounds[i] })
+
+		mu.Lock()
+		defer mu.Unlock()
+		buckets[i].Objects++
+		buckets[i].SizeBytes += aws.ToInt64(obj.Size)
+		return false
+	})
+	if _, err := lister.listEach(ctx, prefixes); err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	report := &models.RetentionReport{
+		BucketName:    c.config.BucketName,
+		Prefix:        strings.Join(prefixes, ","),
+		Buckets:       buckets,
+		OperationTime: utils.FormatTime(startTime),
+	}
+	for _, bucket := range buckets {
+		report.TotalObjects += bucket.Objects
+		report.TotalSizeBytes += bucket.SizeBytes
+	}
+	report.TotalSizeHuman = utils.FormatBytes(report.TotalSizeBytes)
+	for i := range buckets {
+		buckets[i].SizeHuman = utils.FormatBytes(buckets[i].SizeBytes)
+		buckets[i].ObjectsPercent = percentOf(buckets[i].Objects, report.TotalObjects)
+		buckets[i].BytesPercent = percentOf(buckets[i].SizeBytes, report.TotalSizeBytes)
+	}
+	return report, nil
+}
+
+// formatAge formats whole days as "30d" and other ages like time.Duration.
+func formatAge(age time.Duration) string {
+	day := 24 * time.Hour
+	if age%day == 0 {
+		return fmt.Sprintf("%dd", age/day)
+	}
+	return age.String()
+}
+
+// percentOf returns part as a percentage of total, rounded to one decimal.
+func percentOf(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
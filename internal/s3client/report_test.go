@@ -0,0 +1,56 @@
+package s3client
+
+import (
+	"context"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestRetentionReport(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	now := time.Now()
+	day := 24 * time.Hour
+	fake.PutObject("test-bucket", "backups/today.sql", make([]byte, 100), now.Add(-time.Hour))
+	fake.PutObject("test-bucket", "backups/last-week.sql", make([]byte, 200), now.Add(-10*day))
+	fake.PutObject("test-bucket", "backups/last-month.sql", make([]byte, 300), now.Add(-40*day))
+	fake.PutObject("test-bucket", "backups/last-year.sql", make([]byte, 400), now.Add(-400*day))
+	fake.PutObject("test-bucket", "logs/app.log", make([]byte, 1000), now.Add(-400*day))
+	client := newTestClient(t, fake, nil)
+
+	report, err := client.RetentionReport(context.Background(), []string{"backups/"}, []time.Duration{90 * day, 7 * day, 30 * day})
+	if err != nil {
+		t.Fatalf("RetentionReport() error = %v", err)
+	}
+
+	want := []struct {
+		age     string
+		objects int64
+		size    int64
+	}{
+		{"<7d", 1, 100},
+		{"<30d", 1, 200},
+		{"<90d", 1, 300},
+		{">=90d", 1, 400},
+	}
+	if len(report.Buckets) != len(want) {
+		t.Fatalf("buckets = %+v, want %d", report.Buckets, len(want))
+	}
+	for i, w := range want {
+		got := report.Buckets[i]
+		if got.Age != w.age || got.Objects != w.objects || got.SizeBytes != w.size {
+			t.Errorf("bucket %d = %+v, want %s with %d objects of %d bytes", i, got, w.age, w.objects, w.size)
+		}
+	}
+	if report.TotalObjects != 4 || report.TotalSizeBytes != 1000 {
+		t.Errorf("totals = %d objects, %d bytes, want 4 objects, 1000 bytes", report.TotalObjects, report.TotalSizeBytes)
+	}
+	if report.Buckets[3].BytesPercent != 40 || report.Buckets[0].ObjectsPercent != 25 {
+		t.Errorf("percentages = %+v, want 40%% of the bytes and 25%% of the objects", report.Buckets)
+	}
+
+	if _, err := client.RetentionReport(context.Background(), []string{""}, nil); err == nil {
+		t.Errorf("RetentionReport() without bounds should return error")
+	}
+}
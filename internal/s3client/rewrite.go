@@ -0,0 +1,185 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// RewriteOptions are the headers Rewrite sets; empty ones are left as they are.
+type RewriteOptions struct {
+	ContentType string
+	// DetectContentType sets the content type from the extension of each key, for keys
+	// with a known extension
+	DetectContentType bool
+	CacheControl      string
+	StorageClass      string
+	// Metadata is merged into the user metadata of each object
+	Metadata map[string]string
+	// RemoveMetadata are the user metadata keys to remove
+	RemoveMetadata []string
+	// Include is a glob the objects must match, like the --pattern of latest.
+	Include     string
+	DryRun      bool
+	Concurrency int
+}
+
+// Rewrite sets the headers of opts on every object under prefix by copying each object
+// onto itself on the server, for example to fix the content type of historical uploads.
+// Objects that already have the headers are not copied. Like Touch, an object replaced
+// meanwhile fails the rewrite rather than losing the newer data, and the first failure
+// stops it.
+func (c *Client) Rewrite(ctx context.Context, prefix string, opts RewriteOptions) (*models.RewriteResult, error) {
+	startTime := time.Now()
+
+	if opts.StorageClass != "" && !slices.Contains(types.StorageClassStandard.Values(), types.StorageClass(opts.StorageClass)) {
+		return nil, fmt.Errorf("unknown storage class %s", opts.StorageClass)
+	}
+	if opts.ContentType != "" && opts.DetectContentType {
+		return nil, fmt.Errorf("set a content type or detect it, not both")
+	}
+
+	objects, err := c.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	objects, _ = withoutKeys(objects, func(obj types.Object) string { return aws.ToString(obj.Key) }, func(key string) bool {
+		return c.inTrash(key) || !matchesPattern(key, opts.Include)
+	})
+
+	result := &models.RewriteResult{
+		BucketName:    c.config.BucketName,
+		Prefix:        prefix,
+		Objects:       []models.RewrittenObject{},
+		DryRun:        opts.DryRun,
+		OperationTime: utils.FormatTime(startTime),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = c.settings().RequestConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			changes, err := c.rewriteObject(ctx, key, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to rewrite %s: %w", key, err)
+					cancel()
+				}
+				return
+			}
+			if len(changes) == 0 {
+				result.Unchanged++
+				return
+			}
+			result.Objects = append(result.Objects, models.RewrittenObject{Key: key, Changes: changes})
+		}(aws.ToString(obj.Key))
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(result.Objects, func(a, b models.RewrittenObject) int { return strings.Compare(a.Key, b.Key) })
+	result.Count = len(result.Objects)
+	result.Duration = time.Since(startTime).Truncate(time.Millisecond).String()
+	return result, nil
+}
+
+// rewriteObject sets the headers of opts on the object key and returns what changed,
+// nothing when it already had them.
+func (c *Client) rewriteObject(ctx context.Context, key string, opts RewriteOptions) ([]models.HeaderChange, error) {
+	head, err := c.headObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	headers := headersOf(head)
+
+	var changes []models.HeaderChange
+	set := func(header string, current *string, value string) *string {
+		if value == "" || aws.ToString(current) == value {
+			return current
+		}
+		changes = append(changes, models.HeaderChange{Header: header, From: aws.ToString(current), To: value})
+		return aws.String(value)
+	}
+
+	contentType := opts.ContentType
+	if opts.DetectContentType {
+		if detected := c.detectContentType(key); detected != "application/octet-stream" {
+			contentType = detected
+		}
+	}
+	headers.ContentType = set("Content-Type", headers.ContentType, contentType)
+	headers.CacheControl = set("Cache-Control", headers.CacheControl, opts.CacheControl)
+
+	currentClass := headers.StorageClass
+	if currentClass == "" {
+		currentClass = types.StorageClassStandard
+	}
+	if opts.StorageClass != "" && string(currentClass) != opts.StorageClass {
+		changes = append(changes, models.HeaderChange{Header: "Storage-Class", From: string(currentClass), To: opts.StorageClass})
+		headers.StorageClass = types.StorageClass(opts.StorageClass)
+	}
+
+	metadata := maps.Clone(headers.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	for _, name := range slices.Sorted(maps.Keys(opts.Metadata)) {
+		if value, ok := metadata[name]; !ok || value != opts.Metadata[name] {
+			changes = append(changes, models.HeaderChange{Header: "x-amz-meta-" + name, From: value, To: opts.Metadata[name]})
+			metadata[name] = opts.Metadata[name]
+		}
+	}
+	for _, name := range opts.RemoveMetadata {
+		if value, ok := metadata[name]; ok {
+			changes = append(changes, models.HeaderChange{Header: "x-amz-meta-" + name, From: value})
+			delete(metadata, name)
+		}
+	}
+	headers.Metadata = metadata
+
+	if len(changes) == 0 || opts.DryRun {
+		return changes, nil
+	}
+	if _, err := c.replaceObject(ctx, key, head, headers); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
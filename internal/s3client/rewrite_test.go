@@ -0,0 +1,81 @@
+package s3client
+
+import (
+	"context"
+	"reflect"
+	"s3manager/internal/models"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestRewrite(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	old := time.Now().AddDate(-1, 0, 0)
+	fake.PutObject("test-bucket", "site/app.css", []byte("body{}"), old)
+	fake.PutObject("test-bucket", "site/logo.svg", []byte("<svg/>"), old)
+	fake.PutObject("test-bucket", "site/data.bin", []byte{0}, old)
+	fake.SetTags("test-bucket", "site/app.css", map[string]string{"team": "web"})
+
+	opts := RewriteOptions{DetectContentType: true, CacheControl: "max-age=3600", Include: "*.css", DryRun: true}
+	result, err := client.Rewrite(ctx, "site/", opts)
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	want := []models.RewrittenObject{{Key: "site/app.css", Changes: []models.HeaderChange{
+		{Header: "Content-Type", From: "binary/octet-stream", To: "text/css"},
+		{Header: "Cache-Control", To: "max-age=3600"},
+	}}}
+	if !reflect.DeepEqual(result.Objects, want) {
+		t.Errorf("Rewrite() objects = %+v, want %+v", result.Objects, want)
+	}
+	if obj, _ := fake.Object("test-bucket", "site/app.css"); obj.ContentType != "" || !obj.LastModified.Equal(old) {
+		t.Errorf("dry run changed the object: %+v", obj)
+	}
+
+	// Without a glob, an unknown extension keeps its content type
+	opts.Include, opts.DryRun = "", false
+	opts.Metadata = map[string]string{"fixed": "yes"}
+	if result, err = client.Rewrite(ctx, "site/", opts); err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if result.Count != 3 {
+		t.Errorf("Rewrite() = %+v, want 3 objects", result)
+	}
+	css, _ := fake.Object("test-bucket", "site/app.css")
+	if css.ContentType != "text/css" || css.CacheControl != "max-age=3600" || css.Metadata["fixed"] != "yes" {
+		t.Errorf("site/app.css = %+v", css)
+	}
+	if !reflect.DeepEqual(css.Tags, map[string]string{"team": "web"}) {
+		t.Errorf("tags = %v, want them kept", css.Tags)
+	}
+	if bin, _ := fake.Object("test-bucket", "site/data.bin"); bin.ContentType != "binary/octet-stream" && bin.ContentType != "" {
+		t.Errorf("site/data.bin has content type %q", bin.ContentType)
+	}
+
+	// Objects that already have the headers are not copied again
+	fake.PutObject("test-bucket", "site/new.css", []byte("a{}"), old)
+	opts.StorageClass = "STANDARD_IA"
+	opts.RemoveMetadata = []string{"fixed"}
+	opts.Metadata = nil
+	opts.DetectContentType, opts.CacheControl = false, ""
+	opts.Include = "app.css"
+	if result, err = client.Rewrite(ctx, "site/", opts); err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	css, _ = fake.Object("test-bucket", "site/app.css")
+	if result.Count != 1 || css.StorageClass != "STANDARD_IA" || css.Metadata["fixed"] != "" || css.ContentType != "text/css" {
+		t.Errorf("Rewrite() = %+v, object = %+v", result, css)
+	}
+	if result, err = client.Rewrite(ctx, "site/", opts); err != nil || result.Count != 0 || result.Unchanged != 1 {
+		t.Errorf("Rewrite() again = %+v, %v, want it unchanged", result, err)
+	}
+
+	if _, err := client.Rewrite(ctx, "site/", RewriteOptions{StorageClass: "COLD"}); err == nil {
+		t.Error("Rewrite() with an unknown storage class succeeded")
+	}
+}
@@ -0,0 +1,92 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"s3manager/internal/signing"
+)
+
+// SetSigner makes uploads sign every file they upload. The detached signature is stored
+// next to the file, under its key with the .sig suffix.
+func (c *Client) SetSigner(signer *signing.Signer) {
+	c.signer = signer
+}
+
+// signatureKey returns the key of the signature of remotePath, or "" when uploads are
+// not signed.
+func (c *Client) signatureKey(remotePath string) string {
+	if c.signer == nil {
+		return ""
+	}
+	return remotePath + signing.Suffix
+}
+
+// sign returns the signature of the file at localPath, or nil when uploads are not signed.
+// Files are signed before they are uploaded, so a missing key fails the upload early.
+func (c *Client) sign(ctx context.Context, localPath string) ([]byte, error) {
+	if c.signer == nil {
+		return nil, nil
+	}
+	signature, err := c.signer.Sign(ctx, localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign %s: %w", localPath, err)
+	}
+	return signature, nil
+}
+
+// putSignature uploads the signature of the object at remotePath.
+func (c *Client) putSignature(ctx context.Context, remotePath string, signature []byte) error {
+	contentType := "text/plain"
+	if c.signer.Method() == signing.MethodGPG {
+		contentType = "application/pgp-signature"
+	}
+	_, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(c.config.BucketName),
+		Key:           aws.String(c.signatureKey(remotePath)),
+		Body:          bytes.NewReader(signature),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(int64(len(signature))),
+		Tagging:       c.expiryTagging(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload signature of %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// verifySignature checks the file downloaded from key to localPath against the signature
+// stored next to the object.
+func (c *Client) verifySignature(ctx context.Context, verifier *signing.Verifier, key, localPath string) error {
+	sigKey := key + signing.Suffix
+	resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(sigKey),
+	})
+	if err != nil {
+		if hasErrorCode(err, "NoSuchKey", "NotFound") {
+			return fmt.Errorf("%s is not signed, %s does not exist", key, sigKey)
+		}
+		return fmt.Errorf("failed to get signature %s: %w", sigKey, err)
+	}
+	defer resp.Body.Close()
+
+	signature, err := io.ReadAll(io.LimitReader(resp.Body, signing.MaxSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read signature %s: %w", sigKey, err)
+	}
+	if len(signature) > signing.MaxSize {
+		return fmt.Errorf("signature %s is larger than %d bytes", sigKey, signing.MaxSize)
+	}
+	return verifier.Verify(ctx, localPath, signature)
+}
+
+func isSignature(key string) bool {
+	return strings.HasSuffix(key, signing.Suffix)
+}
@@ -0,0 +1,126 @@
+package s3client
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"s3manager/internal/signing"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedUploadAndVerifiedDownload(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	client.SetSigner(newTestSigner(t))
+	ctx := context.Background()
+
+	src := filepath.Join(t.TempDir(), "db.sql")
+	os.WriteFile(src, []byte("CREATE TABLE users (id int);\n"), 0644)
+	result, err := client.UploadFiles(ctx, []string{src}, "backups", false, "", nil, time.Time{})
+	if err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	if result.Items[0].Signature != "backups/db.sql.sig" {
+		t.Errorf("Signature = %q, want backups/db.sql.sig", result.Items[0].Signature)
+	}
+	if _, ok := fake.Object("test-bucket", "backups/db.sql.sig"); !ok {
+		t.Fatalf("signature was not uploaded, keys = %v", fake.Keys("test-bucket"))
+	}
+
+	verifier, err := signing.NewVerifier(&config.Config{SignaturePublicKey: filepath.Join(os.Getenv("GNUPGHOME"), "pubring.kbx")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := t.TempDir()
+	download, err := client.DownloadLatestFile(ctx, "backups", dest, DownloadOptions{Verifier: verifier})
+	if err != nil {
+		t.Fatalf("DownloadLatestFile() error = %v", err)
+	}
+	if item := download.Items[0]; item.RemotePath != "backups/db.sql" || !item.SignatureVerified {
+		t.Errorf("downloaded %+v, want verified backups/db.sql", item)
+	}
+
+	// A backup replaced without a new signature is refused and not left on disk
+	os.Remove(filepath.Join(dest, "db.sql"))
+	fake.PutObject("test-bucket", "backups/db.sql", []byte("DROP TABLE users;\n"), time.Now().Add(time.Minute))
+	if _, err := client.DownloadLatestFile(ctx, "backups", dest, DownloadOptions{Verifier: verifier}); err == nil || !strings.Contains(err.Error(), "bad signature") {
+		t.Errorf("DownloadLatestFile() of a tampered file error = %v, want bad signature", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "db.sql")); !os.IsNotExist(err) {
+		t.Errorf("the unverified file should be removed, stat error = %v", err)
+	}
+
+	fake.PutObject("test-bucket", "unsigned/db.sql", []byte("data"), time.Now())
+	if _, err := client.DownloadLatestFile(ctx, "unsigned", dest, DownloadOptions{Verifier: verifier}); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("DownloadLatestFile() of an unsigned file error = %v, want not signed", err)
+	}
+}
+
+// Signatures are uploaded after their file, but are never the newest backup
+func TestLatestObjectsSkipsSignatures(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	now := time.Now().Add(-time.Hour)
+	fake.Now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	client := newTestClient(t, fake, nil)
+	client.SetSigner(newTestSigner(t))
+	ctx := context.Background()
+
+	src := filepath.Join(t.TempDir(), "db.sql")
+	os.WriteFile(src, []byte(strings.Repeat("INSERT INTO users VALUES (1);\n", 100)), 0644)
+	if _, err := client.UploadFiles(ctx, []string{src}, "backups", false, "", nil, time.Time{}); err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+
+	latest, err := client.LatestObjects(ctx, LatestOptions{Prefixes: []string{"backups/"}, Count: 5})
+	if err != nil {
+		t.Fatalf("LatestObjects() error = %v", err)
+	}
+	if latest.MatchedCount != 1 || latest.Items[0].Key != "backups/db.sql" {
+		t.Errorf("LatestObjects() = %+v, want only backups/db.sql", latest.Items)
+	}
+
+	check, err := client.CheckFreshness(ctx, FreshnessOptions{Prefix: "backups/", MaxAge: time.Hour, MinSize: 1024})
+	if err != nil {
+		t.Fatalf("CheckFreshness() error = %v", err)
+	}
+	if check.Status != FreshnessOK {
+		t.Errorf("CheckFreshness() status = %s, problems = %v, want ok", check.Status, check.Problems)
+	}
+}
+
+// newTestSigner generates a GnuPG signing key in a temporary home, skipping the test
+// without gpg.
+func newTestSigner(t *testing.T) *signing.Signer {
+	t.Helper()
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	home, err := os.MkdirTemp("", "gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GNUPGHOME", home)
+	t.Cleanup(func() {
+		exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+		os.RemoveAll(home)
+	})
+	user := "Backup Signing <backup@example.com>"
+	if out, err := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", user, "ed25519", "sign", "never").CombinedOutput(); err != nil {
+		t.Fatalf("failed to generate key: %v\n%s", err, out)
+	}
+	signer, err := signing.NewSigner(&config.Config{SigningKey: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
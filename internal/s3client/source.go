@@ -0,0 +1,85 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SourceDisposal removes uploaded files from the local disk once the checksum of their
+// upload was verified, for "move to S3" semantics on spool directories.
+type SourceDisposal struct {
+	// MoveTo is a local directory the files are moved to instead of being deleted. They
+	// keep their path relative to the uploaded folder.
+	MoveTo string
+}
+
+// SetSourceDisposal makes uploads of individual files remove each file after its upload
+// is verified. nil keeps the files.
+func (c *Client) SetSourceDisposal(disposal *SourceDisposal) {
+	c.sourceDisposal = disposal
+}
+
+// disposeSource verifies the upload of localPath to remotePath and then deletes the file,
+// or moves it to relPath below the MoveTo directory. It returns where the file was moved
+// to, "" when it was deleted.
+func (c *Client) disposeSource(ctx context.Context, localPath, remotePath, relPath string) (string, error) {
+	check, err := c.VerifyChecksum(ctx, remotePath, localPath, ChecksumAuto, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify upload of %s, source kept: %w", localPath, err)
+	}
+	if !*check.Match {
+		return "", fmt.Errorf("checksum of %s does not match the upload, source kept", localPath)
+	}
+
+	if c.sourceDisposal.MoveTo == "" {
+		if err := os.Remove(localPath); err != nil {
+			return "", fmt.Errorf("failed to delete source %s: %w", localPath, err)
+		}
+		return "", nil
+	}
+
+	target := filepath.Join(c.sourceDisposal.MoveTo, relPath)
+	if err := moveFile(localPath, target); err != nil {
+		return "", fmt.Errorf("failed to move source %s: %w", localPath, err)
+	}
+	return target, nil
+}
+
+// moveFile renames src to dst, creating the directories of dst. Across file systems the
+// file is copied and then removed.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
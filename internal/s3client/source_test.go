@@ -0,0 +1,104 @@
+package s3client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestUploadDeletesSource(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	client.SetSourceDisposal(&SourceDisposal{})
+
+	spool := filepath.Join(t.TempDir(), "spool")
+	os.MkdirAll(filepath.Join(spool, "sub"), 0755)
+	os.WriteFile(filepath.Join(spool, "a.csv"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(spool, "sub", "b.csv"), []byte("b"), 0644)
+
+	result, err := client.UploadFiles(context.Background(), []string{spool}, "in", false, "", nil, time.Time{})
+	if err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	for _, item := range result.Items {
+		if !item.SourceRemoved {
+			t.Errorf("%s: SourceRemoved = false", item.LocalPath)
+		}
+		if _, err := os.Stat(item.LocalPath); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", item.LocalPath)
+		}
+	}
+	if _, ok := fake.Object("test-bucket", "in/spool/sub/b.csv"); !ok {
+		t.Error("in/spool/sub/b.csv was not uploaded")
+	}
+}
+
+func TestUploadMovesSource(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	done := filepath.Join(t.TempDir(), "done")
+	client.SetSourceDisposal(&SourceDisposal{MoveTo: done})
+
+	spool := filepath.Join(t.TempDir(), "spool")
+	os.MkdirAll(filepath.Join(spool, "sub"), 0755)
+	os.WriteFile(filepath.Join(spool, "sub", "b.csv"), []byte("b"), 0644)
+
+	result, err := client.UploadFiles(context.Background(), []string{spool}, "", false, "", nil, time.Time{})
+	if err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	want := filepath.Join(done, "spool", "sub", "b.csv")
+	if got := result.Items[0].SourceMovedTo; got != want {
+		t.Errorf("SourceMovedTo = %s, want %s", got, want)
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != "b" {
+		t.Errorf("moved file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(spool, "sub", "b.csv")); !os.IsNotExist(err) {
+		t.Error("source still exists")
+	}
+}
+
+func TestUploadKeepsSourceOnMismatch(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	client.SetSourceDisposal(&SourceDisposal{})
+
+	// The file changes between the upload and the verification
+	src := filepath.Join(t.TempDir(), "a.csv")
+	os.WriteFile(src, []byte("a"), 0644)
+	client.SetProgressHandler(func(p FileProgress) {
+		if p.Event == ProgressFinished {
+			os.WriteFile(src, []byte("changed"), 0644)
+		}
+	})
+
+	if _, err := client.UploadFiles(context.Background(), []string{src}, "", false, "", nil, time.Time{}); err == nil {
+		t.Fatal("UploadFiles() succeeded with a mismatching checksum")
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("source was removed: %v", err)
+	}
+}
+
+func TestUploadArchiveRejectsSourceDisposal(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	client.SetSourceDisposal(&SourceDisposal{})
+
+	src := filepath.Join(t.TempDir(), "a.csv")
+	os.WriteFile(src, []byte("a"), 0644)
+	if _, err := client.UploadFiles(context.Background(), []string{src}, "", true, "", nil, time.Time{}); err == nil {
+		t.Fatal("UploadFiles() archived with source disposal")
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("source was removed: %v", err)
+	}
+}
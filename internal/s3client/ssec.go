@@ -0,0 +1,71 @@
+package s3client
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// SSE-C only supports AES-256
+const (
+	sseCustomerAlgorithm = "AES256"
+	sseCustomerKeySize   = 32
+)
+
+// SSECustomerKey is a key for server-side encryption with customer-provided keys (SSE-C).
+// S3 encrypts objects with it and discards it, so every read of such an object needs the
+// same key again.
+type SSECustomerKey struct {
+	key string
+	md5 string
+}
+
+// ParseSSECustomerKey reads a 256-bit key from a file, holding the raw 32 bytes or their
+// base64 encoding, or from the base64 encoded value itself.
+func ParseSSECustomerKey(value string) (*SSECustomerKey, error) {
+	encoded := value
+	if info, err := os.Stat(value); err == nil && info.Mode().IsRegular() {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSE-C key: %w", err)
+		}
+		if len(data) == sseCustomerKeySize {
+			return newSSECustomerKey(data), nil
+		}
+		encoded = string(data)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("SSE-C key must be a file or base64, e.g. from openssl rand -base64 32")
+	}
+	if len(key) != sseCustomerKeySize {
+		return nil, fmt.Errorf("SSE-C key must be %d bytes for AES-256, got %d", sseCustomerKeySize, len(key))
+	}
+	return newSSECustomerKey(key), nil
+}
+
+func newSSECustomerKey(key []byte) *SSECustomerKey {
+	sum := md5.Sum(key)
+	return &SSECustomerKey{
+		key: base64.StdEncoding.EncodeToString(key),
+		md5: base64.StdEncoding.EncodeToString(sum[:]),
+	}
+}
+
+// headers returns the algorithm, key and key MD5 request fields, all nil without a key.
+func (k *SSECustomerKey) headers() (algorithm, key, keyMD5 *string) {
+	if k == nil {
+		return nil, nil, nil
+	}
+	return aws.String(sseCustomerAlgorithm), aws.String(k.key), aws.String(k.md5)
+}
+
+// SetSSECustomerKey encrypts uploads with key and decrypts downloads with it.
+func (c *Client) SetSSECustomerKey(key *SSECustomerKey) {
+	c.sseCustomerKey = key
+}
@@ -0,0 +1,138 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestParseSSECustomerKey(t *testing.T) {
+	raw := bytes.Repeat([]byte{7}, 32)
+	encoded := base64.StdEncoding.EncodeToString(raw)
+
+	dir := t.TempDir()
+	rawFile := filepath.Join(dir, "raw.key")
+	os.WriteFile(rawFile, raw, 0600)
+	encodedFile := filepath.Join(dir, "encoded.key")
+	os.WriteFile(encodedFile, []byte(encoded+"\n"), 0600)
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"base64", encoded, false},
+		{"raw file", rawFile, false},
+		{"base64 file", encodedFile, false},
+		{"short key", base64.StdEncoding.EncodeToString(raw[:16]), true},
+		{"not base64", "not a key", true},
+		{"missing file", filepath.Join(dir, "missing.key"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseSSECustomerKey(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSSECustomerKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && key.key != encoded {
+				t.Errorf("key = %s, want %s", key.key, encoded)
+			}
+		})
+	}
+}
+
+func TestSSECustomerKeyTransfers(t *testing.T) {
+	oldKey := newSSECustomerKey(bytes.Repeat([]byte{1}, 32))
+	newKey := newSSECustomerKey(bytes.Repeat([]byte{2}, 32))
+
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	client.SetSSECustomerKey(oldKey)
+	ctx := context.Background()
+
+	src := filepath.Join(t.TempDir(), "db.sql")
+	os.WriteFile(src, []byte("secret dump"), 0644)
+	if _, err := client.UploadFiles(ctx, []string{src}, "backups", false, "", nil, time.Time{}); err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	if obj, _ := fake.Object("test-bucket", "backups/db.sql"); obj.SSECustomerKeyMD5 != oldKey.md5 {
+		t.Fatalf("object key MD5 = %q, want the key of the upload", obj.SSECustomerKeyMD5)
+	}
+
+	if _, err := client.DownloadLatestFile(ctx, "backups", t.TempDir(), DownloadOptions{}); err != nil {
+		t.Errorf("DownloadLatestFile() with the key error = %v", err)
+	}
+	withoutKey := newTestClient(t, fake, nil)
+	if _, err := withoutKey.DownloadLatestFile(ctx, "backups", t.TempDir(), DownloadOptions{}); err == nil {
+		t.Errorf("DownloadLatestFile() without the key should return error")
+	}
+
+	// Rotate the key by copying the object onto itself
+	client.SetSSECustomerKey(newKey)
+	if _, err := client.Copy(ctx, "backups/db.sql", "backups/db.sql", nil); err == nil {
+		t.Errorf("Copy() without the source key should return error")
+	}
+	result, err := client.Copy(ctx, "backups/db.sql", "backups/db.sql", oldKey)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if !result.SSECustomerKey {
+		t.Errorf("result = %+v, want an SSE-C copy", result)
+	}
+	if obj, _ := fake.Object("test-bucket", "backups/db.sql"); obj.SSECustomerKeyMD5 != newKey.md5 || string(obj.Data) != "secret dump" {
+		t.Errorf("object = %+v, want the content under the new key", obj)
+	}
+
+	if _, err := withoutKey.Copy(ctx, "backups/db.sql", "backups/db.sql", nil); err == nil {
+		t.Errorf("Copy() of an object onto itself without keys should return error")
+	}
+}
+
+func TestSSECustomerKeyRotatesLargeObjects(t *testing.T) {
+	oldKey := newSSECustomerKey(bytes.Repeat([]byte{1}, 32))
+	newKey := newSSECustomerKey(bytes.Repeat([]byte{2}, 32))
+
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	client.SetSSECustomerKey(oldKey)
+	ctx := context.Background()
+
+	// Objects over the CopyObject limit are re-keyed with part copies
+	fake.MaxCopySize = 6 * 1024 * 1024
+	defer func(size int64) { maxCopyObjectSize = size }(maxCopyObjectSize)
+	maxCopyObjectSize = int64(fake.MaxCopySize)
+
+	data := bytes.Repeat([]byte("0123456789"), 700*1024)
+	src := filepath.Join(t.TempDir(), "vault.tar")
+	os.WriteFile(src, data, 0644)
+	if _, err := client.UploadFiles(ctx, []string{src}, "vault", false, "", nil, time.Time{}); err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	fake.SetTags("test-bucket", "vault/vault.tar", map[string]string{"retention": "7y"})
+
+	client.SetSSECustomerKey(newKey)
+	result, err := client.Copy(ctx, "vault/vault.tar", "vault/vault.tar", oldKey)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if !result.SSECustomerKey {
+		t.Errorf("result = %+v, want an SSE-C copy", result)
+	}
+	obj, _ := fake.Object("test-bucket", "vault/vault.tar")
+	if obj.SSECustomerKeyMD5 != newKey.md5 || !bytes.Equal(obj.Data, data) {
+		t.Errorf("object has %d bytes under key %q, want %d under the new key", len(obj.Data), obj.SSECustomerKeyMD5, len(data))
+	}
+	if obj.Tags["retention"] != "7y" {
+		t.Errorf("tags = %v, want them kept", obj.Tags)
+	}
+	if fake.Uploads() != 0 {
+		t.Errorf("%d multipart uploads left behind", fake.Uploads())
+	}
+}
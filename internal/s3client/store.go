@@ -0,0 +1,146 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/storage"
+)
+
+// Client is the S3 implementation of the storage backend interface.
+var _ storage.AttributeStore = (*Client)(nil)
+
+// Name returns the configured bucket.
+func (c *Client) Name() string {
+	return c.config.BucketName
+}
+
+// List returns every object under prefix.
+func (c *Client) List(ctx context.Context, prefix string) ([]storage.Object, error) {
+	objects, err := c.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.Object, 0, len(objects))
+	for _, obj := range objects {
+		result = append(result, storage.Object{
+			Key:          aws.ToString(obj.Key),
+			Size:         aws.ToInt64(obj.Size),
+			LastModified: aws.ToTime(obj.LastModified),
+		})
+	}
+	return result, nil
+}
+
+// Put uploads body to key, in parts when it is large.
+func (c *Client) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	return c.put(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.config.BucketName),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(c.detectContentType(key)),
+	}, size)
+}
+
+// PutWithAttributes uploads body to key like Put, with the headers, metadata, storage
+// class and tags of attrs. Tags are left out when the provider does not support them.
+func (c *Client) PutWithAttributes(ctx context.Context, key string, body io.Reader, size int64, attrs storage.Attributes) error {
+	input := &s3.PutObjectInput{
+		Bucket:             aws.String(c.config.BucketName),
+		Key:                aws.String(key),
+		Body:               body,
+		ContentType:        aws.String(attrs.ContentType),
+		ContentEncoding:    optionalString(attrs.ContentEncoding),
+		ContentDisposition: optionalString(attrs.ContentDisposition),
+		ContentLanguage:    optionalString(attrs.ContentLanguage),
+		CacheControl:       optionalString(attrs.CacheControl),
+		Metadata:           attrs.Metadata,
+	}
+	if attrs.ContentType == "" {
+		input.ContentType = aws.String(c.detectContentType(key))
+	}
+	// STANDARD is left implicit for providers that do not support storage classes
+	if attrs.StorageClass != "" && attrs.StorageClass != string(types.StorageClassStandard) {
+		input.StorageClass = types.StorageClass(attrs.StorageClass)
+	}
+	if len(attrs.Tags) > 0 && c.supports(featureTagging) {
+		values := url.Values{}
+		for k, v := range attrs.Tags {
+			values.Set(k, v)
+		}
+		input.Tagging = aws.String(values.Encode())
+	}
+	return c.put(ctx, input, size)
+}
+
+func (c *Client) put(ctx context.Context, input *s3.PutObjectInput, size int64) error {
+	// Without a length the uploader buffers parts until body ends
+	if size >= 0 {
+		input.ContentLength = aws.Int64(size)
+	}
+	err := c.upload(ctx, c.newUploader(), input)
+	if err != nil {
+		return fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	return nil
+}
+
+// Attributes returns the headers, user metadata, storage class and tags of key. Tags are
+// nil when the provider does not support them or they may not be read.
+func (c *Client) Attributes(ctx context.Context, key string) (storage.Attributes, error) {
+	head, err := c.headObject(ctx, key)
+	if err != nil {
+		return storage.Attributes{}, fmt.Errorf("failed to check %s: %w", key, err)
+	}
+	attrs := storage.Attributes{
+		ContentType:        aws.ToString(head.ContentType),
+		ContentEncoding:    aws.ToString(head.ContentEncoding),
+		ContentDisposition: aws.ToString(head.ContentDisposition),
+		ContentLanguage:    aws.ToString(head.ContentLanguage),
+		CacheControl:       aws.ToString(head.CacheControl),
+		StorageClass:       string(head.StorageClass),
+		Metadata:           head.Metadata,
+	}
+	if c.supports(featureTagging) {
+		attrs.Tags, err = c.objectTags(ctx, key)
+		switch {
+		case hasErrorCode(err, "AccessDenied"):
+			// Without s3:GetObjectTagging the tags are unknown, like on providers
+			// without tagging, and the object is copied without them
+			slog.Warn("Tags are not readable, copying the object without them", "key", key, "error", err)
+			attrs.Tags = nil
+		case err != nil:
+			return storage.Attributes{}, err
+		}
+	}
+	return attrs, nil
+}
+
+// Get opens the content of key.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes keys in batches, or moves them to the trash when it is enabled.
+func (c *Client) Delete(ctx context.Context, keys []string) ([]string, error) {
+	objects := make([]types.ObjectIdentifier, 0, len(keys))
+	for _, key := range keys {
+		objects = append(objects, types.ObjectIdentifier{Key: aws.String(key)})
+	}
+	return c.removeObjects(ctx, objects, nil)
+}
@@ -0,0 +1,98 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"s3manager/internal/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrateKeepsAttributes(t *testing.T) {
+	fake := s3fake.New("source-bucket", "dest-bucket")
+	defer fake.Close()
+	source := newTestClient(t, fake, func(cfg *config.Config) { cfg.BucketName = "source-bucket" })
+	dest := newTestClient(t, fake, func(cfg *config.Config) { cfg.BucketName = "dest-bucket" })
+	ctx := context.Background()
+
+	attrs := storage.Attributes{
+		ContentType:        "application/json",
+		ContentEncoding:    "gzip",
+		ContentDisposition: `attachment; filename="report.json"`,
+		CacheControl:       "max-age=60",
+		StorageClass:       "STANDARD_IA",
+		Metadata:           map[string]string{"origin": "nightly"},
+		Tags:               map[string]string{"team": "data"},
+	}
+	if err := source.PutWithAttributes(ctx, "reports/a.json", strings.NewReader("{}"), 2, attrs); err != nil {
+		t.Fatalf("PutWithAttributes() error = %v", err)
+	}
+	if err := source.Put(ctx, "reports/b.json", strings.NewReader("[]"), 2); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	result, err := storage.Migrate(ctx, source, dest, storage.MigrateOptions{Prefix: "reports/", Verify: true})
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if !result.Verified || result.CopiedCount != 2 {
+		t.Errorf("Migrate() = %+v, want 2 copied and verified objects", result)
+	}
+	got, err := dest.Attributes(ctx, "reports/a.json")
+	if err != nil {
+		t.Fatalf("Attributes() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, attrs) {
+		t.Errorf("destination attributes = %+v, want %+v", got, attrs)
+	}
+
+	// Objects skipped by size are reported when their attributes differ
+	if err := source.PutWithAttributes(ctx, "reports/b.json", strings.NewReader("[]"), 2, storage.Attributes{ContentType: "text/plain"}); err != nil {
+		t.Fatalf("PutWithAttributes() error = %v", err)
+	}
+	result, err = storage.Migrate(ctx, source, dest, storage.MigrateOptions{Prefix: "reports/", SkipExisting: true, Verify: true})
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	want := []string{"content_type"}
+	if result.Verified || len(result.Mismatches) != 1 || result.Mismatches[0].Key != "reports/b.json" || !reflect.DeepEqual(result.Mismatches[0].Attributes, want) {
+		t.Errorf("Migrate() mismatches = %+v, want the content type of reports/b.json", result.Mismatches)
+	}
+
+	// The storage class can be replaced for destinations without it
+	opts := storage.MigrateOptions{Prefix: "reports/a", StorageClass: "STANDARD", Verify: true}
+	if result, err = storage.Migrate(ctx, source, dest, opts); err != nil || !result.Verified {
+		t.Fatalf("Migrate() = %+v, %v", result, err)
+	}
+	if obj, _ := fake.Object("dest-bucket", "reports/a.json"); obj.StorageClass != "" {
+		t.Errorf("storage class = %q, want STANDARD", obj.StorageClass)
+	}
+}
+
+func TestAttributesWithoutTaggingPermission(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	denyTagging := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Query().Has("tagging") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	})
+	client := newTestClient(t, denyTagging, nil)
+	fake.PutObject("test-bucket", "reports/a.json", []byte("{}"), time.Now())
+
+	attrs, err := client.Attributes(context.Background(), "reports/a.json")
+	if err != nil {
+		t.Fatalf("Attributes() error = %v", err)
+	}
+	if attrs.Tags != nil {
+		t.Errorf("Attributes() tags = %v, want nil when they may not be read", attrs.Tags)
+	}
+}
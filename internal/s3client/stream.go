@@ -0,0 +1,109 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/internal/storage"
+)
+
+// StreamDeleteOld finds the objects a dry run of DeleteOldFiles reports, but passes each
+// one to emit as soon as its listing page arrives instead of collecting them, so memory
+// stays flat on prefixes with millions of objects. emit is never called concurrently.
+// Objects arrive in key order within a shard of the listing but not overall. It returns
+// the number and total size of the emitted objects.
+func (c *Client) StreamDeleteOld(ctx context.Context, opts DeleteOptions, emit func(item models.ListItem) error) (int, int64, error) {
+	now := time.Now()
+	cutoffDate := opts.cutoff(now)
+
+	if len(opts.Tags) > 0 {
+		if err := c.checkSupported(featureTagging); err != nil {
+			return 0, 0, err
+		}
+	}
+	var lastRead map[string]time.Time
+	if opts.UnusedFor > 0 {
+		var err error
+		if lastRead, err = c.lastReads(ctx, now.Add(-opts.UnusedFor)); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var firstErr error
+	var count int
+	var totalSize int64
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	emitDue := func(obj types.Object) bool {
+		key := aws.ToString(obj.Key)
+		if due, _ := opts.due(obj, cutoffDate); !due {
+			return false
+		}
+		if c.isProtected(key) || opts.excludes(key) || c.inTrash(key) {
+			return false
+		}
+		if lastRead != nil && lastRead[key].After(now.Add(-opts.UnusedFor)) {
+			return false
+		}
+		if len(opts.Tags) > 0 {
+			tagged, err := c.hasTags(ctx, key, opts.Tags)
+			if err != nil {
+				fail(err)
+				return false
+			}
+			if !tagged {
+				return false
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr != nil {
+			return false
+		}
+		if err := emit(newListItem(obj, now)); err != nil {
+			firstErr = err
+			cancel()
+			return false
+		}
+		count++
+		totalSize += aws.ToInt64(obj.Size)
+		return false
+	}
+
+	for _, prefix := range storage.FolderPrefixes(opts.Folders) {
+		listPrefix, matches := c.listPrefix(prefix)
+		lister := c.newShardedLister(func(obj types.Object) bool {
+			if obj.LastModified == nil || !matches(aws.ToString(obj.Key)) {
+				return false
+			}
+			return emitDue(obj)
+		})
+
+		_, err := lister.listAll(ctx, listPrefix)
+		if firstErr != nil {
+			return count, totalSize, firstErr
+		}
+		if err != nil {
+			return count, totalSize, fmt.Errorf("failed to list objects: %w", err)
+		}
+	}
+	return count, totalSize, nil
+}
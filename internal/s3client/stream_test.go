@@ -0,0 +1,56 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"s3manager/config"
+	"s3manager/internal/models"
+	"s3manager/internal/s3fake"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestStreamDeleteOld(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	old := time.Now().AddDate(0, 0, -60)
+	var want []string
+	// Enough objects for the listing to be split into shards
+	for i := range 1200 {
+		key := fmt.Sprintf("logs/%02d/%04d.log", i%12, i)
+		fake.PutObject("test-bucket", key, []byte("x"), old)
+		want = append(want, key)
+	}
+	fake.PutObject("test-bucket", "logs/new.log", []byte("x"), time.Now())
+	fake.PutObject("test-bucket", "logs/manifest.json", []byte("x"), old)
+	fake.PutObject("test-bucket", "logs/keep/base.log", []byte("x"), old)
+	client := newTestClient(t, fake, func(cfg *config.Config) {
+		cfg.ListConcurrency = 4
+		cfg.ProtectedPrefixes = []string{"logs/keep/"}
+	})
+
+	var got []string
+	count, size, err := client.StreamDeleteOld(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, Exclude: []string{"*.json"}}, func(item models.ListItem) error {
+		got = append(got, item.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamDeleteOld() error = %v", err)
+	}
+	sort.Strings(got)
+	if count != len(want) || size != int64(len(want)) || len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("StreamDeleteOld() = %d objects, %d bytes, want %d old logs", count, size, len(want))
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != len(want)+3 {
+		t.Errorf("a dry run should not delete anything, %d objects left", len(keys))
+	}
+
+	// An emit error stops the listing
+	_, _, err = client.StreamDeleteOld(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30}, func(models.ListItem) error {
+		return fmt.Errorf("broken pipe")
+	})
+	if err == nil || err.Error() != "broken pipe" {
+		t.Errorf("StreamDeleteOld() error = %v, want the emit error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// withTags returns the items whose object tags include every key=value pair in filter,
+// in their original order. An empty filter keeps all items without any requests.
+func withTags[T any](ctx context.Context, c *Client, items []T, key func(T) string, filter map[string]string) ([]T, error) {
+	if len(filter) == 0 || len(items) == 0 {
+		return items, nil
+	}
+	if err := c.checkSupported(featureTagging); err != nil {
+		return nil, err
+	}
+	return filterTagged(ctx, c, items, key, func(_ string, tags map[string]string) bool {
+		return matchesTags(tags, filter)
+	})
+}
+
+// filterTagged fetches the tags of the objects of items and returns the items keep
+// accepts the tags of, in their original order. keep is never called concurrently.
+func filterTagged[T any](ctx context.Context, c *Client, items []T, key func(T) string, keep func(key string, tags map[string]string) bool) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	matched := make([]bool, len(items))
+	// Tags can only be read one object at a time, so they are fetched in parallel
+	sem := make(chan struct{}, c.settings().RequestConcurrency)
+
+	for i, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			tags, err := c.objectTags(ctx, key)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			matched[i] = keep(key, tags)
+		}(i, key(item))
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var kept []T
+	for i, item := range items {
+		if matched[i] {
+			kept = append(kept, item)
+		}
+	}
+	return kept, nil
+}
+
+// hasTags reports whether the object's tags include every pair in filter.
+func (c *Client) hasTags(ctx context.Context, key string, filter map[string]string) (bool, error) {
+	tags, err := c.objectTags(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return matchesTags(tags, filter), nil
+}
+
+// objectTags returns the tags of the object key.
+func (c *Client) objectTags(ctx context.Context, key string) (map[string]string, error) {
+	resp, err := c.s3Client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags of %s: %w", key, err)
+	}
+
+	tags := make(map[string]string, len(resp.TagSet))
+	for _, tag := range resp.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func matchesTags(tags, filter map[string]string) bool {
+	for key, value := range filter {
+		if current, ok := tags[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDeleteOldFilesTagFilter(t *testing.T) {
+	tags := map[string]string{
+		"logs/staging.log":  `<Tag><Key>environment</Key><Value>staging</Value></Tag><Tag><Key>team</Key><Value>web</Value></Tag>`,
+		"logs/prod.log":     `<Tag><Key>environment</Key><Value>production</Value></Tag>`,
+		"logs/untagged.log": ``,
+	}
+	listing := `<ListBucketResult>
+		<Contents><Key>logs/staging.log</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>10</Size></Contents>
+		<Contents><Key>logs/prod.log</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>20</Size></Contents>
+		<Contents><Key>logs/untagged.log</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>30</Size></Contents>
+	</ListBucketResult>`
+
+	var mu sync.Mutex
+	var deletedKeys []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Has("tagging"):
+			key := strings.TrimPrefix(r.URL.Path, "/test-bucket/")
+			fmt.Fprintf(w, `<Tagging><TagSet>%s</TagSet></Tagging>`, tags[key])
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, listing)
+		default:
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			for _, part := range strings.Split(string(body), "<Key>")[1:] {
+				deletedKeys = append(deletedKeys, part[:strings.Index(part, "</Key>")])
+			}
+			mu.Unlock()
+			fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+		}
+	})
+	client := newTestClient(t, handler, nil)
+	filter := map[string]string{"environment": "staging"}
+
+	plan, err := client.PlanDeleteOld(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, Tags: filter})
+	if err != nil {
+		t.Fatalf("PlanDeleteOld() error = %v", err)
+	}
+	if plan.TotalObjects != 1 || plan.Objects[0].Key != "logs/staging.log" || plan.TagFilter["environment"] != "staging" {
+		t.Errorf("plan = %+v, want only the staging object", plan)
+	}
+
+	result, err := client.DeleteOldFiles(context.Background(), DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, Tags: filter})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 1 || result.TotalSizeBytes != 10 {
+		t.Errorf("result = %+v, want one deleted object of 10 bytes", result)
+	}
+	if len(deletedKeys) != 1 || deletedKeys[0] != "logs/staging.log" {
+		t.Errorf("deleted keys = %v, want [logs/staging.log]", deletedKeys)
+	}
+
+	// Every pair of the filter has to match
+	latest, err := client.LatestObjects(context.Background(), LatestOptions{Prefixes: []string{"logs/"}, Count: 5, Tags: map[string]string{"environment": "staging", "team": "db"}})
+	if err != nil {
+		t.Fatalf("LatestObjects() error = %v", err)
+	}
+	if latest.Count != 0 {
+		t.Errorf("LatestObjects() count = %d, want 0", latest.Count)
+	}
+}
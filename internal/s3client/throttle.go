@@ -0,0 +1,116 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go/middleware"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Retried requests back off for up to this long between attempts, a variable so that
+// tests need not wait
+var throttleMaxBackoff = 20 * time.Second
+
+// throttleCodes recognises SlowDown and the throttling errors of other services
+var throttleCodes = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+// isThrottle reports whether err asks to send fewer requests: a throttling error code,
+// or a 503 or 429 response, which HEAD requests return without a code.
+func isThrottle(err error) bool {
+	if err == nil {
+		return false
+	}
+	if throttleCodes.IsErrorThrottle(err).Bool() {
+		return true
+	}
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		status := responseErr.HTTPStatusCode()
+		return status == http.StatusServiceUnavailable || status == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// adaptiveConcurrencyMiddleware holds every request attempt, including retries, until
+// the limiter has a slot for it, and reports throttled attempts so that it sends fewer
+// at once. It runs after the retry middleware so each attempt takes a slot.
+func adaptiveConcurrencyMiddleware(limiter *utils.AdaptiveLimiter) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("S3ManagerAdaptiveConcurrency",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				ticket, err := limiter.Acquire(ctx)
+				if err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+				out, metadata, err := next.HandleFinalize(ctx, in)
+				if limit := limiter.Release(ticket, isThrottle(err)); limit > 0 {
+					slog.Warn("Requests are throttled, sending fewer at once", "concurrency", limit, "error", err)
+				}
+				return out, metadata, err
+			}), middleware.After)
+	}
+}
+
+// throttleRetryer retries throttled requests up to throttleAttempts times without drawing
+// on the retry quota, so a long run that is slowed down does not fail once the quota is
+// spent. Other errors are tried maxAttempts times.
+type throttleRetryer struct {
+	aws.RetryerV2
+	maxAttempts      int
+	throttleAttempts int
+}
+
+func newThrottleRetryer(maxAttempts, throttleAttempts int) aws.RetryerV2 {
+	maxAttempts = max(maxAttempts, 1)
+	throttleAttempts = max(throttleAttempts, maxAttempts)
+	return &throttleRetryer{
+		RetryerV2: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.MaxAttempts = throttleAttempts
+			o.MaxBackoff = throttleMaxBackoff
+		}),
+		maxAttempts:      maxAttempts,
+		throttleAttempts: throttleAttempts,
+	}
+}
+
+func (r *throttleRetryer) MaxAttempts() int {
+	return r.throttleAttempts
+}
+
+func (r *throttleRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	if isThrottle(opErr) {
+		return func(error) error { return nil }, nil
+	}
+	return r.RetryerV2.GetRetryToken(ctx, opErr)
+}
+
+func (r *throttleRetryer) RetryDelay(attempt int, opErr error) (time.Duration, error) {
+	if attempt >= r.maxAttempts && !isThrottle(opErr) {
+		return 0, &retry.MaxAttemptsError{Attempt: attempt, Err: opErr}
+	}
+	return r.RetryerV2.RetryDelay(attempt, opErr)
+}
+
+// Throttling returns the throttling the client has seen, nil when it saw none.
+func (c *Client) Throttling() *models.Throttling {
+	stats := c.throttle.Stats()
+	if stats.Throttled == 0 {
+		return nil
+	}
+	return &models.Throttling{
+		ThrottledRequests:     stats.Throttled,
+		ConcurrencyReductions: stats.Reductions,
+		MinConcurrency:        stats.MinLimit,
+		FirstThrottled:        utils.FormatTime(stats.First),
+		LastThrottled:         utils.FormatTime(stats.Last),
+	}
+}
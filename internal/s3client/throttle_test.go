@@ -0,0 +1,98 @@
+package s3client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowDown answers the first n requests with 503 SlowDown and passes the rest to next.
+func slowDown(n int64, next http.Handler) http.Handler {
+	var requests atomic.Int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= n {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, `<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>`)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestThrottledRequestsAreRetried(t *testing.T) {
+	backoff := throttleMaxBackoff
+	throttleMaxBackoff = time.Millisecond
+	t.Cleanup(func() { throttleMaxBackoff = backoff })
+
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "data.txt", []byte("data"), time.Now())
+
+	// More throttled attempts than the 3 other errors get
+	client := newTestClient(t, slowDown(5, fake), func(cfg *config.Config) { cfg.ThrottleMaxAttempts = 10 })
+	body, err := client.Get(context.Background(), "data.txt")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body.Close()
+
+	throttling := client.Throttling()
+	if throttling == nil || throttling.ThrottledRequests != 5 || throttling.MinConcurrency != 1 {
+		t.Errorf("Throttling() = %+v, want 5 throttled requests at concurrency 1", throttling)
+	}
+
+	// Without throttling nothing is reported
+	calm := newTestClient(t, fake, nil)
+	if _, err := calm.Get(context.Background(), "data.txt"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if throttling := calm.Throttling(); throttling != nil {
+		t.Errorf("Throttling() without SlowDown = %+v, want nil", throttling)
+	}
+}
+
+func TestThrottleAttempts(t *testing.T) {
+	backoff := throttleMaxBackoff
+	throttleMaxBackoff = time.Millisecond
+	t.Cleanup(func() { throttleMaxBackoff = backoff })
+
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "data.txt", []byte("data"), time.Now())
+
+	client := newTestClient(t, slowDown(3, fake), func(cfg *config.Config) { cfg.ThrottleMaxAttempts = 3 })
+	if _, err := client.Get(context.Background(), "data.txt"); err == nil {
+		t.Error("Get() succeeded although every attempt was throttled")
+	}
+
+	// Other errors are not retried more often than by default
+	var requests atomic.Int64
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `<Error><Code>InternalError</Code><Message>We encountered an internal error.</Message></Error>`)
+	})
+	client = newTestClient(t, failing, func(cfg *config.Config) { cfg.ThrottleMaxAttempts = 10 })
+	if _, err := client.Get(context.Background(), "data.txt"); err == nil {
+		t.Error("Get() of a failing server succeeded")
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("InternalError was tried %d times, want 3", got)
+	}
+
+	requests.Store(0)
+	client = newTestClient(t, failing, func(cfg *config.Config) { cfg.MaxAttempts = 1 })
+	if _, err := client.Get(context.Background(), "data.txt"); err == nil {
+		t.Error("Get() of a failing server succeeded")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("InternalError was tried %d times with MAX_ATTEMPTS=1, want 1", got)
+	}
+}
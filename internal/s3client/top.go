@@ -0,0 +1,101 @@
+package s3client
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Orders of the top report
+const (
+	TopBySize   = "size"
+	TopByRecent = "recent"
+)
+
+// TopObjects returns the limit largest or most recently modified objects under prefixes.
+// Only the current top is kept while listing, so the memory does not grow with the
+// number of objects.
+func (c *Client) TopObjects(ctx context.Context, prefixes []string, by string, limit int) (*models.TopReport, error) {
+	var less func(a, b types.Object) bool
+	switch by {
+	case TopBySize:
+		less = func(a, b types.Object) bool { return aws.ToInt64(a.Size) < aws.ToInt64(b.Size) }
+	case TopByRecent:
+		less = func(a, b types.Object) bool { return aws.ToTime(a.LastModified).Before(aws.ToTime(b.LastModified)) }
+	default:
+		return nil, fmt.Errorf("invalid order %q, expected %s or %s", by, TopBySize, TopByRecent)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	startTime := time.Now()
+	var mu sync.Mutex
+	var scanned int64
+	top := &objectHeap{less: less}
+	lister := c.newShardedLister(func(obj types.Object) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		scanned++
+		if top.Len() < limit {
+			heap.Push(top, obj)
+		} else if less(top.objects[0], obj) {
+			top.objects[0] = obj
+			heap.Fix(top, 0)
+		}
+		return false
+	})
+	if _, err := lister.listEach(ctx, prefixes); err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	objects := top.objects
+	sort.Slice(objects, func(i, j int) bool {
+		if less(objects[j], objects[i]) || less(objects[i], objects[j]) {
+			return less(objects[j], objects[i])
+		}
+		return aws.ToString(objects[i].Key) < aws.ToString(objects[j].Key)
+	})
+
+	items := make([]models.ListItem, 0, len(objects))
+	for _, obj := range objects {
+		items = append(items, newListItem(obj, startTime))
+	}
+	return &models.TopReport{
+		BucketName:     c.config.BucketName,
+		Prefix:         strings.Join(prefixes, ","),
+		By:             by,
+		Limit:          limit,
+		Items:          items,
+		Count:          len(items),
+		ScannedObjects: scanned,
+		OperationTime:  utils.FormatTime(startTime),
+	}, nil
+}
+
+// objectHeap is a min-heap of objects, its root is the smallest of the current top.
+type objectHeap struct {
+	objects []types.Object
+	less    func(a, b types.Object) bool
+}
+
+func (h *objectHeap) Len() int           { return len(h.objects) }
+func (h *objectHeap) Less(i, j int) bool { return h.less(h.objects[i], h.objects[j]) }
+func (h *objectHeap) Swap(i, j int)      { h.objects[i], h.objects[j] = h.objects[j], h.objects[i] }
+func (h *objectHeap) Push(x any)         { h.objects = append(h.objects, x.(types.Object)) }
+
+func (h *objectHeap) Pop() any {
+	last := h.objects[len(h.objects)-1]
+	h.objects = h.objects[:len(h.objects)-1]
+	return last
+}
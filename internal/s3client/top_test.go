@@ -0,0 +1,50 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestTopObjects(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	start := time.Now().Add(-100 * time.Hour)
+	// Sizes and dates in different orders, so both reports pick other objects
+	for i := 0; i < 20; i++ {
+		size := (i * 7) % 20
+		fake.PutObject("test-bucket", fmt.Sprintf("data/object-%02d", i), make([]byte, size*10), start.Add(time.Duration(i)*time.Hour))
+	}
+	fake.PutObject("test-bucket", "other/huge.bin", make([]byte, 1000), time.Now())
+	client := newTestClient(t, fake, nil)
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{TopBySize, []string{"data/object-17", "data/object-14", "data/object-11"}},
+		{TopByRecent, []string{"data/object-19", "data/object-18", "data/object-17"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			report, err := client.TopObjects(context.Background(), []string{"data/"}, tt.by, 3)
+			if err != nil {
+				t.Fatalf("TopObjects() error = %v", err)
+			}
+			if report.Count != len(tt.want) || report.ScannedObjects != 20 {
+				t.Fatalf("report = %+v, want %d of 20 objects", report, len(tt.want))
+			}
+			for i, key := range tt.want {
+				if report.Items[i].Key != key {
+					t.Errorf("item %d = %s, want %s", i, report.Items[i].Key, key)
+				}
+			}
+		})
+	}
+
+	if _, err := client.TopObjects(context.Background(), []string{""}, "name", 3); err == nil {
+		t.Errorf("TopObjects() with an unknown order should return error")
+	}
+}
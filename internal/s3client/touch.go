@@ -0,0 +1,109 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// Touch resets the LastModified time of the object target, or of every object under the
+// prefix target when no such object exists or target ends with a slash, so that age-based
+// cleanups and lifecycle transitions count from now. Each object is copied onto itself
+// on the server with its content type, metadata, storage class and tags; objects that
+// are replaced meanwhile fail the touch rather than losing the newer data.
+func (c *Client) Touch(ctx context.Context, target string) (*models.TouchResult, error) {
+	startTime := time.Now()
+	result := &models.TouchResult{
+		BucketName:    c.config.BucketName,
+		Target:        target,
+		OperationTime: utils.FormatTime(startTime),
+	}
+
+	if !strings.HasSuffix(target, "/") {
+		head, err := c.headObject(ctx, target)
+		switch {
+		case err == nil:
+			if _, err := c.replaceObject(ctx, target, head, headersOf(head)); err != nil {
+				return nil, err
+			}
+			result.Keys = []string{target}
+			result.TotalSizeBytes = aws.ToInt64(head.ContentLength)
+			return finishTouch(result, startTime), nil
+		case !hasErrorCode(err, "NotFound", "NoSuchKey"):
+			return nil, fmt.Errorf("failed to check %s: %w", target, err)
+		}
+	}
+
+	objects, err := c.listObjects(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	objects, _ = withoutKeys(objects, func(obj types.Object) string { return aws.ToString(obj.Key) }, c.inTrash)
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no object %s and no objects under it", target)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, c.settings().RequestConcurrency)
+
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			head, err := c.headObject(ctx, key)
+			if err == nil {
+				_, err = c.replaceObject(ctx, key, head, headersOf(head))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to touch %s: %w", key, err)
+					cancel()
+				}
+				return
+			}
+			result.Keys = append(result.Keys, key)
+			result.TotalSizeBytes += aws.ToInt64(head.ContentLength)
+		}(aws.ToString(obj.Key))
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	slices.Sort(result.Keys)
+	return finishTouch(result, startTime), nil
+}
+
+func finishTouch(result *models.TouchResult, startTime time.Time) *models.TouchResult {
+	result.Count = len(result.Keys)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.Duration = time.Since(startTime).Truncate(time.Millisecond).String()
+	return result
+}
@@ -0,0 +1,80 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestTouch(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+	ctx := context.Background()
+
+	old := time.Now().AddDate(0, 0, -90)
+	fake.PutObject("test-bucket", "reports/q1.pdf", []byte("q1"), old)
+	fake.PutObject("test-bucket", "reports/q2.pdf", []byte("q2"), old)
+	fake.PutObject("test-bucket", "reportsarchive.zip", []byte("zip"), old)
+	fake.PutObject("test-bucket", ".trash/2026-01-01/reports/q0.pdf", []byte("q0"), old)
+	fake.SetTags("test-bucket", "reports/q1.pdf", map[string]string{"keep": "yes"})
+
+	// A single object
+	result, err := client.Touch(ctx, "reports/q1.pdf")
+	if err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Keys, []string{"reports/q1.pdf"}) || result.TotalSizeBytes != 2 {
+		t.Errorf("Touch() = %+v, want reports/q1.pdf", result)
+	}
+	obj, _ := fake.Object("test-bucket", "reports/q1.pdf")
+	if !obj.LastModified.After(old) || string(obj.Data) != "q1" {
+		t.Errorf("object = %+v, want its content with a new LastModified", obj)
+	}
+	if !reflect.DeepEqual(obj.Tags, map[string]string{"keep": "yes"}) {
+		t.Errorf("tags = %v, want them kept", obj.Tags)
+	}
+
+	// A prefix, without the trash
+	result, err = client.Touch(ctx, "reports")
+	if err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Keys, []string{"reports/q1.pdf", "reports/q2.pdf", "reportsarchive.zip"}) {
+		t.Errorf("Touch() keys = %v", result.Keys)
+	}
+	if obj, _ := fake.Object("test-bucket", ".trash/2026-01-01/reports/q0.pdf"); !obj.LastModified.Equal(old) {
+		t.Error("trashed object was touched")
+	}
+
+	if _, err := client.Touch(ctx, "missing/"); err == nil {
+		t.Error("Touch() of an empty prefix succeeded")
+	}
+}
+
+func TestTouchConcurrentWrite(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "data.csv", []byte("old"), time.Now().AddDate(0, 0, -90))
+
+	// Another writer replaces the object between the HEAD and the copy
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "" {
+			fake.PutObject("test-bucket", "data.csv", []byte("new"), time.Now())
+		}
+		fake.ServeHTTP(w, r)
+	})
+	client := newTestClient(t, handler, nil)
+
+	_, err := client.Touch(context.Background(), "data.csv")
+	if !errors.Is(err, ErrConcurrentWrite) {
+		t.Fatalf("Touch() error = %v, want ErrConcurrentWrite", err)
+	}
+	if obj, _ := fake.Object("test-bucket", "data.csv"); string(obj.Data) != "new" {
+		t.Errorf("object holds %q, want the write of the other writer", obj.Data)
+	}
+}
@@ -0,0 +1,342 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/journal"
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	TrashOperationRestore = "restore"
+	TrashOperationPurge   = "purge"
+
+	// Trash folders are named after the day objects were moved there
+	trashDateLayout = "2006-01-02"
+)
+
+// TrashFolder returns the folder objects removed today are moved to, or "" when trash
+// mode is off.
+func (c *Client) TrashFolder() string {
+	if !c.config.UseTrash {
+		return ""
+	}
+	return c.trashPrefix() + time.Now().UTC().Format(trashDateLayout) + "/"
+}
+
+func (c *Client) trashPrefix() string {
+	prefix := strings.TrimPrefix(c.config.TrashPrefix, "/")
+	if prefix == "" {
+		prefix = ".trash/"
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+func (c *Client) inTrash(key string) bool {
+	return c.config.UseTrash && strings.HasPrefix(key, c.trashPrefix())
+}
+
+// removeObjects deletes objects, or moves them to today's trash folder when trash mode
+// is on. Like deleteObjects it returns the removed keys even when it fails part way.
+func (c *Client) removeObjects(ctx context.Context, objects []types.ObjectIdentifier, jr *journal.Journal) ([]string, error) {
+	if !c.config.UseTrash {
+		return c.deleteObjects(ctx, objects, jr)
+	}
+
+	folder := c.TrashFolder()
+	var moves []trashMove
+	for _, obj := range objects {
+		key := aws.ToString(obj.Key)
+		// Never trash the trash itself
+		if !c.inTrash(key) {
+			moves = append(moves, trashMove{from: key, to: folder + key, lookup: true})
+		}
+	}
+
+	copied, copyErr := c.copyMoves(ctx, moves)
+	deleted, err := c.deleteObjects(ctx, copied, jr)
+	if copyErr != nil {
+		return deleted, copyErr
+	}
+	return deleted, err
+}
+
+// trashMove copies the object from to the key to in its storage class, which is class
+// or, with lookup set, read from the object first.
+type trashMove struct {
+	from   string
+	to     string
+	class  types.StorageClass
+	lookup bool
+}
+
+// copyMoves copies every move's source to its target and returns the sources that were
+// copied, stopping at the first failure.
+func (c *Client) copyMoves(ctx context.Context, moves []trashMove) ([]types.ObjectIdentifier, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	var copied []types.ObjectIdentifier
+	sem := make(chan struct{}, c.settings().RequestConcurrency)
+
+	for _, move := range moves {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(move trashMove) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := c.copyMove(ctx, move)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to move %s: %w", move.from, err)
+					cancel()
+				}
+				return
+			}
+			copied = append(copied, types.ObjectIdentifier{Key: aws.String(move.from)})
+		}(move)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return copied, firstErr
+	}
+	return copied, ctx.Err()
+}
+
+// copyMove copies the source of move to its target. CopyObject resets the storage class
+// to STANDARD unless it is given again.
+func (c *Client) copyMove(ctx context.Context, move trashMove) error {
+	class := move.class
+	if move.lookup {
+		head, err := c.headObject(ctx, move.from)
+		if err != nil {
+			return fmt.Errorf("failed to read object: %w", err)
+		}
+		class = head.StorageClass
+	}
+
+	_, err := c.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:       aws.String(c.config.BucketName),
+		Key:          aws.String(move.to),
+		CopySource:   aws.String(copySource(c.config.BucketName, move.from)),
+		StorageClass: class,
+	})
+	if err != nil {
+		// CopyObject fails for objects larger than 5GB, which are copied in parts
+		if large, largeErr := c.copyLargeObject(ctx, move.from, move.to); large {
+			return largeErr
+		}
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	return nil
+}
+
+// RestoreTrash moves the objects trashed on date back to their original keys. Only keys
+// starting with prefix are restored. Objects whose original key exists again are skipped
+// unless overwrite is set.
+func (c *Client) RestoreTrash(ctx context.Context, date, prefix string, overwrite, dryRun bool) (*models.TrashResult, error) {
+	if _, err := time.Parse(trashDateLayout, date); err != nil {
+		return nil, fmt.Errorf("invalid trash date %q, expected YYYY-MM-DD", date)
+	}
+
+	folder := c.trashPrefix() + date + "/"
+	objects, err := c.listObjects(ctx, folder+prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no trashed objects found under %s%s", folder, prefix)
+	}
+
+	var existing map[string]bool
+	if !overwrite {
+		keys := make([]string, len(objects))
+		for i, obj := range objects {
+			keys[i] = strings.TrimPrefix(aws.ToString(obj.Key), folder)
+		}
+		if existing, err = c.existingKeys(ctx, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	result := c.newTrashResult(TrashOperationRestore, dryRun)
+	var moves []trashMove
+	sizes := make(map[string]int64, len(objects))
+	for _, obj := range objects {
+		trashKey := aws.ToString(obj.Key)
+		key := strings.TrimPrefix(trashKey, folder)
+		if existing[key] {
+			result.SkippedKeys = append(result.SkippedKeys, key)
+			continue
+		}
+
+		moves = append(moves, trashMove{from: trashKey, to: key, class: types.StorageClass(obj.StorageClass)})
+		sizes[trashKey] = aws.ToInt64(obj.Size)
+	}
+
+	if dryRun {
+		for _, move := range moves {
+			addTrashItem(result, move.to, move.from, sizes[move.from])
+		}
+		return result, nil
+	}
+
+	copied, copyErr := c.copyMoves(ctx, moves)
+	deleted, err := c.deleteObjects(ctx, copied, nil)
+	for _, trashKey := range deleted {
+		addTrashItem(result, strings.TrimPrefix(trashKey, folder), trashKey, sizes[trashKey])
+	}
+	if copyErr != nil {
+		return nil, copyErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PurgeTrash permanently deletes the trash folders of days more than olderThanDays ago;
+// 0 empties the whole trash.
+func (c *Client) PurgeTrash(ctx context.Context, olderThanDays int, dryRun bool) (*models.TrashResult, error) {
+	cutoff := time.Now().UTC().AddDate(0, 0, -olderThanDays).Format(trashDateLayout)
+	prefix := c.trashPrefix()
+
+	objects, err := c.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := c.newTrashResult(TrashOperationPurge, dryRun)
+	var toDelete []types.ObjectIdentifier
+	for _, obj := range objects {
+		trashKey := aws.ToString(obj.Key)
+		date, key, ok := strings.Cut(strings.TrimPrefix(trashKey, prefix), "/")
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(trashDateLayout, date); err != nil {
+			continue
+		}
+		// Dates sort lexically; today's folder is kept unless the whole trash is purged
+		if olderThanDays > 0 && date >= cutoff {
+			continue
+		}
+		toDelete = append(toDelete, types.ObjectIdentifier{Key: obj.Key})
+		addTrashItem(result, key, trashKey, aws.ToInt64(obj.Size))
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := c.checkMaxDelete(len(toDelete)); err != nil {
+		return nil, err
+	}
+	if _, err := c.deleteObjects(ctx, toDelete, nil); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) newTrashResult(operation string, dryRun bool) *models.TrashResult {
+	return &models.TrashResult{
+		BucketName:     c.config.BucketName,
+		Operation:      operation,
+		TrashPrefix:    c.trashPrefix(),
+		Items:          []models.TrashItem{},
+		TotalSizeHuman: utils.FormatBytes(0),
+		OperationTime:  utils.FormatTime(time.Now()),
+		DryRun:         dryRun,
+	}
+}
+
+func addTrashItem(result *models.TrashResult, key, trashKey string, size int64) {
+	result.Items = append(result.Items, models.TrashItem{Key: key, TrashKey: trashKey, Size: size})
+	result.Count++
+	result.TotalSizeBytes += size
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+}
+
+// existingKeys returns which of keys exist, checking them concurrently.
+func (c *Client) existingKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	existing := make(map[string]bool)
+	sem := make(chan struct{}, c.settings().RequestConcurrency)
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			exists, err := c.objectExists(ctx, key)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			if exists {
+				existing[key] = true
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return existing, ctx.Err()
+}
+
+func (c *Client) objectExists(ctx context.Context, key string) (bool, error) {
+	_, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(key),
+	})
+	if hasErrorCode(err, "NotFound", "NoSuchKey") {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", key, err)
+	}
+	return true, nil
+}
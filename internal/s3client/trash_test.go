@@ -0,0 +1,207 @@
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"s3manager/config"
+	"s3manager/internal/s3fake"
+	"s3manager/internal/storage"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeBucket keeps object sizes in memory and supports the calls used to move objects.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string]int64
+}
+
+func (b *fakeBucket) keys() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/test-bucket/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		prefix := r.URL.Query().Get("prefix")
+		fmt.Fprint(w, `<ListBucketResult>`)
+		for k, size := range b.objects {
+			if strings.HasPrefix(k, prefix) {
+				fmt.Fprintf(w, `<Contents><Key>%s</Key><LastModified>2020-01-01T00:00:00Z</LastModified><Size>%d</Size></Contents>`, k, size)
+			}
+		}
+		fmt.Fprint(w, `</ListBucketResult>`)
+	case r.Method == http.MethodHead:
+		if _, ok := b.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+		size, ok := b.objects[strings.TrimPrefix(source, "test-bucket/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		b.objects[key] = size
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+	case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+		for _, part := range strings.Split(string(body), "<Key>")[1:] {
+			delete(b.objects, part[:strings.Index(part, "</Key>")])
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestTrashMoveRestoreAndPurge(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string]int64{
+		"logs/a.log":                   10,
+		"logs/b.log":                   20,
+		".trash/2020-01-01/logs/x.log": 5,
+	}}
+	client := newTestClient(t, bucket, func(cfg *config.Config) {
+		cfg.UseTrash = true
+		cfg.TrashPrefix = ".trash"
+	})
+	ctx := context.Background()
+	today := time.Now().UTC().Format("2006-01-02")
+
+	result, err := client.DeleteOldFiles(ctx, DeleteOptions{DaysOld: 30})
+	if err != nil {
+		t.Fatalf("DeleteOldFiles() error = %v", err)
+	}
+	if result.DeletedCount != 2 || result.TrashFolder != ".trash/"+today+"/" {
+		t.Errorf("result = %+v, want 2 objects moved to today's trash", result)
+	}
+	want := []string{".trash/2020-01-01/logs/x.log", ".trash/" + today + "/logs/a.log", ".trash/" + today + "/logs/b.log"}
+	if got := bucket.keys(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("keys after trashing = %v, want %v", got, want)
+	}
+
+	// A new a.log was uploaded since; it is kept unless overwriting
+	bucket.mu.Lock()
+	bucket.objects["logs/a.log"] = 99
+	bucket.mu.Unlock()
+
+	restored, err := client.RestoreTrash(ctx, today, "logs/", false, false)
+	if err != nil {
+		t.Fatalf("RestoreTrash() error = %v", err)
+	}
+	if restored.Count != 1 || restored.Items[0].Key != "logs/b.log" || len(restored.SkippedKeys) != 1 {
+		t.Errorf("restored = %+v, want b.log restored and a.log skipped", restored)
+	}
+	if bucket.objects["logs/a.log"] != 99 || bucket.objects["logs/b.log"] != 20 {
+		t.Errorf("objects = %v, want the new a.log kept and b.log back", bucket.objects)
+	}
+
+	purged, err := client.PurgeTrash(ctx, 7, false)
+	if err != nil {
+		t.Fatalf("PurgeTrash() error = %v", err)
+	}
+	if purged.Count != 1 || purged.Items[0].TrashKey != ".trash/2020-01-01/logs/x.log" {
+		t.Errorf("purged = %+v, want only the old trash folder", purged)
+	}
+	if _, ok := bucket.objects[".trash/"+today+"/logs/a.log"]; !ok {
+		t.Errorf("today's trash should be kept when purging older than 7 days")
+	}
+
+	if _, err := client.RestoreTrash(ctx, "yesterday", "", false, false); err == nil {
+		t.Errorf("RestoreTrash() with an invalid date should return error")
+	}
+}
+
+func TestTrashMovesLargeObjects(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) {
+		cfg.UseTrash = true
+	})
+
+	// Objects over the CopyObject limit are copied into the trash in parts
+	fake.MaxCopySize = 6 * 1024 * 1024
+	defer func(size int64) { maxCopyObjectSize = size }(maxCopyObjectSize)
+	maxCopyObjectSize = int64(fake.MaxCopySize)
+
+	large := bytes.Repeat([]byte("0123456789"), 700*1024)
+	fake.PutObject("test-bucket", "db/base.tar", large, time.Now())
+	fake.SetTags("test-bucket", "db/base.tar", map[string]string{"kind": "base"})
+	fake.PutObject("test-bucket", "db/wal.log", []byte("wal"), time.Now())
+
+	deleted, err := client.removeObjects(context.Background(), []types.ObjectIdentifier{
+		{Key: aws.String("db/base.tar")},
+		{Key: aws.String("db/wal.log")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("removeObjects() error = %v", err)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("removeObjects() deleted %v, want both objects", deleted)
+	}
+	trashed, ok := fake.Object("test-bucket", client.TrashFolder()+"db/base.tar")
+	if !ok || !bytes.Equal(trashed.Data, large) {
+		t.Fatalf("trashed copy has %d bytes, want %d", len(trashed.Data), len(large))
+	}
+	if trashed.Tags["kind"] != "base" {
+		t.Errorf("trashed tags = %v, want them kept", trashed.Tags)
+	}
+	if _, ok := fake.Object("test-bucket", "db/base.tar"); ok {
+		t.Error("db/base.tar was not deleted")
+	}
+	if fake.Uploads() != 0 {
+		t.Errorf("%d multipart uploads left behind", fake.Uploads())
+	}
+}
+
+func TestTrashKeepsStorageClass(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, func(cfg *config.Config) {
+		cfg.UseTrash = true
+	})
+	ctx := context.Background()
+
+	attrs := storage.Attributes{StorageClass: "STANDARD_IA"}
+	if err := client.PutWithAttributes(ctx, "logs/a.log", strings.NewReader("a"), 1, attrs); err != nil {
+		t.Fatalf("PutWithAttributes() error = %v", err)
+	}
+	if _, err := client.removeObjects(ctx, []types.ObjectIdentifier{{Key: aws.String("logs/a.log")}}, nil); err != nil {
+		t.Fatalf("removeObjects() error = %v", err)
+	}
+	trashed, ok := fake.Object("test-bucket", client.TrashFolder()+"logs/a.log")
+	if !ok || trashed.StorageClass != "STANDARD_IA" {
+		t.Fatalf("trashed storage class = %q, want STANDARD_IA", trashed.StorageClass)
+	}
+
+	date := strings.TrimSuffix(strings.TrimPrefix(client.TrashFolder(), ".trash/"), "/")
+	if _, err := client.RestoreTrash(ctx, date, "", false, false); err != nil {
+		t.Fatalf("RestoreTrash() error = %v", err)
+	}
+	restored, ok := fake.Object("test-bucket", "logs/a.log")
+	if !ok || restored.StorageClass != "STANDARD_IA" {
+		t.Errorf("restored storage class = %q, want STANDARD_IA", restored.StorageClass)
+	}
+}
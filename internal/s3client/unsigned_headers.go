@@ -0,0 +1,48 @@
+package s3client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+type unsignedHeadersKey struct{}
+
+// unsignedHeadersMiddleware removes headers from the request while it is signed and puts
+// them back afterwards, so a proxy changing them does not invalidate the signature.
+func unsignedHeadersMiddleware(headers []string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		err := stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("S3ManagerHideUnsignedHeaders",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if req, ok := in.Request.(*smithyhttp.Request); ok {
+					hidden := make(http.Header)
+					for _, name := range headers {
+						name = http.CanonicalHeaderKey(name)
+						if values, ok := req.Header[name]; ok {
+							hidden[name] = values
+							req.Header.Del(name)
+						}
+					}
+					ctx = middleware.WithStackValue(ctx, unsignedHeadersKey{}, hidden)
+				}
+				return next.HandleFinalize(ctx, in)
+			}), "Signing", middleware.Before)
+		if err != nil {
+			return err
+		}
+
+		return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("S3ManagerRestoreUnsignedHeaders",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				req, ok := in.Request.(*smithyhttp.Request)
+				hidden, _ := middleware.GetStackValue(ctx, unsignedHeadersKey{}).(http.Header)
+				if ok {
+					for name, values := range hidden {
+						req.Header[name] = values
+					}
+				}
+				return next.HandleFinalize(ctx, in)
+			}), "Signing", middleware.After)
+	}
+}
@@ -0,0 +1,131 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"s3manager/internal/s3fake"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUploadFilesAbortsMultipartOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var aborted int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>test-bucket</Bucket><Key>big.bin</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut && query.Has("partNumber"):
+			io.Copy(io.Discard, r.Body)
+			// Simulate Ctrl-C while the parts are in flight
+			cancel()
+			<-r.Context().Done()
+		case r.Method == http.MethodDelete && query.Get("uploadId") == "upload-1":
+			atomic.AddInt32(&aborted, 1)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	})
+	client := newTestClient(t, handler, nil)
+
+	tempDir, err := os.MkdirTemp("", "upload-cancel-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Large enough to need several 5MB parts
+	path := filepath.Join(tempDir, "big.bin")
+	if err := os.WriteFile(path, make([]byte, 12*1024*1024), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := client.UploadFiles(ctx, []string{path}, "", false, "", nil, time.Time{})
+	if err == nil {
+		t.Fatalf("UploadFiles() should fail when the context is cancelled")
+	}
+
+	if result == nil || !result.Interrupted || result.Error == "" {
+		t.Errorf("result = %+v, want an interrupted partial result", result)
+	}
+
+	if atomic.LoadInt32(&aborted) != 1 {
+		t.Errorf("AbortMultipartUpload calls = %d, want 1", aborted)
+	}
+}
+
+func TestUploadFilesRemovesArchiveOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := newTestClient(t, http.NotFoundHandler(), nil)
+
+	tempDir, err := os.MkdirTemp("", "upload-archive-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "data.txt")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := client.UploadFiles(ctx, []string{path}, "", true, "", nil, time.Time{})
+	if err == nil || result == nil || !result.Interrupted {
+		t.Fatalf("UploadFiles() = %+v, %v, want an interrupted result", result, err)
+	}
+
+	if _, err := os.Stat(result.ArchivePath); !os.IsNotExist(err) {
+		t.Errorf("temporary archive %s should be removed, stat error = %v", result.ArchivePath, err)
+	}
+}
+
+func TestUploadFilesModifiedSince(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	client := newTestClient(t, fake, nil)
+
+	tempDir, err := os.MkdirTemp("", "s3manager-incremental-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	src := filepath.Join(tempDir, "site")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "old.html"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(src, "new.html"), []byte("new"), 0644)
+	lastRun := time.Now().Add(-time.Hour)
+	old := lastRun.Add(-time.Hour)
+	os.Chtimes(filepath.Join(src, "old.html"), old, old)
+
+	result, err := client.UploadFiles(context.Background(), []string{src}, "mirror", false, "", nil, lastRun)
+	if err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	if result.TotalFiles != 1 || result.Items[0].RemotePath != "mirror/site/new.html" || result.SkippedCount != 1 || result.ModifiedSince == "" {
+		t.Errorf("UploadFiles() = %+v, want only new.html uploaded", result)
+	}
+
+	// Nothing changed since: no empty archive is uploaded
+	result, err = client.UploadFiles(context.Background(), []string{src}, "archives", true, "", nil, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	if result.TotalFiles != 0 || result.ArchiveCreated || result.SkippedCount != 2 {
+		t.Errorf("UploadFiles() = %+v, want nothing uploaded", result)
+	}
+	if keys := fake.Keys("test-bucket"); len(keys) != 1 {
+		t.Errorf("Keys = %v, want only mirror/site/new.html", keys)
+	}
+}
@@ -0,0 +1,107 @@
+package s3client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+type WaitOptions struct {
+	// Pattern is a key, or a glob like "exports/*/done.json" that is matched against
+	// whole keys when it contains a slash and against object names otherwise.
+	Pattern  string
+	Interval time.Duration
+	// MinSize and NewerThan are conditions the object has to meet, not checked when zero.
+	MinSize   int64
+	NewerThan time.Time
+}
+
+// Wait polls the bucket every Interval until an object matching the pattern and the
+// conditions appears, and returns the newest one. When the deadline of ctx passes first,
+// the result has TimedOut set instead of an error; other cancellations are errors.
+func (c *Client) Wait(ctx context.Context, opts WaitOptions) (*models.WaitResult, error) {
+	if opts.Pattern == "" {
+		return nil, fmt.Errorf("pattern must not be empty")
+	}
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+
+	start := time.Now()
+	result := &models.WaitResult{
+		BucketName:    c.config.BucketName,
+		Pattern:       opts.Pattern,
+		OperationTime: utils.FormatTime(start),
+	}
+
+	// Only the part before the first wildcard narrows the listing
+	prefix := opts.Pattern
+	if i := strings.IndexAny(prefix, "*?[\\"); i >= 0 {
+		prefix = prefix[:i]
+	}
+
+	for {
+		result.Attempts++
+		item, err := c.findMatch(ctx, prefix, opts)
+		if err != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, err
+		}
+		if item != nil {
+			result.Found = true
+			result.Object = item
+			break
+		}
+		if err != nil {
+			break
+		}
+
+		slog.Debug("No matching object yet", "pattern", opts.Pattern, "attempt", result.Attempts)
+		select {
+		case <-ctx.Done():
+		case <-time.After(opts.Interval):
+			continue
+		}
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, context.Cause(ctx)
+		}
+		break
+	}
+
+	result.TimedOut = !result.Found
+	result.Waited = time.Since(start).Truncate(time.Second).String()
+	return result, nil
+}
+
+// findMatch returns the newest object under prefix that matches the pattern and the
+// conditions of opts, or nil when there is none.
+func (c *Client) findMatch(ctx context.Context, prefix string, opts WaitOptions) (*models.ListItem, error) {
+	objects, err := c.listObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(objects)
+
+	for _, obj := range objects {
+		key := aws.ToString(obj.Key)
+		if key != opts.Pattern && !matchesPattern(key, opts.Pattern) {
+			continue
+		}
+		if opts.MinSize > 0 && aws.ToInt64(obj.Size) < opts.MinSize {
+			continue
+		}
+		if !opts.NewerThan.IsZero() && aws.ToTime(obj.LastModified).Before(opts.NewerThan) {
+			continue
+		}
+		item := newListItem(obj, time.Now())
+		return &item, nil
+	}
+	return nil, nil
+}
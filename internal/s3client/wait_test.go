@@ -0,0 +1,58 @@
+package s3client
+
+import (
+	"context"
+	"s3manager/internal/s3fake"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "exports/2024-03-14/done.json", []byte("{}"), time.Now().Add(-24*time.Hour))
+	client := newTestClient(t, fake, nil)
+
+	// The export of the day is published while waiting
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fake.PutObject("test-bucket", "exports/2024-03-15/done.json", []byte("{}"), time.Now())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := client.Wait(ctx, WaitOptions{
+		Pattern:   "exports/*/done.json",
+		Interval:  10 * time.Millisecond,
+		NewerThan: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if !result.Found || result.TimedOut || result.Object.Key != "exports/2024-03-15/done.json" || result.Attempts < 2 {
+		t.Errorf("Wait() = %+v, want the new export found after several attempts", result)
+	}
+}
+
+func TestWaitTimesOut(t *testing.T) {
+	fake := s3fake.New("test-bucket")
+	defer fake.Close()
+	fake.PutObject("test-bucket", "backups/db.sql.gz", []byte("partial"), time.Now())
+	client := newTestClient(t, fake, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := client.Wait(ctx, WaitOptions{Pattern: "backups/db.sql.gz", Interval: 10 * time.Millisecond, MinSize: 1024})
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if result.Found || !result.TimedOut || result.Object != nil {
+		t.Errorf("Wait() = %+v, want a timeout for the too small object", result)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Wait(ctx, WaitOptions{Pattern: "missing", Interval: time.Second}); err == nil {
+		t.Errorf("Wait() with a cancelled context should return error")
+	}
+}
@@ -0,0 +1,177 @@
+package s3client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+func (c *Client) GetBucketWebsite(ctx context.Context) (*models.BucketWebsite, error) {
+	if err := c.checkSupported(featureWebsite); err != nil {
+		return nil, err
+	}
+	bucketName := c.config.BucketName
+
+	resp, err := c.s3Client.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		if hasErrorCode(err, "NoSuchWebsiteConfiguration") {
+			return &models.BucketWebsite{
+				BucketName:    bucketName,
+				Enabled:       false,
+				OperationTime: utils.FormatTime(time.Now()),
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to get bucket website configuration: %w", err)
+	}
+
+	website := &models.BucketWebsite{
+		BucketName:      bucketName,
+		Enabled:         true,
+		WebsiteEndpoint: c.websiteEndpoint(),
+		OperationTime:   utils.FormatTime(time.Now()),
+	}
+	if resp.IndexDocument != nil {
+		website.IndexDocument = aws.ToString(resp.IndexDocument.Suffix)
+	}
+	if resp.ErrorDocument != nil {
+		website.ErrorDocument = aws.ToString(resp.ErrorDocument.Key)
+	}
+	if resp.RedirectAllRequestsTo != nil {
+		website.RedirectAllRequestsTo = &models.WebsiteRedirect{
+			HostName: aws.ToString(resp.RedirectAllRequestsTo.HostName),
+			Protocol: string(resp.RedirectAllRequestsTo.Protocol),
+		}
+	}
+	for _, rule := range resp.RoutingRules {
+		website.RoutingRules = append(website.RoutingRules, fromRoutingRule(rule))
+	}
+
+	return website, nil
+}
+
+func (c *Client) PutBucketWebsite(ctx context.Context, website *models.BucketWebsite) (*models.BucketWebsite, error) {
+	if err := c.checkSupported(featureWebsite); err != nil {
+		return nil, err
+	}
+	bucketName := c.config.BucketName
+
+	configuration := &types.WebsiteConfiguration{}
+	if website.RedirectAllRequestsTo != nil {
+		if website.IndexDocument != "" || website.ErrorDocument != "" || len(website.RoutingRules) > 0 {
+			return nil, fmt.Errorf("redirect-all cannot be combined with index/error documents or routing rules")
+		}
+		configuration.RedirectAllRequestsTo = &types.RedirectAllRequestsTo{
+			HostName: aws.String(website.RedirectAllRequestsTo.HostName),
+			Protocol: types.Protocol(website.RedirectAllRequestsTo.Protocol),
+		}
+	} else {
+		if website.IndexDocument == "" {
+			return nil, fmt.Errorf("index document is required")
+		}
+		configuration.IndexDocument = &types.IndexDocument{Suffix: aws.String(website.IndexDocument)}
+		if website.ErrorDocument != "" {
+			configuration.ErrorDocument = &types.ErrorDocument{Key: aws.String(website.ErrorDocument)}
+		}
+		for _, rule := range website.RoutingRules {
+			configuration.RoutingRules = append(configuration.RoutingRules, toRoutingRule(rule))
+		}
+	}
+
+	_, err := c.s3Client.PutBucketWebsite(ctx, &s3.PutBucketWebsiteInput{
+		Bucket:               aws.String(bucketName),
+		WebsiteConfiguration: configuration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put bucket website configuration: %w", err)
+	}
+
+	result := *website
+	result.BucketName = bucketName
+	result.Enabled = true
+	result.WebsiteEndpoint = c.websiteEndpoint()
+	result.OperationTime = utils.FormatTime(time.Now())
+	return &result, nil
+}
+
+func (c *Client) DeleteBucketWebsite(ctx context.Context) (*models.BucketWebsite, error) {
+	if err := c.checkSupported(featureWebsite); err != nil {
+		return nil, err
+	}
+	bucketName := c.config.BucketName
+
+	_, err := c.s3Client.DeleteBucketWebsite(ctx, &s3.DeleteBucketWebsiteInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete bucket website configuration: %w", err)
+	}
+
+	return &models.BucketWebsite{
+		BucketName:    bucketName,
+		Enabled:       false,
+		OperationTime: utils.FormatTime(time.Now()),
+	}, nil
+}
+
+// websiteEndpoint returns the AWS website endpoint; custom endpoints have no well-known format.
+func (c *Client) websiteEndpoint() string {
+	if c.config.ApiURL != "" || c.config.Region == "" {
+		return ""
+	}
+	return fmt.Sprintf("http://%s.s3-website-%s.amazonaws.com", c.config.BucketName, c.config.Region)
+}
+
+func fromRoutingRule(rule types.RoutingRule) models.WebsiteRoutingRule {
+	result := models.WebsiteRoutingRule{}
+	if rule.Condition != nil {
+		result.Condition = &models.WebsiteCondition{
+			KeyPrefixEquals:             aws.ToString(rule.Condition.KeyPrefixEquals),
+			HttpErrorCodeReturnedEquals: aws.ToString(rule.Condition.HttpErrorCodeReturnedEquals),
+		}
+	}
+	if rule.Redirect != nil {
+		result.Redirect = models.WebsiteRedirect{
+			HostName:             aws.ToString(rule.Redirect.HostName),
+			Protocol:             string(rule.Redirect.Protocol),
+			HttpRedirectCode:     aws.ToString(rule.Redirect.HttpRedirectCode),
+			ReplaceKeyPrefixWith: aws.ToString(rule.Redirect.ReplaceKeyPrefixWith),
+			ReplaceKeyWith:       aws.ToString(rule.Redirect.ReplaceKeyWith),
+		}
+	}
+	return result
+}
+
+func toRoutingRule(rule models.WebsiteRoutingRule) types.RoutingRule {
+	result := types.RoutingRule{
+		Redirect: &types.Redirect{
+			HostName:             optionalString(rule.Redirect.HostName),
+			Protocol:             types.Protocol(rule.Redirect.Protocol),
+			HttpRedirectCode:     optionalString(rule.Redirect.HttpRedirectCode),
+			ReplaceKeyPrefixWith: optionalString(rule.Redirect.ReplaceKeyPrefixWith),
+			ReplaceKeyWith:       optionalString(rule.Redirect.ReplaceKeyWith),
+		},
+	}
+	if rule.Condition != nil {
+		result.Condition = &types.Condition{
+			KeyPrefixEquals:             optionalString(rule.Condition.KeyPrefixEquals),
+			HttpErrorCodeReturnedEquals: optionalString(rule.Condition.HttpErrorCodeReturnedEquals),
+		}
+	}
+	return result
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
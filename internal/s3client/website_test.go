@@ -0,0 +1,44 @@
+package s3client
+
+import (
+	"s3manager/config"
+	"s3manager/internal/models"
+	"testing"
+)
+
+func TestRoutingRuleConversion(t *testing.T) {
+	rule := models.WebsiteRoutingRule{
+		Condition: &models.WebsiteCondition{HttpErrorCodeReturnedEquals: "404"},
+		Redirect: models.WebsiteRedirect{
+			HostName:       "example.com",
+			Protocol:       "https",
+			ReplaceKeyWith: "not-found.html",
+		},
+	}
+
+	converted := fromRoutingRule(toRoutingRule(rule))
+
+	if converted.Condition == nil || converted.Condition.HttpErrorCodeReturnedEquals != "404" {
+		t.Errorf("Condition = %+v, want error code 404", converted.Condition)
+	}
+
+	if converted.Redirect != rule.Redirect {
+		t.Errorf("Redirect = %+v, want %+v", converted.Redirect, rule.Redirect)
+	}
+
+	if toRoutingRule(models.WebsiteRoutingRule{}).Condition != nil {
+		t.Errorf("rule without condition should not produce a Condition")
+	}
+}
+
+func TestWebsiteEndpoint(t *testing.T) {
+	c := &Client{config: &config.Config{BucketName: "site", Region: "eu-west-1"}}
+	if endpoint := c.websiteEndpoint(); endpoint != "http://site.s3-website-eu-west-1.amazonaws.com" {
+		t.Errorf("websiteEndpoint() = %s", endpoint)
+	}
+
+	c.config.ApiURL = "http://localhost:9000"
+	if endpoint := c.websiteEndpoint(); endpoint != "" {
+		t.Errorf("websiteEndpoint() with custom API URL = %s, want empty", endpoint)
+	}
+}
@@ -0,0 +1,873 @@
+// Package s3fake is an in-memory S3 server for tests. It implements the subset of the
+// S3 REST API the client uses, with path-style addressing: listing, object reads and
+// writes, multipart uploads with part copies, copies, tags, ACLs, batch deletes, SSE-C,
+// and bucket tags, logging and metrics configurations.
+//
+//	server := s3fake.New("test-bucket")
+//	defer server.Close()
+//	cfg := &config.Config{ApiURL: server.URL(), BucketName: "test-bucket", ...}
+package s3fake
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Object is a stored object.
+type Object struct {
+	Data               []byte
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	CacheControl       string
+	// StorageClass is empty for STANDARD
+	StorageClass string
+	Metadata     map[string]string
+	ETag         string
+	LastModified time.Time
+	Tags         map[string]string
+	// SSECustomerKeyMD5 is set for objects encrypted with a customer-provided key,
+	// which every read must present again
+	SSECustomerKeyMD5 string
+	// Owner is the canonical ID of the account owning the object, BucketOwner when empty
+	Owner string
+	// Grants are the ACL entries besides the full control of the owner
+	Grants []Grant
+}
+
+// BucketOwner is the canonical ID of the account owning every bucket of the server.
+const BucketOwner = "s3fake-owner"
+
+// Grant gives Permission to the account with canonical ID, or to the group URI.
+type Grant struct {
+	ID         string
+	URI        string
+	Permission string
+}
+
+type multipartUpload struct {
+	bucket, key string
+	contentType string
+	keyMD5      string
+	tags        map[string]string
+	// header holds the Cache-Control, storage class and user metadata of the object
+	header http.Header
+	parts  map[int][]byte
+}
+
+// Headers of the SSE-C key of a request and of the source of a copy
+const (
+	sseCustomerHeaders       = "X-Amz-Server-Side-Encryption-Customer-"
+	copySourceCustomerHeader = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-"
+)
+
+type Server struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	buckets map[string]map[string]*Object
+	// bucketConfigs holds the XML documents of bucket subresources like ?tagging by
+	// bucket and subresource, stored as sent since the responses have the same form
+	bucketConfigs map[string]map[string][]byte
+	uploads       map[string]*multipartUpload
+	nextID        int
+	// Now returns the LastModified time of written objects
+	Now func() time.Time
+	// MaxCopySize is the largest object CopyObject copies, 5GB like S3 unless a test
+	// lowers it
+	MaxCopySize int
+}
+
+// New starts a server with the given empty buckets.
+func New(buckets ...string) *Server {
+	s := &Server{
+		buckets:       make(map[string]map[string]*Object),
+		bucketConfigs: make(map[string]map[string][]byte),
+		uploads:       make(map[string]*multipartUpload),
+		Now:           time.Now,
+		MaxCopySize:   5 * 1024 * 1024 * 1024,
+	}
+	for _, bucket := range buckets {
+		s.buckets[bucket] = make(map[string]*Object)
+		s.bucketConfigs[bucket] = make(map[string][]byte)
+	}
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+// URL is the endpoint to use as API_URL.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// PutObject stores an object directly, e.g. to seed a bucket with old objects.
+func (s *Server) PutObject(bucket, key string, data []byte, lastModified time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[bucket][key] = &Object{Data: data, ETag: etag(data), LastModified: lastModified}
+}
+
+// SetETag replaces the ETag of a stored object, e.g. with the multipart ETag it would
+// have when uploaded in parts.
+func (s *Server) SetETag(bucket, key, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[bucket][key].ETag = etag
+}
+
+// SetTags replaces the tags of a stored object.
+func (s *Server) SetTags(bucket, key string, tags map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[bucket][key].Tags = tags
+}
+
+// SetACL sets the owner and the grants of a stored object, e.g. to simulate an upload
+// by another account.
+func (s *Server) SetACL(bucket, key, owner string, grants ...Grant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[bucket][key].Owner = owner
+	s.buckets[bucket][key].Grants = grants
+}
+
+// Object returns a copy of a stored object.
+func (s *Server) Object(bucket, key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.buckets[bucket][key]
+	if !ok {
+		return Object{}, false
+	}
+	return *obj, true
+}
+
+// Keys returns the sorted keys of a bucket.
+func (s *Server) Keys(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	for key := range s.buckets[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Uploads returns the number of multipart uploads that were neither completed nor aborted.
+func (s *Server) Uploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.uploads)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path == "" {
+		s.listBuckets(w)
+		return
+	}
+	bucketName, key, _ := strings.Cut(path, "/")
+	bucket, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	query := r.URL.Query()
+
+	if key == "" {
+		switch {
+		case r.Method == http.MethodHead:
+		case query.Has("tagging"):
+			s.bucketConfig(w, r, bucketName, "tagging", body, func(w http.ResponseWriter) {
+				writeError(w, http.StatusNotFound, "NoSuchTagSet", "The TagSet does not exist")
+			})
+		case query.Has("logging"):
+			// Buckets without logging have an empty status rather than none
+			s.bucketConfig(w, r, bucketName, "logging", body, func(w http.ResponseWriter) {
+				writeXML(w, struct {
+					XMLName xml.Name `xml:"BucketLoggingStatus"`
+				}{})
+			})
+		case r.Method == http.MethodGet && query.Has("metrics") && !query.Has("id"):
+			s.listMetricsConfigurations(w, bucketName)
+		case query.Has("metrics"):
+			s.bucketConfig(w, r, bucketName, "metrics/"+query.Get("id"), body, func(w http.ResponseWriter) {
+				writeError(w, http.StatusNotFound, "NoSuchConfiguration", "The specified configuration does not exist.")
+			})
+		case r.Method == http.MethodGet && query.Has("acl"):
+			writeACL(w, BucketOwner, nil)
+		case query.Has("ownershipControls"):
+			s.bucketConfig(w, r, bucketName, "ownershipControls", body, func(w http.ResponseWriter) {
+				writeError(w, http.StatusNotFound, "OwnershipControlsNotFoundError", "The bucket ownership controls were not found")
+			})
+		case r.Method == http.MethodGet && query.Has("location"):
+			writeXML(w, struct {
+				XMLName xml.Name `xml:"LocationConstraint"`
+			}{})
+		case r.Method == http.MethodGet:
+			s.listObjects(w, bucket, query)
+		case r.Method == http.MethodPost && query.Has("delete"):
+			s.deleteObjects(w, bucket, body)
+		default:
+			writeError(w, http.StatusNotImplemented, "NotImplemented", "bucket operation not implemented by s3fake")
+		}
+		return
+	}
+
+	keyMD5, err := customerKey(r.Header, sseCustomerHeaders)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.nextID++
+		id := strconv.Itoa(s.nextID)
+		s.uploads[id] = &multipartUpload{bucket: bucketName, key: key, contentType: r.Header.Get("Content-Type"), keyMD5: keyMD5, tags: tagging(r.Header), header: r.Header.Clone(), parts: make(map[int][]byte)}
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucketName, Key: key, UploadId: id})
+	case r.Method == http.MethodPut && query.Has("uploadId") && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copyPart(w, r, query.Get("uploadId"), keyMD5)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		upload, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+			return
+		}
+		if keyMD5 != upload.keyMD5 {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "The SSE-C key of the part does not match the upload")
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		upload.parts[number] = body
+		w.Header().Set("ETag", `"`+etag(body)+`"`)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.completeUpload(w, r, bucket, query.Get("uploadId"), body)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && query.Has("tagging"):
+		obj, ok := bucket[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		var tagging struct {
+			Tags []struct{ Key, Value string } `xml:"TagSet>Tag"`
+		}
+		xml.Unmarshal(body, &tagging)
+		obj.Tags = make(map[string]string)
+		for _, tag := range tagging.Tags {
+			obj.Tags[tag.Key] = tag.Value
+		}
+	case r.Method == http.MethodGet && query.Has("tagging"):
+		obj, ok := bucket[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		type tag struct{ Key, Value string }
+		result := struct {
+			XMLName xml.Name `xml:"Tagging"`
+			Tags    []tag    `xml:"TagSet>Tag"`
+		}{}
+		for k, v := range obj.Tags {
+			result.Tags = append(result.Tags, tag{k, v})
+		}
+		writeXML(w, result)
+	case r.Method == http.MethodGet && query.Has("acl"):
+		obj, ok := bucket[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		owner := obj.Owner
+		if owner == "" {
+			owner = BucketOwner
+		}
+		writeACL(w, owner, obj.Grants)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copyObject(w, r, bucket, key, keyMD5)
+	case r.Method == http.MethodPut:
+		obj := &Object{Data: body, ContentType: r.Header.Get("Content-Type"), ETag: etag(body), LastModified: s.Now(), Tags: tagging(r.Header), SSECustomerKeyMD5: keyMD5}
+		setHeaders(obj, r.Header)
+		bucket[key] = obj
+		w.Header().Set("ETag", `"`+obj.ETag+`"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := bucket[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		if keyMD5 != obj.SSECustomerKeyMD5 {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "The SSE-C key does not match the key the object was encrypted with")
+			return
+		}
+		serveObject(w, r, obj)
+	case r.Method == http.MethodDelete:
+		delete(bucket, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", "object operation not implemented by s3fake")
+	}
+}
+
+// bucketConfig stores, returns or deletes the subresource document of a bucket. Reads of
+// a missing document are answered by missing.
+func (s *Server) bucketConfig(w http.ResponseWriter, r *http.Request, bucket, subresource string, body []byte, missing func(w http.ResponseWriter)) {
+	configs := s.bucketConfigs[bucket]
+	switch r.Method {
+	case http.MethodPut:
+		configs[subresource] = body
+	case http.MethodDelete:
+		delete(configs, subresource)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		document, ok := configs[subresource]
+		if !ok {
+			missing(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(document)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", "bucket operation not implemented by s3fake")
+	}
+}
+
+// listMetricsConfigurations wraps the stored metrics configurations of bucket, in order
+// of their IDs, in a single unpaged list.
+func (s *Server) listMetricsConfigurations(w http.ResponseWriter, bucket string) {
+	var ids []string
+	for subresource := range s.bucketConfigs[bucket] {
+		if id, ok := strings.CutPrefix(subresource, "metrics/"); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var list bytes.Buffer
+	list.WriteString(xml.Header + `<ListMetricsConfigurationsResult><IsTruncated>false</IsTruncated>`)
+	for _, id := range ids {
+		list.Write(bytes.TrimPrefix(s.bucketConfigs[bucket]["metrics/"+id], []byte(xml.Header)))
+	}
+	list.WriteString(`</ListMetricsConfigurationsResult>`)
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(list.Bytes())
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) {
+	type bucket struct {
+		Name         string
+		CreationDate time.Time
+	}
+	result := struct {
+		XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+		Buckets []bucket `xml:"Buckets>Bucket"`
+	}{}
+	for name := range s.buckets {
+		result.Buckets = append(result.Buckets, bucket{Name: name, CreationDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)})
+	}
+	writeXML(w, result)
+}
+
+// listObjects implements ListObjectsV2 with prefix, delimiter, start-after, max-keys and
+// continuation tokens, which are simply the last key or common prefix of the previous
+// page.
+func (s *Server) listObjects(w http.ResponseWriter, bucket map[string]*Object, query url.Values) {
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	after := query.Get("start-after")
+	if token := query.Get("continuation-token"); token != "" {
+		after = token
+	}
+	maxKeys := 1000
+	if value, err := strconv.Atoi(query.Get("max-keys")); err == nil && value >= 0 {
+		maxKeys = min(value, 1000)
+	}
+
+	// Keys and common prefixes, which are returned in one sorted sequence
+	keys := make([]string, 0, len(bucket))
+	seen := make(map[string]bool)
+	for key := range bucket {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				key = key[:len(prefix)+i+len(delimiter)]
+			}
+		}
+		if key > after && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+		StorageClass string
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Prefix                string
+		KeyCount              int
+		MaxKeys               int
+		IsTruncated           bool
+		NextContinuationToken string         `xml:",omitempty"`
+		Contents              []content      `xml:"Contents"`
+		CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+	}{Prefix: prefix, MaxKeys: maxKeys}
+
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		result.IsTruncated = true
+		if maxKeys > 0 {
+			result.NextContinuationToken = keys[len(keys)-1]
+		}
+	}
+	for _, key := range keys {
+		if delimiter != "" && strings.Contains(key[len(prefix):], delimiter) {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: key})
+			continue
+		}
+		obj := bucket[key]
+		storageClass := obj.StorageClass
+		if storageClass == "" {
+			storageClass = "STANDARD"
+		}
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: obj.LastModified.UTC().Format(time.RFC3339),
+			ETag:         `"` + obj.ETag + `"`,
+			Size:         len(obj.Data),
+			StorageClass: storageClass,
+		})
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	writeXML(w, result)
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, bucket map[string]*Object, body []byte) {
+	var request struct {
+		Quiet   bool
+		Objects []struct{ Key string } `xml:"Object"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	type deleted struct{ Key string }
+	result := struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Deleted []deleted `xml:"Deleted"`
+	}{}
+	for _, obj := range request.Objects {
+		delete(bucket, obj.Key)
+		if !request.Quiet {
+			result.Deleted = append(result.Deleted, deleted{obj.Key})
+		}
+	}
+	writeXML(w, result)
+}
+
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, bucket map[string]*Object, key, keyMD5 string) {
+	source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	sourceBucket, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	obj, ok := s.buckets[sourceBucket][sourceKey]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	sourceKeyMD5, err := customerKey(r.Header, copySourceCustomerHeader)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	if sourceKeyMD5 != obj.SSECustomerKeyMD5 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "The SSE-C key of the copy source does not match the key it was encrypted with")
+		return
+	}
+	if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); match != "" && strings.Trim(match, `"`) != obj.ETag {
+		writeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+		return
+	}
+	if len(obj.Data) > s.MaxCopySize {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", fmt.Sprintf("The specified copy source is larger than the maximum allowable size for a copy source: %d", s.MaxCopySize))
+		return
+	}
+	replace := r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE"
+	if bucket[key] == obj && !replace && keyMD5 == obj.SSECustomerKeyMD5 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata, storage class, website redirect location or encryption attributes.")
+		return
+	}
+
+	copied := *obj
+	copied.Data = bytes.Clone(obj.Data)
+	copied.LastModified = s.Now()
+	if replace {
+		copied.ContentType = r.Header.Get("Content-Type")
+		setHeaders(&copied, r.Header)
+	}
+	// Like S3, a copy is STANDARD unless its storage class is given again
+	copied.StorageClass = r.Header.Get("X-Amz-Storage-Class")
+	if copied.StorageClass == "STANDARD" {
+		copied.StorageClass = ""
+	}
+	copied.SSECustomerKeyMD5 = keyMD5
+	bucket[key] = &copied
+	writeXML(w, struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		ETag         string
+		LastModified string
+	}{ETag: `"` + copied.ETag + `"`, LastModified: copied.LastModified.UTC().Format(time.RFC3339)})
+}
+
+// copyPart stores a part of a multipart upload copied from an object, or from the byte
+// range of it given by X-Amz-Copy-Source-Range.
+func (s *Server) copyPart(w http.ResponseWriter, r *http.Request, id, keyMD5 string) {
+	upload, ok := s.uploads[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+	if keyMD5 != upload.keyMD5 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "The SSE-C key of the part does not match the upload")
+		return
+	}
+	source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	sourceBucket, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	obj, ok := s.buckets[sourceBucket][sourceKey]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	sourceKeyMD5, err := customerKey(r.Header, copySourceCustomerHeader)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	if sourceKeyMD5 != obj.SSECustomerKeyMD5 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "The SSE-C key of the copy source does not match the key it was encrypted with")
+		return
+	}
+	if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); match != "" && strings.Trim(match, `"`) != obj.ETag {
+		writeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+		return
+	}
+
+	data := obj.Data
+	if spec, ok := strings.CutPrefix(r.Header.Get("X-Amz-Copy-Source-Range"), "bytes="); ok {
+		startText, endText, _ := strings.Cut(spec, "-")
+		start, err1 := strconv.Atoi(startText)
+		end, err2 := strconv.Atoi(endText)
+		if err1 != nil || err2 != nil || start > end || end >= len(data) {
+			writeError(w, http.StatusBadRequest, "InvalidArgument", "The x-amz-copy-source-range value must be of the form bytes=first-last within the object")
+			return
+		}
+		data = data[start : end+1]
+	}
+	number, _ := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	upload.parts[number] = bytes.Clone(data)
+	writeXML(w, struct {
+		XMLName      xml.Name `xml:"CopyPartResult"`
+		ETag         string
+		LastModified string
+	}{ETag: `"` + etag(data) + `"`, LastModified: s.Now().UTC().Format(time.RFC3339)})
+}
+
+// minPartSize is the smallest size S3 accepts for the parts of a multipart upload but the
+// last.
+const minPartSize = 5 * 1024 * 1024
+
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request, bucket map[string]*Object, id string, body []byte) {
+	upload, ok := s.uploads[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+	existing, exists := bucket[upload.key]
+	if match := r.Header.Get("If-Match"); match != "" && (!exists || strings.Trim(match, `"`) != existing.ETag) ||
+		r.Header.Get("If-None-Match") == "*" && exists {
+		writeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+		return
+	}
+	var request struct {
+		Parts []struct{ PartNumber int } `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	var data []byte
+	var sums []byte
+	for i, part := range request.Parts {
+		partData, ok := upload.parts[part.PartNumber]
+		if !ok {
+			writeError(w, http.StatusBadRequest, "InvalidPart", fmt.Sprintf("part %d was not uploaded", part.PartNumber))
+			return
+		}
+		if len(partData) < minPartSize && i < len(request.Parts)-1 {
+			writeError(w, http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.")
+			return
+		}
+		data = append(data, partData...)
+		sum := md5.Sum(partData)
+		sums = append(sums, sum[:]...)
+	}
+	delete(s.uploads, id)
+
+	// Multipart ETags are the MD5 of the part MD5s followed by the part count
+	sum := md5.Sum(sums)
+	obj := &Object{
+		Data:              data,
+		ContentType:       upload.contentType,
+		ETag:              fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(request.Parts)),
+		LastModified:      s.Now(),
+		Tags:              upload.tags,
+		SSECustomerKeyMD5: upload.keyMD5,
+	}
+	setHeaders(obj, upload.header)
+	bucket[upload.key] = obj
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: upload.bucket, Key: upload.key, ETag: `"` + obj.ETag + `"`})
+}
+
+// setHeaders stores the Cache-Control, storage class and user metadata of a write.
+func setHeaders(obj *Object, h http.Header) {
+	obj.ContentEncoding = h.Get("Content-Encoding")
+	obj.ContentDisposition = h.Get("Content-Disposition")
+	obj.CacheControl = h.Get("Cache-Control")
+	obj.StorageClass = h.Get("X-Amz-Storage-Class")
+	if obj.StorageClass == "STANDARD" {
+		obj.StorageClass = ""
+	}
+	obj.Metadata = nil
+	for name := range h {
+		if key, ok := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-"); ok {
+			if obj.Metadata == nil {
+				obj.Metadata = make(map[string]string)
+			}
+			obj.Metadata[key] = h.Get(name)
+		}
+	}
+}
+
+// tagging parses the tags set with the X-Amz-Tagging header of a write, nil without.
+func tagging(h http.Header) map[string]string {
+	values, err := url.ParseQuery(h.Get("X-Amz-Tagging"))
+	if err != nil || len(values) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(values))
+	for key := range values {
+		tags[key] = values.Get(key)
+	}
+	return tags
+}
+
+// customerKey validates the SSE-C headers with the given prefix like S3 does and returns
+// the key MD5, or "" when the request has no key.
+func customerKey(h http.Header, prefix string) (string, error) {
+	algorithm, key, keyMD5 := h.Get(prefix+"Algorithm"), h.Get(prefix+"Key"), h.Get(prefix+"Key-Md5")
+	if algorithm == "" && key == "" && keyMD5 == "" {
+		return "", nil
+	}
+	if algorithm != "AES256" {
+		return "", fmt.Errorf("unsupported SSE-C algorithm %q", algorithm)
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 32 {
+		return "", fmt.Errorf("the SSE-C key must be 256 bits, base64 encoded")
+	}
+	sum := md5.Sum(raw)
+	if base64.StdEncoding.EncodeToString(sum[:]) != keyMD5 {
+		return "", fmt.Errorf("the SSE-C key MD5 does not match the key")
+	}
+	return keyMD5, nil
+}
+
+// serveObject writes obj, or the byte range requested by the ranged GETs of the
+// SDK's downloader.
+func serveObject(w http.ResponseWriter, r *http.Request, obj *Object) {
+	data := obj.Data
+	status := http.StatusOK
+	if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+		startText, endText, _ := strings.Cut(spec, "-")
+		start, _ := strconv.Atoi(startText)
+		end, err := strconv.Atoi(endText)
+		if err != nil || end >= len(data) {
+			end = len(data) - 1
+		}
+		if start >= len(data) && len(data) > 0 {
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable")
+			return
+		}
+		if len(data) > 0 {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			data = data[start : end+1]
+		}
+		status = http.StatusPartialContent
+	}
+
+	contentType := obj.ContentType
+	if contentType == "" {
+		contentType = "binary/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	for name, value := range map[string]string{
+		"Content-Encoding":    obj.ContentEncoding,
+		"Content-Disposition": obj.ContentDisposition,
+		"Cache-Control":       obj.CacheControl,
+	} {
+		if value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+	if obj.StorageClass != "" {
+		w.Header().Set("X-Amz-Storage-Class", obj.StorageClass)
+	}
+	for key, value := range obj.Metadata {
+		w.Header().Set("X-Amz-Meta-"+key, value)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("ETag", `"`+obj.ETag+`"`)
+	w.Header().Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// readBody returns the request payload, decoding the aws-chunked encoding of streaming
+// uploads.
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return body, nil
+	}
+
+	var data []byte
+	reader := bufio.NewReader(bytes.NewReader(body))
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("invalid aws-chunked body: %w", err)
+		}
+		sizeText, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeText, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aws-chunked chunk size %q", sizeText)
+		}
+		if size == 0 {
+			// Trailing checksums follow, they are not verified
+			return data, nil
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, fmt.Errorf("invalid aws-chunked body: %w", err)
+		}
+		data = append(data, chunk...)
+		reader.ReadString('\n')
+	}
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(v)
+}
+
+// writeACL writes an access control policy giving owner full control and grants. It is
+// formatted by hand since encoding/xml cannot write the xsi:type of the grantees.
+func writeACL(w http.ResponseWriter, owner string, grants []Grant) {
+	var policy strings.Builder
+	policy.WriteString(xml.Header + `<AccessControlPolicy><Owner><ID>`)
+	xml.EscapeText(&policy, []byte(owner))
+	policy.WriteString(`</ID></Owner><AccessControlList>`)
+	for _, grant := range append([]Grant{{ID: owner, Permission: "FULL_CONTROL"}}, grants...) {
+		policy.WriteString(`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" `)
+		if grant.URI != "" {
+			policy.WriteString(`xsi:type="Group"><URI>`)
+			xml.EscapeText(&policy, []byte(grant.URI))
+			policy.WriteString(`</URI>`)
+		} else {
+			policy.WriteString(`xsi:type="CanonicalUser"><ID>`)
+			xml.EscapeText(&policy, []byte(grant.ID))
+			policy.WriteString(`</ID>`)
+		}
+		policy.WriteString(`</Grantee><Permission>` + grant.Permission + `</Permission></Grant>`)
+	}
+	policy.WriteString(`</AccessControlList></AccessControlPolicy>`)
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(policy.String()))
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: message})
+}
@@ -0,0 +1,171 @@
+// Package schedule renders the systemd units and crontab lines that run an s3manager job
+// on a schedule, for hosts without a scheduler of their own.
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Job is a command line of s3manager run on a schedule.
+type Job struct {
+	// Name names the units, s3manager-<name>.service and .timer
+	Name string
+	// Command is the command line, starting with the absolute path of the binary
+	Command []string
+	// Schedule is a systemd calendar event like daily or *-*-* 03:00:00 for systemd, a
+	// crontab schedule like 0 3 * * * for cron; hourly, daily, weekly and monthly work
+	// for both
+	Schedule string
+	// WorkingDir is where the job runs and reads .env from
+	WorkingDir string
+	// EnvFile is an environment file loaded in addition to .env, optional
+	EnvFile string
+	// User runs the systemd service, optional
+	User string
+	// RandomizedDelay spreads the start of the timer, like 15m, optional
+	RandomizedDelay string
+	// LogFile receives the output of the cron job, optional
+	LogFile string
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Validate checks that the job can be rendered.
+func (j Job) Validate() error {
+	if !namePattern.MatchString(j.Name) {
+		return fmt.Errorf("invalid job name %q, use letters, digits, ., _ and -", j.Name)
+	}
+	if len(j.Command) == 0 {
+		return fmt.Errorf("job %s has no command", j.Name)
+	}
+	if strings.TrimSpace(j.Schedule) == "" {
+		return fmt.Errorf("job %s has no schedule", j.Name)
+	}
+	return nil
+}
+
+// UnitName returns the name of the systemd units of the job without their suffix.
+func (j Job) UnitName() string {
+	return "s3manager-" + j.Name
+}
+
+// SystemdService returns the oneshot service that runs the job once.
+func SystemdService(j Job) (string, error) {
+	if err := j.Validate(); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=s3manager job %s\n", j.Name)
+	fmt.Fprintf(&b, "Wants=network-online.target\n")
+	fmt.Fprintf(&b, "After=network-online.target\n")
+	fmt.Fprintf(&b, "\n[Service]\n")
+	fmt.Fprintf(&b, "Type=oneshot\n")
+	if j.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", j.User)
+	}
+	if j.WorkingDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdEscape(j.WorkingDir))
+	}
+	if j.EnvFile != "" {
+		fmt.Fprintf(&b, "EnvironmentFile=%s\n", systemdEscape(j.EnvFile))
+	}
+	words := make([]string, len(j.Command))
+	for i, word := range j.Command {
+		words[i] = systemdQuote(word)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(words, " "))
+	// SIGINT lets the job stop cleanly, like Ctrl-C: uploads are aborted and temporary
+	// files removed
+	fmt.Fprintf(&b, "KillSignal=SIGINT\n")
+	fmt.Fprintf(&b, "SuccessExitStatus=130\n")
+	return b.String(), nil
+}
+
+// SystemdTimer returns the timer that starts the service of the job on its schedule.
+// Runs missed while the machine was off are caught up on the next boot.
+func SystemdTimer(j Job) (string, error) {
+	if err := j.Validate(); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=Schedule of s3manager job %s\n", j.Name)
+	fmt.Fprintf(&b, "\n[Timer]\n")
+	fmt.Fprintf(&b, "OnCalendar=%s\n", strings.TrimSpace(j.Schedule))
+	fmt.Fprintf(&b, "Persistent=true\n")
+	if j.RandomizedDelay != "" {
+		fmt.Fprintf(&b, "RandomizedDelaySec=%s\n", j.RandomizedDelay)
+	}
+	fmt.Fprintf(&b, "\n[Install]\n")
+	fmt.Fprintf(&b, "WantedBy=timers.target\n")
+	return b.String(), nil
+}
+
+// cronShortcuts are the schedules that are spelt the same for systemd and cron.
+var cronShortcuts = map[string]string{
+	"hourly":  "@hourly",
+	"daily":   "@daily",
+	"weekly":  "@weekly",
+	"monthly": "@monthly",
+	"yearly":  "@yearly",
+}
+
+// CrontabLine returns the crontab line that runs the job on its schedule, in its working
+// directory and with its output appended to its log file.
+func CrontabLine(j Job) (string, error) {
+	if err := j.Validate(); err != nil {
+		return "", err
+	}
+	schedule := strings.TrimSpace(j.Schedule)
+	if shortcut, ok := cronShortcuts[strings.ToLower(schedule)]; ok {
+		schedule = shortcut
+	} else if !strings.HasPrefix(schedule, "@") && len(strings.Fields(schedule)) != 5 {
+		return "", fmt.Errorf("invalid crontab schedule %q, expected five fields like 0 3 * * * or daily", schedule)
+	}
+
+	var command []string
+	if j.WorkingDir != "" {
+		command = append(command, "cd "+shellQuote(j.WorkingDir)+" &&")
+	}
+	if j.EnvFile != "" {
+		command = append(command, "set -a && . "+shellQuote(j.EnvFile)+" && set +a &&")
+	}
+	for _, word := range j.Command {
+		command = append(command, shellQuote(word))
+	}
+	if j.LogFile != "" {
+		command = append(command, ">>", shellQuote(j.LogFile), "2>&1")
+	}
+	// cron turns unescaped % into newlines
+	line := strings.ReplaceAll(strings.Join(command, " "), "%", `\%`)
+	return schedule + " " + line, nil
+}
+
+// systemdEscape escapes the specifiers systemd expands in settings like
+// WorkingDirectory.
+func systemdEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// systemdQuote quotes word for the command line of a systemd unit when it needs it, and
+// escapes the specifiers and variables systemd would expand.
+func systemdQuote(word string) string {
+	word = strings.ReplaceAll(systemdEscape(word), "$", "$$")
+	if word != "" && !strings.ContainsAny(word, " \t\"'\\;") {
+		return word
+	}
+	word = strings.ReplaceAll(word, `\`, `\\`)
+	word = strings.ReplaceAll(word, `"`, `\"`)
+	return `"` + word + `"`
+}
+
+// shellQuote quotes word for a POSIX shell when it needs it.
+func shellQuote(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
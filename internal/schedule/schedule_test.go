@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemdService(t *testing.T) {
+	job := Job{
+		Name:       "nightly-backup",
+		Command:    []string{"/usr/local/bin/s3manager", "upload", "/var/backups/db dumps", "--destination", "db/{yyyy}/%m", "--confirm"},
+		Schedule:   "daily",
+		WorkingDir: "/etc/s3manager",
+		User:       "backup",
+	}
+	service, err := SystemdService(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Type=oneshot\n",
+		"User=backup\n",
+		"WorkingDirectory=/etc/s3manager\n",
+		`ExecStart=/usr/local/bin/s3manager upload "/var/backups/db dumps" --destination db/{yyyy}/%%m --confirm` + "\n",
+		"KillSignal=SIGINT\n",
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("service lacks %q:\n%s", want, service)
+		}
+	}
+
+	job.RandomizedDelay = "15m"
+	timer, err := SystemdTimer(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"OnCalendar=daily\n", "Persistent=true\n", "RandomizedDelaySec=15m\n", "WantedBy=timers.target\n"} {
+		if !strings.Contains(timer, want) {
+			t.Errorf("timer lacks %q:\n%s", want, timer)
+		}
+	}
+}
+
+func TestCrontabLine(t *testing.T) {
+	tests := []struct {
+		job  Job
+		want string
+	}{
+		{
+			Job{Name: "backup", Command: []string{"/usr/bin/s3manager", "backup"}, Schedule: "daily", WorkingDir: "/srv/jobs"},
+			"@daily cd /srv/jobs && /usr/bin/s3manager backup",
+		},
+		{
+			Job{Name: "cleanup", Command: []string{"/usr/bin/s3manager", "delete-old", "--folder", "my logs", "--filter", "age>30d"}, Schedule: "0 3 * * *", LogFile: "/var/log/s3manager.log"},
+			"0 3 * * * /usr/bin/s3manager delete-old --folder 'my logs' --filter 'age>30d' >> /var/log/s3manager.log 2>&1",
+		},
+		{
+			Job{Name: "upload", Command: []string{"/usr/bin/s3manager", "upload", "--destination", "db/%Y"}, Schedule: "@reboot", EnvFile: "/etc/s3manager.env"},
+			`@reboot set -a && . /etc/s3manager.env && set +a && /usr/bin/s3manager upload --destination db/\%Y`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := CrontabLine(tt.job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("CrontabLine(%s) =\n%s\nwant\n%s", tt.job.Name, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidJobs(t *testing.T) {
+	command := []string{"/usr/bin/s3manager", "backup"}
+	for _, job := range []Job{
+		{Name: "../evil", Command: command, Schedule: "daily"},
+		{Name: "backup", Schedule: "daily"},
+		{Name: "backup", Command: command},
+	} {
+		if _, err := SystemdService(job); err == nil {
+			t.Errorf("SystemdService(%+v) succeeded", job)
+		}
+	}
+	if _, err := CrontabLine(Job{Name: "backup", Command: command, Schedule: "*-*-* 03:00:00"}); err == nil {
+		t.Error("CrontabLine accepted a systemd calendar event")
+	}
+}
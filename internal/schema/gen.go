@@ -0,0 +1,24 @@
+//go:build ignore
+
+// gen writes the schemas of all types to the schemas directory, see go generate.
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"s3manager/internal/schema"
+)
+
+func main() {
+	for _, name := range schema.Names() {
+		data, err := schema.Generate(name)
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join("schemas", schema.FileName(name)), data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"s3manager/internal/models"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// generate returns the schema of the struct type t, with the structs it refers to in
+// $defs. Fields without omitempty are required, and those encoding/json writes as null
+// when unset, like nil slices and pointers, may be null. Objects may have properties the
+// schema does not list, so that new fields do not break validation of older schemas. The
+// results carry the schema_version of models.OutputVersion, which the schema requires.
+func generate(name string, t reflect.Type) ([]byte, error) {
+	defs := map[string]any{}
+	root, err := objectSchema(t, defs)
+	if err != nil {
+		return nil, err
+	}
+	root["properties"].(map[string]any)["schema_version"] = map[string]any{"type": "integer", "const": models.OutputVersion}
+	required, _ := root["required"].([]string)
+	root["required"] = append([]string{"schema_version"}, required...)
+	root["$schema"] = draft
+	root["title"] = name
+	if len(defs) > 0 {
+		root["$defs"] = defs
+	}
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// objectSchema returns the schema of the struct type t.
+func objectSchema(t reflect.Type, defs map[string]any) (map[string]any, error) {
+	properties := map[string]any{}
+	required := []string{}
+	if err := addFields(t, properties, &required, defs); err != nil {
+		return nil, err
+	}
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// addFields adds the fields of the struct type t to properties, and the names of those
+// without omitempty to required. The fields of embedded structs are added like encoding/json
+// writes them, as fields of t.
+func addFields(t reflect.Type, properties map[string]any, required *[]string, defs map[string]any) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			if err := addFields(field.Type, properties, required, defs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		schema, err := typeSchema(field.Type, defs)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		if !omitEmpty {
+			if nilable(field.Type) {
+				schema = nullable(schema)
+			}
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+	return nil
+}
+
+// jsonName returns the name encoding/json gives field, whether it has omitempty, and
+// whether encoding/json skips it.
+func jsonName(field reflect.StructField) (string, bool, bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false, true
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	omitEmpty := false
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// typeSchema returns the schema of values of type t. Structs other than time.Time are
+// added to defs and referred to.
+func typeSchema(t reflect.Type, defs map[string]any) (map[string]any, error) {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), defs)
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]any{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem(), defs)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys of type %s are not supported", t.Key())
+		}
+		values, err := typeSchema(t.Elem(), defs)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := defs[name]; !ok {
+			// reserve the name first, so that recursive types end
+			defs[name] = nil
+			def, err := objectSchema(t, defs)
+			if err != nil {
+				return nil, err
+			}
+			defs[name] = def
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}, nil
+	}
+	return nil, fmt.Errorf("type %s is not supported", t)
+}
+
+// nilable reports whether encoding/json writes null for the zero value of t.
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// nullable returns schema extended to allow null.
+func nullable(schema map[string]any) map[string]any {
+	switch kind := schema["type"].(type) {
+	case string:
+		schema["type"] = []string{kind, "null"}
+		return schema
+	case nil:
+		if len(schema) == 0 {
+			return schema
+		}
+	}
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
@@ -0,0 +1,122 @@
+// Package schema holds the JSON Schemas of the results the commands print, so that other
+// systems can validate the output or generate code for it. The schemas are generated from
+// the models by reflection and embedded; after changing a model, regenerate them with
+//
+//	go generate ./internal/schema
+package schema
+
+//go:generate go run gen.go
+
+import (
+	"embed"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"s3manager/internal/models"
+)
+
+//go:embed schemas/*.schema.json
+var files embed.FS
+
+// types are the results printed by the commands, by name.
+var types = map[string]any{
+	"AccessReport":         models.AccessReport{},
+	"AppendResult":         models.AppendResult{},
+	"ArchiveExtractResult": models.ArchiveExtractResult{},
+	"ArchiveInfo":          models.ArchiveInfo{},
+	"ArchiveListResult":    models.ArchiveListResult{},
+	"BenchResult":          models.BenchResult{},
+	"BucketInfo":           models.BucketInfo{},
+	"BucketLogging":        models.BucketLogging{},
+	"BucketMetrics":        models.BucketMetrics{},
+	"BucketTags":           models.BucketTags{},
+	"BucketWebsite":        models.BucketWebsite{},
+	"ChecksumResult":       models.ChecksumResult{},
+	"CompareResult":        models.CompareResult{},
+	"ConcatResult":         models.ConcatResult{},
+	"CopyResult":           models.CopyResult{},
+	"DeleteResult":         models.DeleteResult{},
+	"DeletionPlan":         models.DeletionPlan{},
+	"DeployResult":         models.DeployResult{},
+	"DoctorResult":         models.DoctorResult{},
+	"DownloadResult":       models.DownloadResult{},
+	"DumpResult":           models.DumpResult{},
+	"DuplicatesReport":     models.DuplicatesReport{},
+	"ErrorResponse":        models.ErrorResponse{},
+	"EventResult":          models.EventResult{},
+	"ExistsResult":         models.ExistsResult{},
+	"ExpireResult":         models.ExpireResult{},
+	"FetchResult":          models.FetchResult{},
+	"FreshnessResult":      models.FreshnessResult{},
+	"GrepMatch":            models.GrepMatch{},
+	"GrepSummary":          models.GrepSummary{},
+	"IngestResult":         models.IngestResult{},
+	"InventoryDiff":        models.InventoryDiff{},
+	"InventoryResult":      models.InventoryResult{},
+	"LatestResult":         models.LatestResult{},
+	"ListItem":             models.ListItem{},
+	"MigrateResult":        models.MigrateResult{},
+	"PingResult":           models.PingResult{},
+	"PruneResult":          models.PruneResult{},
+	"RetentionReport":      models.RetentionReport{},
+	"RewriteResult":        models.RewriteResult{},
+	"SelfUpdateResult":     models.SelfUpdateResult{},
+	"TopReport":            models.TopReport{},
+	"TouchResult":          models.TouchResult{},
+	"TransferPlan":         models.TransferPlan{},
+	"TrashResult":          models.TrashResult{},
+	"UploadResult":         models.UploadResult{},
+	"VersionResult":        models.VersionResult{},
+	"WaitResult":           models.WaitResult{},
+}
+
+// Names returns the names of the types with a schema, sorted.
+func Names() []string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the name of the type called name in any case, e.g. UploadResult for
+// uploadresult, or false when there is no such type.
+func Lookup(name string) (string, bool) {
+	for known := range types {
+		if strings.EqualFold(known, name) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the embedded schema of the type called name, in any case.
+func Get(name string) ([]byte, error) {
+	known, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	return files.ReadFile(fileName(known))
+}
+
+// Generate returns the schema of the type called name from the model itself, which is what
+// Get returns as long as the embedded schemas are up to date.
+func Generate(name string) ([]byte, error) {
+	v, ok := types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", name)
+	}
+	return generate(name, reflect.TypeOf(v))
+}
+
+// FileName returns the name of the schema file of the type called name.
+func FileName(name string) string {
+	return name + ".schema.json"
+}
+
+func fileName(name string) string {
+	return "schemas/" + FileName(name)
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestEmbeddedSchemasUpToDate(t *testing.T) {
+	for _, name := range Names() {
+		generated, err := Generate(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		embedded, err := Get(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(generated, embedded) {
+			t.Errorf("schema of %s is out of date, run go generate ./internal/schema", name)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	data, err := Get("downloadresult")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Schema     string                    `json:"$schema"`
+		Title      string                    `json:"title"`
+		Required   []string                  `json:"required"`
+		Properties map[string]map[string]any `json:"properties"`
+		Defs       map[string]any            `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if schema.Schema != draft || schema.Title != "DownloadResult" {
+		t.Errorf("got $schema %q and title %q", schema.Schema, schema.Title)
+	}
+	for _, name := range []string{"schema_version", "bucket_name", "items", "total_size_bytes"} {
+		if !slices.Contains(schema.Required, name) {
+			t.Errorf("%s is not required: %v", name, schema.Required)
+		}
+	}
+	if slices.Contains(schema.Required, "throttling") {
+		t.Errorf("omitempty field throttling is required")
+	}
+
+	if got := schema.Properties["total_size_bytes"]["type"]; got != "integer" {
+		t.Errorf("total_size_bytes has type %v", got)
+	}
+	items := schema.Properties["items"]
+	if got := items["type"]; !reflect.DeepEqual(got, []any{"array", "null"}) {
+		t.Errorf("items has type %v, want a nullable array", got)
+	}
+	if got := items["items"]; !reflect.DeepEqual(got, map[string]any{"$ref": "#/$defs/DownloadItem"}) {
+		t.Errorf("items refer to %v", got)
+	}
+	if got := schema.Properties["throttling"]; !reflect.DeepEqual(got, map[string]any{"$ref": "#/$defs/Throttling"}) {
+		t.Errorf("throttling is %v", got)
+	}
+	if _, ok := schema.Defs["DownloadItem"]; !ok {
+		t.Errorf("DownloadItem is missing from $defs")
+	}
+}
+
+func TestGetUnknown(t *testing.T) {
+	if _, err := Get("NoSuchResult"); err == nil {
+		t.Error("expected an error")
+	}
+}
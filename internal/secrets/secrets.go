@@ -0,0 +1,47 @@
+// Package secrets fetches the configuration variables that hold credentials, like
+// ACCESS_KEY and SECRET_KEY, from a secret store at startup, so that they need not be
+// kept on disk in plain text: HashiCorp Vault, or a file encrypted with SOPS.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	ProviderVault = "vault"
+	ProviderSOPS  = "sops"
+)
+
+// Provider is a secret store.
+type Provider interface {
+	// Name names the store in errors, like vault
+	Name() string
+	// Load returns the variables held by the store by their upper-case names, like
+	// ACCESS_KEY.
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// normalize returns values with upper-case names, so that a secret may hold access_key as
+// well as ACCESS_KEY.
+func normalize(values map[string]string) map[string]string {
+	normalized := make(map[string]string, len(values))
+	for name, value := range values {
+		normalized[strings.ToUpper(strings.TrimSpace(name))] = value
+	}
+	return normalized
+}
+
+// stringValue returns a scalar value of a secret as the text of a variable.
+func stringValue(name string, value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("field %s of the secret is not a string", name)
+}
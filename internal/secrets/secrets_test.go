@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newVaultServer serves the KV version 2 secret secret/data/s3manager to the token
+// s.root, and logs the Kubernetes role s3manager in with the service account token jwt.
+func newVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["role"] != "s3manager" || body["jwt"] != "jwt" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		w.Write([]byte(`{"auth":{"client_token":"s.root"}}`))
+	})
+	mux.HandleFunc("GET /v1/secret/data/s3manager", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.root" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"access_key":"AKIA","SECRET_KEY":"secret","SMTP_PORT":465},"metadata":{"version":3}}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVault(t *testing.T) {
+	server := newVaultServer(t)
+	jwt := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(jwt, []byte("jwt\n"), 0600)
+
+	for name, vault := range map[string]*Vault{
+		"token":      {Addr: server.URL, Path: "secret/data/s3manager", Token: "s.root"},
+		"kubernetes": {Addr: server.URL, Path: "/secret/data/s3manager", Role: "s3manager", JWTFile: jwt},
+	} {
+		values, err := vault.Load(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if values["ACCESS_KEY"] != "AKIA" || values["SECRET_KEY"] != "secret" || values["SMTP_PORT"] != "465" {
+			t.Errorf("%s: got %v", name, values)
+		}
+	}
+
+	vault := &Vault{Addr: server.URL, Path: "secret/data/s3manager", Token: "wrong"}
+	if _, err := vault.Load(context.Background()); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+	vault = &Vault{Addr: server.URL, Path: "secret/data/s3manager"}
+	if _, err := vault.Load(context.Background()); err == nil {
+		t.Error("expected an error without credentials")
+	}
+}
+
+func TestSOPS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake sops is a shell script")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "sops")
+	script := "#!/bin/sh\n" +
+		"[ \"$1 $2 $3\" = \"--decrypt --output-type dotenv\" ] || { echo 'bad arguments' >&2; exit 1; }\n" +
+		"[ -f \"$4\" ] || { echo 'Failed to read file' >&2; exit 1; }\n" +
+		"printf 'ACCESS_KEY=AKIA\\nsecret_key=se=cret\\n'\n"
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "secrets.enc.env")
+	os.WriteFile(file, []byte("encrypted"), 0600)
+
+	values, err := (&SOPS{File: file, Binary: binary}).Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["ACCESS_KEY"] != "AKIA" || values["SECRET_KEY"] != "se=cret" {
+		t.Errorf("got %v", values)
+	}
+
+	_, err = (&SOPS{File: filepath.Join(dir, "missing"), Binary: binary}).Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Failed to read file") {
+		t.Errorf("expected the error of sops, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// SOPS decrypts a file encrypted with SOPS, a .env, YAML or JSON file of variables like
+// ACCESS_KEY. The sops program does the decryption, so the keys stay with the KMS, age or
+// GnuPG setup it already uses.
+type SOPS struct {
+	File string
+	// Binary is the sops program, sops from the PATH when it is empty
+	Binary string
+}
+
+func (s *SOPS) Name() string {
+	return ProviderSOPS
+}
+
+// Load decrypts File and returns its variables. Nested values are not supported.
+func (s *SOPS) Load(ctx context.Context) (map[string]string, error) {
+	if s.File == "" {
+		return nil, fmt.Errorf("sops: no file, set SOPS_FILE")
+	}
+	binary := s.Binary
+	if binary == "" {
+		binary = "sops"
+	}
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, binary, "--decrypt", "--output-type", "dotenv", s.File)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if message := lines[len(lines)-1]; message != "" {
+			return nil, fmt.Errorf("sops: failed to decrypt %s: %s", s.File, message)
+		}
+		return nil, fmt.Errorf("sops: failed to decrypt %s: %w", s.File, err)
+	}
+	values, err := godotenv.Unmarshal(stdout.String())
+	if err != nil {
+		return nil, fmt.Errorf("sops: failed to parse %s: %w", s.File, err)
+	}
+	return normalize(values), nil
+}
@@ -0,0 +1,185 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultJWTFile is the service account token Kubernetes mounts into every pod.
+	DefaultJWTFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	vaultTimeout = 30 * time.Second
+	// maxVaultResponse bounds the responses read from Vault
+	maxVaultResponse = 1 << 20
+)
+
+// Vault reads a secret of HashiCorp Vault. It logs in with Token if it is set, else with
+// the AppRole RoleID and SecretID, else with the Kubernetes auth role Role and the service
+// account token of the pod.
+type Vault struct {
+	// Addr is the address of the server, like https://vault.example.com:8200
+	Addr string
+	// Namespace is the Vault Enterprise namespace, optional
+	Namespace string
+	// Path is the API path of the secret, like secret/data/s3manager for the KV version 2
+	// engine mounted at secret/, or secret/s3manager for version 1
+	Path string
+
+	Token string
+
+	RoleID   string
+	SecretID string
+
+	Role string
+	// JWTFile is the service account token, DefaultJWTFile when it is empty
+	JWTFile string
+
+	// AuthMount is where the auth method is mounted, kubernetes or approle when it is
+	// empty
+	AuthMount string
+
+	Client *http.Client
+}
+
+func (v *Vault) Name() string {
+	return ProviderVault
+}
+
+// Load logs in and returns the fields of the secret at Path.
+func (v *Vault) Load(ctx context.Context) (map[string]string, error) {
+	if v.Addr == "" {
+		return nil, fmt.Errorf("vault: no address, set VAULT_ADDR")
+	}
+	if v.Path == "" {
+		return nil, fmt.Errorf("vault: no secret path, set VAULT_SECRET_PATH")
+	}
+	token, err := v.login(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("vault: failed to log in: %w", err)
+	}
+
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodGet, strings.TrimLeft(v.Path, "/"), token, nil, &response); err != nil {
+		return nil, fmt.Errorf("vault: failed to read %s: %w", v.Path, err)
+	}
+	data := response.Data
+	// the KV version 2 engine wraps the fields in data next to their metadata
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("vault: secret %s is empty", v.Path)
+	}
+	values := make(map[string]string, len(data))
+	for name, value := range data {
+		s, err := stringValue(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("vault: %w", err)
+		}
+		values[name] = s
+	}
+	return normalize(values), nil
+}
+
+// login returns the token to read the secret with.
+func (v *Vault) login(ctx context.Context) (string, error) {
+	var method string
+	var body map[string]string
+	switch {
+	case v.Token != "":
+		return v.Token, nil
+	case v.RoleID != "":
+		method = "approle"
+		body = map[string]string{"role_id": v.RoleID, "secret_id": v.SecretID}
+	case v.Role != "":
+		method = "kubernetes"
+		jwtFile := v.JWTFile
+		if jwtFile == "" {
+			jwtFile = DefaultJWTFile
+		}
+		jwt, err := os.ReadFile(jwtFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the service account token: %w", err)
+		}
+		body = map[string]string{"role": v.Role, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		return "", fmt.Errorf("no credentials, set VAULT_TOKEN, VAULT_ROLE_ID or VAULT_ROLE")
+	}
+
+	mount := v.AuthMount
+	if mount == "" {
+		mount = method
+	}
+	var response struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := v.do(ctx, http.MethodPost, "auth/"+strings.Trim(mount, "/")+"/login", "", body, &response); err != nil {
+		return "", err
+	}
+	if response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("no token in the response of the %s login", method)
+	}
+	return response.Auth.ClientToken, nil
+}
+
+// do sends a request to the API path /v1/<path> and decodes its JSON response into out.
+func (v *Vault) do(ctx context.Context, method, path, token string, body, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, vaultTimeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(v.Addr, "/")+"/v1/"+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxVaultResponse))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &failure) == nil && len(failure.Errors) > 0 {
+			return fmt.Errorf("%s: %s", resp.Status, strings.Join(failure.Errors, "; "))
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return json.Unmarshal(data, out)
+}
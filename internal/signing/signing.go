@@ -0,0 +1,209 @@
+// Package signing creates and checks detached signatures of backup files with GnuPG or
+// minisign. Both tools are run as external programs, so keys stay in the keyring or key
+// files they already manage and passphrases are asked for by their agents.
+package signing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"s3manager/config"
+)
+
+const (
+	MethodGPG      = "gpg"
+	MethodMinisign = "minisign"
+
+	// Suffix is appended to the key of an object to name its signature
+	Suffix = ".sig"
+
+	// MaxSize bounds the signatures that are downloaded for verification
+	MaxSize = 64 * 1024
+)
+
+// Signer creates detached signatures.
+type Signer struct {
+	method string
+	// key is the GnuPG key ID or the minisign secret key file
+	key string
+}
+
+// Verifier checks detached signatures.
+type Verifier struct {
+	method string
+	// key is the GnuPG keyring or the minisign public key file, empty when GnuPG checks
+	// against fingerprint in the default keyring
+	key string
+	// fingerprint is the GnuPG key signatures must be made by, empty when every key of
+	// the keyring is trusted
+	fingerprint string
+}
+
+// NewSigner returns a signer for SIGNATURE_METHOD and SIGNING_KEY.
+func NewSigner(cfg *config.Config) (*Signer, error) {
+	method, err := methodOf(cfg.SignatureMethod)
+	if err != nil {
+		return nil, err
+	}
+	if method == MethodMinisign && cfg.SigningKey == "" {
+		return nil, fmt.Errorf("SIGNING_KEY must name the minisign secret key file")
+	}
+	return &Signer{method: method, key: cfg.SigningKey}, nil
+}
+
+// NewVerifier returns a verifier for SIGNATURE_METHOD and SIGNATURE_PUBLIC_KEY.
+func NewVerifier(cfg *config.Config) (*Verifier, error) {
+	method, err := methodOf(cfg.SignatureMethod)
+	if err != nil {
+		return nil, err
+	}
+	if method == MethodMinisign && cfg.SignaturePublicKey == "" {
+		return nil, fmt.Errorf("SIGNATURE_PUBLIC_KEY must name the minisign public key file")
+	}
+	// The default keyring holds every key ever imported, so GnuPG has to be told which
+	// keys are trusted
+	if method == MethodGPG && cfg.SignaturePublicKey == "" {
+		return nil, fmt.Errorf("SIGNATURE_PUBLIC_KEY must name the GnuPG keyring or the fingerprint of the signing key")
+	}
+	if fingerprint, ok := fingerprintOf(cfg.SignaturePublicKey); method == MethodGPG && ok {
+		return &Verifier{method: method, fingerprint: fingerprint}, nil
+	}
+	return &Verifier{method: method, key: cfg.SignaturePublicKey}, nil
+}
+
+// fingerprintOf reports whether key is a GnuPG fingerprint rather than a keyring file,
+// and returns it in upper case without spaces.
+func fingerprintOf(key string) (string, bool) {
+	if _, err := os.Stat(key); err == nil {
+		return "", false
+	}
+	fingerprint := strings.ToUpper(strings.TrimPrefix(strings.ReplaceAll(key, " ", ""), "0x"))
+	if len(fingerprint) != 40 && len(fingerprint) != 64 {
+		return "", false
+	}
+	for _, r := range fingerprint {
+		if !strings.ContainsRune("0123456789ABCDEF", r) {
+			return "", false
+		}
+	}
+	return fingerprint, true
+}
+
+func methodOf(method string) (string, error) {
+	switch strings.ToLower(method) {
+	case "", MethodGPG:
+		return MethodGPG, nil
+	case MethodMinisign:
+		return MethodMinisign, nil
+	}
+	return "", fmt.Errorf("unsupported signature method %q, expected gpg or minisign", method)
+}
+
+// Method returns gpg or minisign.
+func (s *Signer) Method() string {
+	return s.method
+}
+
+// Sign returns a detached signature of the file at path.
+func (s *Signer) Sign(ctx context.Context, path string) ([]byte, error) {
+	if s.method == MethodGPG {
+		args := []string{"--batch", "--yes", "--detach-sign", "--output", "-"}
+		if s.key != "" {
+			args = append(args, "--local-user", s.key)
+		}
+		return run(ctx, "gpg", append(args, "--", path)...)
+	}
+
+	// minisign only writes signatures to files
+	dir, err := os.MkdirTemp("", "s3manager-sign-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sigPath := filepath.Join(dir, "file"+Suffix)
+	if _, err := run(ctx, "minisign", "-S", "-s", s.key, "-m", path, "-x", sigPath); err != nil {
+		return nil, err
+	}
+	signature, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	return signature, nil
+}
+
+// Verify checks that signature is a valid signature of the file at path by a trusted key.
+func (v *Verifier) Verify(ctx context.Context, path string, signature []byte) error {
+	sig, err := os.CreateTemp("", "s3manager-*"+Suffix)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(sig.Name())
+	_, err = sig.Write(signature)
+	if closeErr := sig.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	if v.method == MethodGPG {
+		args := []string{"--batch", "--status-fd", "1"}
+		if v.key != "" {
+			args = append(args, "--no-default-keyring", "--keyring", v.key)
+		}
+		var status []byte
+		status, err = run(ctx, "gpg", append(args, "--verify", "--", sig.Name(), path)...)
+		if err == nil && !signedBy(status, v.fingerprint) {
+			err = fmt.Errorf("gpg: signature is not made by %s", v.fingerprint)
+		}
+	} else {
+		_, err = run(ctx, "minisign", "-V", "-q", "-p", v.key, "-m", path, "-x", sig.Name())
+	}
+	if err != nil {
+		return fmt.Errorf("bad signature for %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// signedBy reports whether the gpg status output has a valid signature by the key with
+// fingerprint, which may also be the primary key of the signing subkey. An empty
+// fingerprint accepts any valid signature.
+func signedBy(status []byte, fingerprint string) bool {
+	for _, line := range strings.Split(string(status), "\n") {
+		// [GNUPG:] VALIDSIG <fingerprint> <date> ... <primary key fingerprint>
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" || fields[1] != "VALIDSIG" {
+			continue
+		}
+		if fingerprint == "" || strings.EqualFold(fields[2], fingerprint) || strings.EqualFold(fields[len(fields)-1], fingerprint) {
+			return true
+		}
+	}
+	return false
+}
+
+// run executes name and returns its output. Errors carry the last line the program
+// wrote to stderr, which says why a signature could not be created or checked.
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, name, args...)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if message := lines[len(lines)-1]; message != "" {
+			return nil, fmt.Errorf("%s: %s", name, message)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
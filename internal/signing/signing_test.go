@@ -0,0 +1,116 @@
+package signing
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"s3manager/config"
+	"strings"
+	"testing"
+)
+
+// newTestKeyring creates a GnuPG home with a signing key and returns the key's user ID
+// and fingerprint. The directory is short because gpg-agent puts its socket in it.
+func newTestKeyring(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+
+	home, err := os.MkdirTemp("", "gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GNUPGHOME", home)
+	t.Cleanup(func() {
+		exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+		os.RemoveAll(home)
+	})
+
+	user := "Backup Signing <backup@example.com>"
+	out, err := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", user, "ed25519", "sign", "never").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v\n%s", err, out)
+	}
+	out, err = exec.Command("gpg", "--batch", "--with-colons", "--fingerprint", user).Output()
+	if err != nil {
+		t.Fatalf("failed to list key: %v", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Split(line, ":"); fields[0] == "fpr" {
+			return user, fields[9]
+		}
+	}
+	t.Fatalf("no fingerprint in %s", out)
+	return "", ""
+}
+
+func TestSignAndVerifyGPG(t *testing.T) {
+	user, fingerprint := newTestKeyring(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "backup.zip")
+	os.WriteFile(path, []byte("backup content"), 0644)
+
+	signer, err := NewSigner(&config.Config{SigningKey: user})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	signature, err := signer.Sign(ctx, path)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if _, err := NewVerifier(&config.Config{}); err == nil {
+		t.Errorf("NewVerifier() without a keyring or fingerprint should return error")
+	}
+	verifier, err := NewVerifier(&config.Config{SignaturePublicKey: fingerprint})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	if err := verifier.Verify(ctx, path, signature); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	other, err := NewVerifier(&config.Config{SignaturePublicKey: strings.Repeat("AB", 20)})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	if err := other.Verify(ctx, path, signature); err == nil {
+		t.Errorf("Verify() of a signature by another key should return error")
+	}
+
+	os.WriteFile(path, []byte("tampered content"), 0644)
+	if err := verifier.Verify(ctx, path, signature); err == nil {
+		t.Errorf("Verify() of a modified file should return error")
+	}
+
+	if _, err := (&Signer{method: MethodGPG, key: "nobody@example.com"}).Sign(ctx, path); err == nil {
+		t.Errorf("Sign() with an unknown key should return error")
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	tests := []struct {
+		cfg     config.Config
+		method  string
+		wantErr bool
+	}{
+		{config.Config{}, MethodGPG, false},
+		{config.Config{SignatureMethod: "GPG", SigningKey: "ABCD1234"}, MethodGPG, false},
+		{config.Config{SignatureMethod: "minisign", SigningKey: "/etc/minisign.key"}, MethodMinisign, false},
+		{config.Config{SignatureMethod: "minisign"}, "", true},
+		{config.Config{SignatureMethod: "x509"}, "", true},
+	}
+	for _, tt := range tests {
+		signer, err := NewSigner(&tt.cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewSigner(%+v) error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+			continue
+		}
+		if err == nil && signer.Method() != tt.method {
+			t.Errorf("NewSigner(%+v) method = %s, want %s", tt.cfg, signer.Method(), tt.method)
+		}
+	}
+}
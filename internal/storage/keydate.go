@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultKeyDateLayout is the layout of dates such as backups/2024-05-01/.
+const DefaultKeyDateLayout = "2006-01-02"
+
+// KeyDate parses the date an object belongs to from its key, for buckets where
+// lifecycle rewrites or replication reset LastModified.
+type KeyDate struct {
+	pattern *regexp.Regexp
+	layout  string
+}
+
+// NewKeyDate returns a KeyDate that takes the date from the first capture group of
+// expr, or from the whole match when expr has no group, and parses it with the Go time
+// layout. Dates without a zone are in UTC.
+func NewKeyDate(expr, layout string) (*KeyDate, error) {
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key date pattern %q: %w", expr, err)
+	}
+	if layout == "" {
+		layout = DefaultKeyDateLayout
+	}
+	return &KeyDate{pattern: pattern, layout: layout}, nil
+}
+
+// Time returns the date in key, and false when key holds no date.
+func (d *KeyDate) Time(key string) (time.Time, bool) {
+	match := d.pattern.FindStringSubmatch(key)
+	if match == nil {
+		return time.Time{}, false
+	}
+	value := match[0]
+	if len(match) > 1 {
+		value = match[1]
+	}
+	t, err := time.Parse(d.layout, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// String returns the pattern and layout as reported in results.
+func (d *KeyDate) String() string {
+	return d.pattern.String() + " (" + d.layout + ")"
+}
+
+// Modified returns the time the age of an object is computed from: the date in its key
+// when d is set, otherwise lastModified. It returns false for keys without a date.
+func (d *KeyDate) Modified(key string, lastModified time.Time) (time.Time, bool) {
+	if d == nil {
+		return lastModified, true
+	}
+	return d.Time(key)
+}
@@ -0,0 +1,405 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"s3manager/internal/journal"
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+const (
+	MismatchMissing    = "missing"
+	MismatchSize       = "size"
+	MismatchAttributes = "attributes"
+)
+
+// MigrateOptions select the objects Migrate copies and how.
+type MigrateOptions struct {
+	Prefix string
+	// Include keeps only the objects matching one of its globs, Exclude drops those
+	// matching one of its globs
+	Include []string
+	Exclude []string
+	// SkipExisting skips objects the destination already has with the same size
+	SkipExisting bool
+	// BandwidthLimit bounds the bytes per second read from the source across all copies,
+	// 0 for no limit
+	BandwidthLimit int64
+	Concurrency    int
+	// StorageClass replaces the storage class of the source objects when both stores
+	// keep attributes, for destinations that do not offer the same classes
+	StorageClass string
+	// Verify lists the destination after copying and checks every planned object
+	Verify bool
+	DryRun bool
+	// Journal records the plan and the copied objects so that an interrupted run can
+	// resume; it may be nil
+	Journal *journal.Journal
+	// OnCopied is called after each copied object with the number copied so far, never
+	// concurrently
+	OnCopied func(key string, size int64, copied int)
+}
+
+// Migrate copies the objects under opts.Prefix from source to the same keys in dest. The
+// stores may belong to different backends or providers; objects are streamed through
+// this process. When both stores are an AttributeStore the objects keep their
+// attributes, otherwise the destination sets its defaults. A resumed journal replaces
+// the listing of source by its plan and skips the objects it marked done.
+func Migrate(ctx context.Context, source, dest ObjectStore, opts MigrateOptions) (*models.MigrateResult, error) {
+	startTime := time.Now()
+
+	for _, patterns := range [][]string{opts.Include, opts.Exclude} {
+		if err := ValidatePatterns(patterns); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if _, _, ok := attributeStores(source, dest); opts.StorageClass != "" && !ok {
+		return nil, fmt.Errorf("a storage class can only be set when both stores keep attributes")
+	}
+
+	result := &models.MigrateResult{
+		SourceBucket:  source.Name(),
+		DestBucket:    dest.Name(),
+		Prefix:        opts.Prefix,
+		Include:       opts.Include,
+		Exclude:       opts.Exclude,
+		OperationTime: utils.FormatTime(startTime),
+		DryRun:        opts.DryRun,
+	}
+
+	planned, err := migrationPlan(ctx, source, dest, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.PlannedCount = len(planned)
+
+	var existing map[string]int64
+	if opts.SkipExisting {
+		if existing, err = objectSizes(ctx, dest, opts.Prefix); err != nil {
+			return nil, err
+		}
+	}
+	var pending []journal.Entry
+	for _, entry := range planned {
+		size, ok := existing[entry.Key]
+		switch {
+		case opts.Journal.IsDone(entry.Key):
+			result.ResumedCount++
+		case ok && size == entry.Size:
+			result.SkippedCount++
+		default:
+			pending = append(pending, entry)
+		}
+	}
+
+	if opts.DryRun {
+		for _, entry := range pending {
+			result.TotalSizeBytes += entry.Size
+		}
+		finishMigrate(result, startTime)
+		return result, nil
+	}
+
+	if err := copyObjects(ctx, source, dest, pending, opts, result); err != nil {
+		return interruptedMigrate(ctx, result, startTime, err)
+	}
+
+	if opts.Verify {
+		if err := verifyMigration(ctx, source, dest, planned, opts, result); err != nil {
+			return nil, err
+		}
+	}
+	finishMigrate(result, startTime)
+	return result, nil
+}
+
+// migrationPlan returns the objects to migrate, sorted by key, from the journal of a
+// previous run or from a listing of source that is then recorded in the journal.
+func migrationPlan(ctx context.Context, source, dest ObjectStore, opts MigrateOptions) ([]journal.Entry, error) {
+	if opts.Journal.Resumed() {
+		planned, _ := opts.Journal.Plan()
+		return planned, nil
+	}
+
+	objects, err := source.List(ctx, opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	var planned []journal.Entry
+	for _, obj := range objects {
+		if len(opts.Include) > 0 && !MatchesAny(obj.Key, opts.Include) || MatchesAny(obj.Key, opts.Exclude) {
+			continue
+		}
+		planned = append(planned, journal.Entry{Key: obj.Key, Size: obj.Size})
+	}
+	sort.Slice(planned, func(i, j int) bool { return planned[i].Key < planned[j].Key })
+
+	if err := opts.Journal.RecordPlan(planned, map[string]string{"source": source.Name(), "dest": dest.Name()}); err != nil {
+		return nil, err
+	}
+	return planned, nil
+}
+
+// copyObjects streams entries from source to dest, opts.Concurrency at a time, and marks
+// each copied object done in the journal. It stops starting copies after the first error.
+func copyObjects(ctx context.Context, source, dest ObjectStore, entries []journal.Entry, opts MigrateOptions, result *models.MigrateResult) error {
+	limiter := utils.NewBandwidthLimiter(opts.BandwidthLimit)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, opts.Concurrency)
+
+	for _, entry := range entries {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed || ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(entry journal.Entry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := copyObject(ctx, source, dest, entry, opts, limiter)
+			if err == nil {
+				err = opts.Journal.MarkDone(entry.Key)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			result.CopiedCount++
+			result.TotalSizeBytes += entry.Size
+			if opts.OnCopied != nil {
+				opts.OnCopied(entry.Key, entry.Size, result.CopiedCount)
+			}
+		}(entry)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func copyObject(ctx context.Context, source, dest ObjectStore, entry journal.Entry, opts MigrateOptions, limiter *utils.RateLimiter) error {
+	attrSource, attrDest, keepAttributes := attributeStores(source, dest)
+	var attrs Attributes
+	if keepAttributes {
+		var err error
+		if attrs, err = attrSource.Attributes(ctx, entry.Key); err != nil {
+			return fmt.Errorf("failed to read %s from %s: %w", entry.Key, source.Name(), err)
+		}
+		if opts.StorageClass != "" {
+			attrs.StorageClass = opts.StorageClass
+		}
+	}
+
+	body, err := source.Get(ctx, entry.Key)
+	if err != nil {
+		return fmt.Errorf("failed to read %s from %s: %w", entry.Key, source.Name(), err)
+	}
+	defer body.Close()
+
+	reader := utils.ThrottledReader(ctx, body, limiter)
+	if keepAttributes {
+		err = attrDest.PutWithAttributes(ctx, entry.Key, reader, entry.Size, attrs)
+	} else {
+		err = dest.Put(ctx, entry.Key, reader, entry.Size)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s to %s: %w", entry.Key, dest.Name(), err)
+	}
+	return nil
+}
+
+// attributeStores returns source and dest as AttributeStores, and whether both are one.
+func attributeStores(source, dest ObjectStore) (AttributeStore, AttributeStore, bool) {
+	attrSource, ok := source.(AttributeStore)
+	if !ok {
+		return nil, nil, false
+	}
+	attrDest, ok := dest.(AttributeStore)
+	return attrSource, attrDest, ok
+}
+
+// verifyMigration lists dest and reports the planned objects it is missing or holds with
+// another size. Sizes are compared because ETags differ between providers. When both
+// stores keep attributes, the objects of the right size are also checked to have the
+// attributes of the source.
+func verifyMigration(ctx context.Context, source, dest ObjectStore, planned []journal.Entry, opts MigrateOptions, result *models.MigrateResult) error {
+	sizes, err := objectSizes(ctx, dest, opts.Prefix)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", dest.Name(), err)
+	}
+
+	var arrived []journal.Entry
+	for _, entry := range planned {
+		size, ok := sizes[entry.Key]
+		switch {
+		case !ok:
+			result.Mismatches = append(result.Mismatches, models.MigrateMismatch{Key: entry.Key, Problem: MismatchMissing, SourceSize: entry.Size})
+		case size != entry.Size:
+			result.Mismatches = append(result.Mismatches, models.MigrateMismatch{Key: entry.Key, Problem: MismatchSize, SourceSize: entry.Size, DestSize: size})
+		default:
+			arrived = append(arrived, entry)
+		}
+	}
+
+	if attrSource, attrDest, ok := attributeStores(source, dest); ok {
+		differences, err := compareAttributes(ctx, attrSource, attrDest, arrived, opts)
+		if err != nil {
+			return fmt.Errorf("failed to verify %s: %w", dest.Name(), err)
+		}
+		for _, entry := range arrived {
+			if names := differences[entry.Key]; len(names) > 0 {
+				result.Mismatches = append(result.Mismatches, models.MigrateMismatch{Key: entry.Key, Problem: MismatchAttributes, SourceSize: entry.Size, DestSize: entry.Size, Attributes: names})
+			}
+		}
+	}
+	result.VerifiedCount = len(planned) - len(result.Mismatches)
+	result.Verified = len(result.Mismatches) == 0
+	return nil
+}
+
+// compareAttributes reads the attributes of entries from both stores, opts.Concurrency
+// at a time, and returns the names of those that differ by key.
+func compareAttributes(ctx context.Context, source, dest AttributeStore, entries []journal.Entry, opts MigrateOptions) (map[string][]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	differences := make(map[string][]string)
+	sem := make(chan struct{}, opts.Concurrency)
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			want, err := source.Attributes(ctx, key)
+			var got Attributes
+			if err == nil {
+				got, err = dest.Attributes(ctx, key)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			if opts.StorageClass != "" {
+				want.StorageClass = opts.StorageClass
+			}
+			if names := attributeDifferences(want, got); len(names) > 0 {
+				differences[key] = names
+			}
+		}(entry.Key)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return differences, ctx.Err()
+}
+
+// attributeDifferences returns the names of the attributes that differ between want and
+// got. An empty storage class is the standard one, and tags are only compared when both
+// stores support them.
+func attributeDifferences(want, got Attributes) []string {
+	var names []string
+	for _, field := range []struct {
+		name      string
+		want, got string
+	}{
+		{"content_type", want.ContentType, got.ContentType},
+		{"content_encoding", want.ContentEncoding, got.ContentEncoding},
+		{"content_disposition", want.ContentDisposition, got.ContentDisposition},
+		{"content_language", want.ContentLanguage, got.ContentLanguage},
+		{"cache_control", want.CacheControl, got.CacheControl},
+		{"storage_class", standardClass(want.StorageClass), standardClass(got.StorageClass)},
+	} {
+		if field.want != field.got {
+			names = append(names, field.name)
+		}
+	}
+	if !maps.Equal(want.Metadata, got.Metadata) {
+		names = append(names, "metadata")
+	}
+	if want.Tags != nil && got.Tags != nil && !maps.Equal(want.Tags, got.Tags) {
+		names = append(names, "tags")
+	}
+	return names
+}
+
+func standardClass(class string) string {
+	if class == "" {
+		return "STANDARD"
+	}
+	return class
+}
+
+func objectSizes(ctx context.Context, store ObjectStore, prefix string) (map[string]int64, error) {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(objects))
+	for _, obj := range objects {
+		sizes[obj.Key] = obj.Size
+	}
+	return sizes, nil
+}
+
+func finishMigrate(result *models.MigrateResult, startTime time.Time) {
+	duration := time.Since(startTime)
+	throughput := utils.BytesPerSecond(result.TotalSizeBytes, duration)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.MigrateDuration = duration.String()
+	result.ThroughputBytes = throughput
+	result.ThroughputHuman = utils.FormatSpeed(throughput)
+}
+
+// interruptedMigrate returns what was copied so far when ctx was cancelled, and only the
+// error otherwise.
+func interruptedMigrate(ctx context.Context, result *models.MigrateResult, startTime time.Time, err error) (*models.MigrateResult, error) {
+	if ctx.Err() == nil {
+		return nil, err
+	}
+	finishMigrate(result, startTime)
+	result.Interrupted = true
+	result.Error = err.Error()
+	return result, err
+}
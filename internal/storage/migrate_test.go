@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"s3manager/internal/journal"
+	"testing"
+	"time"
+)
+
+// discardStore accepts writes without storing them.
+type discardStore struct {
+	*memStore
+}
+
+func (d discardStore) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	_, err := io.Copy(io.Discard, body)
+	return err
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	source, dest := newMemStore(), newMemStore()
+	now := time.Now()
+	source.objects["db/1.sql"] = memObject{data: []byte("one"), lastModified: now}
+	source.objects["db/2.log"] = memObject{data: []byte("two"), lastModified: now}
+	source.objects["db/3.sql"] = memObject{data: []byte("three"), lastModified: now}
+	source.objects["db/skip/4.sql"] = memObject{data: []byte("four"), lastModified: now}
+	source.objects["other/5.sql"] = memObject{data: []byte("five"), lastModified: now}
+	dest.objects["db/3.sql"] = memObject{data: []byte("THREE"), lastModified: now}
+
+	path := filepath.Join(t.TempDir(), "migrate.journal")
+	jr, err := journal.Open(path, "migrate", "test", false)
+	if err != nil {
+		t.Fatalf("journal.Open() error = %v", err)
+	}
+	opts := MigrateOptions{
+		Prefix:       "db/",
+		Include:      []string{"*.sql"},
+		Exclude:      []string{"db/skip/*"},
+		SkipExisting: true,
+		Concurrency:  2,
+		Verify:       true,
+		Journal:      jr,
+	}
+
+	dryRun := opts
+	dryRun.DryRun, dryRun.Journal = true, nil
+	result, err := Migrate(ctx, source, dest, dryRun)
+	if err != nil {
+		t.Fatalf("Migrate(dry run) error = %v", err)
+	}
+	if result.PlannedCount != 2 || result.TotalSizeBytes != 3 || len(dest.objects) != 1 {
+		t.Errorf("Migrate(dry run) = %+v, want 2 planned objects, 3 bytes to copy and nothing copied", result)
+	}
+
+	result, err = Migrate(ctx, source, dest, opts)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if result.PlannedCount != 2 || result.CopiedCount != 1 || result.SkippedCount != 1 || result.TotalSizeBytes != 3 {
+		t.Errorf("Migrate() = %+v, want 1 of 2 planned objects copied and 1 skipped", result)
+	}
+	if !result.Verified || result.VerifiedCount != 2 {
+		t.Errorf("Verified = %v with %d objects, want true with 2", result.Verified, result.VerifiedCount)
+	}
+	if string(dest.objects["db/1.sql"].data) != "one" {
+		t.Errorf("db/1.sql in destination = %q, want one", dest.objects["db/1.sql"].data)
+	}
+	if err := jr.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// A resumed run skips what the journal marked done, even without the destination listing
+	jr, err = journal.Open(path, "migrate", "test", true)
+	if err != nil {
+		t.Fatalf("journal.Open(resume) error = %v", err)
+	}
+	defer jr.Close()
+	opts.Journal, opts.SkipExisting = jr, false
+	source.objects["db/6.sql"] = memObject{data: []byte("six"), lastModified: now}
+	result, err = Migrate(ctx, source, dest, opts)
+	if err != nil {
+		t.Fatalf("Migrate(resume) error = %v", err)
+	}
+	if result.PlannedCount != 2 || result.ResumedCount != 1 || result.CopiedCount != 1 {
+		t.Errorf("Migrate(resume) = %+v, want the 2 planned objects, 1 resumed and 1 copied", result)
+	}
+	if string(dest.objects["db/3.sql"].data) != "three" {
+		t.Errorf("db/3.sql in destination = %q, want three", dest.objects["db/3.sql"].data)
+	}
+}
+
+func TestMigrateVerifyFindsMissingObjects(t *testing.T) {
+	source := newMemStore()
+	source.objects["a.bin"] = memObject{data: []byte("a"), lastModified: time.Now()}
+
+	result, err := Migrate(context.Background(), source, discardStore{newMemStore()}, MigrateOptions{Verify: true, BandwidthLimit: 1 << 20})
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if result.Verified || len(result.Mismatches) != 1 || result.Mismatches[0].Problem != MismatchMissing {
+		t.Errorf("Migrate() = %+v, want a.bin reported missing", result)
+	}
+}
+
+func TestMigrateStorageClassNeedsAttributes(t *testing.T) {
+	_, err := Migrate(context.Background(), newMemStore(), newMemStore(), MigrateOptions{StorageClass: "STANDARD"})
+	if err == nil {
+		t.Error("Migrate() with a storage class between stores without attributes succeeded")
+	}
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ValidatePatterns checks that every glob in patterns is well formed.
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MatchesAny reports whether key matches one of the globs in patterns. A pattern without
+// a slash is matched against the object name, otherwise against the full key.
+func MatchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		name := key
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(key)
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CompileRegex compiles a --regex, which is matched against full keys where globs fall
+// short. An empty expression gives nil, which matches every key.
+func CompileRegex(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+	}
+	return re, nil
+}
+
+// MatchesRegex reports whether key matches re, a nil re matches every key.
+func MatchesRegex(key string, re *regexp.Regexp) bool {
+	return re == nil || re.MatchString(key)
+}
+
+// RegexString reports the expression of re, empty when it is nil.
+func RegexString(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// DistinctPrefixes returns prefixes without duplicates and without the prefixes that
+// start with another one, whose objects the other one already covers, so that listing
+// each of them returns every object once. An empty prefix, or none at all, is the whole
+// bucket.
+func DistinctPrefixes(prefixes []string) []string {
+	sorted := slices.Clone(prefixes)
+	slices.Sort(sorted)
+
+	distinct := []string{}
+	for _, prefix := range sorted {
+		if len(distinct) > 0 && strings.HasPrefix(prefix, distinct[len(distinct)-1]) {
+			continue
+		}
+		distinct = append(distinct, prefix)
+	}
+	if len(distinct) == 0 {
+		return []string{""}
+	}
+	return distinct
+}
+
+// FolderPrefixes returns the distinct listing prefixes of folders, each ending with "/".
+func FolderPrefixes(folders []string) []string {
+	prefixes := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		prefixes = append(prefixes, folderPrefix(folder))
+	}
+	return DistinctPrefixes(prefixes)
+}
+
+// folderEscaper escapes the commas that separate joined folders, and the backslashes
+// that escape them.
+var folderEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`)
+
+// JoinFolders formats folders for results and plans, which SplitFolders reads back.
+// Commas inside a folder are escaped as "\,".
+func JoinFolders(folders []string) string {
+	escaped := make([]string, len(folders))
+	for i, folder := range folders {
+		escaped[i] = folderEscaper.Replace(folder)
+	}
+	return strings.Join(escaped, ",")
+}
+
+// SplitFolders returns the folders of a result or plan, see JoinFolders. A backslash
+// escapes a following comma or backslash and is kept before any other character.
+func SplitFolders(folder string) []string {
+	var folders []string
+	var b strings.Builder
+	for i := 0; i < len(folder); i++ {
+		switch {
+		case folder[i] == '\\' && i+1 < len(folder) && (folder[i+1] == ',' || folder[i+1] == '\\'):
+			i++
+			b.WriteByte(folder[i])
+		case folder[i] == ',':
+			folders = append(folders, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(folder[i])
+		}
+	}
+	return append(folders, b.String())
+}
+
+// ListFolders lists the objects of every folder in store, each object once.
+func ListFolders(ctx context.Context, store ObjectStore, folders []string) ([]Object, error) {
+	var objects []Object
+	for _, prefix := range FolderPrefixes(folders) {
+		listed, err := store.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, listed...)
+	}
+	return objects, nil
+}
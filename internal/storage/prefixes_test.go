@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDistinctPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     []string
+	}{
+		{"none", nil, []string{""}},
+		{"whole bucket", []string{"logs/", ""}, []string{""}},
+		{"duplicates", []string{"logs/app2/", "logs/app1/", "logs/app2/"}, []string{"logs/app1/", "logs/app2/"}},
+		{"nested", []string{"logs/app1/2024/", "logs/"}, []string{"logs/"}},
+		{"siblings", []string{"logs/app", "logs/app-2"}, []string{"logs/app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DistinctPrefixes(tt.prefixes); !slices.Equal(got, tt.want) {
+				t.Errorf("DistinctPrefixes(%q) = %q, want %q", tt.prefixes, got, tt.want)
+			}
+		})
+	}
+
+	// Commas inside folders survive a round trip through results and plans
+	folders := []string{"exports/a,b/", `logs\app/`, ""}
+	if got := SplitFolders(JoinFolders(folders)); !slices.Equal(got, folders) {
+		t.Errorf("SplitFolders(JoinFolders(%q)) = %q", folders, got)
+	}
+	if got := SplitFolders(`logs/app1,exports/a\,b`); !slices.Equal(got, []string{"logs/app1", "exports/a,b"}) {
+		t.Errorf("SplitFolders() = %q, want the escaped comma kept", got)
+	}
+
+	// Folders end with a slash, so logs/app does not cover logs/app-2
+	if got := FolderPrefixes([]string{"logs/app", "logs/app-2/"}); !slices.Equal(got, []string{"logs/app-2/", "logs/app/"}) {
+		t.Errorf("FolderPrefixes() = %q", got)
+	}
+}
+
+func TestDeleteOldFolders(t *testing.T) {
+	old := time.Now().AddDate(0, 0, -40)
+	store := newMemStore()
+	for _, key := range []string{"logs/app1/a.log", "logs/app2/b.log", "logs/app3/c.log"} {
+		store.objects[key] = memObject{data: []byte("x"), lastModified: old}
+	}
+
+	opts := DeleteOptions{Folders: []string{"logs/app1", "logs/app2", "logs/app1/"}, DaysOld: 30, DryRun: true}
+	result, err := DeleteOld(context.Background(), store, opts)
+	if err != nil {
+		t.Fatalf("DeleteOld() error = %v", err)
+	}
+	slices.Sort(result.DeletedFiles)
+	if !slices.Equal(result.DeletedFiles, []string{"logs/app1/a.log", "logs/app2/b.log"}) {
+		t.Errorf("DeletedFiles = %q, want the old objects of app1 and app2 once", result.DeletedFiles)
+	}
+	if !slices.Equal(SplitFolders(result.Folder), opts.Folders) {
+		t.Errorf("Folder = %q, want the folders of the run", result.Folder)
+	}
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"s3manager/internal/filter"
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+type PruneOptions struct {
+	Folder string
+	// MaxTotalSize is the byte budget of the folder.
+	MaxTotalSize int64
+	// Exclude keeps the objects matching one of these globs; they still count against
+	// the budget.
+	Exclude []string
+	// Filter, when set, keeps the objects it does not match like excluded ones.
+	Filter            *filter.Filter
+	ProtectedPrefixes []string
+	MaxDelete         int
+	DryRun            bool
+}
+
+// Prune deletes the oldest objects in the folder until the rest fits within
+// MaxTotalSize, like the S3 client's Prune. Protected and excluded objects are never
+// deleted but take up the budget like any other object.
+func Prune(ctx context.Context, store ObjectStore, opts PruneOptions) (*models.PruneResult, error) {
+	if opts.MaxTotalSize < 0 {
+		return nil, fmt.Errorf("max total size must not be negative")
+	}
+
+	objects, err := store.List(ctx, folderPrefix(opts.Folder))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].LastModified.After(objects[j].LastModified)
+	})
+
+	result := &models.PruneResult{
+		BucketName:        store.Name(),
+		Folder:            opts.Folder,
+		Filter:            opts.Filter.String(),
+		MaxTotalSizeBytes: opts.MaxTotalSize,
+		MaxTotalSizeHuman: utils.FormatBytes(opts.MaxTotalSize),
+		DeletedFiles:      []string{},
+		DryRun:            opts.DryRun,
+	}
+
+	// Kept objects are charged first, so that deleting the oldest of the rest frees the
+	// remaining budget for the newest ones
+	var rest []Object
+	for _, obj := range objects {
+		result.TotalSizeBytes += obj.Size
+		switch {
+		case isProtected(obj.Key, opts.ProtectedPrefixes):
+			result.ProtectedCount++
+		case MatchesAny(obj.Key, opts.Exclude) || !opts.Filter.Match(filter.Object{Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified}):
+			result.ExcludedCount++
+		default:
+			rest = append(rest, obj)
+			continue
+		}
+		result.KeptCount++
+		result.KeptSizeBytes += obj.Size
+	}
+
+	// Walking from the newest object, everything from the first one that does not fit
+	// is deleted, so a newer object is never deleted while an older one is kept
+	var toDelete []string
+	sizes := make(map[string]int64)
+	for _, obj := range rest {
+		if len(toDelete) == 0 && result.KeptSizeBytes+obj.Size <= opts.MaxTotalSize {
+			result.KeptCount++
+			result.KeptSizeBytes += obj.Size
+			continue
+		}
+		toDelete = append(toDelete, obj.Key)
+		sizes[obj.Key] = obj.Size
+		result.DeletedSizeBytes += obj.Size
+	}
+	result.DeletedFiles = append(result.DeletedFiles, toDelete...)
+	result.OverBudget = result.KeptSizeBytes > opts.MaxTotalSize
+
+	if !opts.DryRun && len(toDelete) > 0 {
+		if opts.MaxDelete > 0 && len(toDelete) > opts.MaxDelete {
+			return nil, fmt.Errorf("%w: %d objects match, the limit is %d (--max-delete or MAX_DELETE)",
+				ErrMaxDeleteExceeded, len(toDelete), opts.MaxDelete)
+		}
+
+		deleted, err := store.Delete(ctx, toDelete)
+		result.DeletedCount = len(deleted)
+		if err != nil {
+			if ctx.Err() == nil {
+				return nil, err
+			}
+			// Report only what was deleted before the interruption
+			result.DeletedFiles = deleted
+			result.DeletedSizeBytes = 0
+			for _, key := range deleted {
+				result.DeletedSizeBytes += sizes[key]
+			}
+			result.Interrupted = true
+			result.Error = err.Error()
+			finishPruneResult(result)
+			return result, err
+		}
+	}
+
+	finishPruneResult(result)
+	return result, nil
+}
+
+func finishPruneResult(result *models.PruneResult) {
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.KeptSizeHuman = utils.FormatBytes(result.KeptSizeBytes)
+	result.DeletedSizeHuman = utils.FormatBytes(result.DeletedSizeBytes)
+	result.OperationTime = utils.FormatTime(time.Now())
+}
@@ -0,0 +1,419 @@
+// Package storage defines the operations every storage backend provides and implements
+// upload, download and delete-old on top of them. The S3 client implements ObjectStore
+// as well, but its commands keep using the S3-specific code paths with journals, locks
+// and multipart transfers; ObjectStore is what lets those commands run against other
+// backends.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"s3manager/internal/filter"
+	"s3manager/internal/models"
+	"s3manager/pkg/utils"
+)
+
+// ErrMaxDeleteExceeded is returned when a deletion would remove more objects than the
+// configured MaxDelete limit. Nothing is deleted in that case.
+var ErrMaxDeleteExceeded = errors.New("too many objects to delete")
+
+// Object describes a stored object.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ObjectStore is a bucket or container of a storage backend.
+type ObjectStore interface {
+	// Name is the bucket or container reported in results.
+	Name() string
+	// List returns every object whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Put stores size bytes read from body under key, replacing an existing object. A
+	// size of -1 reads body to its end, for content whose length is not known in advance.
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	// Get opens the content of key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes keys and returns those it removed, also when it fails part-way.
+	Delete(ctx context.Context, keys []string) ([]string, error)
+}
+
+// Attributes are what a store keeps of an object besides its content. Empty fields are
+// not set.
+type Attributes struct {
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CacheControl       string
+	StorageClass       string
+	Metadata           map[string]string
+	// Tags are nil when the store does not support tags
+	Tags map[string]string
+}
+
+// AttributeStore is an ObjectStore that keeps the attributes of its objects. Migrate
+// carries them over when both stores are one.
+type AttributeStore interface {
+	ObjectStore
+	// Attributes returns the attributes of key.
+	Attributes(ctx context.Context, key string) (Attributes, error)
+	// PutWithAttributes stores body like Put, with attrs instead of the attributes Put
+	// derives from the key.
+	PutWithAttributes(ctx context.Context, key string, body io.Reader, size int64, attrs Attributes) error
+}
+
+// Upload stores paths under destination, as a single zip archive when archive is set.
+// The archive is named archiveName, or gets a generated name when it is empty. A
+// non-zero modifiedSince skips files last modified before it.
+func Upload(ctx context.Context, store ObjectStore, paths []string, destination string, archive bool, archiveName string, excludePatterns []string, modifiedSince time.Time, tempDir string) (*models.UploadResult, error) {
+	startTime := time.Now()
+
+	if err := utils.ValidatePaths(paths); err != nil {
+		return nil, fmt.Errorf("path validation failed: %w", err)
+	}
+
+	result := &models.UploadResult{
+		BucketName:      store.Name(),
+		DestinationPath: destination,
+		OperationTime:   utils.FormatTime(startTime),
+	}
+	if !modifiedSince.IsZero() {
+		result.ModifiedSince = utils.FormatTime(modifiedSince)
+	}
+
+	if archive {
+		dir, err := utils.TempDir(tempDir)
+		if err != nil {
+			return nil, err
+		}
+		archivePath := filepath.Join(dir, utils.ArchiveName(archiveName, paths, ".zip"))
+		defer func() {
+			if err := utils.CleanupTempFile(archivePath); err != nil {
+				slog.Warn("Failed to clean up temporary archive file", "path", archivePath, "error", err)
+			}
+		}()
+
+		size, err := utils.ArchiveInputSize(paths, excludePatterns, modifiedSince)
+		if err != nil {
+			return nil, err
+		}
+		if err := utils.CheckFreeSpace(filepath.Dir(archivePath), size); err != nil {
+			return nil, fmt.Errorf("cannot create archive, use --temp-dir or TEMP_DIR for a larger volume: %w", err)
+		}
+		archiveInfo, err := utils.CreateArchiveSince(ctx, paths, archivePath, excludePatterns, modifiedSince)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create archive: %w", err)
+		}
+		result.SkippedCount = archiveInfo.SkippedCount
+		if !modifiedSince.IsZero() && archiveInfo.FileCount == 0 {
+			return finishUpload(result, startTime), nil
+		}
+
+		remotePath := RemotePath(destination, filepath.Base(archivePath))
+		if err := putFile(ctx, store, archivePath, remotePath); err != nil {
+			return nil, fmt.Errorf("failed to upload archive: %w", err)
+		}
+
+		result.ArchiveCreated = true
+		result.ArchivePath = archivePath
+		result.Items = append(result.Items, models.UploadItem{
+			LocalPath:  strings.Join(paths, ", "),
+			RemotePath: remotePath,
+			Size:       archiveInfo.CompressedSize,
+			IsArchived: true,
+		})
+		result.TotalSizeBytes = archiveInfo.CompressedSize
+	} else {
+		for _, path := range paths {
+			err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				if err := ctx.Err(); err != nil {
+					return context.Cause(ctx)
+				}
+				if info.ModTime().Before(modifiedSince) {
+					result.SkippedCount++
+					return nil
+				}
+
+				name := filepath.Base(path)
+				if file != path {
+					rel, err := filepath.Rel(path, file)
+					if err != nil {
+						return err
+					}
+					name = filepath.Join(name, rel)
+				}
+
+				remotePath := RemotePath(destination, filepath.ToSlash(name))
+				if err := putFile(ctx, store, file, remotePath); err != nil {
+					return err
+				}
+
+				result.Items = append(result.Items, models.UploadItem{
+					LocalPath:  file,
+					RemotePath: remotePath,
+					Size:       info.Size(),
+				})
+				result.TotalSizeBytes += info.Size()
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to upload %s: %w", path, err)
+			}
+		}
+	}
+
+	return finishUpload(result, startTime), nil
+}
+
+func finishUpload(result *models.UploadResult, startTime time.Time) *models.UploadResult {
+	duration := time.Since(startTime)
+	throughput := utils.BytesPerSecond(result.TotalSizeBytes, duration)
+	result.TotalFiles = len(result.Items)
+	result.TotalSizeHuman = utils.FormatBytes(result.TotalSizeBytes)
+	result.UploadDuration = duration.String()
+	result.ThroughputBytes = throughput
+	result.ThroughputHuman = utils.FormatSpeed(throughput)
+	return result
+}
+
+func putFile(ctx context.Context, store ObjectStore, localPath, key string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", localPath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file %s: %w", localPath, err)
+	}
+	return store.Put(ctx, key, file, info.Size())
+}
+
+// DownloadLatest downloads the most recently modified object in folder into destination.
+func DownloadLatest(ctx context.Context, store ObjectStore, folder, destination string) (*models.DownloadResult, error) {
+	startTime := time.Now()
+
+	objects, err := store.List(ctx, folderPrefix(folder))
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no files found in folder: %s", folder)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].LastModified.After(objects[j].LastModified)
+	})
+	latest := objects[0]
+
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	if err := utils.CheckFreeSpace(destination, latest.Size); err != nil {
+		return nil, err
+	}
+	localPath := filepath.Join(destination, filepath.Base(latest.Key))
+	if err := getFile(ctx, store, latest.Key, localPath); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(startTime)
+	throughput := utils.BytesPerSecond(latest.Size, duration)
+	return &models.DownloadResult{
+		BucketName: store.Name(),
+		SourcePath: folder,
+		Items: []models.DownloadItem{{
+			RemotePath:   latest.Key,
+			LocalPath:    localPath,
+			Size:         latest.Size,
+			LastModified: latest.LastModified.Format(time.RFC3339),
+		}},
+		TotalFiles:       1,
+		TotalSizeBytes:   latest.Size,
+		TotalSizeHuman:   utils.FormatBytes(latest.Size),
+		OperationTime:    utils.FormatTime(startTime),
+		DownloadDuration: duration.String(),
+		ThroughputBytes:  throughput,
+		ThroughputHuman:  utils.FormatSpeed(throughput),
+	}, nil
+}
+
+func getFile(ctx context.Context, store ObjectStore, key, localPath string) error {
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer body.Close()
+
+	file, err := utils.CreateTempFor(localPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = utils.CommitTemp(file.Name(), localPath)
+	}
+	if err != nil {
+		// Do not leave a truncated file behind
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	return nil
+}
+
+type DeleteOptions struct {
+	// Folders are searched for old objects, the whole bucket when there is none
+	Folders []string
+	DaysOld int
+	DryRun  bool
+	// MaxDelete aborts deletions of more objects, 0 disables the limit
+	MaxDelete int
+	// Objects under ProtectedPrefixes are skipped
+	ProtectedPrefixes []string
+	// Objects matching one of the Exclude globs are kept however old they are
+	Exclude []string
+	// Regex, when set, limits the deletion to the keys it matches
+	Regex *regexp.Regexp
+	// Filter, when set, limits the deletion to the objects matching the expression
+	Filter *filter.Filter
+	// DateFromKey, when set, dates objects by their key instead of LastModified
+	DateFromKey *KeyDate
+	// Window limits the deletion to objects modified within it
+	Window TimeWindow
+}
+
+// DeleteOld deletes the objects in opts.Folders last modified more than opts.DaysOld days ago.
+func DeleteOld(ctx context.Context, store ObjectStore, opts DeleteOptions) (*models.DeleteResult, error) {
+	cutoffDate := opts.Window.Cutoff(time.Now().AddDate(0, 0, -opts.DaysOld))
+
+	objects, err := ListFolders(ctx, store, opts.Folders)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	var totalSize int64
+	protectedCount, excludedCount, undatedCount := 0, 0, 0
+	for _, obj := range objects {
+		if !MatchesRegex(obj.Key, opts.Regex) || !opts.Filter.Match(filter.Object{Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified}) {
+			continue
+		}
+		modified, ok := opts.DateFromKey.Modified(obj.Key, obj.LastModified)
+		if !ok {
+			undatedCount++
+			continue
+		}
+		if !modified.Before(cutoffDate) || !opts.Window.Contains(modified) {
+			continue
+		}
+		if MatchesAny(obj.Key, opts.Exclude) {
+			excludedCount++
+			continue
+		}
+		if isProtected(obj.Key, opts.ProtectedPrefixes) {
+			protectedCount++
+			continue
+		}
+		candidates = append(candidates, obj.Key)
+		totalSize += obj.Size
+	}
+
+	result := &models.DeleteResult{
+		BucketName:     store.Name(),
+		Folder:         JoinFolders(opts.Folders),
+		DaysOld:        opts.DaysOld,
+		DeletedFiles:   candidates,
+		TotalSizeBytes: totalSize,
+		TotalSizeHuman: utils.FormatBytes(totalSize),
+		CutoffDate:     utils.FormatTime(cutoffDate),
+		NewerThan:      FormatBound(opts.Window.After),
+		ProtectedCount: protectedCount,
+		ExcludedCount:  excludedCount,
+		UndatedCount:   undatedCount,
+		Regex:          RegexString(opts.Regex),
+		Filter:         opts.Filter.String(),
+	}
+	if opts.DateFromKey != nil {
+		result.DateFromKey = opts.DateFromKey.String()
+	}
+	if result.DeletedFiles == nil {
+		result.DeletedFiles = []string{}
+	}
+
+	if !opts.DryRun && len(candidates) > 0 {
+		if opts.MaxDelete > 0 && len(candidates) > opts.MaxDelete {
+			return nil, fmt.Errorf("%w: %d objects match, the limit is %d (--max-delete or MAX_DELETE)",
+				ErrMaxDeleteExceeded, len(candidates), opts.MaxDelete)
+		}
+
+		deleted, err := store.Delete(ctx, candidates)
+		result.DeletedCount = len(deleted)
+		if err != nil {
+			if ctx.Err() == nil {
+				return nil, err
+			}
+			result.DeletedFiles = deleted
+			result.Interrupted = true
+			result.Error = err.Error()
+			result.OperationTime = utils.FormatTime(time.Now())
+			return result, err
+		}
+	}
+
+	result.OperationTime = utils.FormatTime(time.Now())
+	return result, nil
+}
+
+// FormatBound reports a bound of a TimeWindow, empty when it is open.
+func FormatBound(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return utils.FormatTime(t)
+}
+
+func isProtected(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, strings.TrimPrefix(prefix, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemotePath joins destination and name into an object key.
+func RemotePath(destination, name string) string {
+	destination = strings.TrimPrefix(destination, "/")
+	if destination == "" {
+		return name
+	}
+	if !strings.HasSuffix(destination, "/") {
+		destination += "/"
+	}
+	return destination + name
+}
+
+func folderPrefix(folder string) string {
+	if folder != "" && !strings.HasSuffix(folder, "/") {
+		return folder + "/"
+	}
+	return folder
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is an ObjectStore kept in memory.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string]memObject
+}
+
+type memObject struct {
+	data         []byte
+	lastModified time.Time
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: make(map[string]memObject)}
+}
+
+func (m *memStore) Name() string { return "mem" }
+
+func (m *memStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var objects []Object
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, Object{Key: key, Size: int64(len(obj.data)), LastModified: obj.lastModified})
+		}
+	}
+	return objects, nil
+}
+
+func (m *memStore) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = memObject{data: data, lastModified: time.Now()}
+	return nil
+}
+
+func (m *memStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (m *memStore) Delete(ctx context.Context, keys []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.objects, key)
+	}
+	return keys, nil
+}
+
+func TestUploadAndDownloadLatest(t *testing.T) {
+	dir, err := os.MkdirTemp("", "storage-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "site")
+	if err := os.MkdirAll(filepath.Join(src, "css"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(src, "index.html"), []byte("<html>"), 0644)
+	os.WriteFile(filepath.Join(src, "css", "app.css"), []byte("body{}"), 0644)
+
+	store := newMemStore()
+	result, err := Upload(context.Background(), store, []string{src}, "releases", false, "", nil, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.TotalFiles != 2 || result.TotalSizeBytes != 12 {
+		t.Errorf("Upload() = %d files, %d bytes, want 2 files, 12 bytes", result.TotalFiles, result.TotalSizeBytes)
+	}
+
+	keys := make([]string, 0, len(store.objects))
+	for key := range store.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if strings.Join(keys, ",") != "releases/site/css/app.css,releases/site/index.html" {
+		t.Errorf("uploaded keys = %v", keys)
+	}
+
+	store.objects["releases/site/index.html"] = memObject{data: []byte("<html>"), lastModified: time.Now().Add(time.Hour)}
+	download, err := DownloadLatest(context.Background(), store, "releases/site", filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatalf("DownloadLatest() error = %v", err)
+	}
+	data, err := os.ReadFile(download.Items[0].LocalPath)
+	if err != nil || string(data) != "<html>" {
+		t.Errorf("DownloadLatest() wrote %q, %v, want the newest object", data, err)
+	}
+}
+
+func TestUploadArchiveUsesTempDir(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "site")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "index.html"), []byte("<html>"), 0644)
+	tempDir := filepath.Join(t.TempDir(), "spool")
+
+	result, err := Upload(context.Background(), newMemStore(), []string{src}, "", true, "site", nil, time.Time{}, tempDir)
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if want := filepath.Join(tempDir, "site.zip"); result.ArchivePath != want {
+		t.Errorf("ArchivePath = %s, want %s", result.ArchivePath, want)
+	}
+	if _, err := os.Stat(result.ArchivePath); !os.IsNotExist(err) {
+		t.Errorf("temporary archive was not removed: %v", err)
+	}
+}
+
+func TestDeleteOld(t *testing.T) {
+	old := time.Now().AddDate(0, 0, -40)
+	store := newMemStore()
+	store.objects["logs/a.log"] = memObject{data: []byte("aaa"), lastModified: old}
+	store.objects["logs/keep/b.log"] = memObject{data: []byte("b"), lastModified: old}
+	store.objects["logs/c.log"] = memObject{data: []byte("c"), lastModified: time.Now()}
+	store.objects["other/d.log"] = memObject{data: []byte("d"), lastModified: old}
+
+	opts := DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, DryRun: true, ProtectedPrefixes: []string{"logs/keep/"}}
+	result, err := DeleteOld(context.Background(), store, opts)
+	if err != nil {
+		t.Fatalf("DeleteOld() error = %v", err)
+	}
+	if len(result.DeletedFiles) != 1 || result.DeletedCount != 0 || result.ProtectedCount != 1 {
+		t.Errorf("DeleteOld() dry run = %+v", result)
+	}
+	if len(store.objects) != 4 {
+		t.Errorf("dry run deleted objects")
+	}
+
+	opts.DryRun = false
+	opts.MaxDelete = 1
+	if _, err := DeleteOld(context.Background(), store, DeleteOptions{DaysOld: 30, MaxDelete: 1}); !errors.Is(err, ErrMaxDeleteExceeded) {
+		t.Errorf("DeleteOld() error = %v, want ErrMaxDeleteExceeded", err)
+	}
+
+	result, err = DeleteOld(context.Background(), store, opts)
+	if err != nil {
+		t.Fatalf("DeleteOld() error = %v", err)
+	}
+	if result.DeletedCount != 1 || result.TotalSizeBytes != 3 {
+		t.Errorf("DeleteOld() = %+v, want logs/a.log deleted", result)
+	}
+	if _, ok := store.objects["logs/a.log"]; ok {
+		t.Errorf("logs/a.log was not deleted")
+	}
+
+	store.objects["logs/manifest.json"] = memObject{data: []byte("{}"), lastModified: old}
+	result, err = DeleteOld(context.Background(), store, DeleteOptions{Folders: []string{"logs"}, DaysOld: 30, DryRun: true, Exclude: []string{"*.json"}})
+	if err != nil {
+		t.Fatalf("DeleteOld() error = %v", err)
+	}
+	if len(result.DeletedFiles) != 1 || result.DeletedFiles[0] != "logs/keep/b.log" || result.ExcludedCount != 1 {
+		t.Errorf("DeleteOld() with exclusions = %+v, want logs/manifest.json kept", result)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	store := newMemStore()
+	store.objects["db/1.sql"] = memObject{data: []byte("1111"), lastModified: now.Add(-4 * time.Hour)}
+	store.objects["db/2.sql"] = memObject{data: []byte("2222"), lastModified: now.Add(-3 * time.Hour)}
+	store.objects["db/3.sql"] = memObject{data: []byte("3333"), lastModified: now.Add(-2 * time.Hour)}
+	store.objects["db/manifest.json"] = memObject{data: []byte("{}"), lastModified: now.Add(-5 * time.Hour)}
+
+	opts := PruneOptions{Folder: "db", MaxTotalSize: 7, Exclude: []string{"*.json"}, MaxDelete: 1}
+	if _, err := Prune(context.Background(), store, opts); !errors.Is(err, ErrMaxDeleteExceeded) {
+		t.Errorf("Prune() error = %v, want ErrMaxDeleteExceeded", err)
+	}
+
+	opts.MaxDelete = 0
+	result, err := Prune(context.Background(), store, opts)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	// The manifest is kept and takes up 2 bytes, so only db/3.sql fits besides it
+	if strings.Join(result.DeletedFiles, ",") != "db/2.sql,db/1.sql" || result.KeptSizeBytes != 6 || result.ExcludedCount != 1 {
+		t.Errorf("Prune() = %+v, want the two oldest dumps deleted", result)
+	}
+	if _, ok := store.objects["db/1.sql"]; ok {
+		t.Errorf("db/1.sql was not deleted")
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		key      string
+		patterns []string
+		want     bool
+	}{
+		{"exports/2024/manifest.json", []string{"*.json"}, true},
+		{"exports/LATEST", []string{"*.json", "LATEST"}, true},
+		{"exports/2024/data.csv", []string{"*.json", "LATEST"}, false},
+		{"exports/2024/index.html", []string{"exports/*/index.html"}, true},
+		{"exports/2024/index.html", []string{"exports/*.html"}, false},
+		{"exports/data.csv", nil, false},
+	}
+	for _, tt := range tests {
+		if got := MatchesAny(tt.key, tt.patterns); got != tt.want {
+			t.Errorf("MatchesAny(%q, %v) = %v, want %v", tt.key, tt.patterns, got, tt.want)
+		}
+	}
+
+	if err := ValidatePatterns([]string{"*.json", "[a-"}); err == nil {
+		t.Errorf("ValidatePatterns() with a malformed glob should return error")
+	}
+}
+
+func TestKeyDate(t *testing.T) {
+	byDay, err := NewKeyDate(`(\d{4}-\d{2}-\d{2})/`, "")
+	if err != nil {
+		t.Fatalf("NewKeyDate() error = %v", err)
+	}
+	got, ok := byDay.Time("backups/2024-05-01/db.sql.gz")
+	if !ok || !got.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time() = %v, %v, want 2024-05-01", got, ok)
+	}
+	if _, ok := byDay.Time("backups/latest/db.sql.gz"); ok {
+		t.Errorf("Time() of a key without date should return false")
+	}
+	if _, ok := byDay.Time("backups/2024-13-01/db.sql.gz"); ok {
+		t.Errorf("Time() of an invalid date should return false")
+	}
+
+	// Without a group the whole match is parsed
+	compact, _ := NewKeyDate(`\d{8}T\d{4}`, "20060102T1504")
+	if got, ok := compact.Time("dumps/db-20240501T2330.sql"); !ok || got.Hour() != 23 {
+		t.Errorf("Time() = %v, %v, want 2024-05-01 23:30", got, ok)
+	}
+
+	var none *KeyDate
+	modified := time.Now()
+	if got, ok := none.Modified("backups/2024-05-01/db.sql.gz", modified); !ok || !got.Equal(modified) {
+		t.Errorf("Modified() without KeyDate = %v, %v, want LastModified", got, ok)
+	}
+
+	if _, err := NewKeyDate(`(\d{4}`, ""); err == nil {
+		t.Errorf("NewKeyDate() with a malformed pattern should return error")
+	}
+}
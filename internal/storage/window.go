@@ -0,0 +1,23 @@
+package storage
+
+import "time"
+
+// TimeWindow limits operations to objects modified in [After, Before). A zero bound
+// leaves that side open.
+type TimeWindow struct {
+	After  time.Time
+	Before time.Time
+}
+
+// Contains reports whether t lies in the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	return (w.After.IsZero() || !t.Before(w.After)) && (w.Before.IsZero() || t.Before(w.Before))
+}
+
+// Cutoff returns the earlier of cutoff and the upper bound of the window.
+func (w TimeWindow) Cutoff(cutoff time.Time) time.Time {
+	if !w.Before.IsZero() && w.Before.Before(cutoff) {
+		return w.Before
+	}
+	return cutoff
+}
@@ -0,0 +1,146 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// archAliases are other names release builds use for an architecture.
+var archAliases = map[string][]string{
+	"amd64": {"x86_64"},
+	"arm64": {"aarch64"},
+	"386":   {"i386"},
+}
+
+// AssetFor returns the build of release for goos and goarch: an asset whose name contains
+// both, like s3manager-linux-amd64 or s3manager_Linux_x86_64.tar.gz, that is a binary or
+// a .tar.gz, .tgz or .zip archive rather than a checksum or signature.
+func AssetFor(release *Release, goos, goarch string) (Asset, bool) {
+	arches := append([]string{goarch}, archAliases[goarch]...)
+	for _, asset := range release.Assets {
+		name := strings.ToLower(asset.Name)
+		if isChecksum(name) || strings.HasSuffix(name, ".sig") || strings.HasSuffix(name, ".asc") || strings.HasSuffix(name, ".pem") {
+			continue
+		}
+		if !strings.Contains(name, goos) {
+			continue
+		}
+		for _, arch := range arches {
+			if containsWord(name, arch) {
+				return asset, true
+			}
+		}
+	}
+	return Asset{}, false
+}
+
+// containsWord reports whether name contains word not directly followed by a letter or
+// digit, so that amd64 does not match amd64p32 and arm does not match arm64.
+func containsWord(name, word string) bool {
+	for rest := name; ; {
+		i := strings.Index(rest, word)
+		if i < 0 {
+			return false
+		}
+		end := i + len(word)
+		if end == len(rest) || !isAlnum(rest[end]) {
+			return true
+		}
+		rest = rest[end:]
+	}
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+func isChecksum(name string) bool {
+	return strings.HasSuffix(name, ".sha256") || strings.Contains(name, "checksums")
+}
+
+// checksumAsset returns the asset with the checksum of asset: <asset>.sha256, or else a
+// checksums file of the whole release.
+func checksumAsset(release *Release, asset Asset) (Asset, bool) {
+	var list Asset
+	found := false
+	for _, candidate := range release.Assets {
+		name := strings.ToLower(candidate.Name)
+		switch {
+		case name == strings.ToLower(asset.Name)+".sha256":
+			return candidate, true
+		case strings.Contains(name, "checksums") && !found:
+			list, found = candidate, true
+		}
+	}
+	return list, found
+}
+
+// findChecksum returns the checksum of name in a sha256sum listing, lines of a hex digest
+// and a file name, or the digest of a file holding a single one.
+func findChecksum(listing, name string) (string, bool) {
+	lines := strings.Split(strings.TrimSpace(listing), "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1 && len(lines) == 1:
+			return fields[0], true
+		case len(fields) >= 2 && strings.TrimPrefix(fields[len(fields)-1], "*") == name:
+			return fields[0], true
+		}
+	}
+	return "", false
+}
+
+// extractBinary returns the s3manager executable in the archive data named name, or data
+// itself when name is not an archive.
+func extractBinary(name string, data []byte) ([]byte, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		tr := tar.NewReader(gz)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return nil, fmt.Errorf("no s3manager executable in the archive")
+			}
+			if err != nil {
+				return nil, err
+			}
+			if header.Typeflag == tar.TypeReg && isExecutableName(header.Name) {
+				return io.ReadAll(tr)
+			}
+		}
+	case strings.HasSuffix(lower, ".zip"):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range zr.File {
+			if !file.FileInfo().IsDir() && isExecutableName(file.Name) {
+				r, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer r.Close()
+				return io.ReadAll(r)
+			}
+		}
+		return nil, fmt.Errorf("no s3manager executable in the archive")
+	}
+	return data, nil
+}
+
+func isExecutableName(name string) bool {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	return base == "s3manager" || base == "s3manager.exe"
+}
@@ -0,0 +1,213 @@
+// Package update looks up the releases of s3manager on GitHub and replaces the running
+// binary with the build of a release for the current platform.
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"s3manager/pkg/utils"
+)
+
+const (
+	// DefaultReleasesURL is the GitHub API endpoint of the releases of s3manager.
+	DefaultReleasesURL = "https://api.github.com/repos/Romasmi/s3-manager/releases"
+
+	requestTimeout = 5 * time.Minute
+	// maxDownload bounds the size of a downloaded release asset
+	maxDownload = 512 << 20
+)
+
+// ErrNoChecksum is returned by Install when the release publishes no checksum of the
+// build to install.
+var ErrNoChecksum = errors.New("release publishes no checksum")
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Release is a published release of s3manager.
+type Release struct {
+	Tag       string  `json:"tag_name"`
+	URL       string  `json:"html_url"`
+	Published string  `json:"published_at"`
+	Assets    []Asset `json:"assets"`
+}
+
+// Updater talks to the releases API at ReleasesURL.
+type Updater struct {
+	ReleasesURL string
+	// Token is sent as a bearer token when set, e.g. GITHUB_TOKEN to avoid the rate limit
+	// of anonymous requests
+	Token     string
+	UserAgent string
+	client    *http.Client
+}
+
+// New returns an updater for the releases API at releasesURL, DefaultReleasesURL when it is
+// empty.
+func New(releasesURL, token, userAgent string) *Updater {
+	if releasesURL == "" {
+		releasesURL = DefaultReleasesURL
+	}
+	return &Updater{
+		ReleasesURL: strings.TrimRight(releasesURL, "/"),
+		Token:       token,
+		UserAgent:   userAgent,
+		client:      &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Release returns the release tagged tag, or the latest release when tag is empty.
+func (u *Updater) Release(ctx context.Context, tag string) (*Release, error) {
+	releaseURL := u.ReleasesURL + "/latest"
+	if tag != "" {
+		// A tag like ../../other stays one path segment of the releases API
+		releaseURL = u.ReleasesURL + "/tags/" + url.PathEscape(tag)
+	}
+	body, err := u.get(ctx, releaseURL, "application/vnd.github+json", 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up release: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, fmt.Errorf("release without a tag at %s", releaseURL)
+	}
+	return &release, nil
+}
+
+// Install downloads the build of release for the current platform, checks it against the
+// checksums of the release and replaces the binary at path with it. A release without a
+// checksum of the build fails with ErrNoChecksum, unless skipVerify installs it
+// unverified. It returns the asset that was installed and whether its checksum was
+// verified.
+func (u *Updater) Install(ctx context.Context, release *Release, path string, skipVerify bool) (Asset, bool, error) {
+	asset, ok := AssetFor(release, runtime.GOOS, runtime.GOARCH)
+	if !ok {
+		return Asset{}, false, fmt.Errorf("release %s has no build for %s/%s", release.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+
+	data, err := u.get(ctx, asset.URL, "application/octet-stream", maxDownload)
+	if err != nil {
+		return asset, false, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	verified, err := u.verify(ctx, release, asset, data)
+	if errors.Is(err, ErrNoChecksum) && skipVerify {
+		verified, err = false, nil
+	}
+	if err != nil {
+		return asset, false, err
+	}
+	binary, err := extractBinary(asset.Name, data)
+	if err != nil {
+		return asset, verified, fmt.Errorf("failed to unpack %s: %w", asset.Name, err)
+	}
+	if err := replace(path, binary); err != nil {
+		return asset, verified, err
+	}
+	return asset, verified, nil
+}
+
+// verify checks data against the checksum of asset in release, either in <asset>.sha256
+// or in a checksums file listing the assets. It fails with ErrNoChecksum when the release
+// has neither.
+func (u *Updater) verify(ctx context.Context, release *Release, asset Asset, data []byte) (bool, error) {
+	sum, ok := checksumAsset(release, asset)
+	if !ok {
+		return false, fmt.Errorf("%w for %s in %s", ErrNoChecksum, asset.Name, release.Tag)
+	}
+	list, err := u.get(ctx, sum.URL, "application/octet-stream", 1<<20)
+	if err != nil {
+		return false, fmt.Errorf("failed to download %s: %w", sum.Name, err)
+	}
+	want, ok := findChecksum(string(list), asset.Name)
+	if !ok {
+		return false, fmt.Errorf("%s has no checksum for %s", sum.Name, asset.Name)
+	}
+	got := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(got[:]), want) {
+		return false, fmt.Errorf("checksum mismatch for %s: expected %s, got %x", asset.Name, want, got)
+	}
+	return true, nil
+}
+
+func (u *Updater) get(ctx context.Context, url, accept string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if u.UserAgent != "" {
+		req.Header.Set("User-Agent", u.UserAgent)
+	}
+	if u.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %s", url, utils.FormatBytes(limit))
+	}
+	return data, nil
+}
+
+// replace writes binary next to path and renames it over path, so that a failed update
+// leaves the old binary in place. Windows does not allow to replace a running executable,
+// but to rename it, so there the old binary is kept as <path>.old.
+func replace(path string, binary []byte) error {
+	tmp, err := utils.CreateTempFor(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return fmt.Errorf("failed to make %s executable: %w", tmp.Name(), err)
+	}
+
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
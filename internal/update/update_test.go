@@ -0,0 +1,242 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newReleaseServer serves a latest release with the given files as assets.
+func newReleaseServer(t *testing.T, tag string, files map[string][]byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		var assets []string
+		for name := range files {
+			assets = append(assets, fmt.Sprintf(`{"name":%q,"browser_download_url":%q}`, name, server.URL+"/download/"+name))
+		}
+		fmt.Fprintf(w, `{"tag_name":%q,"html_url":"https://example.com/%s","assets":[%s]}`, tag, tag, strings.Join(assets, ","))
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/download/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	})
+	return server
+}
+
+func platformAsset(suffix string) string {
+	return fmt.Sprintf("s3manager-%s-%s%s", runtime.GOOS, runtime.GOARCH, suffix)
+}
+
+func TestInstall(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(binary)
+	server := newReleaseServer(t, "v1.5.0", map[string][]byte{
+		platformAsset(""):          binary,
+		"s3manager-plan9-mips":     []byte("other platform"),
+		"s3manager_checksums.txt":  fmt.Appendf(nil, "%x  %s\n%x  s3manager-plan9-mips\n", sum, platformAsset(""), sha256.Sum256(nil)),
+		platformAsset("") + ".sig": []byte("signature"),
+	})
+
+	path := filepath.Join(t.TempDir(), "s3manager")
+	if err := os.WriteFile(path, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	u := New(server.URL+"/releases", "", "s3manager/test")
+	release, err := u.Release(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Tag != "v1.5.0" || release.URL != "https://example.com/v1.5.0" {
+		t.Fatalf("got release %+v", release)
+	}
+
+	asset, verified, err := u.Install(context.Background(), release, path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.Name != platformAsset("") || !verified {
+		t.Errorf("installed %s, verified %t", asset.Name, verified)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, binary) {
+		t.Errorf("binary is %q, want %q", got, binary)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("binary is not executable: %v %v", info.Mode(), err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestInstallChecksumMismatch(t *testing.T) {
+	server := newReleaseServer(t, "v1.5.0", map[string][]byte{
+		platformAsset(""):             []byte("tampered"),
+		platformAsset("") + ".sha256": []byte(strings.Repeat("0", 64) + "\n"),
+	})
+	path := filepath.Join(t.TempDir(), "s3manager")
+	os.WriteFile(path, []byte("old"), 0755)
+
+	u := New(server.URL+"/releases", "", "")
+	release, err := u.Release(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := u.Install(context.Background(), release, path, false); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old" {
+		t.Errorf("binary was replaced with %q", got)
+	}
+}
+
+func TestInstallWithoutChecksum(t *testing.T) {
+	server := newReleaseServer(t, "v1.5.0", map[string][]byte{platformAsset(""): []byte("unverified")})
+	path := filepath.Join(t.TempDir(), "s3manager")
+	os.WriteFile(path, []byte("old"), 0755)
+
+	u := New(server.URL+"/releases", "", "")
+	release, err := u.Release(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := u.Install(context.Background(), release, path, false); !errors.Is(err, ErrNoChecksum) {
+		t.Fatalf("expected ErrNoChecksum, got %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old" {
+		t.Errorf("binary was replaced with %q", got)
+	}
+
+	_, verified, err := u.Install(context.Background(), release, path, true)
+	if err != nil || verified {
+		t.Fatalf("Install(skipVerify) = %t, %v, want an unverified install", verified, err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "unverified" {
+		t.Errorf("binary is %q, want the unverified download", got)
+	}
+}
+
+func TestInstallFromArchive(t *testing.T) {
+	binary := []byte("new binary")
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string][]byte{"README.md": []byte("readme"), "s3manager": binary} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg})
+		tw.Write(data)
+	}
+	tw.Close()
+	gz.Close()
+
+	server := newReleaseServer(t, "v2.0.0", map[string][]byte{platformAsset(".tar.gz"): archive.Bytes()})
+	path := filepath.Join(t.TempDir(), "s3manager")
+	os.WriteFile(path, []byte("old"), 0755)
+
+	u := New(server.URL+"/releases", "", "")
+	release, err := u.Release(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, verified, err := u.Install(context.Background(), release, path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified {
+		t.Error("download without checksums reported as verified")
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, binary) {
+		t.Errorf("binary is %q, want %q", got, binary)
+	}
+}
+
+func TestAssetFor(t *testing.T) {
+	release := &Release{Assets: []Asset{
+		{Name: "s3manager_Linux_arm64.tar.gz.sha256"},
+		{Name: "s3manager_Linux_arm64.tar.gz"},
+		{Name: "s3manager_Linux_x86_64.tar.gz"},
+		{Name: "s3manager-linux-amd64p32"},
+		{Name: "s3manager-darwin-arm64"},
+	}}
+	tests := []struct {
+		goos, goarch string
+		want         string
+	}{
+		{"linux", "arm64", "s3manager_Linux_arm64.tar.gz"},
+		{"linux", "amd64", "s3manager_Linux_x86_64.tar.gz"},
+		{"darwin", "arm64", "s3manager-darwin-arm64"},
+		{"windows", "amd64", ""},
+		{"linux", "arm", ""},
+	}
+	for _, tt := range tests {
+		asset, _ := AssetFor(release, tt.goos, tt.goarch)
+		if asset.Name != tt.want {
+			t.Errorf("AssetFor(%s/%s) = %q, want %q", tt.goos, tt.goarch, asset.Name, tt.want)
+		}
+	}
+}
+
+func TestReleaseEscapesTag(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		fmt.Fprint(w, `{"tag_name":"v1.4.0"}`)
+	}))
+	defer server.Close()
+
+	if _, err := New(server.URL+"/releases", "", "test").Release(context.Background(), "v1.4.0/../../latest"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if want := "/releases/tags/v1.4.0%2F..%2F..%2Flatest"; path != want {
+		t.Errorf("requested %s, want %s", path, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.4.0", "v1.3.9", 1},
+		{"v1.4.0", "1.4.0", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.4.0-rc.1", "v1.4.0", -1},
+		{"v2", "v1.9.9", 1},
+		{"v1.4.0+build.5", "v1.4.0", 0},
+		{"v1.4.0-rc.10", "v1.4.0-rc.2", 1},
+		{"v1.4.0-rc.1", "v1.4.0-rc.1.1", -1},
+		{"v1.4.0-alpha", "v1.4.0-1", 1},
+		{"v1.4.0-beta.2", "v1.4.0-alpha.10", 1},
+	}
+	for _, tt := range tests {
+		got, err := Compare(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, %v, want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	if _, err := Compare("v1.4.0", "dev"); err == nil {
+		t.Error("expected an error for the dev version")
+	}
+}
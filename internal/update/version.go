@@ -0,0 +1,99 @@
+package update
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Compare compares the release versions a and b, like v1.4.0 or 1.4.0-rc.1, and returns
+// -1, 0 or +1. A pre-release is older than the release it leads to. It fails for versions
+// that are not of that form, like the dev version of local builds.
+func Compare(a, b string) (int, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range va.numbers {
+		if c := cmp.Compare(va.numbers[i], vb.numbers[i]); c != 0 {
+			return c, nil
+		}
+	}
+	switch {
+	case va.pre == vb.pre:
+		return 0, nil
+	case va.pre == "":
+		return 1, nil
+	case vb.pre == "":
+		return -1, nil
+	}
+	return comparePre(va.pre, vb.pre), nil
+}
+
+// comparePre compares pre-release versions like rc.2 and rc.10 by their dot-separated
+// identifiers as semver orders them: numeric identifiers by value and before
+// alphanumeric ones, others lexically, and a shorter version first when all of its
+// identifiers are equal.
+func comparePre(a, b string) int {
+	ia, ib := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range min(len(ia), len(ib)) {
+		na, nb := isNumeric(ia[i]), isNumeric(ib[i])
+		var c int
+		switch {
+		case na && nb:
+			// Numeric identifiers have no leading zeros, so the longer one is larger
+			c = cmp.Or(cmp.Compare(len(ia[i]), len(ib[i])), strings.Compare(ia[i], ib[i]))
+		case na:
+			c = -1
+		case nb:
+			c = 1
+		default:
+			c = strings.Compare(ia[i], ib[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(ia), len(ib))
+}
+
+func isNumeric(identifier string) bool {
+	if identifier == "" {
+		return false
+	}
+	for _, r := range identifier {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type version struct {
+	numbers [3]int
+	pre     string
+}
+
+func parseVersion(s string) (version, error) {
+	var v version
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	rest, _, _ = strings.Cut(rest, "+")
+	rest, v.pre, _ = strings.Cut(rest, "-")
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q, expected e.g. v1.4.0", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q, expected e.g. v1.4.0", s)
+		}
+		v.numbers[i] = n
+	}
+	return v, nil
+}
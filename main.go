@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log/slog"
 	"os"
 	"s3manager/cmd"
@@ -16,6 +17,15 @@ func main() {
 		os.Exit(1)
 	}
 	if err := cmd.Execute(cnf); err != nil {
+		var exit *cmd.ExitError
+		if errors.As(err, &exit) {
+			// The command printed its result, only the status is left to report
+			os.Exit(exit.Code)
+		}
+		if errors.Is(err, cmd.ErrInterrupted) {
+			// Conventional exit status for a process stopped by SIGINT
+			os.Exit(130)
+		}
 		slog.Error("Failed to execute command", "error", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AdaptiveLimiter bounds the requests in flight after a service asks to slow down. It
+// starts unbounded; the first throttled request caps it at half of the requests then in
+// flight and later ones halve the cap again, at most once per round of requests. Every
+// cap's worth of successful requests raises it by one until the concurrency of before
+// the throttling is reached and the cap is lifted. A nil *AdaptiveLimiter never blocks.
+// Requests are never held back below one in flight.
+type AdaptiveLimiter struct {
+	mu       sync.Mutex
+	inFlight int
+	// limit is the current cap, 0 while unbounded
+	limit int
+	// ceiling is the concurrency when throttling started, reaching it lifts the cap
+	ceiling int
+	// round counts reductions; requests started before the last one do not reduce again
+	round     uint64
+	successes int
+	// released is closed and replaced whenever a slot frees up or the cap grows
+	released chan struct{}
+	stats    ThrottleStats
+}
+
+// ThrottleStats summarise the throttling an AdaptiveLimiter reacted to.
+type ThrottleStats struct {
+	Throttled  int64
+	Reductions int
+	// MinLimit is the lowest cap, 0 when the limiter was never throttled
+	MinLimit int
+	First    time.Time
+	Last     time.Time
+}
+
+func NewAdaptiveLimiter() *AdaptiveLimiter {
+	return &AdaptiveLimiter{released: make(chan struct{})}
+}
+
+// Acquire blocks until a request may be sent or ctx is done. The returned ticket is
+// passed to Release.
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) (uint64, error) {
+	if l == nil {
+		return 0, ctx.Err()
+	}
+	for {
+		l.mu.Lock()
+		if l.limit == 0 || l.inFlight < l.limit {
+			l.inFlight++
+			round := l.round
+			l.mu.Unlock()
+			return round, nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// Release ends a request acquired with ticket. It returns the new cap when a throttled
+// request reduced it, 0 otherwise.
+func (l *AdaptiveLimiter) Release(ticket uint64, throttled bool) int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	defer l.wake()
+
+	if !throttled {
+		if l.limit > 0 {
+			if l.successes++; l.successes >= l.limit {
+				l.successes = 0
+				if l.limit++; l.limit >= l.ceiling {
+					l.limit = 0
+				}
+			}
+		}
+		return 0
+	}
+
+	now := time.Now()
+	l.stats.Throttled++
+	if l.stats.First.IsZero() {
+		l.stats.First = now
+	}
+	l.stats.Last = now
+	// Requests sent before the last reduction saw the old cap, the new one already
+	// accounts for them
+	if ticket < l.round || l.limit == 1 {
+		return 0
+	}
+
+	current := l.limit
+	if current == 0 {
+		current = l.inFlight + 1
+		l.ceiling = current
+	}
+	l.limit = max(current/2, 1)
+	l.successes = 0
+	l.round++
+	l.stats.Reductions++
+	if l.stats.MinLimit == 0 || l.limit < l.stats.MinLimit {
+		l.stats.MinLimit = l.limit
+	}
+	return l.limit
+}
+
+// Stats returns the throttling seen so far.
+func (l *AdaptiveLimiter) Stats() ThrottleStats {
+	if l == nil {
+		return ThrottleStats{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+func (l *AdaptiveLimiter) wake() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	var unlimited *AdaptiveLimiter
+	if _, err := unlimited.Acquire(context.Background()); err != nil {
+		t.Errorf("nil limiter Acquire() error = %v", err)
+	}
+
+	limiter := NewAdaptiveLimiter()
+	ctx := context.Background()
+	tickets := make([]uint64, 8)
+	for i := range tickets {
+		tickets[i], _ = limiter.Acquire(ctx)
+	}
+
+	// Eight requests in flight are throttled at once, only the first one halves the cap
+	if limit := limiter.Release(tickets[0], true); limit != 4 {
+		t.Errorf("Release() of the first throttled request = %d, want cap 4", limit)
+	}
+	for _, ticket := range tickets[1:4] {
+		if limit := limiter.Release(ticket, true); limit != 0 {
+			t.Errorf("Release() of a request of the same round = %d, want no reduction", limit)
+		}
+	}
+
+	// The four requests left in flight fill the cap
+	blocked, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(blocked); err == nil {
+		t.Error("Acquire() above the cap succeeded")
+	}
+	for _, ticket := range tickets[4:] {
+		limiter.Release(ticket, false)
+	}
+
+	// A request of the new round halves again
+	ticket, _ := limiter.Acquire(ctx)
+	if limit := limiter.Release(ticket, true); limit != 2 {
+		t.Errorf("Release() in the next round = %d, want cap 2", limit)
+	}
+
+	// Successes raise the cap by one per cap's worth of requests until it is lifted at 8
+	for i := 0; i < 2+3+4+5+6+7; i++ {
+		ticket, _ := limiter.Acquire(ctx)
+		limiter.Release(ticket, false)
+	}
+	lifted, cancelLifted := context.WithTimeout(ctx, time.Second)
+	defer cancelLifted()
+	for i := 0; i < 16; i++ {
+		if _, err := limiter.Acquire(lifted); err != nil {
+			t.Fatalf("Acquire() %d after the cap was lifted error = %v", i, err)
+		}
+	}
+
+	stats := limiter.Stats()
+	if stats.Throttled != 5 || stats.Reductions != 2 || stats.MinLimit != 2 || stats.First.IsZero() {
+		t.Errorf("Stats() = %+v, want 5 throttled, 2 reductions down to 2", stats)
+	}
+}
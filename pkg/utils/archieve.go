@@ -2,6 +2,7 @@ package utils
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,7 +13,16 @@ import (
 	"time"
 )
 
-func CreateArchive(paths []string, outputPath string, excludePatterns []string) (*models.ArchiveInfo, error) {
+// CreateArchive writes the files below paths to a zip archive at outputPath. Cancelling
+// ctx stops it between files and while copying them, leaving a partial archive that the
+// caller removes.
+func CreateArchive(ctx context.Context, paths []string, outputPath string, excludePatterns []string) (*models.ArchiveInfo, error) {
+	return CreateArchiveSince(ctx, paths, outputPath, excludePatterns, time.Time{})
+}
+
+// CreateArchiveSince is CreateArchive for incremental backups: files last modified before
+// modifiedSince are left out and counted as skipped. A zero time includes every file.
+func CreateArchiveSince(ctx context.Context, paths []string, outputPath string, excludePatterns []string, modifiedSince time.Time) (*models.ArchiveInfo, error) {
 	if err := ValidatePaths(paths); err != nil {
 		return nil, err
 	}
@@ -30,10 +40,11 @@ func CreateArchive(paths []string, outputPath string, excludePatterns []string)
 	zipWriter := zip.NewWriter(outFile)
 
 	var originalSize int64
+	var counts archiveCounts
 	createdAt := time.Now()
 
 	for _, path := range paths {
-		if err := addToArchive(zipWriter, path, "", excludePatterns); err != nil {
+		if err := addToArchive(ctx, zipWriter, path, "", excludePatterns, modifiedSince, &counts); err != nil {
 			return nil, fmt.Errorf("failed to add %s to archive: %w", path, err)
 		}
 
@@ -66,14 +77,23 @@ func CreateArchive(paths []string, outputPath string, excludePatterns []string)
 		OriginalSize:     originalSize,
 		CompressionRatio: compressionRatio,
 		CreatedAt:        createdAt,
+		FileCount:        counts.added,
+		SkippedCount:     counts.skipped,
 	}, nil
 }
 
-func addToArchive(zipWriter *zip.Writer, sourcePath, basePath string, excludePatterns []string) error {
+type archiveCounts struct {
+	added, skipped int
+}
+
+func addToArchive(ctx context.Context, zipWriter *zip.Writer, sourcePath, basePath string, excludePatterns []string, modifiedSince time.Time, counts *archiveCounts) error {
 	return filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return context.Cause(ctx)
+		}
 
 		if shouldExclude(path, excludePatterns) {
 			if info.IsDir() {
@@ -107,6 +127,11 @@ func addToArchive(zipWriter *zip.Writer, sourcePath, basePath string, excludePat
 		if info.IsDir() {
 			return nil
 		}
+		if info.ModTime().Before(modifiedSince) {
+			counts.skipped++
+			return nil
+		}
+		counts.added++
 
 		writer, err := zipWriter.CreateHeader(header)
 		if err != nil {
@@ -124,7 +149,7 @@ func addToArchive(zipWriter *zip.Writer, sourcePath, basePath string, excludePat
 			}
 		}(file)
 
-		_, err = io.Copy(writer, file)
+		_, err = io.Copy(writer, ContextReader(ctx, file))
 		return err
 	})
 }
@@ -146,6 +171,35 @@ func shouldExclude(path string, excludePatterns []string) bool {
 	return false
 }
 
+// ArchiveInputSize returns an upper bound for the size of the archive CreateArchiveSince
+// writes for the same arguments: the size of the files it would add, plus room for the zip
+// headers and for data that does not compress.
+func ArchiveInputSize(paths []string, excludePatterns []string, modifiedSince time.Time) (int64, error) {
+	var size int64
+	for _, sourcePath := range paths {
+		err := filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if shouldExclude(path, excludePatterns) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() || info.ModTime().Before(modifiedSince) {
+				return nil
+			}
+			size += info.Size() + info.Size()/1000 + 2*int64(len(path)) + 256
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to calculate size for %s: %w", sourcePath, err)
+		}
+	}
+	return size, nil
+}
+
 func getPathSize(path string) (int64, error) {
 	var size int64
 	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
@@ -173,6 +227,18 @@ func GenerateArchiveName(paths []string, extension string) string {
 	return fmt.Sprintf("archive_%s%s", time.Now().Format("20060102_150405"), extension)
 }
 
+// ArchiveName returns the file name of the archive of paths: name with the extension
+// added when it is missing, or a generated name when name is empty.
+func ArchiveName(name string, paths []string, extension string) string {
+	if name == "" {
+		return GenerateArchiveName(paths, extension)
+	}
+	if !strings.HasSuffix(name, extension) {
+		name += extension
+	}
+	return name
+}
+
 func ValidatePaths(paths []string) error {
 	for _, path := range paths {
 		if _, err := os.Stat(path); err != nil {
@@ -185,6 +251,18 @@ func ValidatePaths(paths []string) error {
 	return nil
 }
 
+// TempDir returns dir for temporary archives and snapshots, creating it when it does not
+// exist, or the system temporary directory when dir is empty.
+func TempDir(dir string) (string, error) {
+	if dir == "" {
+		return os.TempDir(), nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	return dir, nil
+}
+
 func CleanupTempFile(path string) error {
 	if path == "" {
 		return nil
@@ -2,10 +2,13 @@ package utils
 
 import (
 	"archive/zip"
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidatePaths(t *testing.T) {
@@ -85,6 +88,19 @@ func TestGenerateArchiveName(t *testing.T) {
 	}
 }
 
+func TestArchiveName(t *testing.T) {
+	paths := []string{"/path/to/dir"}
+	if result := ArchiveName("v1.0.0", paths, ".zip"); result != "v1.0.0.zip" {
+		t.Errorf("ArchiveName() = %s, want v1.0.0.zip", result)
+	}
+	if result := ArchiveName("release.zip", paths, ".zip"); result != "release.zip" {
+		t.Errorf("ArchiveName() = %s, want release.zip", result)
+	}
+	if result := ArchiveName("", paths, ".zip"); !strings.HasPrefix(result, "dir_") {
+		t.Errorf("ArchiveName() = %s, want a generated name", result)
+	}
+}
+
 func TestCleanupTempFile(t *testing.T) {
 	tempFile, err := os.CreateTemp("", "cleanup-test-*")
 	if err != nil {
@@ -143,7 +159,7 @@ func TestCreateArchive(t *testing.T) {
 
 	archivePath := filepath.Join(tempDir, "test-archive.zip")
 
-	archiveInfo, err := CreateArchive([]string{file1Path, file2Path}, archivePath)
+	archiveInfo, err := CreateArchive(context.Background(), []string{file1Path, file2Path}, archivePath, nil)
 	if err != nil {
 		t.Fatalf("CreateArchive() error = %v", err)
 	}
@@ -179,7 +195,7 @@ func TestCreateArchive(t *testing.T) {
 	}
 
 	archivePath2 := filepath.Join(tempDir, "test-archive2.zip")
-	_, err = CreateArchive([]string{tempDir}, archivePath2)
+	_, err = CreateArchive(context.Background(), []string{tempDir}, archivePath2, nil)
 	if err != nil {
 		t.Fatalf("CreateArchive() with directory error = %v", err)
 	}
@@ -198,12 +214,46 @@ func TestCreateArchive(t *testing.T) {
 		t.Errorf("Archive contains %d files, want at least 3", len(reader2.File))
 	}
 
-	_, err = CreateArchive([]string{filepath.Join(tempDir, "non-existent")}, archivePath)
+	_, err = CreateArchive(context.Background(), []string{filepath.Join(tempDir, "non-existent")}, archivePath, nil)
 	if err == nil {
 		t.Errorf("CreateArchive() with invalid path should return error")
 	}
 }
 
+func TestCreateArchiveSince(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "archive-since-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	src := filepath.Join(tempDir, "data")
+	os.Mkdir(src, 0755)
+	os.WriteFile(filepath.Join(src, "old.txt"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(src, "new.txt"), []byte("new"), 0644)
+	since := time.Now().Add(-time.Hour)
+	old := since.Add(-time.Hour)
+	os.Chtimes(filepath.Join(src, "old.txt"), old, old)
+
+	archivePath := filepath.Join(tempDir, "incremental.zip")
+	archiveInfo, err := CreateArchiveSince(context.Background(), []string{src}, archivePath, nil, since)
+	if err != nil {
+		t.Fatalf("CreateArchiveSince() error = %v", err)
+	}
+	if archiveInfo.FileCount != 1 || archiveInfo.SkippedCount != 1 {
+		t.Errorf("FileCount = %d, SkippedCount = %d, want 1 and 1", archiveInfo.FileCount, archiveInfo.SkippedCount)
+	}
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer reader.Close()
+	if len(reader.File) != 1 || reader.File[0].Name != "data/new.txt" {
+		t.Errorf("Archive contains %d files, want only data/new.txt", len(reader.File))
+	}
+}
+
 func TestGetPathSize(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "pathsize-test-*")
 	if err != nil {
@@ -246,3 +296,40 @@ func TestGetPathSize(t *testing.T) {
 		t.Errorf("getPathSize() with invalid path should return error")
 	}
 }
+
+func TestArchiveInputSize(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "data.bin"), make([]byte, 100000), 0644)
+	os.WriteFile(filepath.Join(dir, "skip.log"), make([]byte, 50000), 0644)
+
+	size, err := ArchiveInputSize([]string{dir}, []string{"*.log"}, time.Time{})
+	if err != nil {
+		t.Fatalf("ArchiveInputSize() error = %v", err)
+	}
+	if size < 100000 || size >= 150000 {
+		t.Errorf("ArchiveInputSize() = %d, want the size of data.bin plus overhead", size)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "out.zip")
+	info, err := CreateArchive(context.Background(), []string{dir}, archivePath, []string{"*.log"})
+	if err != nil {
+		t.Fatalf("CreateArchive() error = %v", err)
+	}
+	if info.CompressedSize > size {
+		t.Errorf("archive of %d bytes exceeds the estimate %d", info.CompressedSize, size)
+	}
+}
+
+func TestCreateArchiveCancelled(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := CreateArchive(ctx, []string{dir}, filepath.Join(t.TempDir(), "out.zip"), nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateArchive() error = %v, want context.Canceled", err)
+	}
+}
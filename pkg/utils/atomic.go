@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CreateTempFor creates the file <path>.tmp-<random> next to path. Downloads are written
+// to it and renamed to path with CommitTemp once complete, so that programs watching the
+// directory never see a partially written file under the final name.
+func CreateTempFor(path string) (*os.File, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return file, nil
+}
+
+// CommitTemp gives the closed temporary file tmpPath the permissions of a regular file
+// and renames it to path, replacing an existing file.
+func CommitTemp(tmpPath, path string) error {
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to finish %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to finish %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// FileMD5 returns the hex MD5 of a file, which equals the ETag of a single-part upload.
+func FileMD5(path string) (string, error) {
+	sum, err := fileDigest(path, md5.New())
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// FileSHA256 returns the base64 SHA-256 of a file as reported in x-amz-checksum-sha256.
+func FileSHA256(path string) (string, error) {
+	sum, err := fileDigest(path, sha256.New())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// MultipartETag recomputes the ETag S3 assigns to a multipart upload: the MD5 of the
+// concatenated part MD5s followed by "-<part count>".
+func MultipartETag(path string, partSize int64) (string, error) {
+	sum, parts, err := compositeDigest(path, partSize, md5.New)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum), parts), nil
+}
+
+// CompositeSHA256 recomputes a multipart SHA-256 checksum: the SHA-256 of the
+// concatenated part checksums followed by "-<part count>".
+func CompositeSHA256(path string, partSize int64) (string, error) {
+	sum, parts, err := compositeDigest(path, partSize, sha256.New)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(sum), parts), nil
+}
+
+func fileDigest(path string, h hash.Hash) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return h.Sum(nil), nil
+}
+
+func compositeDigest(path string, partSize int64, newHash func() hash.Hash) ([]byte, int, error) {
+	if partSize <= 0 {
+		return nil, 0, fmt.Errorf("part size must be greater than 0")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	combined := newHash()
+	parts := 0
+	for {
+		part := newHash()
+		n, err := io.CopyN(part, file, partSize)
+		if err != nil && err != io.EOF {
+			return nil, 0, fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		if n == 0 && parts > 0 {
+			break
+		}
+
+		combined.Write(part.Sum(nil))
+		parts++
+
+		if n < partSize {
+			break
+		}
+	}
+
+	return combined.Sum(nil), parts, nil
+}
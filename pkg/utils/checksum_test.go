@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMultipartETag(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "checksum-test-*.bin")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	content := []byte("0123456789")
+	tempFile.Write(content)
+	tempFile.Close()
+
+	part1 := md5.Sum(content[:4])
+	part2 := md5.Sum(content[4:8])
+	part3 := md5.Sum(content[8:])
+	combined := md5.Sum(append(append(part1[:], part2[:]...), part3[:]...))
+	expected := fmt.Sprintf("%s-3", hex.EncodeToString(combined[:]))
+
+	etag, err := MultipartETag(tempFile.Name(), 4)
+	if err != nil {
+		t.Fatalf("MultipartETag() error = %v", err)
+	}
+	if etag != expected {
+		t.Errorf("MultipartETag() = %s, want %s", etag, expected)
+	}
+
+	// A file that is an exact multiple of the part size must not get an empty trailing part
+	etag, err = MultipartETag(tempFile.Name(), 5)
+	if err != nil {
+		t.Fatalf("MultipartETag() error = %v", err)
+	}
+	if etag[len(etag)-2:] != "-2" {
+		t.Errorf("MultipartETag() = %s, want 2 parts", etag)
+	}
+
+	if _, err := MultipartETag(tempFile.Name(), 0); err == nil {
+		t.Errorf("MultipartETag() with zero part size should return error")
+	}
+}
+
+func TestFileMD5AndSHA256(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "checksum-test-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+	tempFile.Write([]byte("hello"))
+	tempFile.Close()
+
+	md5sum, err := FileMD5(tempFile.Name())
+	if err != nil {
+		t.Fatalf("FileMD5() error = %v", err)
+	}
+	if md5sum != "5d41402abc4b2a76b9719d911017c592" {
+		t.Errorf("FileMD5() = %s", md5sum)
+	}
+
+	sha, err := FileSHA256(tempFile.Name())
+	if err != nil {
+		t.Fatalf("FileSHA256() error = %v", err)
+	}
+	if sha != "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=" {
+		t.Errorf("FileSHA256() = %s", sha)
+	}
+
+	composite, err := CompositeSHA256(tempFile.Name(), 1024)
+	if err != nil {
+		t.Fatalf("CompositeSHA256() error = %v", err)
+	}
+	if composite[len(composite)-2:] != "-1" {
+		t.Errorf("CompositeSHA256() = %s, want 1 part", composite)
+	}
+}
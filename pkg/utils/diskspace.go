@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrInsufficientSpace is returned when a file system cannot hold a file that is about to
+// be written, so that operations fail early instead of leaving partial files behind.
+var ErrInsufficientSpace = errors.New("not enough free disk space")
+
+// CheckFreeSpace fails with ErrInsufficientSpace when the file system holding dir has less
+// than need bytes available. dir may not exist yet, its nearest existing parent is checked
+// then. Where the free space cannot be determined the check passes.
+func CheckFreeSpace(dir string, need int64) error {
+	if need <= 0 {
+		return nil
+	}
+	for {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	available, err := freeSpace(dir)
+	if err != nil {
+		return nil
+	}
+	if available < uint64(need) {
+		return fmt.Errorf("%w in %s: need %s, %s available", ErrInsufficientSpace, dir, FormatBytes(need), FormatBytes(int64(available)))
+	}
+	return nil
+}
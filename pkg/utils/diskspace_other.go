@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package utils
+
+import "errors"
+
+func freeSpace(string) (uint64, error) {
+	return 0, errors.New("free disk space is not available on this platform")
+}
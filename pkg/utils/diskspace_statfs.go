@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package utils
+
+import "syscall"
+
+// freeSpace returns the bytes available to unprivileged users on the file system of path.
+func freeSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
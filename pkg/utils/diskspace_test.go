@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckFreeSpace(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "not", "created")
+
+	if err := CheckFreeSpace(dir, 1); err != nil {
+		t.Errorf("CheckFreeSpace(1 byte) error = %v", err)
+	}
+	if err := CheckFreeSpace(dir, 0); err != nil {
+		t.Errorf("CheckFreeSpace(0) error = %v", err)
+	}
+	if _, err := freeSpace(t.TempDir()); err != nil {
+		t.Skipf("free space is not available: %v", err)
+	}
+	if err := CheckFreeSpace(dir, 1<<62); !errors.Is(err, ErrInsufficientSpace) {
+		t.Errorf("CheckFreeSpace(4 EiB) error = %v, want ErrInsufficientSpace", err)
+	}
+}
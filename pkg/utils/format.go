@@ -1,10 +1,14 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"s3manager/internal/models"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,16 +25,125 @@ func FormatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// ParseBytes parses sizes such as "512", "100MB", "1.5GiB" or "64k" using 1024-based units,
+// matching FormatBytes.
+func ParseBytes(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		if idx := strings.IndexByte("KMGTPE", s[n-1]); idx >= 0 {
+			multiplier = int64(1) << (10 * (idx + 1))
+			s = s[:n-1]
+		}
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+// ParseAge parses ages such as "180d", "2w" or "36h". Days and weeks are added to the
+// units understood by time.ParseDuration, and a bare number is a number of days.
+func ParseAge(value string) (time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	if s == "" {
+		return 0, fmt.Errorf("empty age")
+	}
+
+	unit := 24 * time.Hour
+	switch {
+	case strings.HasSuffix(s, "w"):
+		unit *= 7
+		s = strings.TrimSuffix(s, "w")
+	case strings.HasSuffix(s, "d"):
+		s = strings.TrimSuffix(s, "d")
+	default:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			age, err := time.ParseDuration(s)
+			if err != nil || age < 0 {
+				return 0, fmt.Errorf("invalid age: %s", value)
+			}
+			return age, nil
+		}
+	}
+
+	number, err := strconv.ParseFloat(s, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid age: %s", value)
+	}
+	return time.Duration(number * float64(unit)), nil
+}
+
+// ParseDate parses a point in time given as an RFC3339 timestamp, as "2006-01-02T15:04:05"
+// or as a date such as "2024-01-01". Times without a zone are in UTC.
+func ParseDate(value string) (time.Time, error) {
+	s := strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s, expected 2006-01-02 or RFC3339", value)
+}
+
+// MarshalResult returns data as JSON, starting objects with their schema_version.
+// Arrays are written as they are, without a version.
+func MarshalResult(data interface{}) ([]byte, error) {
+	jsonOutput, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	if len(jsonOutput) < 2 || jsonOutput[0] != '{' {
+		return jsonOutput, nil
+	}
+	versioned := fmt.Appendf(nil, `{"schema_version":%d`, models.OutputVersion)
+	if jsonOutput[1] != '}' {
+		versioned = append(versioned, ',')
+	}
+	return append(versioned, jsonOutput[1:]...), nil
+}
+
 func PrintJSON(data interface{}) error {
-	jsonOutput, err := json.MarshalIndent(data, "", "  ")
+	jsonOutput, err := MarshalResult(data)
 	if err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, jsonOutput, "", "  "); err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
-	fmt.Println(string(jsonOutput))
+	fmt.Println(indented.String())
 	return nil
 }
 
+// PrintJSONLine prints data as a single line of JSON, one record of a JSON Lines stream.
+func PrintJSONLine(data interface{}) error {
+	jsonOutput, err := MarshalResult(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(jsonOutput))
+	return err
+}
+
+// printedErrors counts the errors PrintError printed, see PrintedErrors.
+var printedErrors atomic.Int64
+
+// PrintedErrors returns the number of errors PrintError printed so far, which tells
+// whether a command that reports its errors as JSON failed.
+func PrintedErrors() int64 {
+	return printedErrors.Load()
+}
+
 func PrintError(err error, command string) {
+	printedErrors.Add(1)
 	errorResp := models.ErrorResponse{
 		Error:     err.Error(),
 		Timestamp: time.Now().Format(time.RFC3339),
@@ -44,6 +157,19 @@ func PrintError(err error, command string) {
 	}
 }
 
+// BytesPerSecond returns the transfer rate of bytes moved in d, or 0 when d is zero.
+func BytesPerSecond(bytes int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(bytes) / d.Seconds()
+}
+
+// FormatSpeed formats a transfer rate such as "12.5 MB/s".
+func FormatSpeed(bytesPerSecond float64) string {
+	return FormatBytes(int64(bytesPerSecond)) + "/s"
+}
+
 func FormatTime(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
@@ -55,7 +55,7 @@ func TestPrintJSON(t *testing.T) {
 		t.Errorf("PrintJSON() returned error: %v", err)
 	}
 
-	var result map[string]string
+	var result map[string]any
 	err = json.Unmarshal([]byte(output), &result)
 	if err != nil {
 		t.Errorf("PrintJSON() produced invalid JSON: %v", err)
@@ -64,6 +64,29 @@ func TestPrintJSON(t *testing.T) {
 	if result["key"] != "value" {
 		t.Errorf("PrintJSON() output = %v, want %v", result, testData)
 	}
+	if result["schema_version"] != float64(models.OutputVersion) {
+		t.Errorf("PrintJSON() schema_version = %v, want %d", result["schema_version"], models.OutputVersion)
+	}
+}
+
+func TestMarshalResult(t *testing.T) {
+	tests := []struct {
+		data any
+		want string
+	}{
+		{map[string]string{"key": "value"}, `{"schema_version":2,"key":"value"}`},
+		{struct{}{}, `{"schema_version":2}`},
+		{[]string{"a"}, `["a"]`},
+	}
+	for _, tt := range tests {
+		got, err := MarshalResult(tt.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("MarshalResult(%v) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
 }
 
 func TestPrintError(t *testing.T) {
@@ -115,3 +138,109 @@ func TestFormatTime(t *testing.T) {
 		t.Errorf("FormatTime() = %s, want %s", result, expected)
 	}
 }
+
+func TestBytesPerSecond(t *testing.T) {
+	rate := BytesPerSecond(10*1024*1024, 2*time.Second)
+	if rate != 5*1024*1024 {
+		t.Errorf("BytesPerSecond() = %f, want %d", rate, 5*1024*1024)
+	}
+
+	if FormatSpeed(rate) != "5.0 MB/s" {
+		t.Errorf("FormatSpeed() = %s, want %s", FormatSpeed(rate), "5.0 MB/s")
+	}
+
+	if BytesPerSecond(1024, 0) != 0 {
+		t.Errorf("BytesPerSecond() with zero duration should be 0")
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"64k", 64 * 1024},
+		{"16MB", 16 * 1024 * 1024},
+		{"1.5GiB", 1536 * 1024 * 1024},
+		{" 2 TB ", 2 * 1024 * 1024 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseBytes(tt.input)
+		if err != nil {
+			t.Errorf("ParseBytes(%q) error = %v", tt.input, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("ParseBytes(%q) = %d, want %d", tt.input, result, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "MB", "ten", "-5MB"} {
+		if _, err := ParseBytes(invalid); err == nil {
+			t.Errorf("ParseBytes(%q) should return error", invalid)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"180d", 180 * 24 * time.Hour},
+		{"30", 30 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"36h", 36 * time.Hour},
+		{" 90m ", 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseAge(tt.input)
+		if err != nil {
+			t.Errorf("ParseAge(%q) error = %v", tt.input, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "d", "ten days", "-5d", "-1h"} {
+		if _, err := ParseAge(invalid); err == nil {
+			t.Errorf("ParseAge(%q) should return error", invalid)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-01T12:30:00", time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)},
+		{"2024-01-01T12:30:00+02:00", time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)},
+		{" 2024-01-01 ", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		result, err := ParseDate(tt.input)
+		if err != nil {
+			t.Errorf("ParseDate(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !result.Equal(tt.expected) {
+			t.Errorf("ParseDate(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "yesterday", "2024-13-01", "01/02/2024"} {
+		if _, err := ParseDate(invalid); err == nil {
+			t.Errorf("ParseDate(%q) should return error", invalid)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if NewRateLimiter(0, 1) != nil {
+		t.Errorf("NewRateLimiter(0) should return nil (unlimited)")
+	}
+
+	var unlimited *RateLimiter
+	if err := unlimited.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter Wait() error = %v", err)
+	}
+
+	limiter := NewRateLimiter(50, 1)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	// The first token is available immediately, the next five need 5/50s
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("6 events at 50/s took %v, want at least 80ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := NewRateLimiter(0.001, 1)
+	slow.Wait(context.Background())
+	if err := slow.Wait(ctx); err == nil {
+		t.Errorf("Wait() with cancelled context should return error")
+	}
+}
+
+func TestThrottledReader(t *testing.T) {
+	data := make([]byte, 3000)
+	if r := ThrottledReader(context.Background(), bytes.NewReader(data), nil); r == nil {
+		t.Fatal("ThrottledReader() without limiter returned nil")
+	}
+
+	// 1000 bytes per second in bursts of 125: only the first burst is free
+	limiter := NewBandwidthLimiter(1000)
+	start := time.Now()
+	n, err := io.Copy(io.Discard, ThrottledReader(context.Background(), bytes.NewReader(data[:1500]), limiter))
+	if err != nil || n != 1500 {
+		t.Fatalf("io.Copy() = %d, %v, want 1500 bytes", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("1500 bytes at 1000/s took %v, want at least 1s", elapsed)
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"io"
+)
+
+// ContextReader returns a reader that fails with the cause of ctx once it is cancelled,
+// so that long copies stop between chunks instead of running to the end of the file.
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	return r.r.Read(p)
+}
+
+// NewBandwidthLimiter returns a limiter of bytesPerSecond for ThrottledReader, nil when
+// bytesPerSecond is not positive.
+func NewBandwidthLimiter(bytesPerSecond int64) *RateLimiter {
+	// Bursts of an eighth of a second keep the rate smooth without tiny reads
+	return NewRateLimiter(float64(bytesPerSecond), int(min(max(bytesPerSecond/8, 1), 256*1024)))
+}
+
+// ThrottledReader returns a reader that reads no faster than limiter allows, one byte per
+// token. Readers sharing a limiter share its rate. A nil limiter does not throttle.
+func ThrottledReader(ctx context.Context, r io.Reader, limiter *RateLimiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, limiter: limiter}
+}
+
+type throttledReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *RateLimiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if burst := int(r.limiter.burst); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
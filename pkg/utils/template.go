@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateTokens are the {token} placeholders ExpandTemplate replaces, by name.
+var templateTokens = map[string]func(now time.Time) (string, error){
+	"yyyy":     func(now time.Time) (string, error) { return now.Format("2006"), nil },
+	"yy":       func(now time.Time) (string, error) { return now.Format("06"), nil },
+	"MM":       func(now time.Time) (string, error) { return now.Format("01"), nil },
+	"dd":       func(now time.Time) (string, error) { return now.Format("02"), nil },
+	"HH":       func(now time.Time) (string, error) { return now.Format("15"), nil },
+	"mm":       func(now time.Time) (string, error) { return now.Format("04"), nil },
+	"ss":       func(now time.Time) (string, error) { return now.Format("05"), nil },
+	"hostname": func(time.Time) (string, error) { return os.Hostname() },
+	"unix":     func(now time.Time) (string, error) { return strconv.FormatInt(now.Unix(), 10), nil },
+	"uuid":     func(time.Time) (string, error) { return newUUID() },
+}
+
+// envTokenPrefix starts the {env:VAR} tokens, which expand to the value of VAR.
+const envTokenPrefix = "env:"
+
+// ExpandTemplate replaces the {token} placeholders in s, e.g. "backups/{yyyy}/{MM}/{dd}"
+// becomes "backups/2024/03/15" for a now on that day. Unknown tokens, unclosed braces and
+// unset or empty environment variables are errors, so a typo does not end up in an
+// object key.
+func ExpandTemplate(s string, now time.Time) (string, error) {
+	var b strings.Builder
+	rest := s
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed { in %q", s)
+		}
+		name := rest[start+1 : start+end]
+
+		value, err := expandToken(name, now)
+		if err != nil {
+			return "", fmt.Errorf("invalid token {%s} in %q: %w", name, s, err)
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(value)
+		rest = rest[start+end+1:]
+	}
+}
+
+func expandToken(name string, now time.Time) (string, error) {
+	if variable, ok := strings.CutPrefix(name, envTokenPrefix); ok {
+		if value := os.Getenv(variable); value != "" {
+			return value, nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", variable)
+	}
+	expand, ok := templateTokens[name]
+	if !ok {
+		return "", fmt.Errorf("unknown token")
+	}
+	return expand(now)
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
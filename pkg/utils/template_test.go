@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 5, 7, 8, 9, 0, time.UTC)
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("S3MANAGER_TEST_SITE", "eu-1")
+	t.Setenv("S3MANAGER_TEST_EMPTY", "")
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{"No tokens", "backups/db", "backups/db", false},
+		{"Date partitions", "backups/{yyyy}/{MM}/{dd}", "backups/2024/03/05", false},
+		{"Time and short year", "{yy}{MM}{dd}-{HH}{mm}{ss}", "240305-070809", false},
+		{"Hostname", "backups/{hostname}/", "backups/" + hostname + "/", false},
+		{"Unix time", "{unix}.zip", "1709622489.zip", false},
+		{"Environment variable", "{env:S3MANAGER_TEST_SITE}/{hostname}", "eu-1/" + hostname, false},
+		{"Empty environment variable", "{env:S3MANAGER_TEST_EMPTY}/db", "", true},
+		{"Unset environment variable", "{env:S3MANAGER_TEST_UNSET}/db", "", true},
+		{"Unknown token", "backups/{yyyyy}", "", true},
+		{"Unclosed brace", "backups/{yyyy", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExpandTemplate(tt.template, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ExpandTemplate() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandTemplateUUID(t *testing.T) {
+	first, err := ExpandTemplate("{uuid}", time.Now())
+	if err != nil {
+		t.Fatalf("ExpandTemplate() error = %v", err)
+	}
+	second, _ := ExpandTemplate("{uuid}", time.Now())
+
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !pattern.MatchString(first) {
+		t.Errorf("ExpandTemplate({uuid}) = %q, want a version 4 UUID", first)
+	}
+	if first == second {
+		t.Errorf("ExpandTemplate({uuid}) returned %q twice", first)
+	}
+}